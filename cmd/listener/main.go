@@ -11,21 +11,31 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	solanasdk "github.com/gagliardetto/solana-go"
 	"github.com/google/uuid"
+	blockchainadapter "github.com/hxuan190/stable_payment_gateway/internal/adapters/blockchain"
 	"github.com/hxuan190/stable_payment_gateway/internal/config"
 	auditrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/audit/repository"
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/bsc"
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/solana"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/tron"
 	complianceservice "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/service"
 	infrastructurerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/repository"
+	infrastructureservice "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/service"
+	ledgerrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/repository"
+	ledgerservice "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/service"
 	merchantrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/repository"
 	notificationservice "github.com/hxuan190/stable_payment_gateway/internal/modules/notification/service"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/legacy"
 	paymentrepo "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/repository"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
 	paymentport "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
 	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
 	walletDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/wallet/domain"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/database"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/trmlabs"
+	"github.com/hxuan190/stable_payment_gateway/internal/ports"
+	"github.com/hxuan190/stable_payment_gateway/internal/shared/events"
+	"github.com/hxuan190/stable_payment_gateway/internal/worker"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -62,6 +72,24 @@ func main() {
 	merchantRepo := merchantrepository.NewMerchantRepository(db)
 	auditRepo := auditrepository.NewAuditRepository(db)
 
+	// Listener cursors and recorded transactions let the listeners backfill blocks missed while down
+	listenerCursorRepo := infrastructurerepository.NewListenerCursorRepository(db)
+	blockchainTxRepo := infrastructurerepository.NewBlockchainTxRepository(db)
+
+	// Every inbound transfer is kept, unmatched ones are queued for ops in the admin API
+	inboundTransferRepo := paymentrepo.NewPostgresInboundTransferRepository(db)
+
+	// Watched tokens come from the token registry, configured tokens missing from it are added first
+	// Tokens added or disabled by admins are picked up when the listener restarts
+	tokenRegistry := paymentservice.NewTokenRegistry(
+		paymentrepo.NewPostgresTokenRepository(db),
+		paymentservice.DefaultTokenCacheTTL,
+		appLogger.Logger,
+	)
+	if err := tokenRegistry.Bootstrap(configuredTokens(cfg)); err != nil {
+		appLogger.WithError(err).Fatal("Failed to add configured tokens to the token registry")
+	}
+
 	// Initialize services
 	notifService := notificationservice.NewNotificationService(notificationservice.NotificationServiceConfig{
 		Logger:      appLogger.Logger,
@@ -100,18 +128,32 @@ func main() {
 	appLogger.Info("Wallet health check passed")
 
 	// Initialize payment service for transaction confirmation
-	paymentService := initializePaymentService(cfg, db, merchantRepo, solanaWallet, appLogger.Logger)
-
-	// Create payment confirmation callback with AML screening
-	confirmationCallback := createPaymentConfirmationCallback(
-		paymentService,
-		amlService,
-		complianceAlertService,
-		appLogger.Logger,
-	)
+	paymentService := initializePaymentService(cfg, db, merchantRepo, solanaWallet, tokenRegistry, appLogger.Logger)
+
+	// Create payment confirmation callbacks with AML screening, one per chain a transfer is observed on
+	newConfirmationCallback := func(chain paymentDomain.Chain) paymentConfirmationCallback {
+		return createPaymentConfirmationCallback(
+			chain,
+			paymentService,
+			amlService,
+			complianceAlertService,
+			appLogger.Logger,
+		)
+	}
 
 	// Configure supported token mints for Solana
-	supportedTokenMints := createSupportedTokenMints(cfg, appLogger.Logger)
+	// The listener looks them up in the token registry on every poll, an unreadable registry is only fatal at startup
+	solanaTokenMints := createSolanaTokenMintProvider(tokenRegistry, appLogger.Logger)
+	supportedTokenMints, err := solanaTokenMints(ctx)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Failed to configure Solana token mints")
+	}
+	if len(supportedTokenMints) == 0 {
+		appLogger.Warn("No supported token mints configured - listener will not process any payments")
+	}
+
+	// Deposit addresses are matched by recipient, the repository is the source of truth
+	depositAddressRepo := paymentrepo.NewPostgresDepositAddressRepository(db)
 
 	// Create Solana Client for listener
 	solanaClient, err := solana.NewClientWithURL(cfg.Solana.RPCURL)
@@ -119,12 +161,20 @@ func main() {
 		appLogger.WithError(err).Fatal("Failed to create Solana client for listener")
 	}
 
+	solanaListenerStore := infrastructurerepository.NewPostgresListenerStore(
+		listenerCursorRepo, blockchainTxRepo, inboundTransferRepo, paymentDomain.ChainSolana, cfg.Solana.Network, solanaWallet.GetAddress(),
+	)
+
 	// Initialize and start Solana blockchain listener
 	solanaListener, err := solana.NewTransactionListener(solana.ListenerConfig{
 		Client:               solanaClient,
 		Wallet:               solanaWallet,
 		WSURL:                cfg.Solana.WSURL, // Use configured WebSocket URL (auto-derives from RPC if empty)
-		ConfirmationCallback: confirmationCallback,
+		ConfirmationCallback: solana.PaymentConfirmationCallback(newConfirmationCallback(paymentDomain.ChainSolana)),
+		DepositProvider:      createSolanaDepositProvider(depositAddressRepo, solanaTokenMints),
+		ReferenceProvider:    createSolanaReferenceProvider(paymentrepo.NewPostgresPaymentRepository(db)),
+		Store:                solanaListenerStore,
+		TokenMintProvider:    solanaTokenMints,
 		SupportedTokenMints:  supportedTokenMints,
 		PollInterval:         10 * time.Second,
 		MaxRetries:           3,
@@ -165,8 +215,15 @@ func main() {
 
 		appLogger.Info("BSC wallet health check passed")
 
-		// Configure supported token contracts for BSC
-		supportedBSCTokens := createSupportedBSCTokenContracts(cfg, appLogger.Logger)
+		// Configure supported token contracts for BSC, reloaded from the token registry on every poll
+		bscTokenContracts := createBSCTokenContractProvider(tokenRegistry, appLogger.Logger)
+		supportedBSCTokens, err := bscTokenContracts(ctx)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to configure BSC token contracts")
+		}
+		if len(supportedBSCTokens) == 0 {
+			appLogger.Warn("No supported BSC token contracts configured - BSC listener will not process any payments")
+		}
 
 		// Create BSC Client for listener
 		bscClient, err := bsc.NewClientWithURL(cfg.BSC.RPCURL)
@@ -174,11 +231,18 @@ func main() {
 			appLogger.WithError(err).Fatal("Failed to create BSC client for listener")
 		}
 
+		bscListenerStore := infrastructurerepository.NewPostgresListenerStore(
+			listenerCursorRepo, blockchainTxRepo, inboundTransferRepo, paymentDomain.ChainBSC, cfg.BSC.Network, bscWallet.GetAddress(),
+		)
+
 		// Initialize BSC blockchain listener
 		bscListener, err = bsc.NewTransactionListener(bsc.ListenerConfig{
 			Client:                  bscClient,
 			Wallet:                  bscWallet,
-			ConfirmationCallback:    confirmationCallback,
+			ConfirmationCallback:    bsc.PaymentConfirmationCallback(newConfirmationCallback(paymentDomain.ChainBSC)),
+			DepositAddressProvider:  createBSCDepositProvider(depositAddressRepo),
+			Store:                   bscListenerStore,
+			TokenContractProvider:   bscTokenContracts,
 			SupportedTokenContracts: supportedBSCTokens,
 			PollInterval:            10 * time.Second,
 			RequiredConfirmations:   15, // BSC finality
@@ -204,6 +268,96 @@ func main() {
 		appLogger.Info("BSC configuration not found, skipping BSC listener initialization")
 	}
 
+	// Initialize and start TRON blockchain listener (if configured)
+	// The listener only watches the hot wallet, so no private key is needed
+	var tronListener *tron.TransactionListener
+	if cfg.TRON.WalletAddress != "" && cfg.TRON.RPCURL != "" {
+		appLogger.Info("Initializing TRON blockchain listener...")
+
+		tronClient, err := tron.NewClient(tron.ClientConfig{
+			RPCURL:  cfg.TRON.RPCURL,
+			APIKey:  cfg.TRON.APIKey,
+			Timeout: 30 * time.Second,
+		})
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to create TRON client for listener")
+		}
+
+		if err := tronClient.HealthCheck(ctx); err != nil {
+			appLogger.WithError(err).Fatal("TRON node health check failed")
+		}
+
+		// Configure supported token contracts for TRON, reloaded from the token registry on every poll
+		tronTokenContracts := createTRONTokenContractProvider(tokenRegistry, appLogger.Logger)
+		supportedTRONTokens, err := tronTokenContracts(ctx)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to configure TRON token contracts")
+		}
+		if len(supportedTRONTokens) == 0 {
+			appLogger.Warn("No supported TRON token contracts configured - TRON listener will not process any payments")
+		}
+
+		tronListenerStore := infrastructurerepository.NewPostgresListenerStore(
+			listenerCursorRepo, blockchainTxRepo, inboundTransferRepo, paymentDomain.ChainTRON, cfg.TRON.Network, cfg.TRON.WalletAddress,
+		)
+
+		tronListener, err = tron.NewTransactionListener(tron.ListenerConfig{
+			Client:                  tronClient,
+			WalletAddress:           cfg.TRON.WalletAddress,
+			ConfirmationCallback:    tron.PaymentConfirmationCallback(newConfirmationCallback(paymentDomain.ChainTRON)),
+			Store:                   tronListenerStore,
+			TokenContractProvider:   tronTokenContracts,
+			SupportedTokenContracts: supportedTRONTokens,
+			PollInterval:            10 * time.Second,
+			RequiredConfirmations:   uint64(cfg.TRON.MinConfirmations),
+			MaxRetries:              3,
+		})
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to create TRON transaction listener")
+		}
+
+		appLogger.WithFields(logger.Fields{
+			"wallet_address":    tronListener.GetWalletAddress(),
+			"supported_tokens":  len(supportedTRONTokens),
+			"required_confirms": cfg.TRON.MinConfirmations,
+		}).Info("TRON transaction listener configured")
+
+		// Start the TRON listener
+		if err := tronListener.Start(); err != nil {
+			appLogger.WithError(err).Fatal("Failed to start TRON transaction listener")
+		}
+
+		appLogger.Info("TRON transaction listener started successfully")
+	} else {
+		appLogger.Info("TRON configuration not found, skipping TRON listener initialization")
+	}
+
+	// Initialize one listener per configured EVM network (Ethereum, Polygon, Arbitrum, ...)
+	// Confirmations are published on the event bus and handed to the confirmation callback of their network
+	// The bus is synchronous so a rejected transfer reaches the listener and is queued as unmatched
+	evmEventBus := events.NewSyncEventBus(appLogger.Logger)
+	evmEventBus.Subscribe("payment.confirmed", createEventConfirmationHandler(newConfirmationCallback))
+	evmListeners := blockchainadapter.NewListenerManager(evmEventBus, appLogger.Logger)
+
+	for _, network := range cfg.EVMNetworks {
+		listenerConfig := createEVMListenerConfig(network, tokenRegistry, appLogger.Logger)
+		listenerConfig.ListenerStore = infrastructurerepository.NewPostgresListenerStore(
+			listenerCursorRepo, blockchainTxRepo, inboundTransferRepo, paymentDomain.Chain(network.Name), "mainnet", network.WalletAddress,
+		)
+
+		if err := evmListeners.AddListenerFromConfig(listenerConfig); err != nil {
+			appLogger.WithError(err).WithField("network", network.Name).Fatal("Failed to create EVM transaction listener")
+		}
+	}
+
+	if err := evmListeners.StartAll(ctx); err != nil {
+		appLogger.WithError(err).Fatal("Failed to start EVM transaction listeners")
+	}
+
+	if evmListeners.GetListenerCount() > 0 {
+		appLogger.WithField("networks", evmListeners.GetListenerCount()).Info("EVM transaction listeners started successfully")
+	}
+
 	// Start wallet balance monitor
 	monitorConfig := solana.WalletMonitorConfig{
 		CheckInterval:   5 * time.Minute,
@@ -246,6 +400,24 @@ func main() {
 		}
 	}
 
+	// Stop TRON listener if running
+	if tronListener != nil {
+		if err := tronListener.Stop(); err != nil {
+			appLogger.WithError(err).Error("Error stopping TRON listener")
+		}
+	}
+
+	// Stop EVM listeners
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := evmListeners.StopAll(shutdownCtx); err != nil {
+		appLogger.WithError(err).Error("Error stopping EVM listeners")
+	}
+	if err := evmEventBus.Shutdown(shutdownCtx); err != nil {
+		appLogger.WithError(err).Error("Error shutting down EVM event bus")
+	}
+
 	// Stop wallet monitor
 	walletMonitor.Stop()
 
@@ -299,13 +471,52 @@ func initializePaymentService(
 	db *gorm.DB,
 	merchantRepo *merchantrepository.MerchantRepository,
 	solanaWallet *solana.Wallet,
+	tokenRegistry *paymentservice.TokenRegistry,
 	appLogger *logrus.Logger,
 ) *paymentservice.PaymentService {
 	newPaymentRepo := paymentrepo.NewPostgresPaymentRepository(db)
+	ledgerService := ledgerservice.NewLedgerService(
+		ledgerrepository.NewLedgerRepository(db),
+		ledgerrepository.NewBalanceRepository(db),
+		db,
+	)
+
+	// Payments stay confirming until their transfers reach the policy depth, the worker completes them
+	confirmationPolicy, err := paymentDomain.ParseConfirmationPolicy(cfg.Confirmation.Policies)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Invalid confirmation policy")
+	}
+
+	// Late payments are re-quoted when accepted and refunded by the worker, which sends the refunds
+	exchangeRateService := infrastructureservice.NewExchangeRateService(
+		cfg.ExchangeRate.PrimaryAPI,
+		cfg.ExchangeRate.SecondaryAPI,
+		time.Duration(cfg.ExchangeRate.CacheTTL)*time.Second,
+		time.Duration(cfg.ExchangeRate.Timeout)*time.Second,
+		nil,
+		appLogger,
+	)
+	refundService := paymentservice.NewRefundService(
+		newPaymentRepo,
+		paymentrepo.NewPostgresPaymentTransferRepository(db),
+		paymentrepo.NewPostgresRefundRepository(db),
+		ledgerService,
+		paymentservice.RefundServiceConfig{},
+		appLogger,
+	)
+
+	// Invoice webhooks are published when the payments paying them complete here
+	queue, _ := worker.NewQueue(&worker.QueueConfig{
+		RedisAddr:     cfg.GetRedisAddr(),
+		RedisPassword: cfg.Redis.Password,
+		RedisDB:       cfg.Redis.DB,
+	})
+
 	return paymentservice.NewPaymentService(
 		newPaymentRepo,
+		paymentrepo.NewPostgresPaymentTransferRepository(db),
 		merchantRepo,
-		nil,
+		legacy.NewExchangeRateServiceAdapter(exchangeRateService),
 		nil,
 		nil,
 		paymentservice.PaymentServiceConfig{
@@ -315,26 +526,42 @@ func initializePaymentService(
 			FeePercentage:   0.01,
 			ExpiryMinutes:   30,
 			RedisClient:     nil,
+			LedgerService:   ledgerService,
+			TokenRegistry:   tokenRegistry,
+
+			ConfirmationPolicy:  confirmationPolicy,
+			LatePaymentRefunder: refundService,
+			WebhookPublisher:    queue,
+
+			InvoiceRepository:      paymentrepo.NewPostgresInvoiceRepository(db),
+			SubscriptionRepository: paymentrepo.NewPostgresSubscriptionRepository(db),
+			PaymentLinkRepository:  paymentrepo.NewPostgresPaymentLinkRepository(db),
 		},
 		appLogger,
 	)
 }
 
-// createPaymentConfirmationCallback creates a callback function with AML screening
+// paymentConfirmationCallback is the confirmation callback shared by the chain listeners
+type paymentConfirmationCallback func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error
+
+// createPaymentConfirmationCallback creates a callback function with AML screening for transfers observed on chain
 func createPaymentConfirmationCallback(
+	chain paymentDomain.Chain,
 	paymentService *paymentservice.PaymentService,
 	amlService complianceservice.AMLService,
 	complianceAlertService *complianceservice.ComplianceAlertService,
 	appLogger *logrus.Logger,
-) func(paymentID string, txHash string, amount decimal.Decimal, tokenMint string) error {
-	return func(paymentID string, txHash string, amount decimal.Decimal, tokenMint string) error {
+) paymentConfirmationCallback {
+	return func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
 		ctx := context.Background()
 
 		appLogger.WithFields(logrus.Fields{
-			"payment_id": paymentID,
-			"tx_hash":    txHash,
-			"amount":     amount,
-			"token":      tokenMint,
+			"payment_id":   paymentID,
+			"tx_hash":      txHash,
+			"amount":       amount,
+			"chain":        chain,
+			"token":        tokenSymbol,
+			"from_address": fromAddress,
 		}).Info("Processing payment confirmation")
 
 		// Get payment to extract sender address
@@ -344,17 +571,15 @@ func createPaymentConfirmationCallback(
 			return fmt.Errorf("failed to get payment: %w", err)
 		}
 
-		// Extract from_address from payment (it will be set by blockchain parser)
-		// For now, we'll screen after confirmation when from_address is available
-		// In a full implementation, the listener would pass from_address to this callback
-
-		// Create confirmation request
+		// Create confirmation request, the sender is kept on the payment for refunds and AML screening
 		confirmReq := paymentport.ConfirmPaymentRequest{
 			PaymentID:     paymentID,
 			TxHash:        txHash,
 			ActualAmount:  amount,
 			Confirmations: 1,
-			FromAddress:   "",
+			FromAddress:   fromAddress,
+			Chain:         chain,
+			Currency:      tokenSymbol,
 		}
 
 		// Confirm the payment
@@ -371,11 +596,11 @@ func createPaymentConfirmationCallback(
 			appLogger.WithFields(logrus.Fields{
 				"payment_id":   paymentID,
 				"from_address": fromAddress,
-				"chain":        "solana",
+				"chain":        chain,
 			}).Info("Screening sender address for AML compliance")
 
 			// Screen the wallet address
-			result, err := amlService.ScreenWalletAddress(ctx, fromAddress, "solana")
+			result, err := amlService.ScreenWalletAddress(ctx, fromAddress, string(chain))
 			if err != nil {
 				appLogger.WithError(err).Warn("AML screening failed (non-fatal)")
 				// Don't fail payment if AML screening service has issues
@@ -502,94 +727,272 @@ func createMockTRMClient() complianceservice.TRMLabsClient {
 	return trmlabs.NewMockClient()
 }
 
-// createSupportedTokenMints creates a map of supported token mints from config
-func createSupportedTokenMints(cfg *config.Config, appLogger *logrus.Logger) map[string]solana.TokenMintInfo {
-	supportedTokens := make(map[string]solana.TokenMintInfo)
-
-	// Add USDT if configured
-	if cfg.Solana.USDTMint != "" {
-		usdtMint, err := solanasdk.PublicKeyFromBase58(cfg.Solana.USDTMint)
+// createSolanaTokenMintProvider looks the SPL token mints enabled in the token registry up
+// The registry caches its tokens, changes made through the admin API reach the listener within the cache TTL
+func createSolanaTokenMintProvider(tokenRegistry *paymentservice.TokenRegistry, appLogger *logrus.Logger) solana.TokenMintProvider {
+	return func(ctx context.Context) (map[string]solana.TokenMintInfo, error) {
+		tokens, err := enabledRegistryTokens(tokenRegistry, paymentDomain.ChainSolana, appLogger)
 		if err != nil {
-			appLogger.WithFields(logrus.Fields{
-				"error": err.Error(),
-				"mint":  cfg.Solana.USDTMint,
-			}).Warn("Invalid USDT mint address, skipping")
-		} else {
-			supportedTokens["USDT"] = solana.TokenMintInfo{
-				MintAddress: usdtMint,
-				Symbol:      "USDT",
-				Decimals:    6, // USDT uses 6 decimals on Solana
+			return nil, err
+		}
+
+		supportedTokens := make(map[string]solana.TokenMintInfo, len(tokens))
+		for _, token := range tokens {
+			mint, err := solanasdk.PublicKeyFromBase58(token.Address)
+			if err != nil {
+				appLogger.WithFields(logrus.Fields{
+					"error": err.Error(),
+					"mint":  token.Address,
+				}).Warnf("Invalid %s mint address, skipping", token.Symbol)
+				continue
+			}
+
+			supportedTokens[token.Symbol] = solana.TokenMintInfo{
+				MintAddress: mint,
+				Symbol:      token.Symbol,
+				Decimals:    uint8(token.Decimals),
 			}
-			appLogger.WithField("mint", cfg.Solana.USDTMint).Info("USDT support configured")
 		}
+
+		return supportedTokens, nil
 	}
+}
 
-	// Add USDC if configured
-	if cfg.Solana.USDCMint != "" {
-		usdcMint, err := solanasdk.PublicKeyFromBase58(cfg.Solana.USDCMint)
+// createSolanaDepositProvider lists the watched Solana deposit token accounts
+// Deposits in currencies without an enabled mint are skipped
+func createSolanaDepositProvider(
+	depositAddressRepo paymentDomain.DepositAddressRepository,
+	tokenMints solana.TokenMintProvider,
+) solana.DepositAccountProvider {
+	return func(ctx context.Context) ([]solana.DepositAccount, error) {
+		supportedTokenMints, err := tokenMints(ctx)
 		if err != nil {
-			appLogger.WithFields(logrus.Fields{
-				"error": err.Error(),
-				"mint":  cfg.Solana.USDCMint,
-			}).Warn("Invalid USDC mint address, skipping")
-		} else {
-			supportedTokens["USDC"] = solana.TokenMintInfo{
-				MintAddress: usdcMint,
-				Symbol:      "USDC",
-				Decimals:    6, // USDC uses 6 decimals on Solana
+			return nil, err
+		}
+
+		addresses, err := depositAddressRepo.ListWatchedByChain(paymentDomain.ChainSolana)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts := make([]solana.DepositAccount, 0, len(addresses))
+		for _, address := range addresses {
+			tokenInfo, ok := supportedTokenMints[address.Currency]
+			if !ok {
+				continue
+			}
+			account, err := solanasdk.PublicKeyFromBase58(address.Address)
+			if err != nil {
+				continue
 			}
-			appLogger.WithField("mint", cfg.Solana.USDCMint).Info("USDC support configured")
+			accounts = append(accounts, solana.DepositAccount{
+				Address:   account,
+				PaymentID: address.PaymentID,
+				TokenMint: tokenInfo.MintAddress,
+			})
 		}
+
+		return accounts, nil
 	}
+}
 
-	if len(supportedTokens) == 0 {
-		appLogger.Warn("No supported token mints configured - listener will not process any payments")
+// createSolanaReferenceProvider lists the Solana Pay reference keys of payments that can still receive a transfer
+// Reference keys of payments expired within the late transfer window stay watched so late transfers are recorded
+func createSolanaReferenceProvider(paymentRepo paymentDomain.PaymentRepository) solana.ReferenceKeyProvider {
+	return func(ctx context.Context) ([]solana.ReferenceKey, error) {
+		payments, err := paymentRepo.ListSolanaPayPayments(time.Now().Add(-paymentDomain.SolanaPayLateTransferWindow))
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]solana.ReferenceKey, 0, len(payments))
+		for _, payment := range payments {
+			reference, err := solanasdk.PublicKeyFromBase58(payment.SolanaPayReference.String)
+			if err != nil {
+				continue
+			}
+			keys = append(keys, solana.ReferenceKey{
+				Address:   reference,
+				PaymentID: payment.ID,
+			})
+		}
+
+		return keys, nil
 	}
+}
+
+// createBSCDepositProvider maps the watched BSC deposit addresses to their payment IDs
+func createBSCDepositProvider(depositAddressRepo paymentDomain.DepositAddressRepository) bsc.DepositAddressProvider {
+	return func(ctx context.Context) (map[common.Address]string, error) {
+		addresses, err := depositAddressRepo.ListWatchedByChain(paymentDomain.ChainBSC)
+		if err != nil {
+			return nil, err
+		}
 
-	return supportedTokens
+		deposits := make(map[common.Address]string, len(addresses))
+		for _, address := range addresses {
+			deposits[common.HexToAddress(address.Address)] = address.PaymentID
+		}
+
+		return deposits, nil
+	}
 }
 
-// createSupportedBSCTokenContracts creates a map of supported BEP20 token contracts from config
-func createSupportedBSCTokenContracts(cfg *config.Config, appLogger *logrus.Logger) map[string]bsc.TokenContractInfo {
-	supportedTokens := make(map[string]bsc.TokenContractInfo)
+// createBSCTokenContractProvider looks the BEP20 token contracts enabled in the token registry up
+// The registry caches its tokens, changes made through the admin API reach the listener within the cache TTL
+func createBSCTokenContractProvider(tokenRegistry *paymentservice.TokenRegistry, appLogger *logrus.Logger) bsc.TokenContractProvider {
+	return func(ctx context.Context) (map[string]bsc.TokenContractInfo, error) {
+		tokens, err := enabledRegistryTokens(tokenRegistry, paymentDomain.ChainBSC, appLogger)
+		if err != nil {
+			return nil, err
+		}
 
-	// Add USDT if configured
-	if cfg.BSC.USDTContract != "" {
-		if !common.IsHexAddress(cfg.BSC.USDTContract) {
-			appLogger.WithFields(logrus.Fields{
-				"contract": cfg.BSC.USDTContract,
-			}).Warn("Invalid USDT contract address, skipping")
-		} else {
-			usdtContract := common.HexToAddress(cfg.BSC.USDTContract)
-			supportedTokens["USDT"] = bsc.TokenContractInfo{
-				ContractAddress: usdtContract,
-				Symbol:          "USDT",
-				Decimals:        18, // USDT uses 18 decimals on BSC
+		supportedTokens := make(map[string]bsc.TokenContractInfo, len(tokens))
+		for _, token := range tokens {
+			if !common.IsHexAddress(token.Address) {
+				appLogger.WithFields(logrus.Fields{
+					"contract": token.Address,
+				}).Warnf("Invalid %s contract address, skipping", token.Symbol)
+				continue
+			}
+
+			supportedTokens[token.Symbol] = bsc.TokenContractInfo{
+				ContractAddress: common.HexToAddress(token.Address),
+				Symbol:          token.Symbol,
+				Decimals:        uint8(token.Decimals),
 			}
-			appLogger.WithField("contract", cfg.BSC.USDTContract).Info("BSC USDT support configured")
 		}
+
+		return supportedTokens, nil
+	}
+}
+
+// createEVMListenerConfig builds the listener configuration of a configured EVM network
+// The network's tokens are the ones enabled in the token registry, looked up again on every poll
+func createEVMListenerConfig(network config.EVMNetworkConfig, tokenRegistry *paymentservice.TokenRegistry, appLogger *logrus.Logger) ports.BlockchainListenerConfig {
+	chain := paymentDomain.Chain(network.Name)
+	tokens, err := enabledRegistryTokens(tokenRegistry, chain, appLogger)
+	if err != nil {
+		appLogger.WithError(err).WithField("chain", chain).Fatal("Failed to configure EVM network tokens")
+	}
+	supportedTokens := make(map[string]string, len(tokens))
+	tokenDecimals := make(map[string]uint8, len(tokens))
+	for _, token := range tokens {
+		supportedTokens[token.Symbol] = token.Address
+		tokenDecimals[token.Symbol] = uint8(token.Decimals)
+	}
+
+	return ports.BlockchainListenerConfig{
+		BlockchainType:        ports.BlockchainType(network.Name),
+		RPCURL:                network.RPCURL,
+		WalletAddress:         network.WalletAddress,
+		SupportedTokens:       supportedTokens,
+		TokenDecimals:         tokenDecimals,
+		TokenProvider:         createEVMTokenProvider(tokenRegistry, chain, appLogger),
+		ChainID:               network.ChainID,
+		PollIntervalSeconds:   network.PollIntervalSeconds,
+		RequiredConfirmations: uint64(network.RequiredConfirmations),
+		MaxRetries:            3,
+	}
+}
+
+// createEVMTokenProvider looks the tokens of an EVM network enabled in the token registry up
+func createEVMTokenProvider(tokenRegistry *paymentservice.TokenRegistry, chain paymentDomain.Chain, appLogger *logrus.Logger) ports.TokenProvider {
+	return func(ctx context.Context) ([]ports.ListenerToken, error) {
+		tokens, err := enabledRegistryTokens(tokenRegistry, chain, appLogger)
+		if err != nil {
+			return nil, err
+		}
+
+		listenerTokens := make([]ports.ListenerToken, 0, len(tokens))
+		for _, token := range tokens {
+			listenerTokens = append(listenerTokens, ports.ListenerToken{
+				Symbol:   token.Symbol,
+				Address:  token.Address,
+				Decimals: uint8(token.Decimals),
+			})
+		}
+
+		return listenerTokens, nil
 	}
+}
 
-	// Add BUSD if configured
-	if cfg.BSC.BUSDContract != "" {
-		if !common.IsHexAddress(cfg.BSC.BUSDContract) {
-			appLogger.WithFields(logrus.Fields{
-				"contract": cfg.BSC.BUSDContract,
-			}).Warn("Invalid BUSD contract address, skipping")
-		} else {
-			busdContract := common.HexToAddress(cfg.BSC.BUSDContract)
-			supportedTokens["BUSD"] = bsc.TokenContractInfo{
-				ContractAddress: busdContract,
-				Symbol:          "BUSD",
-				Decimals:        18, // BUSD uses 18 decimals on BSC
+// createEventConfirmationHandler passes payment confirmed events to the payment confirmation callback of their chain
+func createEventConfirmationHandler(newConfirmationCallback func(chain paymentDomain.Chain) paymentConfirmationCallback) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		confirmed, ok := event.(*events.PaymentConfirmedEvent)
+		if !ok {
+			return fmt.Errorf("unexpected event type %T", event)
+		}
+
+		confirmationCallback := newConfirmationCallback(paymentDomain.Chain(confirmed.Blockchain))
+		return confirmationCallback(confirmed.PaymentID, confirmed.TxHash, confirmed.Amount, confirmed.TokenSymbol, confirmed.Sender)
+	}
+}
+
+// createTRONTokenContractProvider looks the TRC-20 token contracts enabled in the token registry up
+// The registry caches its tokens, changes made through the admin API reach the listener within the cache TTL
+func createTRONTokenContractProvider(tokenRegistry *paymentservice.TokenRegistry, appLogger *logrus.Logger) tron.TokenContractProvider {
+	return func(ctx context.Context) (map[string]tron.TokenContractInfo, error) {
+		tokens, err := enabledRegistryTokens(tokenRegistry, paymentDomain.ChainTRON, appLogger)
+		if err != nil {
+			return nil, err
+		}
+
+		supportedTokens := make(map[string]tron.TokenContractInfo, len(tokens))
+		for _, token := range tokens {
+			contractAddress, err := tron.ParseAddress(token.Address)
+			if err != nil {
+				appLogger.WithFields(logrus.Fields{
+					"error":    err.Error(),
+					"contract": token.Address,
+				}).Warnf("Invalid TRON %s contract address, skipping", token.Symbol)
+				continue
+			}
+
+			supportedTokens[token.Symbol] = tron.TokenContractInfo{
+				ContractAddress: contractAddress,
+				Symbol:          token.Symbol,
+				Decimals:        uint8(token.Decimals),
 			}
-			appLogger.WithField("contract", cfg.BSC.BUSDContract).Info("BSC BUSD support configured")
 		}
+
+		return supportedTokens, nil
 	}
+}
 
-	if len(supportedTokens) == 0 {
-		appLogger.Warn("No supported BSC token contracts configured - BSC listener will not process any payments")
+// enabledRegistryTokens returns the enabled tokens of a chain that have an address
+func enabledRegistryTokens(tokenRegistry *paymentservice.TokenRegistry, chain paymentDomain.Chain, appLogger *logrus.Logger) ([]paymentDomain.Token, error) {
+	tokens, err := tokenRegistry.EnabledTokens(chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s tokens from the token registry: %w", chain, err)
+	}
+
+	withAddress := make([]paymentDomain.Token, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Address == "" {
+			appLogger.WithFields(logrus.Fields{
+				"chain":  chain,
+				"symbol": token.Symbol,
+			}).Warn("Token has no address in the registry, skipping")
+			continue
+		}
+		withAddress = append(withAddress, token)
 	}
 
-	return supportedTokens
+	return withAddress, nil
+}
+
+// configuredTokens converts the tokens of the deployment configuration for the token registry
+func configuredTokens(cfg *config.Config) []paymentDomain.Token {
+	var tokens []paymentDomain.Token
+	for _, token := range cfg.ConfiguredTokens() {
+		tokens = append(tokens, paymentDomain.Token{
+			Chain:    paymentDomain.Chain(token.Chain),
+			Symbol:   token.Symbol,
+			Address:  token.Address,
+			Decimals: token.Decimals,
+		})
+	}
+	return tokens
 }
@@ -8,11 +8,17 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/hxuan190/stable_payment_gateway/internal/config"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/bsc"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/evm"
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/solana"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/tron"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/cache"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/database"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/storage"
 	"github.com/hxuan190/stable_payment_gateway/internal/worker"
 )
 
@@ -104,25 +110,142 @@ func main() {
 		}
 	}
 
+	// Initialize BSC wallet (optional, for sending refunds)
+	var bscWallet *bsc.Wallet
+	if cfg.BSC.RPCURL != "" && cfg.BSC.WalletPrivateKey != "" {
+		bscWallet, err = bsc.LoadWallet(cfg.BSC.WalletPrivateKey, cfg.BSC.RPCURL)
+		if err != nil {
+			logger.Warn("Failed to load BSC wallet", logger.Fields{
+				"error": err.Error(),
+			})
+		} else {
+			logger.Info("BSC wallet loaded", logger.Fields{
+				"address": bscWallet.GetAddress(),
+			})
+		}
+	}
+
+	// Initialize read-only chain clients (optional, for verifying payment confirmations)
+	var bscClient *bsc.Client
+	if cfg.BSC.RPCURL != "" {
+		bscClient, err = bsc.NewClientWithURL(cfg.BSC.RPCURL)
+		if err != nil {
+			logger.Warn("Failed to initialize BSC client", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	var tronClient *tron.Client
+	if cfg.TRON.RPCURL != "" && cfg.TRON.WalletAddress != "" {
+		tronClient, err = tron.NewClient(tron.ClientConfig{
+			RPCURL:  cfg.TRON.RPCURL,
+			APIKey:  cfg.TRON.APIKey,
+			Timeout: 30 * time.Second,
+		})
+		if err != nil {
+			logger.Warn("Failed to initialize TRON client", logger.Fields{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	evmClients := make(map[string]*ethclient.Client)
+	for _, network := range cfg.EVMNetworks {
+		client, err := evm.DialBackend(ctx, network.RPCURL)
+		if err != nil {
+			logger.Warn("Failed to initialize EVM client", logger.Fields{
+				"network": network.Name,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		evmClients[network.Name] = client
+	}
+
+	confirmationPolicy, err := paymentDomain.ParseConfirmationPolicy(cfg.Confirmation.Policies)
+	if err != nil {
+		logger.Fatal("Invalid confirmation policy", err)
+	}
+
+	dunningPolicy := paymentDomain.DunningPolicy{
+		GracePeriod: time.Duration(cfg.Subscription.GracePeriodDays) * 24 * time.Hour,
+	}
+	for _, hours := range cfg.Subscription.RetryScheduleHours {
+		dunningPolicy.RetryIntervals = append(dunningPolicy.RetryIntervals, time.Duration(hours)*time.Hour)
+	}
+	paymentPageBaseURL := cfg.Subscription.PaymentPageBaseURL
+	if paymentPageBaseURL == "" {
+		paymentPageBaseURL = fmt.Sprintf("http://%s:%d", cfg.API.Host, cfg.API.Port)
+	}
+
+	// Payments issued by the worker pay into the same wallets as the API's
+	chainWallets := map[paymentDomain.Chain]string{
+		paymentDomain.ChainBSC:  cfg.BSC.WalletAddress,
+		paymentDomain.ChainTRON: cfg.TRON.WalletAddress,
+	}
+	for _, network := range cfg.EVMNetworks {
+		chainWallets[paymentDomain.Chain(network.Name)] = network.WalletAddress
+	}
+
+	// Merchant exports are stored in the same bucket the API reads them from
+	var storageService storage.StorageService
+	if cfg.Storage.Bucket != "" && cfg.Storage.Region != "" {
+		storageService, err = storage.NewS3Storage(context.Background(), storage.S3Config{
+			Region:      cfg.Storage.Region,
+			KYCBucket:   cfg.Storage.Bucket,
+			AuditBucket: cfg.Storage.Bucket,
+			Encryption:  "AES256",
+		})
+		if err != nil {
+			logger.Warn("Failed to initialize S3 storage, using mock", logger.Fields{"error": err.Error()})
+			storageService = storage.NewMockStorage()
+		}
+	}
+
 	// Create worker server
 	logger.Info("Setting up worker server...")
 	workerServer := worker.NewServer(&worker.ServerConfig{
-		RedisAddr:                fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		RedisPassword:            cfg.Redis.Password,
-		RedisDB:                  cfg.Redis.DB,
-		DB:                       db.GetGORM(),
-		Cache:                    redisClient,
-		SolanaClient:             solanaClient,
-		SolanaWallet:             solanaWallet,
+		RedisAddr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		RedisPassword: cfg.Redis.Password,
+		RedisDB:       cfg.Redis.DB,
+		DB:            db.GetGORM(),
+		Cache:         redisClient,
+		SolanaClient:  solanaClient,
+		SolanaWallet:  solanaWallet,
+		BSCWallet:     bscWallet,
+		SolanaTokenMints: map[string]string{
+			"USDT": cfg.Solana.USDTMint,
+			"USDC": cfg.Solana.USDCMint,
+		},
+		BSCTokenContracts: map[string]string{
+			"USDT": cfg.BSC.USDTContract,
+			"BUSD": cfg.BSC.BUSDContract,
+		},
+		BSCDepositXPrv:           cfg.BSC.DepositXPrv,
 		Concurrency:              10, // Process up to 10 jobs concurrently
 		ExchangeRatePrimaryAPI:   cfg.ExchangeRate.PrimaryAPI,
 		ExchangeRateSecondaryAPI: cfg.ExchangeRate.SecondaryAPI,
 		ExchangeRateCacheTTL:     time.Duration(cfg.ExchangeRate.CacheTTL) * time.Second,
 		ExchangeRateTimeout:      time.Duration(cfg.ExchangeRate.Timeout) * time.Second,
+		BSCClient:                bscClient,
+		TRONClient:               tronClient,
+		EVMClients:               evmClients,
+		ConfirmationPolicy:       confirmationPolicy,
+		ReorgWatchWindow:         time.Duration(cfg.Confirmation.ReorgWatchHours) * time.Hour,
+		OpsTeamEmails:            cfg.OpsTeamEmails,
+
+		SubscriptionDunningPolicy: dunningPolicy,
+		PaymentPageBaseURL:        paymentPageBaseURL,
+		ChainWallets:              chainWallets,
+		Storage:                   storageService,
+		StorageBucket:             cfg.Storage.Bucket,
+
 		Queues: map[string]int{
 			"webhooks":       5, // Highest priority
 			"webhooks_retry": 3,
 			"periodic":       4,
+			"batches":        3,
 			"monitoring":     2,
 			"reports":        1, // Lowest priority
 		},
@@ -113,6 +113,7 @@ func (a *BSCListenerAdapter) Start(ctx context.Context) error {
 		PollInterval:            pollInterval,
 		RequiredConfirmations:   requiredConfirmations,
 		MaxRetries:              a.config.MaxRetries,
+		Store:                   a.config.ListenerStore,
 	}
 
 	listener, err := bsc.NewTransactionListener(listenerConfig)
@@ -192,12 +193,15 @@ func (a *BSCListenerAdapter) GetListenerHealth() ports.ListenerHealth {
 	health.LastActivityTimestamp = a.lastActivity.Unix()
 	health.ErrorCount = a.errorCount
 	health.SuccessfulConfirmations = a.successCount
+	if a.listener != nil {
+		health.LastProcessedBlock = a.listener.GetLastProcessedBlock()
+	}
 
 	return health
 }
 
 // handlePaymentConfirmation is the adapter function that converts BSC-specific callback to the generic port interface
-func (a *BSCListenerAdapter) handlePaymentConfirmation(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string) error {
+func (a *BSCListenerAdapter) handlePaymentConfirmation(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
 	a.handlerMu.RLock()
 	handler := a.confirmationHandler
 	a.handlerMu.RUnlock()
@@ -219,7 +223,7 @@ func (a *BSCListenerAdapter) handlePaymentConfirmation(paymentID string, txHash
 		TokenSymbol:    tokenSymbol,
 		BlockchainType: ports.BlockchainTypeBSC,
 		Recipient:      a.wallet.GetAddress(),
-		// Sender is not easily available from the callback, would need to parse transaction
+		Sender:         fromAddress,
 		// BlockNumber and Timestamp would require additional RPC calls
 		// For MVP, we'll leave these empty and can enhance later
 	}
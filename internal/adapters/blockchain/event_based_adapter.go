@@ -43,7 +43,8 @@ func (a *EventBasedListenerAdapter) handlePaymentConfirmation(ctx context.Contex
 	case ports.BlockchainTypeTRON:
 		blockchain = events.BlockchainTypeTRON
 	default:
-		blockchain = events.BlockchainTypeSolana
+		// EVM networks are named by configuration
+		blockchain = events.BlockchainType(confirmation.BlockchainType)
 	}
 
 	// Create payment confirmed event
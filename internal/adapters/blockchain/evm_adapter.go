@@ -0,0 +1,290 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/shopspring/decimal"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/evm"
+	"github.com/hxuan190/stable_payment_gateway/internal/ports"
+)
+
+// EVMListenerAdapter adapts the generic EVM TransactionListener to implement BlockchainListener interface
+// One adapter runs per configured EVM network (Ethereum, Polygon, Arbitrum, ...)
+type EVMListenerAdapter struct {
+	// Underlying EVM listener implementation
+	listener *evm.TransactionListener
+
+	// Configuration
+	config ports.BlockchainListenerConfig
+	client *ethclient.Client
+
+	// Confirmation handler
+	confirmationHandler ports.PaymentConfirmationHandler
+	handlerMu           sync.RWMutex
+
+	// Health tracking
+	health       ports.ListenerHealth
+	healthMu     sync.RWMutex
+	lastActivity time.Time
+	errorCount   uint64
+	successCount uint64
+}
+
+// NewEVMListenerAdapter creates a new EVM blockchain listener adapter
+// The blockchain type is the configured network name, the listener only watches the wallet so no private key is needed
+func NewEVMListenerAdapter(config ports.BlockchainListenerConfig) (*EVMListenerAdapter, error) {
+	if config.BlockchainType == "" {
+		return nil, fmt.Errorf("blockchain type cannot be empty")
+	}
+
+	if config.ChainID <= 0 {
+		return nil, fmt.Errorf("invalid chain ID for %s: %d", config.BlockchainType, config.ChainID)
+	}
+
+	if !common.IsHexAddress(config.WalletAddress) {
+		return nil, fmt.Errorf("invalid %s wallet address: %s", config.BlockchainType, config.WalletAddress)
+	}
+
+	// Create EVM client
+	client, err := evm.DialBackend(context.Background(), config.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s client: %w", config.BlockchainType, err)
+	}
+
+	adapter := &EVMListenerAdapter{
+		config:       config,
+		client:       client,
+		lastActivity: time.Now(),
+		health: ports.ListenerHealth{
+			IsHealthy:        true,
+			ConnectionStatus: "initialized",
+		},
+	}
+
+	// Set confirmation handler if provided
+	if config.ConfirmationHandler != nil {
+		adapter.confirmationHandler = config.ConfirmationHandler
+	}
+
+	return adapter, nil
+}
+
+// Start begins listening for transactions on the EVM network
+func (a *EVMListenerAdapter) Start(ctx context.Context) error {
+	// Build supported token contracts map
+	supportedTokenContracts, err := evmTokenContracts(a.config.SupportedTokens, a.config.TokenDecimals)
+	if err != nil {
+		return err
+	}
+
+	// Create the underlying EVM listener with our adapter's callback
+	listenerConfig := evm.ListenerConfig{
+		Backend:                 a.client,
+		Network:                 string(a.config.BlockchainType),
+		ChainID:                 big.NewInt(a.config.ChainID),
+		WalletAddress:           common.HexToAddress(a.config.WalletAddress),
+		ConfirmationCallback:    a.handlePaymentConfirmation,
+		SupportedTokenContracts: supportedTokenContracts,
+		TokenContractProvider:   evmTokenContractProvider(a.config.TokenProvider),
+		PollInterval:            time.Duration(a.config.PollIntervalSeconds) * time.Second,
+		RequiredConfirmations:   a.config.RequiredConfirmations,
+		MaxRetries:              a.config.MaxRetries,
+		Store:                   a.config.ListenerStore,
+	}
+
+	listener, err := evm.NewTransactionListener(listenerConfig)
+	if err != nil {
+		a.updateHealth(false, "failed to create listener")
+		return fmt.Errorf("failed to create %s transaction listener: %w", a.config.BlockchainType, err)
+	}
+
+	a.listener = listener
+
+	// Start the listener
+	if err := a.listener.Start(); err != nil {
+		a.updateHealth(false, "failed to start listener")
+		return fmt.Errorf("failed to start %s listener: %w", a.config.BlockchainType, err)
+	}
+
+	a.updateHealth(true, "running")
+	return nil
+}
+
+// evmTokenContracts builds the token contracts of the listener from token symbols, addresses and decimals
+func evmTokenContracts(addresses map[string]string, decimals map[string]uint8) (map[string]evm.TokenContractInfo, error) {
+	contracts := make(map[string]evm.TokenContractInfo, len(addresses))
+	for symbol, contractAddress := range addresses {
+		if !common.IsHexAddress(contractAddress) {
+			return nil, fmt.Errorf("invalid %s contract address: %s", symbol, contractAddress)
+		}
+
+		tokenDecimals, ok := decimals[symbol]
+		if !ok {
+			tokenDecimals = 18 // ERC-20 default
+		}
+
+		contracts[symbol] = evm.TokenContractInfo{
+			ContractAddress: common.HexToAddress(contractAddress),
+			Symbol:          symbol,
+			Decimals:        tokenDecimals,
+		}
+	}
+	return contracts, nil
+}
+
+// evmTokenContractProvider adapts the token provider of the listener configuration, nil stays nil
+func evmTokenContractProvider(provider ports.TokenProvider) evm.TokenContractProvider {
+	if provider == nil {
+		return nil
+	}
+
+	return func(ctx context.Context) (map[string]evm.TokenContractInfo, error) {
+		tokens, err := provider(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses := make(map[string]string, len(tokens))
+		decimals := make(map[string]uint8, len(tokens))
+		for _, token := range tokens {
+			addresses[token.Symbol] = token.Address
+			decimals[token.Symbol] = token.Decimals
+		}
+		return evmTokenContracts(addresses, decimals)
+	}
+}
+
+// Stop gracefully stops the listener and cleans up resources
+func (a *EVMListenerAdapter) Stop(ctx context.Context) error {
+	if a.listener == nil {
+		return fmt.Errorf("listener not initialized")
+	}
+
+	err := a.listener.Stop()
+	if err != nil {
+		a.updateHealth(false, "failed to stop")
+		return fmt.Errorf("failed to stop %s listener: %w", a.config.BlockchainType, err)
+	}
+
+	a.updateHealth(false, "stopped")
+	return nil
+}
+
+// IsRunning returns whether the listener is currently active
+func (a *EVMListenerAdapter) IsRunning() bool {
+	if a.listener == nil {
+		return false
+	}
+	return a.listener.IsRunning()
+}
+
+// GetBlockchainType returns the type of blockchain this listener monitors
+func (a *EVMListenerAdapter) GetBlockchainType() ports.BlockchainType {
+	return a.config.BlockchainType
+}
+
+// GetWalletAddress returns the wallet address being monitored
+func (a *EVMListenerAdapter) GetWalletAddress() string {
+	return a.config.WalletAddress
+}
+
+// SetConfirmationHandler sets the callback for when payments are confirmed
+func (a *EVMListenerAdapter) SetConfirmationHandler(handler ports.PaymentConfirmationHandler) {
+	a.handlerMu.Lock()
+	defer a.handlerMu.Unlock()
+	a.confirmationHandler = handler
+}
+
+// GetSupportedTokens returns a list of token symbols supported by this listener
+func (a *EVMListenerAdapter) GetSupportedTokens() []string {
+	tokens := make([]string, 0, len(a.config.SupportedTokens))
+	for symbol := range a.config.SupportedTokens {
+		tokens = append(tokens, symbol)
+	}
+	return tokens
+}
+
+// GetListenerHealth returns health status and metrics
+func (a *EVMListenerAdapter) GetListenerHealth() ports.ListenerHealth {
+	a.healthMu.RLock()
+	defer a.healthMu.RUnlock()
+
+	health := a.health
+	health.LastActivityTimestamp = a.lastActivity.Unix()
+	health.ErrorCount = a.errorCount
+	health.SuccessfulConfirmations = a.successCount
+	if a.listener != nil {
+		health.LastProcessedBlock = a.listener.GetLastProcessedBlock()
+	}
+
+	return health
+}
+
+// handlePaymentConfirmation is the adapter function that converts the EVM-specific callback to the generic port interface
+func (a *EVMListenerAdapter) handlePaymentConfirmation(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
+	a.handlerMu.RLock()
+	handler := a.confirmationHandler
+	a.handlerMu.RUnlock()
+
+	if handler == nil {
+		// No handler set, just log and return
+		fmt.Printf("Warning: Payment confirmed but no handler set: %s\n", paymentID)
+		return nil
+	}
+
+	// Update last activity
+	a.lastActivity = time.Now()
+
+	// Create generic payment confirmation from EVM-specific data
+	confirmation := ports.PaymentConfirmation{
+		PaymentID:      paymentID,
+		TxHash:         txHash,
+		Amount:         amount,
+		TokenSymbol:    tokenSymbol,
+		BlockchainType: a.config.BlockchainType,
+		Recipient:      a.config.WalletAddress,
+		Sender:         fromAddress,
+	}
+
+	// Call the handler with context
+	ctx := context.Background()
+	err := handler(ctx, confirmation)
+
+	if err != nil {
+		a.incrementErrorCount()
+		return fmt.Errorf("confirmation handler failed: %w", err)
+	}
+
+	a.incrementSuccessCount()
+	return nil
+}
+
+// updateHealth updates the health status
+func (a *EVMListenerAdapter) updateHealth(isHealthy bool, status string) {
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+
+	a.health.IsHealthy = isHealthy
+	a.health.ConnectionStatus = status
+}
+
+// incrementErrorCount increments the error counter
+func (a *EVMListenerAdapter) incrementErrorCount() {
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+	a.errorCount++
+}
+
+// incrementSuccessCount increments the success counter
+func (a *EVMListenerAdapter) incrementSuccessCount() {
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+	a.successCount++
+}
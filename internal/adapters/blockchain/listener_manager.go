@@ -66,6 +66,34 @@ func (m *ListenerManager) AddListener(listener ports.BlockchainListener) error {
 	return nil
 }
 
+// AddListenerFromConfig creates the adapter for config.BlockchainType and adds it to the manager
+func (m *ListenerManager) AddListenerFromConfig(config ports.BlockchainListenerConfig) error {
+	listener, err := NewBlockchainListener(config)
+	if err != nil {
+		return err
+	}
+
+	return m.AddListener(listener)
+}
+
+// NewBlockchainListener creates the listener adapter for the configured blockchain
+func NewBlockchainListener(config ports.BlockchainListenerConfig) (ports.BlockchainListener, error) {
+	switch config.BlockchainType {
+	case ports.BlockchainTypeSolana:
+		return NewSolanaListenerAdapter(config)
+	case ports.BlockchainTypeBSC:
+		return NewBSCListenerAdapter(config)
+	case ports.BlockchainTypeTRON:
+		return NewTRONListenerAdapter(config)
+	default:
+		// Any other EVM network is configured by name and chain ID
+		if config.ChainID != 0 {
+			return NewEVMListenerAdapter(config)
+		}
+		return nil, fmt.Errorf("unsupported blockchain type: %s", config.BlockchainType)
+	}
+}
+
 // RemoveListener removes a blockchain listener from the manager
 func (m *ListenerManager) RemoveListener(blockchainType ports.BlockchainType) error {
 	m.mu.Lock()
@@ -110,6 +110,7 @@ func (a *SolanaListenerAdapter) Start(ctx context.Context) error {
 		SupportedTokenMints:  supportedTokenMints,
 		PollInterval:         pollInterval,
 		MaxRetries:           a.config.MaxRetries,
+		Store:                a.config.ListenerStore,
 	}
 
 	listener, err := solana.NewTransactionListener(listenerConfig)
@@ -189,12 +190,15 @@ func (a *SolanaListenerAdapter) GetListenerHealth() ports.ListenerHealth {
 	health.LastActivityTimestamp = a.lastActivity.Unix()
 	health.ErrorCount = a.errorCount
 	health.SuccessfulConfirmations = a.successCount
+	if a.listener != nil {
+		health.LastProcessedBlock = a.listener.GetLastProcessedSlot()
+	}
 
 	return health
 }
 
 // handlePaymentConfirmation is the adapter function that converts Solana-specific callback to the generic port interface
-func (a *SolanaListenerAdapter) handlePaymentConfirmation(paymentID string, txHash string, amount decimal.Decimal, tokenMint string) error {
+func (a *SolanaListenerAdapter) handlePaymentConfirmation(paymentID string, txHash string, amount decimal.Decimal, tokenMint string, fromAddress string) error {
 	a.handlerMu.RLock()
 	handler := a.confirmationHandler
 	a.handlerMu.RUnlock()
@@ -216,7 +220,7 @@ func (a *SolanaListenerAdapter) handlePaymentConfirmation(paymentID string, txHa
 		TokenSymbol:    tokenMint,
 		BlockchainType: ports.BlockchainTypeSolana,
 		Recipient:      a.wallet.GetAddress(),
-		// Sender is not easily available from the callback, would need to parse transaction
+		Sender:         fromAddress,
 		// BlockNumber and Timestamp would require additional RPC calls
 		// For MVP, we'll leave these empty and can enhance later
 	}
@@ -0,0 +1,260 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/tron"
+	"github.com/hxuan190/stable_payment_gateway/internal/ports"
+)
+
+// TRONListenerAdapter adapts the TRON TransactionListener to implement BlockchainListener interface
+// This implements the ADAPTER pattern in Ports & Adapters (Hexagonal Architecture)
+type TRONListenerAdapter struct {
+	// Underlying TRON listener implementation
+	listener *tron.TransactionListener
+
+	// Configuration
+	config ports.BlockchainListenerConfig
+	client *tron.Client
+
+	// Confirmation handler
+	confirmationHandler ports.PaymentConfirmationHandler
+	handlerMu           sync.RWMutex
+
+	// Health tracking
+	health       ports.ListenerHealth
+	healthMu     sync.RWMutex
+	lastActivity time.Time
+	errorCount   uint64
+	successCount uint64
+}
+
+// NewTRONListenerAdapter creates a new TRON blockchain listener adapter
+// The listener only watches the wallet, so no private key is needed
+func NewTRONListenerAdapter(config ports.BlockchainListenerConfig) (*TRONListenerAdapter, error) {
+	if config.BlockchainType != ports.BlockchainTypeTRON {
+		return nil, fmt.Errorf("invalid blockchain type: expected %s, got %s", ports.BlockchainTypeTRON, config.BlockchainType)
+	}
+
+	if _, err := tron.ParseAddress(config.WalletAddress); err != nil {
+		return nil, fmt.Errorf("invalid TRON wallet address: %w", err)
+	}
+
+	// Create TRON client
+	client, err := tron.NewClient(tron.ClientConfig{
+		RPCURL: config.RPCURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TRON client: %w", err)
+	}
+
+	adapter := &TRONListenerAdapter{
+		config:       config,
+		client:       client,
+		lastActivity: time.Now(),
+		health: ports.ListenerHealth{
+			IsHealthy:        true,
+			ConnectionStatus: "initialized",
+		},
+	}
+
+	// Set confirmation handler if provided
+	if config.ConfirmationHandler != nil {
+		adapter.confirmationHandler = config.ConfirmationHandler
+	}
+
+	return adapter, nil
+}
+
+// Start begins listening for transactions on the TRON blockchain
+func (a *TRONListenerAdapter) Start(ctx context.Context) error {
+	// Build supported token contracts map
+	supportedTokenContracts := make(map[string]tron.TokenContractInfo)
+	for symbol, contractAddress := range a.config.SupportedTokens {
+		addr, err := tron.ParseAddress(contractAddress)
+		if err != nil {
+			return fmt.Errorf("invalid %s contract address: %w", symbol, err)
+		}
+
+		// TRC-20 USDT and USDC both use 6 decimals
+		supportedTokenContracts[symbol] = tron.TokenContractInfo{
+			ContractAddress: addr,
+			Symbol:          symbol,
+			Decimals:        6,
+		}
+	}
+
+	// Set poll interval
+	pollInterval := time.Duration(a.config.PollIntervalSeconds) * time.Second
+	if pollInterval == 0 {
+		pollInterval = 10 * time.Second // TRON block time is ~3 seconds
+	}
+
+	// Set required confirmations
+	requiredConfirmations := a.config.RequiredConfirmations
+	if requiredConfirmations == 0 {
+		requiredConfirmations = 19 // Solidified block
+	}
+
+	// Create the underlying TRON listener with our adapter's callback
+	listenerConfig := tron.ListenerConfig{
+		Client:                  a.client,
+		WalletAddress:           a.config.WalletAddress,
+		ConfirmationCallback:    a.handlePaymentConfirmation,
+		SupportedTokenContracts: supportedTokenContracts,
+		PollInterval:            pollInterval,
+		RequiredConfirmations:   requiredConfirmations,
+		MaxRetries:              a.config.MaxRetries,
+		Store:                   a.config.ListenerStore,
+	}
+
+	listener, err := tron.NewTransactionListener(listenerConfig)
+	if err != nil {
+		a.updateHealth(false, "failed to create listener")
+		return fmt.Errorf("failed to create TRON transaction listener: %w", err)
+	}
+
+	a.listener = listener
+
+	// Start the listener
+	if err := a.listener.Start(); err != nil {
+		a.updateHealth(false, "failed to start listener")
+		return fmt.Errorf("failed to start TRON listener: %w", err)
+	}
+
+	a.updateHealth(true, "running")
+	return nil
+}
+
+// Stop gracefully stops the listener and cleans up resources
+func (a *TRONListenerAdapter) Stop(ctx context.Context) error {
+	if a.listener == nil {
+		return fmt.Errorf("listener not initialized")
+	}
+
+	err := a.listener.Stop()
+	if err != nil {
+		a.updateHealth(false, "failed to stop")
+		return fmt.Errorf("failed to stop TRON listener: %w", err)
+	}
+
+	a.updateHealth(false, "stopped")
+	return nil
+}
+
+// IsRunning returns whether the listener is currently active
+func (a *TRONListenerAdapter) IsRunning() bool {
+	if a.listener == nil {
+		return false
+	}
+	return a.listener.IsRunning()
+}
+
+// GetBlockchainType returns the type of blockchain this listener monitors
+func (a *TRONListenerAdapter) GetBlockchainType() ports.BlockchainType {
+	return ports.BlockchainTypeTRON
+}
+
+// GetWalletAddress returns the wallet address being monitored
+func (a *TRONListenerAdapter) GetWalletAddress() string {
+	return a.config.WalletAddress
+}
+
+// SetConfirmationHandler sets the callback for when payments are confirmed
+func (a *TRONListenerAdapter) SetConfirmationHandler(handler ports.PaymentConfirmationHandler) {
+	a.handlerMu.Lock()
+	defer a.handlerMu.Unlock()
+	a.confirmationHandler = handler
+}
+
+// GetSupportedTokens returns a list of token symbols supported by this listener
+func (a *TRONListenerAdapter) GetSupportedTokens() []string {
+	tokens := make([]string, 0, len(a.config.SupportedTokens))
+	for symbol := range a.config.SupportedTokens {
+		tokens = append(tokens, symbol)
+	}
+	return tokens
+}
+
+// GetListenerHealth returns health status and metrics
+func (a *TRONListenerAdapter) GetListenerHealth() ports.ListenerHealth {
+	a.healthMu.RLock()
+	defer a.healthMu.RUnlock()
+
+	health := a.health
+	health.LastActivityTimestamp = a.lastActivity.Unix()
+	health.ErrorCount = a.errorCount
+	health.SuccessfulConfirmations = a.successCount
+	if a.listener != nil {
+		health.LastProcessedBlock = a.listener.GetLastProcessedBlock()
+	}
+
+	return health
+}
+
+// handlePaymentConfirmation is the adapter function that converts the TRON-specific callback to the generic port interface
+func (a *TRONListenerAdapter) handlePaymentConfirmation(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
+	a.handlerMu.RLock()
+	handler := a.confirmationHandler
+	a.handlerMu.RUnlock()
+
+	if handler == nil {
+		// No handler set, just log and return
+		fmt.Printf("Warning: Payment confirmed but no handler set: %s\n", paymentID)
+		return nil
+	}
+
+	// Update last activity
+	a.lastActivity = time.Now()
+
+	// Create generic payment confirmation from TRON-specific data
+	confirmation := ports.PaymentConfirmation{
+		PaymentID:      paymentID,
+		TxHash:         txHash,
+		Amount:         amount,
+		TokenSymbol:    tokenSymbol,
+		BlockchainType: ports.BlockchainTypeTRON,
+		Recipient:      a.config.WalletAddress,
+		Sender:         fromAddress,
+	}
+
+	// Call the handler with context
+	ctx := context.Background()
+	err := handler(ctx, confirmation)
+
+	if err != nil {
+		a.incrementErrorCount()
+		return fmt.Errorf("confirmation handler failed: %w", err)
+	}
+
+	a.incrementSuccessCount()
+	return nil
+}
+
+// updateHealth updates the health status
+func (a *TRONListenerAdapter) updateHealth(isHealthy bool, status string) {
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+
+	a.health.IsHealthy = isHealthy
+	a.health.ConnectionStatus = status
+}
+
+// incrementErrorCount increments the error counter
+func (a *TRONListenerAdapter) incrementErrorCount() {
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+	a.errorCount++
+}
+
+// incrementSuccessCount increments the success counter
+func (a *TRONListenerAdapter) incrementSuccessCount() {
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+	a.successCount++
+}
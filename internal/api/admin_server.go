@@ -19,11 +19,15 @@ import (
 	compliancerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/repository"
 	complianceservice "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/service"
 	infrastructurerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/repository"
+	infrastructureservice "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/service"
 	ledgerrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/repository"
+	ledgerservice "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/service"
 	merchanthandler "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/handler"
 	merchantrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/repository"
 	merchantservice "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/service"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/legacy"
 	paymentrepo "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/repository"
+	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
 	payoutrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/repository"
 	payoutservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/service"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/cache"
@@ -253,6 +257,58 @@ func (s *AdminServer) setupRoutes(router *gin.Engine) {
 		s.gormDB,
 	)
 
+	// Late payments are resolved here: accepted at a re-quoted rate or refunded by the worker
+	ledgerService := ledgerservice.NewLedgerService(
+		ledgerrepository.NewLedgerRepository(s.gormDB),
+		balanceRepo,
+		s.gormDB,
+	)
+	testModeLedgerService := ledgerservice.NewTestModeLedgerService(
+		ledgerrepository.NewLedgerRepository(s.gormDB),
+		balanceRepo,
+		s.gormDB,
+	)
+	exchangeRateService := infrastructureservice.NewExchangeRateService(
+		s.config.ExchangeRate.PrimaryAPI,
+		s.config.ExchangeRate.SecondaryAPI,
+		time.Duration(s.config.ExchangeRate.CacheTTL)*time.Second,
+		time.Duration(s.config.ExchangeRate.Timeout)*time.Second,
+		s.cache,
+		logger.GetLogger().Logger,
+	)
+	refundService := paymentservice.NewRefundService(
+		newPaymentRepo,
+		paymentrepo.NewPostgresPaymentTransferRepository(s.gormDB),
+		paymentrepo.NewPostgresRefundRepository(s.gormDB),
+		ledgerService,
+		paymentservice.RefundServiceConfig{},
+		logger.GetLogger().Logger,
+	)
+	tokenRegistry := paymentservice.NewTokenRegistry(
+		paymentrepo.NewPostgresTokenRepository(s.gormDB),
+		paymentservice.DefaultTokenCacheTTL,
+		logger.GetLogger().Logger,
+	)
+	paymentService := paymentservice.NewPaymentService(
+		newPaymentRepo,
+		paymentrepo.NewPostgresPaymentTransferRepository(s.gormDB),
+		merchantRepo,
+		legacy.NewExchangeRateServiceAdapter(exchangeRateService),
+		nil,
+		nil,
+		paymentservice.PaymentServiceConfig{
+			RedisClient:   redisClient,
+			LedgerService: ledgerService,
+			TokenRegistry: tokenRegistry,
+
+			TestModeLedgerService: testModeLedgerService,
+
+			LatePaymentRefunder:       refundService,
+			InboundTransferRepository: paymentrepo.NewPostgresInboundTransferRepository(s.gormDB),
+		},
+		logger.GetLogger().Logger,
+	)
+
 	// Health check handler (no auth required)
 	healthHandler := handler.NewHealthHandler(
 		s.gormDB,
@@ -296,6 +352,16 @@ func (s *AdminServer) setupRoutes(router *gin.Engine) {
 				walletBalanceGetter,
 			)
 
+			listenerAdminHandler := handler.NewListenerAdminHandler(
+				infrastructurerepository.NewListenerCursorRepository(s.gormDB),
+			)
+
+			paymentAdminHandler := handler.NewPaymentAdminHandler(paymentService)
+			latePaymentAdminHandler := handler.NewLatePaymentAdminHandler(paymentService)
+
+			inboundTransferAdminHandler := handler.NewInboundTransferAdminHandler(paymentService, auditRepo)
+			tokenAdminHandler := handler.NewTokenAdminHandler(tokenRegistry, auditRepo)
+
 			// Create storage adapter for KYC handler
 			storageAdapter := &kycStorageAdapter{storage: storageService}
 			kycHandler := merchanthandler.NewKYCHandler(storageAdapter, kycDocumentRepo)
@@ -303,8 +369,39 @@ func (s *AdminServer) setupRoutes(router *gin.Engine) {
 			// Merchant management routes
 			merchants := protected.Group("/merchants")
 			{
-				merchants.GET("", adminHandler.ListMerchants)   // List all merchants
-				merchants.GET("/:id", adminHandler.GetMerchant) // Get merchant details
+				merchants.GET("", adminHandler.ListMerchants)                                // List all merchants
+				merchants.GET("/:id", adminHandler.GetMerchant)                              // Get merchant details
+				merchants.PUT("/:id/payment-tolerance", adminHandler.UpdatePaymentTolerance) // Update under/overpayment tolerance
+				merchants.PUT("/:id/address-mode", adminHandler.UpdateAddressMode)           // Select memo or deposit address matching
+
+				merchants.PUT("/:id/late-payment-policy", adminHandler.UpdateLatePaymentPolicy) // Accept, refund or review late payments
+				merchants.PUT("/:id/quote-spread", adminHandler.UpdateQuoteSpread)              // Spread deducted from the rate on quotes
+				merchants.POST("/:id/sandbox", adminHandler.CreateSandboxMerchant)              // Create the sandbox merchant and its sk_test_ API key
+			}
+
+			// Payment search and late payment routes (transfers received after a payment expired)
+			payments := protected.Group("/payments")
+			{
+				payments.GET("", paymentAdminHandler.SearchPayments)                                   // Search payments of every merchant
+				payments.GET("/late", latePaymentAdminHandler.ListLatePayments)                        // List late payments
+				payments.POST("/:id/late-payment/resolve", latePaymentAdminHandler.ResolveLatePayment) // Accept or refund a late payment
+			}
+
+			// Inbound transfer routes (unmatched deposit queue)
+			inboundTransfers := protected.Group("/inbound-transfers")
+			{
+				inboundTransfers.GET("", inboundTransferAdminHandler.ListInboundTransfers)                               // List inbound transfers, unmatched by default
+				inboundTransfers.GET("/:id", inboundTransferAdminHandler.GetInboundTransfer)                             // Get inbound transfer details
+				inboundTransfers.POST("/:id/resolve", inboundTransferAdminHandler.ResolveInboundTransfer)                // Attach, refund or book as treasury
+				inboundTransfers.POST("/:id/complete-refund", inboundTransferAdminHandler.CompleteInboundTransferRefund) // Record the refund of a refund_pending transfer
+			}
+
+			// Token registry routes (tokens accepted on each chain)
+			tokens := protected.Group("/tokens")
+			{
+				tokens.GET("", tokenAdminHandler.ListTokens)        // List every token, disabled ones included
+				tokens.POST("", tokenAdminHandler.CreateToken)      // Add a token to a chain
+				tokens.PATCH("/:id", tokenAdminHandler.UpdateToken) // Change address, pricing, limits or enable/disable
 			}
 
 			// KYC management routes
@@ -330,6 +427,10 @@ func (s *AdminServer) setupRoutes(router *gin.Engine) {
 			{
 				system.GET("/stats", adminHandler.GetStats)            // System-wide statistics
 				system.GET("/stats/daily", adminHandler.GetDailyStats) // Daily statistics
+
+				system.GET("/listeners", listenerAdminHandler.ListCursors)                  // Listener cursors
+				system.POST("/listeners/:chain/rescan", listenerAdminHandler.RequestRescan) // Force a block range or tx rescan
+				system.GET("/listeners/rescans/:id", listenerAdminHandler.GetRescan)        // Rescan request status
 			}
 
 			// Compliance routes
@@ -3,8 +3,10 @@ package dto
 import (
 	"time"
 
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
 	compliancedomain "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/domain"
 	merchantDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/domain"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
 	payoutDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/domain"
 	"github.com/shopspring/decimal"
 )
@@ -418,6 +420,306 @@ type UpgradeMerchantTierRequest struct {
 	Tier string `json:"tier" binding:"required,oneof=tier1 tier2 tier3" example:"tier2"`
 }
 
+// UpdatePaymentToleranceRequest represents a request to update a merchant's payment amount tolerance
+type UpdatePaymentToleranceRequest struct {
+	UnderpaymentPercentage decimal.Decimal `json:"underpayment_percentage" example:"0.01"`
+	OverpaymentPercentage  decimal.Decimal `json:"overpayment_percentage" example:"0.01"`
+}
+
+// UpdateQuoteSpreadRequest represents a request to update the spread deducted from the market rate on a merchant's quotes
+type UpdateQuoteSpreadRequest struct {
+	SpreadPercentage decimal.Decimal `json:"spread_percentage" example:"0.005"`
+}
+
+// UpdateAddressModeRequest represents a request to select how a merchant's payments on a chain are matched
+type UpdateAddressModeRequest struct {
+	Chain string `json:"chain" binding:"required,oneof=solana bsc" example:"bsc"`
+	Mode  string `json:"mode" binding:"required,oneof=memo deposit" example:"deposit"`
+}
+
+// UpdateLatePaymentPolicyRequest represents a request to select what happens to a merchant's late payments
+type UpdateLatePaymentPolicyRequest struct {
+	Policy string `json:"policy" binding:"required,oneof=accept refund review" example:"review"`
+}
+
+// Admin Late Payment DTOs
+
+// ListLatePaymentsQuery represents query parameters for listing late payments
+type ListLatePaymentsQuery struct {
+	Resolution string `form:"resolution" binding:"omitempty,oneof=pending_review accepted refunding refunded" example:"pending_review"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+	Offset     int    `form:"offset" binding:"omitempty,min=0" example:"0"`
+}
+
+// LatePaymentItem represents a payment that received transfers after it expired
+type LatePaymentItem struct {
+	ID             string          `json:"id"`
+	MerchantID     string          `json:"merchant_id"`
+	Status         string          `json:"status"`
+	LateResolution string          `json:"late_resolution"`
+	Chain          string          `json:"chain"`
+	Currency       string          `json:"currency"`
+	AmountCrypto   decimal.Decimal `json:"amount_crypto"`
+	AmountReceived decimal.Decimal `json:"amount_received"`
+	AmountVND      decimal.Decimal `json:"amount_vnd"`
+	ExchangeRate   decimal.Decimal `json:"exchange_rate"`
+	FromAddress    string          `json:"from_address,omitempty"`
+	TxHash         string          `json:"tx_hash,omitempty"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	LatePaidAt     *time.Time      `json:"late_paid_at,omitempty"`
+}
+
+// ListLatePaymentsResponse represents the response for listing late payments
+type ListLatePaymentsResponse struct {
+	Payments []LatePaymentItem `json:"payments"`
+	Total    int64             `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+// ResolveLatePaymentRequest represents a request to accept or refund a late payment held for review
+type ResolveLatePaymentRequest struct {
+	Action string `json:"action" binding:"required,oneof=accept refund" example:"accept"`
+}
+
+// Admin Payment Search DTOs
+
+// SearchPaymentsQuery represents the filters, sort order and cursor of an admin payment search
+type SearchPaymentsQuery struct {
+	MerchantID string `form:"merchant_id" binding:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+	Cursor    string `form:"cursor"`
+	SortBy    string `form:"sort_by" binding:"omitempty,oneof=created_at amount_vnd" example:"created_at"`
+	SortOrder string `form:"sort_order" binding:"omitempty,oneof=asc desc" example:"desc"`
+
+	Status   string `form:"status" example:"completed,overpaid"` // Comma-separated list of statuses
+	Chain    string `form:"chain" binding:"omitempty,max=20" example:"solana"`
+	Currency string `form:"currency" binding:"omitempty,max=10" example:"USDT"`
+
+	CreatedFrom   time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo     time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	ConfirmedFrom time.Time `form:"confirmed_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	ConfirmedTo   time.Time `form:"confirmed_to" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	OrderID     string `form:"order_id" binding:"omitempty,max=255"`
+	TxHash      string `form:"tx_hash" binding:"omitempty,max=255"`
+	FromAddress string `form:"from_address" binding:"omitempty,max=255"`
+
+	MinAmountVND float64 `form:"min_amount_vnd" binding:"omitempty,gte=0"`
+	MaxAmountVND float64 `form:"max_amount_vnd" binding:"omitempty,gte=0"`
+
+	// Amount range in the pricing currency, min_amount and max_amount require pricing_currency
+	PricingCurrency string  `form:"pricing_currency" binding:"omitempty,len=3"`
+	MinAmount       float64 `form:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount       float64 `form:"max_amount" binding:"omitempty,gte=0"`
+}
+
+// ToPaymentSearch converts the query to a payment search, across every merchant when no merchant is given
+// Metadata filters come from the metadata[key]=value query parameters
+func (q SearchPaymentsQuery) ToPaymentSearch(metadata map[string]string) (paymentDomain.PaymentSearch, error) {
+	statuses, err := paymentDomain.ParsePaymentStatuses(q.Status)
+	if err != nil {
+		return paymentDomain.PaymentSearch{}, err
+	}
+
+	return paymentDomain.PaymentSearch{
+		Filter: paymentDomain.PaymentFilter{
+			MerchantID:    q.MerchantID,
+			Statuses:      statuses,
+			Chain:         paymentDomain.Chain(q.Chain),
+			Currency:      q.Currency,
+			CreatedFrom:   q.CreatedFrom,
+			CreatedTo:     q.CreatedTo,
+			ConfirmedFrom: q.ConfirmedFrom,
+			ConfirmedTo:   q.ConfirmedTo,
+			OrderID:       q.OrderID,
+			TxHash:        q.TxHash,
+			FromAddress:   q.FromAddress,
+			MinAmountVND:  decimal.NewFromFloat(q.MinAmountVND),
+			MaxAmountVND:  decimal.NewFromFloat(q.MaxAmountVND),
+			Metadata:      metadata,
+
+			PricingCurrency:  q.PricingCurrency,
+			MinPricingAmount: decimal.NewFromFloat(q.MinAmount),
+			MaxPricingAmount: decimal.NewFromFloat(q.MaxAmount),
+		},
+		SortBy:     paymentDomain.PaymentSortField(q.SortBy),
+		Descending: q.SortOrder != "asc",
+		Cursor:     q.Cursor,
+		Limit:      q.Limit,
+	}, nil
+}
+
+// PaymentSearchItem represents a payment found by an admin search
+type PaymentSearchItem struct {
+	ID             string          `json:"id"`
+	MerchantID     string          `json:"merchant_id"`
+	Status         string          `json:"status"`
+	Chain          string          `json:"chain"`
+	Currency       string          `json:"currency"`
+	AmountVND      decimal.Decimal `json:"amount_vnd"`
+	AmountCrypto   decimal.Decimal `json:"amount_crypto"`
+	AmountReceived decimal.Decimal `json:"amount_received"`
+	OrderID        string          `json:"order_id,omitempty"`
+	TxHash         string          `json:"tx_hash,omitempty"`
+	FromAddress    string          `json:"from_address,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ConfirmedAt    *time.Time      `json:"confirmed_at,omitempty"`
+}
+
+// SearchPaymentsResponse represents a page of an admin payment search
+type SearchPaymentsResponse struct {
+	Payments   []PaymentSearchItem `json:"payments"`
+	NextCursor *string             `json:"next_cursor,omitempty"`
+	HasMore    bool                `json:"has_more"`
+}
+
+// Admin Inbound Transfer DTOs
+
+// ListInboundTransfersQuery represents query parameters for listing inbound transfers
+type ListInboundTransfersQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=matched unmatched attached refund_pending refunded treasury" example:"unmatched"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+	Offset int    `form:"offset" binding:"omitempty,min=0" example:"0"`
+}
+
+// InboundTransferItem represents a stablecoin transfer received on a watched address
+type InboundTransferItem struct {
+	ID              string          `json:"id"`
+	Chain           string          `json:"chain"`
+	Network         string          `json:"network"`
+	TxHash          string          `json:"tx_hash"`
+	BlockNumber     *int64          `json:"block_number,omitempty"`
+	BlockTime       *time.Time      `json:"block_time,omitempty"`
+	FromAddress     string          `json:"from_address,omitempty"`
+	ToAddress       string          `json:"to_address"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`
+	TokenAddress    string          `json:"token_address,omitempty"`
+	Reference       string          `json:"reference,omitempty"`
+	PaymentID       string          `json:"payment_id,omitempty"`
+	Status          string          `json:"status"`
+	UnmatchedReason string          `json:"unmatched_reason,omitempty"`
+	UnmatchedDetail string          `json:"unmatched_detail,omitempty"`
+	ResolvedBy      string          `json:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time      `json:"resolved_at,omitempty"`
+	ResolutionNote  string          `json:"resolution_note,omitempty"`
+	RefundTxHash    string          `json:"refund_tx_hash,omitempty"`
+	RefundedBy      string          `json:"refunded_by,omitempty"`
+	RefundedAt      *time.Time      `json:"refunded_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// ListInboundTransfersResponse represents the response for listing inbound transfers
+type ListInboundTransfersResponse struct {
+	Transfers []InboundTransferItem `json:"transfers"`
+	Total     int64                 `json:"total"`
+	Limit     int                   `json:"limit"`
+	Offset    int                   `json:"offset"`
+}
+
+// ResolveInboundTransferRequest represents an operator resolution of an unmatched transfer
+// Attaching requires the payment the transfer is counted toward
+type ResolveInboundTransferRequest struct {
+	Action    string `json:"action" binding:"required,oneof=attach refund treasury" example:"attach"`
+	PaymentID string `json:"payment_id,omitempty" binding:"omitempty,uuid" example:"3f8a4c1e-9b2d-4e6f-8a1c-5d7e9f0b2c4a"`
+	Note      string `json:"note,omitempty" binding:"omitempty,max=500" example:"Customer sent the payment without a memo"`
+}
+
+// CompleteInboundTransferRefundRequest records the transaction that sent a transfer marked for refund back to the sender
+type CompleteInboundTransferRefundRequest struct {
+	RefundTxHash string `json:"refund_tx_hash" binding:"required,max=255" example:"5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW"`
+}
+
+// Admin Listener Management DTOs
+
+// ListenerCursorItem represents the persisted cursor of a blockchain listener
+type ListenerCursorItem struct {
+	Chain         string    `json:"chain"`
+	WalletAddress string    `json:"wallet_address"`
+	BlockNumber   int64     `json:"block_number"`
+	Signature     string    `json:"signature,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ListListenerCursorsResponse represents the response for listing listener cursors
+type ListListenerCursorsResponse struct {
+	Cursors []ListenerCursorItem `json:"cursors"`
+	Total   int                  `json:"total"`
+}
+
+// RescanListenerRequest represents a request to force a listener to rescan a block range or a single transaction
+// On Solana the block range is a slot range and the tx hash is a signature
+type RescanListenerRequest struct {
+	FromBlock *int64 `json:"from_block,omitempty" binding:"omitempty,min=0" example:"41200000"`
+	ToBlock   *int64 `json:"to_block,omitempty" binding:"omitempty,min=0" example:"41200100"`
+	TxHash    string `json:"tx_hash,omitempty" binding:"omitempty,max=128" example:"0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"`
+}
+
+// RescanRequestResponse represents a queued or processed listener rescan
+type RescanRequestResponse struct {
+	ID           string     `json:"id"`
+	Chain        string     `json:"chain"`
+	FromBlock    *int64     `json:"from_block,omitempty"`
+	ToBlock      *int64     `json:"to_block,omitempty"`
+	TxHash       string     `json:"tx_hash,omitempty"`
+	Status       string     `json:"status"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	RequestedBy  string     `json:"requested_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+}
+
+// Admin Token Registry DTOs
+
+// TokenItem represents a token of the registry
+type TokenItem struct {
+	ID          string          `json:"id"`
+	Chain       string          `json:"chain"`
+	Symbol      string          `json:"symbol"`
+	Address     string          `json:"address"`
+	Decimals    int             `json:"decimals"`
+	PegCurrency string          `json:"peg_currency"`
+	PriceSource string          `json:"price_source"`
+	Enabled     bool            `json:"enabled"`
+	MinAmount   decimal.Decimal `json:"min_amount"`
+	MaxAmount   decimal.Decimal `json:"max_amount"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ListTokensResponse represents the response for listing the token registry
+type ListTokensResponse struct {
+	Tokens []TokenItem `json:"tokens"`
+	Total  int         `json:"total"`
+}
+
+// CreateTokenRequest represents a request to add a token to the registry
+// Zero amount bounds leave payments of the token unbounded
+type CreateTokenRequest struct {
+	Chain       string          `json:"chain" binding:"required,max=20" example:"bsc"`
+	Symbol      string          `json:"symbol" binding:"required,max=10" example:"FDUSD"`
+	Address     string          `json:"address" binding:"required,max=100" example:"0xc5f0f7b66764F6ec8C8Dff7BA683102295E16409"`
+	Decimals    *int            `json:"decimals" binding:"required,min=0,max=36" example:"18"`
+	PegCurrency string          `json:"peg_currency,omitempty" binding:"omitempty,len=3" example:"USD"`
+	PriceSource string          `json:"price_source,omitempty" binding:"omitempty,oneof=usdt usdc peg" example:"usdt"`
+	Enabled     *bool           `json:"enabled,omitempty" example:"true"`
+	MinAmount   decimal.Decimal `json:"min_amount" example:"1"`
+	MaxAmount   decimal.Decimal `json:"max_amount" example:"50000"`
+}
+
+// UpdateTokenRequest represents an update of a registry token, omitted fields are left as they are
+type UpdateTokenRequest struct {
+	Address     *string          `json:"address,omitempty" binding:"omitempty,max=100"`
+	Decimals    *int             `json:"decimals,omitempty" binding:"omitempty,min=0,max=36"`
+	PegCurrency *string          `json:"peg_currency,omitempty" binding:"omitempty,len=3"`
+	PriceSource *string          `json:"price_source,omitempty" binding:"omitempty,oneof=usdt usdc peg"`
+	Enabled     *bool            `json:"enabled,omitempty" example:"false"`
+	MinAmount   *decimal.Decimal `json:"min_amount,omitempty"`
+	MaxAmount   *decimal.Decimal `json:"max_amount,omitempty"`
+}
+
 // GetComplianceMetricsResponse represents compliance metrics for a merchant
 type GetComplianceMetricsResponse struct {
 	MerchantID             string          `json:"merchant_id"`
@@ -431,6 +733,149 @@ type GetComplianceMetricsResponse struct {
 	LastScreeningDate      *time.Time      `json:"last_screening_date,omitempty"`
 }
 
+// ListenerCursorToItem converts a listener cursor to a list item DTO
+func ListenerCursorToItem(cursor *blockchainDomain.ListenerCursor) ListenerCursorItem {
+	return ListenerCursorItem{
+		Chain:         string(cursor.Chain),
+		WalletAddress: cursor.WalletAddress,
+		BlockNumber:   cursor.BlockNumber,
+		Signature:     cursor.GetSignature(),
+		UpdatedAt:     cursor.UpdatedAt,
+	}
+}
+
+// TokenToItem converts a registry token to a list item DTO
+func TokenToItem(token *paymentDomain.Token) TokenItem {
+	return TokenItem{
+		ID:          token.ID,
+		Chain:       string(token.Chain),
+		Symbol:      token.Symbol,
+		Address:     token.Address,
+		Decimals:    token.Decimals,
+		PegCurrency: token.PegCurrency,
+		PriceSource: string(token.PriceSource),
+		Enabled:     token.Enabled,
+		MinAmount:   token.MinAmount,
+		MaxAmount:   token.MaxAmount,
+		CreatedAt:   token.CreatedAt,
+		UpdatedAt:   token.UpdatedAt,
+	}
+}
+
+// PaymentToSearchItem converts a payment to a PaymentSearchItem
+func PaymentToSearchItem(payment *paymentDomain.Payment) PaymentSearchItem {
+	item := PaymentSearchItem{
+		ID:             payment.ID,
+		MerchantID:     payment.MerchantID,
+		Status:         string(payment.Status),
+		Chain:          string(payment.Chain),
+		Currency:       payment.Currency,
+		AmountVND:      payment.AmountVND,
+		AmountCrypto:   payment.AmountCrypto,
+		AmountReceived: payment.AmountReceived,
+		OrderID:        payment.GetOrderID(),
+		TxHash:         payment.GetTxHash(),
+		FromAddress:    payment.FromAddress.String,
+		CreatedAt:      payment.CreatedAt,
+	}
+
+	if payment.ConfirmedAt.Valid {
+		item.ConfirmedAt = &payment.ConfirmedAt.Time
+	}
+
+	return item
+}
+
+// LatePaymentToItem converts a late-paid payment to a list item DTO
+func LatePaymentToItem(payment *paymentDomain.Payment) LatePaymentItem {
+	item := LatePaymentItem{
+		ID:             payment.ID,
+		MerchantID:     payment.MerchantID,
+		Status:         string(payment.Status),
+		LateResolution: string(payment.GetLateResolution()),
+		Chain:          string(payment.Chain),
+		Currency:       payment.Currency,
+		AmountCrypto:   payment.AmountCrypto,
+		AmountReceived: payment.AmountReceived,
+		AmountVND:      payment.AmountVND,
+		ExchangeRate:   payment.ExchangeRate,
+		FromAddress:    payment.FromAddress.String,
+		TxHash:         payment.GetTxHash(),
+		ExpiresAt:      payment.ExpiresAt,
+	}
+
+	if payment.LatePaidAt.Valid {
+		item.LatePaidAt = &payment.LatePaidAt.Time
+	}
+
+	return item
+}
+
+// InboundTransferToItem converts an inbound transfer to a list item DTO
+func InboundTransferToItem(transfer *paymentDomain.InboundTransfer) InboundTransferItem {
+	item := InboundTransferItem{
+		ID:              transfer.ID,
+		Chain:           string(transfer.Chain),
+		Network:         transfer.Network,
+		TxHash:          transfer.TxHash,
+		FromAddress:     transfer.FromAddress.String,
+		ToAddress:       transfer.ToAddress,
+		Amount:          transfer.Amount,
+		Currency:        transfer.Currency,
+		TokenAddress:    transfer.TokenAddress.String,
+		Reference:       transfer.Reference.String,
+		PaymentID:       transfer.GetPaymentID(),
+		Status:          string(transfer.Status),
+		UnmatchedReason: string(transfer.GetUnmatchedReason()),
+		UnmatchedDetail: transfer.UnmatchedDetail.String,
+		ResolvedBy:      transfer.ResolvedBy.String,
+		ResolutionNote:  transfer.ResolutionNote.String,
+		RefundTxHash:    transfer.RefundTxHash.String,
+		RefundedBy:      transfer.RefundedBy.String,
+		CreatedAt:       transfer.CreatedAt,
+	}
+
+	if transfer.BlockNumber.Valid {
+		item.BlockNumber = &transfer.BlockNumber.Int64
+	}
+	if transfer.BlockTime.Valid {
+		item.BlockTime = &transfer.BlockTime.Time
+	}
+	if transfer.ResolvedAt.Valid {
+		item.ResolvedAt = &transfer.ResolvedAt.Time
+	}
+	if transfer.RefundedAt.Valid {
+		item.RefundedAt = &transfer.RefundedAt.Time
+	}
+
+	return item
+}
+
+// RescanRequestToResponse converts a rescan request to a response DTO
+func RescanRequestToResponse(request *blockchainDomain.RescanRequest) RescanRequestResponse {
+	response := RescanRequestResponse{
+		ID:           request.ID,
+		Chain:        string(request.Chain),
+		TxHash:       request.TxHash.String,
+		Status:       string(request.Status),
+		ErrorMessage: request.ErrorMessage.String,
+		RequestedBy:  request.RequestedBy,
+		CreatedAt:    request.CreatedAt,
+	}
+
+	if request.FromBlock.Valid {
+		response.FromBlock = &request.FromBlock.Int64
+	}
+	if request.ToBlock.Valid {
+		response.ToBlock = &request.ToBlock.Int64
+	}
+	if request.ProcessedAt.Valid {
+		response.ProcessedAt = &request.ProcessedAt.Time
+	}
+
+	return response
+}
+
 // TravelRuleDataToListItem converts a compliancedomain.TravelRuleData to TravelRuleDataItem
 func TravelRuleDataToListItem(data *compliancedomain.TravelRuleData) TravelRuleDataItem {
 	item := TravelRuleDataItem{
@@ -10,8 +10,8 @@ import (
 // CreatePaymentRequest represents the request to create a new payment
 type CreatePaymentRequest struct {
 	AmountVND   float64            `json:"amount_vnd" binding:"required,gt=0" validate:"required,gt=0"`
-	Currency    string             `json:"currency,omitempty" validate:"omitempty,oneof=USDT USDC BUSD"`
-	Chain       string             `json:"chain,omitempty" validate:"omitempty,oneof=solana bsc"`
+	Currency    string             `json:"currency,omitempty" validate:"omitempty,alphanum,max=10"`
+	Chain       string             `json:"chain,omitempty" validate:"omitempty,max=20"` // Supported chains are checked by the payment service
 	OrderID     string             `json:"order_id,omitempty" validate:"omitempty,max=255"`
 	Description string             `json:"description,omitempty" validate:"omitempty,max=1000"`
 	CallbackURL string             `json:"callback_url,omitempty" validate:"omitempty,url,max=500"`
@@ -291,6 +291,293 @@ func (h *AdminHandler) RejectKYC(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// UpdatePaymentTolerance updates a merchant's under/overpayment tolerance
+// PUT /api/admin/merchants/:id/payment-tolerance
+func (h *AdminHandler) UpdatePaymentTolerance(c *gin.Context) {
+	merchantID := c.Param("id")
+	if merchantID == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_MERCHANT_ID",
+			"Merchant ID is required",
+		))
+		return
+	}
+
+	var req dto.UpdatePaymentToleranceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	merchant, err := h.merchantService.UpdatePaymentTolerance(merchantID, req.UnderpaymentPercentage, req.OverpaymentPercentage)
+	if err != nil {
+		if errors.Is(err, merchantservice.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"MERCHANT_NOT_FOUND",
+				"Merchant not found",
+			))
+			return
+		}
+		if errors.Is(err, merchantservice.ErrInvalidTolerance) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"INVALID_TOLERANCE",
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"TOLERANCE_UPDATE_FAILED",
+			"Failed to update payment tolerance",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data: gin.H{
+			"merchant_id":                       merchant.ID,
+			"underpayment_tolerance_percentage": merchant.UnderpaymentTolerancePercentage,
+			"overpayment_tolerance_percentage":  merchant.OverpaymentTolerancePercentage,
+			"message":                           "Payment tolerance updated successfully",
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateQuoteSpread updates the spread deducted from the market rate on a merchant's rate-locked quotes
+// PUT /api/admin/merchants/:id/quote-spread
+func (h *AdminHandler) UpdateQuoteSpread(c *gin.Context) {
+	merchantID := c.Param("id")
+	if merchantID == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_MERCHANT_ID",
+			"Merchant ID is required",
+		))
+		return
+	}
+
+	var req dto.UpdateQuoteSpreadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	merchant, err := h.merchantService.UpdateQuoteSpread(merchantID, req.SpreadPercentage)
+	if err != nil {
+		if errors.Is(err, merchantservice.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"MERCHANT_NOT_FOUND",
+				"Merchant not found",
+			))
+			return
+		}
+		if errors.Is(err, merchantservice.ErrInvalidQuoteSpread) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"INVALID_QUOTE_SPREAD",
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"QUOTE_SPREAD_UPDATE_FAILED",
+			"Failed to update quote spread",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data: gin.H{
+			"merchant_id":             merchant.ID,
+			"quote_spread_percentage": merchant.QuoteSpreadPercentage,
+			"message":                 "Quote spread updated successfully",
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateAddressMode selects memo matching or per-payment deposit addresses for a merchant and chain
+// PUT /api/admin/merchants/:id/address-mode
+func (h *AdminHandler) UpdateAddressMode(c *gin.Context) {
+	merchantID := c.Param("id")
+	if merchantID == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_MERCHANT_ID",
+			"Merchant ID is required",
+		))
+		return
+	}
+
+	var req dto.UpdateAddressModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	merchant, err := h.merchantService.UpdateAddressMode(merchantID, req.Chain, req.Mode)
+	if err != nil {
+		if errors.Is(err, merchantservice.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"MERCHANT_NOT_FOUND",
+				"Merchant not found",
+			))
+			return
+		}
+		if errors.Is(err, merchantservice.ErrInvalidAddressMode) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"INVALID_ADDRESS_MODE",
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"ADDRESS_MODE_UPDATE_FAILED",
+			"Failed to update address mode",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data: gin.H{
+			"merchant_id":           merchant.ID,
+			"deposit_address_modes": merchant.DepositAddressModes,
+			"message":               "Address mode updated successfully",
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateLatePaymentPolicy selects whether a merchant's late payments are accepted, refunded or held for review
+// PUT /api/admin/merchants/:id/late-payment-policy
+func (h *AdminHandler) UpdateLatePaymentPolicy(c *gin.Context) {
+	merchantID := c.Param("id")
+	if merchantID == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_MERCHANT_ID",
+			"Merchant ID is required",
+		))
+		return
+	}
+
+	var req dto.UpdateLatePaymentPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	merchant, err := h.merchantService.UpdateLatePaymentPolicy(merchantID, req.Policy)
+	if err != nil {
+		if errors.Is(err, merchantservice.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"MERCHANT_NOT_FOUND",
+				"Merchant not found",
+			))
+			return
+		}
+		if errors.Is(err, merchantservice.ErrInvalidLatePolicy) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"INVALID_LATE_PAYMENT_POLICY",
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"LATE_PAYMENT_POLICY_UPDATE_FAILED",
+			"Failed to update late payment policy",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data: gin.H{
+			"merchant_id":         merchant.ID,
+			"late_payment_policy": merchant.GetLatePaymentPolicy(),
+			"message":             "Late payment policy updated successfully",
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateSandboxMerchant creates or refreshes the sandbox of an approved merchant and issues its sk_test_ API key
+// POST /api/admin/merchants/:id/sandbox
+func (h *AdminHandler) CreateSandboxMerchant(c *gin.Context) {
+	merchantID := c.Param("id")
+	if merchantID == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_MERCHANT_ID",
+			"Merchant ID is required",
+		))
+		return
+	}
+
+	sandbox, err := h.merchantService.CreateSandbox(merchantID)
+	if err != nil {
+		if errors.Is(err, merchantservice.ErrMerchantNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"MERCHANT_NOT_FOUND",
+				"Merchant not found",
+			))
+			return
+		}
+		if errors.Is(err, merchantservice.ErrMerchantNotApproved) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"MERCHANT_NOT_APPROVED",
+				"Merchant KYC must be approved before a sandbox is created",
+			))
+			return
+		}
+		if errors.Is(err, merchantservice.ErrSandboxMerchant) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"SANDBOX_MERCHANT",
+				"Merchant is already a sandbox merchant",
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"SANDBOX_CREATION_FAILED",
+			"Failed to create sandbox merchant",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data: gin.H{
+			"merchant_id":         sandbox.ID,
+			"sandbox_of":          merchantID,
+			"api_key":             sandbox.APIKey.String,
+			"api_key_created_at":  sandbox.APIKeyCreatedAt.Time,
+			"webhook_url":         sandbox.GetWebhookURL(),
+			"late_payment_policy": sandbox.GetLatePaymentPolicy(),
+			"message":             "Sandbox merchant ready. Payments created with the sk_test_ API key are test payments.",
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // ==================== Payout Management ====================
 
 // ListPayouts lists all payouts with optional filtering by status
@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/dto"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	auditdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/audit/domain"
+	auditrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/audit/repository"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// InboundTransferAdminHandler handles HTTP requests for the unmatched deposit queue
+type InboundTransferAdminHandler struct {
+	paymentService *paymentservice.PaymentService
+	auditRepo      *auditrepository.AuditRepository
+}
+
+// NewInboundTransferAdminHandler creates a new inbound transfer admin handler instance
+func NewInboundTransferAdminHandler(
+	paymentService *paymentservice.PaymentService,
+	auditRepo *auditrepository.AuditRepository,
+) *InboundTransferAdminHandler {
+	return &InboundTransferAdminHandler{
+		paymentService: paymentService,
+		auditRepo:      auditRepo,
+	}
+}
+
+// ListInboundTransfers lists transfers received on watched addresses, the unmatched queue by default
+// GET /api/admin/v1/inbound-transfers
+func (h *InboundTransferAdminHandler) ListInboundTransfers(c *gin.Context) {
+	var query dto.ListInboundTransfersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_QUERY",
+			"Invalid query parameters",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	if query.Status == "" {
+		query.Status = string(paymentDomain.InboundTransferStatusUnmatched)
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	transfers, total, err := h.paymentService.ListInboundTransfers(
+		c.Request.Context(),
+		paymentDomain.InboundTransferStatus(query.Status),
+		query.Limit,
+		query.Offset,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"FAILED_TO_LIST_INBOUND_TRANSFERS",
+			"Failed to retrieve inbound transfers",
+		))
+		return
+	}
+
+	items := make([]dto.InboundTransferItem, len(transfers))
+	for i, transfer := range transfers {
+		items[i] = dto.InboundTransferToItem(transfer)
+	}
+
+	response := dto.APIResponse{
+		Data: dto.ListInboundTransfersResponse{
+			Transfers: items,
+			Total:     total,
+			Limit:     query.Limit,
+			Offset:    query.Offset,
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetInboundTransfer returns a single inbound transfer
+// GET /api/admin/v1/inbound-transfers/:id
+func (h *InboundTransferAdminHandler) GetInboundTransfer(c *gin.Context) {
+	transferID := c.Param("id")
+	if _, err := parseUUID(transferID); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_TRANSFER_ID",
+			"Invalid inbound transfer ID format",
+		))
+		return
+	}
+
+	transfer, err := h.paymentService.GetInboundTransfer(c.Request.Context(), transferID)
+	if err != nil {
+		if errors.Is(err, paymentDomain.ErrInboundTransferNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"INBOUND_TRANSFER_NOT_FOUND",
+				"Inbound transfer not found",
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"FAILED_TO_GET_INBOUND_TRANSFER",
+			"Failed to retrieve inbound transfer",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data:      dto.InboundTransferToItem(transfer),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResolveInboundTransfer attaches an unmatched transfer to a payment, or marks it for refund or as a treasury deposit
+// Attaching confirms the payment like a transfer seen by the listener. Every attempt is audit-logged.
+// POST /api/admin/v1/inbound-transfers/:id/resolve
+func (h *InboundTransferAdminHandler) ResolveInboundTransfer(c *gin.Context) {
+	transferID := c.Param("id")
+	if _, err := parseUUID(transferID); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_TRANSFER_ID",
+			"Invalid inbound transfer ID format",
+		))
+		return
+	}
+
+	var req dto.ResolveInboundTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	if req.Action == "attach" && req.PaymentID == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_REQUEST",
+			"payment_id is required to attach a transfer",
+		))
+		return
+	}
+
+	resolvedBy, err := middleware.GetAdminEmail(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse(
+			"UNAUTHORIZED",
+			"Admin identity not found",
+		))
+		return
+	}
+
+	ctx := c.Request.Context()
+	var transfer *paymentDomain.InboundTransfer
+	switch req.Action {
+	case "attach":
+		transfer, _, err = h.paymentService.AttachInboundTransfer(ctx, transferID, req.PaymentID, resolvedBy, req.Note)
+	case "refund":
+		transfer, err = h.paymentService.MarkInboundTransferForRefund(ctx, transferID, resolvedBy, req.Note)
+	case "treasury":
+		transfer, err = h.paymentService.MarkInboundTransferAsTreasury(ctx, transferID, resolvedBy, req.Note)
+	default:
+		err = paymentDomain.ErrInvalidInboundTransferAction
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, paymentDomain.ErrInboundTransferNotFound):
+			status = http.StatusNotFound
+			c.JSON(status, dto.ErrorResponse(
+				"INBOUND_TRANSFER_NOT_FOUND",
+				"Inbound transfer not found",
+			))
+		case errors.Is(err, paymentDomain.ErrPaymentNotFound):
+			status = http.StatusNotFound
+			c.JSON(status, dto.ErrorResponse(
+				"PAYMENT_NOT_FOUND",
+				"Payment not found",
+			))
+		case errors.Is(err, paymentDomain.ErrInboundTransferNotUnmatched):
+			status = http.StatusConflict
+			c.JSON(status, dto.ErrorResponse(
+				"INBOUND_TRANSFER_ALREADY_RESOLVED",
+				"Inbound transfer is not in the unmatched queue",
+			))
+		case errors.Is(err, paymentDomain.ErrInboundTransferMismatch),
+			errors.Is(err, paymentDomain.ErrInvalidInboundTransferAction),
+			errors.Is(err, paymentDomain.ErrInvalidPaymentState),
+			errors.Is(err, paymentDomain.ErrPaymentExpired),
+			errors.Is(err, paymentDomain.ErrPaymentAlreadyCompleted),
+			errors.Is(err, paymentDomain.ErrInvalidAmount):
+			status = http.StatusBadRequest
+			c.JSON(status, dto.ErrorResponse(
+				"INVALID_INBOUND_TRANSFER_ACTION",
+				err.Error(),
+			))
+		default:
+			c.JSON(status, dto.ErrorResponseWithDetails(
+				"INBOUND_TRANSFER_RESOLUTION_FAILED",
+				"Failed to resolve inbound transfer",
+				err.Error(),
+			))
+		}
+		h.audit(c, transferID, req.Action, resolutionMetadata(req), status, err)
+		return
+	}
+
+	h.audit(c, transferID, req.Action, resolutionMetadata(req), http.StatusOK, nil)
+
+	response := dto.APIResponse{
+		Data:      dto.InboundTransferToItem(transfer),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CompleteInboundTransferRefund records the transaction that sent a transfer marked for refund back to the sender
+// The refund transaction is checked on chain when a verifier is configured. Every attempt is audit-logged.
+// POST /api/admin/v1/inbound-transfers/:id/complete-refund
+func (h *InboundTransferAdminHandler) CompleteInboundTransferRefund(c *gin.Context) {
+	transferID := c.Param("id")
+	if _, err := parseUUID(transferID); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_TRANSFER_ID",
+			"Invalid inbound transfer ID format",
+		))
+		return
+	}
+
+	var req dto.CompleteInboundTransferRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	refundedBy, err := middleware.GetAdminEmail(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse(
+			"UNAUTHORIZED",
+			"Admin identity not found",
+		))
+		return
+	}
+
+	metadata := map[string]interface{}{
+		"refund_tx_hash": req.RefundTxHash,
+	}
+
+	transfer, err := h.paymentService.CompleteInboundTransferRefund(c.Request.Context(), transferID, req.RefundTxHash, refundedBy)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, paymentDomain.ErrInboundTransferNotFound):
+			status = http.StatusNotFound
+			c.JSON(status, dto.ErrorResponse(
+				"INBOUND_TRANSFER_NOT_FOUND",
+				"Inbound transfer not found",
+			))
+		case errors.Is(err, paymentDomain.ErrInboundTransferNotRefundPending):
+			status = http.StatusConflict
+			c.JSON(status, dto.ErrorResponse(
+				"INBOUND_TRANSFER_NOT_REFUND_PENDING",
+				"Inbound transfer is not marked for refund",
+			))
+		case errors.Is(err, paymentDomain.ErrInboundTransferRefundNotIncluded):
+			status = http.StatusBadRequest
+			c.JSON(status, dto.ErrorResponse(
+				"REFUND_TRANSACTION_NOT_FOUND",
+				err.Error(),
+			))
+		default:
+			c.JSON(status, dto.ErrorResponseWithDetails(
+				"INBOUND_TRANSFER_REFUND_FAILED",
+				"Failed to record the inbound transfer refund",
+				err.Error(),
+			))
+		}
+		h.audit(c, transferID, "refunded", metadata, status, err)
+		return
+	}
+
+	h.audit(c, transferID, "refunded", metadata, http.StatusOK, nil)
+
+	response := dto.APIResponse{
+		Data:      dto.InboundTransferToItem(transfer),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// resolutionMetadata returns the audit metadata of an operator resolution
+func resolutionMetadata(req dto.ResolveInboundTransferRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"action":     req.Action,
+		"payment_id": req.PaymentID,
+		"note":       req.Note,
+	}
+}
+
+// audit records an operator action on an inbound transfer
+// A failed audit write is logged and does not fail the request, the resolution is already stored
+func (h *InboundTransferAdminHandler) audit(c *gin.Context, transferID, action string, metadata map[string]interface{}, httpStatus int, actionErr error) {
+	if h.auditRepo == nil {
+		return
+	}
+
+	adminID, _ := middleware.GetAdminID(c)
+	adminEmail, _ := middleware.GetAdminEmail(c)
+
+	auditLog := &auditdomain.AuditLog{
+		ID:             uuid.New().String(),
+		ActorType:      auditdomain.ActorTypeAdmin,
+		ActorID:        sql.NullString{String: adminID, Valid: adminID != ""},
+		ActorEmail:     sql.NullString{String: adminEmail, Valid: adminEmail != ""},
+		ActorIPAddress: sql.NullString{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+		Action:         "inbound_transfer_" + action,
+		ActionCategory: auditdomain.ActionCategoryPayment,
+		ResourceType:   "inbound_transfer",
+		ResourceID:     transferID,
+		Status:         auditdomain.AuditStatusSuccess,
+		HTTPMethod:     sql.NullString{String: c.Request.Method, Valid: true},
+		HTTPPath:       sql.NullString{String: c.Request.URL.Path, Valid: true},
+		HTTPStatusCode: sql.NullInt32{Int32: int32(httpStatus), Valid: true},
+		UserAgent:      sql.NullString{String: c.Request.UserAgent(), Valid: c.Request.UserAgent() != ""},
+		Metadata:       metadata,
+		CreatedAt:      time.Now(),
+	}
+
+	if actionErr != nil {
+		auditLog.Status = auditdomain.AuditStatusFailed
+		auditLog.ErrorMessage = sql.NullString{String: actionErr.Error(), Valid: true}
+	}
+
+	if err := h.auditRepo.Create(auditLog); err != nil {
+		logger.Error("Failed to create audit log for inbound transfer resolution", err, logger.Fields{
+			"transfer_id": transferID,
+			"action":      action,
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/dto"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
+)
+
+// LatePaymentAdminHandler handles HTTP requests for payments paid after they expired
+type LatePaymentAdminHandler struct {
+	paymentService *paymentservice.PaymentService
+}
+
+// NewLatePaymentAdminHandler creates a new late payment admin handler instance
+func NewLatePaymentAdminHandler(paymentService *paymentservice.PaymentService) *LatePaymentAdminHandler {
+	return &LatePaymentAdminHandler{
+		paymentService: paymentService,
+	}
+}
+
+// ListLatePayments lists payments that received transfers after they expired
+// GET /api/admin/v1/payments/late
+func (h *LatePaymentAdminHandler) ListLatePayments(c *gin.Context) {
+	var query dto.ListLatePaymentsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_QUERY",
+			"Invalid query parameters",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	payments, total, err := h.paymentService.ListLatePayments(
+		c.Request.Context(),
+		paymentDomain.LatePaymentResolution(query.Resolution),
+		query.Limit,
+		query.Offset,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"FAILED_TO_LIST_LATE_PAYMENTS",
+			"Failed to retrieve late payments",
+		))
+		return
+	}
+
+	items := make([]dto.LatePaymentItem, len(payments))
+	for i, payment := range payments {
+		items[i] = dto.LatePaymentToItem(payment)
+	}
+
+	response := dto.APIResponse{
+		Data: dto.ListLatePaymentsResponse{
+			Payments: items,
+			Total:    total,
+			Limit:    query.Limit,
+			Offset:   query.Offset,
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResolveLatePayment accepts or refunds a late payment held for review
+// Accepting completes the payment at the current exchange rate, refunding sends the amount back to the payer
+// POST /api/admin/v1/payments/:id/late-payment/resolve
+func (h *LatePaymentAdminHandler) ResolveLatePayment(c *gin.Context) {
+	paymentID := c.Param("id")
+	if _, err := parseUUID(paymentID); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_PAYMENT_ID",
+			"Invalid payment ID format",
+		))
+		return
+	}
+
+	var req dto.ResolveLatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	payment, err := h.paymentService.ResolveLatePayment(c.Request.Context(), paymentID, paymentDomain.LatePaymentPolicy(req.Action))
+	if err != nil {
+		switch {
+		case errors.Is(err, paymentDomain.ErrPaymentNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"PAYMENT_NOT_FOUND",
+				"Payment not found",
+			))
+		case errors.Is(err, paymentDomain.ErrInvalidPaymentState):
+			c.JSON(http.StatusConflict, dto.ErrorResponse(
+				"LATE_PAYMENT_NOT_PENDING_REVIEW",
+				"Payment is not a late payment pending review",
+			))
+		case errors.Is(err, paymentDomain.ErrInvalidLatePaymentAction),
+			errors.Is(err, paymentDomain.ErrRefundAddressUnknown):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"INVALID_LATE_PAYMENT_ACTION",
+				err.Error(),
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponseWithDetails(
+				"LATE_PAYMENT_RESOLUTION_FAILED",
+				"Failed to resolve late payment",
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	response := dto.APIResponse{
+		Data:      dto.LatePaymentToItem(payment),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/dto"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+	infrastructurerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/repository"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// ListenerAdminHandler handles HTTP requests for blockchain listener operations
+type ListenerAdminHandler struct {
+	cursorRepo *infrastructurerepository.ListenerCursorRepository
+}
+
+// NewListenerAdminHandler creates a new listener admin handler instance
+func NewListenerAdminHandler(cursorRepo *infrastructurerepository.ListenerCursorRepository) *ListenerAdminHandler {
+	return &ListenerAdminHandler{
+		cursorRepo: cursorRepo,
+	}
+}
+
+// ListCursors lists the persisted cursors of all blockchain listeners
+// GET /api/admin/v1/system/listeners
+func (h *ListenerAdminHandler) ListCursors(c *gin.Context) {
+	cursors, err := h.cursorRepo.ListCursors()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"FAILED_TO_LIST_LISTENER_CURSORS",
+			"Failed to retrieve listener cursors",
+		))
+		return
+	}
+
+	items := make([]dto.ListenerCursorItem, len(cursors))
+	for i, cursor := range cursors {
+		items[i] = dto.ListenerCursorToItem(cursor)
+	}
+
+	response := dto.APIResponse{
+		Data: dto.ListListenerCursorsResponse{
+			Cursors: items,
+			Total:   len(items),
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RequestRescan queues a forced rescan of a block range or a single transaction
+// The listener of the chain picks it up on its next poll, already recorded transactions are processed again
+// POST /api/admin/v1/system/listeners/:chain/rescan
+func (h *ListenerAdminHandler) RequestRescan(c *gin.Context) {
+	chain := strings.ToLower(c.Param("chain"))
+	if chain == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_CHAIN",
+			"Chain is required",
+		))
+		return
+	}
+
+	var req dto.RescanListenerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	if req.TxHash != "" && (req.FromBlock != nil || req.ToBlock != nil) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_RESCAN_REQUEST",
+			"Provide either a block range or a tx hash, not both",
+		))
+		return
+	}
+
+	requestedBy, err := middleware.GetAdminEmail(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse(
+			"UNAUTHORIZED",
+			"Admin identity not found",
+		))
+		return
+	}
+
+	rescan := &blockchainDomain.RescanRequest{
+		Chain:       paymentDomain.Chain(chain),
+		TxHash:      sql.NullString{String: req.TxHash, Valid: req.TxHash != ""},
+		RequestedBy: requestedBy,
+	}
+	if req.FromBlock != nil {
+		rescan.FromBlock = sql.NullInt64{Int64: *req.FromBlock, Valid: true}
+	}
+	if req.ToBlock != nil {
+		rescan.ToBlock = sql.NullInt64{Int64: *req.ToBlock, Valid: true}
+	}
+
+	if err := h.cursorRepo.CreateRescanRequest(rescan); err != nil {
+		if errors.Is(err, infrastructurerepository.ErrInvalidRescanRequest) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"INVALID_RESCAN_REQUEST",
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"RESCAN_REQUEST_FAILED",
+			"Failed to queue rescan",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data:      dto.RescanRequestToResponse(rescan),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// GetRescan retrieves the status of a rescan request
+// GET /api/admin/v1/system/listeners/rescans/:id
+func (h *ListenerAdminHandler) GetRescan(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := parseUUID(id); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_RESCAN_ID",
+			"Rescan ID must be a valid UUID",
+		))
+		return
+	}
+
+	rescan, err := h.cursorRepo.GetRescanRequest(id)
+	if err != nil {
+		if errors.Is(err, infrastructurerepository.ErrRescanRequestNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse(
+				"RESCAN_NOT_FOUND",
+				"Rescan request not found",
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"FAILED_TO_GET_RESCAN",
+			"Failed to retrieve rescan request",
+		))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data:      dto.RescanRequestToResponse(rescan),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/dto"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
+)
+
+// PaymentAdminHandler handles HTTP requests for searching payments across merchants
+type PaymentAdminHandler struct {
+	paymentService *paymentservice.PaymentService
+}
+
+// NewPaymentAdminHandler creates a new payment admin handler instance
+func NewPaymentAdminHandler(paymentService *paymentservice.PaymentService) *PaymentAdminHandler {
+	return &PaymentAdminHandler{
+		paymentService: paymentService,
+	}
+}
+
+// SearchPayments searches the payments of every merchant, or of one with merchant_id, newest first
+// Uses the same filters and cursor pagination as the merchant API
+// GET /api/admin/v1/payments
+func (h *PaymentAdminHandler) SearchPayments(c *gin.Context) {
+	var query dto.SearchPaymentsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_QUERY",
+			"Invalid query parameters",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	search, err := query.ToPaymentSearch(c.QueryMap("metadata"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_QUERY",
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	page, err := h.paymentService.SearchPayments(c.Request.Context(), search)
+	if err != nil {
+		switch {
+		case errors.Is(err, paymentDomain.ErrInvalidPaymentCursor):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+				"INVALID_CURSOR",
+				"Invalid cursor, it must come from a search with the same sort order",
+			))
+		case errors.Is(err, paymentDomain.ErrInvalidPaymentSearch):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+				"INVALID_QUERY",
+				"Invalid query parameters",
+				err.Error(),
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+				"FAILED_TO_SEARCH_PAYMENTS",
+				"Failed to search payments",
+			))
+		}
+		return
+	}
+
+	items := make([]dto.PaymentSearchItem, len(page.Payments))
+	for i, payment := range page.Payments {
+		items[i] = dto.PaymentToSearchItem(payment)
+	}
+
+	data := dto.SearchPaymentsResponse{
+		Payments: items,
+		HasMore:  page.NextCursor != "",
+	}
+	if page.NextCursor != "" {
+		data.NextCursor = &page.NextCursor
+	}
+
+	response := dto.APIResponse{
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/dto"
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	auditdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/audit/domain"
+	auditrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/audit/repository"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// TokenAdminHandler handles HTTP requests for managing the token registry
+type TokenAdminHandler struct {
+	tokenRegistry *paymentservice.TokenRegistry
+	auditRepo     *auditrepository.AuditRepository
+}
+
+// NewTokenAdminHandler creates a new token admin handler instance
+func NewTokenAdminHandler(
+	tokenRegistry *paymentservice.TokenRegistry,
+	auditRepo *auditrepository.AuditRepository,
+) *TokenAdminHandler {
+	return &TokenAdminHandler{
+		tokenRegistry: tokenRegistry,
+		auditRepo:     auditRepo,
+	}
+}
+
+// ListTokens lists every token of the registry, disabled ones included
+// GET /api/admin/v1/tokens
+func (h *TokenAdminHandler) ListTokens(c *gin.Context) {
+	tokens, err := h.tokenRegistry.ListTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(
+			"FAILED_TO_LIST_TOKENS",
+			"Failed to retrieve tokens",
+		))
+		return
+	}
+
+	items := make([]dto.TokenItem, len(tokens))
+	for i, token := range tokens {
+		items[i] = dto.TokenToItem(token)
+	}
+
+	response := dto.APIResponse{
+		Data: dto.ListTokensResponse{
+			Tokens: items,
+			Total:  len(items),
+		},
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateToken adds a token to the registry, payments can use it once the services reload the registry
+// Listeners pick up new tokens when they restart
+// POST /api/admin/v1/tokens
+func (h *TokenAdminHandler) CreateToken(c *gin.Context) {
+	var req dto.CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	token := &paymentDomain.Token{
+		Chain:       paymentDomain.Chain(strings.ToLower(req.Chain)),
+		Symbol:      req.Symbol,
+		Address:     req.Address,
+		Decimals:    *req.Decimals,
+		PegCurrency: req.PegCurrency,
+		PriceSource: paymentDomain.PriceSource(req.PriceSource),
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		MinAmount:   req.MinAmount,
+		MaxAmount:   req.MaxAmount,
+	}
+
+	err := h.tokenRegistry.CreateToken(token)
+	status, errorCode := tokenErrorStatus(err, http.StatusCreated)
+	h.audit(c, "token_created", token, status, err)
+	if err != nil {
+		c.JSON(status, dto.ErrorResponse(errorCode, tokenErrorMessage(err)))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data:      dto.TokenToItem(token),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateToken changes the address, pricing, limits or enabled flag of a registry token
+// Disabling a token stops new payments in it, existing payments are still settled
+// PATCH /api/admin/v1/tokens/:id
+func (h *TokenAdminHandler) UpdateToken(c *gin.Context) {
+	tokenID := c.Param("id")
+	if _, err := parseUUID(tokenID); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(
+			"INVALID_TOKEN_ID",
+			"Token ID must be a valid UUID",
+		))
+		return
+	}
+
+	var req dto.UpdateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			fmt.Sprintf("%v", err),
+		))
+		return
+	}
+
+	update := paymentservice.TokenUpdate{
+		Address:     req.Address,
+		Decimals:    req.Decimals,
+		PegCurrency: req.PegCurrency,
+		Enabled:     req.Enabled,
+		MinAmount:   req.MinAmount,
+		MaxAmount:   req.MaxAmount,
+	}
+	if req.PriceSource != nil {
+		priceSource := paymentDomain.PriceSource(*req.PriceSource)
+		update.PriceSource = &priceSource
+	}
+
+	token, err := h.tokenRegistry.UpdateToken(tokenID, update)
+	status, errorCode := tokenErrorStatus(err, http.StatusOK)
+	if token == nil {
+		token = &paymentDomain.Token{ID: tokenID}
+	}
+	h.audit(c, "token_updated", token, status, err)
+	if err != nil {
+		c.JSON(status, dto.ErrorResponse(errorCode, tokenErrorMessage(err)))
+		return
+	}
+
+	response := dto.APIResponse{
+		Data:      dto.TokenToItem(token),
+		Timestamp: time.Now(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// tokenErrorStatus maps token registry errors to an HTTP status and error code
+func tokenErrorStatus(err error, successStatus int) (int, string) {
+	switch {
+	case err == nil:
+		return successStatus, ""
+	case errors.Is(err, paymentDomain.ErrInvalidToken):
+		return http.StatusBadRequest, "INVALID_TOKEN"
+	case errors.Is(err, paymentDomain.ErrTokenNotFound):
+		return http.StatusNotFound, "TOKEN_NOT_FOUND"
+	case errors.Is(err, paymentDomain.ErrTokenAlreadyExists):
+		return http.StatusConflict, "TOKEN_ALREADY_EXISTS"
+	default:
+		return http.StatusInternalServerError, "TOKEN_UPDATE_FAILED"
+	}
+}
+
+// tokenErrorMessage returns the message shown for a token registry error, internal causes are hidden
+func tokenErrorMessage(err error) string {
+	if errors.Is(err, paymentDomain.ErrInvalidToken) ||
+		errors.Is(err, paymentDomain.ErrTokenNotFound) ||
+		errors.Is(err, paymentDomain.ErrTokenAlreadyExists) {
+		return err.Error()
+	}
+	return "Failed to save token"
+}
+
+// audit records an operator change to the token registry
+// A failed audit write is logged and does not fail the request, the change is already stored
+func (h *TokenAdminHandler) audit(c *gin.Context, action string, token *paymentDomain.Token, httpStatus int, actionErr error) {
+	if h.auditRepo == nil {
+		return
+	}
+
+	adminID, _ := middleware.GetAdminID(c)
+	adminEmail, _ := middleware.GetAdminEmail(c)
+
+	auditLog := &auditdomain.AuditLog{
+		ID:             uuid.New().String(),
+		ActorType:      auditdomain.ActorTypeAdmin,
+		ActorID:        sql.NullString{String: adminID, Valid: adminID != ""},
+		ActorEmail:     sql.NullString{String: adminEmail, Valid: adminEmail != ""},
+		ActorIPAddress: sql.NullString{String: c.ClientIP(), Valid: c.ClientIP() != ""},
+		Action:         action,
+		ActionCategory: auditdomain.ActionCategoryAdmin,
+		ResourceType:   "token",
+		ResourceID:     token.ID,
+		Status:         auditdomain.AuditStatusSuccess,
+		HTTPMethod:     sql.NullString{String: c.Request.Method, Valid: true},
+		HTTPPath:       sql.NullString{String: c.Request.URL.Path, Valid: true},
+		HTTPStatusCode: sql.NullInt32{Int32: int32(httpStatus), Valid: true},
+		UserAgent:      sql.NullString{String: c.Request.UserAgent(), Valid: c.Request.UserAgent() != ""},
+		Metadata: map[string]interface{}{
+			"chain":        string(token.Chain),
+			"symbol":       token.Symbol,
+			"address":      token.Address,
+			"enabled":      token.Enabled,
+			"price_source": string(token.PriceSource),
+			"min_amount":   token.MinAmount.String(),
+			"max_amount":   token.MaxAmount.String(),
+		},
+		CreatedAt: time.Now(),
+	}
+
+	if actionErr != nil {
+		auditLog.Status = auditdomain.AuditStatusFailed
+		auditLog.ErrorMessage = sql.NullString{String: actionErr.Error(), Valid: true}
+	}
+
+	if err := h.auditRepo.Create(auditLog); err != nil {
+		logger.Error("Failed to create audit log for token registry change", err, logger.Fields{
+			"token_id": token.ID,
+			"action":   action,
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client-generated idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength limits the size of client-provided keys
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyRecord is the stored outcome of a request made with an idempotency key
+type IdempotencyRecord struct {
+	RequestHash string `json:"request_hash"` // SHA-256 of method, path and body
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyStore defines the interface for storing idempotent responses
+type IdempotencyStore interface {
+	// Get returns the stored record for a key, or nil if there is none
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Save stores the record for a key
+	Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	// Lock tries to acquire the processing lock for a key and returns a token to release it
+	Lock(ctx context.Context, key string, ttl time.Duration) (token string, acquired bool, err error)
+	// Unlock releases the processing lock if it is still held with the given token
+	Unlock(ctx context.Context, key, token string) error
+	// Extend renews the processing lock if it is still held with the given token
+	// Returns false if the lock was lost
+	Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+}
+
+// RedisIdempotencyStore implements IdempotencyStore using Redis
+type RedisIdempotencyStore struct {
+	client *redis.Client
+}
+
+// NewRedisIdempotencyStore creates a new Redis-based idempotency store
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{
+		client: client,
+	}
+}
+
+// unlockScript deletes the lock only if it still holds our token
+var unlockScript = redis.NewScript(`
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('DEL', KEYS[1])
+	end
+	return 0
+`)
+
+// extendScript renews the lock only if it still holds our token
+var extendScript = redis.NewScript(`
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	end
+	return 0
+`)
+
+// Get returns the stored record for a key
+func (s *RedisIdempotencyStore) Get(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+	return &record, nil
+}
+
+// Save stores the record for a key
+func (s *RedisIdempotencyStore) Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+	if err := s.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+	return nil
+}
+
+// Lock tries to acquire the processing lock for a key
+func (s *RedisIdempotencyStore) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", false, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	acquired, err := s.client.SetNX(ctx, key+":lock", token, ttl).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to acquire idempotency lock: %w", err)
+	}
+	return token, acquired, nil
+}
+
+// Unlock releases the processing lock
+func (s *RedisIdempotencyStore) Unlock(ctx context.Context, key, token string) error {
+	if err := unlockScript.Run(ctx, s.client, []string{key + ":lock"}, token).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency lock: %w", err)
+	}
+	return nil
+}
+
+// Extend renews the processing lock
+func (s *RedisIdempotencyStore) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	extended, err := extendScript.Run(ctx, s.client, []string{key + ":lock"}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to extend idempotency lock: %w", err)
+	}
+	return extended == 1, nil
+}
+
+// IdempotencyConfig holds configuration for idempotency middleware
+type IdempotencyConfig struct {
+	Store        IdempotencyStore
+	TTL          time.Duration // How long responses are kept (default: 24 hours)
+	LockTTL      time.Duration // Lock expiry, renewed while the request runs so a crashed instance releases it (default: 30 seconds)
+	LockWaitTime time.Duration // How long a concurrent duplicate waits for the first request (default: 10 seconds)
+	MaxBodyBytes int64         // Largest request body read to fingerprint the request (default: 1 MiB)
+}
+
+// Idempotency returns a Gin middleware that honours the Idempotency-Key header on mutating requests
+// It must run after APIKeyAuth: keys are scoped per merchant.
+// A replay with the same body returns the original response, a replay with a different body returns 422.
+// Concurrent duplicates wait for the first request and then replay its response.
+func Idempotency(config IdempotencyConfig) gin.HandlerFunc {
+	if config.TTL == 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTTL == 0 {
+		config.LockTTL = 30 * time.Second
+	}
+	if config.LockWaitTime == 0 {
+		config.LockWaitTime = 10 * time.Second
+	}
+	if config.MaxBodyBytes == 0 {
+		config.MaxBodyBytes = 1 << 20
+	}
+
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" || config.Store == nil || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "INVALID_IDEMPOTENCY_KEY",
+					"message": fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength),
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		merchantID := c.GetString(MerchantIDKey)
+		if merchantID == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": gin.H{
+					"code":    "REQUEST_TOO_LARGE",
+					"message": fmt.Sprintf("Request body must be at most %d bytes", config.MaxBodyBytes),
+				},
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "INVALID_REQUEST",
+					"message": "Failed to read request body",
+				},
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := fmt.Sprintf("idempotency:%s:%s", merchantID, idempotencyKey)
+		requestHash := hashIdempotentRequest(c.Request.Method, c.Request.URL.Path, body)
+		logFields := logrus.Fields{
+			"merchant_id":     merchantID,
+			"idempotency_key": idempotencyKey,
+			"path":            c.Request.URL.Path,
+		}
+
+		if replayIdempotentResponse(c, config.Store, storeKey, requestHash, logFields) {
+			return
+		}
+
+		// Serialize concurrent duplicates: wait for the request holding the lock, then replay its response
+		token, acquired, err := config.Store.Lock(ctx, storeKey, config.LockTTL)
+		deadline := time.Now().Add(config.LockWaitTime)
+		for err == nil && !acquired && time.Now().Before(deadline) {
+			select {
+			case <-ctx.Done():
+				c.Abort()
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			if replayIdempotentResponse(c, config.Store, storeKey, requestHash, logFields) {
+				return
+			}
+			token, acquired, err = config.Store.Lock(ctx, storeKey, config.LockTTL)
+		}
+
+		if err != nil {
+			// On error, process the request without idempotency but log the issue
+			logger.WithContext(ctx).WithFields(logFields).WithField("error", err.Error()).
+				Error("Idempotency lock failed")
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": gin.H{
+					"code":    "IDEMPOTENCY_REQUEST_IN_PROGRESS",
+					"message": "A request with this Idempotency-Key is still being processed",
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		stopRenewal := renewIdempotencyLock(ctx, config.Store, storeKey, token, config.LockTTL, logFields)
+		defer func() {
+			stopRenewal()
+			if err := config.Store.Unlock(context.Background(), storeKey, token); err != nil {
+				logger.WithContext(ctx).WithFields(logFields).WithField("error", err.Error()).
+					Warn("Failed to release idempotency lock")
+			}
+		}()
+
+		// The previous holder may have finished between our check and acquiring the lock
+		if replayIdempotentResponse(c, config.Store, storeKey, requestHash, logFields) {
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// Server errors are not stored so the client can retry with the same key
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		record := &IdempotencyRecord{
+			RequestHash: requestHash,
+			StatusCode:  status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if err := config.Store.Save(context.Background(), storeKey, record, config.TTL); err != nil {
+			logger.WithContext(ctx).WithFields(logFields).WithField("error", err.Error()).
+				Error("Failed to save idempotent response")
+		}
+	}
+}
+
+// renewIdempotencyLock extends the processing lock every third of its TTL until the returned function is called
+// A slow request keeps its lock, while a crashed instance still releases it once the TTL passes
+func renewIdempotencyLock(ctx context.Context, store IdempotencyStore, storeKey, token string, ttl time.Duration, logFields logrus.Fields) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			extended, err := store.Extend(context.Background(), storeKey, token, ttl)
+			if err != nil {
+				logger.WithContext(ctx).WithFields(logFields).WithField("error", err.Error()).
+					Warn("Failed to extend idempotency lock")
+				continue
+			}
+			if !extended {
+				logger.WithContext(ctx).WithFields(logFields).Warn("Idempotency lock lost while the request was processed")
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// replayIdempotentResponse writes the stored response for the key if there is one
+// Returns true if the request was answered
+func replayIdempotentResponse(c *gin.Context, store IdempotencyStore, storeKey, requestHash string, logFields logrus.Fields) bool {
+	ctx := c.Request.Context()
+
+	record, err := store.Get(ctx, storeKey)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logFields).WithField("error", err.Error()).
+			Error("Idempotency lookup failed")
+		return false
+	}
+	if record == nil {
+		return false
+	}
+
+	if record.RequestHash != requestHash {
+		logger.WithContext(ctx).WithFields(logFields).Warn("Idempotency-Key reused with a different request")
+
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": gin.H{
+				"code":    "IDEMPOTENCY_KEY_MISMATCH",
+				"message": "Idempotency-Key was already used with a different request",
+			},
+		})
+		c.Abort()
+		return true
+	}
+
+	logger.WithContext(ctx).WithFields(logFields).Debug("Replaying idempotent response")
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.StatusCode, record.ContentType, record.Body)
+	c.Abort()
+	return true
+}
+
+// hashIdempotentRequest fingerprints a request so a key cannot be reused for a different one
+func hashIdempotentRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// isMutatingMethod returns true for HTTP methods that change state
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyResponseWriter captures the response body so it can be replayed
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore for testing
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*IdempotencyRecord
+	locks   map[string]string
+	nextID  int
+	// extensions counts the calls to Extend
+	extensions int
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{
+		records: make(map[string]*IdempotencyRecord),
+		locks:   make(map[string]string),
+	}
+}
+
+func (s *memoryIdempotencyStore) Get(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *memoryIdempotencyStore) Save(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, held := s.locks[key]; held {
+		return "", false, nil
+	}
+	s.nextID++
+	token := fmt.Sprintf("token-%d", s.nextID)
+	s.locks[key] = token
+	return token, true, nil
+}
+
+func (s *memoryIdempotencyStore) Unlock(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks[key] == token {
+		delete(s.locks, key)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.extensions++
+	return s.locks[key] == token, nil
+}
+
+// setupIdempotencyRouter returns a router whose POST /payments handler counts its calls
+func setupIdempotencyRouter(store IdempotencyStore, calls *int32, delay time.Duration) *gin.Engine {
+	return setupIdempotencyRouterWithConfig(IdempotencyConfig{
+		Store:        store,
+		LockWaitTime: 2 * time.Second,
+	}, calls, delay)
+}
+
+func setupIdempotencyRouterWithConfig(config IdempotencyConfig, calls *int32, delay time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(MerchantIDKey, c.GetHeader("X-Test-Merchant"))
+		c.Next()
+	})
+	router.Use(Idempotency(config))
+	router.POST("/payments", func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		time.Sleep(delay)
+		c.JSON(http.StatusCreated, gin.H{"payment": n})
+	})
+	return router
+}
+
+func doIdempotentRequest(router *gin.Engine, merchantID, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Merchant", merchantID)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaySameBody(t *testing.T) {
+	var calls int32
+	router := setupIdempotencyRouter(newMemoryIdempotencyStore(), &calls, 0)
+
+	first := doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":100000}`)
+	second := doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":100000}`)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_DifferentBodyReturns422(t *testing.T) {
+	var calls int32
+	router := setupIdempotencyRouter(newMemoryIdempotencyStore(), &calls, 0)
+
+	first := doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":100000}`)
+	second := doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":200000}`)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, second.Code)
+	assert.Contains(t, second.Body.String(), "IDEMPOTENCY_KEY_MISMATCH")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_KeysScopedPerMerchant(t *testing.T) {
+	var calls int32
+	router := setupIdempotencyRouter(newMemoryIdempotencyStore(), &calls, 0)
+
+	doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":100000}`)
+	other := doIdempotentRequest(router, "merchant-2", "key-1", `{"amount_vnd":100000}`)
+
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.Empty(t, other.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_NoHeaderPassesThrough(t *testing.T) {
+	var calls int32
+	router := setupIdempotencyRouter(newMemoryIdempotencyStore(), &calls, 0)
+
+	doIdempotentRequest(router, "merchant-1", "", `{"amount_vnd":100000}`)
+	doIdempotentRequest(router, "merchant-1", "", `{"amount_vnd":100000}`)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_ConcurrentDuplicatesSerialized(t *testing.T) {
+	var calls int32
+	router := setupIdempotencyRouter(newMemoryIdempotencyStore(), &calls, 200*time.Millisecond)
+
+	const requests = 5
+	responses := make([]*httptest.ResponseRecorder, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":100000}`)
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, w := range responses {
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, responses[0].Body.String(), w.Body.String())
+	}
+}
+
+func TestIdempotency_LockRenewedWhileRequestRuns(t *testing.T) {
+	var calls int32
+	store := newMemoryIdempotencyStore()
+	router := setupIdempotencyRouterWithConfig(IdempotencyConfig{
+		Store:   store,
+		LockTTL: 30 * time.Millisecond,
+	}, &calls, 100*time.Millisecond)
+
+	w := doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":100000}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.GreaterOrEqual(t, store.extensions, 2)
+	assert.Empty(t, store.locks)
+}
+
+func TestIdempotency_RejectsOversizedBody(t *testing.T) {
+	var calls int32
+	router := setupIdempotencyRouterWithConfig(IdempotencyConfig{
+		Store:        newMemoryIdempotencyStore(),
+		MaxBodyBytes: 16,
+	}, &calls, 0)
+
+	w := doIdempotentRequest(router, "merchant-1", "key-1", `{"amount_vnd":100000,"description":"too long"}`)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
@@ -399,6 +399,60 @@ func TestRateLimit_DefaultConfiguration(t *testing.T) {
 	assert.Equal(t, 1*time.Minute, calls[0].Arguments.Get(3).(time.Duration))
 }
 
+func TestParamRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		state          *RateLimitState
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "allowed",
+			state:          &RateLimitState{Allowed: true, Limit: 30, Remaining: 29},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "exceeded",
+			state:          &RateLimitState{Allowed: false, Limit: 30, RetryIn: 20},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "limiter error allows the request",
+			err:            fmt.Errorf("redis unavailable"),
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLimiter := new(MockRateLimiter)
+			mockLimiter.On("Allow", mock.Anything, "ratelimit:payment_link:link-1", 30, time.Minute).
+				Return(tt.state, tt.err)
+
+			router := gin.New()
+			router.POST("/links/:id/payments", ParamRateLimit(ParamRateLimitConfig{
+				Limiter: mockLimiter,
+				Param:   "id",
+				Scope:   "payment_link",
+			}), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "ok"})
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/links/link-1/payments", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "20", w.Header().Get("Retry-After"))
+			}
+			mockLimiter.AssertExpectations(t)
+		})
+	}
+}
+
 func TestRateLimit_MultipleRequests(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
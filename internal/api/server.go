@@ -21,15 +21,21 @@ import (
 	compliancehandler "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/handler"
 	compliancerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/repository"
 	complianceservice "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/service"
+	exporthandler "github.com/hxuan190/stable_payment_gateway/internal/modules/export/handler"
+	exportrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/export/repository"
+	exportservice "github.com/hxuan190/stable_payment_gateway/internal/modules/export/service"
 	infrastructurerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/repository"
 	infrastructureservice "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/service"
 	ledgerrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/repository"
+	ledgerservice "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/service"
 	merchanthandler "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/handler"
 	merchantrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/repository"
 	merchantservice "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/service"
+	paymentblockchain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/blockchain"
 	paymenthttp "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/http"
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/legacy"
 	paymentrepo "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/repository"
+	paymentdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
 	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
 	payouthandler "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/handler"
 	payoutrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/repository"
@@ -39,6 +45,7 @@ import (
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/storage"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/trmlabs"
+	"github.com/hxuan190/stable_payment_gateway/internal/worker"
 )
 
 // Server represents the HTTP server
@@ -157,6 +164,26 @@ func (s *Server) rateLimitMiddleware() gin.HandlerFunc {
 	})
 }
 
+// paymentLinkRateLimitMiddleware limits the payments opened per payment link on the public route
+// An anonymous payer cannot use up the link's max_uses faster than the limit
+func (s *Server) paymentLinkRateLimitMiddleware() gin.HandlerFunc {
+	redisCache, ok := s.cache.(*cache.RedisCache)
+	if !ok {
+		logger.Warn("Payment link rate limiting disabled: Redis cache not available")
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return middleware.ParamRateLimit(middleware.ParamRateLimitConfig{
+		Limiter: middleware.NewRedisRateLimiter(redisCache.GetClient()),
+		Param:   "id",
+		Scope:   "payment_link",
+		Limit:   s.config.Security.PaymentLinkRateLimitPerMinute,
+		Window:  1 * time.Minute,
+	})
+}
+
 // setupRoutes configures all API routes
 func (s *Server) setupRoutes(router *gin.Engine) {
 	// Health check handler
@@ -222,15 +249,80 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		auditRepo,
 		logger.GetLogger(),
 	)
-	// Skip merchant adapter for now - type mismatch issue
-	var merchantAdapter *legacy.MerchantRepositoryAdapter
 	exchangeRateAdapter := legacy.NewExchangeRateServiceAdapter(exchangeRateService)
 	complianceAdapter := legacy.NewComplianceServiceAdapter(complianceService)
 	amlAdapter := legacy.NewAMLServiceAdapter(amlService)
 
+	// Deposit addresses fall back to memo matching when the generator cannot be built
+	depositAddressRepo := paymentrepo.NewPostgresDepositAddressRepository(s.db)
+	var depositAddressGen paymentdomain.DepositAddressGenerator
+	generator, err := paymentblockchain.NewDepositAddressGenerator(depositAddressRepo, paymentblockchain.DepositAddressGeneratorConfig{
+		SolanaWallet: s.solanaWallet,
+		SolanaTokenMints: map[string]string{
+			"USDT": s.config.Solana.USDTMint,
+			"USDC": s.config.Solana.USDCMint,
+		},
+		BSCAccountXPub: s.config.BSC.DepositXPub,
+	})
+	if err != nil {
+		logger.Error("Failed to initialize deposit address generator", err)
+	} else {
+		depositAddressGen = generator
+	}
+
+	// EVM networks from configuration accept their own tokens and pay into their own wallet
+	chainWallets := map[paymentdomain.Chain]string{
+		paymentdomain.ChainBSC:  s.config.BSC.WalletAddress,
+		paymentdomain.ChainTRON: s.config.TRON.WalletAddress,
+	}
+	for _, network := range s.config.EVMNetworks {
+		chainWallets[paymentdomain.Chain(network.Name)] = network.WalletAddress
+	}
+
+	// Accepted tokens come from the token registry, configured tokens missing from it are added on startup
+	tokenRegistry := paymentservice.NewTokenRegistry(
+		paymentrepo.NewPostgresTokenRepository(s.db),
+		paymentservice.DefaultTokenCacheTTL,
+		logger.GetLogger().Logger,
+	)
+	var configuredTokens []paymentdomain.Token
+	for _, token := range s.config.ConfiguredTokens() {
+		configuredTokens = append(configuredTokens, paymentdomain.Token{
+			Chain:    paymentdomain.Chain(token.Chain),
+			Symbol:   token.Symbol,
+			Address:  token.Address,
+			Decimals: token.Decimals,
+		})
+	}
+	if err := tokenRegistry.Bootstrap(configuredTokens); err != nil {
+		logger.Error("Failed to add configured tokens to the token registry", err)
+	}
+
+	invoiceRepo := paymentrepo.NewPostgresInvoiceRepository(s.db)
+	paymentLinkRepo := paymentrepo.NewPostgresPaymentLinkRepository(s.db)
+	subscriptionRepo := paymentrepo.NewPostgresSubscriptionRepository(s.db)
+
+	// Payments, payment links, invoices, subscriptions and payment batches notify merchants through the worker webhook queue
+	webhookQueue, _ := worker.NewQueue(&worker.QueueConfig{
+		RedisAddr:     s.config.GetRedisAddr(),
+		RedisPassword: s.config.Redis.Password,
+		RedisDB:       s.config.Redis.DB,
+	})
+	ledgerService := ledgerservice.NewLedgerService(
+		ledgerrepository.NewLedgerRepository(s.db),
+		balanceRepo,
+		s.db,
+	)
+	// Simulated test payments are booked to the sandbox merchant's accounts and the test: system accounts
+	testModeLedgerService := ledgerservice.NewTestModeLedgerService(
+		ledgerrepository.NewLedgerRepository(s.db),
+		balanceRepo,
+		s.db,
+	)
 	paymentService := paymentservice.NewPaymentService(
 		paymentRepo,
-		merchantAdapter,
+		paymentrepo.NewPostgresPaymentTransferRepository(s.db),
+		merchantRepo,
 		exchangeRateAdapter,
 		complianceAdapter,
 		amlAdapter,
@@ -238,9 +330,30 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 			DefaultChain:    "solana",
 			DefaultCurrency: "USDT",
 			WalletAddress:   s.solanaWallet.GetAddress(),
+			ChainWallets:    chainWallets,
+			TokenRegistry:   tokenRegistry,
 			FeePercentage:   0.01,
 			ExpiryMinutes:   30,
 			RedisClient:     redisClient,
+
+			DepositAddressRepository: depositAddressRepo,
+			DepositAddressGenerator:  depositAddressGen,
+			DepositAddressQueue:      webhookQueue,
+
+			QuoteRepository: paymentrepo.NewPostgresQuoteRepository(s.db),
+			QuoteSigningKey: s.config.Security.QuoteSigningKey,
+
+			InvoiceRepository:      invoiceRepo,
+			SubscriptionRepository: subscriptionRepo,
+			PaymentLinkRepository:  paymentLinkRepo,
+
+			OwnershipChallengeRepository: paymentrepo.NewPostgresOwnershipChallengeRepository(s.db),
+
+			// Merchant cancellations, expiry extensions and test payment simulations
+			LedgerService:         ledgerService,
+			TestModeLedgerService: testModeLedgerService,
+			WebhookPublisher:      webhookQueue,
+			AuditRecorder:         legacy.NewAuditAdapter(auditRepo),
 		},
 		logger.GetLogger().Logger,
 	)
@@ -250,14 +363,90 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		s.db,
 	)
 
+	// Refunds are only created here, the worker sends them on-chain
+	refundService := paymentservice.NewRefundService(
+		paymentRepo,
+		paymentrepo.NewPostgresPaymentTransferRepository(s.db),
+		paymentrepo.NewPostgresRefundRepository(s.db),
+		ledgerService,
+		paymentservice.RefundServiceConfig{},
+		logger.GetLogger().Logger,
+	)
+
+	paymentLinkService := paymentservice.NewPaymentLinkService(
+		paymentService,
+		paymentLinkRepo,
+		paymentservice.PaymentLinkServiceConfig{WebhookPublisher: webhookQueue},
+		logger.GetLogger().Logger,
+	)
+	invoiceService := paymentservice.NewInvoiceService(
+		paymentService,
+		invoiceRepo,
+		paymentservice.InvoiceServiceConfig{WebhookPublisher: webhookQueue},
+		logger.GetLogger().Logger,
+	)
+	paymentBatchService := paymentservice.NewPaymentBatchService(
+		paymentService,
+		paymentrepo.NewPostgresPaymentBatchRepository(s.db),
+		paymentservice.PaymentBatchServiceConfig{
+			BatchQueue:       webhookQueue,
+			WebhookPublisher: webhookQueue,
+		},
+		logger.GetLogger().Logger,
+	)
+
+	// Solana Pay transaction requests need the RPC for recent blockhashes
+	solanaPayConfig := paymentservice.SolanaPayServiceConfig{
+		Label:   s.config.Solana.PayLabel,
+		IconURL: s.config.Solana.PayIconURL,
+	}
+	if s.solanaClient != nil && s.solanaWallet != nil {
+		solanaPayConfig.TransactionBuilder = paymentblockchain.NewSolanaPayTransactionBuilder(s.solanaClient, s.solanaWallet)
+	}
+	solanaPayService := paymentservice.NewSolanaPayService(
+		paymentRepo,
+		tokenRegistry,
+		solanaPayConfig,
+		logger.GetLogger().Logger,
+	)
+
 	// Initialize handlers
 	// Use storage base URL or construct from API config
 	baseURL := s.config.Storage.BaseURL
 	if baseURL == "" {
 		baseURL = fmt.Sprintf("http://%s:%d", s.config.API.Host, s.config.API.Port)
 	}
+
+	// Billing cycles are issued by the worker, the API only manages plans and subscriptions
+	subscriptionService := paymentservice.NewSubscriptionService(
+		paymentService,
+		paymentrepo.NewPostgresSubscriptionPlanRepository(s.db),
+		subscriptionRepo,
+		paymentservice.SubscriptionServiceConfig{
+			WebhookPublisher:   webhookQueue,
+			PaymentPageBaseURL: baseURL,
+		},
+		logger.GetLogger().Logger,
+	)
 	exchangeRateHTTPAdapter := legacy.NewExchangeRateHTTPAdapter(exchangeRateService)
-	paymentHandler := paymenthttp.NewPaymentHandler(paymentService, complianceService, exchangeRateHTTPAdapter, baseURL)
+	// Payment QR codes use EIP-681 on EVM chains with a known chain ID
+	qrChainIDs := map[paymentdomain.Chain]int64{paymentdomain.ChainBSC: s.config.BSC.ChainID}
+	for _, network := range s.config.EVMNetworks {
+		qrChainIDs[paymentdomain.Chain(network.Name)] = network.ChainID
+	}
+	paymentHandler := paymenthttp.NewPaymentHandler(
+		paymentService,
+		complianceService,
+		exchangeRateHTTPAdapter,
+		paymenthttp.QRCodeConfig{Tokens: tokenRegistry, ChainIDs: qrChainIDs},
+		baseURL,
+	)
+	refundHandler := paymenthttp.NewRefundHandler(refundService)
+	paymentLinkHandler := paymenthttp.NewPaymentLinkHandler(paymentLinkService, baseURL)
+	invoiceHandler := paymenthttp.NewInvoiceHandler(invoiceService, baseURL)
+	paymentBatchHandler := paymenthttp.NewPaymentBatchHandler(paymentBatchService, baseURL)
+	solanaPayHandler := paymenthttp.NewSolanaPayHandler(solanaPayService)
+	subscriptionHandler := paymenthttp.NewSubscriptionHandler(subscriptionService)
 
 	// Use module handlers
 	payoutHandler := payouthandler.NewPayoutHandler(payoutService)
@@ -284,6 +473,19 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 	kycStorageAdapter := &kycStorageAdapter{storage: baseStorageService}
 	kycHandler := merchanthandler.NewKYCHandler(kycStorageAdapter, kycDocumentRepo)
 
+	// Exports are written by the worker, the API queues them and issues download URLs
+	exportService := exportservice.NewExportService(
+		exportrepository.NewPostgresExportRepository(s.db),
+		paymentRepo,
+		payoutRepo,
+		ledgerrepository.NewLedgerRepository(s.db),
+		baseStorageService,
+		s.config.Storage.Bucket,
+		exportservice.ExportServiceConfig{ExportQueue: webhookQueue},
+		logger.GetLogger().Logger,
+	)
+	exportHandler := exporthandler.NewExportHandler(exportService)
+
 	// Compliance module handlers
 	amlRuleHandler := compliancehandler.NewAMLRuleHandler(amlRuleRepo)
 
@@ -303,6 +505,16 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		walletBalanceGetter,
 	)
 
+	// Idempotency-Key support for mutating merchant endpoints (disabled without Redis)
+	var idempotencyStore middleware.IdempotencyStore
+	if redisClient != nil {
+		idempotencyStore = middleware.NewRedisIdempotencyStore(redisClient)
+	}
+	idempotency := middleware.Idempotency(middleware.IdempotencyConfig{
+		Store: idempotencyStore,
+		TTL:   24 * time.Hour,
+	})
+
 	// Public routes (no authentication required)
 	router.GET("/health", healthHandler.Health)
 
@@ -316,19 +528,125 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		publicGroup := v1.Group("/public")
 		{
 			publicGroup.GET("/payments/:id/status", paymentHandler.GetPublicPaymentStatus)
+			publicGroup.GET("/payment-links/:id", paymentLinkHandler.GetPublicPaymentLink)
+			publicGroup.POST("/payment-links/:id/payments", s.paymentLinkRateLimitMiddleware(), paymentLinkHandler.OpenPaymentLink)
+			publicGroup.GET("/invoices/:id", invoiceHandler.GetPublicInvoice)
+			publicGroup.POST("/invoices/:id/payments", invoiceHandler.PayInvoice)
 		}
 
+		// Solana Pay transaction requests, called by the payer's wallet
+		v1.GET("/solana-pay/:payment_id", solanaPayHandler.GetTransactionRequest)
+		v1.POST("/solana-pay/:payment_id", solanaPayHandler.CreateTransaction)
+
 		// Payment routes (API key authentication required)
 		paymentGroup := v1.Group("/payments")
 		paymentGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
 			MerchantRepo: merchantRepo,
 			Cache:        s.cache,
 			CacheTTL:     5 * time.Minute,
-		}))
+		}), idempotency)
 		{
 			paymentGroup.POST("", paymentHandler.CreatePayment)
+			paymentGroup.POST("/batch", paymentBatchHandler.CreatePaymentBatch)
+			paymentGroup.GET("/batches/:id", paymentBatchHandler.GetPaymentBatch)
 			paymentGroup.GET("/:id", paymentHandler.GetPayment)
+			paymentGroup.POST("/:id/cancel", paymentHandler.CancelPayment)
+			paymentGroup.POST("/:id/extend", paymentHandler.ExtendPayment)
 			paymentGroup.GET("", paymentHandler.ListPayments)
+			paymentGroup.POST("/:id/refunds", refundHandler.CreateRefund)
+			paymentGroup.GET("/:id/refunds", refundHandler.ListRefunds)
+		}
+
+		// Rate-locked quotes (API key authentication required)
+		quoteGroup := v1.Group("/quotes")
+		quoteGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			quoteGroup.POST("", paymentHandler.CreateQuote)
+		}
+
+		// Wallet ownership challenges for EVM payers (API key authentication required)
+		ownershipChallengeGroup := v1.Group("/ownership-challenges")
+		ownershipChallengeGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			ownershipChallengeGroup.POST("", paymentHandler.CreateOwnershipChallenge)
+		}
+
+		// Payment links (API key authentication required)
+		paymentLinkGroup := v1.Group("/payment-links")
+		paymentLinkGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			paymentLinkGroup.POST("", paymentLinkHandler.CreatePaymentLink)
+			paymentLinkGroup.GET("", paymentLinkHandler.ListPaymentLinks)
+			paymentLinkGroup.GET("/:id", paymentLinkHandler.GetPaymentLink)
+			paymentLinkGroup.POST("/:id/deactivate", paymentLinkHandler.DeactivatePaymentLink)
+		}
+
+		// Invoices (API key authentication required)
+		invoiceGroup := v1.Group("/invoices")
+		invoiceGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			invoiceGroup.POST("", invoiceHandler.CreateInvoice)
+			invoiceGroup.GET("", invoiceHandler.ListInvoices)
+			invoiceGroup.GET("/:id", invoiceHandler.GetInvoice)
+			invoiceGroup.POST("/:id/void", invoiceHandler.VoidInvoice)
+		}
+
+		// Subscription plans (API key authentication required)
+		subscriptionPlanGroup := v1.Group("/subscription-plans")
+		subscriptionPlanGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			subscriptionPlanGroup.POST("", subscriptionHandler.CreatePlan)
+			subscriptionPlanGroup.GET("", subscriptionHandler.ListPlans)
+			subscriptionPlanGroup.GET("/:id", subscriptionHandler.GetPlan)
+			subscriptionPlanGroup.POST("/:id/archive", subscriptionHandler.ArchivePlan)
+		}
+
+		// Subscriptions (API key authentication required)
+		subscriptionGroup := v1.Group("/subscriptions")
+		subscriptionGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			subscriptionGroup.POST("", subscriptionHandler.CreateSubscription)
+			subscriptionGroup.GET("", subscriptionHandler.ListSubscriptions)
+			subscriptionGroup.GET("/:id", subscriptionHandler.GetSubscription)
+			subscriptionGroup.GET("/:id/cycles", subscriptionHandler.ListCycles)
+			subscriptionGroup.POST("/:id/cancel", subscriptionHandler.CancelSubscription)
+		}
+
+		// Exports of payments, payouts and ledger entries (API key authentication required)
+		exportGroup := v1.Group("/exports")
+		exportGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			exportGroup.POST("", exportHandler.CreateExport)
+			exportGroup.GET("", exportHandler.ListExports)
+			exportGroup.GET("/:id", exportHandler.GetExport)
 		}
 
 		// Merchant routes (API key authentication required)
@@ -337,7 +655,7 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 			MerchantRepo: merchantRepo,
 			Cache:        s.cache,
 			CacheTTL:     5 * time.Minute,
-		}))
+		}), idempotency)
 		{
 			// TODO: merchantGroup.GET("/balance", merchantHandler.GetBalance)
 			// TODO: merchantGroup.GET("/transactions", merchantHandler.GetTransactions)
@@ -345,6 +663,17 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 			merchantGroup.GET("/payouts", payoutHandler.ListPayouts)
 			merchantGroup.GET("/payouts/:id", payoutHandler.GetPayout)
 		}
+
+		// Sandbox routes (sk_test_ API key authentication required)
+		testGroup := v1.Group("/test")
+		testGroup.Use(middleware.APIKeyAuth(middleware.APIKeyAuthConfig{
+			MerchantRepo: merchantRepo,
+			Cache:        s.cache,
+			CacheTTL:     5 * time.Minute,
+		}), idempotency)
+		{
+			testGroup.POST("/payments/:id/simulate", paymentHandler.SimulatePayment)
+		}
 	}
 
 	// WebSocket routes (real-time payment status updates)
@@ -380,6 +709,8 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		adminGroup.POST("/merchants/:id/kyc/reject", adminHandler.RejectKYC)
 		adminGroup.GET("/merchants", adminHandler.ListMerchants)
 		adminGroup.GET("/merchants/:id", adminHandler.GetMerchant)
+		adminGroup.PUT("/merchants/:id/payment-tolerance", adminHandler.UpdatePaymentTolerance)
+		adminGroup.PUT("/merchants/:id/address-mode", adminHandler.UpdateAddressMode)
 
 		// KYC Document management
 		adminGroup.GET("/kyc/documents/pending", adminHandler.GetPendingKYCDocuments)
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,9 @@ type Config struct {
 	Solana        SolanaConfig
 	BSC           BSCConfig
 	TRON          TRONConfig
+	EVMNetworks   []EVMNetworkConfig // Additional EVM networks (Ethereum, Polygon, Arbitrum, ...)
+	Confirmation  ConfirmationConfig
+	Subscription  SubscriptionConfig
 	ExchangeRate  ExchangeRateConfig
 	Security      SecurityConfig
 	Email         EmailConfig
@@ -80,6 +84,8 @@ type SolanaConfig struct {
 	ConfirmationLevel string // finalized, confirmed
 	USDTMint          string
 	USDCMint          string
+	PayLabel          string // Solana Pay transaction request label shown by wallets
+	PayIconURL        string // Solana Pay transaction request icon (SVG, PNG or WebP)
 }
 
 // BSCConfig contains Binance Smart Chain configuration
@@ -91,11 +97,14 @@ type BSCConfig struct {
 	ChainID          int64
 	USDTContract     string
 	BUSDContract     string
+	DepositXPub      string // Account xpub (m/44'/60'/0') deposit addresses are derived from
+	DepositXPrv      string // Matching xprv, only needed by the worker to sweep deposits
 }
 
 // TRONConfig contains TRON blockchain configuration
 type TRONConfig struct {
-	RPCURL             string
+	RPCURL             string // TronGrid-compatible HTTP API
+	APIKey             string // Optional TronGrid API key
 	WalletPrivateKey   string
 	WalletAddress      string
 	ColdWalletAddress  string
@@ -107,6 +116,47 @@ type TRONConfig struct {
 	SweepIntervalHours int    // Auto-sweep interval in hours (default: 6)
 }
 
+// EVMNetworkConfig contains the configuration of one EVM-compatible network
+// Networks are listed in EVM_NETWORKS and configured with EVM_<NAME>_* variables
+type EVMNetworkConfig struct {
+	Name                  string // Chain name stored on payments, e.g. "ethereum", "polygon"
+	RPCURL                string
+	ChainID               int64
+	WalletAddress         string
+	RequiredConfirmations int64
+	PollIntervalSeconds   int
+	Tokens                []EVMTokenConfig
+}
+
+// EVMTokenConfig contains an ERC-20 token accepted on an EVM network
+type EVMTokenConfig struct {
+	Symbol   string
+	Contract string
+	Decimals int
+}
+
+// TokenConfig is a token listed in the deployment configuration
+// It seeds the token registry, which is the source of truth once the token is stored
+type TokenConfig struct {
+	Chain    string
+	Symbol   string
+	Address  string
+	Decimals int
+}
+
+// ConfirmationConfig contains the confirmation depths payments must reach before they complete
+type ConfirmationConfig struct {
+	Policies        map[string]string // Chain name -> MIN_AMOUNT_USD:REQUIREMENT bands, e.g. "0:15,1000:finalized"
+	ReorgWatchHours int               // How long confirmed transfers are re-checked for reorgs (default: 24)
+}
+
+// SubscriptionConfig contains the dunning schedule of subscription billing cycles
+type SubscriptionConfig struct {
+	RetryScheduleHours []int  // Hours after the due date at which unpaid cycles are retried (default: 24,72,120)
+	GracePeriodDays    int    // Days after the due date before an unpaid subscription is canceled (default: 7)
+	PaymentPageBaseURL string // Base URL of the hosted payment page linked in payer emails
+}
+
 // ExchangeRateConfig contains exchange rate API configuration
 type ExchangeRateConfig struct {
 	PrimaryAPI   string
@@ -121,9 +171,12 @@ type SecurityConfig struct {
 	JWTExpirationHours int
 	APIKeyLength       int
 	WebhookSigningKey  string
+	QuoteSigningKey    string // Signs rate-locked quotes, quotes are disabled without it
 	PasswordMinLength  int
 	MaxLoginAttempts   int
 	RateLimitPerMinute int
+	// PaymentLinkRateLimitPerMinute bounds the payments opened per payment link on the public route
+	PaymentLinkRateLimitPerMinute int
 }
 
 // EmailConfig contains email service configuration
@@ -181,6 +234,11 @@ func Load() (*Config, error) {
 		Timeout: getEnvAsInt("TRM_LABS_TIMEOUT", 30),
 	}
 
+	evmNetworks, err := loadEVMNetworks()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Environment: getEnv("ENV", "development"),
 		Version:     getEnv("VERSION", "1.0.0"),
@@ -223,6 +281,8 @@ func Load() (*Config, error) {
 			ConfirmationLevel: getEnv("SOLANA_CONFIRMATION_LEVEL", "finalized"),
 			USDTMint:          getEnv("SOLANA_USDT_MINT", "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"),
 			USDCMint:          getEnv("SOLANA_USDC_MINT", "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"),
+			PayLabel:          getEnv("SOLANA_PAY_LABEL", ""),
+			PayIconURL:        getEnv("SOLANA_PAY_ICON_URL", ""),
 		},
 		BSC: BSCConfig{
 			RPCURL:           getEnv("BSC_RPC_URL", "https://data-seed-prebsc-1-s1.binance.org:8545"),
@@ -232,9 +292,12 @@ func Load() (*Config, error) {
 			ChainID:          getEnvAsInt64("BSC_CHAIN_ID", 97),
 			USDTContract:     getEnv("BSC_USDT_CONTRACT", ""),
 			BUSDContract:     getEnv("BSC_BUSD_CONTRACT", ""),
+			DepositXPub:      getEnv("BSC_DEPOSIT_XPUB", ""),
+			DepositXPrv:      getEnv("BSC_DEPOSIT_XPRV", ""),
 		},
 		TRON: TRONConfig{
-			RPCURL:             getEnv("TRON_RPC_URL", "grpc.shasta.trongrid.io:50051"),
+			RPCURL:             getEnv("TRON_RPC_URL", "https://api.shasta.trongrid.io"),
+			APIKey:             getEnv("TRON_API_KEY", ""),
 			WalletPrivateKey:   getEnv("TRON_WALLET_PRIVATE_KEY", ""),
 			WalletAddress:      getEnv("TRON_WALLET_ADDRESS", ""),
 			ColdWalletAddress:  getEnv("TRON_COLD_WALLET_ADDRESS", ""),
@@ -245,6 +308,16 @@ func Load() (*Config, error) {
 			SweepThresholdUSD:  getEnvAsInt64("TRON_SWEEP_THRESHOLD_USD", 10000),
 			SweepIntervalHours: getEnvAsInt("TRON_SWEEP_INTERVAL_HOURS", 6),
 		},
+		EVMNetworks: evmNetworks,
+		Confirmation: ConfirmationConfig{
+			Policies:        loadConfirmationPolicies(evmNetworks),
+			ReorgWatchHours: getEnvAsInt("REORG_WATCH_WINDOW_HOURS", 24),
+		},
+		Subscription: SubscriptionConfig{
+			RetryScheduleHours: getEnvAsIntSlice("SUBSCRIPTION_RETRY_SCHEDULE_HOURS", []int{24, 72, 120}),
+			GracePeriodDays:    getEnvAsInt("SUBSCRIPTION_GRACE_PERIOD_DAYS", 7),
+			PaymentPageBaseURL: getEnv("PAYMENT_PAGE_BASE_URL", ""),
+		},
 		ExchangeRate: ExchangeRateConfig{
 			PrimaryAPI:   getEnv("EXCHANGE_RATE_PRIMARY_API", "https://api.coingecko.com/api/v3"),
 			SecondaryAPI: getEnv("EXCHANGE_RATE_SECONDARY_API", "https://api.binance.com/api/v3"),
@@ -256,9 +329,12 @@ func Load() (*Config, error) {
 			JWTExpirationHours: getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
 			APIKeyLength:       getEnvAsInt("API_KEY_LENGTH", 32),
 			WebhookSigningKey:  getEnv("WEBHOOK_SIGNING_KEY", ""),
+			QuoteSigningKey:    getEnv("QUOTE_SIGNING_KEY", ""),
 			PasswordMinLength:  getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
 			MaxLoginAttempts:   getEnvAsInt("MAX_LOGIN_ATTEMPTS", 5),
 			RateLimitPerMinute: getEnvAsInt("RATE_LIMIT_PER_MINUTE", 100),
+
+			PaymentLinkRateLimitPerMinute: getEnvAsInt("PAYMENT_LINK_RATE_LIMIT_PER_MINUTE", 30),
 		},
 		Email: EmailConfig{
 			Provider:     getEnv("EMAIL_PROVIDER", "smtp"),
@@ -314,6 +390,9 @@ func (c *Config) Validate() error {
 		if c.Security.WebhookSigningKey == "" {
 			errors = append(errors, "WEBHOOK_SIGNING_KEY is required in production")
 		}
+		if c.Security.QuoteSigningKey == "" {
+			errors = append(errors, "QUOTE_SIGNING_KEY is required in production")
+		}
 		if c.Solana.WalletPrivateKey == "" {
 			errors = append(errors, "SOLANA_WALLET_PRIVATE_KEY is required")
 		}
@@ -360,6 +439,9 @@ func (c *Config) Validate() error {
 		errors = append(errors, "TRON_COLD_WALLET_ADDRESS is required in production")
 	}
 
+	// Validate EVM networks
+	errors = append(errors, c.validateEVMNetworks()...)
+
 	if len(errors) > 0 {
 		return fmt.Errorf("configuration validation failed:\n- %s", strings.Join(errors, "\n- "))
 	}
@@ -367,6 +449,56 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateEVMNetworks checks every configured EVM network
+func (c *Config) validateEVMNetworks() []string {
+	var errors []string
+	seen := make(map[string]bool)
+
+	for _, network := range c.EVMNetworks {
+		prefix := evmEnvPrefix(network.Name)
+
+		if !evmNetworkNamePattern.MatchString(network.Name) {
+			errors = append(errors, fmt.Sprintf("EVM network name %q must be lowercase letters, digits or underscores (max 20)", network.Name))
+			continue
+		}
+		if reservedChainNames[network.Name] {
+			errors = append(errors, fmt.Sprintf("EVM network %q has its own configuration and cannot be listed in EVM_NETWORKS", network.Name))
+			continue
+		}
+		if seen[network.Name] {
+			errors = append(errors, fmt.Sprintf("EVM network %q is listed more than once", network.Name))
+			continue
+		}
+		seen[network.Name] = true
+
+		if network.RPCURL == "" {
+			errors = append(errors, prefix+"RPC_URL is required")
+		}
+		if network.ChainID <= 0 {
+			errors = append(errors, prefix+"CHAIN_ID is required")
+		}
+		if !evmAddressPattern.MatchString(network.WalletAddress) {
+			errors = append(errors, prefix+"WALLET_ADDRESS must be a 0x-prefixed address")
+		}
+		if len(network.Tokens) == 0 {
+			errors = append(errors, prefix+"TOKENS is required")
+		}
+		for _, token := range network.Tokens {
+			if !supportedEVMTokens[token.Symbol] {
+				errors = append(errors, fmt.Sprintf("%sTOKENS: unsupported token %s", prefix, token.Symbol))
+			}
+			if !evmAddressPattern.MatchString(token.Contract) {
+				errors = append(errors, fmt.Sprintf("%sTOKENS: invalid %s contract address %s", prefix, token.Symbol, token.Contract))
+			}
+			if token.Decimals < 0 || token.Decimals > 36 {
+				errors = append(errors, fmt.Sprintf("%sTOKENS: invalid %s decimals %d", prefix, token.Symbol, token.Decimals))
+			}
+		}
+	}
+
+	return errors
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -400,6 +532,37 @@ func (c *Config) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%d", c.Redis.Host, c.Redis.Port)
 }
 
+// ConfiguredTokens lists the tokens configured for the built-in chains and the EVM networks
+// Tokens without an address are left out
+func (c *Config) ConfiguredTokens() []TokenConfig {
+	tokens := []TokenConfig{
+		{Chain: "solana", Symbol: "USDT", Address: c.Solana.USDTMint, Decimals: 6},
+		{Chain: "solana", Symbol: "USDC", Address: c.Solana.USDCMint, Decimals: 6},
+		{Chain: "bsc", Symbol: "USDT", Address: c.BSC.USDTContract, Decimals: 18},
+		{Chain: "bsc", Symbol: "BUSD", Address: c.BSC.BUSDContract, Decimals: 18},
+		{Chain: "tron", Symbol: "USDT", Address: c.TRON.USDTContract, Decimals: 6},
+		{Chain: "tron", Symbol: "USDC", Address: c.TRON.USDCContract, Decimals: 6},
+	}
+	for _, network := range c.EVMNetworks {
+		for _, token := range network.Tokens {
+			tokens = append(tokens, TokenConfig{
+				Chain:    network.Name,
+				Symbol:   token.Symbol,
+				Address:  token.Contract,
+				Decimals: token.Decimals,
+			})
+		}
+	}
+
+	configured := tokens[:0]
+	for _, token := range tokens {
+		if token.Address != "" {
+			configured = append(configured, token)
+		}
+	}
+	return configured
+}
+
 // Helper functions to read environment variables
 
 func getEnv(key, defaultValue string) string {
@@ -433,6 +596,103 @@ func getEnvAsInt64(key string, defaultValue int64) int64 {
 	return value
 }
 
+var (
+	// EVM network names are stored in VARCHAR(20) chain columns and used in environment variable names
+	evmNetworkNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+	evmAddressPattern     = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+	// Chains with a dedicated listener and configuration section
+	reservedChainNames = map[string]bool{"solana": true, "bsc": true, "tron": true}
+
+	// Tokens the exchange rate service can price
+	supportedEVMTokens = map[string]bool{"USDT": true, "USDC": true, "BUSD": true}
+)
+
+// loadEVMNetworks reads the networks listed in EVM_NETWORKS
+// Each network is configured with EVM_<NAME>_RPC_URL, _CHAIN_ID, _WALLET_ADDRESS, _CONFIRMATIONS,
+// _POLL_INTERVAL and _TOKENS, the latter as a list of SYMBOL:CONTRACT:DECIMALS entries
+func loadEVMNetworks() ([]EVMNetworkConfig, error) {
+	var networks []EVMNetworkConfig
+
+	for _, name := range getEnvAsSlice("EVM_NETWORKS", []string{}) {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := evmEnvPrefix(name)
+		tokens, err := parseEVMTokens(getEnv(prefix+"TOKENS", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %sTOKENS: %w", prefix, err)
+		}
+
+		networks = append(networks, EVMNetworkConfig{
+			Name:                  name,
+			RPCURL:                getEnv(prefix+"RPC_URL", ""),
+			ChainID:               getEnvAsInt64(prefix+"CHAIN_ID", 0),
+			WalletAddress:         getEnv(prefix+"WALLET_ADDRESS", ""),
+			RequiredConfirmations: getEnvAsInt64(prefix+"CONFIRMATIONS", 12),
+			PollIntervalSeconds:   getEnvAsInt(prefix+"POLL_INTERVAL", 10),
+			Tokens:                tokens,
+		})
+	}
+
+	return networks, nil
+}
+
+// loadConfirmationPolicies reads CONFIRMATION_POLICY_<CHAIN> for the built-in chains and every EVM network
+// Chains without a policy keep the default bands of the payment module
+func loadConfirmationPolicies(evmNetworks []EVMNetworkConfig) map[string]string {
+	chains := []string{"solana", "bsc", "tron"}
+	for _, network := range evmNetworks {
+		chains = append(chains, network.Name)
+	}
+
+	policies := make(map[string]string)
+	for _, chain := range chains {
+		if policy := getEnv("CONFIRMATION_POLICY_"+strings.ToUpper(chain), ""); policy != "" {
+			policies[chain] = policy
+		}
+	}
+
+	return policies
+}
+
+// parseEVMTokens parses a comma-separated list of SYMBOL:CONTRACT:DECIMALS entries
+func parseEVMTokens(value string) ([]EVMTokenConfig, error) {
+	var tokens []EVMTokenConfig
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("token %q must be SYMBOL:CONTRACT:DECIMALS", entry)
+		}
+
+		decimals, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("token %q has invalid decimals: %w", entry, err)
+		}
+
+		tokens = append(tokens, EVMTokenConfig{
+			Symbol:   strings.ToUpper(parts[0]),
+			Contract: parts[1],
+			Decimals: decimals,
+		})
+	}
+
+	return tokens, nil
+}
+
+// evmEnvPrefix returns the environment variable prefix of an EVM network
+func evmEnvPrefix(name string) string {
+	return "EVM_" + strings.ToUpper(name) + "_"
+}
+
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -440,3 +700,19 @@ func getEnvAsSlice(key string, defaultValue []string) []string {
 	}
 	return strings.Split(valueStr, ",")
 }
+
+func getEnvAsIntSlice(key string, defaultValue []int) []int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	values := make([]int, 0)
+	for _, part := range strings.Split(valueStr, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return defaultValue
+		}
+		values = append(values, value)
+	}
+	return values
+}
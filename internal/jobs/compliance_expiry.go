@@ -91,14 +91,19 @@ func (j *ComplianceExpiryJob) expirePayment(ctx context.Context, payment *paymen
 	}).Info("Expiring payment due to compliance timeout")
 
 	// Update payment status to failed with reason
-	payment.Status = paymentdomain.PaymentStatusFailed
+	reason := "Compliance data (FATF Travel Rule) not submitted within 24 hours deadline"
 	payment.FailureReason = sql.NullString{
 		Valid:  true,
-		String: "Compliance data (FATF Travel Rule) not submitted within 24 hours deadline",
+		String: reason,
 	}
 
-	// Update payment in database
-	if err := j.paymentRepo.Update(payment); err != nil {
+	transition, err := payment.TransitionTo(paymentdomain.PaymentStatusFailed, paymentdomain.PaymentActorWorker, "", reason)
+	if err != nil {
+		return err
+	}
+
+	// Update payment in database, a transfer or submission recorded meanwhile changes the version
+	if err := j.paymentRepo.Transition(payment, transition); err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
 
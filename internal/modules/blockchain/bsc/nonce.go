@@ -0,0 +1,42 @@
+package bsc
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceReservationTimeout is how long a reserved nonce is kept ahead of the node's pending nonce
+// A transaction signed but not broadcast by then is considered abandoned and its nonce is reused
+const NonceReservationTimeout = 2 * time.Minute
+
+// nonceManagers holds the nonce manager of every wallet address, shared by the wallets loaded for it
+var nonceManagers sync.Map // common.Address -> *nonceManager
+
+// nonceManager hands out the nonces of the transactions of one wallet
+// The node's pending nonce does not count transactions that are signed but not broadcast yet,
+// so concurrent sends (refunds, gas top-ups) would otherwise sign with the same nonce
+type nonceManager struct {
+	mu         sync.Mutex
+	next       uint64
+	reservedAt time.Time
+}
+
+// reserve returns the nonce of the next transaction given the node's pending nonce
+func (m *nonceManager) reserve(pendingNonce func() (uint64, error)) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, err := pendingNonce()
+	if err != nil {
+		return 0, err
+	}
+
+	if pending > m.next || time.Since(m.reservedAt) > NonceReservationTimeout {
+		m.next = pending
+	}
+
+	nonce := m.next
+	m.next++
+	m.reservedAt = time.Now()
+	return nonce, nil
+}
@@ -0,0 +1,59 @@
+package bsc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceManager_ConcurrentReservationsAreUnique(t *testing.T) {
+	manager := &nonceManager{}
+	// The node does not see any of the transactions until they are broadcast
+	pending := func() (uint64, error) { return 7, nil }
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := manager.reserve(pending)
+			require.NoError(t, err)
+			mu.Lock()
+			seen[nonce] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, 10)
+	for nonce := uint64(7); nonce < 17; nonce++ {
+		assert.True(t, seen[nonce], "nonce %d not reserved", nonce)
+	}
+}
+
+func TestNonceManager_FollowsNodeAndReusesAbandonedNonces(t *testing.T) {
+	manager := &nonceManager{}
+	pending := uint64(3)
+	node := func() (uint64, error) { return pending, nil }
+
+	nonce, err := manager.reserve(node)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), nonce)
+
+	// Transactions sent from elsewhere moved the node ahead
+	pending = 10
+	nonce, err = manager.reserve(node)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), nonce)
+
+	// Nonce 10 was signed but never broadcast, it is reused once the reservation timed out
+	manager.reservedAt = time.Now().Add(-NonceReservationTimeout - time.Second)
+	nonce, err = manager.reserve(node)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), nonce)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/shopspring/decimal"
 )
@@ -225,6 +226,178 @@ func ParseAddress(address string) (common.Address, error) {
 	return common.HexToAddress(address), nil
 }
 
-// Note: For sending transactions (not needed for listener, but for future implementation)
-// You would implement SendBEP20Transfer, SendBNB, etc. methods here
-// These would use the private key to sign transactions and broadcast them
+// SendBEP20Transfer signs and broadcasts a BEP20 transfer(to, amount) from the wallet
+// The amount is given in whole tokens and scaled by the token's decimals
+// Returns the transaction hash once the transaction is accepted by the node
+func (w *Wallet) SendBEP20Transfer(ctx context.Context, tokenContract common.Address, to common.Address, amount decimal.Decimal) (common.Hash, error) {
+	signedTx, err := w.SignBEP20Transfer(ctx, tokenContract, to, amount)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if err := w.SendSignedTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, err
+	}
+
+	return signedTx.Hash(), nil
+}
+
+// SignBEP20Transfer builds and signs a BEP20 transfer(to, amount) from the wallet without broadcasting it
+// The hash of the returned transaction identifies it once it is sent with SendSignedTransaction
+func (w *Wallet) SignBEP20Transfer(ctx context.Context, tokenContract common.Address, to common.Address, amount decimal.Decimal) (*types.Transaction, error) {
+	data, err := w.bep20TransferData(ctx, tokenContract, to, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice, gasLimit, err := w.estimateGas(ctx, tokenContract, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserved last, a transaction that is never signed would leave a gap in the nonces
+	nonce, err := w.reserveNonce(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       &tokenContract,
+		Value:    big.NewInt(0),
+		Data:     data,
+	})
+
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(w.client.GetChainID()), w.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	return signedTx, nil
+}
+
+// EstimateBEP20TransferFee returns the BNB fee of a BEP20 transfer(to, amount) from the wallet,
+// estimated the way SignBEP20Transfer prices the transaction
+func (w *Wallet) EstimateBEP20TransferFee(ctx context.Context, tokenContract common.Address, to common.Address, amount decimal.Decimal) (decimal.Decimal, error) {
+	data, err := w.bep20TransferData(ctx, tokenContract, to, amount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	gasPrice, gasLimit, err := w.estimateGas(ctx, tokenContract, data)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	return decimal.NewFromBigInt(fee, -18), nil
+}
+
+// bep20TransferData encodes the transfer(to, amount) call, the amount is given in whole tokens
+func (w *Wallet) bep20TransferData(ctx context.Context, tokenContract common.Address, to common.Address, amount decimal.Decimal) ([]byte, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, fmt.Errorf("transfer amount must be positive")
+	}
+
+	decimals, err := w.GetBEP20Decimals(ctx, tokenContract)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token decimals: %w", err)
+	}
+
+	// Convert to smallest token unit
+	rawAmount := amount.Shift(int32(decimals))
+	if !rawAmount.Equal(rawAmount.Truncate(0)) {
+		return nil, fmt.Errorf("amount %s exceeds token precision of %d decimals", amount.String(), decimals)
+	}
+
+	// Call transfer(address,uint256) function
+	// Method ID: 0xa9059cbb
+	data := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(rawAmount.BigInt().Bytes(), 32)...)
+
+	return data, nil
+}
+
+// estimateGas returns the gas price and limit of a contract call from the wallet
+func (w *Wallet) estimateGas(ctx context.Context, contract common.Address, data []byte) (*big.Int, uint64, error) {
+	ethClient := w.client.GetEthClient()
+
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	gasLimit, err := ethClient.EstimateGas(ctx, ethereum.CallMsg{
+		From: w.address,
+		To:   &contract,
+		Data: data,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	return gasPrice, gasLimit, nil
+}
+
+// reserveNonce returns the nonce of the next transaction signed by the wallet
+// Nonces are reserved per address, so transactions signed concurrently never share one
+func (w *Wallet) reserveNonce(ctx context.Context) (uint64, error) {
+	manager, _ := nonceManagers.LoadOrStore(w.address, &nonceManager{})
+	return manager.(*nonceManager).reserve(func() (uint64, error) {
+		return w.client.GetEthClient().PendingNonceAt(ctx, w.address)
+	})
+}
+
+// SendSignedTransaction broadcasts a transaction signed by the wallet
+// An error does not prove the node rejected the transaction, check its hash before signing another one
+func (w *Wallet) SendSignedTransaction(ctx context.Context, signedTx *types.Transaction) error {
+	if err := w.client.GetEthClient().SendTransaction(ctx, signedTx); err != nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SendBNB signs and broadcasts a native BNB transfer from the wallet
+// The amount is given in BNB and scaled to wei
+func (w *Wallet) SendBNB(ctx context.Context, to common.Address, amount decimal.Decimal) (common.Hash, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return common.Hash{}, fmt.Errorf("transfer amount must be positive")
+	}
+
+	weiAmount := amount.Shift(18).Truncate(0)
+
+	ethClient := w.client.GetEthClient()
+
+	nonce, err := w.reserveNonce(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      21000, // Plain value transfer
+		To:       &to,
+		Value:    weiAmount.BigInt(),
+	})
+
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(w.client.GetChainID()), w.privateKey)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	if err := ethClient.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return signedTx.Hash(), nil
+}
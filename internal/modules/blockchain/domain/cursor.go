@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/shopspring/decimal"
+)
+
+// ListenerCursor is the last fully processed position of a listener on a wallet
+// EVM and TRON listeners track block numbers, the Solana listener tracks the newest
+// processed signature and stores its slot as the block number.
+type ListenerCursor struct {
+	Chain         paymentDomain.Chain `json:"chain" db:"chain"`
+	WalletAddress string              `json:"wallet_address" db:"wallet_address"`
+	BlockNumber   int64               `json:"block_number" db:"block_number"`
+	Signature     sql.NullString      `json:"signature,omitempty" db:"signature"`
+	UpdatedAt     time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+func (ListenerCursor) TableName() string {
+	return "listener_cursors"
+}
+
+// GetSignature returns the Solana signature if available
+func (c *ListenerCursor) GetSignature() string {
+	if c.Signature.Valid {
+		return c.Signature.String
+	}
+	return ""
+}
+
+// RescanStatus represents the lifecycle of a rescan request
+type RescanStatus string
+
+const (
+	RescanStatusPending   RescanStatus = "pending"   // Waiting for the listener to pick it up
+	RescanStatusCompleted RescanStatus = "completed" // The range or transaction was processed again
+	RescanStatusFailed    RescanStatus = "failed"    // The listener could not process it, see ErrorMessage
+)
+
+// RescanRequest asks a listener to process a block range or a single transaction again
+// Already recorded transactions are not skipped, payment confirmation is idempotent per tx hash.
+// On Solana the block range is a slot range.
+type RescanRequest struct {
+	ID           string              `json:"id" db:"id"`
+	Chain        paymentDomain.Chain `json:"chain" db:"chain"`
+	FromBlock    sql.NullInt64       `json:"from_block,omitempty" db:"from_block"`
+	ToBlock      sql.NullInt64       `json:"to_block,omitempty" db:"to_block"`
+	TxHash       sql.NullString      `json:"tx_hash,omitempty" db:"tx_hash"`
+	Status       RescanStatus        `json:"status" db:"status"`
+	ErrorMessage sql.NullString      `json:"error_message,omitempty" db:"error_message"`
+	RequestedBy  string              `json:"requested_by" db:"requested_by"`
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	ProcessedAt  sql.NullTime        `json:"processed_at,omitempty" db:"processed_at"`
+}
+
+func (RescanRequest) TableName() string {
+	return "listener_rescan_requests"
+}
+
+// IsTransactionRescan returns true if the request targets a single transaction
+func (r *RescanRequest) IsTransactionRescan() bool {
+	return r.TxHash.Valid && r.TxHash.String != ""
+}
+
+// ObservedTransfer is a token transfer to a watched address seen by a listener
+type ObservedTransfer struct {
+	TxHash       string
+	BlockNumber  uint64
+	BlockTime    time.Time // Optional
+	FromAddress  string
+	ToAddress    string
+	Amount       decimal.Decimal
+	Currency     string
+	TokenAddress string // Token contract or mint
+	Memo         string // Payment reference carried by the transfer, empty for deposit address transfers
+	PaymentID    string // Payment identified by the memo or the deposit address, empty if neither
+}
+
+// ListenerStore persists the progress of a listener, it is bound to a single chain and wallet
+// Listeners resume from the saved cursor after a restart and skip transactions that are already recorded.
+type ListenerStore interface {
+	// LoadCursor returns the saved cursor, or nil if the listener has not saved one yet
+	LoadCursor(ctx context.Context) (*ListenerCursor, error)
+
+	// SaveCursor stores the last fully processed block (slot on Solana) and Solana signature
+	SaveCursor(ctx context.Context, blockNumber uint64, signature string) error
+
+	// IsTransactionRecorded returns true if the transaction is already in blockchain_transactions
+	IsTransactionRecorded(ctx context.Context, txHash string) (bool, error)
+
+	// RecordTransaction stores a confirmed transfer in blockchain_transactions
+	RecordTransaction(ctx context.Context, transfer ObservedTransfer) error
+
+	// RecordInboundTransfer stores every transfer to a watched address in inbound_transfers
+	// matchErr is the error of the payment confirmation, transfers that did not confirm a payment
+	// (no reference, unknown reference or refused by the payment) are queued for an operator
+	RecordInboundTransfer(ctx context.Context, transfer ObservedTransfer, matchErr error) error
+
+	// PendingRescans returns the rescan requests waiting for this listener
+	PendingRescans(ctx context.Context) ([]*RescanRequest, error)
+
+	// CompleteRescan marks a rescan request as completed, or failed if rescanErr is set
+	CompleteRescan(ctx context.Context, id string, rescanErr error) error
+}
@@ -34,7 +34,7 @@ type BlockchainTransaction struct {
 	ID string `json:"id" db:"id"`
 
 	// Blockchain details
-	Chain   paymentDomain.Chain `json:"chain" db:"chain" validate:"required,oneof=solana bsc ethereum"`
+	Chain   paymentDomain.Chain `json:"chain" db:"chain" validate:"required,oneof=solana bsc ethereum tron"`
 	Network Network             `json:"network" db:"network" validate:"required,oneof=mainnet testnet devnet"`
 
 	// Transaction identification
@@ -61,7 +61,7 @@ type BlockchainTransaction struct {
 	// Gas/fee information
 	GasUsed     sql.NullInt64       `json:"gas_used,omitempty" db:"gas_used"`
 	GasPrice    decimal.NullDecimal `json:"gas_price,omitempty" db:"gas_price"`
-	TxFee       decimal.NullDecimal `json:"transaction_fee,omitempty" db:"transaction_fee"`
+	TxFee       decimal.NullDecimal `json:"transaction_fee,omitempty" db:"transaction_fee" gorm:"column:transaction_fee"`
 	FeeCurrency sql.NullString      `json:"fee_currency,omitempty" db:"fee_currency"`
 
 	// Associated payment (if matched)
@@ -0,0 +1,32 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Backend is the subset of the JSON-RPC API the listener needs
+// It is satisfied by *ethclient.Client and by go-ethereum's simulated backend client
+type Backend interface {
+	ethereum.BlockNumberReader
+	ethereum.ChainIDReader
+	ethereum.LogFilterer
+	ethereum.TransactionReader
+}
+
+// DialBackend connects to the JSON-RPC endpoint of an EVM network
+func DialBackend(ctx context.Context, rpcURL string) (*ethclient.Client, error) {
+	if rpcURL == "" {
+		return nil, fmt.Errorf("RPC URL cannot be empty")
+	}
+
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to EVM node: %w", err)
+	}
+
+	return client, nil
+}
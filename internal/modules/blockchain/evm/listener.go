@@ -0,0 +1,775 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+)
+
+// PaymentConfirmationCallback is called when a payment is confirmed
+// paymentID: the payment ID extracted from the transaction
+// txHash: the transaction hash
+// amount: the amount transferred in decimal form
+// tokenSymbol: the token symbol (e.g., "USDT", "USDC")
+// fromAddress: the sender of the transfer
+type PaymentConfirmationCallback func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error
+
+// DepositAddressProvider returns the active per-payment deposit addresses mapped to their payment IDs
+// Transfers to these addresses are matched by recipient instead of memo
+type DepositAddressProvider func(ctx context.Context) (map[common.Address]string, error)
+
+// TokenContractProvider returns the supported token contracts mapped to their symbols
+// The listener reloads them every poll, so tokens enabled or disabled meanwhile are picked up without a restart
+type TokenContractProvider func(ctx context.Context) (map[string]TokenContractInfo, error)
+
+// TokenContractInfo contains information about a supported ERC-20 token
+type TokenContractInfo struct {
+	ContractAddress common.Address
+	Symbol          string
+	Decimals        uint8
+}
+
+// TransactionListener monitors an EVM network for incoming ERC-20 token transfers
+// to a specific wallet address and to per-payment deposit addresses
+type TransactionListener struct {
+	backend              Backend
+	network              string
+	chainID              *big.Int
+	walletAddress        common.Address
+	confirmationCallback PaymentConfirmationCallback
+	depositProvider      DepositAddressProvider
+	tokenProvider        TokenContractProvider
+	store                blockchainDomain.ListenerStore
+
+	// Supported token contracts for filtering, refreshed from tokenProvider every poll
+	supportedTokenContracts map[string]TokenContractInfo
+
+	// Control channels
+	ctx        context.Context
+	cancel     context.CancelFunc
+	shutdownCh chan struct{}
+	wg         sync.WaitGroup
+
+	// Configuration
+	pollInterval          time.Duration
+	requiredConfirmations uint64
+	maxBlockRange         uint64
+	maxRetries            int
+
+	// State
+	isRunning          bool
+	mu                 sync.RWMutex
+	lastProcessedBlock uint64
+	processedLogs      map[string]bool // Track processed transfer logs to avoid duplicates
+	processedLogsMu    sync.RWMutex
+	depositAddresses   map[common.Address]string // Refreshed from depositProvider every poll
+}
+
+// ListenerConfig holds configuration for the transaction listener
+type ListenerConfig struct {
+	Backend                 Backend
+	Network                 string   // Network name used in logs, e.g. "ethereum", "polygon"
+	ChainID                 *big.Int // Optional, the listener refuses to start against a node of another chain
+	WalletAddress           common.Address
+	ConfirmationCallback    PaymentConfirmationCallback
+	DepositAddressProvider  DepositAddressProvider         // Optional, enables deposit address matching
+	TokenContractProvider   TokenContractProvider          // Optional, reloads the supported token contracts every poll
+	Store                   blockchainDomain.ListenerStore // Optional, persists the cursor so downtime is backfilled
+	SupportedTokenContracts map[string]TokenContractInfo   // Used until the token provider first answers
+	PollInterval            time.Duration
+	RequiredConfirmations   uint64
+	MaxBlockRange           uint64 // Maximum number of blocks per eth_getLogs request
+	MaxRetries              int
+}
+
+// NewTransactionListener creates a new transaction listener
+func NewTransactionListener(config ListenerConfig) (*TransactionListener, error) {
+	if config.Backend == nil {
+		return nil, fmt.Errorf("backend cannot be nil")
+	}
+
+	if config.Network == "" {
+		return nil, fmt.Errorf("network cannot be empty")
+	}
+
+	if config.WalletAddress == (common.Address{}) {
+		return nil, fmt.Errorf("wallet address cannot be empty")
+	}
+
+	if config.ConfirmationCallback == nil {
+		return nil, fmt.Errorf("confirmation callback cannot be nil")
+	}
+
+	// Set defaults
+	pollInterval := config.PollInterval
+	if pollInterval == 0 {
+		pollInterval = 10 * time.Second
+	}
+
+	requiredConfirmations := config.RequiredConfirmations
+	if requiredConfirmations == 0 {
+		requiredConfirmations = 12
+	}
+
+	maxBlockRange := config.MaxBlockRange
+	if maxBlockRange == 0 {
+		maxBlockRange = 100 // Public RPC endpoints commonly cap eth_getLogs ranges
+	}
+
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	listener := &TransactionListener{
+		backend:                 config.Backend,
+		network:                 config.Network,
+		chainID:                 config.ChainID,
+		walletAddress:           config.WalletAddress,
+		confirmationCallback:    config.ConfirmationCallback,
+		depositProvider:         config.DepositAddressProvider,
+		tokenProvider:           config.TokenContractProvider,
+		store:                   config.Store,
+		supportedTokenContracts: config.SupportedTokenContracts,
+		ctx:                     ctx,
+		cancel:                  cancel,
+		shutdownCh:              make(chan struct{}),
+		pollInterval:            pollInterval,
+		requiredConfirmations:   requiredConfirmations,
+		maxBlockRange:           maxBlockRange,
+		maxRetries:              maxRetries,
+		isRunning:               false,
+		processedLogs:           make(map[string]bool),
+	}
+
+	return listener, nil
+}
+
+// Start begins listening for transactions
+func (l *TransactionListener) Start() error {
+	l.mu.Lock()
+	if l.isRunning {
+		l.mu.Unlock()
+		return fmt.Errorf("listener is already running")
+	}
+	l.isRunning = true
+	l.mu.Unlock()
+
+	if err := l.initialize(context.Background()); err != nil {
+		l.mu.Lock()
+		l.isRunning = false
+		l.mu.Unlock()
+		return err
+	}
+
+	fmt.Printf("Starting %s listener from block %d\n", l.network, l.GetLastProcessedBlock())
+
+	// Start polling goroutine
+	l.wg.Add(1)
+	go l.pollBlocks()
+
+	return nil
+}
+
+// initialize checks the node's chain and resumes from the saved cursor
+// Without a saved cursor the listener starts from the newest block that already has enough confirmations
+func (l *TransactionListener) initialize(ctx context.Context) error {
+	if l.chainID != nil {
+		chainID, err := l.backend.ChainID(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get chain ID: %w", err)
+		}
+		if chainID.Cmp(l.chainID) != 0 {
+			return fmt.Errorf("%s node reports chain ID %s, expected %s", l.network, chainID, l.chainID)
+		}
+	}
+
+	currentBlock, err := l.backend.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current block number: %w", err)
+	}
+
+	if l.store != nil {
+		cursor, err := l.store.LoadCursor(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load %s listener cursor: %w", l.network, err)
+		}
+
+		if cursor != nil {
+			l.mu.Lock()
+			l.lastProcessedBlock = uint64(cursor.BlockNumber)
+			l.mu.Unlock()
+			return nil
+		}
+	}
+
+	l.advanceCursor(ctx, l.confirmedHead(currentBlock))
+
+	return nil
+}
+
+// Stop gracefully stops the listener
+func (l *TransactionListener) Stop() error {
+	l.mu.Lock()
+	if !l.isRunning {
+		l.mu.Unlock()
+		return fmt.Errorf("listener is not running")
+	}
+	l.mu.Unlock()
+
+	// Cancel context to signal shutdown
+	l.cancel()
+
+	// Wait for goroutines to finish
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	// Wait with timeout
+	select {
+	case <-done:
+		l.mu.Lock()
+		l.isRunning = false
+		l.mu.Unlock()
+		close(l.shutdownCh)
+		return nil
+	case <-time.After(10 * time.Second):
+		l.mu.Lock()
+		l.isRunning = false
+		l.mu.Unlock()
+		return fmt.Errorf("listener shutdown timeout")
+	}
+}
+
+// IsRunning returns whether the listener is currently running
+func (l *TransactionListener) IsRunning() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.isRunning
+}
+
+// GetLastProcessedBlock returns the last fully processed block number
+func (l *TransactionListener) GetLastProcessedBlock() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lastProcessedBlock
+}
+
+// GetNetwork returns the name of the network being monitored
+func (l *TransactionListener) GetNetwork() string {
+	return l.network
+}
+
+// pollBlocks periodically polls for new blocks and processes transactions
+func (l *TransactionListener) pollBlocks() {
+	defer l.wg.Done()
+
+	// Backfill the gap since the saved cursor right away instead of waiting for the first tick
+	l.processNewBlocks()
+
+	ticker := time.NewTicker(l.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			l.processNewBlocks()
+			l.processRescans()
+		}
+	}
+}
+
+// processNewBlocks processes the confirmed blocks since the last processed block
+// A gap left by downtime is backfilled in batches of at most maxBlockRange blocks
+func (l *TransactionListener) processNewBlocks() {
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+
+		if !l.processNextBatch() {
+			return
+		}
+	}
+}
+
+// processNextBatch processes the next batch of confirmed blocks and returns true if more are waiting
+// Transfers are found with a single eth_getLogs request per batch instead of reading every transaction,
+// the cursor is only advanced once all of its transfers have been handled
+func (l *TransactionListener) processNextBatch() bool {
+	ctx, cancel := context.WithTimeout(l.ctx, 60*time.Second)
+	defer cancel()
+
+	// Get current block number
+	currentBlock, err := l.backend.BlockNumber(ctx)
+	if err != nil {
+		fmt.Printf("Failed to get current %s block number: %v\n", l.network, err)
+		return false
+	}
+
+	fromBlock := l.GetLastProcessedBlock() + 1
+	toBlock := l.confirmedHead(currentBlock)
+
+	if fromBlock > toBlock {
+		// No new confirmed blocks to process
+		return false
+	}
+
+	hasMore := false
+	if toBlock-fromBlock+1 > l.maxBlockRange {
+		toBlock = fromBlock + l.maxBlockRange - 1
+		hasMore = true
+	}
+
+	fmt.Printf("Processing %s blocks %d to %d\n", l.network, fromBlock, toBlock)
+
+	l.refreshDepositAddresses(ctx)
+	l.refreshTokenContracts(ctx)
+
+	if err := l.processBlockRange(ctx, fromBlock, toBlock, false); err != nil {
+		// The range is retried on the next poll, processed transactions are skipped
+		fmt.Printf("Failed to process %s blocks %d to %d: %v\n", l.network, fromBlock, toBlock, err)
+		return false
+	}
+
+	l.advanceCursor(ctx, toBlock)
+
+	return hasMore
+}
+
+// advanceCursor moves the last processed block and saves it to the store
+// A failed save is only logged, the range is processed again after a restart and deduplicated
+func (l *TransactionListener) advanceCursor(ctx context.Context, block uint64) {
+	l.mu.Lock()
+	l.lastProcessedBlock = block
+	l.mu.Unlock()
+
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.SaveCursor(ctx, block, ""); err != nil {
+		fmt.Printf("Failed to save %s listener cursor at block %d: %v\n", l.network, block, err)
+	}
+}
+
+// processBlockRange handles the token transfers to watched addresses in a block range
+// Recorded transactions are skipped unless force is set
+func (l *TransactionListener) processBlockRange(ctx context.Context, fromBlock, toBlock uint64, force bool) error {
+	query := l.transferQuery(fromBlock, toBlock)
+	if len(query.Addresses) == 0 {
+		return nil
+	}
+
+	logs, err := l.backend.FilterLogs(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	for _, txLogs := range groupLogsByTransaction(logs) {
+		select {
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		default:
+		}
+
+		if err := l.handleTransactionLogs(ctx, txLogs, force); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// groupLogsByTransaction groups logs by transaction, keeping the order of the logs
+func groupLogsByTransaction(logs []types.Log) [][]*types.Log {
+	var groups [][]*types.Log
+	indexes := make(map[common.Hash]int)
+
+	for i := range logs {
+		index, ok := indexes[logs[i].TxHash]
+		if !ok {
+			index = len(groups)
+			indexes[logs[i].TxHash] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], &logs[i])
+	}
+
+	return groups
+}
+
+// handleTransactionLogs handles the transfer logs of one transaction
+// The transaction is recorded once all of its transfers to watched addresses are confirmed,
+// so a transaction paying several deposit addresses is only skipped when every payment went through
+func (l *TransactionListener) handleTransactionLogs(ctx context.Context, logs []*types.Log, force bool) error {
+	txHash := logs[0].TxHash.Hex()
+
+	if !force {
+		recorded, err := l.isTransactionRecorded(ctx, txHash)
+		if err != nil {
+			return fmt.Errorf("failed to check transaction %s: %w", txHash, err)
+		}
+		if recorded {
+			return nil
+		}
+	}
+
+	var observed *blockchainDomain.ObservedTransfer
+	allConfirmed := true
+
+	for _, log := range logs {
+		transfer, confirmed, err := l.handleLog(ctx, log, force)
+		if err != nil {
+			return err
+		}
+		if transfer == nil {
+			continue
+		}
+		if !confirmed {
+			allConfirmed = false
+			continue
+		}
+		if observed == nil {
+			observed = transfer
+		}
+	}
+
+	if observed != nil && allConfirmed {
+		l.recordTransaction(ctx, *observed)
+	}
+
+	return nil
+}
+
+// transferQuery builds the filter for Transfer events of supported tokens to the wallet or a deposit address
+func (l *TransactionListener) transferQuery(fromBlock, toBlock uint64) ethereum.FilterQuery {
+	contracts := make([]common.Address, 0, len(l.supportedTokenContracts))
+	for _, tokenInfo := range l.supportedTokenContracts {
+		contracts = append(contracts, tokenInfo.ContractAddress)
+	}
+
+	recipients := make([]common.Hash, 0, len(l.depositAddresses)+1)
+	recipients = append(recipients, common.BytesToHash(l.walletAddress.Bytes()))
+	for address := range l.depositAddresses {
+		recipients = append(recipients, common.BytesToHash(address.Bytes()))
+	}
+
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: contracts,
+		Topics:    [][]common.Hash{{transferEventSignature}, nil, recipients},
+	}
+}
+
+// handleLog processes a single Transfer event log
+// It returns the transfer if it pays a payment and whether the payment was confirmed.
+// Only node errors are returned, transfers that cannot be matched to a payment are recorded as unmatched and skipped
+func (l *TransactionListener) handleLog(ctx context.Context, log *types.Log, force bool) (*blockchainDomain.ObservedTransfer, bool, error) {
+	if log.Removed {
+		return nil, false, nil
+	}
+
+	tokenInfo, isSupported := l.isSupportedTokenContract(log.Address)
+	if !isSupported {
+		return nil, false, nil
+	}
+
+	transfer, ok := parseTransferLog(log)
+	if !ok {
+		return nil, false, nil
+	}
+
+	// A transaction can pay several deposit addresses, so logs are tracked individually
+	txHash := log.TxHash.Hex()
+	logKey := fmt.Sprintf("%s:%d", txHash, log.Index)
+
+	l.processedLogsMu.RLock()
+	alreadyProcessed := l.processedLogs[logKey]
+	l.processedLogsMu.RUnlock()
+
+	if alreadyProcessed && !force {
+		return nil, false, nil
+	}
+
+	// Convert amount based on token decimals
+	divisor := decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(tokenInfo.Decimals)))
+	amount := decimal.NewFromBigInt(transfer.Amount, 0).Div(divisor)
+
+	observed := &blockchainDomain.ObservedTransfer{
+		TxHash:       txHash,
+		BlockNumber:  log.BlockNumber,
+		FromAddress:  transfer.From.Hex(),
+		ToAddress:    transfer.To.Hex(),
+		Amount:       amount,
+		Currency:     tokenInfo.Symbol,
+		TokenAddress: tokenInfo.ContractAddress.Hex(),
+	}
+
+	if depositPaymentID, ok := l.depositAddresses[transfer.To]; ok {
+		// Transfers to a deposit address identify the payment by recipient
+		observed.PaymentID = depositPaymentID
+
+		fmt.Printf("Detected %s transfer: %s sent %s %s to deposit address %s\n",
+			l.network, transfer.From.Hex(), transfer.Amount.String(), tokenInfo.Symbol, transfer.To.Hex())
+	} else if transfer.To == l.walletAddress {
+		fmt.Printf("Detected %s transfer: %s sent %s %s to wallet\n",
+			l.network, transfer.From.Hex(), transfer.Amount.String(), tokenInfo.Symbol)
+
+		// The memo is only part of the transaction input, not of the log
+		tx, err := l.getTransaction(ctx, log.TxHash)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get transaction %s: %w", txHash, err)
+		}
+
+		memo, err := ExtractMemoFromTransaction(tx)
+		if err != nil {
+			fmt.Printf("Warning: No payment ID found in transaction %s: %v\n", txHash, err)
+			l.recordInboundTransfer(ctx, *observed, nil)
+			l.markProcessed(logKey)
+			return nil, false, nil
+		}
+		observed.Memo = memo
+		observed.PaymentID = memo
+	} else {
+		return nil, false, nil
+	}
+
+	if err := l.confirmationCallback(observed.PaymentID, txHash, amount, tokenInfo.Symbol, observed.FromAddress); err != nil {
+		fmt.Printf("Payment confirmation callback failed for %s: %v\n", txHash, err)
+		l.recordInboundTransfer(ctx, *observed, err)
+		return observed, false, nil
+	}
+
+	l.recordInboundTransfer(ctx, *observed, nil)
+	l.markProcessed(logKey)
+
+	fmt.Printf("Successfully confirmed payment %s for transaction %s\n", observed.PaymentID, txHash)
+	return observed, true, nil
+}
+
+// isTransactionRecorded checks the store for an already recorded transaction
+func (l *TransactionListener) isTransactionRecorded(ctx context.Context, txHash string) (bool, error) {
+	if l.store == nil {
+		return false, nil
+	}
+	return l.store.IsTransactionRecorded(ctx, txHash)
+}
+
+// recordTransaction stores a confirmed transfer, failures are logged since the payment is already confirmed
+func (l *TransactionListener) recordTransaction(ctx context.Context, transfer blockchainDomain.ObservedTransfer) {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.RecordTransaction(ctx, transfer); err != nil {
+		fmt.Printf("Failed to record %s transaction %s: %v\n", l.network, transfer.TxHash, err)
+	}
+}
+
+// recordInboundTransfer stores a transfer to a watched address, queued for an operator unless it
+// confirmed its payment. Failures are logged, the transfer is recorded again by a rescan
+func (l *TransactionListener) recordInboundTransfer(ctx context.Context, transfer blockchainDomain.ObservedTransfer, matchErr error) {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.RecordInboundTransfer(ctx, transfer, matchErr); err != nil {
+		fmt.Printf("Failed to record %s inbound transfer %s: %v\n", l.network, transfer.TxHash, err)
+	}
+}
+
+// processRescans processes the pending rescan requests of the store
+func (l *TransactionListener) processRescans() {
+	if l.store == nil {
+		return
+	}
+
+	requests, err := l.store.PendingRescans(l.ctx)
+	if err != nil {
+		fmt.Printf("Failed to load %s rescan requests: %v\n", l.network, err)
+		return
+	}
+
+	for _, request := range requests {
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+
+		rescanErr := l.rescan(request)
+		if rescanErr != nil {
+			fmt.Printf("Failed to rescan %s request %s: %v\n", l.network, request.ID, rescanErr)
+		}
+
+		if err := l.store.CompleteRescan(l.ctx, request.ID, rescanErr); err != nil {
+			fmt.Printf("Failed to complete %s rescan request %s: %v\n", l.network, request.ID, err)
+		}
+	}
+}
+
+// rescan processes a requested block range or transaction again without skipping recorded transactions
+// The cursor is not moved, only blocks that already have enough confirmations can be rescanned
+func (l *TransactionListener) rescan(request *blockchainDomain.RescanRequest) error {
+	ctx, cancel := context.WithTimeout(l.ctx, 5*time.Minute)
+	defer cancel()
+
+	currentBlock, err := l.backend.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current block number: %w", err)
+	}
+	confirmedHead := l.confirmedHead(currentBlock)
+
+	l.refreshDepositAddresses(ctx)
+	l.refreshTokenContracts(ctx)
+
+	if request.IsTransactionRescan() {
+		return l.rescanTransaction(ctx, common.HexToHash(request.TxHash.String), confirmedHead)
+	}
+
+	fromBlock := uint64(request.FromBlock.Int64)
+	toBlock := uint64(request.ToBlock.Int64)
+	if toBlock > confirmedHead {
+		return fmt.Errorf("block %d does not have %d confirmations yet", toBlock, l.requiredConfirmations)
+	}
+
+	fmt.Printf("Rescanning %s blocks %d to %d\n", l.network, fromBlock, toBlock)
+
+	for start := fromBlock; start <= toBlock; start += l.maxBlockRange {
+		end := min(start+l.maxBlockRange-1, toBlock)
+		if err := l.processBlockRange(ctx, start, end, true); err != nil {
+			return fmt.Errorf("failed to rescan blocks %d to %d: %w", start, end, err)
+		}
+	}
+
+	return nil
+}
+
+// rescanTransaction processes the transfers of a single transaction again
+func (l *TransactionListener) rescanTransaction(ctx context.Context, txHash common.Hash, confirmedHead uint64) error {
+	receipt, err := l.backend.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("failed to get receipt of transaction %s: %w", txHash.Hex(), err)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s failed", txHash.Hex())
+	}
+
+	if receipt.BlockNumber == nil || receipt.BlockNumber.Uint64() > confirmedHead {
+		return fmt.Errorf("transaction %s does not have %d confirmations yet", txHash.Hex(), l.requiredConfirmations)
+	}
+
+	logs := make([]*types.Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		if len(log.Topics) == 3 && log.Topics[0] == transferEventSignature {
+			logs = append(logs, log)
+		}
+	}
+
+	if len(logs) == 0 {
+		return fmt.Errorf("transaction %s has no token transfers", txHash.Hex())
+	}
+
+	fmt.Printf("Rescanning %s transaction %s\n", l.network, txHash.Hex())
+
+	return l.handleTransactionLogs(ctx, logs, true)
+}
+
+// markProcessed records a handled transfer log
+func (l *TransactionListener) markProcessed(logKey string) {
+	l.processedLogsMu.Lock()
+	l.processedLogs[logKey] = true
+	l.processedLogsMu.Unlock()
+}
+
+// getTransaction fetches a transaction with retries
+func (l *TransactionListener) getTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, error) {
+	var tx *types.Transaction
+	var err error
+
+	for i := 0; i < l.maxRetries; i++ {
+		tx, _, err = l.backend.TransactionByHash(ctx, txHash)
+		if err == nil {
+			return tx, nil
+		}
+
+		if i < l.maxRetries-1 {
+			time.Sleep(time.Duration(i+1) * time.Second)
+		}
+	}
+
+	return nil, err
+}
+
+// refreshDepositAddresses reloads the deposit addresses watched by the listener
+// The previous set is kept if the provider fails
+func (l *TransactionListener) refreshDepositAddresses(ctx context.Context) {
+	if l.depositProvider == nil {
+		return
+	}
+
+	addresses, err := l.depositProvider(ctx)
+	if err != nil {
+		fmt.Printf("Failed to load %s deposit addresses: %v\n", l.network, err)
+		return
+	}
+	l.depositAddresses = addresses
+}
+
+// refreshTokenContracts reloads the token contracts supported by the listener
+// The previous set is kept if the provider fails
+func (l *TransactionListener) refreshTokenContracts(ctx context.Context) {
+	if l.tokenProvider == nil {
+		return
+	}
+
+	contracts, err := l.tokenProvider(ctx)
+	if err != nil {
+		fmt.Printf("Failed to load %s token contracts: %v\n", l.network, err)
+		return
+	}
+	l.supportedTokenContracts = contracts
+}
+
+// isSupportedTokenContract checks if a contract address is a supported token
+func (l *TransactionListener) isSupportedTokenContract(contractAddr common.Address) (TokenContractInfo, bool) {
+	for _, tokenInfo := range l.supportedTokenContracts {
+		if tokenInfo.ContractAddress == contractAddr {
+			return tokenInfo, true
+		}
+	}
+	return TokenContractInfo{}, false
+}
+
+// confirmedHead returns the newest block with the required number of confirmations
+func (l *TransactionListener) confirmedHead(currentBlock uint64) uint64 {
+	if currentBlock+1 < l.requiredConfirmations {
+		return 0
+	}
+	return currentBlock + 1 - l.requiredConfirmations
+}
+
+// GetWalletAddress returns the wallet address being monitored
+func (l *TransactionListener) GetWalletAddress() string {
+	return l.walletAddress.Hex()
+}
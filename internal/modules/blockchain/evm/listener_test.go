@@ -0,0 +1,448 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"database/sql"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+)
+
+// tokenRuntime emits Transfer(msg.sender, to, amount) for transfer(address,uint256) calls and ignores extra input,
+// which is all the listener relies on from a real ERC-20 token
+var tokenRuntime = append(append(
+	common.FromHex("0x602435600052600435337f"),
+	transferEventSignature.Bytes()...),
+	common.FromHex("0x60206000a300")...,
+)
+
+// tokenInitCode copies tokenRuntime (0x31 bytes at offset 0x0b) into memory and returns it
+var tokenInitCode = append(common.FromHex("0x603180600b6000396000f3"), tokenRuntime...)
+
+type confirmedPayment struct {
+	paymentID string
+	txHash    string
+	amount    decimal.Decimal
+	symbol    string
+}
+
+// simulatedChain is a single-node chain with a funded sender and a deployed token
+type simulatedChain struct {
+	backend *simulated.Backend
+	client  simulated.Client
+	key     *ecdsa.PrivateKey
+	sender  common.Address
+	chainID *big.Int
+	nonce   uint64
+	token   common.Address
+}
+
+func newSimulatedChain(t *testing.T) *simulatedChain {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		sender: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+	})
+	t.Cleanup(func() { _ = backend.Close() })
+
+	chain := &simulatedChain{
+		backend: backend,
+		client:  backend.Client(),
+		key:     key,
+		sender:  sender,
+	}
+
+	chain.chainID, err = chain.client.ChainID(context.Background())
+	require.NoError(t, err)
+
+	chain.token = crypto.CreateAddress(sender, chain.nonce)
+	chain.send(t, nil, tokenInitCode)
+	chain.backend.Commit()
+
+	code, err := chain.client.CodeAt(context.Background(), chain.token, nil)
+	require.NoError(t, err)
+	require.Equal(t, tokenRuntime, code)
+
+	return chain
+}
+
+// send signs and submits a transaction, it is mined on the next Commit
+func (c *simulatedChain) send(t *testing.T, to *common.Address, data []byte) common.Hash {
+	ctx := context.Background()
+
+	gasPrice, err := c.client.SuggestGasPrice(ctx)
+	require.NoError(t, err)
+
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    c.nonce,
+		GasPrice: gasPrice,
+		Gas:      300000,
+		To:       to,
+		Data:     data,
+	}), types.LatestSignerForChainID(c.chainID), c.key)
+	require.NoError(t, err)
+
+	require.NoError(t, c.client.SendTransaction(ctx, tx))
+	c.nonce++
+
+	return tx.Hash()
+}
+
+func (c *simulatedChain) transfer(t *testing.T, to common.Address, amount int64, memo string) common.Hash {
+	return c.send(t, &c.token, transferCalldata(to, amount, []byte(memo)))
+}
+
+// memoryStore is an in-memory blockchainDomain.ListenerStore
+type memoryStore struct {
+	mu       sync.Mutex
+	cursor   *blockchainDomain.ListenerCursor
+	recorded map[string]blockchainDomain.ObservedTransfer
+	inbound  []inboundTransfer
+	rescans  []*blockchainDomain.RescanRequest
+	results  map[string]error
+}
+
+// inboundTransfer is a transfer recorded with RecordInboundTransfer
+type inboundTransfer struct {
+	transfer blockchainDomain.ObservedTransfer
+	matchErr error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		recorded: make(map[string]blockchainDomain.ObservedTransfer),
+		results:  make(map[string]error),
+	}
+}
+
+func (s *memoryStore) LoadCursor(ctx context.Context) (*blockchainDomain.ListenerCursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor, nil
+}
+
+func (s *memoryStore) SaveCursor(ctx context.Context, blockNumber uint64, signature string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = &blockchainDomain.ListenerCursor{
+		BlockNumber: int64(blockNumber),
+		Signature:   sql.NullString{String: signature, Valid: signature != ""},
+	}
+	return nil
+}
+
+func (s *memoryStore) IsTransactionRecorded(ctx context.Context, txHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.recorded[txHash]
+	return ok, nil
+}
+
+func (s *memoryStore) RecordTransaction(ctx context.Context, transfer blockchainDomain.ObservedTransfer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorded[transfer.TxHash] = transfer
+	return nil
+}
+
+func (s *memoryStore) RecordInboundTransfer(ctx context.Context, transfer blockchainDomain.ObservedTransfer, matchErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inbound = append(s.inbound, inboundTransfer{transfer, matchErr})
+	return nil
+}
+
+func (s *memoryStore) PendingRescans(ctx context.Context) ([]*blockchainDomain.RescanRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.rescans
+	s.rescans = nil
+	return pending, nil
+}
+
+func (s *memoryStore) CompleteRescan(ctx context.Context, id string, rescanErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[id] = rescanErr
+	return nil
+}
+
+func newTestListener(t *testing.T, chain *simulatedChain, deposits map[common.Address]string, confirmed *[]confirmedPayment, store blockchainDomain.ListenerStore) *TransactionListener {
+	listener, err := NewTransactionListener(ListenerConfig{
+		Backend:       chain.client,
+		Network:       "ethereum",
+		ChainID:       chain.chainID,
+		WalletAddress: testWallet,
+		ConfirmationCallback: func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
+			*confirmed = append(*confirmed, confirmedPayment{paymentID, txHash, amount, tokenSymbol})
+			return nil
+		},
+		DepositAddressProvider: func(ctx context.Context) (map[common.Address]string, error) {
+			return deposits, nil
+		},
+		Store: store,
+		SupportedTokenContracts: map[string]TokenContractInfo{
+			"USDC": {ContractAddress: chain.token, Symbol: "USDC", Decimals: 6},
+		},
+		RequiredConfirmations: 3,
+		MaxBlockRange:         2,
+		MaxRetries:            1,
+	})
+	require.NoError(t, err)
+
+	return listener
+}
+
+func TestNewTransactionListener_Validation(t *testing.T) {
+	backend := simulated.NewBackend(types.GenesisAlloc{})
+	defer backend.Close()
+	callback := func(string, string, decimal.Decimal, string, string) error { return nil }
+
+	_, err := NewTransactionListener(ListenerConfig{Network: "ethereum", WalletAddress: testWallet, ConfirmationCallback: callback})
+	assert.Error(t, err)
+
+	_, err = NewTransactionListener(ListenerConfig{Backend: backend.Client(), WalletAddress: testWallet, ConfirmationCallback: callback})
+	assert.Error(t, err)
+
+	_, err = NewTransactionListener(ListenerConfig{Backend: backend.Client(), Network: "ethereum", ConfirmationCallback: callback})
+	assert.Error(t, err)
+
+	_, err = NewTransactionListener(ListenerConfig{Backend: backend.Client(), Network: "ethereum", WalletAddress: testWallet})
+	assert.Error(t, err)
+}
+
+func TestTransactionListener_RejectsWrongChain(t *testing.T) {
+	chain := newSimulatedChain(t)
+
+	listener, err := NewTransactionListener(ListenerConfig{
+		Backend:              chain.client,
+		Network:              "polygon",
+		ChainID:              big.NewInt(137),
+		WalletAddress:        testWallet,
+		ConfirmationCallback: func(string, string, decimal.Decimal, string, string) error { return nil },
+	})
+	require.NoError(t, err)
+
+	err = listener.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chain ID")
+	assert.False(t, listener.IsRunning())
+}
+
+func TestTransactionListener_ConfirmsTransfersAfterConfirmations(t *testing.T) {
+	chain := newSimulatedChain(t)
+	deposit := common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	var confirmed []confirmedPayment
+	listener := newTestListener(t, chain, map[common.Address]string{deposit: "payment-deposit"}, &confirmed, nil)
+	require.NoError(t, listener.initialize(context.Background()))
+
+	// A memo transfer to the wallet, a transfer to a deposit address and one to an unrelated address
+	paidTx := chain.transfer(t, testWallet, 25500000, "payment-123")
+	depositTx := chain.transfer(t, deposit, 10000000, "")
+	chain.transfer(t, testSender, 5000000, "payment-other")
+	chain.backend.Commit()
+
+	transferBlock, err := chain.client.BlockNumber(context.Background())
+	require.NoError(t, err)
+
+	// The transfer block has a single confirmation
+	listener.processNewBlocks()
+	assert.Empty(t, confirmed)
+	assert.Less(t, listener.GetLastProcessedBlock(), transferBlock)
+
+	chain.backend.Commit()
+	chain.backend.Commit()
+
+	listener.processNewBlocks()
+	require.Len(t, confirmed, 2)
+	assert.Equal(t, transferBlock, listener.GetLastProcessedBlock())
+
+	byPayment := map[string]confirmedPayment{}
+	for _, payment := range confirmed {
+		byPayment[payment.paymentID] = payment
+	}
+
+	assert.Equal(t, paidTx.Hex(), byPayment["payment-123"].txHash)
+	assert.True(t, decimal.RequireFromString("25.5").Equal(byPayment["payment-123"].amount))
+	assert.Equal(t, "USDC", byPayment["payment-123"].symbol)
+
+	assert.Equal(t, depositTx.Hex(), byPayment["payment-deposit"].txHash)
+	assert.True(t, decimal.NewFromInt(10).Equal(byPayment["payment-deposit"].amount))
+
+	// Reprocessing the same range does not confirm the transfers twice
+	listener.mu.Lock()
+	listener.lastProcessedBlock = transferBlock - 1
+	listener.mu.Unlock()
+
+	listener.processNewBlocks()
+	assert.Len(t, confirmed, 2)
+}
+
+func TestTransactionListener_ResumesFromCursor(t *testing.T) {
+	chain := newSimulatedChain(t)
+	store := newMemoryStore()
+
+	var confirmed []confirmedPayment
+	first := newTestListener(t, chain, nil, &confirmed, store)
+	require.NoError(t, first.initialize(context.Background()))
+	require.NotNil(t, store.cursor)
+	startBlock := uint64(store.cursor.BlockNumber)
+
+	// The listener is down while a payment arrives and more blocks are mined than fit in one batch
+	paidTx := chain.transfer(t, testWallet, 25500000, "payment-123")
+	chain.backend.Commit()
+	transferBlock, err := chain.client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		chain.backend.Commit()
+	}
+
+	// A restarted listener backfills from the saved cursor instead of the chain head
+	second := newTestListener(t, chain, nil, &confirmed, store)
+	require.NoError(t, second.initialize(context.Background()))
+	assert.Equal(t, startBlock, second.GetLastProcessedBlock())
+
+	second.processNewBlocks()
+	require.Len(t, confirmed, 1)
+	assert.Equal(t, paidTx.Hex(), confirmed[0].txHash)
+	assert.Equal(t, transferBlock+4, second.GetLastProcessedBlock())
+	assert.Equal(t, int64(transferBlock+4), store.cursor.BlockNumber)
+
+	recorded, ok := store.recorded[paidTx.Hex()]
+	require.True(t, ok)
+	assert.Equal(t, "payment-123", recorded.PaymentID)
+	assert.Equal(t, transferBlock, recorded.BlockNumber)
+
+	// Recorded transactions are skipped when the range is processed again
+	require.NoError(t, store.SaveCursor(context.Background(), startBlock, ""))
+	third := newTestListener(t, chain, nil, &confirmed, store)
+	require.NoError(t, third.initialize(context.Background()))
+	third.processNewBlocks()
+	assert.Len(t, confirmed, 1)
+
+	// A forced rescan confirms the transaction again, payment confirmation is idempotent
+	store.rescans = []*blockchainDomain.RescanRequest{
+		{ID: "rescan-tx", TxHash: sql.NullString{String: paidTx.Hex(), Valid: true}},
+		{ID: "rescan-range", FromBlock: sql.NullInt64{Int64: int64(transferBlock), Valid: true}, ToBlock: sql.NullInt64{Int64: int64(transferBlock), Valid: true}},
+		{ID: "rescan-unconfirmed", FromBlock: sql.NullInt64{Int64: 0, Valid: true}, ToBlock: sql.NullInt64{Int64: int64(transferBlock + 100), Valid: true}},
+	}
+	third.processRescans()
+	assert.Len(t, confirmed, 3)
+	assert.NoError(t, store.results["rescan-tx"])
+	assert.NoError(t, store.results["rescan-range"])
+	assert.Error(t, store.results["rescan-unconfirmed"])
+}
+
+func TestTransactionListener_RecordsUnmatchedTransfers(t *testing.T) {
+	chain := newSimulatedChain(t)
+	store := newMemoryStore()
+	errRejected := errors.New("payment is already completed")
+
+	listener, err := NewTransactionListener(ListenerConfig{
+		Backend:       chain.client,
+		Network:       "ethereum",
+		WalletAddress: testWallet,
+		ConfirmationCallback: func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
+			if paymentID == "payment-completed" {
+				return errRejected
+			}
+			return nil
+		},
+		Store: store,
+		SupportedTokenContracts: map[string]TokenContractInfo{
+			"USDC": {ContractAddress: chain.token, Symbol: "USDC", Decimals: 6},
+		},
+		RequiredConfirmations: 1,
+		MaxRetries:            1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, listener.initialize(context.Background()))
+
+	// A transfer without memo, one the payment refuses and one that confirms its payment
+	noMemoTx := chain.transfer(t, testWallet, 1000000, "")
+	rejectedTx := chain.transfer(t, testWallet, 2000000, "payment-completed")
+	paidTx := chain.transfer(t, testWallet, 3000000, "payment-123")
+	chain.backend.Commit()
+
+	listener.processNewBlocks()
+
+	inbound := map[string]inboundTransfer{}
+	for _, recorded := range store.inbound {
+		inbound[recorded.transfer.TxHash] = recorded
+	}
+	require.Len(t, inbound, 3)
+
+	noMemo := inbound[noMemoTx.Hex()]
+	assert.Empty(t, noMemo.transfer.PaymentID)
+	assert.Empty(t, noMemo.transfer.Memo)
+	assert.NoError(t, noMemo.matchErr)
+	assert.True(t, decimal.NewFromInt(1).Equal(noMemo.transfer.Amount))
+	assert.Equal(t, chain.sender.Hex(), noMemo.transfer.FromAddress)
+
+	rejected := inbound[rejectedTx.Hex()]
+	assert.Equal(t, "payment-completed", rejected.transfer.Memo)
+	assert.ErrorIs(t, rejected.matchErr, errRejected)
+
+	paid := inbound[paidTx.Hex()]
+	assert.Equal(t, "payment-123", paid.transfer.PaymentID)
+	assert.NoError(t, paid.matchErr)
+
+	// Only the transaction that confirmed its payment is recorded as processed
+	assert.Len(t, store.recorded, 1)
+	assert.Contains(t, store.recorded, paidTx.Hex())
+}
+
+func TestTransactionListener_ReloadsTokensEveryPoll(t *testing.T) {
+	chain := newSimulatedChain(t)
+
+	var confirmed []confirmedPayment
+	var tokens map[string]TokenContractInfo // The token is not enabled yet
+	listener, err := NewTransactionListener(ListenerConfig{
+		Backend:       chain.client,
+		Network:       "ethereum",
+		ChainID:       chain.chainID,
+		WalletAddress: testWallet,
+		ConfirmationCallback: func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
+			confirmed = append(confirmed, confirmedPayment{paymentID, txHash, amount, tokenSymbol})
+			return nil
+		},
+		TokenContractProvider: func(ctx context.Context) (map[string]TokenContractInfo, error) {
+			return tokens, nil
+		},
+		RequiredConfirmations: 1,
+		MaxRetries:            1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, listener.initialize(context.Background()))
+
+	chain.transfer(t, testWallet, 1000000, "payment-before")
+	chain.backend.Commit()
+	listener.processNewBlocks()
+	assert.Empty(t, confirmed)
+
+	tokens = map[string]TokenContractInfo{
+		"USDC": {ContractAddress: chain.token, Symbol: "USDC", Decimals: 6},
+	}
+	chain.transfer(t, testWallet, 2000000, "payment-after")
+	chain.backend.Commit()
+	listener.processNewBlocks()
+
+	require.Len(t, confirmed, 1)
+	assert.Equal(t, "payment-after", confirmed[0].paymentID)
+	assert.Equal(t, "USDC", confirmed[0].symbol)
+}
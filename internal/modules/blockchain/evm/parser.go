@@ -0,0 +1,133 @@
+package evm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TokenTransfer represents a parsed ERC-20 token transfer
+type TokenTransfer struct {
+	From          common.Address
+	To            common.Address
+	Amount        *big.Int
+	TokenContract common.Address
+}
+
+// Transfer event signature: Transfer(address indexed from, address indexed to, uint256 value)
+var transferEventSignature = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// ERC-20 transfer method ID: first 4 bytes of keccak256("transfer(address,uint256)")
+var transferMethodID = []byte{0xa9, 0x05, 0x9c, 0xbb}
+
+// ParseTransferFromLogs finds and parses the ERC-20 Transfer events of a token contract in logs
+func ParseTransferFromLogs(logs []*types.Log, tokenContract common.Address) ([]*TokenTransfer, error) {
+	transfers := []*TokenTransfer{}
+
+	for _, log := range logs {
+		transfer, ok := parseTransferLog(log)
+		if !ok || transfer.TokenContract != tokenContract {
+			continue
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("no ERC-20 token transfers found from contract %s", tokenContract.Hex())
+	}
+
+	return transfers, nil
+}
+
+// parseTransferLog parses a single Transfer event log
+// Topic[0] is the event signature, Topic[1] and Topic[2] the indexed from and to addresses,
+// the data holds the uint256 amount
+func parseTransferLog(log *types.Log) (*TokenTransfer, bool) {
+	if log == nil || len(log.Topics) < 3 || log.Topics[0] != transferEventSignature {
+		return nil, false
+	}
+
+	if len(log.Data) < 32 {
+		return nil, false
+	}
+
+	return &TokenTransfer{
+		From:          common.BytesToAddress(log.Topics[1].Bytes()),
+		To:            common.BytesToAddress(log.Topics[2].Bytes()),
+		Amount:        new(big.Int).SetBytes(log.Data[:32]),
+		TokenContract: log.Address,
+	}, true
+}
+
+// ExtractMemoFromTransaction extracts the payment memo from an ERC-20 transfer transaction
+// The memo is appended to the input data after the transfer(address,uint256) parameters
+func ExtractMemoFromTransaction(tx *types.Transaction) (string, error) {
+	if tx == nil {
+		return "", fmt.Errorf("transaction is nil")
+	}
+
+	data := tx.Data()
+	if len(data) == 0 {
+		return "", fmt.Errorf("no data in transaction")
+	}
+
+	// Standard ERC-20 transfer input has 68 bytes (4 + 32 + 32), anything after it is the memo
+	if len(data) > 68 && bytes.Equal(data[:4], transferMethodID) {
+		memo, err := decodeMemoFromBytes(data[68:])
+		if err == nil && memo != "" {
+			return memo, nil
+		}
+	}
+
+	return "", fmt.Errorf("no memo found in transaction")
+}
+
+// decodeMemoFromBytes attempts to decode memo from bytes
+// Handles both plain text and ABI-encoded strings
+func decodeMemoFromBytes(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("empty data")
+	}
+
+	// Try decoding as ABI-encoded string: [offset][length][data]
+	if len(data) > 64 {
+		length := new(big.Int).SetBytes(data[32:64])
+		if length.IsInt64() && length.Int64() <= int64(len(data)-64) {
+			memo := string(data[64 : 64+length.Int64()])
+			if isPrintable(memo) {
+				return memo, nil
+			}
+		}
+	}
+
+	// Try decoding as plain text
+	memo := string(data)
+	if isPrintable(memo) {
+		return memo, nil
+	}
+
+	return "", fmt.Errorf("unable to decode memo")
+}
+
+// isPrintable checks if a string contains only printable characters
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if r < 32 || r > 126 {
+			// Allow common whitespace characters
+			if r != '\n' && r != '\r' && r != '\t' {
+				return false
+			}
+		}
+	}
+	return len(s) > 0
+}
+
+// GetTransferEventSignature returns the Transfer event signature hash
+func GetTransferEventSignature() common.Hash {
+	return transferEventSignature
+}
@@ -0,0 +1,85 @@
+package evm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testToken  = common.HexToAddress("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238")
+	testWallet = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testSender = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func transferLog(contract, from, to common.Address, amount int64) *types.Log {
+	return &types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			transferEventSignature,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+	}
+}
+
+// transferCalldata builds transfer(address,uint256) input data with the memo appended
+func transferCalldata(to common.Address, amount int64, memo []byte) []byte {
+	data := append([]byte{}, transferMethodID...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(amount).Bytes(), 32)...)
+	return append(data, memo...)
+}
+
+func TestParseTransferFromLogs(t *testing.T) {
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	logs := []*types.Log{
+		// Transfer of another token is ignored
+		transferLog(other, testSender, testWallet, 1),
+		transferLog(testToken, testSender, testWallet, 25500000),
+		// Approval-like event without the Transfer signature is ignored
+		{Address: testToken, Topics: []common.Hash{{}, {}, {}}, Data: make([]byte, 32)},
+	}
+
+	transfers, err := ParseTransferFromLogs(logs, testToken)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, testSender, transfers[0].From)
+	assert.Equal(t, testWallet, transfers[0].To)
+	assert.Equal(t, int64(25500000), transfers[0].Amount.Int64())
+	assert.Equal(t, testToken, transfers[0].TokenContract)
+
+	_, err = ParseTransferFromLogs(logs[:1], testToken)
+	assert.Error(t, err)
+}
+
+func TestExtractMemoFromTransaction(t *testing.T) {
+	newTx := func(data []byte) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{To: &testToken, Data: data})
+	}
+
+	memo, err := ExtractMemoFromTransaction(newTx(transferCalldata(testWallet, 1, []byte("payment-123"))))
+	require.NoError(t, err)
+	assert.Equal(t, "payment-123", memo)
+
+	// ABI-encoded string memo
+	abiMemo := append(common.LeftPadBytes([]byte{0x20}, 32), common.LeftPadBytes([]byte{11}, 32)...)
+	abiMemo = append(abiMemo, common.RightPadBytes([]byte("payment-456"), 32)...)
+	memo, err = ExtractMemoFromTransaction(newTx(transferCalldata(testWallet, 1, abiMemo)))
+	require.NoError(t, err)
+	assert.Equal(t, "payment-456", memo)
+
+	_, err = ExtractMemoFromTransaction(newTx(transferCalldata(testWallet, 1, nil)))
+	assert.Error(t, err)
+
+	_, err = ExtractMemoFromTransaction(newTx(transferCalldata(testWallet, 1, []byte{0x00, 0xff})))
+	assert.Error(t, err)
+
+	_, err = ExtractMemoFromTransaction(nil)
+	assert.Error(t, err)
+}
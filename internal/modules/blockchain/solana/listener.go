@@ -2,6 +2,7 @@ package solana
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -10,13 +11,52 @@ import (
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 	"github.com/shopspring/decimal"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+)
+
+const (
+	// signatureBatchSize is the page size for signature listing, the cursor is saved after each batch
+	signatureBatchSize = 100
+
+	// maxBackfillSignatures bounds the backfill when the cursor signature is no longer returned by the node
+	maxBackfillSignatures = 10000
 )
 
+// errTransactionFailed is returned for transactions that failed on-chain, they are never retried
+var errTransactionFailed = errors.New("transaction failed on-chain")
+
 // PaymentConfirmationCallback is called when a payment is confirmed
 // paymentID: the payment ID from the transaction memo
 // txHash: the transaction signature
 // amount: the amount transferred in decimal form
-type PaymentConfirmationCallback func(paymentID string, txHash string, amount decimal.Decimal, tokenMint string) error
+// fromAddress: the wallet that authorized the token transfer
+type PaymentConfirmationCallback func(paymentID string, txHash string, amount decimal.Decimal, tokenMint string, fromAddress string) error
+
+// DepositAccount is a per-payment deposit token account watched by the listener
+type DepositAccount struct {
+	Address   solana.PublicKey
+	PaymentID string
+	TokenMint solana.PublicKey
+}
+
+// DepositAccountProvider returns the active deposit token accounts
+// Transfers to these accounts are matched by recipient instead of memo
+type DepositAccountProvider func(ctx context.Context) ([]DepositAccount, error)
+
+// TokenMintProvider returns the supported SPL token mints mapped to their symbols
+// The listener reloads them every poll, so tokens enabled or disabled meanwhile are picked up without a restart
+type TokenMintProvider func(ctx context.Context) (map[string]TokenMintInfo, error)
+
+// ReferenceKey is the Solana Pay reference key of a payment watched by the listener
+type ReferenceKey struct {
+	Address   solana.PublicKey
+	PaymentID string
+}
+
+// ReferenceKeyProvider returns the reference keys of payments paid with Solana Pay transaction requests
+// Transactions carrying a reference key are matched to its payment, the memo is only cross-checked
+type ReferenceKeyProvider func(ctx context.Context) ([]ReferenceKey, error)
 
 // TransactionListener monitors Solana blockchain for incoming transactions
 // to a specific wallet address
@@ -26,9 +66,14 @@ type TransactionListener struct {
 	wsClient             *ws.Client
 	wsURL                string
 	confirmationCallback PaymentConfirmationCallback
+	depositProvider      DepositAccountProvider
+	referenceProvider    ReferenceKeyProvider
+	tokenProvider        TokenMintProvider
+	store                blockchainDomain.ListenerStore
 
-	// Supported token mints for filtering
+	// Supported token mints for filtering, refreshed from tokenProvider every poll
 	supportedTokenMints  map[string]TokenMintInfo
+	tokensMu             sync.RWMutex
 
 	// Control channels
 	ctx                  context.Context
@@ -43,6 +88,9 @@ type TransactionListener struct {
 	// State
 	isRunning            bool
 	mu                   sync.RWMutex
+	lastSignature        solana.Signature // Newest fully processed wallet signature
+	lastProcessedSlot    uint64
+	fetchMu              sync.Mutex       // The WebSocket and polling goroutines both fetch signatures
 }
 
 // TokenMintInfo contains information about supported SPL tokens
@@ -58,7 +106,11 @@ type ListenerConfig struct {
 	Wallet               *Wallet
 	WSURL                string
 	ConfirmationCallback PaymentConfirmationCallback
-	SupportedTokenMints  map[string]TokenMintInfo
+	DepositProvider      DepositAccountProvider         // Optional, enables deposit account matching
+	ReferenceProvider    ReferenceKeyProvider           // Optional, enables Solana Pay reference matching
+	TokenMintProvider    TokenMintProvider              // Optional, reloads the supported token mints every poll
+	Store                blockchainDomain.ListenerStore // Optional, persists the cursor so downtime is backfilled
+	SupportedTokenMints  map[string]TokenMintInfo       // Used until the token provider first answers
 	PollInterval         time.Duration
 	MaxRetries           int
 }
@@ -101,6 +153,10 @@ func NewTransactionListener(config ListenerConfig) (*TransactionListener, error)
 		wallet:               config.Wallet,
 		wsURL:                wsURL,
 		confirmationCallback: config.ConfirmationCallback,
+		depositProvider:      config.DepositProvider,
+		referenceProvider:    config.ReferenceProvider,
+		tokenProvider:        config.TokenMintProvider,
+		store:                config.Store,
 		supportedTokenMints:  config.SupportedTokenMints,
 		ctx:                  ctx,
 		cancel:               cancel,
@@ -135,6 +191,13 @@ func (l *TransactionListener) Start() error {
 	l.isRunning = true
 	l.mu.Unlock()
 
+	if err := l.initialize(context.Background()); err != nil {
+		l.mu.Lock()
+		l.isRunning = false
+		l.mu.Unlock()
+		return err
+	}
+
 	// Start WebSocket listener goroutine
 	l.wg.Add(1)
 	go l.listenWebSocket()
@@ -146,6 +209,36 @@ func (l *TransactionListener) Start() error {
 	return nil
 }
 
+// initialize resumes from the saved cursor
+// Without a saved cursor the first fetch processes the most recent signatures
+func (l *TransactionListener) initialize(ctx context.Context) error {
+	if l.store == nil {
+		return nil
+	}
+
+	cursor, err := l.store.LoadCursor(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load Solana listener cursor: %w", err)
+	}
+
+	if cursor == nil || cursor.GetSignature() == "" {
+		return nil
+	}
+
+	signature, err := solana.SignatureFromBase58(cursor.GetSignature())
+	if err != nil {
+		return fmt.Errorf("invalid Solana listener cursor signature: %w", err)
+	}
+
+	l.mu.Lock()
+	l.lastSignature = signature
+	l.lastProcessedSlot = uint64(cursor.BlockNumber)
+	l.mu.Unlock()
+
+	fmt.Printf("Resuming Solana listener from signature %s (slot %d)\n", signature, cursor.BlockNumber)
+	return nil
+}
+
 // Stop gracefully stops the listener
 func (l *TransactionListener) Stop() error {
 	l.mu.Lock()
@@ -193,6 +286,20 @@ func (l *TransactionListener) IsRunning() bool {
 	return l.isRunning
 }
 
+// GetLastProcessedSlot returns the slot of the newest fully processed wallet signature
+func (l *TransactionListener) GetLastProcessedSlot() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lastProcessedSlot
+}
+
+// getLastSignature returns the newest fully processed wallet signature
+func (l *TransactionListener) getLastSignature() solana.Signature {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lastSignature
+}
+
 // listenWebSocket listens for transactions via WebSocket subscription
 func (l *TransactionListener) listenWebSocket() {
 	defer l.wg.Done()
@@ -264,6 +371,9 @@ func (l *TransactionListener) listenWebSocket() {
 func (l *TransactionListener) pollTransactions() {
 	defer l.wg.Done()
 
+	// Backfill the gap since the saved cursor right away instead of waiting for the first tick
+	l.fetchAndProcessRecentTransactions()
+
 	ticker := time.NewTicker(l.pollInterval)
 	defer ticker.Stop()
 
@@ -273,97 +383,232 @@ func (l *TransactionListener) pollTransactions() {
 			return
 		case <-ticker.C:
 			l.fetchAndProcessRecentTransactions()
+			l.processRescans()
 		}
 	}
 }
 
-// fetchAndProcessRecentTransactions fetches and processes recent transactions
+// fetchAndProcessRecentTransactions processes the wallet's finalized transactions since the cursor
+// They are processed oldest first and the cursor is saved after each batch, a transaction that
+// cannot be loaded stops the batch so it is retried on the next fetch
 func (l *TransactionListener) fetchAndProcessRecentTransactions() {
-	ctx, cancel := context.WithTimeout(l.ctx, 30*time.Second)
+	l.fetchMu.Lock()
+	defer l.fetchMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(l.ctx, 60*time.Second)
 	defer cancel()
 
-	// Get recent transaction signatures for the wallet
-	sigs, err := l.client.GetRPCClient().GetSignaturesForAddress(
-		ctx,
-		l.wallet.GetPublicKey(),
-	)
+	l.refreshTokenMints(ctx)
+
+	sigs, err := l.listSignaturesSinceCursor(ctx)
 	if err != nil {
 		fmt.Printf("Failed to get signatures: %v\n", err)
 		return
 	}
 
-	if sigs == nil {
-		return
-	}
+	var unsaved *rpc.TransactionSignature // Processed but not saved to the cursor yet
+	for i, sig := range sigs {
+		if l.ctx.Err() != nil {
+			break
+		}
 
-	// Process each signature (most recent first)
-	for _, sig := range sigs {
-		if sig.Err != nil {
-			// Skip failed transactions
-			continue
+		// Skip failed transactions
+		if sig.Err == nil {
+			if err := l.handleTransaction(sig.Signature, false); err != nil && !errors.Is(err, errTransactionFailed) {
+				fmt.Printf("%v\n", err)
+				break
+			}
 		}
 
-		// Only process finalized transactions
-		if sig.ConfirmationStatus != rpc.ConfirmationStatusFinalized {
-			continue
+		unsaved = sig
+		if (i+1)%signatureBatchSize == 0 {
+			l.advanceCursor(unsaved)
+			unsaved = nil
 		}
+	}
 
-		// Process the transaction
-		l.handleTransaction(sig.Signature)
+	if unsaved != nil {
+		l.advanceCursor(unsaved)
 	}
+
+	depositCtx, depositCancel := context.WithTimeout(l.ctx, 30*time.Second)
+	defer depositCancel()
+
+	l.fetchAndProcessDepositTransactions(depositCtx)
+
+	referenceCtx, referenceCancel := context.WithTimeout(l.ctx, 30*time.Second)
+	defer referenceCancel()
+
+	l.fetchAndProcessReferenceTransactions(referenceCtx)
 }
 
-// handleTransaction processes a single transaction
-func (l *TransactionListener) handleTransaction(signature solana.Signature) {
-	ctx, cancel := context.WithTimeout(l.ctx, 30*time.Second)
-	defer cancel()
+// listSignaturesSinceCursor lists the wallet's finalized signatures newer than the cursor, oldest first
+// The node pages backwards in time, so all pages back to the cursor are listed before processing.
+// Without a cursor only the most recent page is returned.
+func (l *TransactionListener) listSignaturesSinceCursor(ctx context.Context) ([]*rpc.TransactionSignature, error) {
+	until := l.getLastSignature()
+	limit := signatureBatchSize
 
-	// Get transaction details with retries
-	var txInfo *TransactionInfo
-	var err error
+	var sigs []*rpc.TransactionSignature
+	var before solana.Signature
 
-	for i := 0; i < l.maxRetries; i++ {
-		txInfo, err = l.client.GetTransaction(ctx, signature)
-		if err == nil {
+	for {
+		page, err := l.client.GetRPCClient().GetSignaturesForAddressWithOpts(
+			ctx,
+			l.wallet.GetPublicKey(),
+			&rpc.GetSignaturesForAddressOpts{
+				Limit:      &limit,
+				Before:     before,
+				Until:      until,
+				Commitment: rpc.CommitmentFinalized,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sigs = append(sigs, page...)
+
+		if until.IsZero() || len(page) < limit {
 			break
 		}
 
-		if i < l.maxRetries-1 {
-			time.Sleep(time.Duration(i+1) * time.Second)
+		if len(sigs) >= maxBackfillSignatures {
+			fmt.Printf("Warning: Solana cursor %s not found within %d signatures, older transactions need a rescan\n", until, len(sigs))
+			break
 		}
+
+		before = page[len(page)-1].Signature
+	}
+
+	// Signatures are returned newest first
+	for i, j := 0, len(sigs)-1; i < j; i, j = i+1, j-1 {
+		sigs[i], sigs[j] = sigs[j], sigs[i]
+	}
+
+	return sigs, nil
+}
+
+// advanceCursor moves the cursor to a processed signature and saves it to the store
+// A failed save is only logged, the signatures are processed again after a restart and deduplicated
+func (l *TransactionListener) advanceCursor(sig *rpc.TransactionSignature) {
+	l.mu.Lock()
+	l.lastSignature = sig.Signature
+	l.lastProcessedSlot = sig.Slot
+	l.mu.Unlock()
+
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.SaveCursor(l.ctx, sig.Slot, sig.Signature.String()); err != nil {
+		fmt.Printf("Failed to save Solana listener cursor at %s: %v\n", sig.Signature, err)
+	}
+}
+
+// fetchAndProcessDepositTransactions processes recent transactions of the deposit accounts
+func (l *TransactionListener) fetchAndProcessDepositTransactions(ctx context.Context) {
+	if l.depositProvider == nil {
+		return
 	}
 
+	accounts, err := l.depositProvider(ctx)
 	if err != nil {
-		fmt.Printf("Failed to get transaction %s after %d retries: %v\n", signature, l.maxRetries, err)
+		fmt.Printf("Failed to load deposit accounts: %v\n", err)
 		return
 	}
 
-	// Check if transaction is finalized
-	if !txInfo.IsFinalized {
-		// Wait for finalization
-		err = l.client.WaitForConfirmation(ctx, signature, rpc.CommitmentFinalized, 30)
+	for _, account := range accounts {
+		sigs, err := l.client.GetRPCClient().GetSignaturesForAddress(ctx, account.Address)
 		if err != nil {
-			fmt.Printf("Transaction %s not finalized: %v\n", signature, err)
-			return
+			fmt.Printf("Failed to get signatures for deposit account %s: %v\n", account.Address, err)
+			continue
+		}
+
+		for _, sig := range sigs {
+			if sig.Err != nil || sig.ConfirmationStatus != rpc.ConfirmationStatusFinalized {
+				continue
+			}
+
+			l.handleDepositTransaction(sig.Signature, account, false)
 		}
 	}
+}
 
-	// Check if transaction succeeded
-	if txInfo.Error != nil {
-		fmt.Printf("Transaction %s failed: %v\n", signature, txInfo.Error)
+// fetchAndProcessReferenceTransactions processes recent transactions carrying a Solana Pay reference key
+func (l *TransactionListener) fetchAndProcessReferenceTransactions(ctx context.Context) {
+	if l.referenceProvider == nil {
+		return
+	}
+
+	keys, err := l.referenceProvider(ctx)
+	if err != nil {
+		fmt.Printf("Failed to load Solana Pay reference keys: %v\n", err)
+		return
+	}
+
+	if len(keys) == 0 {
 		return
 	}
 
+	tokenAccounts := l.walletTokenAccounts()
+
+	for _, key := range keys {
+		sigs, err := l.client.GetRPCClient().GetSignaturesForAddress(ctx, key.Address)
+		if err != nil {
+			fmt.Printf("Failed to get signatures for reference key %s: %v\n", key.Address, err)
+			continue
+		}
+
+		for _, sig := range sigs {
+			if sig.Err != nil || sig.ConfirmationStatus != rpc.ConfirmationStatusFinalized {
+				continue
+			}
+
+			l.handleReferenceTransaction(sig.Signature, key, tokenAccounts, false)
+		}
+	}
+}
+
+// handleTransaction processes a single transaction
+// Recorded transactions are skipped unless force is set. Only errors loading the transaction
+// are returned, transactions that are not payments are skipped
+func (l *TransactionListener) handleTransaction(signature solana.Signature, force bool) error {
+	if l.isTransactionRecorded(signature, force) {
+		return nil
+	}
+
+	txInfo, err := l.getSuccessfulTransaction(signature)
+	if err != nil {
+		return err
+	}
+
 	// Parse the transaction to extract payment details
 	paymentDetails, err := l.parsePaymentTransaction(txInfo)
 	if err != nil {
 		// Not a payment transaction or parsing error
-		return
+		return nil
 	}
 
 	// Verify the payment is to our wallet
 	if paymentDetails.Recipient != l.wallet.GetPublicKey() {
-		return
+		return nil
+	}
+
+	observed := blockchainDomain.ObservedTransfer{
+		FromAddress:  paymentDetails.Sender.String(),
+		ToAddress:    paymentDetails.Recipient.String(),
+		Amount:       paymentDetails.Amount,
+		Currency:     paymentDetails.TokenMint,
+		TokenAddress: l.tokenMints()[paymentDetails.TokenMint].MintAddress.String(),
+		Memo:         paymentDetails.PaymentID,
+		PaymentID:    paymentDetails.PaymentID,
+	}
+
+	if paymentDetails.PaymentID == "" {
+		fmt.Printf("Warning: No payment ID found in transaction %s\n", signature)
+		l.recordInboundTransfer(txInfo, observed, nil)
+		return nil
 	}
 
 	// Call the confirmation callback
@@ -372,11 +617,385 @@ func (l *TransactionListener) handleTransaction(signature solana.Signature) {
 		signature.String(),
 		paymentDetails.Amount,
 		paymentDetails.TokenMint,
+		observed.FromAddress,
+	)
+
+	if err != nil {
+		fmt.Printf("Payment confirmation callback failed for %s: %v\n", signature, err)
+		l.recordInboundTransfer(txInfo, observed, err)
+		return nil
+	}
+
+	l.recordInboundTransfer(txInfo, observed, nil)
+	l.recordTransaction(txInfo, observed)
+
+	return nil
+}
+
+// handleDepositTransaction confirms a transfer into a deposit account
+// The deposit account identifies the payment, no memo is needed
+func (l *TransactionListener) handleDepositTransaction(signature solana.Signature, account DepositAccount, force bool) {
+	if l.isTransactionRecorded(signature, force) {
+		return
+	}
+
+	txInfo, err := l.getSuccessfulTransaction(signature)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+
+	// Outgoing sweeps are skipped here since their recipient is the hot wallet
+	transfer, err := parseSPLTokenTransfer(txInfo.Transaction, account.Address)
+	if err != nil {
+		return
+	}
+
+	// A token account only holds its own mint, plain Transfer instructions do not carry it
+	tokenInfo, supported := l.isSupportedToken(account.TokenMint)
+	if !supported {
+		fmt.Printf("Deposit account %s has unsupported token: %s\n", account.Address, account.TokenMint)
+		return
+	}
+
+	divisor := decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(tokenInfo.Decimals)))
+	amount := decimal.NewFromBigInt(transfer.Amount, 0).Div(divisor)
+
+	observed := blockchainDomain.ObservedTransfer{
+		FromAddress:  transfer.Sender.String(),
+		ToAddress:    account.Address.String(),
+		Amount:       amount,
+		Currency:     tokenInfo.Symbol,
+		TokenAddress: tokenInfo.MintAddress.String(),
+		PaymentID:    account.PaymentID,
+	}
+
+	err = l.confirmationCallback(
+		account.PaymentID,
+		signature.String(),
+		amount,
+		tokenInfo.Symbol,
+		observed.FromAddress,
 	)
 
 	if err != nil {
 		fmt.Printf("Payment confirmation callback failed for %s: %v\n", signature, err)
+		l.recordInboundTransfer(txInfo, observed, err)
+		return
 	}
+
+	l.recordInboundTransfer(txInfo, observed, nil)
+	l.recordTransaction(txInfo, observed)
+}
+
+// handleReferenceTransaction confirms a transfer to the wallet carrying the reference key of a payment
+// The reference key identifies the payment, a memo naming another payment queues the transfer instead
+func (l *TransactionListener) handleReferenceTransaction(signature solana.Signature, key ReferenceKey, tokenAccounts map[solana.PublicKey]solana.PublicKey, force bool) {
+	if l.isTransactionRecorded(signature, force) {
+		return
+	}
+
+	txInfo, err := l.getSuccessfulTransaction(signature)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+
+	transfer, err := findReferenceTransfer(txInfo.Transaction, tokenAccounts)
+	if err != nil {
+		return
+	}
+
+	tokenInfo, supported := l.isSupportedToken(transfer.TokenMint)
+	if !supported {
+		return
+	}
+
+	divisor := decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(tokenInfo.Decimals)))
+	amount := decimal.NewFromBigInt(transfer.Amount, 0).Div(divisor)
+
+	memo, _ := extractMemoFromTransaction(txInfo.Transaction)
+
+	observed := blockchainDomain.ObservedTransfer{
+		FromAddress:  transfer.Sender.String(),
+		ToAddress:    transfer.Recipient.String(),
+		Amount:       amount,
+		Currency:     tokenInfo.Symbol,
+		TokenAddress: tokenInfo.MintAddress.String(),
+		Memo:         memo,
+		PaymentID:    key.PaymentID,
+	}
+
+	if memo != "" && memo != key.PaymentID {
+		err := fmt.Errorf("memo %q does not match payment %s of reference key %s", memo, key.PaymentID, key.Address)
+		fmt.Printf("Solana Pay transaction %s not matched: %v\n", signature, err)
+		l.recordInboundTransfer(txInfo, observed, err)
+		return
+	}
+
+	err = l.confirmationCallback(
+		key.PaymentID,
+		signature.String(),
+		amount,
+		tokenInfo.Symbol,
+		observed.FromAddress,
+	)
+
+	if err != nil {
+		fmt.Printf("Payment confirmation callback failed for %s: %v\n", signature, err)
+		l.recordInboundTransfer(txInfo, observed, err)
+		return
+	}
+
+	l.recordInboundTransfer(txInfo, observed, nil)
+	l.recordTransaction(txInfo, observed)
+}
+
+// walletTokenAccounts returns the wallet's associated token accounts of the supported mints, keyed to their mint
+func (l *TransactionListener) walletTokenAccounts() map[solana.PublicKey]solana.PublicKey {
+	tokenMints := l.tokenMints()
+	accounts := make(map[solana.PublicKey]solana.PublicKey, len(tokenMints))
+	for _, tokenInfo := range tokenMints {
+		ata, _, err := solana.FindAssociatedTokenAddress(l.wallet.GetPublicKey(), tokenInfo.MintAddress)
+		if err != nil {
+			fmt.Printf("Failed to derive token account for %s: %v\n", tokenInfo.Symbol, err)
+			continue
+		}
+		accounts[ata] = tokenInfo.MintAddress
+	}
+	return accounts
+}
+
+// isTransactionRecorded checks the store for an already recorded transaction
+// Store errors are logged and the transaction is processed, payment confirmation is idempotent
+func (l *TransactionListener) isTransactionRecorded(signature solana.Signature, force bool) bool {
+	if force || l.store == nil {
+		return false
+	}
+
+	recorded, err := l.store.IsTransactionRecorded(l.ctx, signature.String())
+	if err != nil {
+		fmt.Printf("Failed to check Solana transaction %s: %v\n", signature, err)
+		return false
+	}
+
+	return recorded
+}
+
+// recordTransaction stores a confirmed transfer, failures are logged since the payment is already confirmed
+func (l *TransactionListener) recordTransaction(txInfo *TransactionInfo, transfer blockchainDomain.ObservedTransfer) {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.RecordTransaction(l.ctx, withTransactionInfo(txInfo, transfer)); err != nil {
+		fmt.Printf("Failed to record Solana transaction %s: %v\n", txInfo.Signature, err)
+	}
+}
+
+// recordInboundTransfer stores a transfer to a watched account, queued for an operator unless it
+// confirmed its payment. Failures are logged, the transfer is recorded again by a rescan
+func (l *TransactionListener) recordInboundTransfer(txInfo *TransactionInfo, transfer blockchainDomain.ObservedTransfer, matchErr error) {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.RecordInboundTransfer(l.ctx, withTransactionInfo(txInfo, transfer), matchErr); err != nil {
+		fmt.Printf("Failed to record Solana inbound transfer %s: %v\n", txInfo.Signature, err)
+	}
+}
+
+// withTransactionInfo sets the signature, slot and block time of the transaction on a transfer
+func withTransactionInfo(txInfo *TransactionInfo, transfer blockchainDomain.ObservedTransfer) blockchainDomain.ObservedTransfer {
+	transfer.TxHash = txInfo.Signature.String()
+	transfer.BlockNumber = txInfo.Slot
+	if txInfo.BlockTime != nil {
+		transfer.BlockTime = time.Unix(*txInfo.BlockTime, 0)
+	}
+	return transfer
+}
+
+// processRescans processes the pending rescan requests of the store
+func (l *TransactionListener) processRescans() {
+	if l.store == nil {
+		return
+	}
+
+	requests, err := l.store.PendingRescans(l.ctx)
+	if err != nil {
+		fmt.Printf("Failed to load Solana rescan requests: %v\n", err)
+		return
+	}
+
+	for _, request := range requests {
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+
+		rescanErr := l.rescan(request)
+		if rescanErr != nil {
+			fmt.Printf("Failed to rescan Solana request %s: %v\n", request.ID, rescanErr)
+		}
+
+		if err := l.store.CompleteRescan(l.ctx, request.ID, rescanErr); err != nil {
+			fmt.Printf("Failed to complete Solana rescan request %s: %v\n", request.ID, err)
+		}
+	}
+}
+
+// rescan processes a requested signature or slot range again without skipping recorded transactions
+// The cursor is not moved
+func (l *TransactionListener) rescan(request *blockchainDomain.RescanRequest) error {
+	ctx, cancel := context.WithTimeout(l.ctx, 5*time.Minute)
+	defer cancel()
+
+	l.refreshTokenMints(ctx)
+
+	if request.IsTransactionRescan() {
+		signature, err := solana.SignatureFromBase58(request.TxHash.String)
+		if err != nil {
+			return fmt.Errorf("invalid signature %s: %w", request.TxHash.String, err)
+		}
+		return l.rescanSignature(ctx, signature)
+	}
+
+	return l.rescanSlotRange(ctx, uint64(request.FromBlock.Int64), uint64(request.ToBlock.Int64))
+}
+
+// rescanSignature processes a single transaction to the wallet, to a deposit account or carrying a
+// Solana Pay reference key again
+func (l *TransactionListener) rescanSignature(ctx context.Context, signature solana.Signature) error {
+	fmt.Printf("Rescanning Solana transaction %s\n", signature)
+
+	if err := l.handleTransaction(signature, true); err != nil {
+		return err
+	}
+
+	if l.depositProvider == nil && l.referenceProvider == nil {
+		return nil
+	}
+
+	txInfo, err := l.getSuccessfulTransaction(signature)
+	if err != nil {
+		return err
+	}
+
+	if l.depositProvider != nil {
+		accounts, err := l.depositProvider(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load deposit accounts: %w", err)
+		}
+
+		for _, account := range accounts {
+			if txInfo.Transaction.Message.AccountKeys.Contains(account.Address) {
+				l.handleDepositTransaction(signature, account, true)
+			}
+		}
+	}
+
+	if l.referenceProvider != nil {
+		keys, err := l.referenceProvider(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load Solana Pay reference keys: %w", err)
+		}
+
+		for _, key := range keys {
+			if txInfo.Transaction.Message.AccountKeys.Contains(key.Address) {
+				l.handleReferenceTransaction(signature, key, l.walletTokenAccounts(), true)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rescanSlotRange processes the wallet's transactions in a slot range again, oldest first
+// Deposit accounts are not listed, their transactions can be rescanned by signature
+func (l *TransactionListener) rescanSlotRange(ctx context.Context, fromSlot, toSlot uint64) error {
+	fmt.Printf("Rescanning Solana slots %d to %d\n", fromSlot, toSlot)
+
+	limit := signatureBatchSize
+	var matched []*rpc.TransactionSignature
+	var before solana.Signature
+
+	for {
+		page, err := l.client.GetRPCClient().GetSignaturesForAddressWithOpts(
+			ctx,
+			l.wallet.GetPublicKey(),
+			&rpc.GetSignaturesForAddressOpts{
+				Limit:      &limit,
+				Before:     before,
+				Commitment: rpc.CommitmentFinalized,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get signatures: %w", err)
+		}
+
+		for _, sig := range page {
+			if sig.Err == nil && sig.Slot >= fromSlot && sig.Slot <= toSlot {
+				matched = append(matched, sig)
+			}
+		}
+
+		if len(page) < limit || page[len(page)-1].Slot < fromSlot {
+			break
+		}
+
+		before = page[len(page)-1].Signature
+	}
+
+	for i := len(matched) - 1; i >= 0; i-- {
+		if err := l.handleTransaction(matched[i].Signature, true); err != nil && !errors.Is(err, errTransactionFailed) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getSuccessfulTransaction fetches a transaction with retries and waits for it to be finalized
+// Returns an error if the transaction cannot be loaded or failed on-chain
+func (l *TransactionListener) getSuccessfulTransaction(signature solana.Signature) (*TransactionInfo, error) {
+	ctx, cancel := context.WithTimeout(l.ctx, 30*time.Second)
+	defer cancel()
+
+	// Get transaction details with retries
+	var txInfo *TransactionInfo
+	var err error
+
+	for i := 0; i < l.maxRetries; i++ {
+		txInfo, err = l.client.GetTransaction(ctx, signature)
+		if err == nil {
+			break
+		}
+
+		if i < l.maxRetries-1 {
+			time.Sleep(time.Duration(i+1) * time.Second)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction %s after %d retries: %w", signature, l.maxRetries, err)
+	}
+
+	// Check if transaction is finalized
+	if !txInfo.IsFinalized {
+		// Wait for finalization
+		err = l.client.WaitForConfirmation(ctx, signature, rpc.CommitmentFinalized, 30)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s not finalized: %w", signature, err)
+		}
+	}
+
+	// Check if transaction succeeded
+	if txInfo.Error != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errTransactionFailed, signature, txInfo.Error)
+	}
+
+	return txInfo, nil
 }
 
 // PaymentDetails contains extracted payment information from a transaction
@@ -395,12 +1014,6 @@ func (l *TransactionListener) parsePaymentTransaction(txInfo *TransactionInfo) (
 		return nil, fmt.Errorf("invalid transaction info")
 	}
 
-	// Extract memo from transaction
-	memo, err := extractMemoFromTransaction(txInfo.Transaction)
-	if err != nil {
-		return nil, fmt.Errorf("no memo found: %w", err)
-	}
-
 	// Parse SPL token transfer
 	transfer, err := parseSPLTokenTransfer(txInfo.Transaction, l.wallet.GetPublicKey())
 	if err != nil {
@@ -418,6 +1031,9 @@ func (l *TransactionListener) parsePaymentTransaction(txInfo *TransactionInfo) (
 	divisor := decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(tokenInfo.Decimals)))
 	amount := decimal.NewFromBigInt(transfer.Amount, 0).Div(divisor)
 
+	// A transfer without memo is still returned so it can be queued as unmatched
+	memo, _ := extractMemoFromTransaction(txInfo.Transaction)
+
 	details := &PaymentDetails{
 		Recipient:  transfer.Recipient,
 		Sender:     transfer.Sender,
@@ -430,9 +1046,34 @@ func (l *TransactionListener) parsePaymentTransaction(txInfo *TransactionInfo) (
 	return details, nil
 }
 
+// refreshTokenMints reloads the token mints supported by the listener
+// The previous set is kept if the provider fails
+func (l *TransactionListener) refreshTokenMints(ctx context.Context) {
+	if l.tokenProvider == nil {
+		return
+	}
+
+	mints, err := l.tokenProvider(ctx)
+	if err != nil {
+		fmt.Printf("Failed to load Solana token mints: %v\n", err)
+		return
+	}
+
+	l.tokensMu.Lock()
+	l.supportedTokenMints = mints
+	l.tokensMu.Unlock()
+}
+
+// tokenMints returns the supported token mints, the map is replaced on refresh and never modified
+func (l *TransactionListener) tokenMints() map[string]TokenMintInfo {
+	l.tokensMu.RLock()
+	defer l.tokensMu.RUnlock()
+	return l.supportedTokenMints
+}
+
 // isSupportedToken checks if a token mint is supported
 func (l *TransactionListener) isSupportedToken(mint solana.PublicKey) (TokenMintInfo, bool) {
-	for _, tokenInfo := range l.supportedTokenMints {
+	for _, tokenInfo := range l.tokenMints() {
 		if tokenInfo.MintAddress.Equals(mint) {
 			return tokenInfo, true
 		}
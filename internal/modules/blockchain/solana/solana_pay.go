@@ -0,0 +1,169 @@
+package solana
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/memo"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/shopspring/decimal"
+)
+
+// referenceKeyDomain separates reference keys from other hashes of payment IDs
+const referenceKeyDomain = "solana-pay-reference:"
+
+// SolanaPayTransfer is an SPL token transfer composed for a Solana Pay transaction request
+type SolanaPayTransfer struct {
+	Payer                 solana.PublicKey // Signs the transfer and pays the fee
+	Recipient             solana.PublicKey // Wallet receiving the tokens in its associated token account
+	RecipientTokenAccount solana.PublicKey // Optional: token account receiving the tokens instead, e.g. a deposit account
+	Mint                  solana.PublicKey
+	Decimals              uint8
+	Amount                decimal.Decimal // In token units
+	Memo                  string
+	Reference             solana.PublicKey
+}
+
+// PaymentReferenceKey returns the Solana Pay reference key of a payment
+// The key is derived from the payment ID, so every transaction request of the payment carries the same key
+// Nobody holds a private key for it, it is only added as a read-only account and looked up
+func PaymentReferenceKey(paymentID string) solana.PublicKey {
+	hash := sha256.Sum256([]byte(referenceKeyDomain + paymentID))
+	return solana.PublicKeyFromBytes(hash[:])
+}
+
+// NewSolanaPayTransaction composes the unsigned transaction of a Solana Pay transfer
+// Following the Solana Pay specification the memo instruction comes before the transfer,
+// and the reference key is a read-only, non-signer account of the TransferChecked instruction
+func NewSolanaPayTransaction(transfer SolanaPayTransfer, recentBlockhash solana.Hash) (*solana.Transaction, error) {
+	if transfer.Payer.IsZero() || transfer.Mint.IsZero() {
+		return nil, fmt.Errorf("payer and mint are required")
+	}
+	if transfer.Recipient.IsZero() && transfer.RecipientTokenAccount.IsZero() {
+		return nil, fmt.Errorf("recipient is required")
+	}
+	if transfer.Reference.IsZero() {
+		return nil, fmt.Errorf("reference key is required")
+	}
+	if !transfer.Amount.IsPositive() {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	baseUnits := transfer.Amount.Shift(int32(transfer.Decimals))
+	if !baseUnits.Equal(baseUnits.Truncate(0)) {
+		return nil, fmt.Errorf("amount %s has more than %d decimals", transfer.Amount, transfer.Decimals)
+	}
+	if baseUnits.GreaterThan(decimal.NewFromUint64(math.MaxUint64)) {
+		return nil, fmt.Errorf("amount %s is too large", transfer.Amount)
+	}
+
+	sourceATA, _, err := solana.FindAssociatedTokenAddress(transfer.Payer, transfer.Mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive payer token account: %w", err)
+	}
+
+	destination := transfer.RecipientTokenAccount
+	if destination.IsZero() {
+		destination, _, err = solana.FindAssociatedTokenAddress(transfer.Recipient, transfer.Mint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive recipient token account: %w", err)
+		}
+	}
+
+	transferChecked, err := token.NewTransferCheckedInstruction(
+		baseUnits.BigInt().Uint64(),
+		transfer.Decimals,
+		sourceATA,
+		transfer.Mint,
+		destination,
+		transfer.Payer,
+		[]solana.PublicKey{},
+	).ValidateAndBuild()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build transfer instruction: %w", err)
+	}
+
+	data, err := transferChecked.Data()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transfer instruction: %w", err)
+	}
+
+	accounts := append(solana.AccountMetaSlice(transferChecked.Accounts()), solana.Meta(transfer.Reference))
+
+	var instructions []solana.Instruction
+	if transfer.Memo != "" {
+		// The memo program takes the raw UTF-8 message as instruction data
+		instructions = append(instructions, solana.NewInstruction(
+			memo.ProgramID,
+			solana.AccountMetaSlice{solana.Meta(transfer.Payer).SIGNER()},
+			[]byte(transfer.Memo),
+		))
+	}
+	instructions = append(instructions, solana.NewInstruction(token.ProgramID, accounts, data))
+
+	tx, err := solana.NewTransaction(instructions, recentBlockhash, solana.TransactionPayer(transfer.Payer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+// BuildSolanaPayTransaction composes the transaction of a Solana Pay transfer with the latest blockhash
+// and returns it serialized in base64, with empty signatures for the payer's wallet to fill in
+func (c *Client) BuildSolanaPayTransaction(ctx context.Context, transfer SolanaPayTransfer) (string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	latest, err := c.rpcClient.GetLatestBlockhash(timeoutCtx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest blockhash: %w", err)
+	}
+
+	tx, err := NewSolanaPayTransaction(transfer, latest.Value.Blockhash)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeUnsignedTransaction(tx)
+}
+
+// encodeUnsignedTransaction serializes a transaction that has no signatures yet in base64
+func encodeUnsignedTransaction(tx *solana.Transaction) (string, error) {
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// findReferenceTransfer returns the SPL token transfer of a transaction to one of the token accounts
+// The reference key is matched by the caller through getSignaturesForAddress, only the transfer is parsed here
+func findReferenceTransfer(tx *solana.Transaction, tokenAccounts map[solana.PublicKey]solana.PublicKey) (*SPLTokenTransfer, error) {
+	for tokenAccount, mint := range tokenAccounts {
+		transfer, err := parseSPLTokenTransfer(tx, tokenAccount)
+		if err != nil {
+			continue
+		}
+
+		// Transfer instructions do not carry the mint, the token account identifies it
+		if transfer.TokenMint.IsZero() {
+			transfer.TokenMint = mint
+		}
+		if !transfer.TokenMint.Equals(mint) {
+			continue
+		}
+
+		return transfer, nil
+	}
+
+	return nil, fmt.Errorf("no SPL token transfer to the wallet token accounts")
+}
@@ -0,0 +1,150 @@
+package solana
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSolanaPayTransfer() SolanaPayTransfer {
+	return SolanaPayTransfer{
+		Payer:     solana.NewWallet().PublicKey(),
+		Recipient: solana.NewWallet().PublicKey(),
+		Mint:      solana.MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"),
+		Decimals:  6,
+		Amount:    decimal.RequireFromString("12.5"),
+		Memo:      "payment-123",
+		Reference: PaymentReferenceKey("payment-123"),
+	}
+}
+
+func TestPaymentReferenceKey(t *testing.T) {
+	assert.Equal(t, PaymentReferenceKey("payment-123"), PaymentReferenceKey("payment-123"))
+	assert.NotEqual(t, PaymentReferenceKey("payment-123"), PaymentReferenceKey("payment-124"))
+	assert.False(t, PaymentReferenceKey("payment-123").IsZero())
+}
+
+func TestNewSolanaPayTransaction(t *testing.T) {
+	transfer := testSolanaPayTransfer()
+
+	tx, err := NewSolanaPayTransaction(transfer, solana.Hash{1})
+	require.NoError(t, err)
+
+	// The payer pays the fee and is the only signer
+	assert.Equal(t, transfer.Payer, tx.Message.AccountKeys[0])
+	assert.Equal(t, uint8(1), tx.Message.Header.NumRequiredSignatures)
+
+	// The memo comes before the transfer
+	require.Len(t, tx.Message.Instructions, 2)
+	memoProgram, err := tx.ResolveProgramIDIndex(tx.Message.Instructions[0].ProgramIDIndex)
+	require.NoError(t, err)
+	assert.Equal(t, "MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr", memoProgram.String())
+
+	memo, err := extractMemoFromTransaction(tx)
+	require.NoError(t, err)
+	assert.Equal(t, "payment-123", memo)
+
+	recipientATA, _, err := solana.FindAssociatedTokenAddress(transfer.Recipient, transfer.Mint)
+	require.NoError(t, err)
+
+	parsed, err := parseSPLTokenTransfer(tx, recipientATA)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(12_500_000), parsed.Amount)
+	assert.Equal(t, transfer.Mint, parsed.TokenMint)
+	assert.Equal(t, transfer.Payer, parsed.Sender)
+
+	// The reference is a read-only, non-signer account of the transfer
+	referenceIndex, err := tx.GetAccountIndex(transfer.Reference)
+	require.NoError(t, err)
+	assert.Contains(t, tx.Message.Instructions[1].Accounts, referenceIndex)
+	assert.False(t, tx.IsSigner(transfer.Reference))
+	isWritable, err := tx.IsWritable(transfer.Reference)
+	require.NoError(t, err)
+	assert.False(t, isWritable)
+}
+
+func TestNewSolanaPayTransaction_Validation(t *testing.T) {
+	transfer := testSolanaPayTransfer()
+	transfer.Amount = decimal.RequireFromString("1.0000001")
+	_, err := NewSolanaPayTransaction(transfer, solana.Hash{1})
+	assert.ErrorContains(t, err, "more than 6 decimals")
+
+	transfer = testSolanaPayTransfer()
+	transfer.Amount = decimal.Zero
+	_, err = NewSolanaPayTransaction(transfer, solana.Hash{1})
+	assert.ErrorContains(t, err, "amount must be positive")
+
+	transfer = testSolanaPayTransfer()
+	transfer.Reference = solana.PublicKey{}
+	_, err = NewSolanaPayTransaction(transfer, solana.Hash{1})
+	assert.ErrorContains(t, err, "reference key is required")
+}
+
+func TestNewSolanaPayTransaction_WithoutMemo(t *testing.T) {
+	transfer := testSolanaPayTransfer()
+	transfer.Memo = ""
+
+	tx, err := NewSolanaPayTransaction(transfer, solana.Hash{1})
+	require.NoError(t, err)
+	assert.Len(t, tx.Message.Instructions, 1)
+}
+
+func TestEncodeUnsignedTransaction(t *testing.T) {
+	transfer := testSolanaPayTransfer()
+	tx, err := NewSolanaPayTransaction(transfer, solana.Hash{1})
+	require.NoError(t, err)
+
+	encoded, err := encodeUnsignedTransaction(tx)
+	require.NoError(t, err)
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	decoded, err := solana.TransactionFromBytes(raw)
+	require.NoError(t, err)
+
+	require.Len(t, decoded.Signatures, 1)
+	assert.True(t, decoded.Signatures[0].IsZero())
+	assert.Equal(t, transfer.Payer, decoded.Message.AccountKeys[0])
+	assert.Equal(t, solana.Hash{1}, decoded.Message.RecentBlockhash)
+}
+
+func TestFindReferenceTransfer(t *testing.T) {
+	transfer := testSolanaPayTransfer()
+	tx, err := NewSolanaPayTransaction(transfer, solana.Hash{1})
+	require.NoError(t, err)
+
+	recipientATA, _, err := solana.FindAssociatedTokenAddress(transfer.Recipient, transfer.Mint)
+	require.NoError(t, err)
+
+	found, err := findReferenceTransfer(tx, map[solana.PublicKey]solana.PublicKey{recipientATA: transfer.Mint})
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(12_500_000), found.Amount)
+
+	// A token account of another mint does not match
+	otherMint := solana.NewWallet().PublicKey()
+	_, err = findReferenceTransfer(tx, map[solana.PublicKey]solana.PublicKey{recipientATA: otherMint})
+	assert.Error(t, err)
+
+	otherATA, _, err := solana.FindAssociatedTokenAddress(solana.NewWallet().PublicKey(), transfer.Mint)
+	require.NoError(t, err)
+	_, err = findReferenceTransfer(tx, map[solana.PublicKey]solana.PublicKey{otherATA: transfer.Mint})
+	assert.Error(t, err)
+}
+
+func TestNewSolanaPayTransaction_RecipientTokenAccount(t *testing.T) {
+	transfer := testSolanaPayTransfer()
+	transfer.Recipient = solana.PublicKey{}
+	transfer.RecipientTokenAccount = solana.NewWallet().PublicKey()
+
+	tx, err := NewSolanaPayTransaction(transfer, solana.Hash{1})
+	require.NoError(t, err)
+
+	parsed, err := parseSPLTokenTransfer(tx, transfer.RecipientTokenAccount)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(12_500_000), parsed.Amount)
+}
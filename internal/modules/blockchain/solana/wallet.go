@@ -8,6 +8,7 @@ import (
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
 	"github.com/gagliardetto/solana-go/programs/system"
 	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
@@ -269,6 +270,16 @@ func (w *Wallet) SignTransaction(tx *solana.Transaction) error {
 
 // SignAndSendTransaction signs and sends a transaction to the Solana network
 func (w *Wallet) SignAndSendTransaction(ctx context.Context, tx *solana.Transaction) (solana.Signature, error) {
+	if _, err := w.PrepareTransaction(ctx, tx); err != nil {
+		return solana.Signature{}, err
+	}
+
+	return w.SendSignedTransaction(ctx, tx)
+}
+
+// PrepareTransaction sets a recent blockhash on the transaction and signs it without sending it
+// The returned signature identifies the transaction once it is sent
+func (w *Wallet) PrepareTransaction(ctx context.Context, tx *solana.Transaction) (solana.Signature, error) {
 	// Get recent blockhash
 	recent, err := w.rpcClient.GetRecentBlockhash(ctx, rpc.CommitmentFinalized)
 	if err != nil {
@@ -282,7 +293,11 @@ func (w *Wallet) SignAndSendTransaction(ctx context.Context, tx *solana.Transact
 		return solana.Signature{}, err
 	}
 
-	// Send transaction
+	return tx.Signatures[0], nil
+}
+
+// SendSignedTransaction sends a transaction signed by PrepareTransaction to the Solana network
+func (w *Wallet) SendSignedTransaction(ctx context.Context, tx *solana.Transaction) (solana.Signature, error) {
 	sig, err := w.rpcClient.SendTransactionWithOpts(
 		ctx,
 		tx,
@@ -334,6 +349,227 @@ func (w *Wallet) VerifyTransaction(ctx context.Context, signature solana.Signatu
 	return fmt.Errorf("transaction not finalized after %d retries", maxRetries)
 }
 
+// GetTransactionStatus checks the status of a transaction once without waiting
+// Returns nil if the transaction is not known to the cluster yet
+func (w *Wallet) GetTransactionStatus(ctx context.Context, signature solana.Signature) (*rpc.SignatureStatusesResult, error) {
+	status, err := w.rpcClient.GetSignatureStatuses(ctx, true, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature status: %w", err)
+	}
+
+	if status == nil || len(status.Value) == 0 {
+		return nil, nil
+	}
+
+	return status.Value[0], nil
+}
+
+// CreateTokenTransferTransaction builds an unsigned SPL token transfer from the wallet to recipient
+// The recipient's associated token account is created if it does not exist yet (paid by the wallet)
+// Use SignAndSendTransaction to submit the returned transaction
+func (w *Wallet) CreateTokenTransferTransaction(ctx context.Context, tokenMint string, recipient solana.PublicKey, amount decimal.Decimal) (*solana.Transaction, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, fmt.Errorf("transfer amount must be positive")
+	}
+
+	mintPubkey, err := solana.PublicKeyFromBase58(tokenMint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token mint address: %w", err)
+	}
+
+	mintInfo, err := w.GetTokenMintInfo(ctx, tokenMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint info: %w", err)
+	}
+
+	// Convert to smallest token unit
+	rawAmount := amount.Shift(int32(mintInfo.Decimals))
+	if !rawAmount.Equal(rawAmount.Truncate(0)) {
+		return nil, fmt.Errorf("amount %s exceeds token precision of %d decimals", amount.String(), mintInfo.Decimals)
+	}
+
+	sourceATA, _, err := solana.FindAssociatedTokenAddress(w.publicKey, mintPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find source token account: %w", err)
+	}
+
+	destinationATA, _, err := solana.FindAssociatedTokenAddress(recipient, mintPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find destination token account: %w", err)
+	}
+
+	instructions := []solana.Instruction{}
+
+	// Create the recipient's token account if it doesn't exist
+	accountInfo, err := w.rpcClient.GetAccountInfo(ctx, destinationATA)
+	if err != nil || accountInfo == nil || accountInfo.Value == nil {
+		instructions = append(instructions, associatedtokenaccount.NewCreateInstruction(
+			w.publicKey,
+			recipient,
+			mintPubkey,
+		).Build())
+	}
+
+	instructions = append(instructions, token.NewTransferCheckedInstruction(
+		rawAmount.BigInt().Uint64(),
+		mintInfo.Decimals,
+		sourceATA,
+		mintPubkey,
+		destinationATA,
+		w.publicKey,
+		nil,
+	).Build())
+
+	// Recent blockhash is set by SignAndSendTransaction
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(w.publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+// tokenAccountSize is the size of an SPL token account in bytes
+const tokenAccountSize = 165
+
+// DepositTokenAccountAddress returns the address of the token account derived from the wallet and seed
+// The account is owned by the token program, its token authority is the wallet
+func (w *Wallet) DepositTokenAccountAddress(seed string) (solana.PublicKey, error) {
+	return solana.CreateWithSeed(w.publicKey, seed, token.ProgramID)
+}
+
+// CreateDepositTokenAccount creates and initializes a token account at the address derived from the seed
+// and waits until the creation is finalized. An account that already exists is returned with an empty signature,
+// so a creation that was sent but not confirmed can be retried.
+// Payers can send tokens to the account directly, the wallet stays the token authority so it can sweep it
+// Seeds must be at most 32 characters
+func (w *Wallet) CreateDepositTokenAccount(ctx context.Context, seed string, tokenMint string) (solana.PublicKey, solana.Signature, error) {
+	mintPubkey, err := solana.PublicKeyFromBase58(tokenMint)
+	if err != nil {
+		return solana.PublicKey{}, solana.Signature{}, fmt.Errorf("invalid token mint address: %w", err)
+	}
+
+	account, err := w.DepositTokenAccountAddress(seed)
+	if err != nil {
+		return solana.PublicKey{}, solana.Signature{}, fmt.Errorf("failed to derive deposit account: %w", err)
+	}
+
+	accountInfo, err := w.rpcClient.GetAccountInfo(ctx, account)
+	if err == nil && accountInfo != nil && accountInfo.Value != nil {
+		return account, solana.Signature{}, nil
+	}
+
+	rent, err := w.rpcClient.GetMinimumBalanceForRentExemption(ctx, tokenAccountSize, rpc.CommitmentFinalized)
+	if err != nil {
+		return solana.PublicKey{}, solana.Signature{}, fmt.Errorf("failed to get rent exemption: %w", err)
+	}
+
+	instructions := []solana.Instruction{
+		system.NewCreateAccountWithSeedInstruction(
+			w.publicKey,
+			seed,
+			rent,
+			tokenAccountSize,
+			token.ProgramID,
+			w.publicKey,
+			account,
+			w.publicKey,
+		).Build(),
+		token.NewInitializeAccount3Instruction(
+			w.publicKey,
+			account,
+			mintPubkey,
+		).Build(),
+	}
+
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(w.publicKey))
+	if err != nil {
+		return solana.PublicKey{}, solana.Signature{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	signature, err := w.SignAndSendTransaction(ctx, tx)
+	if err != nil {
+		return solana.PublicKey{}, solana.Signature{}, err
+	}
+
+	if err := w.VerifyTransaction(ctx, signature, 0); err != nil {
+		return account, signature, fmt.Errorf("deposit account creation %s not confirmed: %w", signature.String(), err)
+	}
+
+	return account, signature, nil
+}
+
+// SweepDepositTokenAccount moves the whole balance of a deposit token account to the wallet's
+// associated token account and closes the deposit account, returning its rent to the wallet
+// Returns the swept amount in whole tokens
+func (w *Wallet) SweepDepositTokenAccount(ctx context.Context, account solana.PublicKey, tokenMint string) (solana.Signature, decimal.Decimal, error) {
+	mintPubkey, err := solana.PublicKeyFromBase58(tokenMint)
+	if err != nil {
+		return solana.Signature{}, decimal.Zero, fmt.Errorf("invalid token mint address: %w", err)
+	}
+
+	balance, err := w.rpcClient.GetTokenAccountBalance(ctx, account, rpc.CommitmentFinalized)
+	if err != nil {
+		return solana.Signature{}, decimal.Zero, fmt.Errorf("failed to get deposit account balance: %w", err)
+	}
+	if balance == nil || balance.Value == nil {
+		return solana.Signature{}, decimal.Zero, fmt.Errorf("deposit account not found")
+	}
+
+	rawAmount, err := decimal.NewFromString(balance.Value.Amount)
+	if err != nil {
+		return solana.Signature{}, decimal.Zero, fmt.Errorf("invalid deposit account balance: %w", err)
+	}
+
+	instructions := []solana.Instruction{}
+
+	if rawAmount.IsPositive() {
+		destinationATA, _, err := solana.FindAssociatedTokenAddress(w.publicKey, mintPubkey)
+		if err != nil {
+			return solana.Signature{}, decimal.Zero, fmt.Errorf("failed to find wallet token account: %w", err)
+		}
+
+		// Create the wallet's token account if it doesn't exist
+		accountInfo, err := w.rpcClient.GetAccountInfo(ctx, destinationATA)
+		if err != nil || accountInfo == nil || accountInfo.Value == nil {
+			instructions = append(instructions, associatedtokenaccount.NewCreateInstruction(
+				w.publicKey,
+				w.publicKey,
+				mintPubkey,
+			).Build())
+		}
+
+		instructions = append(instructions, token.NewTransferCheckedInstruction(
+			rawAmount.BigInt().Uint64(),
+			balance.Value.Decimals,
+			account,
+			mintPubkey,
+			destinationATA,
+			w.publicKey,
+			nil,
+		).Build())
+	}
+
+	instructions = append(instructions, token.NewCloseAccountInstruction(
+		account,
+		w.publicKey,
+		w.publicKey,
+		nil,
+	).Build())
+
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(w.publicKey))
+	if err != nil {
+		return solana.Signature{}, decimal.Zero, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	signature, err := w.SignAndSendTransaction(ctx, tx)
+	if err != nil {
+		return solana.Signature{}, decimal.Zero, err
+	}
+
+	return signature, rawAmount.Shift(-int32(balance.Value.Decimals)), nil
+}
+
 // CreateTransferInstruction creates a SOL transfer instruction
 func (w *Wallet) CreateTransferInstruction(to solana.PublicKey, lamports uint64) solana.Instruction {
 	return system.NewTransferInstruction(
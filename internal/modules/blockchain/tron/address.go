@@ -0,0 +1,88 @@
+package tron
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// addressPrefix is the first byte of every TRON mainnet and testnet address
+const addressPrefix byte = 0x41
+
+// Address is the 20-byte account identifier shared with the EVM
+// TRON renders it as base58check with a 0x41 prefix (T...)
+type Address [20]byte
+
+// ParseAddress parses a TRON address in base58 (T...), 41-prefixed hex or 0x-prefixed hex form
+func ParseAddress(s string) (Address, error) {
+	var addr Address
+
+	if strings.HasPrefix(s, "T") {
+		decoded, err := base58.Decode(s)
+		if err != nil {
+			return addr, fmt.Errorf("invalid TRON address %s: %w", s, err)
+		}
+		if len(decoded) != 25 {
+			return addr, fmt.Errorf("invalid TRON address length: %s", s)
+		}
+
+		payload, checksum := decoded[:21], decoded[21:]
+		if !bytes.Equal(addressChecksum(payload), checksum) {
+			return addr, fmt.Errorf("invalid TRON address checksum: %s", s)
+		}
+		if payload[0] != addressPrefix {
+			return addr, fmt.Errorf("invalid TRON address prefix: %s", s)
+		}
+
+		copy(addr[:], payload[1:])
+		return addr, nil
+	}
+
+	return addressFromHex(s)
+}
+
+// addressFromHex parses a hex address as returned by the node API
+// Accepts 20-byte bodies, 41-prefixed addresses and 32-byte log topics
+func addressFromHex(s string) (Address, error) {
+	var addr Address
+
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return addr, fmt.Errorf("invalid TRON hex address %s: %w", s, err)
+	}
+
+	switch {
+	case len(raw) == 21 && raw[0] == addressPrefix:
+		copy(addr[:], raw[1:])
+	case len(raw) == 20:
+		copy(addr[:], raw)
+	case len(raw) == 32:
+		copy(addr[:], raw[12:])
+	default:
+		return addr, fmt.Errorf("invalid TRON hex address length: %s", s)
+	}
+
+	return addr, nil
+}
+
+// String returns the base58check form of the address (T...)
+func (a Address) String() string {
+	payload := append([]byte{addressPrefix}, a[:]...)
+	return base58.Encode(append(payload, addressChecksum(payload)...))
+}
+
+// Hex returns the 41-prefixed hex form used by the /wallet API
+func (a Address) Hex() string {
+	return hex.EncodeToString(append([]byte{addressPrefix}, a[:]...))
+}
+
+// addressChecksum returns the first 4 bytes of the double SHA-256 of the payload
+func addressChecksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
@@ -0,0 +1,290 @@
+package tron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrTransactionNotFound is returned when the node does not know a transaction
+// Unconfirmed transactions are reported as not found as well
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// Client wraps the TronGrid-style HTTP API of a TRON full node
+// for blockchain operations and monitoring
+type Client struct {
+	httpClient *http.Client
+	rpcURL     string
+	apiKey     string
+	timeout    time.Duration
+}
+
+// ClientConfig holds configuration for the TRON HTTP client
+type ClientConfig struct {
+	RPCURL  string // e.g. https://api.trongrid.io
+	APIKey  string // Optional TronGrid API key, sent as TRON-PRO-API-KEY
+	Timeout time.Duration
+}
+
+// Log is an event log emitted by a smart contract call
+// Addresses and topics are hex encoded without the 0x prefix
+type Log struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+// TransactionInfo contains the execution result of a TRON transaction
+type TransactionInfo struct {
+	ID              string `json:"id"`
+	BlockNumber     uint64 `json:"blockNumber"`
+	BlockTimeStamp  int64  `json:"blockTimeStamp"`
+	ContractAddress string `json:"contract_address"`
+	Receipt         struct {
+		Result string `json:"result"`
+	} `json:"receipt"`
+	Log    []Log  `json:"log"`
+	Result string `json:"result"` // Set to FAILED when the transaction failed
+}
+
+// IsSuccessful returns true if the transaction executed without error
+func (i *TransactionInfo) IsSuccessful() bool {
+	if i.Result == "FAILED" {
+		return false
+	}
+	return i.Receipt.Result == "" || i.Receipt.Result == "SUCCESS"
+}
+
+// Transaction is a TRON transaction as returned by gettransactionbyid
+type Transaction struct {
+	TxID    string             `json:"txID"`
+	RawData TransactionRawData `json:"raw_data"`
+	Ret     []struct {
+		ContractRet string `json:"contractRet"`
+	} `json:"ret"`
+}
+
+// TransactionRawData is the signed part of a TRON transaction
+type TransactionRawData struct {
+	Data      string                `json:"data"` // Hex encoded note, used as payment memo
+	Timestamp int64                 `json:"timestamp"`
+	Contract  []TransactionContract `json:"contract"`
+}
+
+// TransactionContract is a single contract call of a transaction
+type TransactionContract struct {
+	Type      string `json:"type"` // e.g. TriggerSmartContract for TRC-20 transfers
+	Parameter struct {
+		Value struct {
+			OwnerAddress    string `json:"owner_address"`
+			ContractAddress string `json:"contract_address"`
+			Data            string `json:"data"`
+		} `json:"value"`
+	} `json:"parameter"`
+}
+
+// nowBlockResponse is the subset of getnowblock used to read the chain head
+type nowBlockResponse struct {
+	BlockID     string `json:"blockID"`
+	BlockHeader struct {
+		RawData struct {
+			Number    uint64 `json:"number"`
+			Timestamp int64  `json:"timestamp"`
+		} `json:"raw_data"`
+	} `json:"block_header"`
+}
+
+// NewClient creates a new TRON HTTP client with the given configuration
+func NewClient(config ClientConfig) (*Client, error) {
+	if config.RPCURL == "" {
+		return nil, fmt.Errorf("RPC URL cannot be empty")
+	}
+	if !strings.HasPrefix(config.RPCURL, "http://") && !strings.HasPrefix(config.RPCURL, "https://") {
+		return nil, fmt.Errorf("RPC URL must be an HTTP endpoint: %s", config.RPCURL)
+	}
+
+	// Set default timeout if not specified
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	client := &Client{
+		httpClient: &http.Client{Timeout: timeout},
+		rpcURL:     strings.TrimSuffix(config.RPCURL, "/"),
+		apiKey:     config.APIKey,
+		timeout:    timeout,
+	}
+
+	return client, nil
+}
+
+// NewClientWithURL creates a new TRON HTTP client with just the RPC URL
+// Uses default timeout of 30 seconds
+func NewClientWithURL(rpcURL string) (*Client, error) {
+	return NewClient(ClientConfig{
+		RPCURL:  rpcURL,
+		Timeout: 30 * time.Second,
+	})
+}
+
+// GetBlockNumber returns the current block number
+func (c *Client) GetBlockNumber(ctx context.Context) (uint64, error) {
+	var block nowBlockResponse
+	if err := c.post(ctx, "/wallet/getnowblock", nil, &block); err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	if block.BlockID == "" {
+		return 0, fmt.Errorf("failed to get block number: empty block")
+	}
+
+	return block.BlockHeader.RawData.Number, nil
+}
+
+// GetTransactionInfoByBlockNum returns the execution results of all transactions in a block
+func (c *Client) GetTransactionInfoByBlockNum(ctx context.Context, blockNum uint64) ([]*TransactionInfo, error) {
+	var raw json.RawMessage
+	if err := c.post(ctx, "/wallet/gettransactioninfobyblocknum", map[string]interface{}{"num": blockNum}, &raw); err != nil {
+		return nil, fmt.Errorf("failed to get transactions of block %d: %w", blockNum, err)
+	}
+
+	// Blocks without transactions are returned as an empty object
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return []*TransactionInfo{}, nil
+	}
+
+	var infos []*TransactionInfo
+	if err := json.Unmarshal(trimmed, &infos); err != nil {
+		return nil, fmt.Errorf("failed to decode transactions of block %d: %w", blockNum, err)
+	}
+
+	return infos, nil
+}
+
+// GetTransactionInfoByID returns the execution result of a transaction
+func (c *Client) GetTransactionInfoByID(ctx context.Context, txID string) (*TransactionInfo, error) {
+	var info TransactionInfo
+	if err := c.post(ctx, "/wallet/gettransactioninfobyid", map[string]string{"value": txID}, &info); err != nil {
+		return nil, fmt.Errorf("failed to get transaction info: %w", err)
+	}
+
+	// Unknown and unconfirmed transactions are returned as an empty object
+	if info.ID == "" {
+		return nil, fmt.Errorf("%w: no transaction info for %s", ErrTransactionNotFound, txID)
+	}
+
+	return &info, nil
+}
+
+// GetTransactionByID returns a transaction with its raw data
+func (c *Client) GetTransactionByID(ctx context.Context, txID string) (*Transaction, error) {
+	var tx Transaction
+	if err := c.post(ctx, "/wallet/gettransactionbyid", map[string]string{"value": txID}, &tx); err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if tx.TxID == "" {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, txID)
+	}
+
+	return &tx, nil
+}
+
+// GetConfirmations returns the number of confirmations for a transaction
+func (c *Client) GetConfirmations(ctx context.Context, txID string) (uint64, error) {
+	info, err := c.GetTransactionInfoByID(ctx, txID)
+	if err != nil {
+		return 0, err
+	}
+
+	currentBlock, err := c.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if currentBlock < info.BlockNumber {
+		return 0, nil
+	}
+
+	return currentBlock - info.BlockNumber + 1, nil
+}
+
+// HealthCheck verifies the client can reach the TRON node
+func (c *Client) HealthCheck(ctx context.Context) error {
+	if _, err := c.GetBlockNumber(ctx); err != nil {
+		return fmt.Errorf("RPC health check failed: %w", err)
+	}
+	return nil
+}
+
+// GetRPCURL returns the RPC URL being used
+func (c *Client) GetRPCURL() string {
+	return c.rpcURL
+}
+
+// GetTimeout returns the configured timeout
+func (c *Client) GetTimeout() time.Duration {
+	return c.timeout
+}
+
+// post calls a node API endpoint and decodes the JSON response into out
+func (c *Client) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	payload := []byte("{}")
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(timeoutCtx, http.MethodPost, c.rpcURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("TRON-PRO-API-KEY", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	// The node reports request errors with a 200 status and an Error field
+	var apiErr struct {
+		Error string `json:"Error"`
+	}
+	if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+		return fmt.Errorf("node error: %s", apiErr.Error)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package tron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode is a local stand-in for the TronGrid HTTP API
+type fakeNode struct {
+	mu           sync.Mutex
+	blockNumber  uint64
+	blocks       map[uint64][]*TransactionInfo
+	transactions map[string]*Transaction
+	apiKeys      []string
+	// failures is the number of gettransactionbyid calls to fail for a transaction
+	failures map[string]int
+}
+
+func newFakeNode(t *testing.T, blockNumber uint64) (*fakeNode, *httptest.Server) {
+	node := &fakeNode{
+		blockNumber:  blockNumber,
+		blocks:       make(map[uint64][]*TransactionInfo),
+		transactions: make(map[string]*Transaction),
+		failures:     make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wallet/getnowblock", func(w http.ResponseWriter, r *http.Request) {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		node.apiKeys = append(node.apiKeys, r.Header.Get("TRON-PRO-API-KEY"))
+
+		var block nowBlockResponse
+		block.BlockID = "0000000000000001"
+		block.BlockHeader.RawData.Number = node.blockNumber
+		writeJSON(w, block)
+	})
+	mux.HandleFunc("/wallet/gettransactioninfobyblocknum", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Num uint64 `json:"num"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		infos, ok := node.blocks[req.Num]
+		if !ok {
+			writeJSON(w, map[string]interface{}{})
+			return
+		}
+		writeJSON(w, infos)
+	})
+	mux.HandleFunc("/wallet/gettransactionbyid", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Value string `json:"value"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		if node.failures[req.Value] > 0 {
+			node.failures[req.Value]--
+			http.Error(w, "gateway timeout", http.StatusGatewayTimeout)
+			return
+		}
+		tx, ok := node.transactions[req.Value]
+		if !ok {
+			writeJSON(w, map[string]interface{}{})
+			return
+		}
+		writeJSON(w, tx)
+	})
+	mux.HandleFunc("/wallet/gettransactioninfobyid", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Value string `json:"value"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		for _, infos := range node.blocks {
+			for _, info := range infos {
+				if info.ID == req.Value {
+					writeJSON(w, info)
+					return
+				}
+			}
+		}
+		writeJSON(w, map[string]interface{}{})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return node, server
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(ClientConfig{})
+	assert.Error(t, err)
+
+	// The gRPC endpoint of the old configuration is not usable
+	_, err = NewClient(ClientConfig{RPCURL: "grpc.shasta.trongrid.io:50051"})
+	assert.Error(t, err)
+
+	client, err := NewClient(ClientConfig{RPCURL: "https://api.shasta.trongrid.io/"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.shasta.trongrid.io", client.GetRPCURL())
+}
+
+func TestClient_AgainstFakeNode(t *testing.T) {
+	node, server := newFakeNode(t, 120)
+	node.blocks[100] = []*TransactionInfo{{ID: "tx-1", BlockNumber: 100}}
+	node.transactions["tx-1"] = &Transaction{TxID: "tx-1"}
+
+	client, err := NewClient(ClientConfig{RPCURL: server.URL, APIKey: "test-key"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	blockNumber, err := client.GetBlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(120), blockNumber)
+	assert.Equal(t, []string{"test-key"}, node.apiKeys)
+
+	infos, err := client.GetTransactionInfoByBlockNum(ctx, 100)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "tx-1", infos[0].ID)
+
+	// Empty blocks are returned as an empty object
+	infos, err = client.GetTransactionInfoByBlockNum(ctx, 101)
+	require.NoError(t, err)
+	assert.Empty(t, infos)
+
+	tx, err := client.GetTransactionByID(ctx, "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, "tx-1", tx.TxID)
+
+	_, err = client.GetTransactionByID(ctx, "missing")
+	assert.Error(t, err)
+
+	confirmations, err := client.GetConfirmations(ctx, "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(21), confirmations)
+
+	assert.NoError(t, client.HealthCheck(ctx))
+}
+
+func TestClient_NodeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"Error": "class org.tron.core.exception.BadItemException"})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{RPCURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = client.GetBlockNumber(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "BadItemException")
+}
@@ -0,0 +1,608 @@
+package tron
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+)
+
+// PaymentConfirmationCallback is called when a payment is confirmed
+// paymentID: the payment ID extracted from the transaction memo
+// txHash: the transaction ID
+// amount: the amount transferred in decimal form
+// tokenSymbol: the token symbol (e.g., "USDT", "USDC")
+// fromAddress: the sender of the transfer
+type PaymentConfirmationCallback func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error
+
+// TransactionListener monitors the TRON blockchain for incoming TRC-20 token transfers
+// to a specific wallet address
+type TransactionListener struct {
+	client               *Client
+	walletAddress        Address
+	confirmationCallback PaymentConfirmationCallback
+	tokenProvider        TokenContractProvider
+	store                blockchainDomain.ListenerStore
+
+	// Supported token contracts for filtering, refreshed from tokenProvider every poll
+	supportedTokenContracts map[string]TokenContractInfo
+
+	// Control channels
+	ctx        context.Context
+	cancel     context.CancelFunc
+	shutdownCh chan struct{}
+	wg         sync.WaitGroup
+
+	// Configuration
+	pollInterval          time.Duration
+	requiredConfirmations uint64
+	maxRetries            int
+
+	// State
+	isRunning          bool
+	mu                 sync.RWMutex
+	lastProcessedBlock uint64
+	processedTxs       map[string]bool // Track processed transactions to avoid duplicates
+	processedTxsMu     sync.RWMutex
+}
+
+// TokenContractInfo contains information about supported TRC-20 tokens
+type TokenContractInfo struct {
+	ContractAddress Address
+	Symbol          string
+	Decimals        uint8
+}
+
+// TokenContractProvider returns the supported TRC-20 token contracts mapped to their symbols
+// The listener reloads them every poll, so tokens enabled or disabled meanwhile are picked up without a restart
+type TokenContractProvider func(ctx context.Context) (map[string]TokenContractInfo, error)
+
+// ListenerConfig holds configuration for the transaction listener
+type ListenerConfig struct {
+	Client                  *Client
+	WalletAddress           string // Hot wallet address (T...)
+	ConfirmationCallback    PaymentConfirmationCallback
+	TokenContractProvider   TokenContractProvider          // Optional, reloads the supported token contracts every poll
+	Store                   blockchainDomain.ListenerStore // Optional, persists the cursor so downtime is backfilled
+	SupportedTokenContracts map[string]TokenContractInfo   // Used until the token provider first answers
+	PollInterval            time.Duration
+	RequiredConfirmations   uint64
+	MaxRetries              int
+}
+
+// NewTransactionListener creates a new transaction listener
+func NewTransactionListener(config ListenerConfig) (*TransactionListener, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	walletAddress, err := ParseAddress(config.WalletAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet address: %w", err)
+	}
+
+	if config.ConfirmationCallback == nil {
+		return nil, fmt.Errorf("confirmation callback cannot be nil")
+	}
+
+	// Set defaults
+	pollInterval := config.PollInterval
+	if pollInterval == 0 {
+		pollInterval = 10 * time.Second // TRON block time is ~3 seconds, poll every 10 seconds
+	}
+
+	requiredConfirmations := config.RequiredConfirmations
+	if requiredConfirmations == 0 {
+		requiredConfirmations = 19 // Blocks are solidified after 19 confirmations
+	}
+
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	listener := &TransactionListener{
+		client:                  config.Client,
+		walletAddress:           walletAddress,
+		confirmationCallback:    config.ConfirmationCallback,
+		tokenProvider:           config.TokenContractProvider,
+		store:                   config.Store,
+		supportedTokenContracts: config.SupportedTokenContracts,
+		ctx:                     ctx,
+		cancel:                  cancel,
+		shutdownCh:              make(chan struct{}),
+		pollInterval:            pollInterval,
+		requiredConfirmations:   requiredConfirmations,
+		maxRetries:              maxRetries,
+		isRunning:               false,
+		processedTxs:            make(map[string]bool),
+	}
+
+	return listener, nil
+}
+
+// Start begins listening for transactions
+func (l *TransactionListener) Start() error {
+	l.mu.Lock()
+	if l.isRunning {
+		l.mu.Unlock()
+		return fmt.Errorf("listener is already running")
+	}
+	l.isRunning = true
+	l.mu.Unlock()
+
+	if err := l.initialize(context.Background()); err != nil {
+		l.mu.Lock()
+		l.isRunning = false
+		l.mu.Unlock()
+		return err
+	}
+
+	fmt.Printf("Starting TRON listener from block %d\n", l.GetLastProcessedBlock())
+
+	// Start polling goroutine
+	l.wg.Add(1)
+	go l.pollBlocks()
+
+	return nil
+}
+
+// initialize resumes from the saved cursor
+// Without a saved cursor the listener starts from the newest block that already has enough confirmations
+func (l *TransactionListener) initialize(ctx context.Context) error {
+	if l.store != nil {
+		cursor, err := l.store.LoadCursor(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load TRON listener cursor: %w", err)
+		}
+
+		if cursor != nil {
+			l.mu.Lock()
+			l.lastProcessedBlock = uint64(cursor.BlockNumber)
+			l.mu.Unlock()
+			return nil
+		}
+	}
+
+	currentBlock, err := l.client.GetBlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current block number: %w", err)
+	}
+
+	l.mu.Lock()
+	l.lastProcessedBlock = l.confirmedHead(currentBlock)
+	l.mu.Unlock()
+	l.saveCursor(ctx)
+
+	return nil
+}
+
+// Stop gracefully stops the listener
+func (l *TransactionListener) Stop() error {
+	l.mu.Lock()
+	if !l.isRunning {
+		l.mu.Unlock()
+		return fmt.Errorf("listener is not running")
+	}
+	l.mu.Unlock()
+
+	// Cancel context to signal shutdown
+	l.cancel()
+
+	// Wait for goroutines to finish
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	// Wait with timeout
+	select {
+	case <-done:
+		l.mu.Lock()
+		l.isRunning = false
+		l.mu.Unlock()
+		close(l.shutdownCh)
+		return nil
+	case <-time.After(10 * time.Second):
+		l.mu.Lock()
+		l.isRunning = false
+		l.mu.Unlock()
+		return fmt.Errorf("listener shutdown timeout")
+	}
+}
+
+// IsRunning returns whether the listener is currently running
+func (l *TransactionListener) IsRunning() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.isRunning
+}
+
+// GetLastProcessedBlock returns the last fully processed block number
+func (l *TransactionListener) GetLastProcessedBlock() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lastProcessedBlock
+}
+
+// pollBlocks periodically polls for new blocks and processes transactions
+func (l *TransactionListener) pollBlocks() {
+	defer l.wg.Done()
+
+	// Backfill the gap since the saved cursor right away instead of waiting for the first tick
+	l.processNewBlocks()
+
+	ticker := time.NewTicker(l.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			l.processNewBlocks()
+			l.processRescans()
+		}
+	}
+}
+
+// processNewBlocks processes the confirmed blocks since the last processed block
+// A gap left by downtime is backfilled in batches, the cursor is saved after each one
+func (l *TransactionListener) processNewBlocks() {
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+
+		if !l.processNextBatch() {
+			return
+		}
+	}
+}
+
+// processNextBatch processes the next batch of confirmed blocks and returns true if more are waiting
+// Only blocks with enough confirmations are read, so every block is processed exactly once
+func (l *TransactionListener) processNextBatch() bool {
+	ctx, cancel := context.WithTimeout(l.ctx, 60*time.Second)
+	defer cancel()
+
+	// Get current block number
+	currentBlock, err := l.client.GetBlockNumber(ctx)
+	if err != nil {
+		fmt.Printf("Failed to get current TRON block number: %v\n", err)
+		return false
+	}
+
+	// Limit the number of blocks to process at once to avoid overwhelming the node
+	maxBlocksPerIteration := uint64(100)
+	fromBlock := l.GetLastProcessedBlock() + 1
+	toBlock := l.confirmedHead(currentBlock)
+
+	if fromBlock > toBlock {
+		// No new confirmed blocks to process
+		return false
+	}
+
+	hasMore := false
+	if toBlock-fromBlock > maxBlocksPerIteration {
+		toBlock = fromBlock + maxBlocksPerIteration
+		hasMore = true
+	}
+
+	fmt.Printf("Processing TRON blocks %d to %d\n", fromBlock, toBlock)
+
+	l.refreshTokenContracts(ctx)
+
+	// The cursor is saved up to the last block that was fully processed
+	defer l.saveCursor(ctx)
+
+	for blockNum := fromBlock; blockNum <= toBlock; blockNum++ {
+		select {
+		case <-l.ctx.Done():
+			return false
+		default:
+		}
+
+		// Stop at the first block that cannot be read, it is retried on the next poll
+		if err := l.processBlock(ctx, blockNum, false); err != nil {
+			fmt.Printf("Failed to process TRON block %d: %v\n", blockNum, err)
+			return false
+		}
+
+		l.mu.Lock()
+		l.lastProcessedBlock = blockNum
+		l.mu.Unlock()
+	}
+
+	return hasMore
+}
+
+// saveCursor saves the last processed block to the store
+// A failed save is only logged, the blocks are processed again after a restart and deduplicated
+func (l *TransactionListener) saveCursor(ctx context.Context) {
+	if l.store == nil {
+		return
+	}
+
+	block := l.GetLastProcessedBlock()
+	if err := l.store.SaveCursor(ctx, block, ""); err != nil {
+		fmt.Printf("Failed to save TRON listener cursor at block %d: %v\n", block, err)
+	}
+}
+
+// processBlock processes a single block and looks for transfers to the wallet
+// Recorded transactions are skipped unless force is set
+func (l *TransactionListener) processBlock(ctx context.Context, blockNum uint64, force bool) error {
+	infos, err := l.client.GetTransactionInfoByBlockNum(ctx, blockNum)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if !info.IsSuccessful() || len(info.Log) == 0 {
+			continue
+		}
+
+		if err := l.handleTransaction(ctx, info, force); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleTransaction processes a single transaction
+// Store and node errors are returned so the block is retried, other failures are logged and skipped
+func (l *TransactionListener) handleTransaction(ctx context.Context, info *TransactionInfo, force bool) error {
+	txHash := info.ID
+
+	// Check if already processed
+	l.processedTxsMu.RLock()
+	alreadyProcessed := l.processedTxs[txHash]
+	l.processedTxsMu.RUnlock()
+
+	if alreadyProcessed && !force {
+		return nil
+	}
+
+	if !force && l.store != nil {
+		recorded, err := l.store.IsTransactionRecorded(ctx, txHash)
+		if err != nil {
+			return fmt.Errorf("failed to check transaction %s: %w", txHash, err)
+		}
+		if recorded {
+			return nil
+		}
+	}
+
+	for _, tokenInfo := range l.supportedTokenContracts {
+		// Parse TRC-20 token transfer
+		transfer, err := parseTRC20TokenTransfer(info, l.walletAddress, tokenInfo.ContractAddress)
+		if err != nil {
+			// Not a transfer of this token to our wallet
+			continue
+		}
+
+		fmt.Printf("Detected TRC-20 transfer: %s sent %s %s to wallet\n",
+			transfer.From.String(), transfer.Amount.String(), tokenInfo.Symbol)
+
+		// The memo is only part of the transaction itself, not of its execution result
+		tx, err := l.getTransaction(ctx, txHash)
+		if err != nil {
+			// The transfer is ours, skipping it would move the cursor past it
+			return fmt.Errorf("failed to get transaction %s: %w", txHash, err)
+		}
+
+		// Convert amount based on token decimals
+		divisor := decimal.NewFromInt(10).Pow(decimal.NewFromInt(int64(tokenInfo.Decimals)))
+		amount := decimal.NewFromBigInt(transfer.Amount, 0).Div(divisor)
+
+		observed := blockchainDomain.ObservedTransfer{
+			TxHash:       txHash,
+			BlockNumber:  info.BlockNumber,
+			FromAddress:  transfer.From.String(),
+			ToAddress:    transfer.To.String(),
+			Amount:       amount,
+			Currency:     tokenInfo.Symbol,
+			TokenAddress: tokenInfo.ContractAddress.String(),
+		}
+		if info.BlockTimeStamp > 0 {
+			observed.BlockTime = time.UnixMilli(info.BlockTimeStamp)
+		}
+
+		paymentID, err := extractMemoFromTransaction(tx)
+		if err != nil {
+			fmt.Printf("Warning: No payment ID found in transaction %s: %v\n", txHash, err)
+			l.recordInboundTransfer(ctx, observed, nil)
+			return nil
+		}
+		observed.Memo = paymentID
+		observed.PaymentID = paymentID
+
+		// Call the confirmation callback
+		err = l.confirmationCallback(
+			paymentID,
+			txHash,
+			amount,
+			tokenInfo.Symbol,
+			observed.FromAddress,
+		)
+
+		if err != nil {
+			fmt.Printf("Payment confirmation callback failed for %s: %v\n", txHash, err)
+			l.recordInboundTransfer(ctx, observed, err)
+			return nil
+		}
+
+		// Mark transaction as processed
+		l.processedTxsMu.Lock()
+		l.processedTxs[txHash] = true
+		l.processedTxsMu.Unlock()
+
+		l.recordInboundTransfer(ctx, observed, nil)
+		l.recordTransaction(ctx, observed)
+
+		fmt.Printf("Successfully confirmed payment %s for transaction %s\n", paymentID, txHash)
+		return nil
+	}
+
+	return nil
+}
+
+// recordTransaction stores a confirmed transfer, failures are logged since the payment is already confirmed
+func (l *TransactionListener) recordTransaction(ctx context.Context, transfer blockchainDomain.ObservedTransfer) {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.RecordTransaction(ctx, transfer); err != nil {
+		fmt.Printf("Failed to record TRON transaction %s: %v\n", transfer.TxHash, err)
+	}
+}
+
+// recordInboundTransfer stores a transfer to the wallet, queued for an operator unless it confirmed its payment
+// Failures are logged, the transfer is recorded again by a rescan
+func (l *TransactionListener) recordInboundTransfer(ctx context.Context, transfer blockchainDomain.ObservedTransfer, matchErr error) {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.RecordInboundTransfer(ctx, transfer, matchErr); err != nil {
+		fmt.Printf("Failed to record TRON inbound transfer %s: %v\n", transfer.TxHash, err)
+	}
+}
+
+// processRescans processes the pending rescan requests of the store
+func (l *TransactionListener) processRescans() {
+	if l.store == nil {
+		return
+	}
+
+	requests, err := l.store.PendingRescans(l.ctx)
+	if err != nil {
+		fmt.Printf("Failed to load TRON rescan requests: %v\n", err)
+		return
+	}
+
+	for _, request := range requests {
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+
+		rescanErr := l.rescan(request)
+		if rescanErr != nil {
+			fmt.Printf("Failed to rescan TRON request %s: %v\n", request.ID, rescanErr)
+		}
+
+		if err := l.store.CompleteRescan(l.ctx, request.ID, rescanErr); err != nil {
+			fmt.Printf("Failed to complete TRON rescan request %s: %v\n", request.ID, err)
+		}
+	}
+}
+
+// rescan processes a requested block range or transaction again without skipping recorded transactions
+// The cursor is not moved, only blocks that already have enough confirmations can be rescanned
+func (l *TransactionListener) rescan(request *blockchainDomain.RescanRequest) error {
+	ctx, cancel := context.WithTimeout(l.ctx, 5*time.Minute)
+	defer cancel()
+
+	currentBlock, err := l.client.GetBlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current block number: %w", err)
+	}
+	confirmedHead := l.confirmedHead(currentBlock)
+
+	l.refreshTokenContracts(ctx)
+
+	if request.IsTransactionRescan() {
+		info, err := l.client.GetTransactionInfoByID(ctx, request.TxHash.String)
+		if err != nil {
+			return err
+		}
+
+		if !info.IsSuccessful() {
+			return fmt.Errorf("transaction %s failed", info.ID)
+		}
+
+		if info.BlockNumber > confirmedHead {
+			return fmt.Errorf("transaction %s does not have %d confirmations yet", info.ID, l.requiredConfirmations)
+		}
+
+		fmt.Printf("Rescanning TRON transaction %s\n", info.ID)
+		return l.handleTransaction(ctx, info, true)
+	}
+
+	fromBlock := uint64(request.FromBlock.Int64)
+	toBlock := uint64(request.ToBlock.Int64)
+	if toBlock > confirmedHead {
+		return fmt.Errorf("block %d does not have %d confirmations yet", toBlock, l.requiredConfirmations)
+	}
+
+	fmt.Printf("Rescanning TRON blocks %d to %d\n", fromBlock, toBlock)
+
+	for blockNum := fromBlock; blockNum <= toBlock; blockNum++ {
+		if err := l.processBlock(ctx, blockNum, true); err != nil {
+			return fmt.Errorf("failed to rescan block %d: %w", blockNum, err)
+		}
+	}
+
+	return nil
+}
+
+// refreshTokenContracts reloads the token contracts supported by the listener
+// The previous set is kept if the provider fails
+func (l *TransactionListener) refreshTokenContracts(ctx context.Context) {
+	if l.tokenProvider == nil {
+		return
+	}
+
+	contracts, err := l.tokenProvider(ctx)
+	if err != nil {
+		fmt.Printf("Failed to load TRON token contracts: %v\n", err)
+		return
+	}
+	l.supportedTokenContracts = contracts
+}
+
+// getTransaction fetches a transaction with retries
+func (l *TransactionListener) getTransaction(ctx context.Context, txHash string) (*Transaction, error) {
+	var tx *Transaction
+	var err error
+
+	for i := 0; i < l.maxRetries; i++ {
+		tx, err = l.client.GetTransactionByID(ctx, txHash)
+		if err == nil {
+			return tx, nil
+		}
+
+		if i < l.maxRetries-1 {
+			time.Sleep(time.Duration(i+1) * time.Second)
+		}
+	}
+
+	return nil, err
+}
+
+// confirmedHead returns the newest block with the required number of confirmations
+func (l *TransactionListener) confirmedHead(currentBlock uint64) uint64 {
+	if currentBlock+1 < l.requiredConfirmations {
+		return 0
+	}
+	return currentBlock + 1 - l.requiredConfirmations
+}
+
+// GetWalletAddress returns the wallet address being monitored
+func (l *TransactionListener) GetWalletAddress() string {
+	return l.walletAddress.String()
+}
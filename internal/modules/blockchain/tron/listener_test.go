@@ -0,0 +1,292 @@
+package tron
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+)
+
+type confirmedPayment struct {
+	paymentID string
+	txHash    string
+	amount    decimal.Decimal
+	symbol    string
+}
+
+// memoryStore is an in-memory blockchainDomain.ListenerStore
+type memoryStore struct {
+	mu       sync.Mutex
+	cursor   *blockchainDomain.ListenerCursor
+	recorded map[string]blockchainDomain.ObservedTransfer
+	inbound  []inboundTransfer
+	rescans  []*blockchainDomain.RescanRequest
+	results  map[string]error
+}
+
+// inboundTransfer is a transfer recorded with RecordInboundTransfer
+type inboundTransfer struct {
+	transfer blockchainDomain.ObservedTransfer
+	matchErr error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		recorded: make(map[string]blockchainDomain.ObservedTransfer),
+		results:  make(map[string]error),
+	}
+}
+
+func (s *memoryStore) LoadCursor(ctx context.Context) (*blockchainDomain.ListenerCursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor, nil
+}
+
+func (s *memoryStore) SaveCursor(ctx context.Context, blockNumber uint64, signature string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = &blockchainDomain.ListenerCursor{
+		BlockNumber: int64(blockNumber),
+		Signature:   sql.NullString{String: signature, Valid: signature != ""},
+	}
+	return nil
+}
+
+func (s *memoryStore) IsTransactionRecorded(ctx context.Context, txHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.recorded[txHash]
+	return ok, nil
+}
+
+func (s *memoryStore) RecordTransaction(ctx context.Context, transfer blockchainDomain.ObservedTransfer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorded[transfer.TxHash] = transfer
+	return nil
+}
+
+func (s *memoryStore) RecordInboundTransfer(ctx context.Context, transfer blockchainDomain.ObservedTransfer, matchErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inbound = append(s.inbound, inboundTransfer{transfer, matchErr})
+	return nil
+}
+
+func (s *memoryStore) PendingRescans(ctx context.Context) ([]*blockchainDomain.RescanRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.rescans
+	s.rescans = nil
+	return pending, nil
+}
+
+func (s *memoryStore) CompleteRescan(ctx context.Context, id string, rescanErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[id] = rescanErr
+	return nil
+}
+
+func newTestListener(t *testing.T, rpcURL string, confirmed *[]confirmedPayment) *TransactionListener {
+	return newTestListenerWithStore(t, rpcURL, confirmed, nil)
+}
+
+func newTestListenerWithStore(t *testing.T, rpcURL string, confirmed *[]confirmedPayment, store blockchainDomain.ListenerStore) *TransactionListener {
+	client, err := NewClient(ClientConfig{RPCURL: rpcURL})
+	require.NoError(t, err)
+
+	contract, err := ParseAddress(testUSDTContract)
+	require.NoError(t, err)
+
+	listener, err := NewTransactionListener(ListenerConfig{
+		Client:        client,
+		WalletAddress: testWallet,
+		ConfirmationCallback: func(paymentID string, txHash string, amount decimal.Decimal, tokenSymbol string, fromAddress string) error {
+			*confirmed = append(*confirmed, confirmedPayment{paymentID, txHash, amount, tokenSymbol})
+			return nil
+		},
+		SupportedTokenContracts: map[string]TokenContractInfo{
+			"USDT": {ContractAddress: contract, Symbol: "USDT", Decimals: 6},
+		},
+		Store:                 store,
+		RequiredConfirmations: 19,
+		MaxRetries:            1,
+	})
+	require.NoError(t, err)
+
+	return listener
+}
+
+func TestNewTransactionListener_Validation(t *testing.T) {
+	client, err := NewClient(ClientConfig{RPCURL: "http://localhost"})
+	require.NoError(t, err)
+	callback := func(string, string, decimal.Decimal, string, string) error { return nil }
+
+	_, err = NewTransactionListener(ListenerConfig{WalletAddress: testWallet, ConfirmationCallback: callback})
+	assert.Error(t, err)
+
+	_, err = NewTransactionListener(ListenerConfig{Client: client, WalletAddress: "invalid", ConfirmationCallback: callback})
+	assert.Error(t, err)
+
+	_, err = NewTransactionListener(ListenerConfig{Client: client, WalletAddress: testWallet})
+	assert.Error(t, err)
+}
+
+func TestTransactionListener_ConfirmsTransfersAfterConfirmations(t *testing.T) {
+	node, server := newFakeNode(t, 110)
+
+	// USDT transfer to the wallet with a memo, plus a failed transfer and one to another address
+	node.blocks[100] = []*TransactionInfo{
+		{
+			ID:          "tx-paid",
+			BlockNumber: 100,
+			Log: []Log{
+				transferLog("a614f803b6fd780986a42c78ec9c7f77e6ded13c", "2222222222222222222222222222222222222222", "1111111111111111111111111111111111111111", 25500000),
+			},
+		},
+		{
+			ID:          "tx-failed",
+			BlockNumber: 100,
+			Result:      "FAILED",
+			Log: []Log{
+				transferLog("a614f803b6fd780986a42c78ec9c7f77e6ded13c", "2222222222222222222222222222222222222222", "1111111111111111111111111111111111111111", 1000000),
+			},
+		},
+		{
+			ID:          "tx-other",
+			BlockNumber: 100,
+			Log: []Log{
+				transferLog("a614f803b6fd780986a42c78ec9c7f77e6ded13c", "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222", 1000000),
+			},
+		},
+	}
+	node.transactions["tx-paid"] = &Transaction{TxID: "tx-paid", RawData: TransactionRawData{Data: "7061796d656e742d313233"}}
+
+	var confirmed []confirmedPayment
+	listener := newTestListener(t, server.URL, &confirmed)
+	listener.lastProcessedBlock = 95
+
+	// Block 100 has only 11 confirmations at height 110
+	listener.processNewBlocks()
+	assert.Empty(t, confirmed)
+	assert.Equal(t, uint64(92), listener.confirmedHead(110))
+	assert.Equal(t, uint64(95), listener.GetLastProcessedBlock())
+
+	node.mu.Lock()
+	node.blockNumber = 120
+	node.mu.Unlock()
+
+	listener.processNewBlocks()
+	require.Len(t, confirmed, 1)
+	assert.Equal(t, "payment-123", confirmed[0].paymentID)
+	assert.Equal(t, "tx-paid", confirmed[0].txHash)
+	assert.True(t, decimal.RequireFromString("25.5").Equal(confirmed[0].amount))
+	assert.Equal(t, "USDT", confirmed[0].symbol)
+	assert.Equal(t, uint64(102), listener.GetLastProcessedBlock())
+
+	// Reprocessing the same transaction does not confirm it twice
+	require.NoError(t, listener.handleTransaction(listener.ctx, node.blocks[100][0], false))
+	assert.Len(t, confirmed, 1)
+}
+
+func TestTransactionListener_ResumesFromCursor(t *testing.T) {
+	node, server := newFakeNode(t, 110)
+	store := newMemoryStore()
+
+	var confirmed []confirmedPayment
+	first := newTestListenerWithStore(t, server.URL, &confirmed, store)
+	require.NoError(t, first.initialize(context.Background()))
+	require.NotNil(t, store.cursor)
+	assert.Equal(t, int64(92), store.cursor.BlockNumber)
+
+	// The listener is down while a payment arrives and the chain moves past a full batch
+	node.mu.Lock()
+	node.blocks[100] = []*TransactionInfo{
+		{
+			ID:          "tx-paid",
+			BlockNumber: 100,
+			Log: []Log{
+				transferLog("a614f803b6fd780986a42c78ec9c7f77e6ded13c", "2222222222222222222222222222222222222222", "1111111111111111111111111111111111111111", 25500000),
+			},
+		},
+	}
+	node.transactions["tx-paid"] = &Transaction{TxID: "tx-paid", RawData: TransactionRawData{Data: "7061796d656e742d313233"}}
+	node.blockNumber = 300
+	node.mu.Unlock()
+
+	// A restarted listener backfills from the saved cursor instead of the chain head
+	second := newTestListenerWithStore(t, server.URL, &confirmed, store)
+	require.NoError(t, second.initialize(context.Background()))
+	assert.Equal(t, uint64(92), second.GetLastProcessedBlock())
+
+	second.processNewBlocks()
+	require.Len(t, confirmed, 1)
+	assert.Equal(t, "tx-paid", confirmed[0].txHash)
+	assert.Equal(t, uint64(282), second.GetLastProcessedBlock())
+	assert.Equal(t, int64(282), store.cursor.BlockNumber)
+
+	recorded, ok := store.recorded["tx-paid"]
+	require.True(t, ok)
+	assert.Equal(t, "payment-123", recorded.PaymentID)
+	assert.Equal(t, uint64(100), recorded.BlockNumber)
+
+	// Recorded transactions are skipped when the range is processed again
+	require.NoError(t, store.SaveCursor(context.Background(), 95, ""))
+	third := newTestListenerWithStore(t, server.URL, &confirmed, store)
+	require.NoError(t, third.initialize(context.Background()))
+	third.processNewBlocks()
+	assert.Len(t, confirmed, 1)
+
+	// A forced rescan confirms the transaction again, payment confirmation is idempotent
+	store.rescans = []*blockchainDomain.RescanRequest{
+		{ID: "rescan-tx", TxHash: sql.NullString{String: "tx-paid", Valid: true}},
+		{ID: "rescan-range", FromBlock: sql.NullInt64{Int64: 99, Valid: true}, ToBlock: sql.NullInt64{Int64: 101, Valid: true}},
+		{ID: "rescan-unconfirmed", FromBlock: sql.NullInt64{Int64: 280, Valid: true}, ToBlock: sql.NullInt64{Int64: 300, Valid: true}},
+	}
+	third.processRescans()
+	assert.Len(t, confirmed, 3)
+	assert.NoError(t, store.results["rescan-tx"])
+	assert.NoError(t, store.results["rescan-range"])
+	assert.Error(t, store.results["rescan-unconfirmed"])
+}
+
+func TestTransactionListener_RetriesBlockWhenTransactionCannotBeRead(t *testing.T) {
+	node, server := newFakeNode(t, 120)
+	node.blocks[100] = []*TransactionInfo{
+		{
+			ID:          "tx-paid",
+			BlockNumber: 100,
+			Log: []Log{
+				transferLog("a614f803b6fd780986a42c78ec9c7f77e6ded13c", "2222222222222222222222222222222222222222", "1111111111111111111111111111111111111111", 25500000),
+			},
+		},
+	}
+	node.transactions["tx-paid"] = &Transaction{TxID: "tx-paid", RawData: TransactionRawData{Data: "7061796d656e742d313233"}}
+	node.failures["tx-paid"] = 1
+	store := newMemoryStore()
+
+	var confirmed []confirmedPayment
+	listener := newTestListenerWithStore(t, server.URL, &confirmed, store)
+	listener.lastProcessedBlock = 95
+
+	// The node times out on the transaction, the cursor stops before its block
+	listener.processNewBlocks()
+	assert.Empty(t, confirmed)
+	assert.Empty(t, store.inbound)
+	assert.Equal(t, uint64(99), listener.GetLastProcessedBlock())
+	assert.Equal(t, int64(99), store.cursor.BlockNumber)
+
+	// The next poll reads the block again and confirms the payment
+	listener.processNewBlocks()
+	require.Len(t, confirmed, 1)
+	assert.Equal(t, "payment-123", confirmed[0].paymentID)
+	assert.Equal(t, uint64(102), listener.GetLastProcessedBlock())
+}
@@ -0,0 +1,124 @@
+package tron
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// TRC20TokenTransfer represents a parsed TRC-20 token transfer
+type TRC20TokenTransfer struct {
+	From          Address
+	To            Address
+	Amount        *big.Int
+	TokenContract Address
+}
+
+// transferEventTopic is keccak256("Transfer(address,address,uint256)"), shared with ERC-20
+const transferEventTopic = "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// ParseTransferFromLogs finds and parses the TRC-20 Transfer events of a token contract
+// This doesn't filter by recipient
+func ParseTransferFromLogs(logs []Log, tokenContract Address) ([]*TRC20TokenTransfer, error) {
+	transfers := []*TRC20TokenTransfer{}
+
+	for _, log := range logs {
+		// Check if this log is from the expected token contract
+		contract, err := addressFromHex(log.Address)
+		if err != nil || contract != tokenContract {
+			continue
+		}
+
+		// Topic[0]: event signature, Topic[1]: from, Topic[2]: to, Data: amount
+		if len(log.Topics) < 3 {
+			continue
+		}
+
+		if !strings.EqualFold(strings.TrimPrefix(log.Topics[0], "0x"), transferEventTopic) {
+			continue
+		}
+
+		from, err := addressFromHex(log.Topics[1])
+		if err != nil {
+			continue
+		}
+		to, err := addressFromHex(log.Topics[2])
+		if err != nil {
+			continue
+		}
+
+		data, err := hex.DecodeString(strings.TrimPrefix(log.Data, "0x"))
+		if err != nil || len(data) < 32 {
+			continue
+		}
+
+		transfers = append(transfers, &TRC20TokenTransfer{
+			From:          from,
+			To:            to,
+			Amount:        new(big.Int).SetBytes(data[:32]),
+			TokenContract: tokenContract,
+		})
+	}
+
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("no TRC-20 token transfers found from contract %s", tokenContract.String())
+	}
+
+	return transfers, nil
+}
+
+// parseTRC20TokenTransfer extracts the TRC-20 transfer to the expected recipient from a transaction
+func parseTRC20TokenTransfer(info *TransactionInfo, expectedRecipient Address, tokenContract Address) (*TRC20TokenTransfer, error) {
+	if info == nil {
+		return nil, fmt.Errorf("transaction info is nil")
+	}
+
+	transfers, err := ParseTransferFromLogs(info.Log, tokenContract)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range transfers {
+		if transfer.To == expectedRecipient {
+			return transfer, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no TRC-20 token transfer found to recipient %s from contract %s",
+		expectedRecipient.String(), tokenContract.String())
+}
+
+// extractMemoFromTransaction extracts the payment memo from a TRON transaction
+// Wallets store the memo as a hex encoded note in raw_data.data
+func extractMemoFromTransaction(tx *Transaction) (string, error) {
+	if tx == nil {
+		return "", fmt.Errorf("transaction is nil")
+	}
+
+	if tx.RawData.Data == "" {
+		return "", fmt.Errorf("no memo found in transaction")
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(tx.RawData.Data, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid memo encoding: %w", err)
+	}
+
+	memo := strings.TrimSpace(string(data))
+	if !isPrintable(memo) {
+		return "", fmt.Errorf("unable to decode memo")
+	}
+
+	return memo, nil
+}
+
+// isPrintable checks if a string contains only printable ASCII characters
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if r < 32 || r > 126 {
+			return false
+		}
+	}
+	return len(s) > 0
+}
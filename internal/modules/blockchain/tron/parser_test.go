@@ -0,0 +1,111 @@
+package tron
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUSDTContract = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t" // 41a614f803b6fd780986a42c78ec9c7f77e6ded13c
+	testWallet       = "TBXSw8fM4jpQkGc6zZjsVABFpVN7UvXPdV" // 411111111111111111111111111111111111111111
+	testSender       = "TD5gsCwxykWsLN9aPrq2TAfNjByuZKYp4E" // 412222222222222222222222222222222222222222
+)
+
+// transferLog builds a TRC-20 Transfer event log as returned by the node
+func transferLog(contractHex, fromHex, toHex string, amount int64) Log {
+	return Log{
+		Address: contractHex,
+		Topics: []string{
+			transferEventTopic,
+			"000000000000000000000000" + fromHex,
+			"000000000000000000000000" + toHex,
+		},
+		Data: padUint256(amount),
+	}
+}
+
+func padUint256(amount int64) string {
+	hexAmount := big.NewInt(amount).Text(16)
+	for len(hexAmount) < 64 {
+		hexAmount = "0" + hexAmount
+	}
+	return hexAmount
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantHex string
+		wantErr bool
+	}{
+		{name: "base58", input: testUSDTContract, wantHex: "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"},
+		{name: "41-prefixed hex", input: "41a614f803b6fd780986a42c78ec9c7f77e6ded13c", wantHex: "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"},
+		{name: "0x hex", input: "0xa614f803b6fd780986a42c78ec9c7f77e6ded13c", wantHex: "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"},
+		{name: "bad checksum", input: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", wantErr: true},
+		{name: "garbage", input: "not-an-address", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := ParseAddress(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHex, addr.Hex())
+			assert.Equal(t, testUSDTContract, addr.String())
+		})
+	}
+}
+
+func TestParseTransferFromLogs(t *testing.T) {
+	contract, err := ParseAddress(testUSDTContract)
+	require.NoError(t, err)
+	wallet, err := ParseAddress(testWallet)
+	require.NoError(t, err)
+	sender, err := ParseAddress(testSender)
+	require.NoError(t, err)
+
+	logs := []Log{
+		// Transfer from another contract is ignored
+		transferLog("3333333333333333333333333333333333333333", "2222222222222222222222222222222222222222", "1111111111111111111111111111111111111111", 1),
+		transferLog("a614f803b6fd780986a42c78ec9c7f77e6ded13c", "2222222222222222222222222222222222222222", "1111111111111111111111111111111111111111", 25500000),
+	}
+
+	transfers, err := ParseTransferFromLogs(logs, contract)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, sender, transfers[0].From)
+	assert.Equal(t, wallet, transfers[0].To)
+	assert.Equal(t, int64(25500000), transfers[0].Amount.Int64())
+
+	_, err = ParseTransferFromLogs(logs[:1], contract)
+	assert.Error(t, err)
+
+	transfer, err := parseTRC20TokenTransfer(&TransactionInfo{Log: logs}, wallet, contract)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25500000), transfer.Amount.Int64())
+
+	_, err = parseTRC20TokenTransfer(&TransactionInfo{Log: logs}, sender, contract)
+	assert.Error(t, err)
+}
+
+func TestExtractMemoFromTransaction(t *testing.T) {
+	memo, err := extractMemoFromTransaction(&Transaction{RawData: TransactionRawData{Data: "7061796d656e742d313233"}})
+	require.NoError(t, err)
+	assert.Equal(t, "payment-123", memo)
+
+	_, err = extractMemoFromTransaction(&Transaction{})
+	assert.Error(t, err)
+
+	_, err = extractMemoFromTransaction(&Transaction{RawData: TransactionRawData{Data: "00ff"}})
+	assert.Error(t, err)
+
+	_, err = extractMemoFromTransaction(nil)
+	assert.Error(t, err)
+}
@@ -62,6 +62,7 @@ const (
 	AlertTypeTravelRuleMissing  = "travel_rule_missing"
 	AlertTypeUnusualTransaction = "unusual_transaction"
 	AlertTypeLargeTransaction   = "large_transaction"
+	AlertTypePaymentReversed    = "payment_reversed" // A counted transfer was reorged out of the chain
 )
 
 // Severity constants
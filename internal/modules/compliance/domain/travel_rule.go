@@ -33,7 +33,7 @@ type TravelRuleData struct {
 
 	// Transaction Information
 	TransactionAmount   decimal.Decimal `json:"transaction_amount" db:"transaction_amount" validate:"required,gt=0"`
-	TransactionCurrency string          `json:"transaction_currency" db:"transaction_currency" validate:"required,oneof=USD USDT USDC BUSD"`
+	TransactionCurrency string          `json:"transaction_currency" db:"transaction_currency" validate:"required,alphanum,max=10"`
 	TransactionPurpose  sql.NullString  `json:"transaction_purpose,omitempty" db:"transaction_purpose" validate:"omitempty,oneof=payment_for_goods payment_for_services hotel_accommodation restaurant_payment tourism_service transport_service entertainment other"`
 
 	// Risk Assessment
@@ -57,7 +57,7 @@ type CreateTravelRuleDataRequest struct {
 
 	// Transaction Information
 	TransactionAmount   string  `json:"transaction_amount" binding:"required"` // Decimal as string
-	TransactionCurrency string  `json:"transaction_currency" binding:"required,oneof=USD USDT USDC BUSD"`
+	TransactionCurrency string  `json:"transaction_currency" binding:"required,alphanum,max=10"`
 	TransactionPurpose  *string `json:"transaction_purpose,omitempty"`
 }
 
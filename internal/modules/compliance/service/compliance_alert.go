@@ -17,7 +17,6 @@ import (
 type PaymentRepositoryInterface interface {
 	GetByID(id string) (*paymentDomain.Payment, error)
 	Update(payment *paymentDomain.Payment) error
-	UpdateStatus(id string, status paymentDomain.PaymentStatus) error
 }
 
 // ComplianceAlertService handles business logic for compliance alerts
@@ -217,6 +216,73 @@ func (s *ComplianceAlertService) CreateHighRiskAlert(
 	return alert, nil
 }
 
+// CreatePaymentReversalAlert creates an alert for a payment whose transfer disappeared from the chain
+// The merchant may already have been credited or paid out, so the alert is always critical
+func (s *ComplianceAlertService) CreatePaymentReversalAlert(
+	ctx context.Context,
+	paymentID uuid.UUID,
+	blockchain string,
+	txHash string,
+	reason string,
+) (*domain.ComplianceAlert, error) {
+	// Get payment details
+	payment, err := s.paymentRepo.GetByID(paymentID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// Create the alert
+	alert := domain.NewComplianceAlert(
+		domain.AlertTypePaymentReversed,
+		domain.SeverityCritical,
+		fmt.Sprintf("Payment reversed: transaction %s on %s blockchain is no longer on-chain (%s). Payment ID: %s", txHash, blockchain, reason, paymentID),
+	)
+
+	// Set alert details
+	alert.PaymentID = &paymentID
+	merchantID, err := uuid.Parse(payment.MerchantID)
+	if err == nil {
+		alert.MerchantID = &merchantID
+	}
+	if payment.FromAddress.Valid {
+		alert.FromAddress = &payment.FromAddress.String
+	}
+	alert.Blockchain = &blockchain
+	alert.TransactionHash = &txHash
+	alert.Evidence["reason"] = reason
+	alert.Evidence["amount_received"] = payment.AmountReceived.String()
+	alert.Evidence["currency"] = payment.Currency
+	alert.AddAction(domain.ActionPaymentReversed)
+
+	// Set recommended action
+	recommendedAction := "URGENT: Verify the transaction on a block explorer, hold merchant payouts and recover any settled funds"
+	alert.RecommendedAction = &recommendedAction
+
+	// Save the alert
+	if err := s.repo.Create(ctx, alert); err != nil {
+		return nil, fmt.Errorf("failed to create compliance alert: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"alert_id":   alert.ID,
+		"payment_id": paymentID,
+		"tx_hash":    txHash,
+		"blockchain": blockchain,
+	}).Warn("Payment reversal alert created")
+
+	// Flag the payment for review
+	if err := s.FlagPaymentForReview(ctx, alert, paymentID); err != nil {
+		s.logger.WithError(err).Error("Failed to flag payment for review")
+	}
+
+	// Send alert to compliance team
+	if err := s.SendAlertNotification(ctx, alert); err != nil {
+		s.logger.WithError(err).Error("Failed to send alert notification")
+	}
+
+	return alert, nil
+}
+
 // FlagPaymentForReview flags a payment for manual review and prevents automatic processing
 func (s *ComplianceAlertService) FlagPaymentForReview(ctx context.Context, alert *domain.ComplianceAlert, paymentID uuid.UUID) error {
 	// Get the payment
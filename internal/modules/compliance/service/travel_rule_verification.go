@@ -2,9 +2,6 @@ package service
 
 import (
 	"context"
-	"crypto/ed25519"
-	"encoding/base64"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -16,6 +13,7 @@ import (
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/domain"
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/repository"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/web3"
 )
 
 var (
@@ -32,7 +30,7 @@ var (
 // TravelRuleVerificationService handles 4-layer verification for unhosted wallets
 type TravelRuleVerificationService interface {
 	// Layer 1: Proof of Ownership
-	VerifySignature(ctx context.Context, walletAddress string, message string, signature string) (bool, error)
+	VerifySignature(ctx context.Context, chain string, walletAddress string, message string, signature string) (bool, error)
 	GenerateSignatureMessage(payerName string, walletAddress string, txID string) string
 
 	// Layer 2: Sanctions Screening
@@ -95,18 +93,22 @@ type VerificationSummary struct {
 type travelRuleVerificationServiceImpl struct {
 	travelRuleRepo repository.TravelRuleRepository
 	amlService     AMLService
+	nonceClaimer   web3.ChallengeNonceClaimer // Accepts each EVM challenge nonce once
 	logger         *logger.Logger
 }
 
 // NewTravelRuleVerificationService creates a new verification service
+// EVM signatures are rejected without a nonce claimer, their challenges could be replayed
 func NewTravelRuleVerificationService(
 	travelRuleRepo repository.TravelRuleRepository,
 	amlService AMLService,
+	nonceClaimer web3.ChallengeNonceClaimer,
 	logger *logger.Logger,
 ) TravelRuleVerificationService {
 	return &travelRuleVerificationServiceImpl{
 		travelRuleRepo: travelRuleRepo,
 		amlService:     amlService,
+		nonceClaimer:   nonceClaimer,
 		logger:         logger,
 	}
 }
@@ -131,43 +133,43 @@ func (s *travelRuleVerificationServiceImpl) GenerateSignatureMessage(payerName s
 }
 
 // VerifySignature verifies the cryptographic signature (Layer 1)
-// For Solana/Phantom wallets, this uses Ed25519 signature verification
-func (s *travelRuleVerificationServiceImpl) VerifySignature(ctx context.Context, walletAddress string, message string, signature string) (bool, error) {
+// Solana wallets sign with Ed25519, EVM wallets sign an EIP-191 or EIP-712 challenge with an issued nonce and expiry
+func (s *travelRuleVerificationServiceImpl) VerifySignature(ctx context.Context, chain string, walletAddress string, message string, signature string) (bool, error) {
 	s.logger.Info("Verifying signature for wallet", map[string]interface{}{
 		"wallet_address": walletAddress,
+		"chain":          chain,
 	})
 
-	// Decode the signature (base64 or hex)
-	sigBytes, err := base64.StdEncoding.DecodeString(signature)
+	verified, err := web3.VerifyOwnership(chain, walletAddress, message, signature)
 	if err != nil {
-		// Try hex decoding
-		sigBytes, err = hex.DecodeString(signature)
-		if err != nil {
-			return false, fmt.Errorf("failed to decode signature: %w", err)
-		}
+		s.logger.Warn("Signature verification failed", map[string]interface{}{
+			"wallet_address": walletAddress,
+			"chain":          chain,
+			"error":          err.Error(),
+		})
+		return false, fmt.Errorf("%w: %v", ErrSignatureVerificationFailed, err)
 	}
-
-	// For Solana: wallet address is base58-encoded public key
-	// For production, use: github.com/mr-tron/base58 to decode
-	// For this example, we'll simulate verification
-
-	// CRITICAL: In production, implement actual Ed25519 verification:
-	// pubKey := base58.Decode(walletAddress)
-	// messageBytes := []byte(message)
-	// verified := ed25519.Verify(pubKey, messageBytes, sigBytes)
-
-	// Placeholder verification (REPLACE in production)
-	verified := len(sigBytes) == ed25519.SignatureSize
-
 	if !verified {
 		s.logger.Warn("Signature verification failed", map[string]interface{}{
 			"wallet_address": walletAddress,
+			"chain":          chain,
 		})
 		return false, ErrSignatureVerificationFailed
 	}
 
+	// The nonce of an EVM challenge is accepted once, a replayed signature is rejected
+	if err := web3.ClaimChallengeNonce(s.nonceClaimer, chain, walletAddress, message); err != nil {
+		s.logger.Warn("Signature challenge rejected", map[string]interface{}{
+			"wallet_address": walletAddress,
+			"chain":          chain,
+			"error":          err.Error(),
+		})
+		return false, fmt.Errorf("%w: %v", ErrSignatureVerificationFailed, err)
+	}
+
 	s.logger.Info("Signature verified successfully", map[string]interface{}{
 		"wallet_address": walletAddress,
+		"chain":          chain,
 	})
 	return true, nil
 }
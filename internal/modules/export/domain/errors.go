@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrExportNotFound is returned when an export is not found or belongs to another merchant
+	ErrExportNotFound = errors.New("export not found")
+	// ErrInvalidExport is returned when the type, format, date range or filters of an export are invalid
+	ErrInvalidExport = errors.New("invalid export")
+	// ErrExportTooLarge is returned when an export has more rows than a file can hold
+	ErrExportTooLarge = errors.New("export has too many rows")
+	// ErrExportsNotConfigured is returned when this service cannot queue exports for the worker
+	ErrExportsNotConfigured = errors.New("exports not configured")
+)
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	ledgerdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/domain"
+	paymentdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	payoutdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/domain"
+)
+
+// ExportType is the kind of records an export contains
+type ExportType string
+
+const (
+	ExportTypePayments      ExportType = "payments"
+	ExportTypePayouts       ExportType = "payouts"
+	ExportTypeLedgerEntries ExportType = "ledger_entries"
+)
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+// ContentType returns the MIME type of the export file
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// ExportStatus represents the status of an export generated by the worker
+type ExportStatus string
+
+const (
+	ExportStatusPending    ExportStatus = "pending"    // Queued for the worker
+	ExportStatusProcessing ExportStatus = "processing" // The worker is writing the file
+	ExportStatusCompleted  ExportStatus = "completed"  // The file is stored and can be downloaded
+	ExportStatusFailed     ExportStatus = "failed"
+)
+
+// Export webhook events
+const (
+	ExportEventCompleted = "export.completed"
+	ExportEventFailed    = "export.failed"
+)
+
+const (
+	// MaxExportRange is the longest date range of an export
+	MaxExportRange = 366 * 24 * time.Hour
+	// MaxExportRows is the maximum number of records of an export, an XLSX sheet holds 1,048,576 rows
+	MaxExportRows = 1000000
+	// DefaultDownloadURLExpiry is how long a download URL of an export file stays valid
+	DefaultDownloadURLExpiry = 24 * time.Hour
+)
+
+// ExportFilter selects the records of an export, created in [From, To)
+type ExportFilter struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Statuses []string  `json:"statuses,omitempty"` // Payments and payouts
+	Chain    string    `json:"chain,omitempty"`    // Payments
+	Currency string    `json:"currency,omitempty"` // Payments and ledger entries
+}
+
+// Value implements the driver.Valuer interface for database writes
+func (f ExportFilter) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (f *ExportFilter) Scan(value interface{}) error {
+	if value == nil {
+		*f = ExportFilter{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan export filter: not a byte slice")
+	}
+
+	return json.Unmarshal(bytes, f)
+}
+
+// Export is a file of the payments, payouts or ledger entries of a merchant, written by the worker
+type Export struct {
+	ID         string       `json:"id" db:"id"`
+	MerchantID string       `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+	Type       ExportType   `json:"type" db:"type" validate:"required,oneof=payments payouts ledger_entries"`
+	Format     ExportFormat `json:"format" db:"format" validate:"required,oneof=csv xlsx"`
+	Status     ExportStatus `json:"status" db:"status" validate:"required,oneof=pending processing completed failed"`
+	Filter     ExportFilter `json:"filter" db:"filter"`
+
+	// Set once completed
+	FileKey  sql.NullString `json:"file_key,omitempty" db:"file_key"`
+	FileSize int64          `json:"file_size" db:"file_size"`
+	RowCount int            `json:"row_count" db:"row_count"`
+
+	Error sql.NullString `json:"error,omitempty" db:"error"`
+
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+	CompletedAt sql.NullTime `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+func (Export) TableName() string {
+	return "exports"
+}
+
+// IsFinished returns true once the export completed or failed
+func (e *Export) IsFinished() bool {
+	return e.Status == ExportStatusCompleted || e.Status == ExportStatusFailed
+}
+
+// FileName returns the name the export file is downloaded as, e.g. payments_2026-01-01_2026-02-01.csv
+func (e *Export) FileName() string {
+	return fmt.Sprintf("%s_%s_%s.%s", e.Type, e.Filter.From.Format("2006-01-02"), e.Filter.To.Format("2006-01-02"), e.Format)
+}
+
+// Validate checks the type, format, date range and filters of an export
+func (e *Export) Validate() error {
+	switch e.Type {
+	case ExportTypePayments, ExportTypePayouts, ExportTypeLedgerEntries:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidExport, e.Type)
+	}
+	if e.Format != ExportFormatCSV && e.Format != ExportFormatXLSX {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidExport, e.Format)
+	}
+
+	filter := e.Filter
+	if filter.From.IsZero() || filter.To.IsZero() || !filter.From.Before(filter.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidExport)
+	}
+	if filter.To.Sub(filter.From) > MaxExportRange {
+		return fmt.Errorf("%w: the date range is at most %d days", ErrInvalidExport, int(MaxExportRange.Hours()/24))
+	}
+
+	for _, status := range filter.Statuses {
+		valid := false
+		switch e.Type {
+		case ExportTypePayments:
+			valid = paymentdomain.PaymentStatus(status).IsValid()
+		case ExportTypePayouts:
+			valid = payoutdomain.PayoutStatus(status).IsValid()
+		}
+		if !valid {
+			return fmt.Errorf("%w: unknown %s status %q", ErrInvalidExport, e.Type, status)
+		}
+	}
+	if filter.Chain != "" && e.Type != ExportTypePayments {
+		return fmt.Errorf("%w: chain only filters payments", ErrInvalidExport)
+	}
+	if filter.Currency != "" && e.Type == ExportTypePayouts {
+		return fmt.Errorf("%w: payouts are always in VND", ErrInvalidExport)
+	}
+
+	return nil
+}
+
+// ExportRepository defines the interface for export data access
+type ExportRepository interface {
+	Create(export *Export) error
+	GetByID(id string) (*Export, error)
+	Update(export *Export) error
+	ListByMerchant(merchantID string, limit, offset int) ([]*Export, error)
+}
+
+// PaymentSource reads the payments of an export, implemented by the payment repository
+type PaymentSource interface {
+	Search(search paymentdomain.PaymentSearch) (*paymentdomain.PaymentPage, error)
+}
+
+// PayoutSource reads the payouts of an export, implemented by the payout repository
+type PayoutSource interface {
+	ListByMerchantCreatedBetween(merchantID string, from, to time.Time, statuses []payoutdomain.PayoutStatus, limit, offset int) ([]*payoutdomain.Payout, error)
+}
+
+// LedgerSource reads the ledger entries of an export, implemented by the ledger repository
+type LedgerSource interface {
+	GetByMerchantCreatedBetween(merchantID string, from, to time.Time, currency string, limit, offset int) ([]*ledgerdomain.LedgerEntry, error)
+}
+
+// ExportQueue hands exports over to the worker
+type ExportQueue interface {
+	EnqueueExport(ctx context.Context, exportID string) error
+}
+
+// WebhookPublisher delivers export webhooks to merchants
+type WebhookPublisher interface {
+	PublishWebhook(ctx context.Context, merchantID, event string, data map[string]interface{}) error
+}
+
+// MerchantReader looks up the email address export notifications are sent to
+type MerchantReader interface {
+	GetMerchantEmail(merchantID string) (string, error)
+}
+
+// EmailSender emails merchants the download link of their export
+type EmailSender interface {
+	SendExportReadyEmail(ctx context.Context, merchantEmail, exportID, fileName, downloadURL string) error
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport_Validate(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		export Export
+		valid  bool
+	}{
+		{"payments", Export{Type: ExportTypePayments, Format: ExportFormatCSV, Filter: ExportFilter{From: from, To: to, Statuses: []string{"completed"}, Chain: "solana", Currency: "USDT"}}, true},
+		{"payouts", Export{Type: ExportTypePayouts, Format: ExportFormatXLSX, Filter: ExportFilter{From: from, To: to, Statuses: []string{"completed", "rejected"}}}, true},
+		{"ledger entries", Export{Type: ExportTypeLedgerEntries, Format: ExportFormatXLSX, Filter: ExportFilter{From: from, To: to, Currency: "VND"}}, true},
+		{"unknown type", Export{Type: "refunds", Format: ExportFormatCSV, Filter: ExportFilter{From: from, To: to}}, false},
+		{"unknown format", Export{Type: ExportTypePayments, Format: "pdf", Filter: ExportFilter{From: from, To: to}}, false},
+		{"missing range", Export{Type: ExportTypePayments, Format: ExportFormatCSV}, false},
+		{"reversed range", Export{Type: ExportTypePayments, Format: ExportFormatCSV, Filter: ExportFilter{From: to, To: from}}, false},
+		{"range too long", Export{Type: ExportTypePayments, Format: ExportFormatCSV, Filter: ExportFilter{From: from, To: from.AddDate(2, 0, 0)}}, false},
+		{"payout status on payments", Export{Type: ExportTypePayments, Format: ExportFormatCSV, Filter: ExportFilter{From: from, To: to, Statuses: []string{"rejected"}}}, false},
+		{"status on ledger entries", Export{Type: ExportTypeLedgerEntries, Format: ExportFormatCSV, Filter: ExportFilter{From: from, To: to, Statuses: []string{"completed"}}}, false},
+		{"chain on payouts", Export{Type: ExportTypePayouts, Format: ExportFormatCSV, Filter: ExportFilter{From: from, To: to, Chain: "bsc"}}, false},
+		{"currency on payouts", Export{Type: ExportTypePayouts, Format: ExportFormatCSV, Filter: ExportFilter{From: from, To: to, Currency: "USDT"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.export.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidExport)
+			}
+		})
+	}
+}
+
+func TestExport_FileName(t *testing.T) {
+	export := &Export{
+		Type:   ExportTypeLedgerEntries,
+		Format: ExportFormatXLSX,
+		Filter: ExportFilter{
+			From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	assert.Equal(t, "ledger_entries_2026-01-01_2026-02-01.xlsx", export.FileName())
+	assert.Equal(t, "text/csv", ExportFormatCSV.ContentType())
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/domain"
+)
+
+// CreateExportRequest represents the request body for exporting records to a file
+type CreateExportRequest struct {
+	Type     string    `json:"type" binding:"required,oneof=payments payouts ledger_entries" example:"payments"`
+	Format   string    `json:"format" binding:"required,oneof=csv xlsx" example:"xlsx"`
+	From     time.Time `json:"from" binding:"required" example:"2026-01-01T00:00:00+07:00"`
+	To       time.Time `json:"to" binding:"required" example:"2026-02-01T00:00:00+07:00"`
+	Statuses []string  `json:"statuses,omitempty" binding:"omitempty,max=12" example:"completed"`
+	Chain    string    `json:"chain,omitempty" binding:"omitempty,max=20" example:"solana"`
+	Currency string    `json:"currency,omitempty" binding:"omitempty,max=10" example:"USDT"`
+}
+
+// ToFilter converts the request to the records filter of an export
+func (r *CreateExportRequest) ToFilter() domain.ExportFilter {
+	return domain.ExportFilter{
+		From:     r.From,
+		To:       r.To,
+		Statuses: r.Statuses,
+		Chain:    r.Chain,
+		Currency: r.Currency,
+	}
+}
+
+// ListExportsRequest represents the query parameters for listing exports
+type ListExportsRequest struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ExportResponse represents an export, with a download URL once completed
+type ExportResponse struct {
+	ID       string              `json:"id"`
+	Type     string              `json:"type"`
+	Format   string              `json:"format"`
+	Status   string              `json:"status"`
+	Filter   domain.ExportFilter `json:"filter"`
+	FileName string              `json:"file_name"`
+	RowCount int                 `json:"row_count"`
+	FileSize int64               `json:"file_size"`
+	Error    *string             `json:"error,omitempty"`
+
+	DownloadURL          *string    `json:"download_url,omitempty"`
+	DownloadURLExpiresAt *time.Time `json:"download_url_expires_at,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ExportToResponse converts an export to an ExportResponse without download URL
+func ExportToResponse(export *domain.Export) ExportResponse {
+	response := ExportResponse{
+		ID:        export.ID,
+		Type:      string(export.Type),
+		Format:    string(export.Format),
+		Status:    string(export.Status),
+		Filter:    export.Filter,
+		FileName:  export.FileName(),
+		RowCount:  export.RowCount,
+		FileSize:  export.FileSize,
+		CreatedAt: export.CreatedAt,
+	}
+	if export.Error.Valid {
+		response.Error = &export.Error.String
+	}
+	if export.CompletedAt.Valid {
+		response.CompletedAt = &export.CompletedAt.Time
+	}
+	return response
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/service"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// ExportHandler handles HTTP requests for merchant exports
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// CreateExport handles POST /api/v1/exports
+// @Summary Export records to a file
+// @Description Export the payments, payouts or ledger entries created in a date range to CSV or XLSX. The file is written in the background; an export.completed webhook and an email deliver a time-limited download URL.
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param request body CreateExportRequest true "Export request"
+// @Success 202 {object} APIResponse{data=ExportResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/exports [post]
+// @Security ApiKeyAuth
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	export, err := h.exportService.RequestExport(ctx, merchant.ID, domain.ExportType(req.Type), domain.ExportFormat(req.Format), req.ToFilter())
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to request export")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse(ExportToResponse(export)))
+}
+
+// GetExport handles GET /api/v1/exports/:id
+// @Summary Get an export
+// @Description Retrieve the status of an export and, once completed, a new time-limited download URL
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} APIResponse{data=ExportResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/exports/{id} [get]
+// @Security ApiKeyAuth
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	export, err := h.exportService.GetExport(ctx, c.Param("id"), merchant.ID)
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	response := ExportToResponse(export)
+	if export.Status == domain.ExportStatusCompleted {
+		url, expiresAt, err := h.exportService.DownloadURL(ctx, export)
+		if err != nil {
+			logger.WithContext(ctx).WithFields(logrus.Fields{
+				"error":     err.Error(),
+				"export_id": export.ID,
+			}).Error("Failed to get export download URL")
+
+			c.JSON(http.StatusInternalServerError, ErrorResponse("INTERNAL_ERROR", "Failed to get the download URL"))
+			return
+		}
+		response.DownloadURL = &url
+		response.DownloadURLExpiresAt = &expiresAt
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(response))
+}
+
+// ListExports handles GET /api/v1/exports
+// @Summary List exports
+// @Description List the exports of the authenticated merchant, newest first. Get an export for its download URL.
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param limit query int false "Number of exports (max 100)" default(20)
+// @Param offset query int false "Number of exports to skip" default(0)
+// @Success 200 {object} APIResponse{data=[]ExportResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/exports [get]
+// @Security ApiKeyAuth
+func (h *ExportHandler) ListExports(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req ListExportsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	exports, err := h.exportService.ListExports(ctx, merchant.ID, req.Limit, req.Offset)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to list exports")
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse("INTERNAL_ERROR", "Failed to list exports"))
+		return
+	}
+
+	response := make([]ExportResponse, len(exports))
+	for i, export := range exports {
+		response[i] = ExportToResponse(export)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(response))
+}
+
+// mapServiceError maps export service errors to HTTP status codes and error messages
+func (h *ExportHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrExportNotFound):
+		return http.StatusNotFound, "EXPORT_NOT_FOUND", "Export not found"
+	case errors.Is(err, domain.ErrInvalidExport):
+		return http.StatusBadRequest, "INVALID_EXPORT", err.Error()
+	case errors.Is(err, domain.ErrExportsNotConfigured):
+		return http.StatusServiceUnavailable, "EXPORTS_UNAVAILABLE", "Exports are not available"
+	default:
+		return http.StatusInternalServerError, "INTERNAL_ERROR", "An internal error occurred"
+	}
+}
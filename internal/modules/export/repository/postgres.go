@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/domain"
+)
+
+type PostgresExportRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresExportRepository(db *gorm.DB) *PostgresExportRepository {
+	return &PostgresExportRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresExportRepository) Create(export *domain.Export) error {
+	if export == nil {
+		return errors.New("export cannot be nil")
+	}
+
+	if export.ID == "" {
+		export.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if export.CreatedAt.IsZero() {
+		export.CreatedAt = now
+	}
+	if export.UpdatedAt.IsZero() {
+		export.UpdatedAt = now
+	}
+
+	return r.db.Create(export).Error
+}
+
+func (r *PostgresExportRepository) GetByID(id string) (*domain.Export, error) {
+	if id == "" {
+		return nil, domain.ErrExportNotFound
+	}
+
+	export := &domain.Export{}
+	if err := r.db.Where("id = ?", id).First(export).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrExportNotFound
+		}
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (r *PostgresExportRepository) Update(export *domain.Export) error {
+	if export == nil {
+		return errors.New("export cannot be nil")
+	}
+	if export.ID == "" {
+		return domain.ErrExportNotFound
+	}
+
+	export.UpdatedAt = time.Now()
+
+	// The request (type, format, filter) never changes
+	result := r.db.Model(&domain.Export{}).
+		Where("id = ?", export.ID).
+		Select("status", "file_key", "file_size", "row_count", "error", "completed_at", "updated_at").
+		Updates(export)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrExportNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresExportRepository) ListByMerchant(merchantID string, limit, offset int) ([]*domain.Export, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var exports []*domain.Export
+	if err := r.db.Where("merchant_id = ?", merchantID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+
+	return exports, nil
+}
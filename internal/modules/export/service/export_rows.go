@@ -0,0 +1,166 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/domain"
+	paymentdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	payoutdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/domain"
+)
+
+// exportPageSize is how many records are read from the database at a time
+const exportPageSize = 1000
+
+var paymentExportHeader = []string{
+	"Payment ID", "Order ID", "Status", "Chain", "Currency", "Amount (VND)", "Amount (Crypto)",
+	"Amount Received", "Exchange Rate", "Fee (VND)", "Net Amount (VND)", "TX Hash", "From Address",
+	"Created At", "Confirmed At",
+}
+
+var payoutExportHeader = []string{
+	"Payout ID", "Status", "Amount (VND)", "Fee (VND)", "Net Amount (VND)", "Bank Name",
+	"Bank Account Name", "Bank Account Number", "Bank Reference", "Created At", "Completed At",
+}
+
+var ledgerExportHeader = []string{
+	"Entry ID", "Created At", "Transaction Group", "Entry Type", "Debit Account", "Credit Account",
+	"Amount", "Currency", "Reference Type", "Reference ID", "Description",
+}
+
+// exportHeader returns the column titles of an export type
+func exportHeader(exportType domain.ExportType) []string {
+	switch exportType {
+	case domain.ExportTypePayouts:
+		return payoutExportHeader
+	case domain.ExportTypeLedgerEntries:
+		return ledgerExportHeader
+	default:
+		return paymentExportHeader
+	}
+}
+
+// writeRows writes every record of the export and returns how many there were
+func (s *ExportService) writeRows(export *domain.Export, writer exportWriter) (int, error) {
+	switch export.Type {
+	case domain.ExportTypePayouts:
+		return s.writePayoutRows(export, writer)
+	case domain.ExportTypeLedgerEntries:
+		return s.writeLedgerRows(export, writer)
+	default:
+		return s.writePaymentRows(export, writer)
+	}
+}
+
+// writePaymentRows pages through the payments with the payment search cursor, oldest first
+func (s *ExportService) writePaymentRows(export *domain.Export, writer exportWriter) (int, error) {
+	statuses := make([]paymentdomain.PaymentStatus, len(export.Filter.Statuses))
+	for i, status := range export.Filter.Statuses {
+		statuses[i] = paymentdomain.PaymentStatus(status)
+	}
+
+	search := paymentdomain.PaymentSearch{
+		Filter: paymentdomain.PaymentFilter{
+			MerchantID:  export.MerchantID,
+			Statuses:    statuses,
+			Chain:       paymentdomain.Chain(export.Filter.Chain),
+			Currency:    export.Filter.Currency,
+			CreatedFrom: export.Filter.From,
+			CreatedTo:   export.Filter.To,
+		},
+		SortBy: paymentdomain.PaymentSortCreatedAt,
+		Limit:  exportPageSize,
+	}
+
+	count := 0
+	for {
+		page, err := s.paymentSource.Search(search)
+		if err != nil {
+			return count, err
+		}
+		for _, p := range page.Payments {
+			if count++; count > domain.MaxExportRows {
+				return count, domain.ErrExportTooLarge
+			}
+			if err := writer.WriteRow([]interface{}{
+				p.ID, nullString(p.OrderID), string(p.Status), string(p.Chain), p.Currency, p.AmountVND, p.AmountCrypto,
+				p.AmountReceived, p.ExchangeRate, p.FeeVND, p.NetAmountVND, nullString(p.TxHash), nullString(p.FromAddress),
+				p.CreatedAt, nullTime(p.ConfirmedAt),
+			}); err != nil {
+				return count, err
+			}
+		}
+		if page.NextCursor == "" {
+			return count, nil
+		}
+		search.Cursor = page.NextCursor
+	}
+}
+
+// writePayoutRows pages through the payouts, oldest first
+func (s *ExportService) writePayoutRows(export *domain.Export, writer exportWriter) (int, error) {
+	statuses := make([]payoutdomain.PayoutStatus, len(export.Filter.Statuses))
+	for i, status := range export.Filter.Statuses {
+		statuses[i] = payoutdomain.PayoutStatus(status)
+	}
+
+	count := 0
+	for offset := 0; ; offset += exportPageSize {
+		payouts, err := s.payoutSource.ListByMerchantCreatedBetween(export.MerchantID, export.Filter.From, export.Filter.To, statuses, exportPageSize, offset)
+		if err != nil {
+			return count, err
+		}
+		for _, p := range payouts {
+			if count++; count > domain.MaxExportRows {
+				return count, domain.ErrExportTooLarge
+			}
+			if err := writer.WriteRow([]interface{}{
+				p.ID, string(p.Status), p.AmountVND, p.FeeVND, p.NetAmountVND, p.BankName,
+				p.BankAccountName, p.BankAccountNumber, nullString(p.BankReferenceNumber), p.CreatedAt, nullTime(p.CompletionDate),
+			}); err != nil {
+				return count, err
+			}
+		}
+		if len(payouts) < exportPageSize {
+			return count, nil
+		}
+	}
+}
+
+// writeLedgerRows pages through the ledger entries, oldest first
+func (s *ExportService) writeLedgerRows(export *domain.Export, writer exportWriter) (int, error) {
+	count := 0
+	for offset := 0; ; offset += exportPageSize {
+		entries, err := s.ledgerSource.GetByMerchantCreatedBetween(export.MerchantID, export.Filter.From, export.Filter.To, export.Filter.Currency, exportPageSize, offset)
+		if err != nil {
+			return count, err
+		}
+		for _, e := range entries {
+			if count++; count > domain.MaxExportRows {
+				return count, domain.ErrExportTooLarge
+			}
+			if err := writer.WriteRow([]interface{}{
+				e.ID, e.CreatedAt, e.TransactionGroup, string(e.EntryType), e.DebitAccount, e.CreditAccount,
+				e.Amount, e.Currency, string(e.ReferenceType), e.ReferenceID, e.Description,
+			}); err != nil {
+				return count, err
+			}
+		}
+		if len(entries) < exportPageSize {
+			return count, nil
+		}
+	}
+}
+
+func nullString(value sql.NullString) interface{} {
+	if !value.Valid {
+		return nil
+	}
+	return value.String
+}
+
+func nullTime(value sql.NullTime) interface{} {
+	if !value.Valid {
+		return nil
+	}
+	return value.Time
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/storage"
+)
+
+// exportFailedMessage is shown to merchants when an export fails for an internal reason
+const exportFailedMessage = "the export could not be generated, please request it again"
+
+// ExportService exports the payments, payouts and ledger entries of merchants to CSV and XLSX files
+// Files are written by the worker, stored through the storage service and downloaded with a time-limited URL
+type ExportService struct {
+	exportRepo        domain.ExportRepository
+	paymentSource     domain.PaymentSource
+	payoutSource      domain.PayoutSource
+	ledgerSource      domain.LedgerSource
+	storage           storage.StorageService
+	bucket            string
+	exportQueue       domain.ExportQueue
+	webhookPublisher  domain.WebhookPublisher
+	merchantReader    domain.MerchantReader
+	emailSender       domain.EmailSender
+	downloadURLExpiry time.Duration
+	logger            *logrus.Logger
+}
+
+// ExportServiceConfig contains optional dependencies for ExportService
+type ExportServiceConfig struct {
+	ExportQueue       domain.ExportQueue      // Optional: required to request exports
+	WebhookPublisher  domain.WebhookPublisher // Optional: for export.completed and export.failed webhooks
+	MerchantReader    domain.MerchantReader   // Optional: with EmailSender, emails the download link
+	EmailSender       domain.EmailSender
+	DownloadURLExpiry time.Duration // Optional: defaults to domain.DefaultDownloadURLExpiry
+}
+
+// NewExportService creates a new export service
+func NewExportService(
+	exportRepo domain.ExportRepository,
+	paymentSource domain.PaymentSource,
+	payoutSource domain.PayoutSource,
+	ledgerSource domain.LedgerSource,
+	storageService storage.StorageService,
+	bucket string,
+	config ExportServiceConfig,
+	logger *logrus.Logger,
+) *ExportService {
+	downloadURLExpiry := config.DownloadURLExpiry
+	if downloadURLExpiry <= 0 {
+		downloadURLExpiry = domain.DefaultDownloadURLExpiry
+	}
+
+	return &ExportService{
+		exportRepo:        exportRepo,
+		paymentSource:     paymentSource,
+		payoutSource:      payoutSource,
+		ledgerSource:      ledgerSource,
+		storage:           storageService,
+		bucket:            bucket,
+		exportQueue:       config.ExportQueue,
+		webhookPublisher:  config.WebhookPublisher,
+		merchantReader:    config.MerchantReader,
+		emailSender:       config.EmailSender,
+		downloadURLExpiry: downloadURLExpiry,
+		logger:            logger,
+	}
+}
+
+// RequestExport validates an export and queues it for the worker, it is returned while still pending
+func (s *ExportService) RequestExport(ctx context.Context, merchantID string, exportType domain.ExportType, format domain.ExportFormat, filter domain.ExportFilter) (*domain.Export, error) {
+	if s.exportQueue == nil {
+		return nil, domain.ErrExportsNotConfigured
+	}
+
+	now := time.Now()
+	export := &domain.Export{
+		ID:         uuid.New().String(),
+		MerchantID: merchantID,
+		Type:       exportType,
+		Format:     format,
+		Status:     domain.ExportStatusPending,
+		Filter:     filter,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := export.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	if err := s.exportQueue.EnqueueExport(ctx, export.ID); err != nil {
+		return nil, fmt.Errorf("failed to enqueue export: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"export_id":   export.ID,
+		"merchant_id": merchantID,
+		"type":        exportType,
+		"format":      format,
+	}).Info("Export requested")
+
+	return export, nil
+}
+
+// GetExport returns an export of the merchant
+func (s *ExportService) GetExport(ctx context.Context, exportID, merchantID string) (*domain.Export, error) {
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not reveal exports of other merchants
+	if export.MerchantID != merchantID {
+		return nil, domain.ErrExportNotFound
+	}
+
+	return export, nil
+}
+
+// ListExports lists the exports of a merchant, newest first
+func (s *ExportService) ListExports(ctx context.Context, merchantID string, limit, offset int) ([]*domain.Export, error) {
+	exports, err := s.exportRepo.ListByMerchant(merchantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	return exports, nil
+}
+
+// DownloadURL returns a new download URL of a completed export and when it expires
+func (s *ExportService) DownloadURL(ctx context.Context, export *domain.Export) (string, time.Time, error) {
+	if export.Status != domain.ExportStatusCompleted || !export.FileKey.Valid {
+		return "", time.Time{}, fmt.Errorf("export %s is %s", export.ID, export.Status)
+	}
+
+	expiresAt := time.Now().Add(s.downloadURLExpiry)
+	url, err := s.storage.GetDownloadURL(ctx, s.bucket, export.FileKey.String, s.downloadURLExpiry)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get export download URL: %w", err)
+	}
+
+	return url, expiresAt, nil
+}
+
+// ProcessExport writes the file of an export, stores it and notifies the merchant
+// Errors are returned for the worker to retry, except an export too large to ever succeed which fails
+func (s *ExportService) ProcessExport(ctx context.Context, exportID string) error {
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		return fmt.Errorf("failed to get export: %w", err)
+	}
+	if export.IsFinished() {
+		return nil
+	}
+
+	if export.Status == domain.ExportStatusPending {
+		export.Status = domain.ExportStatusProcessing
+		if err := s.exportRepo.Update(export); err != nil {
+			return fmt.Errorf("failed to update export: %w", err)
+		}
+	}
+
+	rowCount, fileSize, err := s.writeFile(ctx, export)
+	if errors.Is(err, domain.ErrExportTooLarge) {
+		return s.failExport(ctx, export, fmt.Sprintf("%s: more than %d, choose a shorter date range", err, domain.MaxExportRows))
+	}
+	if err != nil {
+		return err
+	}
+
+	export.Status = domain.ExportStatusCompleted
+	export.RowCount = rowCount
+	export.FileSize = fileSize
+	export.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.exportRepo.Update(export); err != nil {
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"export_id":   export.ID,
+		"merchant_id": export.MerchantID,
+		"rows":        rowCount,
+		"size":        fileSize,
+	}).Info("Export completed")
+
+	s.notifyCompleted(ctx, export)
+
+	return nil
+}
+
+// FailExport marks an export failed once the worker gave up retrying it
+func (s *ExportService) FailExport(ctx context.Context, exportID string) error {
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		return fmt.Errorf("failed to get export: %w", err)
+	}
+	if export.IsFinished() {
+		return nil
+	}
+
+	return s.failExport(ctx, export, exportFailedMessage)
+}
+
+func (s *ExportService) failExport(ctx context.Context, export *domain.Export, reason string) error {
+	export.Status = domain.ExportStatusFailed
+	export.Error = sql.NullString{String: reason, Valid: true}
+	export.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.exportRepo.Update(export); err != nil {
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"export_id":   export.ID,
+		"merchant_id": export.MerchantID,
+		"reason":      reason,
+	}).Warn("Export failed")
+
+	if s.webhookPublisher != nil {
+		if err := s.webhookPublisher.PublishWebhook(ctx, export.MerchantID, domain.ExportEventFailed, map[string]interface{}{
+			"export_id": export.ID,
+			"type":      export.Type,
+			"format":    export.Format,
+			"error":     reason,
+		}); err != nil {
+			s.logger.WithError(err).WithField("export_id", export.ID).Error("Failed to publish export webhook")
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes the export to a temporary file and uploads it, returning the row count and file size
+func (s *ExportService) writeFile(ctx context.Context, export *domain.Export) (int, int64, error) {
+	file, err := os.CreateTemp("", "export-*."+string(export.Format))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer, err := newExportWriter(export.Format, file, string(export.Type), exportHeader(export.Type))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rowCount, err := s.writeRows(export, writer)
+	if err != nil {
+		return rowCount, 0, err
+	}
+	if err := writer.Close(); err != nil {
+		return rowCount, 0, err
+	}
+
+	fileSize, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return rowCount, 0, fmt.Errorf("failed to read export file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return rowCount, 0, fmt.Errorf("failed to read export file: %w", err)
+	}
+
+	key := fmt.Sprintf("exports/%s/%s.%s", export.MerchantID, export.ID, export.Format)
+	if _, err := s.storage.UploadFile(ctx, s.bucket, key, file, export.Format.ContentType()); err != nil {
+		return rowCount, 0, fmt.Errorf("failed to upload export file: %w", err)
+	}
+	export.FileKey = sql.NullString{String: key, Valid: true}
+
+	return rowCount, fileSize, nil
+}
+
+// notifyCompleted sends the download link by webhook and email, failures are logged
+func (s *ExportService) notifyCompleted(ctx context.Context, export *domain.Export) {
+	url, expiresAt, err := s.DownloadURL(ctx, export)
+	if err != nil {
+		s.logger.WithError(err).WithField("export_id", export.ID).Error("Failed to get export download URL")
+		return
+	}
+
+	if s.webhookPublisher != nil {
+		if err := s.webhookPublisher.PublishWebhook(ctx, export.MerchantID, domain.ExportEventCompleted, map[string]interface{}{
+			"export_id":               export.ID,
+			"type":                    export.Type,
+			"format":                  export.Format,
+			"row_count":               export.RowCount,
+			"file_name":               export.FileName(),
+			"download_url":            url,
+			"download_url_expires_at": expiresAt.Format(time.RFC3339),
+		}); err != nil {
+			s.logger.WithError(err).WithField("export_id", export.ID).Error("Failed to publish export webhook")
+		}
+	}
+
+	if s.merchantReader != nil && s.emailSender != nil {
+		email, err := s.merchantReader.GetMerchantEmail(export.MerchantID)
+		if err != nil {
+			s.logger.WithError(err).WithField("merchant_id", export.MerchantID).Error("Failed to get merchant email for export")
+			return
+		}
+		if err := s.emailSender.SendExportReadyEmail(ctx, email, export.ID, export.FileName(), url); err != nil {
+			s.logger.WithError(err).WithField("export_id", export.ID).Error("Failed to send export email")
+		}
+	}
+}
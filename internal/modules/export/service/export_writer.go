@@ -0,0 +1,174 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/domain"
+)
+
+// exportWriter writes the rows of an export file, the header row is written on creation
+type exportWriter interface {
+	WriteRow(values []interface{}) error
+	// Close writes what is buffered to the underlying writer
+	Close() error
+}
+
+// newExportWriter creates the writer of an export file in the given format
+func newExportWriter(format domain.ExportFormat, w io.Writer, sheetName string, header []string) (exportWriter, error) {
+	if format == domain.ExportFormatXLSX {
+		return newXLSXExportWriter(w, sheetName, header)
+	}
+	return newCSVExportWriter(w, header)
+}
+
+// csvExportWriter writes RFC 4180 CSV, amounts with full precision and times in RFC 3339 UTC
+type csvExportWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVExportWriter(w io.Writer, header []string) (*csvExportWriter, error) {
+	writer := &csvExportWriter{writer: csv.NewWriter(w)}
+	if err := writer.writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	return writer, nil
+}
+
+func (w *csvExportWriter) WriteRow(values []interface{}) error {
+	w.record = w.record[:0]
+	for _, value := range values {
+		w.record = append(w.record, csvValue(value))
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvExportWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// csvValue formats a cell of a CSV export
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case decimal.Decimal:
+		return v.String()
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return escapeFormula(fmt.Sprint(v))
+	}
+}
+
+// escapeFormula keeps spreadsheets from evaluating text that starts like a formula, such as an
+// order ID or description chosen by a payer
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
+
+// xlsxExportWriter streams rows to a single sheet, so large exports are not held cell by cell in memory
+type xlsxExportWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXExportWriter(w io.Writer, sheetName string, header []string) (*xlsxExportWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheetName); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create sheet: %w", err)
+	}
+
+	stream, err := file.NewStreamWriter(sheetName)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create sheet writer: %w", err)
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D9E1F2"}, Pattern: 1},
+	})
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create header style: %w", err)
+	}
+
+	cells := make([]interface{}, len(header))
+	for i, title := range header {
+		cells[i] = excelize.Cell{StyleID: headerStyle, Value: title}
+	}
+	if err := stream.SetRow("A1", cells); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write XLSX header: %w", err)
+	}
+
+	return &xlsxExportWriter{w: w, file: file, stream: stream, row: 1}, nil
+}
+
+func (w *xlsxExportWriter) WriteRow(values []interface{}) error {
+	w.row++
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = xlsxValue(value)
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+func (w *xlsxExportWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return fmt.Errorf("failed to flush XLSX sheet: %w", err)
+	}
+	if err := w.file.Write(w.w); err != nil {
+		return fmt.Errorf("failed to write XLSX file: %w", err)
+	}
+	return nil
+}
+
+// xlsxValue converts a cell of an XLSX export, amounts are numbers so they can be summed
+func xlsxValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case decimal.Decimal:
+		f, _ := v.Float64()
+		return f
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.UTC().Format("2006-01-02 15:04:05")
+	default:
+		return v
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/export/domain"
+)
+
+func TestCSVExportWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := newExportWriter(domain.ExportFormatCSV, &buf, "payments", []string{"ID", "Order ID", "Amount", "Created At", "Confirmed At"})
+	require.NoError(t, err)
+
+	createdAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("ICT", 7*60*60))
+	require.NoError(t, writer.WriteRow([]interface{}{"payment-1", "order, 1", decimal.RequireFromString("1250000.50"), createdAt, nil}))
+	require.NoError(t, writer.WriteRow([]interface{}{"payment-2", "=HYPERLINK(\"x\")", decimal.Zero, createdAt, createdAt}))
+	require.NoError(t, writer.Close())
+
+	expected := "ID,Order ID,Amount,Created At,Confirmed At\n" +
+		"payment-1,\"order, 1\",1250000.5,2026-03-01T02:30:00Z,\n" +
+		"payment-2,\"'=HYPERLINK(\"\"x\"\")\",0,2026-03-01T02:30:00Z,2026-03-01T02:30:00Z\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestXLSXExportWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := newExportWriter(domain.ExportFormatXLSX, &buf, "payouts", []string{"ID", "Amount (VND)"})
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRow([]interface{}{"payout-1", decimal.RequireFromString("5000000")}))
+	require.NoError(t, writer.Close())
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer file.Close()
+
+	rows, err := file.GetRows("payouts")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"ID", "Amount (VND)"}, {"payout-1", "5000000"}}, rows)
+}
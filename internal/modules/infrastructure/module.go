@@ -12,6 +12,7 @@ type Module struct {
 	MerchantNotificationPrefRepo *repository.MerchantNotificationPreferenceRepository
 	WalletBalanceRepo            *repository.WalletBalanceRepository
 	BlockchainTxRepo             *repository.BlockchainTxRepository
+	ListenerCursorRepo           *repository.ListenerCursorRepository
 	TransactionHashRepo          *repository.TransactionHashRepository
 	KYCDocumentRepo              repository.KYCDocumentRepository
 	PayoutScheduleRepo           *repository.PayoutScheduleRepository
@@ -33,6 +34,7 @@ func NewModule(cfg Config) (*Module, error) {
 		MerchantNotificationPrefRepo: repository.NewMerchantNotificationPreferenceRepository(cfg.DB),
 		WalletBalanceRepo:            repository.NewWalletBalanceRepository(cfg.DB),
 		BlockchainTxRepo:             repository.NewBlockchainTxRepository(cfg.DB),
+		ListenerCursorRepo:           repository.NewListenerCursorRepository(cfg.DB),
 		TransactionHashRepo:          repository.NewTransactionHashRepository(cfg.DB),
 		KYCDocumentRepo:              repository.NewKYCDocumentRepository(cfg.DB),
 		PayoutScheduleRepo:           repository.NewPayoutScheduleRepository(cfg.DB),
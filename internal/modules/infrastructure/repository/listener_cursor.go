@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+var (
+	// ErrListenerCursorNotFound is returned when a listener has not saved a cursor yet
+	ErrListenerCursorNotFound = errors.New("listener cursor not found")
+	// ErrRescanRequestNotFound is returned when a rescan request is not found
+	ErrRescanRequestNotFound = errors.New("rescan request not found")
+	// ErrInvalidRescanRequest is returned when a rescan request has neither a valid block range nor a tx hash
+	ErrInvalidRescanRequest = errors.New("rescan request needs either a block range or a tx hash")
+)
+
+// ListenerCursorRepository handles database operations for listener cursors and rescan requests
+type ListenerCursorRepository struct {
+	db *gorm.DB
+}
+
+// NewListenerCursorRepository creates a new listener cursor repository
+func NewListenerCursorRepository(db *gorm.DB) *ListenerCursorRepository {
+	return &ListenerCursorRepository{
+		db: db,
+	}
+}
+
+// GetCursor retrieves the cursor of a listener on a wallet
+func (r *ListenerCursorRepository) GetCursor(chain paymentDomain.Chain, walletAddress string) (*blockchainDomain.ListenerCursor, error) {
+	cursor := &blockchainDomain.ListenerCursor{}
+	if err := r.db.Where("chain = ? AND wallet_address = ?", chain, walletAddress).First(cursor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListenerCursorNotFound
+		}
+		return nil, fmt.Errorf("failed to get listener cursor: %w", err)
+	}
+
+	return cursor, nil
+}
+
+// SaveCursor creates or moves the cursor of a listener on a wallet
+func (r *ListenerCursorRepository) SaveCursor(cursor *blockchainDomain.ListenerCursor) error {
+	if cursor == nil {
+		return errors.New("listener cursor cannot be nil")
+	}
+
+	if cursor.Chain == "" || cursor.WalletAddress == "" {
+		return errors.New("listener cursor needs a chain and a wallet address")
+	}
+
+	cursor.UpdatedAt = time.Now()
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain"}, {Name: "wallet_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"block_number", "signature", "updated_at"}),
+	}).Create(cursor).Error
+	if err != nil {
+		return fmt.Errorf("failed to save listener cursor: %w", err)
+	}
+
+	return nil
+}
+
+// ListCursors retrieves the cursors of all listeners
+func (r *ListenerCursorRepository) ListCursors() ([]*blockchainDomain.ListenerCursor, error) {
+	var cursors []*blockchainDomain.ListenerCursor
+	if err := r.db.Order("chain ASC, wallet_address ASC").Find(&cursors).Error; err != nil {
+		return nil, fmt.Errorf("failed to list listener cursors: %w", err)
+	}
+
+	return cursors, nil
+}
+
+// CreateRescanRequest queues a rescan for the listener of a chain
+func (r *ListenerCursorRepository) CreateRescanRequest(request *blockchainDomain.RescanRequest) error {
+	if request == nil {
+		return errors.New("rescan request cannot be nil")
+	}
+
+	if request.IsTransactionRescan() {
+		request.FromBlock = sql.NullInt64{}
+		request.ToBlock = sql.NullInt64{}
+	} else if !request.FromBlock.Valid || !request.ToBlock.Valid ||
+		request.FromBlock.Int64 < 0 || request.ToBlock.Int64 < request.FromBlock.Int64 {
+		return ErrInvalidRescanRequest
+	}
+
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+	if request.Status == "" {
+		request.Status = blockchainDomain.RescanStatusPending
+	}
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+
+	if err := r.db.Create(request).Error; err != nil {
+		return fmt.Errorf("failed to create rescan request: %w", err)
+	}
+
+	return nil
+}
+
+// GetRescanRequest retrieves a rescan request by its ID
+func (r *ListenerCursorRepository) GetRescanRequest(id string) (*blockchainDomain.RescanRequest, error) {
+	if id == "" {
+		return nil, errors.New("id cannot be empty")
+	}
+
+	request := &blockchainDomain.RescanRequest{}
+	if err := r.db.Where("id = ?", id).First(request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRescanRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get rescan request: %w", err)
+	}
+
+	return request, nil
+}
+
+// ListPendingRescans retrieves the pending rescan requests of a chain, oldest first
+func (r *ListenerCursorRepository) ListPendingRescans(chain paymentDomain.Chain) ([]*blockchainDomain.RescanRequest, error) {
+	var requests []*blockchainDomain.RescanRequest
+	if err := r.db.Where("chain = ? AND status = ?", chain, blockchainDomain.RescanStatusPending).
+		Order("created_at ASC").
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to list pending rescan requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// MarkRescanProcessed completes a rescan request, a non-empty error message marks it as failed
+func (r *ListenerCursorRepository) MarkRescanProcessed(id string, errorMessage string) error {
+	if id == "" {
+		return errors.New("id cannot be empty")
+	}
+
+	status := blockchainDomain.RescanStatusCompleted
+	if errorMessage != "" {
+		status = blockchainDomain.RescanStatusFailed
+	}
+
+	result := r.db.Model(&blockchainDomain.RescanRequest{}).
+		Where("id = ? AND status = ?", id, blockchainDomain.RescanStatusPending).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"error_message": sql.NullString{String: errorMessage, Valid: errorMessage != ""},
+			"processed_at":  time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark rescan request as processed: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrRescanRequestNotFound
+	}
+
+	return nil
+}
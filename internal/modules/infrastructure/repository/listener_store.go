@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
+	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// PostgresListenerStore implements blockchainDomain.ListenerStore for one chain and wallet
+// Cursors and rescan requests live in their own tables, transactions are deduplicated against blockchain_transactions.
+// Every transfer to a watched address is also kept in inbound_transfers when an inbound transfer repository is set.
+type PostgresListenerStore struct {
+	cursorRepo    *ListenerCursorRepository
+	txRepo        *BlockchainTxRepository
+	inboundRepo   paymentDomain.InboundTransferRepository
+	chain         paymentDomain.Chain
+	network       blockchainDomain.Network
+	walletAddress string
+}
+
+// NewPostgresListenerStore creates a listener store bound to a chain and wallet
+// Network names other than mainnet and devnet (e.g. TRON shasta) are recorded as testnet.
+func NewPostgresListenerStore(
+	cursorRepo *ListenerCursorRepository,
+	txRepo *BlockchainTxRepository,
+	inboundRepo paymentDomain.InboundTransferRepository,
+	chain paymentDomain.Chain,
+	network string,
+	walletAddress string,
+) *PostgresListenerStore {
+	return &PostgresListenerStore{
+		cursorRepo:    cursorRepo,
+		txRepo:        txRepo,
+		inboundRepo:   inboundRepo,
+		chain:         chain,
+		network:       normalizeNetwork(network),
+		walletAddress: walletAddress,
+	}
+}
+
+// LoadCursor returns the saved cursor, or nil if the listener has not saved one yet
+func (s *PostgresListenerStore) LoadCursor(ctx context.Context) (*blockchainDomain.ListenerCursor, error) {
+	cursor, err := s.cursorRepo.GetCursor(s.chain, s.walletAddress)
+	if errors.Is(err, ErrListenerCursorNotFound) {
+		return nil, nil
+	}
+	return cursor, err
+}
+
+// SaveCursor stores the last fully processed block and Solana signature
+func (s *PostgresListenerStore) SaveCursor(ctx context.Context, blockNumber uint64, signature string) error {
+	return s.cursorRepo.SaveCursor(&blockchainDomain.ListenerCursor{
+		Chain:         s.chain,
+		WalletAddress: s.walletAddress,
+		BlockNumber:   int64(blockNumber),
+		Signature:     sql.NullString{String: signature, Valid: signature != ""},
+	})
+}
+
+// IsTransactionRecorded returns true if the transaction is already in blockchain_transactions
+func (s *PostgresListenerStore) IsTransactionRecorded(ctx context.Context, txHash string) (bool, error) {
+	_, err := s.txRepo.GetByTxHash(txHash)
+	if errors.Is(err, ErrBlockchainTxNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RecordTransaction stores a confirmed transfer in blockchain_transactions
+// The payment confirmation watcher marks it as finalized once it reaches chain finality.
+// Transactions that are already recorded, e.g. after a forced rescan, are left unchanged
+func (s *PostgresListenerStore) RecordTransaction(ctx context.Context, transfer blockchainDomain.ObservedTransfer) error {
+	recorded, err := s.IsTransactionRecorded(ctx, transfer.TxHash)
+	if err != nil || recorded {
+		return err
+	}
+
+	now := time.Now()
+	tx := &blockchainDomain.BlockchainTransaction{
+		ID:            uuid.New().String(),
+		Chain:         s.chain,
+		Network:       s.network,
+		TxHash:        transfer.TxHash,
+		BlockNumber:   sql.NullInt64{Int64: int64(transfer.BlockNumber), Valid: transfer.BlockNumber > 0},
+		FromAddress:   transfer.FromAddress,
+		ToAddress:     transfer.ToAddress,
+		Amount:        transfer.Amount,
+		Currency:      transfer.Currency,
+		TokenMint:     sql.NullString{String: transfer.TokenAddress, Valid: transfer.TokenAddress != ""},
+		Confirmations: 1,
+		IsFinalized:   false,
+		Status:        blockchainDomain.BlockchainTxStatusConfirmed,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if !transfer.BlockTime.IsZero() {
+		tx.BlockTimestamp = sql.NullTime{Time: transfer.BlockTime, Valid: true}
+	}
+
+	if transfer.PaymentID != "" {
+		tx.ParsedPaymentReference = sql.NullString{String: transfer.PaymentID, Valid: true}
+		tx.PaymentID = sql.NullString{String: transfer.PaymentID, Valid: true}
+		tx.IsMatched = true
+		tx.MatchedAt = sql.NullTime{Time: now, Valid: true}
+	}
+
+	if err := s.txRepo.Create(tx); err != nil && !errors.Is(err, ErrBlockchainTxAlreadyExists) {
+		return err
+	}
+
+	return nil
+}
+
+// RecordInboundTransfer stores a transfer to a watched address in inbound_transfers
+// Transfers that did not confirm a payment are queued as unmatched with the reason
+func (s *PostgresListenerStore) RecordInboundTransfer(ctx context.Context, transfer blockchainDomain.ObservedTransfer, matchErr error) error {
+	if s.inboundRepo == nil {
+		return nil
+	}
+
+	inbound := &paymentDomain.InboundTransfer{
+		Chain:        s.chain,
+		Network:      string(s.network),
+		TxHash:       transfer.TxHash,
+		BlockNumber:  sql.NullInt64{Int64: int64(transfer.BlockNumber), Valid: transfer.BlockNumber > 0},
+		FromAddress:  sql.NullString{String: transfer.FromAddress, Valid: transfer.FromAddress != ""},
+		ToAddress:    transfer.ToAddress,
+		Amount:       transfer.Amount,
+		Currency:     transfer.Currency,
+		TokenAddress: sql.NullString{String: transfer.TokenAddress, Valid: transfer.TokenAddress != ""},
+		Reference:    sql.NullString{String: transfer.Memo, Valid: transfer.Memo != ""},
+	}
+
+	if !transfer.BlockTime.IsZero() {
+		inbound.BlockTime = sql.NullTime{Time: transfer.BlockTime, Valid: true}
+	}
+
+	if transfer.PaymentID != "" && matchErr == nil {
+		inbound.MarkMatched(transfer.PaymentID)
+	} else {
+		inbound.MarkUnmatched(transfer.PaymentID, matchErr)
+	}
+
+	return s.inboundRepo.Record(inbound)
+}
+
+// PendingRescans returns the rescan requests waiting for the listener of this chain
+func (s *PostgresListenerStore) PendingRescans(ctx context.Context) ([]*blockchainDomain.RescanRequest, error) {
+	return s.cursorRepo.ListPendingRescans(s.chain)
+}
+
+// CompleteRescan marks a rescan request as completed, or failed if rescanErr is set
+func (s *PostgresListenerStore) CompleteRescan(ctx context.Context, id string, rescanErr error) error {
+	errorMessage := ""
+	if rescanErr != nil {
+		errorMessage = rescanErr.Error()
+	}
+	return s.cursorRepo.MarkRescanProcessed(id, errorMessage)
+}
+
+// normalizeNetwork maps a configured network name to the networks stored in blockchain_transactions
+func normalizeNetwork(network string) blockchainDomain.Network {
+	switch strings.ToLower(network) {
+	case "mainnet", "mainnet-beta":
+		return blockchainDomain.NetworkMainnet
+	case "devnet":
+		return blockchainDomain.NetworkDevnet
+	default:
+		return blockchainDomain.NetworkTestnet
+	}
+}
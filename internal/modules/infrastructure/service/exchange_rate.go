@@ -27,6 +27,10 @@ type ExchangeRateService struct {
 	cache          ExchangeRateCache
 	logger         *logrus.Logger
 	circuitBreaker *CircuitBreaker
+
+	// Provider the last rate was fetched from, reported on quotes
+	sourceMu   sync.RWMutex
+	lastSource string
 }
 
 // ExchangeRateCache defines the interface for caching exchange rates
@@ -84,6 +88,8 @@ const (
 	cacheKeyUSDTVND = "exchange_rate:usdt:vnd"
 	// Cache key for stale USDT/VND rate
 	cacheKeyStaleUSDTVND = "exchange_rate:usdt:vnd:stale"
+	// Cache key for the provider the cached USDT/VND rate came from
+	cacheKeyUSDTVNDSource = "exchange_rate:usdt:vnd:source"
 
 	// Default values
 	defaultMaxRetries    = 3
@@ -273,6 +279,7 @@ func (s *ExchangeRateService) GetUSDTToVND(ctx context.Context) (decimal.Decimal
 
 			// Record success in circuit breaker
 			s.circuitBreaker.RecordSuccess(provider.name)
+			s.setRateSource(ctx, provider.name)
 
 			// Cache the result
 			if s.cache != nil {
@@ -303,6 +310,7 @@ func (s *ExchangeRateService) GetUSDTToVND(ctx context.Context) (decimal.Decimal
 		staleRate, err := s.getFromStaleCache(ctx)
 		if err == nil {
 			s.logger.WithField("rate", staleRate).Warn("Using stale cached exchange rate")
+			s.setRateSource(ctx, "stale_cache")
 			return staleRate, nil
 		}
 		s.logger.WithError(err).Error("Stale cache also unavailable")
@@ -314,6 +322,36 @@ func (s *ExchangeRateService) GetUSDTToVND(ctx context.Context) (decimal.Decimal
 	return decimal.Zero, ErrExchangeRateNotAvailable
 }
 
+// GetRateSource returns the provider the current USDT/VND rate was fetched from
+// Rates served from the cache report the provider that filled it
+func (s *ExchangeRateService) GetRateSource(ctx context.Context) string {
+	if s.cache != nil {
+		if source, err := s.cache.Get(ctx, cacheKeyUSDTVNDSource); err == nil && source != "" {
+			return source
+		}
+	}
+
+	s.sourceMu.RLock()
+	defer s.sourceMu.RUnlock()
+	if s.lastSource == "" {
+		return "unknown"
+	}
+	return s.lastSource
+}
+
+// setRateSource records the provider of the rate just fetched
+func (s *ExchangeRateService) setRateSource(ctx context.Context, source string) {
+	s.sourceMu.Lock()
+	s.lastSource = source
+	s.sourceMu.Unlock()
+
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, cacheKeyUSDTVNDSource, source, s.cacheTTL); err != nil {
+			s.logger.WithError(err).Warn("Failed to cache exchange rate source")
+		}
+	}
+}
+
 // GetUSDCToVND returns the current USDC to VND exchange rate
 // Note: For MVP, USDC is treated as 1:1 with USDT (both are USD-pegged stablecoins)
 func (s *ExchangeRateService) GetUSDCToVND(ctx context.Context) (decimal.Decimal, error) {
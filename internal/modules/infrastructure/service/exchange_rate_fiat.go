@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUnsupportedCurrency is returned when no rate can be derived for a currency
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// pivotCurrency is the currency every rate is fetched against, cross rates are derived through it
+const pivotCurrency = "USDT"
+
+// usdStablecoins are valued 1:1 with the pivot
+var usdStablecoins = map[string]bool{
+	"USDT": true,
+	"USDC": true,
+	"BUSD": true,
+}
+
+// GetRate returns how many units of quote one unit of base is worth, e.g. GetRate(ctx, "EUR", "VND")
+// Rates are fetched against USDT and pairs without a direct price are derived as a cross rate,
+// EUR/VND = (USDT/VND) / (USDT/EUR).
+func (s *ExchangeRateService) GetRate(ctx context.Context, base, quote string) (decimal.Decimal, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	quote = strings.ToUpper(strings.TrimSpace(quote))
+	if base == "" || quote == "" {
+		return decimal.Zero, fmt.Errorf("%w: currency cannot be empty", ErrUnsupportedCurrency)
+	}
+
+	if base == quote || (usdStablecoins[base] && usdStablecoins[quote]) {
+		return decimal.NewFromInt(1), nil
+	}
+
+	baseRate, err := s.getPivotRate(ctx, base)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	quoteRate, err := s.getPivotRate(ctx, quote)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return quoteRate.DivRound(baseRate, 8), nil
+}
+
+// getPivotRate returns how many units of currency one USDT is worth
+func (s *ExchangeRateService) getPivotRate(ctx context.Context, currency string) (decimal.Decimal, error) {
+	if usdStablecoins[currency] {
+		return decimal.NewFromInt(1), nil
+	}
+	if currency == "VND" {
+		// USDT/VND has its own provider failover
+		return s.GetUSDTToVND(ctx)
+	}
+
+	return s.getFiatRate(ctx, currency)
+}
+
+// getFiatRate returns the USDT rate of a fiat currency other than VND
+// It uses the same cache, circuit breaker and stale fallback as USDT/VND.
+func (s *ExchangeRateService) getFiatRate(ctx context.Context, currency string) (decimal.Decimal, error) {
+	cacheKey := fmt.Sprintf("exchange_rate:usdt:%s", strings.ToLower(currency))
+	staleCacheKey := cacheKey + ":stale"
+
+	if s.cache != nil {
+		if rate, err := s.getCachedRate(ctx, cacheKey); err == nil {
+			return rate, nil
+		}
+	}
+
+	providers := []struct {
+		name    string
+		fetch   func(context.Context, string) (decimal.Decimal, error)
+		enabled bool
+	}{
+		{"coingecko", s.fetchFiatFromCoinGecko, s.primaryAPI != ""},
+		{"cryptocompare", s.fetchFiatFromCryptoCompare, s.tertiaryAPI != ""},
+	}
+
+	var lastErr error
+	for _, provider := range providers {
+		if !provider.enabled || s.circuitBreaker.IsOpen(provider.name) {
+			continue
+		}
+
+		rate, err := s.retryWithExponentialBackoff(ctx, func(ctx context.Context) (decimal.Decimal, error) {
+			return provider.fetch(ctx, currency)
+		}, provider.name)
+		if err == nil {
+			s.circuitBreaker.RecordSuccess(provider.name)
+			if s.cache != nil {
+				if err := s.cache.Set(ctx, cacheKey, rate.String(), s.cacheTTL); err != nil {
+					s.logger.WithError(err).Warn("Failed to cache exchange rate")
+				}
+				if err := s.cache.Set(ctx, staleCacheKey, rate.String(), s.staleCacheTTL); err != nil {
+					s.logger.WithError(err).Warn("Failed to save to stale cache")
+				}
+			}
+			return rate, nil
+		}
+
+		lastErr = err
+		if errors.Is(err, ErrUnsupportedCurrency) {
+			// The provider answered, it does not price this currency
+			continue
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"provider": provider.name,
+			"currency": currency,
+			"error":    err,
+		}).Warn("Provider failed")
+		s.circuitBreaker.RecordFailure(provider.name)
+	}
+
+	if s.cache != nil {
+		if rate, err := s.getCachedRate(ctx, staleCacheKey); err == nil {
+			s.logger.WithFields(logrus.Fields{
+				"currency": currency,
+				"rate":     rate,
+			}).Warn("Using stale cached exchange rate")
+			return rate, nil
+		}
+	}
+
+	if lastErr != nil {
+		return decimal.Zero, fmt.Errorf("%w: USDT/%s: %w", ErrAllProvidersFailed, currency, lastErr)
+	}
+	return decimal.Zero, fmt.Errorf("%w: USDT/%s", ErrExchangeRateNotAvailable, currency)
+}
+
+// getCachedRate reads a rate from the cache
+func (s *ExchangeRateService) getCachedRate(ctx context.Context, key string) (decimal.Decimal, error) {
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	rate, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid cached value: %w", err)
+	}
+	if rate.LessThanOrEqual(decimal.Zero) {
+		return decimal.Zero, ErrInvalidResponse
+	}
+
+	return rate, nil
+}
+
+// fetchFiatFromCoinGecko fetches the USDT price in a fiat currency from CoinGecko API
+func (s *ExchangeRateService) fetchFiatFromCoinGecko(ctx context.Context, currency string) (decimal.Decimal, error) {
+	vsCurrency := strings.ToLower(currency)
+	url := fmt.Sprintf("%s/simple/price?ids=tether&vs_currencies=%s", s.primaryAPI, vsCurrency)
+
+	var result map[string]map[string]float64
+	if err := s.getJSON(ctx, url, "CoinGecko", &result); err != nil {
+		return decimal.Zero, err
+	}
+
+	price, ok := result["tether"][vsCurrency]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	if price <= 0 {
+		return decimal.Zero, ErrInvalidResponse
+	}
+
+	return decimal.NewFromFloat(price), nil
+}
+
+// fetchFiatFromCryptoCompare fetches the USDT price in a fiat currency from CryptoCompare API
+func (s *ExchangeRateService) fetchFiatFromCryptoCompare(ctx context.Context, currency string) (decimal.Decimal, error) {
+	url := fmt.Sprintf("%s/price?fsym=%s&tsyms=%s", s.tertiaryAPI, pivotCurrency, currency)
+
+	var result map[string]float64
+	if err := s.getJSON(ctx, url, "CryptoCompare", &result); err != nil {
+		return decimal.Zero, err
+	}
+
+	price, ok := result[currency]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	if price <= 0 {
+		return decimal.Zero, ErrInvalidResponse
+	}
+
+	return decimal.NewFromFloat(price), nil
+}
+
+// getJSON sends a GET request and decodes the JSON response
+func (s *ExchangeRateService) getJSON(ctx context.Context, url, provider string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch from %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s API returned status %d: %s", provider, resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", provider, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeCoinGecko serves the USDT price in the given currencies, other currencies are left out like CoinGecko does
+func newFakeCoinGecko(t *testing.T, prices map[string]float64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vsCurrency := r.URL.Query().Get("vs_currencies")
+		tether := map[string]float64{}
+		if price, ok := prices[vsCurrency]; ok {
+			tether[vsCurrency] = price
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]map[string]float64{"tether": tether}))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExchangeRateService_GetRate(t *testing.T) {
+	server := newFakeCoinGecko(t, map[string]float64{
+		"vnd": 25000,
+		"usd": 1,
+		"eur": 0.92,
+	})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	service := NewExchangeRateServiceWithConfig(server.URL, "", "", time.Minute, time.Hour, time.Second, 0, nil, logger, nil)
+
+	tests := []struct {
+		name        string
+		base        string
+		quote       string
+		expected    string
+		expectedErr error
+	}{
+		{"EUR/VND is a cross rate through USDT", "EUR", "VND", "27173.91304348", nil},
+		{"USD/VND", "USD", "VND", "25000", nil},
+		{"VND/USD", "VND", "USD", "0.00004", nil},
+		{"USDT/VND", "USDT", "VND", "25000", nil},
+		{"USDT/EUR", "USDT", "EUR", "0.92", nil},
+		{"stablecoins are worth the same", "USDC", "USDT", "1", nil},
+		{"same currency", "EUR", "EUR", "1", nil},
+		{"codes are case insensitive", " usdt ", "Vnd", "25000", nil},
+		{"unsupported base", "XYZ", "VND", "", ErrUnsupportedCurrency},
+		{"unsupported quote", "USD", "XYZ", "", ErrUnsupportedCurrency},
+		{"empty currency", "", "VND", "", ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := service.GetRate(context.Background(), tt.base, tt.quote)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, rate.Equal(decimal.RequireFromString(tt.expected)), "got %s", rate)
+		})
+	}
+}
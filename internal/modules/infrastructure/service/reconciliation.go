@@ -65,7 +65,7 @@ func NewReconciliationService(
 // Formula:
 //
 //	Assets = Hot Wallet Crypto (converted to VND) + VND Pool + Fee Revenue
-//	Liabilities = Sum of all merchant balances (available + pending + reserved)
+//	Liabilities = Sum of all merchant balances (available + pending + reserved) + Held late payments
 //	Difference = Assets - Liabilities
 //
 // Status:
@@ -184,8 +184,9 @@ func (s *ReconciliationService) PerformDailyReconciliation(ctx context.Context)
 	return nil
 }
 
-// calculateTotalLiabilities sums all merchant balances
-// Liabilities = SUM(available_vnd + pending_vnd + reserved_vnd) for all merchants
+// calculateTotalLiabilities sums all merchant balances and the late payments still held
+// Liabilities = SUM(available_vnd + pending_vnd + reserved_vnd) for all merchants,
+// plus SUM(amount_received * exchange_rate) for late payments pending review or being refunded
 func (s *ReconciliationService) calculateTotalLiabilities(ctx context.Context) (decimal.Decimal, error) {
 	query := `
 		SELECT
@@ -199,7 +200,28 @@ func (s *ReconciliationService) calculateTotalLiabilities(ctx context.Context) (
 		return decimal.Zero, fmt.Errorf("failed to query merchant balances: %w", err)
 	}
 
-	return totalLiabilities, nil
+	// Late payments are owed to the payer (or the merchant) until they are accepted or refunded
+	lateQuery := `
+		SELECT
+			COALESCE(SUM(amount_received * exchange_rate), 0) as late_payment_liabilities
+		FROM payments
+		WHERE status = 'late_paid'
+			AND late_resolution IN ('pending_review', 'refunding')
+			AND deleted_at IS NULL
+	`
+
+	var latePaymentLiabilities decimal.Decimal
+	if err := s.db.Raw(lateQuery).Scan(&latePaymentLiabilities).Error; err != nil {
+		return decimal.Zero, fmt.Errorf("failed to query held late payments: %w", err)
+	}
+
+	if !latePaymentLiabilities.IsZero() {
+		s.logger.Info("Including held late payments in liabilities", map[string]interface{}{
+			"late_payment_liabilities_vnd": latePaymentLiabilities.Round(0).String(),
+		})
+	}
+
+	return totalLiabilities.Add(latePaymentLiabilities.Round(0)), nil
 }
 
 // calculateTotalAssets sums all assets the platform holds
@@ -33,6 +33,36 @@ func (r *BalanceRepository) IncrementPendingTx(tx *gorm.DB, merchantID string, a
 	return nil
 }
 
+func (r *BalanceRepository) DecrementPendingTx(tx *gorm.DB, merchantID string, amount decimal.Decimal) error {
+	result := tx.Table("merchant_balances").
+		Where("merchant_id = ? AND currency = ? AND pending_balance >= ?", merchantID, "VND", amount).
+		Update("pending_balance", gorm.Expr("pending_balance - ?", amount))
+	if result.Error != nil {
+		return fmt.Errorf("failed to decrement pending balance: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("insufficient pending balance")
+	}
+	return nil
+}
+
+func (r *BalanceRepository) IncrementReservedTx(tx *gorm.DB, merchantID string, amount decimal.Decimal) error {
+	err := tx.Table("merchant_balances").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "currency"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"reserved_balance": gorm.Expr("merchant_balances.reserved_balance + ?", amount)}),
+	}).Create(&map[string]interface{}{
+		"merchant_id":       merchantID,
+		"pending_balance":   decimal.Zero,
+		"available_balance": decimal.Zero,
+		"reserved_balance":  amount,
+		"currency":          "VND",
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to increment reserved balance: %w", err)
+	}
+	return nil
+}
+
 func (r *BalanceRepository) ConvertPendingToAvailableTx(tx *gorm.DB, merchantID string, grossAmount, feeAmount decimal.Decimal) error {
 	netAmount := grossAmount.Sub(feeAmount)
 	result := tx.Table("merchant_balances").
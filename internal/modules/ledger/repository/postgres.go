@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	ledgerDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/domain"
@@ -59,6 +60,14 @@ func (r *LedgerRepository) CreateEntry(entry *ledgerDomain.LedgerEntry) error {
 }
 
 func (r *LedgerRepository) CreateEntries(entries []*ledgerDomain.LedgerEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return r.CreateEntriesTx(tx, entries)
+	})
+}
+
+// CreateEntriesTx creates a balanced transaction group within an existing transaction,
+// so balance updates can be committed together with their entries
+func (r *LedgerRepository) CreateEntriesTx(tx *gorm.DB, entries []*ledgerDomain.LedgerEntry) error {
 	if len(entries) == 0 {
 		return errors.New("no entries to create")
 	}
@@ -102,18 +111,16 @@ func (r *LedgerRepository) CreateEntries(entries []*ledgerDomain.LedgerEntry) er
 		return fmt.Errorf("%w: debits=%s, credits=%s", ErrUnbalancedTransaction, totalDebits.String(), totalCredits.String())
 	}
 
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		for _, entry := range entries {
-			if entry.ID == "" {
-				entry.ID = uuid.New().String()
-			}
-
-			if err := tx.Create(entry).Error; err != nil {
-				return fmt.Errorf("failed to create ledger entry: %w", err)
-			}
+	for _, entry := range entries {
+		if entry.ID == "" {
+			entry.ID = uuid.New().String()
 		}
-		return nil
-	})
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create ledger entry: %w", err)
+		}
+	}
+	return nil
 }
 
 func (r *LedgerRepository) GetByID(id string) (*ledgerDomain.LedgerEntry, error) {
@@ -157,6 +164,38 @@ func (r *LedgerRepository) GetByMerchant(merchantID string, limit, offset int) (
 	return entries, nil
 }
 
+// GetByMerchantCreatedBetween retrieves ledger entries of a merchant created in [from, to), oldest first
+// Currency restricts the entries to one currency when not empty
+func (r *LedgerRepository) GetByMerchantCreatedBetween(merchantID string, from, to time.Time, currency string, limit, offset int) ([]*ledgerDomain.LedgerEntry, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := r.db.Where("merchant_id = ? AND created_at >= ? AND created_at < ?", merchantID, from, to)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+
+	var entries []*ledgerDomain.LedgerEntry
+	err := query.
+		Order("created_at ASC").
+		Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger entries by merchant and date range: %w", err)
+	}
+
+	return entries, nil
+}
+
 func (r *LedgerRepository) GetByReference(refType ledgerDomain.ReferenceType, refID string) ([]*ledgerDomain.LedgerEntry, error) {
 	if refType == "" {
 		return nil, errors.New("reference type cannot be empty")
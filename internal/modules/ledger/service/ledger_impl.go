@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/google/uuid"
 	ledgerDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/domain"
@@ -39,6 +40,11 @@ const (
 	AccountMerchantAvailablePrefix = "merchant_available:" // Prefix for merchant available balances
 	AccountMerchantReservedPrefix  = "merchant_reserved:"  // Prefix for merchant reserved balances
 	AccountPayoutLiability         = "payout_liability"    // Pending payouts owed to merchants
+	AccountPayerRefundablePrefix   = "payer_refundable:"   // Prefix for payer surplus owed back per payment
+	AccountLatePaymentHeldPrefix   = "late_payment_held:"  // Prefix for late transfers held per payment until resolved
+	AccountRefundClearing          = "refund_clearing"     // Clears VND deducted from merchants against crypto refunded
+	AccountSplitClearingPrefix     = "split_clearing:"     // Prefix for the net amount of a split payment divided per payment
+	AccountCommissionPrefix        = "commission:"         // Prefix for marketplace commissions earned on split payments
 
 	// Revenue accounts (credit increases, debit decreases)
 	AccountFeeRevenue   = "fee_revenue"   // Transaction and payout fees
@@ -49,13 +55,39 @@ const (
 	AccountOTCExpense    = "otc_expense"    // Costs paid to OTC partner
 	AccountPayoutExpense = "payout_expense" // Bank transfer fees for payouts
 	AccountOperatingExp  = "operating_exp"  // Other operating expenses
+
+	// AccountTestModePrefix prefixes the system accounts of test mode entries, e.g. test:crypto_pool
+	// Simulated payments never touch live system accounts, so reports on them exclude test mode
+	AccountTestModePrefix = "test:"
 )
 
+// PaymentPricing is the price a merchant set for a payment, recorded next to the VND amounts
+// Balances are kept in VND, the pricing currency is carried in the entry metadata for reporting.
+type PaymentPricing struct {
+	Currency string
+	Amount   decimal.Decimal
+	Fee      decimal.Decimal
+}
+
+// metadata returns the pricing fields of a ledger entry metadata
+func (p PaymentPricing) metadata(metadata database.JSONBMap) database.JSONBMap {
+	if p.Currency == "" {
+		return metadata
+	}
+	metadata["pricing_currency"] = p.Currency
+	metadata["pricing_amount"] = p.Amount.String()
+	if !p.Fee.IsZero() {
+		metadata["pricing_fee"] = p.Fee.String()
+	}
+	return metadata
+}
+
 // LedgerService provides business logic for double-entry accounting
 type LedgerService struct {
-	ledgerRepo  *repository.LedgerRepository
-	balanceRepo *repository.BalanceRepository
-	db          *gorm.DB
+	ledgerRepo    *repository.LedgerRepository
+	balanceRepo   *repository.BalanceRepository
+	db            *gorm.DB
+	accountPrefix string // Prefix of the system accounts, AccountTestModePrefix in test mode
 }
 
 // NewLedgerService creates a new ledger service
@@ -71,6 +103,18 @@ func NewLedgerService(
 	}
 }
 
+// NewTestModeLedgerService creates a ledger service for test mode payments
+// Its entries are posted to the test: system accounts instead of the live ones
+func NewTestModeLedgerService(
+	ledgerRepo *repository.LedgerRepository,
+	balanceRepo *repository.BalanceRepository,
+	db *gorm.DB,
+) *LedgerService {
+	s := NewLedgerService(ledgerRepo, balanceRepo, db)
+	s.accountPrefix = AccountTestModePrefix
+	return s
+}
+
 // RecordPaymentReceived records when a crypto payment is received from a user
 // This creates a ledger entry moving crypto to our pool and crediting merchant's pending balance
 //
@@ -83,6 +127,7 @@ func (s *LedgerService) RecordPaymentReceived(
 	amountCrypto decimal.Decimal,
 	cryptoCurrency string,
 	amountVND decimal.Decimal,
+	pricing PaymentPricing,
 ) error {
 	// Validate inputs
 	if err := s.validateBasicInputs(paymentID, merchantID, amountVND, "VND"); err != nil {
@@ -102,8 +147,8 @@ func (s *LedgerService) RecordPaymentReceived(
 	entries := []*ledgerDomain.LedgerEntry{
 		// Debit: Increase crypto pool
 		{
-			DebitAccount:     AccountCryptoPool,
-			CreditAccount:    AccountCryptoPool, // Placeholder, will be replaced
+			DebitAccount:     s.systemAccount(AccountCryptoPool),
+			CreditAccount:    s.systemAccount(AccountCryptoPool), // Placeholder, will be replaced
 			Amount:           amountCrypto,
 			Currency:         cryptoCurrency,
 			ReferenceType:    ledgerDomain.ReferenceTypePayment,
@@ -112,11 +157,11 @@ func (s *LedgerService) RecordPaymentReceived(
 			Description:      fmt.Sprintf("Payment %s received: %s %s", paymentID, amountCrypto.String(), cryptoCurrency),
 			TransactionGroup: transactionGroup,
 			EntryType:        ledgerDomain.EntryTypeDebit,
-			Metadata:         database.JSONBMap{"crypto_amount": amountCrypto.String(), "crypto_currency": cryptoCurrency},
+			Metadata:         pricing.metadata(database.JSONBMap{"crypto_amount": amountCrypto.String(), "crypto_currency": cryptoCurrency, "amount_vnd": amountVND.String()}),
 		},
 		// Credit: This is the corresponding credit (crypto pool from user)
 		{
-			DebitAccount:     AccountCryptoPool,
+			DebitAccount:     s.systemAccount(AccountCryptoPool),
 			CreditAccount:    fmt.Sprintf("user_payment:%s", paymentID),
 			Amount:           amountCrypto,
 			Currency:         cryptoCurrency,
@@ -126,7 +171,7 @@ func (s *LedgerService) RecordPaymentReceived(
 			Description:      fmt.Sprintf("User payment %s: %s %s", paymentID, amountCrypto.String(), cryptoCurrency),
 			TransactionGroup: transactionGroup,
 			EntryType:        ledgerDomain.EntryTypeCredit,
-			Metadata:         database.JSONBMap{"crypto_amount": amountCrypto.String(), "crypto_currency": cryptoCurrency},
+			Metadata:         pricing.metadata(database.JSONBMap{"crypto_amount": amountCrypto.String(), "crypto_currency": cryptoCurrency, "amount_vnd": amountVND.String()}),
 		},
 	}
 
@@ -163,6 +208,7 @@ func (s *LedgerService) RecordPaymentReceived(
 func (s *LedgerService) RecordPaymentConfirmed(
 	paymentID, merchantID string,
 	amountVND, feeVND decimal.Decimal,
+	pricing PaymentPricing,
 ) error {
 	// Validate inputs
 	if err := s.validateBasicInputs(paymentID, merchantID, amountVND, "VND"); err != nil {
@@ -192,7 +238,7 @@ func (s *LedgerService) RecordPaymentConfirmed(
 			Description:      fmt.Sprintf("Payment %s confirmed: converting pending to available", paymentID),
 			TransactionGroup: transactionGroup,
 			EntryType:        ledgerDomain.EntryTypeDebit,
-			Metadata:         database.JSONBMap{"gross_amount": amountVND.String(), "fee": feeVND.String()},
+			Metadata:         pricing.metadata(database.JSONBMap{"gross_amount": amountVND.String(), "fee": feeVND.String()}),
 		},
 		// Credit: Increase merchant available balance (net of fee)
 		{
@@ -206,12 +252,12 @@ func (s *LedgerService) RecordPaymentConfirmed(
 			Description:      fmt.Sprintf("Payment %s: available balance after fee", paymentID),
 			TransactionGroup: transactionGroup,
 			EntryType:        ledgerDomain.EntryTypeCredit,
-			Metadata:         database.JSONBMap{"net_amount": netAmount.String(), "fee_rate": "0.01"},
+			Metadata:         pricing.metadata(database.JSONBMap{"net_amount": netAmount.String(), "fee_rate": "0.01"}),
 		},
 		// Credit: Record fee revenue
 		{
 			DebitAccount:     s.getMerchantPendingAccount(merchantID),
-			CreditAccount:    AccountFeeRevenue,
+			CreditAccount:    s.systemAccount(AccountFeeRevenue),
 			Amount:           feeVND,
 			Currency:         "VND",
 			ReferenceType:    ledgerDomain.ReferenceTypeFee,
@@ -220,7 +266,7 @@ func (s *LedgerService) RecordPaymentConfirmed(
 			Description:      fmt.Sprintf("Transaction fee for payment %s", paymentID),
 			TransactionGroup: transactionGroup,
 			EntryType:        ledgerDomain.EntryTypeCredit,
-			Metadata:         database.JSONBMap{"fee_type": "transaction_fee", "fee_rate": "0.01"},
+			Metadata:         pricing.metadata(database.JSONBMap{"fee_type": "transaction_fee", "fee_rate": "0.01"}),
 		},
 	}
 
@@ -246,6 +292,585 @@ func (s *LedgerService) RecordPaymentConfirmed(
 	return nil
 }
 
+// RecordPaymentSurplus records crypto received above the requested amount of a payment
+// The surplus is held as a refundable credit owed back to the payer, not merchant revenue.
+// It is recorded once per payment and transfer hash, so the call can be retried safely.
+//
+// Accounting entry:
+//
+//	DEBIT:  crypto_pool (+S USDT)
+//	CREDIT: payer_refundable:{payment_id} (+S USDT)
+func (s *LedgerService) RecordPaymentSurplus(
+	paymentID, merchantID string,
+	surplusCrypto decimal.Decimal,
+	cryptoCurrency, txHash string,
+) error {
+	// Validate inputs
+	if err := s.validateBasicInputs(paymentID, merchantID, surplusCrypto, cryptoCurrency); err != nil {
+		return err
+	}
+
+	recorded, err := s.hasRecordedEntry(ledgerDomain.ReferenceTypePayment, paymentID, "surplus_tx_hash", txHash)
+	if err != nil {
+		return err
+	}
+	if recorded {
+		return nil
+	}
+
+	transactionGroup := uuid.New().String()
+	metadata := database.JSONBMap{"surplus_amount": surplusCrypto.String(), "crypto_currency": cryptoCurrency, "surplus_tx_hash": txHash}
+
+	entries := []*ledgerDomain.LedgerEntry{
+		// Debit: Increase crypto pool by the surplus
+		{
+			DebitAccount:     s.systemAccount(AccountCryptoPool),
+			CreditAccount:    s.systemAccount(AccountCryptoPool), // Placeholder
+			Amount:           surplusCrypto,
+			Currency:         cryptoCurrency,
+			ReferenceType:    ledgerDomain.ReferenceTypePayment,
+			ReferenceID:      paymentID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Payment %s overpaid: surplus %s %s received", paymentID, surplusCrypto.String(), cryptoCurrency),
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeDebit,
+			Metadata:         metadata,
+		},
+		// Credit: Refundable credit owed to the payer
+		{
+			DebitAccount:     s.systemAccount(AccountCryptoPool),
+			CreditAccount:    s.getPayerRefundableAccount(paymentID),
+			Amount:           surplusCrypto,
+			Currency:         cryptoCurrency,
+			ReferenceType:    ledgerDomain.ReferenceTypePayment,
+			ReferenceID:      paymentID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Payment %s overpaid: surplus refundable to payer", paymentID),
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeCredit,
+			Metadata:         metadata,
+		},
+	}
+
+	if err := s.ledgerRepo.CreateEntries(entries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	return nil
+}
+
+// RecordLatePaymentReceived records a transfer that arrived after its payment expired
+// The amount is held per payment until the late payment is accepted or refunded
+//
+// Accounting entry:
+//
+//	DEBIT:  crypto_pool (+X USDT)
+//	CREDIT: late_payment_held:{payment_id} (+X USDT)
+func (s *LedgerService) RecordLatePaymentReceived(
+	paymentID, merchantID string,
+	amountCrypto decimal.Decimal,
+	cryptoCurrency string,
+) error {
+	if err := s.validateBasicInputs(paymentID, merchantID, amountCrypto, cryptoCurrency); err != nil {
+		return err
+	}
+
+	return s.recordLatePaymentEntries(
+		paymentID, merchantID, amountCrypto, cryptoCurrency,
+		s.systemAccount(AccountCryptoPool), s.getLatePaymentHeldAccount(paymentID),
+		fmt.Sprintf("Payment %s paid late: %s %s held until resolved", paymentID, amountCrypto.String(), cryptoCurrency),
+		database.JSONBMap{"late_payment": true, "crypto_currency": cryptoCurrency},
+	)
+}
+
+// RecordLatePaymentReleased releases the held amount of a late payment once it is resolved
+// Accepted late payments are then accounted for like any completed payment; refunded ones
+// leave the crypto pool with the refund transfer.
+//
+// Accounting entry:
+//
+//	DEBIT:  late_payment_held:{payment_id} (-X USDT)
+//	CREDIT: crypto_pool (-X USDT)
+func (s *LedgerService) RecordLatePaymentReleased(
+	paymentID, merchantID string,
+	amountCrypto decimal.Decimal,
+	cryptoCurrency string,
+	resolution string,
+) error {
+	if err := s.validateBasicInputs(paymentID, merchantID, amountCrypto, cryptoCurrency); err != nil {
+		return err
+	}
+
+	// Refund completion is retried when the refund status could not be saved, it is released once
+	released, err := s.hasRecordedEntry(ledgerDomain.ReferenceTypePayment, paymentID, "resolution", resolution)
+	if err != nil {
+		return err
+	}
+	if released {
+		return nil
+	}
+
+	return s.recordLatePaymentEntries(
+		paymentID, merchantID, amountCrypto, cryptoCurrency,
+		s.getLatePaymentHeldAccount(paymentID), s.systemAccount(AccountCryptoPool),
+		fmt.Sprintf("Late payment %s %s: held %s %s released", paymentID, resolution, amountCrypto.String(), cryptoCurrency),
+		database.JSONBMap{"late_payment": true, "crypto_currency": cryptoCurrency, "resolution": resolution},
+	)
+}
+
+// recordLatePaymentEntries posts a balanced debit/credit pair for a late payment
+func (s *LedgerService) recordLatePaymentEntries(
+	paymentID, merchantID string,
+	amountCrypto decimal.Decimal,
+	cryptoCurrency, debitAccount, creditAccount, description string,
+	metadata database.JSONBMap,
+) error {
+	transactionGroup := uuid.New().String()
+
+	entries := []*ledgerDomain.LedgerEntry{
+		{
+			DebitAccount:     debitAccount,
+			CreditAccount:    debitAccount, // Placeholder
+			Amount:           amountCrypto,
+			Currency:         cryptoCurrency,
+			ReferenceType:    ledgerDomain.ReferenceTypePayment,
+			ReferenceID:      paymentID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      description,
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeDebit,
+			Metadata:         metadata,
+		},
+		{
+			DebitAccount:     debitAccount,
+			CreditAccount:    creditAccount,
+			Amount:           amountCrypto,
+			Currency:         cryptoCurrency,
+			ReferenceType:    ledgerDomain.ReferenceTypePayment,
+			ReferenceID:      paymentID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      description,
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeCredit,
+			Metadata:         metadata,
+		},
+	}
+
+	if err := s.ledgerRepo.CreateEntries(entries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	return nil
+}
+
+// RecordPaymentReversed reverses every ledger entry recorded for a payment whose transfer
+// was reorged out of the chain. Each transaction group is mirrored (debit and credit accounts
+// swapped) in a new group, the original entries are left untouched.
+// Groups that were already reversed are skipped, so the call can be retried safely.
+func (s *LedgerService) RecordPaymentReversed(paymentID, merchantID, reason string) error {
+	if paymentID == "" {
+		return ErrLedgerInvalidReferenceID
+	}
+	if merchantID == "" {
+		return ErrLedgerInvalidMerchantID
+	}
+
+	var recorded []*ledgerDomain.LedgerEntry
+	for _, refType := range []ledgerDomain.ReferenceType{ledgerDomain.ReferenceTypePayment, ledgerDomain.ReferenceTypeFee} {
+		entries, err := s.ledgerRepo.GetByReference(refType, paymentID)
+		if err != nil {
+			return fmt.Errorf("failed to get ledger entries: %w", err)
+		}
+		recorded = append(recorded, entries...)
+	}
+
+	// Split original entries by transaction group, remembering the groups already reversed
+	reversedGroups := make(map[string]bool)
+	var groupOrder []string
+	groups := make(map[string][]*ledgerDomain.LedgerEntry)
+	for _, entry := range recorded {
+		if group, ok := entry.Metadata["reversal_of_group"].(string); ok {
+			reversedGroups[group] = true
+			continue
+		}
+		if _, ok := groups[entry.TransactionGroup]; !ok {
+			groupOrder = append(groupOrder, entry.TransactionGroup)
+		}
+		groups[entry.TransactionGroup] = append(groups[entry.TransactionGroup], entry)
+	}
+
+	for _, group := range groupOrder {
+		if reversedGroups[group] {
+			continue
+		}
+
+		transactionGroup := uuid.New().String()
+		reversals := make([]*ledgerDomain.LedgerEntry, 0, len(groups[group]))
+		for _, entry := range groups[group] {
+			reversals = append(reversals, &ledgerDomain.LedgerEntry{
+				DebitAccount:     entry.CreditAccount,
+				CreditAccount:    entry.DebitAccount,
+				Amount:           entry.Amount,
+				Currency:         entry.Currency,
+				ReferenceType:    entry.ReferenceType,
+				ReferenceID:      entry.ReferenceID,
+				MerchantID:       entry.MerchantID,
+				Description:      fmt.Sprintf("Payment %s reversed: %s", paymentID, entry.Description),
+				TransactionGroup: transactionGroup,
+				EntryType:        entry.EntryType,
+				Metadata:         database.JSONBMap{"reversal_of": entry.ID, "reversal_of_group": group, "reversal_reason": reason},
+			})
+		}
+
+		// Split shares were credited to pending balances, they are taken back with the entries
+		if _, ok := groups[group][0].Metadata["split_role"]; ok {
+			if err := s.reverseSplitShares(reversals, groups[group]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.ledgerRepo.CreateEntries(reversals); err != nil {
+			return fmt.Errorf("failed to create ledger entries: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reverseSplitShares creates the mirror entries of a split payment distribution and removes
+// each credited share from its recipient's pending balance
+func (s *LedgerService) reverseSplitShares(reversals, original []*ledgerDomain.LedgerEntry) error {
+	tx := s.db.Begin()
+	defer tx.Rollback()
+
+	if err := s.ledgerRepo.CreateEntriesTx(tx, reversals); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	for _, entry := range original {
+		if entry.EntryType != ledgerDomain.EntryTypeCredit {
+			continue
+		}
+		if err := s.balanceRepo.DecrementPendingTx(tx, entry.MerchantID.String, entry.Amount); err != nil {
+			return fmt.Errorf("failed to update merchant balance: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// splitLeg is the share of one recipient of a split payment
+type splitLeg struct {
+	merchantID string
+	account    string
+	role       string // seller or commission
+	amount     decimal.Decimal
+}
+
+// splitLegs returns the legs of a split payment in a stable order (sellers by merchant ID, then
+// the commission) and their total
+func (s *LedgerService) splitLegs(merchantID string, sellerShares map[string]decimal.Decimal, commission decimal.Decimal) ([]splitLeg, decimal.Decimal, error) {
+	sellerIDs := make([]string, 0, len(sellerShares))
+	for sellerID := range sellerShares {
+		sellerIDs = append(sellerIDs, sellerID)
+	}
+	sort.Strings(sellerIDs)
+
+	legs := make([]splitLeg, 0, len(sellerIDs)+1)
+	total := decimal.Zero
+	for _, sellerID := range sellerIDs {
+		amount := sellerShares[sellerID]
+		if sellerID == "" {
+			return nil, decimal.Zero, ErrLedgerInvalidMerchantID
+		}
+		if amount.LessThanOrEqual(decimal.Zero) {
+			return nil, decimal.Zero, ErrLedgerInvalidAmount
+		}
+		legs = append(legs, splitLeg{
+			merchantID: sellerID,
+			account:    s.getMerchantPendingAccount(sellerID),
+			role:       "seller",
+			amount:     amount,
+		})
+		total = total.Add(amount)
+	}
+
+	if commission.LessThan(decimal.Zero) {
+		return nil, decimal.Zero, ErrLedgerInvalidAmount
+	}
+	if commission.GreaterThan(decimal.Zero) {
+		legs = append(legs, splitLeg{
+			merchantID: merchantID,
+			account:    s.getMerchantCommissionAccount(merchantID),
+			role:       "commission",
+			amount:     commission,
+		})
+		total = total.Add(commission)
+	}
+
+	return legs, total, nil
+}
+
+// RecordPaymentSplit records the net amount of a completed split payment divided among its
+// recipients. Each seller's share is credited to its pending balance and the commission to the
+// marketplace merchant that created the payment; entries and balances are committed together.
+//
+// Accounting entry:
+//
+//	DEBIT:  split_clearing:{payment_id} (-N VND)
+//	CREDIT: merchant_pending:{seller_id} (+S VND per seller)
+//	CREDIT: commission:{merchant_id} (+C VND)
+func (s *LedgerService) RecordPaymentSplit(
+	paymentID, merchantID string,
+	sellerShares map[string]decimal.Decimal,
+	commission decimal.Decimal,
+) error {
+	legs, total, err := s.splitLegs(merchantID, sellerShares, commission)
+	if err != nil {
+		return err
+	}
+	if err := s.validateBasicInputs(paymentID, merchantID, total, "VND"); err != nil {
+		return err
+	}
+
+	transactionGroup := uuid.New().String()
+	clearingAccount := s.getSplitClearingAccount(paymentID)
+
+	entries := make([]*ledgerDomain.LedgerEntry, 0, 2*len(legs))
+	for _, leg := range legs {
+		metadata := database.JSONBMap{"split_role": leg.role, "marketplace_merchant_id": merchantID}
+		entries = append(entries,
+			// Debit: Net amount of the payment distributed
+			&ledgerDomain.LedgerEntry{
+				DebitAccount:     clearingAccount,
+				CreditAccount:    clearingAccount, // Placeholder
+				Amount:           leg.amount,
+				Currency:         "VND",
+				ReferenceType:    ledgerDomain.ReferenceTypePayment,
+				ReferenceID:      paymentID,
+				MerchantID:       sql.NullString{String: leg.merchantID, Valid: true},
+				Description:      fmt.Sprintf("Split payment %s: %s VND distributed", paymentID, leg.amount.String()),
+				TransactionGroup: transactionGroup,
+				EntryType:        ledgerDomain.EntryTypeDebit,
+				Metadata:         metadata,
+			},
+			// Credit: Recipient's share
+			&ledgerDomain.LedgerEntry{
+				DebitAccount:     clearingAccount,
+				CreditAccount:    leg.account,
+				Amount:           leg.amount,
+				Currency:         "VND",
+				ReferenceType:    ledgerDomain.ReferenceTypePayment,
+				ReferenceID:      paymentID,
+				MerchantID:       sql.NullString{String: leg.merchantID, Valid: true},
+				Description:      fmt.Sprintf("Split payment %s: %s share", paymentID, leg.role),
+				TransactionGroup: transactionGroup,
+				EntryType:        ledgerDomain.EntryTypeCredit,
+				Metadata:         metadata,
+			},
+		)
+	}
+
+	// Start database transaction
+	tx := s.db.Begin()
+	defer tx.Rollback()
+
+	if err := s.ledgerRepo.CreateEntriesTx(tx, entries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	// Credit every share to the pending balance of its recipient
+	for _, leg := range legs {
+		if err := s.balanceRepo.IncrementPendingTx(tx, leg.merchantID, leg.amount); err != nil {
+			return fmt.Errorf("failed to update merchant balance: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RecordSplitRefundRequested records a refund of a split payment. Each recipient gives back its
+// share of the refund, which is reserved for the marketplace merchant until the transfer is final;
+// RecordRefundCompleted then deducts it like any other refund.
+//
+// Accounting entry:
+//
+//	DEBIT:  merchant_pending:{seller_id} (-S VND per seller)
+//	DEBIT:  commission:{merchant_id} (-C VND)
+//	CREDIT: merchant_reserved:{merchant_id} (+R VND)
+func (s *LedgerService) RecordSplitRefundRequested(
+	refundID, merchantID string,
+	sellerShares map[string]decimal.Decimal,
+	commission decimal.Decimal,
+) error {
+	legs, total, err := s.splitLegs(merchantID, sellerShares, commission)
+	if err != nil {
+		return err
+	}
+	if err := s.validateBasicInputs(refundID, merchantID, total, "VND"); err != nil {
+		return err
+	}
+
+	transactionGroup := uuid.New().String()
+	reservedAccount := s.getMerchantReservedAccount(merchantID)
+
+	entries := make([]*ledgerDomain.LedgerEntry, 0, 2*len(legs))
+	for _, leg := range legs {
+		metadata := database.JSONBMap{"refund_status": "pending", "split_role": leg.role}
+		entries = append(entries,
+			// Debit: Recipient's share of the refund
+			&ledgerDomain.LedgerEntry{
+				DebitAccount:     leg.account,
+				CreditAccount:    leg.account, // Placeholder
+				Amount:           leg.amount,
+				Currency:         "VND",
+				ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+				ReferenceID:      refundID,
+				MerchantID:       sql.NullString{String: leg.merchantID, Valid: true},
+				Description:      fmt.Sprintf("Refund %s requested: %s share of %s VND", refundID, leg.role, leg.amount.String()),
+				TransactionGroup: transactionGroup,
+				EntryType:        ledgerDomain.EntryTypeDebit,
+				Metadata:         metadata,
+			},
+			// Credit: Held for the refund until the on-chain transfer is final
+			&ledgerDomain.LedgerEntry{
+				DebitAccount:     leg.account,
+				CreditAccount:    reservedAccount,
+				Amount:           leg.amount,
+				Currency:         "VND",
+				ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+				ReferenceID:      refundID,
+				MerchantID:       sql.NullString{String: merchantID, Valid: true},
+				Description:      fmt.Sprintf("Refund %s: %s share held until on-chain transfer is final", refundID, leg.role),
+				TransactionGroup: transactionGroup,
+				EntryType:        ledgerDomain.EntryTypeCredit,
+				Metadata:         metadata,
+			},
+		)
+	}
+
+	// Start database transaction
+	tx := s.db.Begin()
+	defer tx.Rollback()
+
+	// Take each share back from its recipient (this will check if sufficient balance exists)
+	for _, leg := range legs {
+		if err := s.balanceRepo.DecrementPendingTx(tx, leg.merchantID, leg.amount); err != nil {
+			return fmt.Errorf("failed to reserve balance: %w", err)
+		}
+	}
+	if err := s.balanceRepo.IncrementReservedTx(tx, merchantID, total); err != nil {
+		return fmt.Errorf("failed to reserve balance: %w", err)
+	}
+
+	if err := s.ledgerRepo.CreateEntriesTx(tx, entries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RecordSplitRefundFailed records when a refund of a split payment could not be sent or failed
+// on-chain. The reserved amount is given back to each recipient's share.
+func (s *LedgerService) RecordSplitRefundFailed(
+	refundID, merchantID string,
+	sellerShares map[string]decimal.Decimal,
+	commission decimal.Decimal,
+	reason string,
+) error {
+	legs, total, err := s.splitLegs(merchantID, sellerShares, commission)
+	if err != nil {
+		return err
+	}
+	if err := s.validateBasicInputs(refundID, merchantID, total, "VND"); err != nil {
+		return err
+	}
+
+	// Failure is retried when the refund status could not be saved, it is posted once
+	failed, err := s.hasRecordedEntry(ledgerDomain.ReferenceTypeRefund, refundID, "refund_status", "failed")
+	if err != nil {
+		return err
+	}
+	if failed {
+		return nil
+	}
+
+	transactionGroup := uuid.New().String()
+	reservedAccount := s.getMerchantReservedAccount(merchantID)
+
+	entries := make([]*ledgerDomain.LedgerEntry, 0, 2*len(legs))
+	for _, leg := range legs {
+		metadata := database.JSONBMap{"refund_status": "failed", "failure_reason": reason, "split_role": leg.role}
+		entries = append(entries,
+			// Debit: Merchant reserved balance (unreserve)
+			&ledgerDomain.LedgerEntry{
+				DebitAccount:     reservedAccount,
+				CreditAccount:    reservedAccount, // Placeholder
+				Amount:           leg.amount,
+				Currency:         "VND",
+				ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+				ReferenceID:      refundID,
+				MerchantID:       sql.NullString{String: merchantID, Valid: true},
+				Description:      fmt.Sprintf("Refund %s failed: releasing %s VND from reserve - %s", refundID, leg.amount.String(), reason),
+				TransactionGroup: transactionGroup,
+				EntryType:        ledgerDomain.EntryTypeDebit,
+				Metadata:         metadata,
+			},
+			// Credit: Share returned to its recipient
+			&ledgerDomain.LedgerEntry{
+				DebitAccount:     reservedAccount,
+				CreditAccount:    leg.account,
+				Amount:           leg.amount,
+				Currency:         "VND",
+				ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+				ReferenceID:      refundID,
+				MerchantID:       sql.NullString{String: leg.merchantID, Valid: true},
+				Description:      fmt.Sprintf("Refund %s failed: %s share returned", refundID, leg.role),
+				TransactionGroup: transactionGroup,
+				EntryType:        ledgerDomain.EntryTypeCredit,
+				Metadata:         metadata,
+			},
+		)
+	}
+
+	// Start database transaction
+	tx := s.db.Begin()
+	defer tx.Rollback()
+
+	// Release the reserved amount back to the recipients
+	if err := s.balanceRepo.DeductBalanceTx(tx, merchantID, total, decimal.Zero); err != nil {
+		return fmt.Errorf("failed to release reserved balance: %w", err)
+	}
+	for _, leg := range legs {
+		if err := s.balanceRepo.IncrementPendingTx(tx, leg.merchantID, leg.amount); err != nil {
+			return fmt.Errorf("failed to update merchant balance: %w", err)
+		}
+	}
+
+	if err := s.ledgerRepo.CreateEntriesTx(tx, entries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // RecordPayoutRequested records when a merchant requests a payout
 // This reserves the requested amount from their available balance
 //
@@ -321,7 +946,7 @@ func (s *LedgerService) RecordPayoutCompleted(
 		// Credit: Decrease VND pool (money going out to merchant's bank)
 		{
 			DebitAccount:     s.getMerchantAvailableAccount(merchantID),
-			CreditAccount:    AccountVNDPool,
+			CreditAccount:    s.systemAccount(AccountVNDPool),
 			Amount:           amount,
 			Currency:         "VND",
 			ReferenceType:    ledgerDomain.ReferenceTypePayout,
@@ -335,7 +960,7 @@ func (s *LedgerService) RecordPayoutCompleted(
 		// Credit: Record payout fee revenue
 		{
 			DebitAccount:     s.getMerchantAvailableAccount(merchantID),
-			CreditAccount:    AccountFeeRevenue,
+			CreditAccount:    s.systemAccount(AccountFeeRevenue),
 			Amount:           feeVND,
 			Currency:         "VND",
 			ReferenceType:    ledgerDomain.ReferenceTypeFee,
@@ -505,6 +1130,278 @@ func (s *LedgerService) RecordPayoutFailed(
 	return nil
 }
 
+// RecordRefundRequested records when a merchant refunds a payment to the payer
+// This reserves the refund amount from their available balance until the transfer is final
+//
+// Accounting entry:
+//
+//	DEBIT:  merchant_available_balance (-R VND)
+//	CREDIT: merchant_reserved_balance (+R VND)
+func (s *LedgerService) RecordRefundRequested(
+	refundID, merchantID string,
+	amountVND decimal.Decimal,
+) error {
+	// Validate inputs
+	if err := s.validateBasicInputs(refundID, merchantID, amountVND, "VND"); err != nil {
+		return err
+	}
+
+	transactionGroup := uuid.New().String()
+
+	entries := []*ledgerDomain.LedgerEntry{
+		// Debit: Decrease merchant available balance
+		{
+			DebitAccount:     s.getMerchantAvailableAccount(merchantID),
+			CreditAccount:    s.getMerchantAvailableAccount(merchantID), // Placeholder
+			Amount:           amountVND,
+			Currency:         "VND",
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s requested: reserving %s VND", refundID, amountVND.String()),
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeDebit,
+			Metadata:         database.JSONBMap{"refund_status": "pending"},
+		},
+		// Credit: Increase merchant reserved balance
+		{
+			DebitAccount:     s.getMerchantAvailableAccount(merchantID),
+			CreditAccount:    s.getMerchantReservedAccount(merchantID),
+			Amount:           amountVND,
+			Currency:         "VND",
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s: amount held until on-chain transfer is final", refundID),
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeCredit,
+			Metadata:         database.JSONBMap{"refund_status": "pending"},
+		},
+	}
+
+	// Start database transaction
+	tx := s.db.Begin()
+	defer tx.Rollback()
+
+	// Reserve balance (this will check if sufficient balance exists)
+	if err := s.balanceRepo.ReserveBalanceTx(tx, merchantID, amountVND); err != nil {
+		return fmt.Errorf("failed to reserve balance: %w", err)
+	}
+
+	// Create ledger entries
+	if err := s.ledgerRepo.CreateEntriesTx(tx, entries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RecordRefundCompleted records when a refund transfer is finalized on-chain
+// The reserved VND is released against the crypto sent back to the payer through a clearing account
+//
+// Accounting entries:
+//
+//	DEBIT:  merchant_reserved_balance (-R VND)
+//	CREDIT: refund_clearing (+R VND)
+//
+//	DEBIT:  refund_clearing (+X USDT)
+//	CREDIT: crypto_pool (-X USDT)
+func (s *LedgerService) RecordRefundCompleted(
+	refundID, merchantID string,
+	amountVND, amountCrypto decimal.Decimal,
+	cryptoCurrency string,
+) error {
+	// Validate inputs
+	if err := s.validateBasicInputs(refundID, merchantID, amountVND, "VND"); err != nil {
+		return err
+	}
+	if amountCrypto.LessThanOrEqual(decimal.Zero) {
+		return ErrLedgerInvalidAmount
+	}
+	if cryptoCurrency == "" {
+		return ErrLedgerInvalidCurrency
+	}
+
+	// Completion is retried when the refund status could not be saved, it is posted once
+	completed, err := s.hasRecordedEntry(ledgerDomain.ReferenceTypeRefund, refundID, "refund_status", "completed")
+	if err != nil {
+		return err
+	}
+	if completed {
+		return nil
+	}
+
+	vndGroup := uuid.New().String()
+	cryptoGroup := uuid.New().String()
+
+	vndEntries := []*ledgerDomain.LedgerEntry{
+		// Debit: Decrease merchant reserved balance
+		{
+			DebitAccount:     s.getMerchantReservedAccount(merchantID),
+			CreditAccount:    s.getMerchantReservedAccount(merchantID), // Placeholder
+			Amount:           amountVND,
+			Currency:         "VND",
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s completed: deducting %s VND", refundID, amountVND.String()),
+			TransactionGroup: vndGroup,
+			EntryType:        ledgerDomain.EntryTypeDebit,
+			Metadata:         database.JSONBMap{"refund_status": "completed", "crypto_group": cryptoGroup},
+		},
+		// Credit: Refund clearing
+		{
+			DebitAccount:     s.getMerchantReservedAccount(merchantID),
+			CreditAccount:    s.systemAccount(AccountRefundClearing),
+			Amount:           amountVND,
+			Currency:         "VND",
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s: VND settled against crypto returned to payer", refundID),
+			TransactionGroup: vndGroup,
+			EntryType:        ledgerDomain.EntryTypeCredit,
+			Metadata:         database.JSONBMap{"refund_status": "completed", "crypto_group": cryptoGroup},
+		},
+	}
+
+	cryptoEntries := []*ledgerDomain.LedgerEntry{
+		// Debit: Refund clearing
+		{
+			DebitAccount:     s.systemAccount(AccountRefundClearing),
+			CreditAccount:    s.systemAccount(AccountRefundClearing), // Placeholder
+			Amount:           amountCrypto,
+			Currency:         cryptoCurrency,
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s: %s %s returned to payer", refundID, amountCrypto.String(), cryptoCurrency),
+			TransactionGroup: cryptoGroup,
+			EntryType:        ledgerDomain.EntryTypeDebit,
+			Metadata:         database.JSONBMap{"crypto_amount": amountCrypto.String(), "crypto_currency": cryptoCurrency, "vnd_group": vndGroup},
+		},
+		// Credit: Decrease crypto pool (crypto sent out of hot wallet)
+		{
+			DebitAccount:     s.systemAccount(AccountRefundClearing),
+			CreditAccount:    s.systemAccount(AccountCryptoPool),
+			Amount:           amountCrypto,
+			Currency:         cryptoCurrency,
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s: crypto sent from hot wallet", refundID),
+			TransactionGroup: cryptoGroup,
+			EntryType:        ledgerDomain.EntryTypeCredit,
+			Metadata:         database.JSONBMap{"crypto_amount": amountCrypto.String(), "crypto_currency": cryptoCurrency, "vnd_group": vndGroup},
+		},
+	}
+
+	// Start database transaction
+	tx := s.db.Begin()
+	defer tx.Rollback()
+
+	// Create ledger entries
+	if err := s.ledgerRepo.CreateEntriesTx(tx, vndEntries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+	if err := s.ledgerRepo.CreateEntriesTx(tx, cryptoEntries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	// Update merchant balance - deduct from reserved
+	if err := s.balanceRepo.DeductBalanceTx(tx, merchantID, amountVND, decimal.Zero); err != nil {
+		return fmt.Errorf("failed to update merchant balance: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RecordRefundFailed records when a refund could not be sent or failed on-chain
+// This releases the reserved balance back to available
+func (s *LedgerService) RecordRefundFailed(
+	refundID, merchantID string,
+	amountVND decimal.Decimal,
+	reason string,
+) error {
+	// Validate inputs
+	if err := s.validateBasicInputs(refundID, merchantID, amountVND, "VND"); err != nil {
+		return err
+	}
+
+	// Failure is retried when the refund status could not be saved, it is posted once
+	failed, err := s.hasRecordedEntry(ledgerDomain.ReferenceTypeRefund, refundID, "refund_status", "failed")
+	if err != nil {
+		return err
+	}
+	if failed {
+		return nil
+	}
+
+	transactionGroup := uuid.New().String()
+
+	entries := []*ledgerDomain.LedgerEntry{
+		// Debit: Merchant reserved balance (unreserve)
+		{
+			DebitAccount:     s.getMerchantReservedAccount(merchantID),
+			CreditAccount:    s.getMerchantReservedAccount(merchantID), // Placeholder
+			Amount:           amountVND,
+			Currency:         "VND",
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s failed: releasing %s VND from reserve - %s", refundID, amountVND.String(), reason),
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeDebit,
+			Metadata:         database.JSONBMap{"refund_status": "failed", "failure_reason": reason},
+		},
+		// Credit: Merchant available balance (funds returned)
+		{
+			DebitAccount:     s.getMerchantReservedAccount(merchantID),
+			CreditAccount:    s.getMerchantAvailableAccount(merchantID),
+			Amount:           amountVND,
+			Currency:         "VND",
+			ReferenceType:    ledgerDomain.ReferenceTypeRefund,
+			ReferenceID:      refundID,
+			MerchantID:       sql.NullString{String: merchantID, Valid: true},
+			Description:      fmt.Sprintf("Refund %s failed: funds returned to available balance", refundID),
+			TransactionGroup: transactionGroup,
+			EntryType:        ledgerDomain.EntryTypeCredit,
+			Metadata:         database.JSONBMap{"refund_status": "failed", "failure_reason": reason},
+		},
+	}
+
+	// Start database transaction
+	tx := s.db.Begin()
+	defer tx.Rollback()
+
+	// Release reserved balance
+	if err := s.balanceRepo.ReleaseReservedBalanceTx(tx, merchantID, amountVND); err != nil {
+		return fmt.Errorf("failed to release reserved balance: %w", err)
+	}
+
+	// Create ledger entries
+	if err := s.ledgerRepo.CreateEntriesTx(tx, entries); err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // RecordOTCConversion records when we convert crypto to VND via OTC partner
 // This decreases crypto pool and increases VND pool
 //
@@ -545,7 +1442,7 @@ func (s *LedgerService) RecordOTCConversion(
 		// Debit: OTC partner receives crypto
 		{
 			DebitAccount:     "otc_partner",
-			CreditAccount:    AccountCryptoPool,
+			CreditAccount:    s.systemAccount(AccountCryptoPool),
 			Amount:           cryptoAmount,
 			Currency:         cryptoCurrency,
 			ReferenceType:    ledgerDomain.ReferenceTypeOTCConversion,
@@ -558,7 +1455,7 @@ func (s *LedgerService) RecordOTCConversion(
 		// Credit: Decrease our crypto pool
 		{
 			DebitAccount:     "otc_partner",
-			CreditAccount:    AccountCryptoPool,
+			CreditAccount:    s.systemAccount(AccountCryptoPool),
 			Amount:           cryptoAmount,
 			Currency:         cryptoCurrency,
 			ReferenceType:    ledgerDomain.ReferenceTypeOTCConversion,
@@ -574,7 +1471,7 @@ func (s *LedgerService) RecordOTCConversion(
 	vndEntries := []*ledgerDomain.LedgerEntry{
 		// Debit: Increase our VND pool
 		{
-			DebitAccount:     AccountVNDPool,
+			DebitAccount:     s.systemAccount(AccountVNDPool),
 			CreditAccount:    "otc_partner",
 			Amount:           vndAmount,
 			Currency:         "VND",
@@ -587,7 +1484,7 @@ func (s *LedgerService) RecordOTCConversion(
 		},
 		// Credit: OTC partner provides VND
 		{
-			DebitAccount:     AccountVNDPool,
+			DebitAccount:     s.systemAccount(AccountVNDPool),
 			CreditAccount:    "otc_partner",
 			Amount:           vndAmount,
 			Currency:         "VND",
@@ -653,6 +1550,26 @@ func (s *LedgerService) ValidateLedgerIntegrity() error {
 
 // Helper functions
 
+// hasRecordedEntry returns true if an entry of the reference carries the metadata value
+// Calls that may be retried use it to post their entries once
+func (s *LedgerService) hasRecordedEntry(refType ledgerDomain.ReferenceType, refID, key, value string) (bool, error) {
+	entries, err := s.ledgerRepo.GetByReference(refType, refID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+	for _, entry := range entries {
+		if recorded, ok := entry.Metadata[key].(string); ok && recorded == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// systemAccount returns the name of a platform account, prefixed in test mode
+func (s *LedgerService) systemAccount(name string) string {
+	return s.accountPrefix + name
+}
+
 func (s *LedgerService) getMerchantPendingAccount(merchantID string) string {
 	return fmt.Sprintf("%s%s", AccountMerchantPendingPrefix, merchantID)
 }
@@ -665,6 +1582,22 @@ func (s *LedgerService) getMerchantReservedAccount(merchantID string) string {
 	return fmt.Sprintf("%s%s", AccountMerchantReservedPrefix, merchantID)
 }
 
+func (s *LedgerService) getPayerRefundableAccount(paymentID string) string {
+	return fmt.Sprintf("%s%s", AccountPayerRefundablePrefix, paymentID)
+}
+
+func (s *LedgerService) getLatePaymentHeldAccount(paymentID string) string {
+	return fmt.Sprintf("%s%s", AccountLatePaymentHeldPrefix, paymentID)
+}
+
+func (s *LedgerService) getSplitClearingAccount(paymentID string) string {
+	return fmt.Sprintf("%s%s", AccountSplitClearingPrefix, paymentID)
+}
+
+func (s *LedgerService) getMerchantCommissionAccount(merchantID string) string {
+	return fmt.Sprintf("%s%s", AccountCommissionPrefix, merchantID)
+}
+
 func (s *LedgerService) validateBasicInputs(referenceID, merchantID string, amount decimal.Decimal, currency string) error {
 	if referenceID == "" {
 		return ErrLedgerInvalidReferenceID
@@ -687,8 +1620,8 @@ func (s *LedgerService) createSpreadEntry(referenceID string, spreadAmount decim
 	if spreadAmount.GreaterThan(decimal.Zero) {
 		// Positive spread = revenue (we got more VND than expected)
 		entries = append(entries, &ledgerDomain.LedgerEntry{
-			DebitAccount:     AccountVNDPool,
-			CreditAccount:    AccountOTCSpread,
+			DebitAccount:     s.systemAccount(AccountVNDPool),
+			CreditAccount:    s.systemAccount(AccountOTCSpread),
 			Amount:           spreadAmount,
 			Currency:         "VND",
 			ReferenceType:    ledgerDomain.ReferenceTypeOTCConversion,
@@ -702,8 +1635,8 @@ func (s *LedgerService) createSpreadEntry(referenceID string, spreadAmount decim
 		// Negative spread = loss (we got less VND than expected)
 		absSpread := spreadAmount.Abs()
 		entries = append(entries, &ledgerDomain.LedgerEntry{
-			DebitAccount:     AccountOTCExpense,
-			CreditAccount:    AccountVNDPool,
+			DebitAccount:     s.systemAccount(AccountOTCExpense),
+			CreditAccount:    s.systemAccount(AccountVNDPool),
 			Amount:           absSpread,
 			Currency:         "VND",
 			ReferenceType:    ledgerDomain.ReferenceTypeOTCConversion,
@@ -72,6 +72,19 @@ type Merchant struct {
 	TotalVolumeThisMonthUSD decimal.Decimal `json:"total_volume_this_month_usd" db:"total_volume_this_month_usd" validate:"gte=0"`
 	VolumeLastResetAt       time.Time       `json:"volume_last_reset_at" db:"volume_last_reset_at"`
 
+	// Payment amount tolerance (fraction of requested amount, e.g. 0.01 = 1%)
+	UnderpaymentTolerancePercentage decimal.Decimal `json:"underpayment_tolerance_percentage" db:"underpayment_tolerance_percentage" validate:"gte=0,lte=0.1"`
+	OverpaymentTolerancePercentage  decimal.Decimal `json:"overpayment_tolerance_percentage" db:"overpayment_tolerance_percentage" validate:"gte=0,lte=0.1"`
+
+	// Fraction deducted from the market rate on rate-locked quotes (e.g. 0.005 = 0.5%)
+	QuoteSpreadPercentage decimal.Decimal `json:"quote_spread_percentage" db:"quote_spread_percentage" validate:"gte=0,lte=0.05"`
+
+	// Address mode per chain ("memo" or "deposit"), chains not listed use memo matching
+	DepositAddressModes database.JSONBMap `json:"deposit_address_modes,omitempty" db:"deposit_address_modes"`
+
+	// What happens to transfers received after a payment expired ("accept", "refund" or "review")
+	LatePaymentPolicy string `json:"late_payment_policy" db:"late_payment_policy" validate:"omitempty,oneof=accept refund review"`
+
 	// API credentials
 	APIKey          sql.NullString `json:"api_key,omitempty" db:"api_key"`
 	APIKeyCreatedAt sql.NullTime   `json:"api_key_created_at,omitempty" db:"api_key_created_at"`
@@ -84,6 +97,9 @@ type Merchant struct {
 	// Status
 	Status MerchantStatus `json:"status" db:"status" validate:"required,oneof=active suspended closed"`
 
+	// Live merchant this sandbox merchant belongs to (NULL for live merchants)
+	SandboxOf sql.NullString `json:"sandbox_of,omitempty" db:"sandbox_of"`
+
 	// Metadata
 	Metadata database.JSONBMap `json:"metadata,omitempty" db:"metadata"`
 
@@ -93,6 +109,41 @@ type Merchant struct {
 	DeletedAt sql.NullTime `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// MaxPaymentTolerancePercentage caps the under/overpayment tolerance a merchant may configure
+var MaxPaymentTolerancePercentage = decimal.NewFromFloat(0.1)
+
+// MaxQuoteSpreadPercentage caps the spread a merchant may deduct from the market rate on quotes
+var MaxQuoteSpreadPercentage = decimal.NewFromFloat(0.05)
+
+// Address modes for matching incoming transfers to payments
+const (
+	AddressModeMemo    = "memo"    // Shared hot wallet, matched by memo
+	AddressModeDeposit = "deposit" // Per-payment deposit address, matched by recipient
+)
+
+// GetAddressMode returns the address mode configured for a chain, memo by default
+func (m *Merchant) GetAddressMode(chain string) string {
+	if mode, ok := m.DepositAddressModes[chain].(string); ok && mode != "" {
+		return mode
+	}
+	return AddressModeMemo
+}
+
+// Late payment policies for transfers received after a payment expired
+const (
+	LatePaymentPolicyAccept = "accept" // Complete the payment at a re-quoted exchange rate
+	LatePaymentPolicyRefund = "refund" // Send the amount back to the payer
+	LatePaymentPolicyReview = "review" // Hold the amount until an operator decides
+)
+
+// GetLatePaymentPolicy returns the merchant's late payment policy, review by default
+func (m *Merchant) GetLatePaymentPolicy() string {
+	if m.LatePaymentPolicy == "" {
+		return LatePaymentPolicyReview
+	}
+	return m.LatePaymentPolicy
+}
+
 // IsApproved returns true if the merchant's KYC is approved
 func (m *Merchant) IsApproved() bool {
 	return m.KYCStatus == KYCStatusApproved
@@ -108,6 +159,12 @@ func (m *Merchant) CanAcceptPayments() bool {
 	return m.IsApproved() && m.IsActive() && m.APIKey.Valid
 }
 
+// IsSandbox returns true if the merchant is the sandbox of a live merchant
+// Sandbox merchants authenticate with sk_test_ keys and only create test payments and payouts
+func (m *Merchant) IsSandbox() bool {
+	return m.SandboxOf.Valid && m.SandboxOf.String != ""
+}
+
 // HasWebhook returns true if the merchant has webhook configured
 func (m *Merchant) HasWebhook() bool {
 	return m.WebhookURL.Valid && m.WebhookURL.String != ""
@@ -70,7 +70,7 @@ func (r *MerchantRepository) GetByEmail(email string) (*domain.Merchant, error)
 	}
 
 	merchant := &domain.Merchant{}
-	if err := r.db.Where("email = ?", email).First(merchant).Error; err != nil {
+	if err := r.db.Where("email = ? AND sandbox_of IS NULL", email).First(merchant).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrMerchantNotFound
 		}
@@ -96,6 +96,23 @@ func (r *MerchantRepository) GetByAPIKey(apiKey string) (*domain.Merchant, error
 	return merchant, nil
 }
 
+// GetSandbox retrieves the sandbox merchant of a live merchant
+func (r *MerchantRepository) GetSandbox(merchantID string) (*domain.Merchant, error) {
+	if merchantID == "" {
+		return nil, ErrInvalidMerchantID
+	}
+
+	merchant := &domain.Merchant{}
+	if err := r.db.Where("sandbox_of = ?", merchantID).First(merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
 func (r *MerchantRepository) Update(merchant *domain.Merchant) error {
 	if merchant == nil {
 		return errors.New("merchant cannot be nil")
@@ -151,7 +168,7 @@ func (r *MerchantRepository) List(limit, offset int) ([]*domain.Merchant, error)
 	}
 
 	var merchants []*domain.Merchant
-	if err := r.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&merchants).Error; err != nil {
+	if err := r.db.Where("sandbox_of IS NULL").Order("created_at DESC").Limit(limit).Offset(offset).Find(&merchants).Error; err != nil {
 		return nil, err
 	}
 
@@ -170,7 +187,7 @@ func (r *MerchantRepository) ListByKYCStatus(status string, limit, offset int) (
 	}
 
 	var merchants []*domain.Merchant
-	if err := r.db.Where("kyc_status = ?", status).Order("kyc_submitted_at ASC").Limit(limit).Offset(offset).Find(&merchants).Error; err != nil {
+	if err := r.db.Where("kyc_status = ? AND sandbox_of IS NULL", status).Order("kyc_submitted_at ASC").Limit(limit).Offset(offset).Find(&merchants).Error; err != nil {
 		return nil, err
 	}
 
@@ -196,7 +213,7 @@ func (r *MerchantRepository) Delete(id string) error {
 
 func (r *MerchantRepository) Count() (int64, error) {
 	var count int64
-	if err := r.db.Model(&domain.Merchant{}).Count(&count).Error; err != nil {
+	if err := r.db.Model(&domain.Merchant{}).Where("sandbox_of IS NULL").Count(&count).Error; err != nil {
 		return 0, err
 	}
 
@@ -209,7 +226,7 @@ func (r *MerchantRepository) CountByKYCStatus(status string) (int64, error) {
 	}
 
 	var count int64
-	if err := r.db.Model(&domain.Merchant{}).Where("kyc_status = ?", status).Count(&count).Error; err != nil {
+	if err := r.db.Model(&domain.Merchant{}).Where("kyc_status = ? AND sandbox_of IS NULL", status).Count(&count).Error; err != nil {
 		return 0, err
 	}
 
@@ -233,6 +250,113 @@ func (r *MerchantRepository) GetMerchantKYCStatus(merchantID string) (string, er
 	return string(merchant.KYCStatus), nil
 }
 
+// GetMerchantEmail retrieves the email address of a merchant (for export module)
+func (r *MerchantRepository) GetMerchantEmail(merchantID string) (string, error) {
+	if merchantID == "" {
+		return "", ErrInvalidMerchantID
+	}
+
+	var merchant domain.Merchant
+	if err := r.db.Select("email").Where("id = ?", merchantID).First(&merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrMerchantNotFound
+		}
+		return "", err
+	}
+
+	return merchant.Email, nil
+}
+
+// GetMerchantPaymentTolerance retrieves the merchant's under/overpayment tolerance (for payment module)
+func (r *MerchantRepository) GetMerchantPaymentTolerance(merchantID string) (decimal.Decimal, decimal.Decimal, error) {
+	if merchantID == "" {
+		return decimal.Zero, decimal.Zero, ErrInvalidMerchantID
+	}
+
+	var merchant domain.Merchant
+	if err := r.db.Select("underpayment_tolerance_percentage", "overpayment_tolerance_percentage").
+		Where("id = ?", merchantID).First(&merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return decimal.Zero, decimal.Zero, ErrMerchantNotFound
+		}
+		return decimal.Zero, decimal.Zero, err
+	}
+
+	return merchant.UnderpaymentTolerancePercentage, merchant.OverpaymentTolerancePercentage, nil
+}
+
+// GetMerchantAddressMode retrieves the merchant's address mode for a chain (for payment module)
+func (r *MerchantRepository) GetMerchantAddressMode(merchantID, chain string) (string, error) {
+	if merchantID == "" {
+		return "", ErrInvalidMerchantID
+	}
+
+	var merchant domain.Merchant
+	if err := r.db.Select("deposit_address_modes").
+		Where("id = ?", merchantID).First(&merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrMerchantNotFound
+		}
+		return "", err
+	}
+
+	return merchant.GetAddressMode(chain), nil
+}
+
+// GetMerchantLatePaymentPolicy retrieves the merchant's late payment policy (for payment module)
+func (r *MerchantRepository) GetMerchantLatePaymentPolicy(merchantID string) (string, error) {
+	if merchantID == "" {
+		return "", ErrInvalidMerchantID
+	}
+
+	var merchant domain.Merchant
+	if err := r.db.Select("late_payment_policy").
+		Where("id = ?", merchantID).First(&merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrMerchantNotFound
+		}
+		return "", err
+	}
+
+	return merchant.GetLatePaymentPolicy(), nil
+}
+
+// GetMerchantQuoteSpread retrieves the spread deducted from the market rate on quotes (for payment module)
+func (r *MerchantRepository) GetMerchantQuoteSpread(merchantID string) (decimal.Decimal, error) {
+	if merchantID == "" {
+		return decimal.Zero, ErrInvalidMerchantID
+	}
+
+	var merchant domain.Merchant
+	if err := r.db.Select("quote_spread_percentage").
+		Where("id = ?", merchantID).First(&merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return decimal.Zero, ErrMerchantNotFound
+		}
+		return decimal.Zero, err
+	}
+
+	return merchant.QuoteSpreadPercentage, nil
+}
+
+// IsSandboxMerchant reports whether the merchant is a sandbox merchant (for payment module)
+func (r *MerchantRepository) IsSandboxMerchant(merchantID string) (bool, error) {
+	if merchantID == "" {
+		return false, ErrInvalidMerchantID
+	}
+
+	var merchant domain.Merchant
+	if err := r.db.Select("sandbox_of").
+		Where("id = ?", merchantID).First(&merchant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrMerchantNotFound
+		}
+		return false, err
+	}
+
+	return merchant.IsSandbox(), nil
+}
+
 // UpdateMerchantVolume updates the merchant's monthly volume (for payment module)
 func (r *MerchantRepository) UpdateMerchantVolume(merchantID string, amountUSD decimal.Decimal) error {
 	if merchantID == "" {
@@ -9,6 +9,7 @@ type Repository interface {
 	GetByID(id string) (*domain.Merchant, error)
 	GetByEmail(email string) (*domain.Merchant, error)
 	GetByAPIKey(apiKey string) (*domain.Merchant, error)
+	GetSandbox(merchantID string) (*domain.Merchant, error)
 	Update(merchant *domain.Merchant) error
 	List(limit, offset int) ([]*domain.Merchant, error)
 	ListByKYCStatus(status string, limit, offset int) ([]*domain.Merchant, error)
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/domain"
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/repository"
@@ -24,6 +25,11 @@ var (
 	ErrInvalidEmail           = errors.New("invalid email address")
 	ErrInvalidBusinessName    = errors.New("invalid business name")
 	ErrAPIKeyGenerationFailed = errors.New("failed to generate API key")
+	ErrInvalidTolerance       = errors.New("payment tolerance must be between 0 and 0.1")
+	ErrInvalidAddressMode     = errors.New("address mode must be memo or deposit")
+	ErrInvalidLatePolicy      = errors.New("late payment policy must be accept, refund or review")
+	ErrInvalidQuoteSpread     = errors.New("quote spread must be between 0 and 0.05")
+	ErrSandboxMerchant        = errors.New("operation not available for sandbox merchants")
 )
 
 // MerchantService handles business logic for merchant management
@@ -83,6 +89,8 @@ func (s *MerchantService) RegisterMerchant(input RegisterMerchantRequest) (*doma
 		Status:        domain.MerchantStatusActive,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
+
+		LatePaymentPolicy: domain.LatePaymentPolicyReview,
 	}
 
 	// Set optional fields
@@ -262,6 +270,100 @@ func (s *MerchantService) GenerateAPIKey() (string, error) {
 	return apiKey, nil
 }
 
+// GenerateTestAPIKey generates a secure random API key of a sandbox merchant
+// Format: sk_test_<64 random base64 characters>
+func (s *MerchantService) GenerateTestAPIKey() (string, error) {
+	randomBytes := make([]byte, 48)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrAPIKeyGenerationFailed, err)
+	}
+
+	return fmt.Sprintf("sk_test_%s", base64.URLEncoding.EncodeToString(randomBytes)), nil
+}
+
+// CreateSandbox creates the sandbox merchant of an approved live merchant and issues its sk_test_ API key
+// The sandbox copies the live merchant's payment settings and webhook configuration, calling it again
+// refreshes them and rotates the test key
+func (s *MerchantService) CreateSandbox(merchantID string) (*domain.Merchant, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+
+	merchant, err := s.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		if err == repository.ErrMerchantNotFound {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	if merchant.IsSandbox() {
+		return nil, ErrSandboxMerchant
+	}
+	if merchant.KYCStatus != domain.KYCStatusApproved {
+		return nil, ErrMerchantNotApproved
+	}
+
+	sandbox, err := s.merchantRepo.GetSandbox(merchant.ID)
+	if err != nil && err != repository.ErrMerchantNotFound {
+		return nil, fmt.Errorf("failed to get sandbox merchant: %w", err)
+	}
+	exists := sandbox != nil
+
+	if !exists {
+		sandbox = &domain.Merchant{
+			ID:                uuid.New().String(),
+			Email:             merchant.Email,
+			BusinessName:      merchant.BusinessName,
+			OwnerFullName:     merchant.OwnerFullName,
+			KYCStatus:         domain.KYCStatusApproved,
+			KYCSubmittedAt:    merchant.KYCSubmittedAt,
+			KYCApprovedAt:     merchant.KYCApprovedAt,
+			KYCApprovedBy:     merchant.KYCApprovedBy,
+			Status:            domain.MerchantStatusActive,
+			SandboxOf:         sql.NullString{String: merchant.ID, Valid: true},
+			VolumeLastResetAt: time.Now(),
+		}
+	}
+
+	apiKey, err := s.GenerateTestAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate test API key: %w", err)
+	}
+
+	// Test payments behave like the live merchant's, so its settings are copied over
+	now := time.Now()
+	sandbox.KYCTier = merchant.KYCTier
+	sandbox.MonthlyLimitUSD = merchant.MonthlyLimitUSD
+	sandbox.UnderpaymentTolerancePercentage = merchant.UnderpaymentTolerancePercentage
+	sandbox.OverpaymentTolerancePercentage = merchant.OverpaymentTolerancePercentage
+	sandbox.QuoteSpreadPercentage = merchant.QuoteSpreadPercentage
+	sandbox.DepositAddressModes = merchant.DepositAddressModes
+	sandbox.LatePaymentPolicy = merchant.GetLatePaymentPolicy()
+	sandbox.BankAccountName = merchant.BankAccountName
+	sandbox.BankAccountNumber = merchant.BankAccountNumber
+	sandbox.BankName = merchant.BankName
+	sandbox.BankBranch = merchant.BankBranch
+	sandbox.WebhookURL = merchant.WebhookURL
+	sandbox.WebhookSecret = merchant.WebhookSecret
+	sandbox.WebhookEvents = merchant.WebhookEvents
+	sandbox.APIKey = sql.NullString{String: apiKey, Valid: true}
+	sandbox.APIKeyCreatedAt = sql.NullTime{Time: now, Valid: true}
+	sandbox.UpdatedAt = now
+
+	if exists {
+		err = s.merchantRepo.Update(sandbox)
+	} else {
+		err = s.merchantRepo.Create(sandbox)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save sandbox merchant: %w", err)
+	}
+
+	return sandbox, nil
+}
+
 // RotateAPIKey generates a new API key for a merchant
 func (s *MerchantService) RotateAPIKey(merchantID string) (string, error) {
 	if merchantID == "" {
@@ -282,8 +384,13 @@ func (s *MerchantService) RotateAPIKey(merchantID string) (string, error) {
 		return "", ErrMerchantNotApproved
 	}
 
-	// Generate new API key
-	newAPIKey, err := s.GenerateAPIKey()
+	// Generate new API key, sandbox merchants keep test keys
+	var newAPIKey string
+	if merchant.IsSandbox() {
+		newAPIKey, err = s.GenerateTestAPIKey()
+	} else {
+		newAPIKey, err = s.GenerateAPIKey()
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to generate new API key: %w", err)
 	}
@@ -416,6 +523,131 @@ func (s *MerchantService) UpdateWebhookConfig(merchantID, webhookURL, webhookSec
 	return nil
 }
 
+// UpdatePaymentTolerance updates the fraction of the requested amount a payment may be
+// under- or overpaid by and still be treated as an exact payment
+func (s *MerchantService) UpdatePaymentTolerance(merchantID string, underpayment, overpayment decimal.Decimal) (*domain.Merchant, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if underpayment.IsNegative() || underpayment.GreaterThan(domain.MaxPaymentTolerancePercentage) ||
+		overpayment.IsNegative() || overpayment.GreaterThan(domain.MaxPaymentTolerancePercentage) {
+		return nil, ErrInvalidTolerance
+	}
+
+	// Get merchant
+	merchant, err := s.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		if err == repository.ErrMerchantNotFound {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	merchant.UnderpaymentTolerancePercentage = underpayment
+	merchant.OverpaymentTolerancePercentage = overpayment
+	merchant.UpdatedAt = time.Now()
+
+	// Save to database
+	if err := s.merchantRepo.Update(merchant); err != nil {
+		return nil, fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	return merchant, nil
+}
+
+// UpdateQuoteSpread updates the fraction deducted from the market rate when the merchant
+// requests a rate-locked quote, covering the rate risk while the quote is valid
+func (s *MerchantService) UpdateQuoteSpread(merchantID string, spread decimal.Decimal) (*domain.Merchant, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if spread.IsNegative() || spread.GreaterThan(domain.MaxQuoteSpreadPercentage) {
+		return nil, ErrInvalidQuoteSpread
+	}
+
+	// Get merchant
+	merchant, err := s.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		if err == repository.ErrMerchantNotFound {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	merchant.QuoteSpreadPercentage = spread
+	merchant.UpdatedAt = time.Now()
+
+	// Save to database
+	if err := s.merchantRepo.Update(merchant); err != nil {
+		return nil, fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	return merchant, nil
+}
+
+// UpdateAddressMode selects how payments on a chain are matched: through the memo on the
+// shared hot wallet, or through a deposit address generated for each payment
+func (s *MerchantService) UpdateAddressMode(merchantID, chain, mode string) (*domain.Merchant, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if mode != domain.AddressModeMemo && mode != domain.AddressModeDeposit {
+		return nil, ErrInvalidAddressMode
+	}
+
+	// Get merchant
+	merchant, err := s.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		if err == repository.ErrMerchantNotFound {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	if merchant.DepositAddressModes == nil {
+		merchant.DepositAddressModes = make(map[string]interface{})
+	}
+	merchant.DepositAddressModes[chain] = mode
+	merchant.UpdatedAt = time.Now()
+
+	// Save to database
+	if err := s.merchantRepo.Update(merchant); err != nil {
+		return nil, fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	return merchant, nil
+}
+
+// UpdateLatePaymentPolicy selects what happens to transfers received after a payment expired:
+// accept them at a re-quoted exchange rate, refund them to the payer, or hold them for review
+func (s *MerchantService) UpdateLatePaymentPolicy(merchantID, policy string) (*domain.Merchant, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if policy != domain.LatePaymentPolicyAccept && policy != domain.LatePaymentPolicyRefund && policy != domain.LatePaymentPolicyReview {
+		return nil, ErrInvalidLatePolicy
+	}
+
+	// Get merchant
+	merchant, err := s.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		if err == repository.ErrMerchantNotFound {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	merchant.LatePaymentPolicy = policy
+	merchant.UpdatedAt = time.Now()
+
+	// Save to database
+	if err := s.merchantRepo.Update(merchant); err != nil {
+		return nil, fmt.Errorf("failed to update merchant: %w", err)
+	}
+
+	return merchant, nil
+}
+
 // SuspendMerchant suspends a merchant account
 func (s *MerchantService) SuspendMerchant(merchantID string, reason string) error {
 	if merchantID == "" {
@@ -25,6 +25,14 @@ const (
 	WebhookEventPaymentFailed WebhookEvent = "payment.failed"
 	// WebhookEventPayoutCompleted is sent when a payout is processed
 	WebhookEventPayoutCompleted WebhookEvent = "payout.completed"
+	// WebhookEventRefundCreated is sent when a refund is requested
+	WebhookEventRefundCreated WebhookEvent = "refund.created"
+	// WebhookEventRefundSubmitted is sent when a refund transaction is broadcast
+	WebhookEventRefundSubmitted WebhookEvent = "refund.submitted"
+	// WebhookEventRefundCompleted is sent when a refund transaction is finalized
+	WebhookEventRefundCompleted WebhookEvent = "refund.completed"
+	// WebhookEventRefundFailed is sent when a refund could not be sent
+	WebhookEventRefundFailed WebhookEvent = "refund.failed"
 )
 
 // WebhookPayload represents the structure of webhook data sent to merchants
@@ -260,6 +268,7 @@ const (
 	EmailTypeDailySettlement     EmailType = "daily_settlement"
 	EmailTypeKYCApproved         EmailType = "kyc_approved"
 	EmailTypeKYCRejected         EmailType = "kyc_rejected"
+	EmailTypeExportReady         EmailType = "export_ready"
 )
 
 // EmailData represents data for email templates
@@ -323,6 +332,17 @@ func (s *NotificationService) SendPayoutCompletedEmail(ctx context.Context, merc
 	return s.SendEmail(ctx, EmailTypePayoutCompleted, merchantEmail, data)
 }
 
+// SendExportReadyEmail sends email with the download link of a finished export
+func (s *NotificationService) SendExportReadyEmail(ctx context.Context, merchantEmail string, exportID string, fileName string, downloadURL string) error {
+	data := map[string]interface{}{
+		"export_id":    exportID,
+		"file_name":    fileName,
+		"download_url": downloadURL,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}
+	return s.SendEmail(ctx, EmailTypeExportReady, merchantEmail, data)
+}
+
 // SendDailySettlementReport sends daily settlement report to ops team
 func (s *NotificationService) SendDailySettlementReport(ctx context.Context, opsEmail string, reportData map[string]interface{}) error {
 	return s.SendEmail(ctx, EmailTypeDailySettlement, opsEmail, reportData)
@@ -0,0 +1,397 @@
+package blockchain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	solanasdk "github.com/gagliardetto/solana-go"
+	"github.com/shopspring/decimal"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/bsc"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/solana"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/hdwallet"
+)
+
+const (
+	// bip44ExternalChain is the BIP-44 change level used for receiving addresses
+	bip44ExternalChain = 0
+	// bscGasTopUpRetries bounds how long a sweep waits for its gas top-ups (~3s per retry)
+	bscGasTopUpRetries = 40
+)
+
+// DepositAddressGenerator creates per-payment deposit addresses
+// BSC addresses are derived from the deposit account xpub (m/44'/60'/0') at 0/index,
+// Solana deposits are token accounts created from the hot wallet with the payment ID as seed,
+// their address is derived when the payment is created and the account is provisioned by the worker
+// Implements domain.DepositAddressGenerator
+type DepositAddressGenerator struct {
+	depositRepo      domain.DepositAddressRepository
+	solanaWallet     *solana.Wallet
+	solanaTokenMints map[string]string     // currency -> SPL mint address
+	bscExternalKey   *hdwallet.ExtendedKey // m/44'/60'/0'/0, public
+}
+
+// DepositAddressGeneratorConfig holds the keys used to generate deposit addresses
+// A chain without keys does not support deposit addresses
+type DepositAddressGeneratorConfig struct {
+	SolanaWallet     *solana.Wallet
+	SolanaTokenMints map[string]string
+	BSCAccountXPub   string // Account-level extended public key (m/44'/60'/0')
+}
+
+// NewDepositAddressGenerator creates a new deposit address generator
+func NewDepositAddressGenerator(depositRepo domain.DepositAddressRepository, config DepositAddressGeneratorConfig) (*DepositAddressGenerator, error) {
+	generator := &DepositAddressGenerator{
+		depositRepo:      depositRepo,
+		solanaWallet:     config.SolanaWallet,
+		solanaTokenMints: config.SolanaTokenMints,
+	}
+
+	if config.BSCAccountXPub != "" {
+		accountKey, err := hdwallet.ParseExtendedKey(config.BSCAccountXPub)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bsc deposit xpub: %w", err)
+		}
+		externalKey, err := accountKey.Child(bip44ExternalChain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive bsc external chain: %w", err)
+		}
+		generator.bscExternalKey = externalKey
+	}
+
+	return generator, nil
+}
+
+// GenerateDepositAddress returns a new deposit address for the payment
+func (g *DepositAddressGenerator) GenerateDepositAddress(ctx context.Context, paymentID, merchantID string, chain domain.Chain, currency string) (*domain.DepositAddress, error) {
+	address := &domain.DepositAddress{
+		PaymentID:  paymentID,
+		MerchantID: merchantID,
+		Chain:      chain,
+		Currency:   currency,
+		Status:     domain.DepositAddressStatusActive,
+	}
+
+	switch chain {
+	case domain.ChainBSC:
+		if g.bscExternalKey == nil {
+			return nil, domain.ErrDepositAddressNotSupported
+		}
+
+		index, err := g.depositRepo.NextDerivationIndex()
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve derivation index: %w", err)
+		}
+		childKey, err := g.bscExternalKey.Child(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive deposit key %d: %w", index, err)
+		}
+		childAddress, err := childKey.Address()
+		if err != nil {
+			return nil, err
+		}
+
+		address.Address = childAddress.Hex()
+		address.DerivationIndex = sql.NullInt64{Int64: int64(index), Valid: true}
+
+	case domain.ChainSolana:
+		if g.solanaWallet == nil {
+			return nil, domain.ErrDepositAddressNotSupported
+		}
+
+		mint, ok := g.solanaTokenMints[currency]
+		if !ok || mint == "" {
+			return nil, fmt.Errorf("no solana mint configured for %s", currency)
+		}
+
+		// Seeds are limited to 32 characters, a UUID without dashes fits exactly
+		seed := strings.ReplaceAll(paymentID, "-", "")
+		account, err := g.solanaWallet.DepositTokenAccountAddress(seed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive deposit token account: %w", err)
+		}
+
+		// The token account is created by ProvisionDepositAddress, payers cannot send to it before
+		address.Address = account.String()
+		address.Seed = sql.NullString{String: seed, Valid: true}
+		address.Status = domain.DepositAddressStatusPending
+
+	default:
+		return nil, domain.ErrDepositAddressNotSupported
+	}
+
+	return address, nil
+}
+
+// ProvisionDepositAddress creates the token account of a pending Solana deposit address and waits until it is finalized
+// Addresses of other chains need no account and are left alone
+func (g *DepositAddressGenerator) ProvisionDepositAddress(ctx context.Context, address *domain.DepositAddress) error {
+	if address.Chain != domain.ChainSolana {
+		return nil
+	}
+	if g.solanaWallet == nil {
+		return domain.ErrDepositAddressNotSupported
+	}
+	if !address.Seed.Valid {
+		return fmt.Errorf("deposit address %s has no seed", address.Address)
+	}
+
+	mint, ok := g.solanaTokenMints[address.Currency]
+	if !ok || mint == "" {
+		return fmt.Errorf("no solana mint configured for %s", address.Currency)
+	}
+
+	// Guard against a hot wallet that is not the one the address was derived from
+	account, err := g.solanaWallet.DepositTokenAccountAddress(address.Seed.String)
+	if err != nil {
+		return fmt.Errorf("failed to derive deposit token account: %w", err)
+	}
+	if account.String() != address.Address {
+		return fmt.Errorf("derived account %s does not match deposit address %s", account.String(), address.Address)
+	}
+
+	if _, _, err := g.solanaWallet.CreateDepositTokenAccount(ctx, address.Seed.String, mint); err != nil {
+		return fmt.Errorf("failed to create deposit token account: %w", err)
+	}
+
+	return nil
+}
+
+// DepositSweeper consolidates deposit address funds into the platform hot wallets
+// Implements domain.DepositSweeper
+type DepositSweeper struct {
+	solanaWallet      *solana.Wallet
+	bscWallet         *bsc.Wallet
+	solanaTokenMints  map[string]string     // currency -> SPL mint address
+	bscTokenContracts map[string]string     // currency -> BEP20 contract address
+	bscExternalKey    *hdwallet.ExtendedKey // m/44'/60'/0'/0, private
+}
+
+// DepositSweeperConfig holds the wallets and keys used to sweep deposit addresses
+// BSC sweeps need the account xprv matching the xpub used to generate the addresses
+type DepositSweeperConfig struct {
+	SolanaWallet      *solana.Wallet
+	BSCWallet         *bsc.Wallet
+	SolanaTokenMints  map[string]string
+	BSCTokenContracts map[string]string
+	BSCAccountXPrv    string // Account-level extended private key (m/44'/60'/0')
+}
+
+// NewDepositSweeper creates a new deposit sweeper
+func NewDepositSweeper(config DepositSweeperConfig) (*DepositSweeper, error) {
+	sweeper := &DepositSweeper{
+		solanaWallet:      config.SolanaWallet,
+		bscWallet:         config.BSCWallet,
+		solanaTokenMints:  config.SolanaTokenMints,
+		bscTokenContracts: config.BSCTokenContracts,
+	}
+
+	if config.BSCAccountXPrv != "" {
+		accountKey, err := hdwallet.ParseExtendedKey(config.BSCAccountXPrv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bsc deposit xprv: %w", err)
+		}
+		if !accountKey.IsPrivate() {
+			return nil, fmt.Errorf("bsc deposit xprv is a public key")
+		}
+		externalKey, err := accountKey.Child(bip44ExternalChain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive bsc external chain: %w", err)
+		}
+		sweeper.bscExternalKey = externalKey
+	}
+
+	return sweeper, nil
+}
+
+// PrepareSweep sends the gas of the BSC sweep transfers from the hot wallet and waits for the top-ups
+// All top-ups are broadcast before the first wait, the batch waits about one block instead of one per address
+// Solana sweeps are paid by the hot wallet and need no preparation
+func (s *DepositSweeper) PrepareSweep(ctx context.Context, chain domain.Chain, addresses []*domain.DepositAddress) error {
+	if chain != domain.ChainBSC {
+		return nil
+	}
+	if s.bscWallet == nil || s.bscExternalKey == nil {
+		return domain.ErrDepositAddressNotSupported
+	}
+
+	var topUps []common.Hash
+	for _, address := range addresses {
+		txHash, err := s.topUpBSCGas(ctx, address)
+		if err != nil {
+			// The sweep of the address reports the missing gas
+			continue
+		}
+		if txHash != (common.Hash{}) {
+			topUps = append(topUps, txHash)
+		}
+	}
+
+	for _, txHash := range topUps {
+		if err := s.bscWallet.GetClient().WaitForConfirmation(ctx, txHash, 1, bscGasTopUpRetries); err != nil {
+			return fmt.Errorf("gas top-up %s not confirmed: %w", txHash.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+// SweepDepositAddress moves the balance of the deposit address to the hot wallet
+func (s *DepositSweeper) SweepDepositAddress(ctx context.Context, address *domain.DepositAddress) (string, decimal.Decimal, error) {
+	switch address.Chain {
+	case domain.ChainSolana:
+		return s.sweepSolana(ctx, address)
+	case domain.ChainBSC:
+		return s.sweepBSC(ctx, address)
+	default:
+		return "", decimal.Zero, domain.ErrDepositAddressNotSupported
+	}
+}
+
+func (s *DepositSweeper) sweepSolana(ctx context.Context, address *domain.DepositAddress) (string, decimal.Decimal, error) {
+	if s.solanaWallet == nil {
+		return "", decimal.Zero, domain.ErrDepositAddressNotSupported
+	}
+
+	mint, ok := s.solanaTokenMints[address.Currency]
+	if !ok || mint == "" {
+		return "", decimal.Zero, fmt.Errorf("no solana mint configured for %s", address.Currency)
+	}
+
+	account, err := solanasdk.PublicKeyFromBase58(address.Address)
+	if err != nil {
+		return "", decimal.Zero, fmt.Errorf("invalid solana deposit address: %w", err)
+	}
+
+	// Also closes the token account so its rent returns to the hot wallet
+	signature, amount, err := s.solanaWallet.SweepDepositTokenAccount(ctx, account, mint)
+	if err != nil {
+		return "", decimal.Zero, err
+	}
+
+	return signature.String(), amount, nil
+}
+
+func (s *DepositSweeper) sweepBSC(ctx context.Context, address *domain.DepositAddress) (string, decimal.Decimal, error) {
+	if s.bscWallet == nil || s.bscExternalKey == nil {
+		return "", decimal.Zero, domain.ErrDepositAddressNotSupported
+	}
+
+	depositWallet, tokenContract, balance, err := s.loadBSCDeposit(ctx, address)
+	if err != nil {
+		return "", decimal.Zero, err
+	}
+	if !balance.IsPositive() {
+		return "", decimal.Zero, nil
+	}
+
+	// The gas was sent by PrepareSweep, checked here so a missing top-up is reported as such
+	fee, err := depositWallet.EstimateBEP20TransferFee(ctx, tokenContract, s.bscWallet.GetCommonAddress(), balance)
+	if err != nil {
+		return "", decimal.Zero, err
+	}
+	bnbBalance, err := depositWallet.GetBalance(ctx)
+	if err != nil {
+		return "", decimal.Zero, fmt.Errorf("failed to get deposit gas balance: %w", err)
+	}
+	if bnbBalance.LessThan(fee) {
+		return "", decimal.Zero, fmt.Errorf("deposit address holds %s BNB, its sweep needs %s", bnbBalance.String(), fee.String())
+	}
+
+	txHash, err := depositWallet.SendBEP20Transfer(ctx, tokenContract, s.bscWallet.GetCommonAddress(), balance)
+	if err != nil {
+		return "", decimal.Zero, err
+	}
+
+	return txHash.Hex(), balance, nil
+}
+
+// loadBSCDeposit returns the signing wallet, token contract and token balance of a BSC deposit address
+func (s *DepositSweeper) loadBSCDeposit(ctx context.Context, address *domain.DepositAddress) (*bsc.Wallet, common.Address, decimal.Decimal, error) {
+	if !address.DerivationIndex.Valid {
+		return nil, common.Address{}, decimal.Zero, fmt.Errorf("deposit address %s has no derivation index", address.Address)
+	}
+
+	contract, ok := s.bscTokenContracts[address.Currency]
+	if !ok || contract == "" {
+		return nil, common.Address{}, decimal.Zero, fmt.Errorf("no bsc token contract configured for %s", address.Currency)
+	}
+	tokenContract, err := bsc.ParseAddress(contract)
+	if err != nil {
+		return nil, common.Address{}, decimal.Zero, err
+	}
+
+	depositWallet, err := s.loadBSCDepositWallet(address)
+	if err != nil {
+		return nil, common.Address{}, decimal.Zero, err
+	}
+
+	balance, _, err := depositWallet.GetBEP20Balance(ctx, tokenContract)
+	if err != nil {
+		return nil, common.Address{}, decimal.Zero, fmt.Errorf("failed to get deposit balance: %w", err)
+	}
+
+	return depositWallet, tokenContract, balance, nil
+}
+
+// loadBSCDepositWallet derives the signing wallet of a BSC deposit address
+func (s *DepositSweeper) loadBSCDepositWallet(address *domain.DepositAddress) (*bsc.Wallet, error) {
+	childKey, err := s.bscExternalKey.Child(uint32(address.DerivationIndex.Int64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive deposit key: %w", err)
+	}
+	privateKey, err := childKey.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	depositWallet, err := bsc.LoadWalletWithClient(hex.EncodeToString(crypto.FromECDSA(privateKey)), s.bscWallet.GetClient())
+	if err != nil {
+		return nil, err
+	}
+
+	// Guard against an xprv that does not match the xpub the address was generated from
+	if !strings.EqualFold(depositWallet.GetAddress(), address.Address) {
+		return nil, fmt.Errorf("derived address %s does not match deposit address %s", depositWallet.GetAddress(), address.Address)
+	}
+
+	return depositWallet, nil
+}
+
+// topUpBSCGas sends the BNB the deposit address is missing for its sweep transfer from the hot wallet
+// The fee is estimated like the sweep transfer itself. Returns an empty hash if nothing had to be sent
+func (s *DepositSweeper) topUpBSCGas(ctx context.Context, address *domain.DepositAddress) (common.Hash, error) {
+	depositWallet, tokenContract, balance, err := s.loadBSCDeposit(ctx, address)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !balance.IsPositive() {
+		return common.Hash{}, nil
+	}
+
+	fee, err := depositWallet.EstimateBEP20TransferFee(ctx, tokenContract, s.bscWallet.GetCommonAddress(), balance)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	bnbBalance, err := depositWallet.GetBalance(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get deposit gas balance: %w", err)
+	}
+	if bnbBalance.GreaterThanOrEqual(fee) {
+		return common.Hash{}, nil
+	}
+
+	txHash, err := s.bscWallet.SendBNB(ctx, depositWallet.GetCommonAddress(), fee.Sub(bnbBalance))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send gas top-up: %w", err)
+	}
+
+	return txHash, nil
+}
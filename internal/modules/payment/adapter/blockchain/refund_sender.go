@@ -0,0 +1,191 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	solanasdk "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/shopspring/decimal"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/bsc"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/solana"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// RefundSender sends refunds from the platform hot wallets
+// Implements domain.RefundSender
+type RefundSender struct {
+	solanaWallet      *solana.Wallet
+	bscWallet         *bsc.Wallet
+	solanaTokenMints  map[string]string // currency -> SPL mint address
+	bscTokenContracts map[string]string // currency -> BEP20 contract address
+}
+
+// RefundSenderConfig holds the wallets and token addresses used for refunds
+// A chain without a wallet cannot be refunded
+type RefundSenderConfig struct {
+	SolanaWallet      *solana.Wallet
+	BSCWallet         *bsc.Wallet
+	SolanaTokenMints  map[string]string
+	BSCTokenContracts map[string]string
+}
+
+// NewRefundSender creates a new refund sender
+func NewRefundSender(config RefundSenderConfig) *RefundSender {
+	return &RefundSender{
+		solanaWallet:      config.SolanaWallet,
+		bscWallet:         config.BSCWallet,
+		solanaTokenMints:  config.SolanaTokenMints,
+		bscTokenContracts: config.BSCTokenContracts,
+	}
+}
+
+// SignRefund builds and signs the transfer of amount of currency to toAddress without broadcasting it
+func (s *RefundSender) SignRefund(ctx context.Context, chain domain.Chain, currency, toAddress string, amount decimal.Decimal) (*domain.SignedRefundTx, error) {
+	switch chain {
+	case domain.ChainSolana:
+		return s.signSolanaRefund(ctx, currency, toAddress, amount)
+	case domain.ChainBSC:
+		return s.signBSCRefund(ctx, currency, toAddress, amount)
+	default:
+		return nil, fmt.Errorf("%w: refunds not supported on %s", domain.ErrInvalidChain, chain)
+	}
+}
+
+// BroadcastRefund sends a refund transaction signed by SignRefund
+func (s *RefundSender) BroadcastRefund(ctx context.Context, signed *domain.SignedRefundTx) error {
+	switch tx := signed.Tx.(type) {
+	case *solanasdk.Transaction:
+		if s.solanaWallet == nil {
+			return domain.ErrRefundSenderNotConfigured
+		}
+		_, err := s.solanaWallet.SendSignedTransaction(ctx, tx)
+		return err
+	case *types.Transaction:
+		if s.bscWallet == nil {
+			return domain.ErrRefundSenderNotConfigured
+		}
+		return s.bscWallet.SendSignedTransaction(ctx, tx)
+	default:
+		return fmt.Errorf("unexpected %s refund transaction type %T", signed.Chain, signed.Tx)
+	}
+}
+
+// GetRefundTxStatus maps the on-chain state of a refund transaction to a refund status
+// Returns RefundStatusSubmitted while the transaction is not yet final
+func (s *RefundSender) GetRefundTxStatus(ctx context.Context, chain domain.Chain, txHash string) (domain.RefundStatus, error) {
+	switch chain {
+	case domain.ChainSolana:
+		if s.solanaWallet == nil {
+			return "", domain.ErrRefundSenderNotConfigured
+		}
+		signature, err := solanasdk.SignatureFromBase58(txHash)
+		if err != nil {
+			return "", fmt.Errorf("invalid solana signature: %w", err)
+		}
+		status, err := s.solanaWallet.GetTransactionStatus(ctx, signature)
+		if err != nil {
+			return "", err
+		}
+		if status == nil {
+			return "", domain.ErrRefundTxNotFound
+		}
+		if status.Err != nil {
+			return domain.RefundStatusFailed, nil
+		}
+		if status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+			return domain.RefundStatusCompleted, nil
+		}
+		return domain.RefundStatusSubmitted, nil
+
+	case domain.ChainBSC:
+		if s.bscWallet == nil {
+			return "", domain.ErrRefundSenderNotConfigured
+		}
+		info, err := s.bscWallet.GetClient().GetTransaction(ctx, common.HexToHash(txHash))
+		if errors.Is(err, ethereum.NotFound) {
+			return "", domain.ErrRefundTxNotFound
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get bsc transaction: %w", err)
+		}
+		if info.Error != nil {
+			return domain.RefundStatusFailed, nil
+		}
+		if info.IsFinalized {
+			return domain.RefundStatusCompleted, nil
+		}
+		return domain.RefundStatusSubmitted, nil
+
+	default:
+		return "", fmt.Errorf("%w: refunds not supported on %s", domain.ErrInvalidChain, chain)
+	}
+}
+
+func (s *RefundSender) signSolanaRefund(ctx context.Context, currency, toAddress string, amount decimal.Decimal) (*domain.SignedRefundTx, error) {
+	if s.solanaWallet == nil {
+		return nil, domain.ErrRefundSenderNotConfigured
+	}
+
+	mint, ok := s.solanaTokenMints[currency]
+	if !ok || mint == "" {
+		return nil, fmt.Errorf("no solana mint configured for %s", currency)
+	}
+
+	recipient, err := solanasdk.PublicKeyFromBase58(toAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid solana address: %w", err)
+	}
+
+	tx, err := s.solanaWallet.CreateTokenTransferTransaction(ctx, mint, recipient, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := s.solanaWallet.PrepareTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SignedRefundTx{
+		Chain:  domain.ChainSolana,
+		TxHash: signature.String(),
+		Tx:     tx,
+	}, nil
+}
+
+func (s *RefundSender) signBSCRefund(ctx context.Context, currency, toAddress string, amount decimal.Decimal) (*domain.SignedRefundTx, error) {
+	if s.bscWallet == nil {
+		return nil, domain.ErrRefundSenderNotConfigured
+	}
+
+	contract, ok := s.bscTokenContracts[currency]
+	if !ok || contract == "" {
+		return nil, fmt.Errorf("no bsc token contract configured for %s", currency)
+	}
+
+	tokenContract, err := bsc.ParseAddress(contract)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := bsc.ParseAddress(toAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.bscWallet.SignBEP20Transfer(ctx, tokenContract, recipient, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SignedRefundTx{
+		Chain:  domain.ChainBSC,
+		TxHash: tx.Hash().Hex(),
+		Tx:     tx,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+
+	solanasdk "github.com/gagliardetto/solana-go"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/solana"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// SolanaPayTransactionBuilder composes Solana Pay transactions paying into the hot wallet
+// Payments with a deposit address are paid straight into their deposit token account
+// Implements domain.SolanaPayTransactionBuilder
+type SolanaPayTransactionBuilder struct {
+	client *solana.Client
+	wallet *solana.Wallet
+}
+
+// NewSolanaPayTransactionBuilder creates a new Solana Pay transaction builder
+func NewSolanaPayTransactionBuilder(client *solana.Client, wallet *solana.Wallet) *SolanaPayTransactionBuilder {
+	return &SolanaPayTransactionBuilder{
+		client: client,
+		wallet: wallet,
+	}
+}
+
+// ReferenceKey returns the reference key derived from the payment ID
+func (b *SolanaPayTransactionBuilder) ReferenceKey(paymentID string) string {
+	return solana.PaymentReferenceKey(paymentID).String()
+}
+
+// BuildTransferTransaction returns the unsigned transaction of the transfer in base64
+func (b *SolanaPayTransactionBuilder) BuildTransferTransaction(ctx context.Context, transfer domain.SolanaPayTransfer) (string, error) {
+	payer, err := solanasdk.PublicKeyFromBase58(transfer.Payer)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrInvalidSolanaPayAccount, err)
+	}
+
+	mint, err := solanasdk.PublicKeyFromBase58(transfer.TokenMint)
+	if err != nil {
+		return "", fmt.Errorf("invalid token mint %s: %w", transfer.TokenMint, err)
+	}
+
+	reference, err := solanasdk.PublicKeyFromBase58(transfer.Reference)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference key %s: %w", transfer.Reference, err)
+	}
+
+	recipient, err := solanasdk.PublicKeyFromBase58(transfer.Recipient)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient %s: %w", transfer.Recipient, err)
+	}
+
+	if transfer.TokenDecimals < 0 || transfer.TokenDecimals > 255 {
+		return "", fmt.Errorf("invalid token decimals %d", transfer.TokenDecimals)
+	}
+
+	solanaTransfer := solana.SolanaPayTransfer{
+		Payer:     payer,
+		Recipient: recipient,
+		Mint:      mint,
+		Decimals:  uint8(transfer.TokenDecimals),
+		Amount:    transfer.Amount,
+		Memo:      transfer.Memo,
+		Reference: reference,
+	}
+
+	// A recipient other than the hot wallet is a deposit token account, not a wallet owning one
+	if !recipient.Equals(b.wallet.GetPublicKey()) {
+		solanaTransfer.Recipient = solanasdk.PublicKey{}
+		solanaTransfer.RecipientTokenAccount = recipient
+	}
+
+	return b.client.BuildSolanaPayTransaction(ctx, solanaTransfer)
+}
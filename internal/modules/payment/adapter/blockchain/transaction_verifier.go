@@ -0,0 +1,187 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	solanasdk "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/solana"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/tron"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+const (
+	// solanaFinalizedConfirmations is reported for finalized Solana transactions, the RPC returns no count for them
+	solanaFinalizedConfirmations = 32
+	// tronSolidifiedConfirmations is the depth at which a TRON block is solidified (2/3 of 27 super representatives)
+	tronSolidifiedConfirmations = 19
+)
+
+// EVMReader is the subset of the JSON-RPC API needed to verify EVM transactions
+// It is satisfied by *ethclient.Client
+type EVMReader interface {
+	ethereum.BlockNumberReader
+	ethereum.TransactionReader
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// TransactionVerifier checks transfers against the chain with the read-only clients of each chain
+// Implements domain.TransactionVerifier
+type TransactionVerifier struct {
+	solanaClient *solana.Client
+	tronClient   *tron.Client
+	evmClients   map[domain.Chain]EVMReader
+}
+
+// TransactionVerifierConfig holds the chain clients used for verification
+// A chain without a client cannot be verified
+type TransactionVerifierConfig struct {
+	SolanaClient *solana.Client
+	TRONClient   *tron.Client
+	EVMClients   map[domain.Chain]EVMReader // BSC and the configured EVM networks
+}
+
+// NewTransactionVerifier creates a new transaction verifier
+func NewTransactionVerifier(config TransactionVerifierConfig) *TransactionVerifier {
+	return &TransactionVerifier{
+		solanaClient: config.SolanaClient,
+		tronClient:   config.TRONClient,
+		evmClients:   config.EVMClients,
+	}
+}
+
+// VerifyTransaction returns the current inclusion of the transaction on the chain
+func (v *TransactionVerifier) VerifyTransaction(ctx context.Context, chain domain.Chain, txHash string) (*domain.TransactionInclusion, error) {
+	switch chain {
+	case domain.ChainSolana:
+		return v.verifySolana(ctx, txHash)
+	case domain.ChainTRON:
+		return v.verifyTRON(ctx, txHash)
+	default:
+		client, ok := v.evmClients[chain]
+		if !ok || client == nil {
+			return nil, fmt.Errorf("%w: no client for %s", domain.ErrTransactionVerifierNotConfigured, chain)
+		}
+		return verifyEVM(ctx, client, txHash)
+	}
+}
+
+func (v *TransactionVerifier) verifySolana(ctx context.Context, txHash string) (*domain.TransactionInclusion, error) {
+	if v.solanaClient == nil {
+		return nil, fmt.Errorf("%w: no client for %s", domain.ErrTransactionVerifierNotConfigured, domain.ChainSolana)
+	}
+
+	signature, err := solanasdk.SignatureFromBase58(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid solana signature: %w", err)
+	}
+
+	statuses, err := v.solanaClient.GetRPCClient().GetSignatureStatuses(ctx, true, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature status: %w", err)
+	}
+
+	// Dropped and reorged signatures have no status
+	if statuses == nil || len(statuses.Value) == 0 || statuses.Value[0] == nil {
+		return &domain.TransactionInclusion{}, nil
+	}
+
+	status := statuses.Value[0]
+	if status.Err != nil {
+		return &domain.TransactionInclusion{}, nil
+	}
+
+	inclusion := &domain.TransactionInclusion{
+		Included:    true,
+		BlockNumber: status.Slot,
+		Finalized:   status.ConfirmationStatus == rpc.ConfirmationStatusFinalized,
+	}
+	switch {
+	case status.Confirmations != nil:
+		inclusion.Confirmations = int32(*status.Confirmations)
+	case inclusion.Finalized:
+		inclusion.Confirmations = solanaFinalizedConfirmations
+	}
+
+	return inclusion, nil
+}
+
+func (v *TransactionVerifier) verifyTRON(ctx context.Context, txHash string) (*domain.TransactionInclusion, error) {
+	if v.tronClient == nil {
+		return nil, fmt.Errorf("%w: no client for %s", domain.ErrTransactionVerifierNotConfigured, domain.ChainTRON)
+	}
+
+	info, err := v.tronClient.GetTransactionInfoByID(ctx, txHash)
+	if err != nil {
+		if errors.Is(err, tron.ErrTransactionNotFound) {
+			return &domain.TransactionInclusion{}, nil
+		}
+		return nil, err
+	}
+	if !info.IsSuccessful() {
+		return &domain.TransactionInclusion{}, nil
+	}
+
+	head, err := v.tronClient.GetBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmations := confirmationsAt(head, info.BlockNumber)
+	return &domain.TransactionInclusion{
+		Included:      true,
+		BlockNumber:   info.BlockNumber,
+		Confirmations: confirmations,
+		Finalized:     confirmations >= tronSolidifiedConfirmations,
+	}, nil
+}
+
+func verifyEVM(ctx context.Context, client EVMReader, txHash string) (*domain.TransactionInclusion, error) {
+	// Nodes drop the receipt of a transaction whose block was reorged out
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return &domain.TransactionInclusion{}, nil
+		}
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		return &domain.TransactionInclusion{}, nil
+	}
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current block number: %w", err)
+	}
+
+	blockNumber := receipt.BlockNumber.Uint64()
+	inclusion := &domain.TransactionInclusion{
+		Included:      true,
+		BlockNumber:   blockNumber,
+		Confirmations: confirmationsAt(head, blockNumber),
+	}
+
+	// Networks without the finalized block tag only report depth
+	finalized, err := client.HeaderByNumber(ctx, big.NewInt(int64(ethrpc.FinalizedBlockNumber)))
+	if err == nil && finalized != nil {
+		inclusion.Finalized = finalized.Number.Uint64() >= blockNumber
+	}
+
+	return inclusion, nil
+}
+
+// confirmationsAt returns the number of blocks from blockNumber up to the head, inclusive
+func confirmationsAt(head, blockNumber uint64) int32 {
+	if head < blockNumber {
+		return 0
+	}
+	return int32(head - blockNumber + 1)
+}
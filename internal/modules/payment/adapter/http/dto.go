@@ -3,20 +3,54 @@ package http
 import (
 	"time"
 
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/shopspring/decimal"
 
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/web3"
 )
 
 // CreatePaymentRequest represents the request to create a new payment
 type CreatePaymentRequest struct {
-	AmountVND   float64            `json:"amount_vnd" binding:"required,gt=0" validate:"required,gt=0"`
-	Currency    string             `json:"currency,omitempty" validate:"omitempty,oneof=USDT USDC BUSD"`
-	Chain       string             `json:"chain,omitempty" validate:"omitempty,oneof=solana bsc"`
+	AmountVND   float64            `json:"amount_vnd,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"` // Required unless pricing_amount is set
+	Currency    string             `json:"currency,omitempty" validate:"omitempty,alphanum,max=10"`
+	Chain       string             `json:"chain,omitempty" validate:"omitempty,max=20"` // Supported chains are checked by the payment service
 	OrderID     string             `json:"order_id,omitempty" validate:"omitempty,max=255"`
 	Description string             `json:"description,omitempty" validate:"omitempty,max=1000"`
 	CallbackURL string             `json:"callback_url,omitempty" validate:"omitempty,url,max=500"`
 	TravelRule  *TravelRuleRequest `json:"travel_rule,omitempty"` // Required for transactions > $1000 USD
+
+	// Locks the rate of a quote still valid, amount, chain and currency must match the quote
+	QuoteID string `json:"quote_id,omitempty" binding:"omitempty,uuid" validate:"omitempty,uuid"`
+
+	// Price in another fiat currency (e.g. USD, EUR) instead of amount_vnd
+	PricingCurrency string  `json:"pricing_currency,omitempty" binding:"omitempty,len=3" validate:"omitempty,len=3"`
+	PricingAmount   float64 `json:"pricing_amount,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"`
+
+	// Marketplace split of the net amount among sellers, a split for the calling merchant is its commission
+	Splits []PaymentSplitRequest `json:"splits,omitempty" binding:"omitempty,max=20,dive" validate:"omitempty,max=20,dive"`
+}
+
+// PaymentSplitRequest is the share of a split payment's net amount credited to one merchant
+type PaymentSplitRequest struct {
+	MerchantID string  `json:"merchant_id" binding:"required,uuid" validate:"required,uuid"`
+	Type       string  `json:"type" binding:"required,oneof=fixed percentage" validate:"required,oneof=fixed percentage"`
+	Value      float64 `json:"value" binding:"required,gt=0" validate:"required,gt=0"` // Amount in the pricing currency, or percentage of the net amount
+}
+
+// CancelPaymentRequest represents the request to cancel a payment
+type CancelPaymentRequest struct {
+	Reason string `json:"reason,omitempty" binding:"omitempty,max=255"`
+}
+
+// ExtendPaymentRequest represents the request to extend the expiry of a payment
+type ExtendPaymentRequest struct {
+	ExtensionMinutes int `json:"extension_minutes,omitempty" binding:"omitempty,min=1"` // Defaults to the payment expiry window
+}
+
+// SimulatePaymentRequest represents the request to simulate the outcome of a test payment
+type SimulatePaymentRequest struct {
+	Outcome string `json:"outcome" binding:"required,oneof=paid underpaid overpaid expired late"`
 }
 
 // TravelRuleRequest represents Travel Rule data for high-value transactions (> $1000 USD)
@@ -25,6 +59,12 @@ type TravelRuleRequest struct {
 	PayerWalletAddress string `json:"payer_wallet_address" validate:"required,max=255"`
 	PayerCountry       string `json:"payer_country" validate:"required,len=2"` // ISO 3166-1 alpha-2
 	PayerIDDocument    string `json:"payer_id_document,omitempty" validate:"omitempty,max=255"`
+
+	// Optional proof of ownership of the payer wallet, verified before the payment is created.
+	// Solana wallets sign signed_message with Ed25519 (Base64 signature); EVM wallets sign the
+	// EIP-191 message or EIP-712 typed data JSON of a challenge from POST /api/v1/ownership-challenges (0x hex signature).
+	Signature     string `json:"signature,omitempty" validate:"omitempty,max=256"`
+	SignedMessage string `json:"signed_message,omitempty" validate:"omitempty,max=4096"`
 }
 
 // CreatePaymentResponse represents the response when a payment is created
@@ -35,12 +75,20 @@ type CreatePaymentResponse struct {
 	Currency          string          `json:"currency"`
 	Chain             string          `json:"chain"`
 	ExchangeRate      decimal.Decimal `json:"exchange_rate"`
+	PricingCurrency   string          `json:"pricing_currency"`
+	PricingAmount     decimal.Decimal `json:"pricing_amount"`
+	PricingRate       decimal.Decimal `json:"pricing_rate"`
 	DestinationWallet string          `json:"destination_wallet"`
 	PaymentReference  string          `json:"payment_reference"`
 	ExpiresAt         time.Time       `json:"expires_at"`
 	FeeVND            decimal.Decimal `json:"fee_vnd"`
 	NetAmountVND      decimal.Decimal `json:"net_amount_vnd"`
+	PricingFee        decimal.Decimal `json:"pricing_fee"`
+	PricingNetAmount  decimal.Decimal `json:"pricing_net_amount"`
+	Splits            []PaymentSplit  `json:"splits,omitempty"`
 	Status            string          `json:"status"`
+	TestMode          bool            `json:"test_mode"`
+	QuoteID           *string         `json:"quote_id,omitempty"`
 	QRCodeURL         string          `json:"qr_code_url"`
 	PaymentURL        string          `json:"payment_url"`
 	CreatedAt         time.Time       `json:"created_at"`
@@ -58,6 +106,12 @@ type GetPaymentResponse struct {
 	DestinationWallet string          `json:"destination_wallet"`
 	PaymentReference  string          `json:"payment_reference"`
 	Status            string          `json:"status"`
+	TestMode          bool            `json:"test_mode"` // Payment of a sandbox merchant, settled by simulation
+
+	// Merchant price, amount_vnd is its VND equivalent
+	PricingCurrency string          `json:"pricing_currency"`
+	PricingAmount   decimal.Decimal `json:"pricing_amount"`
+	PricingRate     decimal.Decimal `json:"pricing_rate"`
 
 	// Optional fields
 	OrderID       *string `json:"order_id,omitempty"`
@@ -65,33 +119,185 @@ type GetPaymentResponse struct {
 	TxHash        *string `json:"tx_hash,omitempty"`
 	FromAddress   *string `json:"from_address,omitempty"`
 	FailureReason *string `json:"failure_reason,omitempty"`
+	QuoteID       *string `json:"quote_id,omitempty"`
+	PaymentLinkID *string `json:"payment_link_id,omitempty"`
+	InvoiceID     *string `json:"invoice_id,omitempty"`
+
+	SubscriptionCycleID *string `json:"subscription_cycle_id,omitempty"`
+
+	// Amount received across all transfers
+	AmountReceived  decimal.Decimal           `json:"amount_received"`
+	AmountRemaining decimal.Decimal           `json:"amount_remaining"`
+	AmountSurplus   decimal.Decimal           `json:"amount_surplus"`
+	Transfers       []PaymentTransferResponse `json:"transfers,omitempty"`
+
+	// Status transitions, oldest first
+	StatusHistory []PaymentStatusTransitionResponse `json:"status_history,omitempty"`
 
 	// Timing
 	ExpiresAt   time.Time  `json:"expires_at"`
 	PaidAt      *time.Time `json:"paid_at,omitempty"`
 	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	ReversedAt  *time.Time `json:"reversed_at,omitempty"`
+
+	// Late payment (transfers received after expiry)
+	LatePaidAt     *time.Time `json:"late_paid_at,omitempty"`
+	LateResolution *string    `json:"late_resolution,omitempty"`
+
+	// Merchant cancellation and expiry extensions
+	CanceledAt       *time.Time `json:"canceled_at,omitempty"`
+	CancelReason     *string    `json:"cancel_reason,omitempty"`
+	ExpiryExtensions int        `json:"expiry_extensions"`
 
 	// Fee information
 	FeePercentage decimal.Decimal `json:"fee_percentage"`
 	FeeVND        decimal.Decimal `json:"fee_vnd"`
 	NetAmountVND  decimal.Decimal `json:"net_amount_vnd"`
 
+	// Fee information in the pricing currency
+	PricingFee       decimal.Decimal `json:"pricing_fee"`
+	PricingNetAmount decimal.Decimal `json:"pricing_net_amount"`
+
+	// Marketplace split of the net amount
+	Splits []PaymentSplit `json:"splits,omitempty"`
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// ListPaymentsRequest represents the request to list payments with pagination
+// PaymentSplit represents the resolved share of a split payment's net amount
+type PaymentSplit struct {
+	MerchantID string          `json:"merchant_id"`
+	Type       string          `json:"type"`
+	Value      decimal.Decimal `json:"value"`
+	Amount     decimal.Decimal `json:"amount"`
+	AmountVND  decimal.Decimal `json:"amount_vnd"`
+	Commission bool            `json:"commission"`
+}
+
+// paymentSplitsFromRequest converts the requested splits to their domain representation
+func paymentSplitsFromRequest(splits []PaymentSplitRequest) []domain.PaymentSplit {
+	var result []domain.PaymentSplit
+	for _, split := range splits {
+		result = append(result, domain.PaymentSplit{
+			MerchantID: split.MerchantID,
+			Type:       domain.PaymentSplitType(split.Type),
+			Value:      decimal.NewFromFloat(split.Value),
+		})
+	}
+	return result
+}
+
+// PaymentSplitsToResponse converts the splits of a payment to their API representation
+func PaymentSplitsToResponse(splits domain.PaymentSplits) []PaymentSplit {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	response := make([]PaymentSplit, len(splits))
+	for i, split := range splits {
+		response[i] = PaymentSplit{
+			MerchantID: split.MerchantID,
+			Type:       string(split.Type),
+			Value:      split.Value,
+			Amount:     split.Amount,
+			AmountVND:  split.AmountVND,
+			Commission: split.Commission,
+		}
+	}
+	return response
+}
+
+// PaymentTransferResponse represents a single on-chain transfer counted toward a payment
+type PaymentTransferResponse struct {
+	TxHash        string          `json:"tx_hash"`
+	FromAddress   *string         `json:"from_address,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	Confirmations int32           `json:"confirmations"`
+	FinalizedAt   *time.Time      `json:"finalized_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// PaymentStatusTransitionResponse represents a status change of a payment
+type PaymentStatusTransitionResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"` // system, listener, worker, merchant or admin
+	Reason     *string   `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ListPaymentsRequest represents the filters, sort order and cursor of a payment search
 type ListPaymentsRequest struct {
-	Page    int    `form:"page" binding:"omitempty,min=1" validate:"omitempty,min=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100" validate:"omitempty,min=1,max=100"`
-	Status  string `form:"status" binding:"omitempty,oneof=created pending confirming completed expired failed"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100" validate:"omitempty,min=1,max=100"`
+	Cursor    string `form:"cursor"`
+	SortBy    string `form:"sort_by" binding:"omitempty,oneof=created_at amount_vnd"`
+	SortOrder string `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+
+	Status   string `form:"status"` // Comma-separated list of statuses
+	Chain    string `form:"chain" binding:"omitempty,max=20"`
+	Currency string `form:"currency" binding:"omitempty,max=10"`
+
+	CreatedFrom   time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo     time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	ConfirmedFrom time.Time `form:"confirmed_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	ConfirmedTo   time.Time `form:"confirmed_to" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	OrderID     string `form:"order_id" binding:"omitempty,max=255"`
+	TxHash      string `form:"tx_hash" binding:"omitempty,max=255"`
+	FromAddress string `form:"from_address" binding:"omitempty,max=255"`
+
+	MinAmountVND float64 `form:"min_amount_vnd" binding:"omitempty,gte=0"`
+	MaxAmountVND float64 `form:"max_amount_vnd" binding:"omitempty,gte=0"`
+
+	// Amount range in the pricing currency, min_amount and max_amount require pricing_currency
+	PricingCurrency string  `form:"pricing_currency" binding:"omitempty,len=3"`
+	MinAmount       float64 `form:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount       float64 `form:"max_amount" binding:"omitempty,gte=0"`
+}
+
+// ToPaymentSearch converts the request to a search of the merchant's payments
+// Metadata filters come from the metadata[key]=value query parameters
+func (r ListPaymentsRequest) ToPaymentSearch(merchantID string, metadata map[string]string) (domain.PaymentSearch, error) {
+	statuses, err := domain.ParsePaymentStatuses(r.Status)
+	if err != nil {
+		return domain.PaymentSearch{}, err
+	}
+
+	return domain.PaymentSearch{
+		Filter: domain.PaymentFilter{
+			MerchantID:    merchantID,
+			Statuses:      statuses,
+			Chain:         domain.Chain(r.Chain),
+			Currency:      r.Currency,
+			CreatedFrom:   r.CreatedFrom,
+			CreatedTo:     r.CreatedTo,
+			ConfirmedFrom: r.ConfirmedFrom,
+			ConfirmedTo:   r.ConfirmedTo,
+			OrderID:       r.OrderID,
+			TxHash:        r.TxHash,
+			FromAddress:   r.FromAddress,
+			MinAmountVND:  decimal.NewFromFloat(r.MinAmountVND),
+			MaxAmountVND:  decimal.NewFromFloat(r.MaxAmountVND),
+			Metadata:      metadata,
+
+			PricingCurrency:  r.PricingCurrency,
+			MinPricingAmount: decimal.NewFromFloat(r.MinAmount),
+			MaxPricingAmount: decimal.NewFromFloat(r.MaxAmount),
+		},
+		SortBy:     domain.PaymentSortField(r.SortBy),
+		Descending: r.SortOrder != "asc",
+		Cursor:     r.Cursor,
+		Limit:      r.Limit,
+	}, nil
 }
 
-// ListPaymentsResponse represents the response when listing payments
+// ListPaymentsResponse represents a page of payments
 type ListPaymentsResponse struct {
 	Payments   []PaymentListItem `json:"payments"`
-	Pagination *PaginationMeta   `json:"pagination"`
+	NextCursor *string           `json:"next_cursor,omitempty"` // Pass as cursor to get the next page, absent on the last page
+	HasMore    bool              `json:"has_more"`
 }
 
 // PaginationMeta contains pagination metadata
@@ -104,18 +310,36 @@ type PaginationMeta struct {
 
 // PaymentListItem represents a summary of a payment in a list
 type PaymentListItem struct {
-	PaymentID    string          `json:"payment_id"`
-	AmountVND    decimal.Decimal `json:"amount_vnd"`
-	AmountCrypto decimal.Decimal `json:"amount_crypto"`
-	Currency     string          `json:"currency"`
-	Chain        string          `json:"chain"`
-	Status       string          `json:"status"`
-	OrderID      *string         `json:"order_id,omitempty"`
-	TxHash       *string         `json:"tx_hash,omitempty"`
-	CreatedAt    time.Time       `json:"created_at"`
-	ExpiresAt    time.Time       `json:"expires_at"`
-	PaidAt       *time.Time      `json:"paid_at,omitempty"`
-	ConfirmedAt  *time.Time      `json:"confirmed_at,omitempty"`
+	PaymentID       string          `json:"payment_id"`
+	AmountVND       decimal.Decimal `json:"amount_vnd"`
+	AmountCrypto    decimal.Decimal `json:"amount_crypto"`
+	PricingCurrency string          `json:"pricing_currency"`
+	PricingAmount   decimal.Decimal `json:"pricing_amount"`
+	Currency        string          `json:"currency"`
+	Chain           string          `json:"chain"`
+	Status          string          `json:"status"`
+	OrderID         *string         `json:"order_id,omitempty"`
+	TxHash          *string         `json:"tx_hash,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+	PaidAt          *time.Time      `json:"paid_at,omitempty"`
+	ConfirmedAt     *time.Time      `json:"confirmed_at,omitempty"`
+}
+
+// PaymentPageToResponse converts a page of a payment search to ListPaymentsResponse
+func PaymentPageToResponse(page *domain.PaymentPage) ListPaymentsResponse {
+	response := ListPaymentsResponse{
+		Payments: make([]PaymentListItem, len(page.Payments)),
+		HasMore:  page.NextCursor != "",
+	}
+	for i, payment := range page.Payments {
+		response.Payments[i] = PaymentToListItem(payment)
+	}
+	if page.NextCursor != "" {
+		response.NextCursor = &page.NextCursor
+	}
+
+	return response
 }
 
 // PaymentToResponse converts a domain.Payment to GetPaymentResponse
@@ -131,10 +355,21 @@ func PaymentToResponse(payment *domain.Payment) GetPaymentResponse {
 		DestinationWallet: payment.DestinationWallet,
 		PaymentReference:  payment.PaymentReference,
 		Status:            string(payment.Status),
+		TestMode:          payment.TestMode,
+		PricingCurrency:   payment.GetPricingCurrency(),
+		PricingAmount:     payment.PricingAmount,
+		PricingRate:       payment.PricingRate,
+		PricingFee:        payment.PricingFee,
+		PricingNetAmount:  payment.PricingNetAmount,
+		AmountReceived:    payment.AmountReceived,
+		AmountRemaining:   payment.RemainingAmount(),
+		AmountSurplus:     payment.SurplusAmount(),
 		ExpiresAt:         payment.ExpiresAt,
+		ExpiryExtensions:  payment.ExpiryExtensions,
 		FeePercentage:     payment.FeePercentage,
 		FeeVND:            payment.FeeVND,
 		NetAmountVND:      payment.NetAmountVND,
+		Splits:            PaymentSplitsToResponse(payment.Splits),
 		CreatedAt:         payment.CreatedAt,
 		UpdatedAt:         payment.UpdatedAt,
 	}
@@ -160,6 +395,22 @@ func PaymentToResponse(payment *domain.Payment) GetPaymentResponse {
 		failureReason := payment.FailureReason.String
 		response.FailureReason = &failureReason
 	}
+	if payment.QuoteID.Valid {
+		quoteID := payment.QuoteID.String
+		response.QuoteID = &quoteID
+	}
+	if payment.PaymentLinkID.Valid {
+		paymentLinkID := payment.PaymentLinkID.String
+		response.PaymentLinkID = &paymentLinkID
+	}
+	if payment.InvoiceID.Valid {
+		invoiceID := payment.InvoiceID.String
+		response.InvoiceID = &invoiceID
+	}
+	if payment.SubscriptionCycleID.Valid {
+		subscriptionCycleID := payment.SubscriptionCycleID.String
+		response.SubscriptionCycleID = &subscriptionCycleID
+	}
 	if payment.PaidAt.Valid {
 		paidAt := payment.PaidAt.Time
 		response.PaidAt = &paidAt
@@ -168,6 +419,835 @@ func PaymentToResponse(payment *domain.Payment) GetPaymentResponse {
 		confirmedAt := payment.ConfirmedAt.Time
 		response.ConfirmedAt = &confirmedAt
 	}
+	if payment.ReversedAt.Valid {
+		reversedAt := payment.ReversedAt.Time
+		response.ReversedAt = &reversedAt
+	}
+	if payment.LatePaidAt.Valid {
+		latePaidAt := payment.LatePaidAt.Time
+		response.LatePaidAt = &latePaidAt
+	}
+	if payment.LateResolution.Valid {
+		lateResolution := payment.LateResolution.String
+		response.LateResolution = &lateResolution
+	}
+	if payment.CanceledAt.Valid {
+		canceledAt := payment.CanceledAt.Time
+		response.CanceledAt = &canceledAt
+	}
+	if payment.CancelReason.Valid {
+		cancelReason := payment.CancelReason.String
+		response.CancelReason = &cancelReason
+	}
+
+	return response
+}
+
+// PaymentTransfersToResponse converts domain.PaymentTransfer records to PaymentTransferResponse
+func PaymentTransfersToResponse(transfers []*domain.PaymentTransfer) []PaymentTransferResponse {
+	items := make([]PaymentTransferResponse, len(transfers))
+	for i, transfer := range transfers {
+		items[i] = PaymentTransferResponse{
+			TxHash:        transfer.TxHash,
+			Amount:        transfer.Amount,
+			Confirmations: transfer.Confirmations,
+			CreatedAt:     transfer.CreatedAt,
+		}
+		if transfer.FromAddress.Valid {
+			fromAddress := transfer.FromAddress.String
+			items[i].FromAddress = &fromAddress
+		}
+		if transfer.FinalizedAt.Valid {
+			finalizedAt := transfer.FinalizedAt.Time
+			items[i].FinalizedAt = &finalizedAt
+		}
+	}
+
+	return items
+}
+
+// PaymentStatusHistoryToResponse converts domain.PaymentStatusTransition records to PaymentStatusTransitionResponse
+func PaymentStatusHistoryToResponse(history []*domain.PaymentStatusTransition) []PaymentStatusTransitionResponse {
+	items := make([]PaymentStatusTransitionResponse, len(history))
+	for i, transition := range history {
+		items[i] = PaymentStatusTransitionResponse{
+			FromStatus: string(transition.FromStatus),
+			ToStatus:   string(transition.ToStatus),
+			Actor:      string(transition.Actor),
+			CreatedAt:  transition.CreatedAt,
+		}
+		if transition.Reason.Valid {
+			reason := transition.Reason.String
+			items[i].Reason = &reason
+		}
+	}
+
+	return items
+}
+
+// CreateQuoteRequest represents the request to lock the exchange rate for an amount
+type CreateQuoteRequest struct {
+	AmountVND float64 `json:"amount_vnd" binding:"required,gt=0" validate:"required,gt=0"`
+	Chain     string  `json:"chain,omitempty" binding:"omitempty,max=20" validate:"omitempty,max=20"`    // Empty quotes every supported chain
+	Currency  string  `json:"currency,omitempty" binding:"omitempty,max=10" validate:"omitempty,max=10"` // Empty quotes every supported token
+}
+
+// QuoteOptionResponse represents the locked terms of a quote on one chain and token
+type QuoteOptionResponse struct {
+	Chain        string          `json:"chain"`
+	Currency     string          `json:"currency"`
+	MarketRate   decimal.Decimal `json:"market_rate"`
+	ExchangeRate decimal.Decimal `json:"exchange_rate"`
+	AmountCrypto decimal.Decimal `json:"amount_crypto"`
+}
+
+// QuoteResponse represents a signed rate-locked quote
+type QuoteResponse struct {
+	QuoteID          string                `json:"quote_id"`
+	AmountVND        decimal.Decimal       `json:"amount_vnd"`
+	SpreadPercentage decimal.Decimal       `json:"spread_percentage"`
+	RateSource       string                `json:"rate_source"`
+	Options          []QuoteOptionResponse `json:"options"`
+	Signature        string                `json:"signature"`
+	ValidUntil       time.Time             `json:"valid_until"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+// QuoteToResponse converts a domain.Quote to QuoteResponse
+func QuoteToResponse(quote *domain.Quote) QuoteResponse {
+	options := make([]QuoteOptionResponse, len(quote.Options))
+	for i, option := range quote.Options {
+		options[i] = QuoteOptionResponse{
+			Chain:        string(option.Chain),
+			Currency:     option.Currency,
+			MarketRate:   option.MarketRate,
+			ExchangeRate: option.ExchangeRate,
+			AmountCrypto: option.AmountCrypto,
+		}
+	}
+
+	return QuoteResponse{
+		QuoteID:          quote.ID,
+		AmountVND:        quote.AmountVND,
+		SpreadPercentage: quote.SpreadPercentage,
+		RateSource:       quote.RateSource,
+		Options:          options,
+		Signature:        quote.Signature,
+		ValidUntil:       quote.ValidUntil,
+		CreatedAt:        quote.CreatedAt,
+	}
+}
+
+// CreateOwnershipChallengeRequest represents the request for a challenge an EVM payer signs to prove wallet ownership
+type CreateOwnershipChallengeRequest struct {
+	Chain         string `json:"chain" binding:"required,max=20" validate:"required,max=20"`
+	WalletAddress string `json:"wallet_address" binding:"required,max=42" validate:"required,max=42"`
+}
+
+// OwnershipChallengeResponse represents an issued ownership challenge
+// The payer signs either message with personal_sign or typed_data with eth_signTypedData_v4
+type OwnershipChallengeResponse struct {
+	Nonce         string             `json:"nonce"`
+	Chain         string             `json:"chain"`
+	WalletAddress string             `json:"wallet_address"`
+	Message       string             `json:"message"`
+	TypedData     apitypes.TypedData `json:"typed_data"`
+	ExpiresAt     time.Time          `json:"expires_at"`
+}
+
+// OwnershipChallengeToResponse converts a domain.OwnershipChallenge to OwnershipChallengeResponse
+// chainID is shown by wallets in the EIP-712 signing prompt
+func OwnershipChallengeToResponse(challenge *domain.OwnershipChallenge, chainID int64) OwnershipChallengeResponse {
+	return OwnershipChallengeResponse{
+		Nonce:         challenge.Nonce,
+		Chain:         string(challenge.Chain),
+		WalletAddress: challenge.WalletAddress,
+		Message:       web3.GenerateEVMChallengeMessage(challenge.WalletAddress, challenge.Nonce, challenge.ExpiresAt),
+		TypedData:     web3.EVMOwnershipTypedData(challenge.WalletAddress, challenge.Nonce, challenge.ExpiresAt, chainID),
+		ExpiresAt:     challenge.ExpiresAt,
+	}
+}
+
+// CreateRefundRequest represents the request to refund a completed payment
+type CreateRefundRequest struct {
+	Amount string `json:"amount,omitempty" validate:"omitempty"` // Crypto amount, empty refunds the remaining amount
+	Reason string `json:"reason,omitempty" binding:"omitempty,max=500" validate:"omitempty,max=500"`
+}
+
+// RefundResponse represents a refund of a payment
+type RefundResponse struct {
+	ID            string          `json:"id"`
+	PaymentID     string          `json:"payment_id"`
+	AmountCrypto  decimal.Decimal `json:"amount_crypto"`
+	AmountVND     decimal.Decimal `json:"amount_vnd"`
+	Currency      string          `json:"currency"`
+	Chain         string          `json:"chain"`
+	ToAddress     string          `json:"to_address"`
+	Reason        *string         `json:"reason,omitempty"`
+	Source        string          `json:"source"`
+	Status        string          `json:"status"`
+	TxHash        *string         `json:"tx_hash,omitempty"`
+	FailureReason *string         `json:"failure_reason,omitempty"`
+	SubmittedAt   *time.Time      `json:"submitted_at,omitempty"`
+	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// RefundToResponse converts a domain.Refund to RefundResponse
+func RefundToResponse(refund *domain.Refund) RefundResponse {
+	response := RefundResponse{
+		ID:           refund.ID,
+		PaymentID:    refund.PaymentID,
+		AmountCrypto: refund.AmountCrypto,
+		AmountVND:    refund.AmountVND,
+		Currency:     refund.Currency,
+		Chain:        string(refund.Chain),
+		ToAddress:    refund.ToAddress,
+		Source:       string(refund.Source),
+		Status:       string(refund.Status),
+		CreatedAt:    refund.CreatedAt,
+		UpdatedAt:    refund.UpdatedAt,
+	}
+
+	if refund.Reason.Valid {
+		reason := refund.Reason.String
+		response.Reason = &reason
+	}
+	if refund.TxHash.Valid {
+		txHash := refund.TxHash.String
+		response.TxHash = &txHash
+	}
+	if refund.FailureReason.Valid {
+		failureReason := refund.FailureReason.String
+		response.FailureReason = &failureReason
+	}
+	if refund.SubmittedAt.Valid {
+		submittedAt := refund.SubmittedAt.Time
+		response.SubmittedAt = &submittedAt
+	}
+	if refund.CompletedAt.Valid {
+		completedAt := refund.CompletedAt.Time
+		response.CompletedAt = &completedAt
+	}
+
+	return response
+}
+
+// CreatePaymentLinkRequest represents the request to create a payment link
+type CreatePaymentLinkRequest struct {
+	AmountType      string                 `json:"amount_type" binding:"required,oneof=fixed open" validate:"required,oneof=fixed open"`
+	PricingCurrency string                 `json:"pricing_currency,omitempty" binding:"omitempty,len=3" validate:"omitempty,len=3"` // Defaults to VND
+	Amount          float64                `json:"amount,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"`             // Required for fixed links
+	MinAmount       float64                `json:"min_amount,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"`
+	MaxAmount       float64                `json:"max_amount,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"`
+	Currency        string                 `json:"currency,omitempty" binding:"omitempty,max=10" validate:"omitempty,max=10"` // Empty lets the payer choose
+	Chain           string                 `json:"chain,omitempty" binding:"omitempty,max=20" validate:"omitempty,max=20"`    // Empty lets the payer choose
+	Description     string                 `json:"description" binding:"required,max=1000" validate:"required,max=1000"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	MaxUses         int32                  `json:"max_uses,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"` // Empty is unlimited
+	ExpiresAt       *time.Time             `json:"expires_at,omitempty"`
+}
+
+// PaymentLinkResponse represents a payment link as seen by its merchant
+type PaymentLinkResponse struct {
+	ID              string                 `json:"id"`
+	URL             string                 `json:"url"`
+	AmountType      string                 `json:"amount_type"`
+	PricingCurrency string                 `json:"pricing_currency"`
+	Amount          *decimal.Decimal       `json:"amount,omitempty"`
+	MinAmount       *decimal.Decimal       `json:"min_amount,omitempty"`
+	MaxAmount       *decimal.Decimal       `json:"max_amount,omitempty"`
+	Currency        *string                `json:"currency,omitempty"`
+	Chain           *string                `json:"chain,omitempty"`
+	Description     string                 `json:"description"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	MaxUses         *int32                 `json:"max_uses,omitempty"`
+	UsesCount       int32                  `json:"uses_count"`
+	ExpiresAt       *time.Time             `json:"expires_at,omitempty"`
+	Status          string                 `json:"status"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// PublicPaymentLinkResponse represents a payment link as shown to payers (no authentication required)
+type PublicPaymentLinkResponse struct {
+	ID              string           `json:"id"`
+	AmountType      string           `json:"amount_type"`
+	PricingCurrency string           `json:"pricing_currency"`
+	Amount          *decimal.Decimal `json:"amount,omitempty"`
+	MinAmount       *decimal.Decimal `json:"min_amount,omitempty"`
+	MaxAmount       *decimal.Decimal `json:"max_amount,omitempty"`
+	Currency        *string          `json:"currency,omitempty"`
+	Chain           *string          `json:"chain,omitempty"`
+	Description     string           `json:"description"`
+	ExpiresAt       *time.Time       `json:"expires_at,omitempty"`
+	Available       bool             `json:"available"` // False once the link is inactive, expired or used up
+}
+
+// ListPaymentLinksRequest represents the request to list payment links with pagination
+type ListPaymentLinksRequest struct {
+	Page    int `form:"page" binding:"omitempty,min=1" validate:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100" validate:"omitempty,min=1,max=100"`
+}
+
+// ListPaymentLinksResponse represents the response when listing payment links
+type ListPaymentLinksResponse struct {
+	PaymentLinks []PaymentLinkResponse `json:"payment_links"`
+	Pagination   *PaginationMeta       `json:"pagination"`
+}
+
+// OpenPaymentLinkRequest represents a payer opening a payment link
+type OpenPaymentLinkRequest struct {
+	Amount   float64 `json:"amount,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"`       // Required for open links
+	Currency string  `json:"currency,omitempty" binding:"omitempty,max=10" validate:"omitempty,max=10"` // Ignored when the link sets one
+	Chain    string  `json:"chain,omitempty" binding:"omitempty,max=20" validate:"omitempty,max=20"`    // Ignored when the link sets one
+}
+
+// PaymentLinkToResponse converts a domain.PaymentLink to PaymentLinkResponse
+func PaymentLinkToResponse(link *domain.PaymentLink, url string) PaymentLinkResponse {
+	public := PaymentLinkToPublicResponse(link)
+
+	response := PaymentLinkResponse{
+		ID:              link.ID,
+		URL:             url,
+		AmountType:      public.AmountType,
+		PricingCurrency: public.PricingCurrency,
+		Amount:          public.Amount,
+		MinAmount:       public.MinAmount,
+		MaxAmount:       public.MaxAmount,
+		Currency:        public.Currency,
+		Chain:           public.Chain,
+		Description:     link.Description,
+		Metadata:        link.Metadata,
+		UsesCount:       link.UsesCount,
+		ExpiresAt:       public.ExpiresAt,
+		Status:          string(link.Status),
+		CreatedAt:       link.CreatedAt,
+		UpdatedAt:       link.UpdatedAt,
+	}
+
+	if link.MaxUses.Valid {
+		maxUses := link.MaxUses.Int32
+		response.MaxUses = &maxUses
+	}
+
+	return response
+}
+
+// PaymentLinkToPublicResponse converts a domain.PaymentLink to PublicPaymentLinkResponse (public-safe)
+func PaymentLinkToPublicResponse(link *domain.PaymentLink) PublicPaymentLinkResponse {
+	response := PublicPaymentLinkResponse{
+		ID:              link.ID,
+		AmountType:      string(link.AmountType),
+		PricingCurrency: link.PricingCurrency,
+		Description:     link.Description,
+		Available:       link.CheckUsable() == nil,
+	}
+
+	if link.AmountType == domain.PaymentLinkAmountFixed {
+		amount := link.Amount
+		response.Amount = &amount
+	}
+	if link.MinAmount.IsPositive() {
+		minAmount := link.MinAmount
+		response.MinAmount = &minAmount
+	}
+	if link.MaxAmount.IsPositive() {
+		maxAmount := link.MaxAmount
+		response.MaxAmount = &maxAmount
+	}
+	if link.Currency.Valid {
+		currency := link.Currency.String
+		response.Currency = &currency
+	}
+	if link.Chain.Valid {
+		chain := link.Chain.String
+		response.Chain = &chain
+	}
+	if link.ExpiresAt.Valid {
+		expiresAt := link.ExpiresAt.Time
+		response.ExpiresAt = &expiresAt
+	}
+
+	return response
+}
+
+// InvoiceLineItemRequest represents a billed item of an invoice
+type InvoiceLineItemRequest struct {
+	Description string  `json:"description" binding:"required,max=500" validate:"required,max=500"`
+	Quantity    float64 `json:"quantity" binding:"required,gt=0" validate:"required,gt=0"`
+	UnitPrice   float64 `json:"unit_price" binding:"gte=0" validate:"gte=0"`
+}
+
+// CreateInvoiceRequest represents the request to create an invoice
+type CreateInvoiceRequest struct {
+	InvoiceNumber   string                   `json:"invoice_number,omitempty" binding:"omitempty,max=50" validate:"omitempty,max=50"` // Generated when empty
+	CustomerName    string                   `json:"customer_name,omitempty" binding:"omitempty,max=255" validate:"omitempty,max=255"`
+	CustomerEmail   string                   `json:"customer_email,omitempty" binding:"omitempty,email,max=255" validate:"omitempty,email,max=255"`
+	Description     string                   `json:"description,omitempty" binding:"omitempty,max=1000" validate:"omitempty,max=1000"`
+	PricingCurrency string                   `json:"pricing_currency,omitempty" binding:"omitempty,len=3" validate:"omitempty,len=3"` // Defaults to VND
+	LineItems       []InvoiceLineItemRequest `json:"line_items" binding:"required,min=1,max=100,dive" validate:"required,min=1,max=100,dive"`
+	TaxRate         float64                  `json:"tax_rate,omitempty" binding:"omitempty,gte=0,lte=1" validate:"omitempty,gte=0,lte=1"` // e.g. 0.1 = 10%
+	DueDate         time.Time                `json:"due_date" binding:"required" validate:"required"`
+	Metadata        map[string]interface{}   `json:"metadata,omitempty"`
+}
+
+// InvoiceLineItemResponse represents a billed item of an invoice
+type InvoiceLineItemResponse struct {
+	Description string          `json:"description"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// InvoiceResponse represents an invoice as seen by its merchant
+type InvoiceResponse struct {
+	ID              string                    `json:"id"`
+	URL             string                    `json:"url"`
+	InvoiceNumber   string                    `json:"invoice_number"`
+	CustomerName    *string                   `json:"customer_name,omitempty"`
+	CustomerEmail   *string                   `json:"customer_email,omitempty"`
+	Description     *string                   `json:"description,omitempty"`
+	PricingCurrency string                    `json:"pricing_currency"`
+	LineItems       []InvoiceLineItemResponse `json:"line_items"`
+	Subtotal        decimal.Decimal           `json:"subtotal"`
+	TaxRate         decimal.Decimal           `json:"tax_rate"`
+	TaxAmount       decimal.Decimal           `json:"tax_amount"`
+	TotalAmount     decimal.Decimal           `json:"total_amount"`
+	AmountPaid      decimal.Decimal           `json:"amount_paid"`
+	AmountDue       decimal.Decimal           `json:"amount_due"`
+	DueDate         time.Time                 `json:"due_date"`
+	Overdue         bool                      `json:"overdue"`
+	Status          string                    `json:"status"`
+	Metadata        map[string]interface{}    `json:"metadata,omitempty"`
+	PaidAt          *time.Time                `json:"paid_at,omitempty"`
+	VoidedAt        *time.Time                `json:"voided_at,omitempty"`
+	CreatedAt       time.Time                 `json:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at"`
+}
+
+// PublicInvoiceResponse represents a hosted invoice as shown to the customer (no authentication required)
+type PublicInvoiceResponse struct {
+	ID              string                    `json:"id"`
+	InvoiceNumber   string                    `json:"invoice_number"`
+	CustomerName    *string                   `json:"customer_name,omitempty"`
+	Description     *string                   `json:"description,omitempty"`
+	PricingCurrency string                    `json:"pricing_currency"`
+	LineItems       []InvoiceLineItemResponse `json:"line_items"`
+	Subtotal        decimal.Decimal           `json:"subtotal"`
+	TaxRate         decimal.Decimal           `json:"tax_rate"`
+	TaxAmount       decimal.Decimal           `json:"tax_amount"`
+	TotalAmount     decimal.Decimal           `json:"total_amount"`
+	AmountPaid      decimal.Decimal           `json:"amount_paid"`
+	AmountDue       decimal.Decimal           `json:"amount_due"`
+	DueDate         time.Time                 `json:"due_date"`
+	Status          string                    `json:"status"`
+}
+
+// ListInvoicesRequest represents the request to list invoices with pagination
+type ListInvoicesRequest struct {
+	Page    int    `form:"page" binding:"omitempty,min=1" validate:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100" validate:"omitempty,min=1,max=100"`
+	Status  string `form:"status" binding:"omitempty,oneof=open partially_paid paid void"`
+}
+
+// ListInvoicesResponse represents the response when listing invoices
+type ListInvoicesResponse struct {
+	Invoices   []InvoiceResponse `json:"invoices"`
+	Pagination *PaginationMeta   `json:"pagination"`
+}
+
+// PayInvoiceRequest represents a customer paying a hosted invoice
+type PayInvoiceRequest struct {
+	Amount   float64 `json:"amount,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"` // Empty pays the amount due
+	Currency string  `json:"currency,omitempty" binding:"omitempty,max=10" validate:"omitempty,max=10"`
+	Chain    string  `json:"chain,omitempty" binding:"omitempty,max=20" validate:"omitempty,max=20"`
+}
+
+// InvoiceToResponse converts a domain.Invoice to InvoiceResponse
+func InvoiceToResponse(invoice *domain.Invoice, url string) InvoiceResponse {
+	response := InvoiceResponse{
+		ID:              invoice.ID,
+		URL:             url,
+		InvoiceNumber:   invoice.InvoiceNumber,
+		PricingCurrency: invoice.PricingCurrency,
+		LineItems:       invoiceLineItemsToResponse(invoice.LineItems),
+		Subtotal:        invoice.Subtotal,
+		TaxRate:         invoice.TaxRate,
+		TaxAmount:       invoice.TaxAmount,
+		TotalAmount:     invoice.TotalAmount,
+		AmountPaid:      invoice.AmountPaid,
+		AmountDue:       invoice.AmountDue(),
+		DueDate:         invoice.DueDate,
+		Overdue:         invoice.IsOverdue(),
+		Status:          string(invoice.Status),
+		Metadata:        invoice.Metadata,
+		CreatedAt:       invoice.CreatedAt,
+		UpdatedAt:       invoice.UpdatedAt,
+	}
+
+	if invoice.CustomerName.Valid {
+		customerName := invoice.CustomerName.String
+		response.CustomerName = &customerName
+	}
+	if invoice.CustomerEmail.Valid {
+		customerEmail := invoice.CustomerEmail.String
+		response.CustomerEmail = &customerEmail
+	}
+	if invoice.Description.Valid {
+		description := invoice.Description.String
+		response.Description = &description
+	}
+	if invoice.PaidAt.Valid {
+		paidAt := invoice.PaidAt.Time
+		response.PaidAt = &paidAt
+	}
+	if invoice.VoidedAt.Valid {
+		voidedAt := invoice.VoidedAt.Time
+		response.VoidedAt = &voidedAt
+	}
+
+	return response
+}
+
+// InvoiceToPublicResponse converts a domain.Invoice to PublicInvoiceResponse (public-safe)
+func InvoiceToPublicResponse(invoice *domain.Invoice) PublicInvoiceResponse {
+	response := PublicInvoiceResponse{
+		ID:              invoice.ID,
+		InvoiceNumber:   invoice.InvoiceNumber,
+		PricingCurrency: invoice.PricingCurrency,
+		LineItems:       invoiceLineItemsToResponse(invoice.LineItems),
+		Subtotal:        invoice.Subtotal,
+		TaxRate:         invoice.TaxRate,
+		TaxAmount:       invoice.TaxAmount,
+		TotalAmount:     invoice.TotalAmount,
+		AmountPaid:      invoice.AmountPaid,
+		AmountDue:       invoice.AmountDue(),
+		DueDate:         invoice.DueDate,
+		Status:          string(invoice.Status),
+	}
+
+	if invoice.CustomerName.Valid {
+		customerName := invoice.CustomerName.String
+		response.CustomerName = &customerName
+	}
+	if invoice.Description.Valid {
+		description := invoice.Description.String
+		response.Description = &description
+	}
+
+	return response
+}
+
+// invoiceLineItemsToResponse converts invoice line items to their response
+func invoiceLineItemsToResponse(lineItems domain.InvoiceLineItems) []InvoiceLineItemResponse {
+	items := make([]InvoiceLineItemResponse, len(lineItems))
+	for i, item := range lineItems {
+		items[i] = InvoiceLineItemResponse{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		}
+	}
+	return items
+}
+
+// CreateSubscriptionPlanRequest represents the request to create a subscription plan
+type CreateSubscriptionPlanRequest struct {
+	Name            string                 `json:"name" binding:"required,max=255" validate:"required,max=255"`
+	Description     string                 `json:"description,omitempty" binding:"omitempty,max=1000" validate:"omitempty,max=1000"`
+	PricingCurrency string                 `json:"pricing_currency,omitempty" binding:"omitempty,len=3" validate:"omitempty,len=3"` // Defaults to VND
+	Amount          float64                `json:"amount" binding:"required,gt=0" validate:"required,gt=0"`
+	BillingInterval string                 `json:"billing_interval" binding:"required,oneof=day week month year" validate:"required,oneof=day week month year"`
+	IntervalCount   int                    `json:"interval_count,omitempty" binding:"omitempty,min=1,max=365" validate:"omitempty,min=1,max=365"` // Defaults to 1
+	Currency        string                 `json:"currency,omitempty" binding:"omitempty,max=10" validate:"omitempty,max=10"`                     // Payer chooses when empty
+	Chain           string                 `json:"chain,omitempty" binding:"omitempty,max=20" validate:"omitempty,max=20"`                        // Payer chooses when empty
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SubscriptionPlanResponse represents a subscription plan
+type SubscriptionPlanResponse struct {
+	ID              string                 `json:"id"`
+	Name            string                 `json:"name"`
+	Description     *string                `json:"description,omitempty"`
+	PricingCurrency string                 `json:"pricing_currency"`
+	Amount          decimal.Decimal        `json:"amount"`
+	BillingInterval string                 `json:"billing_interval"`
+	IntervalCount   int                    `json:"interval_count"`
+	Currency        *string                `json:"currency,omitempty"`
+	Chain           *string                `json:"chain,omitempty"`
+	Status          string                 `json:"status"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// ListSubscriptionPlansRequest represents the request to list subscription plans with pagination
+type ListSubscriptionPlansRequest struct {
+	Page    int `form:"page" binding:"omitempty,min=1" validate:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100" validate:"omitempty,min=1,max=100"`
+}
+
+// ListSubscriptionPlansResponse represents the response when listing subscription plans
+type ListSubscriptionPlansResponse struct {
+	Plans      []SubscriptionPlanResponse `json:"plans"`
+	Pagination *PaginationMeta            `json:"pagination"`
+}
+
+// CreateSubscriptionRequest represents the request to subscribe a customer to a plan
+type CreateSubscriptionRequest struct {
+	PlanID            string                 `json:"plan_id" binding:"required,uuid" validate:"required,uuid"`
+	CustomerName      string                 `json:"customer_name,omitempty" binding:"omitempty,max=255" validate:"omitempty,max=255"`
+	CustomerEmail     string                 `json:"customer_email,omitempty" binding:"omitempty,email,max=255" validate:"omitempty,email,max=255"` // Receives the payment links
+	CustomerReference string                 `json:"customer_reference,omitempty" binding:"omitempty,max=255" validate:"omitempty,max=255"`
+	StartAt           *time.Time             `json:"start_at,omitempty"` // First cycle is issued now when empty
+	Metadata          map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SubscriptionResponse represents a subscription
+type SubscriptionResponse struct {
+	ID                 string                 `json:"id"`
+	PlanID             string                 `json:"plan_id"`
+	CustomerName       *string                `json:"customer_name,omitempty"`
+	CustomerEmail      *string                `json:"customer_email,omitempty"`
+	CustomerReference  *string                `json:"customer_reference,omitempty"`
+	Status             string                 `json:"status"`
+	CurrentPeriodStart time.Time              `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time              `json:"current_period_end"`
+	NextBillingAt      *time.Time             `json:"next_billing_at,omitempty"` // Empty once canceled
+	CyclesCount        int                    `json:"cycles_count"`
+	CancelAtPeriodEnd  bool                   `json:"cancel_at_period_end"`
+	CanceledAt         *time.Time             `json:"canceled_at,omitempty"`
+	CancellationReason *string                `json:"cancellation_reason,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+}
+
+// ListSubscriptionsRequest represents the request to list subscriptions with pagination
+type ListSubscriptionsRequest struct {
+	Page    int    `form:"page" binding:"omitempty,min=1" validate:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100" validate:"omitempty,min=1,max=100"`
+	Status  string `form:"status" binding:"omitempty,oneof=active past_due canceled"`
+}
+
+// ListSubscriptionsResponse represents the response when listing subscriptions
+type ListSubscriptionsResponse struct {
+	Subscriptions []SubscriptionResponse `json:"subscriptions"`
+	Pagination    *PaginationMeta        `json:"pagination"`
+}
+
+// CancelSubscriptionRequest represents the request to cancel a subscription
+type CancelSubscriptionRequest struct {
+	AtPeriodEnd bool `json:"at_period_end,omitempty"` // Keep billing until the end of the paid period
+}
+
+// SubscriptionCycleResponse represents a billing cycle of a subscription
+type SubscriptionCycleResponse struct {
+	ID              string          `json:"id"`
+	CycleNumber     int             `json:"cycle_number"`
+	PeriodStart     time.Time       `json:"period_start"`
+	PeriodEnd       time.Time       `json:"period_end"`
+	PricingCurrency string          `json:"pricing_currency"`
+	Amount          decimal.Decimal `json:"amount"`
+	Status          string          `json:"status"`
+	PaymentID       *string         `json:"payment_id,omitempty"`
+	Attempts        int             `json:"attempts"`
+	DueAt           time.Time       `json:"due_at"`
+	NextAttemptAt   *time.Time      `json:"next_attempt_at,omitempty"`
+	GraceEndsAt     time.Time       `json:"grace_ends_at"`
+	PaidAt          *time.Time      `json:"paid_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// SubscriptionPlanToResponse converts a domain.SubscriptionPlan to SubscriptionPlanResponse
+func SubscriptionPlanToResponse(plan *domain.SubscriptionPlan) SubscriptionPlanResponse {
+	response := SubscriptionPlanResponse{
+		ID:              plan.ID,
+		Name:            plan.Name,
+		PricingCurrency: plan.PricingCurrency,
+		Amount:          plan.Amount,
+		BillingInterval: string(plan.BillingInterval),
+		IntervalCount:   plan.IntervalCount,
+		Status:          string(plan.Status),
+		Metadata:        plan.Metadata,
+		CreatedAt:       plan.CreatedAt,
+		UpdatedAt:       plan.UpdatedAt,
+	}
+
+	if plan.Description.Valid {
+		description := plan.Description.String
+		response.Description = &description
+	}
+	if plan.Currency.Valid {
+		currency := plan.Currency.String
+		response.Currency = &currency
+	}
+	if plan.Chain.Valid {
+		chain := plan.Chain.String
+		response.Chain = &chain
+	}
+
+	return response
+}
+
+// SubscriptionToResponse converts a domain.Subscription to SubscriptionResponse
+func SubscriptionToResponse(subscription *domain.Subscription) SubscriptionResponse {
+	response := SubscriptionResponse{
+		ID:                 subscription.ID,
+		PlanID:             subscription.PlanID,
+		Status:             string(subscription.Status),
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+		CyclesCount:        subscription.CyclesCount,
+		CancelAtPeriodEnd:  subscription.CancelAtPeriodEnd,
+		Metadata:           subscription.Metadata,
+		CreatedAt:          subscription.CreatedAt,
+		UpdatedAt:          subscription.UpdatedAt,
+	}
+
+	if !subscription.IsCanceled() {
+		nextBillingAt := subscription.NextBillingAt
+		response.NextBillingAt = &nextBillingAt
+	}
+	if subscription.CustomerName.Valid {
+		customerName := subscription.CustomerName.String
+		response.CustomerName = &customerName
+	}
+	if subscription.CustomerEmail.Valid {
+		customerEmail := subscription.CustomerEmail.String
+		response.CustomerEmail = &customerEmail
+	}
+	if subscription.CustomerReference.Valid {
+		customerReference := subscription.CustomerReference.String
+		response.CustomerReference = &customerReference
+	}
+	if subscription.CanceledAt.Valid {
+		canceledAt := subscription.CanceledAt.Time
+		response.CanceledAt = &canceledAt
+	}
+	if subscription.CancellationReason.Valid {
+		cancellationReason := subscription.CancellationReason.String
+		response.CancellationReason = &cancellationReason
+	}
+
+	return response
+}
+
+// SubscriptionCycleToResponse converts a domain.SubscriptionCycle to SubscriptionCycleResponse
+func SubscriptionCycleToResponse(cycle *domain.SubscriptionCycle) SubscriptionCycleResponse {
+	response := SubscriptionCycleResponse{
+		ID:              cycle.ID,
+		CycleNumber:     cycle.CycleNumber,
+		PeriodStart:     cycle.PeriodStart,
+		PeriodEnd:       cycle.PeriodEnd,
+		PricingCurrency: cycle.PricingCurrency,
+		Amount:          cycle.Amount,
+		Status:          string(cycle.Status),
+		Attempts:        cycle.Attempts,
+		DueAt:           cycle.DueAt,
+		GraceEndsAt:     cycle.GraceEndsAt,
+		CreatedAt:       cycle.CreatedAt,
+	}
+
+	if cycle.PaymentID.Valid {
+		paymentID := cycle.PaymentID.String
+		response.PaymentID = &paymentID
+	}
+	if cycle.NextAttemptAt.Valid {
+		nextAttemptAt := cycle.NextAttemptAt.Time
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	if cycle.PaidAt.Valid {
+		paidAt := cycle.PaidAt.Time
+		response.PaidAt = &paidAt
+	}
+
+	return response
+}
+
+// CreatePaymentBatchRequest represents a request to create many payments at once
+type CreatePaymentBatchRequest struct {
+	Payments []PaymentBatchItemRequest `json:"payments" binding:"required,min=1,max=500" validate:"required,min=1,max=500"`
+
+	// Create the payments in the background and deliver a batch.completed webhook instead of waiting
+	Async bool `json:"async,omitempty"`
+}
+
+// PaymentBatchItemRequest represents one payment request of a batch, validated on its own
+type PaymentBatchItemRequest struct {
+	AmountVND       float64               `json:"amount_vnd,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"`
+	PricingCurrency string                `json:"pricing_currency,omitempty" binding:"omitempty,len=3" validate:"omitempty,len=3"`
+	PricingAmount   float64               `json:"pricing_amount,omitempty" binding:"omitempty,gt=0" validate:"omitempty,gt=0"`
+	Currency        string                `json:"currency,omitempty" validate:"omitempty,alphanum,max=10"`
+	Chain           string                `json:"chain,omitempty" binding:"omitempty,max=20" validate:"omitempty,max=20"`
+	OrderID         string                `json:"order_id,omitempty" binding:"omitempty,max=255" validate:"omitempty,max=255"`
+	Description     string                `json:"description,omitempty" binding:"omitempty,max=1000" validate:"omitempty,max=1000"`
+	CallbackURL     string                `json:"callback_url,omitempty" binding:"omitempty,url,max=500" validate:"omitempty,url,max=500"`
+	Splits          []PaymentSplitRequest `json:"splits,omitempty" binding:"omitempty,max=20,dive" validate:"omitempty,max=20,dive"`
+}
+
+// PaymentBatchResponse represents a payment batch and the outcome of its items
+type PaymentBatchResponse struct {
+	BatchID      string                     `json:"batch_id,omitempty"` // Only for batches created asynchronously
+	Status       string                     `json:"status"`
+	TotalCount   int                        `json:"total_count"`
+	CreatedCount int                        `json:"created_count"`
+	FailedCount  int                        `json:"failed_count"`
+	Items        []PaymentBatchItemResponse `json:"items"`
+	CreatedAt    *time.Time                 `json:"created_at,omitempty"`
+	CompletedAt  *time.Time                 `json:"completed_at,omitempty"`
+}
+
+// PaymentBatchItemResponse represents the outcome of one payment request of a batch
+type PaymentBatchItemResponse struct {
+	Index      int                 `json:"index"`
+	Status     string              `json:"status"` // pending, created or failed
+	OrderID    *string             `json:"order_id,omitempty"`
+	PaymentID  *string             `json:"payment_id,omitempty"`
+	Payment    *GetPaymentResponse `json:"payment,omitempty"` // Only for batches created within the request
+	PaymentURL *string             `json:"payment_url,omitempty"`
+	ErrorCode  *string             `json:"error_code,omitempty"`
+	Error      *string             `json:"error,omitempty"`
+}
+
+// PaymentBatchToResponse converts a domain.PaymentBatch to PaymentBatchResponse
+func PaymentBatchToResponse(batch *domain.PaymentBatch) PaymentBatchResponse {
+	response := PaymentBatchResponse{
+		BatchID:      batch.ID,
+		Status:       string(batch.Status),
+		TotalCount:   batch.TotalCount,
+		CreatedCount: batch.CreatedCount,
+		FailedCount:  batch.FailedCount,
+		Items:        make([]PaymentBatchItemResponse, len(batch.Items)),
+		CreatedAt:    &batch.CreatedAt,
+	}
+	if batch.CompletedAt.Valid {
+		completedAt := batch.CompletedAt.Time
+		response.CompletedAt = &completedAt
+	}
+
+	for i, item := range batch.Items {
+		itemResponse := PaymentBatchItemResponse{
+			Index:  item.Index,
+			Status: string(item.Status),
+		}
+		if item.OrderID != "" {
+			orderID := item.OrderID
+			itemResponse.OrderID = &orderID
+		}
+		if item.PaymentID != "" {
+			paymentID := item.PaymentID
+			itemResponse.PaymentID = &paymentID
+		}
+		if item.Error != "" {
+			itemError := item.Error
+			itemResponse.Error = &itemError
+		}
+		response.Items[i] = itemResponse
+	}
 
 	return response
 }
@@ -175,14 +1255,16 @@ func PaymentToResponse(payment *domain.Payment) GetPaymentResponse {
 // PaymentToListItem converts a domain.Payment to PaymentListItem
 func PaymentToListItem(payment *domain.Payment) PaymentListItem {
 	item := PaymentListItem{
-		PaymentID:    payment.ID,
-		AmountVND:    payment.AmountVND,
-		AmountCrypto: payment.AmountCrypto,
-		Currency:     payment.Currency,
-		Chain:        string(payment.Chain),
-		Status:       string(payment.Status),
-		CreatedAt:    payment.CreatedAt,
-		ExpiresAt:    payment.ExpiresAt,
+		PaymentID:       payment.ID,
+		AmountVND:       payment.AmountVND,
+		AmountCrypto:    payment.AmountCrypto,
+		PricingCurrency: payment.GetPricingCurrency(),
+		PricingAmount:   payment.PricingAmount,
+		Currency:        payment.Currency,
+		Chain:           string(payment.Chain),
+		Status:          string(payment.Status),
+		CreatedAt:       payment.CreatedAt,
+		ExpiresAt:       payment.ExpiresAt,
 	}
 
 	// Handle optional fields
@@ -209,37 +1291,44 @@ func PaymentToListItem(payment *domain.Payment) PaymentListItem {
 // PaymentStatusResponse represents the public payment status response (no authentication required)
 // This DTO is designed for the payer experience layer and excludes merchant-sensitive information
 type PaymentStatusResponse struct {
-	ID            string          `json:"id"`
-	Status        string          `json:"status"`
-	AmountCrypto  decimal.Decimal `json:"amount_crypto"`
-	AmountVND     decimal.Decimal `json:"amount_vnd"`
-	Currency      string          `json:"currency"`
-	Chain         string          `json:"chain"`
-	WalletAddress string          `json:"wallet_address"`
-	PaymentMemo   string          `json:"payment_memo"`
-	QRCodeData    string          `json:"qr_code_data"`
-	TxHash        *string         `json:"tx_hash,omitempty"`
-	Confirmations int             `json:"confirmations"`
-	ExpiresAt     time.Time       `json:"expires_at"`
-	CreatedAt     time.Time       `json:"created_at"`
-	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+	ID              string          `json:"id"`
+	Status          string          `json:"status"`
+	TestMode        bool            `json:"test_mode"` // Test payments must not be paid for real
+	AmountCrypto    decimal.Decimal `json:"amount_crypto"`
+	AmountReceived  decimal.Decimal `json:"amount_received"`
+	AmountRemaining decimal.Decimal `json:"amount_remaining"`
+	AmountVND       decimal.Decimal `json:"amount_vnd"`
+	Currency        string          `json:"currency"`
+	Chain           string          `json:"chain"`
+	WalletAddress   string          `json:"wallet_address"`
+	PaymentMemo     string          `json:"payment_memo"`
+	QRCodeData      string          `json:"qr_code_data"`
+	SolanaPayURL    *string         `json:"solana_pay_url,omitempty"` // Transaction request link, Solana payments only
+	TxHash          *string         `json:"tx_hash,omitempty"`
+	Confirmations   int             `json:"confirmations"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
 }
 
 // PaymentToPublicStatusResponse converts a domain.Payment to PaymentStatusResponse (public-safe)
 func PaymentToPublicStatusResponse(payment *domain.Payment) PaymentStatusResponse {
 	response := PaymentStatusResponse{
-		ID:            payment.ID,
-		Status:        string(payment.Status),
-		AmountCrypto:  payment.AmountCrypto,
-		AmountVND:     payment.AmountVND,
-		Currency:      payment.Currency,
-		Chain:         string(payment.Chain),
-		WalletAddress: payment.DestinationWallet,
-		PaymentMemo:   payment.PaymentReference,
-		QRCodeData:    formatQRCodeData(payment),
-		Confirmations: 0, // Default to 0, will be updated based on tx status
-		ExpiresAt:     payment.ExpiresAt,
-		CreatedAt:     payment.CreatedAt,
+		ID:              payment.ID,
+		Status:          string(payment.Status),
+		TestMode:        payment.TestMode,
+		AmountCrypto:    payment.AmountCrypto,
+		AmountReceived:  payment.AmountReceived,
+		AmountRemaining: payment.RemainingAmount(),
+		AmountVND:       payment.AmountVND,
+		Currency:        payment.Currency,
+		Chain:           string(payment.Chain),
+		WalletAddress:   payment.DestinationWallet,
+		PaymentMemo:     payment.PaymentReference,
+		QRCodeData:      formatQRCodeData(payment),
+		Confirmations:   0, // Default to 0, will be updated based on tx status
+		ExpiresAt:       payment.ExpiresAt,
+		CreatedAt:       payment.CreatedAt,
 	}
 
 	// Handle optional fields
@@ -253,11 +1342,11 @@ func PaymentToPublicStatusResponse(payment *domain.Payment) PaymentStatusRespons
 	}
 
 	// Set confirmations based on status
-	if payment.Status == domain.PaymentStatusCompleted {
+	if payment.IsCompleted() {
 		response.Confirmations = 100 // Fully confirmed
 	} else if payment.Status == domain.PaymentStatusConfirming {
 		response.Confirmations = 50 // Partially confirmed
-	} else if payment.Status == domain.PaymentStatusPending {
+	} else if payment.Status == domain.PaymentStatusPending || payment.Status == domain.PaymentStatusUnderpaid {
 		response.Confirmations = 1 // Transaction detected
 	}
 
@@ -271,16 +1360,30 @@ func formatQRCodeData(payment *domain.Payment) string {
 	case domain.ChainSolana:
 		// Solana Pay format: solana:{address}?amount={amount}&spl-token={mint}&reference={memo}&label={label}
 		// For now, return simplified format
-		return "solana:" + payment.DestinationWallet + "?amount=" + payment.AmountCrypto.String() + "&reference=" + payment.PaymentReference
-	case domain.ChainBSC:
-		// Ethereum/BSC format
-		return "ethereum:" + payment.DestinationWallet + "?value=" + payment.AmountCrypto.String()
+		return "solana:" + payment.DestinationWallet + "?amount=" + payment.RemainingAmount().String() + "&reference=" + payment.PaymentReference
 	default:
-		// Fallback: just return the wallet address
+		// Other payment URIs cannot carry the memo, return the wallet address and let the payer add the memo
 		return payment.DestinationWallet
 	}
 }
 
+// SolanaPayTransactionRequest is the body wallets post to a Solana Pay transaction request
+type SolanaPayTransactionRequest struct {
+	Account string `json:"account" binding:"required"` // Base58 public key of the payer
+}
+
+// SolanaPayInfoResponse is the Solana Pay GET response, returned without the API envelope
+type SolanaPayInfoResponse struct {
+	Label string `json:"label"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+// SolanaPayTransactionResponse is the Solana Pay POST response, returned without the API envelope
+type SolanaPayTransactionResponse struct {
+	Transaction string `json:"transaction"` // Base64 serialized transaction for the wallet to sign
+	Message     string `json:"message,omitempty"`
+}
+
 // APIResponse represents a standard API response
 type APIResponse struct {
 	Status  string      `json:"status"`
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
@@ -29,12 +31,24 @@ type ExchangeRateService interface {
 	GetExchangeRate(ctx context.Context, currency string) (decimal.Decimal, error)
 }
 
+// TokenFinder looks up registry tokens, payment QR codes carry the token address and decimals
+type TokenFinder interface {
+	Find(chain domain.Chain, symbol string) (*domain.Token, error)
+}
+
+// QRCodeConfig configures the payment QR codes
+type QRCodeConfig struct {
+	Tokens   TokenFinder            // Optional: without it only Solana USDT/USDC payments get a QR code
+	ChainIDs map[domain.Chain]int64 // EVM chain IDs, payments on chains without one get a plain address QR code
+}
+
 // PaymentHandler handles HTTP requests for payment operations
 type PaymentHandler struct {
 	paymentService      port.PaymentService
 	complianceService   ComplianceService
 	exchangeRateService ExchangeRateService
 	qrGenerator         *qrcode.Generator
+	qrConfig            QRCodeConfig
 	baseURL             string // Base URL for payment pages (e.g., https://pay.example.com)
 }
 
@@ -43,6 +57,7 @@ func NewPaymentHandler(
 	paymentService port.PaymentService,
 	complianceService ComplianceService,
 	exchangeRateService ExchangeRateService,
+	qrConfig QRCodeConfig,
 	baseURL string,
 ) *PaymentHandler {
 	return &PaymentHandler{
@@ -50,6 +65,7 @@ func NewPaymentHandler(
 		complianceService:   complianceService,
 		exchangeRateService: exchangeRateService,
 		qrGenerator:         qrcode.NewGenerator(),
+		qrConfig:            qrConfig,
 		baseURL:             baseURL,
 	}
 }
@@ -97,17 +113,26 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		return
 	}
 
+	// Payments are priced either in VND or in another fiat currency
+	if req.AmountVND == 0 && req.PricingAmount == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse("INVALID_AMOUNT", "Either amount_vnd or pricing_amount is required"))
+		return
+	}
+
 	// Convert amount from float64 to decimal.Decimal
 	amountVND := decimal.NewFromFloat(req.AmountVND)
 
 	// Build service request
 	serviceReq := port.CreatePaymentRequest{
-		MerchantID:  merchant.ID,
-		AmountVND:   amountVND,
-		Currency:    req.Currency,
-		OrderID:     req.OrderID,
-		Description: req.Description,
-		CallbackURL: req.CallbackURL,
+		MerchantID:      merchant.ID,
+		AmountVND:       amountVND,
+		PricingCurrency: req.PricingCurrency,
+		PricingAmount:   decimal.NewFromFloat(req.PricingAmount),
+		Currency:        req.Currency,
+		OrderID:         req.OrderID,
+		Description:     req.Description,
+		CallbackURL:     req.CallbackURL,
+		QuoteID:         req.QuoteID,
 	}
 
 	// Set chain if provided
@@ -115,6 +140,15 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		serviceReq.Chain = domain.Chain(req.Chain)
 	}
 
+	serviceReq.Splits = paymentSplitsFromRequest(req.Splits)
+
+	// The payer proves ownership of the Travel Rule wallet by signing an ownership challenge
+	if req.TravelRule != nil && req.TravelRule.Signature != "" {
+		serviceReq.FromAddress = req.TravelRule.PayerWalletAddress
+		serviceReq.Signature = req.TravelRule.Signature
+		serviceReq.SignedMessage = req.TravelRule.SignedMessage
+	}
+
 	// Create payment
 	payment, err := h.paymentService.CreatePayment(ctx, serviceReq)
 	if err != nil {
@@ -194,7 +228,7 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	}
 
 	// Generate QR code
-	qrCodeBase64, err := h.generateQRCode(payment)
+	qrCodeURL, err := h.generateQRCode(ctx, payment)
 	if err != nil {
 		logger.WithContext(ctx).WithFields(logrus.Fields{
 			"error":      err.Error(),
@@ -203,7 +237,7 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 
 		// Log error but don't fail the request - QR code can be generated later
 		// Continue with empty QR code
-		qrCodeBase64 = ""
+		qrCodeURL = "data:image/png;base64,"
 	}
 
 	// Build response
@@ -219,11 +253,22 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		ExpiresAt:         payment.ExpiresAt,
 		FeeVND:            payment.FeeVND,
 		NetAmountVND:      payment.NetAmountVND,
+		PricingCurrency:   payment.GetPricingCurrency(),
+		PricingAmount:     payment.PricingAmount,
+		PricingRate:       payment.PricingRate,
+		PricingFee:        payment.PricingFee,
+		PricingNetAmount:  payment.PricingNetAmount,
+		Splits:            PaymentSplitsToResponse(payment.Splits),
 		Status:            string(payment.Status),
-		QRCodeURL:         fmt.Sprintf("data:image/png;base64,%s", qrCodeBase64),
+		TestMode:          payment.TestMode,
+		QRCodeURL:         qrCodeURL,
 		PaymentURL:        h.getPaymentURL(payment.ID),
 		CreatedAt:         payment.CreatedAt,
 	}
+	if payment.QuoteID.Valid {
+		quoteID := payment.QuoteID.String
+		response.QuoteID = &quoteID
+	}
 
 	logger.WithContext(ctx).WithFields(logrus.Fields{
 		"payment_id":  payment.ID,
@@ -238,7 +283,7 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 
 // GetPayment handles GET /api/v1/payments/:id
 // @Summary Get payment details
-// @Description Retrieve details of a specific payment
+// @Description Retrieve details of a specific payment, with its transfers and status history
 // @Tags payments
 // @Accept json
 // @Produce json
@@ -300,6 +345,26 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 	// Convert to response DTO
 	response := PaymentToResponse(payment)
 
+	transfers, err := h.paymentService.ListPaymentTransfers(ctx, payment.ID)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":      err.Error(),
+			"payment_id": payment.ID,
+		}).Warn("Failed to list payment transfers")
+	} else {
+		response.Transfers = PaymentTransfersToResponse(transfers)
+	}
+
+	history, err := h.paymentService.ListPaymentStatusHistory(ctx, payment.ID)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":      err.Error(),
+			"payment_id": payment.ID,
+		}).Warn("Failed to list payment status history")
+	} else {
+		response.StatusHistory = PaymentStatusHistoryToResponse(history)
+	}
+
 	logger.WithContext(ctx).WithFields(logrus.Fields{
 		"payment_id":  payment.ID,
 		"merchant_id": merchant.ID,
@@ -310,14 +375,31 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 }
 
 // ListPayments handles GET /api/v1/payments
-// @Summary List payments
-// @Description List all payments for the authenticated merchant with pagination
+// @Summary Search payments
+// @Description Search the payments of the authenticated merchant with filters, newest first. Pages are linked by cursor: pass next_cursor of a page as cursor to get the next one, with the same filters and sort order.
 // @Tags payments
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number" default(1)
-// @Param per_page query int false "Items per page" default(20)
-// @Param status query string false "Filter by status" Enums(created, pending, confirming, completed, expired, failed)
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort_by query string false "Sort field" Enums(created_at, amount_vnd) default(created_at)
+// @Param sort_order query string false "Sort order" Enums(asc, desc) default(desc)
+// @Param status query string false "Comma-separated statuses, e.g. completed,overpaid"
+// @Param chain query string false "Blockchain network"
+// @Param currency query string false "Token"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param confirmed_from query string false "Confirmed at or after (RFC 3339)"
+// @Param confirmed_to query string false "Confirmed before (RFC 3339)"
+// @Param order_id query string false "Merchant order ID"
+// @Param tx_hash query string false "Transaction hash"
+// @Param from_address query string false "Payer wallet address"
+// @Param min_amount_vnd query number false "Minimum VND amount"
+// @Param max_amount_vnd query number false "Maximum VND amount"
+// @Param pricing_currency query string false "Pricing currency (ISO 4217), required by min_amount and max_amount"
+// @Param min_amount query number false "Minimum amount in the pricing currency"
+// @Param max_amount query number false "Maximum amount in the pricing currency"
+// @Param metadata[key] query string false "Metadata key equal to the value, repeatable"
 // @Success 200 {object} APIResponse{data=ListPaymentsResponse}
 // @Failure 400 {object} APIResponse
 // @Failure 401 {object} APIResponse
@@ -354,91 +436,259 @@ func (h *PaymentHandler) ListPayments(c *gin.Context) {
 		return
 	}
 
-	// Set defaults
-	page := req.Page
-	if page < 1 {
-		page = 1
+	search, err := req.ToPaymentSearch(merchant.ID, c.QueryMap("metadata"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
 	}
-	perPage := req.PerPage
-	if perPage < 1 {
-		perPage = 20
+
+	page, err := h.paymentService.SearchPayments(ctx, search)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to list payments")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
 	}
-	if perPage > 100 {
-		perPage = 100
+
+	response := PaymentPageToResponse(page)
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"merchant_id": merchant.ID,
+		"count":       len(page.Payments),
+		"has_more":    response.HasMore,
+	}).Debug("Payments listed successfully")
+
+	c.JSON(http.StatusOK, SuccessResponse(response))
+}
+
+// CancelPayment handles POST /api/v1/payments/:id/cancel
+// @Summary Cancel a payment
+// @Description Cancel a payment that was not paid yet (created or pending). Transfers that still arrive are handled by the late payment policy.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body CancelPaymentRequest false "Cancellation reason"
+// @Success 200 {object} APIResponse{data=GetPaymentResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payments/{id}/cancel [post]
+// @Security ApiKeyAuth
+func (h *PaymentHandler) CancelPayment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
 	}
 
-	// Calculate offset
-	offset := (page - 1) * perPage
+	var req CancelPaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+				"INVALID_REQUEST",
+				"Invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
 
-	// Get payments
-	payments, err := h.paymentService.ListPaymentsByMerchant(ctx, merchant.ID, perPage, offset)
+	paymentID := c.Param("id")
+	payment, err := h.paymentService.CancelPayment(ctx, paymentID, merchant.ID, req.Reason)
 	if err != nil {
 		logger.WithContext(ctx).WithFields(logrus.Fields{
 			"error":       err.Error(),
 			"merchant_id": merchant.ID,
-		}).Error("Failed to list payments")
+			"payment_id":  paymentID,
+		}).Error("Failed to cancel payment")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(PaymentToResponse(payment)))
+}
+
+// ExtendPayment handles POST /api/v1/payments/:id/extend
+// @Summary Extend the expiry of a payment
+// @Description Give the payer more time on a payment that was not paid yet. The exchange rate is re-quoted, except for payments created from a quote which keep the quoted amount, and the payment expires extension_minutes from now, within the per-payment limits.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body ExtendPaymentRequest false "Expiry extension"
+// @Success 200 {object} APIResponse{data=GetPaymentResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payments/{id}/extend [post]
+// @Security ApiKeyAuth
+func (h *PaymentHandler) ExtendPayment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req ExtendPaymentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+				"INVALID_REQUEST",
+				"Invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	paymentID := c.Param("id")
+	extension := time.Duration(req.ExtensionMinutes) * time.Minute
+	payment, err := h.paymentService.ExtendPayment(ctx, paymentID, merchant.ID, extension)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"payment_id":  paymentID,
+		}).Error("Failed to extend payment")
 
-		c.JSON(http.StatusInternalServerError, ErrorResponse("INTERNAL_ERROR", "Failed to retrieve payments"))
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
 		return
 	}
 
-	// Convert to list items
-	paymentItems := make([]PaymentListItem, len(payments))
-	for i, payment := range payments {
-		paymentItems[i] = PaymentToListItem(payment)
+	c.JSON(http.StatusOK, SuccessResponse(PaymentToResponse(payment)))
+}
+
+// SimulatePayment handles POST /api/v1/test/payments/:id/simulate
+// @Summary Simulate the outcome of a test payment
+// @Description Settle a test payment of a sandbox merchant (sk_test_ API key) as if the payer paid in full (paid), paid half (underpaid), paid too much (overpaid), never paid (expired) or paid after expiry (late). The payment goes through the same confirmation and expiry code as live payments and the merchant receives the same webhooks.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body SimulatePaymentRequest true "Simulation outcome"
+// @Success 200 {object} APIResponse{data=GetPaymentResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/test/payments/{id}/simulate [post]
+// @Security ApiKeyAuth
+func (h *PaymentHandler) SimulatePayment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
 	}
 
-	// Build pagination metadata
-	// Note: For a complete implementation, we should get total count from repository
-	// For now, we'll provide basic pagination info
-	pagination := &PaginationMeta{
-		CurrentPage: page,
-		PerPage:     perPage,
-		TotalPages:  0, // TODO: Calculate from total count
-		TotalCount:  0, // TODO: Get from repository
+	if !merchant.IsSandbox() {
+		c.JSON(http.StatusForbidden, ErrorResponse("TEST_MODE_REQUIRED", "Simulations require an sk_test_ API key"))
+		return
 	}
 
-	response := ListPaymentsResponse{
-		Payments:   paymentItems,
-		Pagination: pagination,
+	var req SimulatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
 	}
 
-	logger.WithContext(ctx).WithFields(logrus.Fields{
-		"merchant_id": merchant.ID,
-		"count":       len(payments),
-		"page":        page,
-		"per_page":    perPage,
-	}).Debug("Payments listed successfully")
+	paymentID := c.Param("id")
+	payment, err := h.paymentService.SimulatePayment(ctx, paymentID, merchant.ID, domain.SimulationOutcome(req.Outcome))
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"payment_id":  paymentID,
+			"outcome":     req.Outcome,
+		}).Warn("Failed to simulate payment")
 
-	c.JSON(http.StatusOK, SuccessResponse(response))
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(PaymentToResponse(payment)))
 }
 
 // Helper functions
 
-// generateQRCode generates a QR code for the payment
-func (h *PaymentHandler) generateQRCode(payment *domain.Payment) (string, error) {
-	// Determine token mint based on currency
-	var tokenMint string
-	switch payment.Currency {
-	case "USDT":
-		tokenMint = qrcode.USDTMintSolana
-	case "USDC":
-		tokenMint = qrcode.USDCMintSolana
-	default:
-		return "", fmt.Errorf("unsupported currency for QR code: %s", payment.Currency)
+// generateQRCode generates the QR code of the payment as a PNG data URL
+// The code holds the payment URI of the payment chain: Solana Pay, EIP-681, TRON or the plain address
+func (h *PaymentHandler) generateQRCode(ctx context.Context, payment *domain.Payment) (string, error) {
+	qrConfig, err := h.paymentQRConfig(ctx, payment)
+	if err != nil {
+		return "", err
 	}
 
-	// Generate QR code
+	qrConfig.Size = qrcode.QRCodeSizeMedium
+	return h.qrGenerator.GeneratePaymentQRDataURL(qrConfig)
+}
+
+// paymentQRConfig builds the QR code configuration of a payment from its registry token
+// The amount is what the payer still owes, a partially paid payment asks for the rest only
+// EIP-681 and TRON URIs carry no memo, so payments matched by memo get the plain address and show the memo apart
+func (h *PaymentHandler) paymentQRConfig(ctx context.Context, payment *domain.Payment) (qrcode.PaymentQRConfig, error) {
 	qrConfig := qrcode.PaymentQRConfig{
+		Chain:         string(payment.Chain),
+		ChainID:       h.qrConfig.ChainIDs[payment.Chain],
 		WalletAddress: payment.DestinationWallet,
-		Amount:        payment.AmountCrypto,
-		TokenMint:     tokenMint,
+		Amount:        payment.RemainingAmount(),
 		Memo:          payment.PaymentReference,
 		Label:         "Payment",
-		Size:          qrcode.QRCodeSizeMedium,
 	}
 
-	return h.qrGenerator.GeneratePaymentQR(qrConfig)
+	// Solana Pay always carries the memo, other chains drop it for a deposit address that only receives this payment
+	if payment.Chain != domain.ChainSolana && h.paymentService.UsesDepositAddress(ctx, payment) {
+		qrConfig.Memo = ""
+	}
+
+	if h.qrConfig.Tokens != nil {
+		token, err := h.qrConfig.Tokens.Find(payment.Chain, payment.Currency)
+		if err != nil {
+			return qrConfig, fmt.Errorf("unsupported token for QR code: %s on %s: %w", payment.Currency, payment.Chain, err)
+		}
+		qrConfig.TokenMint = token.Address
+		qrConfig.TokenDecimals = token.Decimals
+	}
+
+	// Without a registry address Solana payments use the well-known mints
+	if qrConfig.TokenMint == "" && payment.Chain == domain.ChainSolana {
+		switch payment.Currency {
+		case "USDT":
+			qrConfig.TokenMint = qrcode.USDTMintSolana
+		case "USDC":
+			qrConfig.TokenMint = qrcode.USDCMintSolana
+		default:
+			return qrConfig, fmt.Errorf("unsupported currency for QR code: %s", payment.Currency)
+		}
+	}
+
+	return qrConfig, nil
 }
 
 // getPaymentURL constructs the payment page URL
@@ -485,6 +735,15 @@ func (h *PaymentHandler) GetPublicPaymentStatus(c *gin.Context) {
 
 	// Convert to public status response (excludes merchant-sensitive information)
 	response := PaymentToPublicStatusResponse(payment)
+	if qrConfig, err := h.paymentQRConfig(ctx, payment); err == nil {
+		if paymentURI, err := qrcode.BuildPaymentURI(qrConfig); err == nil {
+			response.QRCodeData = paymentURI
+		}
+	}
+	if payment.Chain == domain.ChainSolana && payment.CanBeConfirmed() && !payment.TestMode {
+		solanaPayURL := SolanaPayTransactionRequestURL(h.baseURL, payment.ID)
+		response.SolanaPayURL = &solanaPayURL
+	}
 
 	logger.WithContext(ctx).WithFields(logrus.Fields{
 		"payment_id": payment.ID,
@@ -496,6 +755,20 @@ func (h *PaymentHandler) GetPublicPaymentStatus(c *gin.Context) {
 
 // mapServiceError maps service layer errors to HTTP status codes and error messages
 func (h *PaymentHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidExpiryExtension):
+		return http.StatusBadRequest, "INVALID_EXPIRY_EXTENSION", err.Error()
+	case errors.Is(err, domain.ErrInvalidPaymentCursor):
+		return http.StatusBadRequest, "INVALID_CURSOR", "Invalid cursor, it must come from a search with the same sort order"
+	case errors.Is(err, domain.ErrInvalidPaymentSearch):
+		return http.StatusBadRequest, "INVALID_REQUEST", err.Error()
+	}
+
+	return mapPaymentServiceError(err)
+}
+
+// mapPaymentServiceError maps payment service errors, shared by the handlers that create payments
+func mapPaymentServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
 	// Default to internal server error
 	statusCode = http.StatusInternalServerError
 	errorCode = "INTERNAL_ERROR"
@@ -522,10 +795,38 @@ func (h *PaymentHandler) mapServiceError(err error) (statusCode int, errorCode s
 		statusCode = http.StatusBadRequest
 		errorCode = "INVALID_PAYMENT_STATE"
 		errorMessage = "Payment is in invalid state for this operation"
+	case errors.Is(err, domain.ErrInvalidPaymentTransition):
+		statusCode = http.StatusBadRequest
+		errorCode = "INVALID_PAYMENT_STATE"
+		errorMessage = "Payment is in invalid state for this operation"
+	case errors.Is(err, domain.ErrPaymentVersionConflict):
+		statusCode = http.StatusConflict
+		errorCode = "PAYMENT_CONFLICT"
+		errorMessage = "Payment was updated concurrently, retry the request"
+	case errors.Is(err, domain.ErrUnsupportedPricingCurrency):
+		statusCode = http.StatusBadRequest
+		errorCode = "UNSUPPORTED_PRICING_CURRENCY"
+		errorMessage = "Pricing currency is not supported, use one of " + strings.Join(domain.SupportedPricingCurrencies, ", ")
 	case errors.Is(err, domain.ErrInvalidChain):
 		statusCode = http.StatusBadRequest
 		errorCode = "INVALID_CHAIN"
 		errorMessage = "Invalid or unsupported blockchain chain"
+	case errors.Is(err, domain.ErrInvalidSignature):
+		statusCode = http.StatusBadRequest
+		errorCode = "INVALID_SIGNATURE"
+		errorMessage = err.Error()
+	case errors.Is(err, domain.ErrInvalidWalletAddress):
+		statusCode = http.StatusBadRequest
+		errorCode = "INVALID_WALLET_ADDRESS"
+		errorMessage = err.Error()
+	case errors.Is(err, domain.ErrOwnershipChallengesNotConfigured):
+		statusCode = http.StatusServiceUnavailable
+		errorCode = "OWNERSHIP_CHALLENGES_UNAVAILABLE"
+		errorMessage = "Wallet ownership proofs are not available on EVM chains"
+	case errors.Is(err, domain.ErrTokenAmountOutOfRange):
+		statusCode = http.StatusBadRequest
+		errorCode = "AMOUNT_OUT_OF_RANGE"
+		errorMessage = err.Error()
 	case errors.Is(err, domain.ErrMerchantNotFound):
 		statusCode = http.StatusNotFound
 		errorCode = "MERCHANT_NOT_FOUND"
@@ -534,6 +835,54 @@ func (h *PaymentHandler) mapServiceError(err error) (statusCode int, errorCode s
 		statusCode = http.StatusForbidden
 		errorCode = "MERCHANT_NOT_APPROVED"
 		errorMessage = "Merchant is not approved to accept payments"
+	case errors.Is(err, domain.ErrQuoteNotFound):
+		statusCode = http.StatusNotFound
+		errorCode = "QUOTE_NOT_FOUND"
+		errorMessage = "Quote not found"
+	case errors.Is(err, domain.ErrQuoteExpired):
+		statusCode = http.StatusGone
+		errorCode = "QUOTE_EXPIRED"
+		errorMessage = "Quote has expired, request a new quote"
+	case errors.Is(err, domain.ErrQuoteAlreadyUsed):
+		statusCode = http.StatusConflict
+		errorCode = "QUOTE_ALREADY_USED"
+		errorMessage = "Quote has already been used by another payment"
+	case errors.Is(err, domain.ErrQuoteMismatch):
+		statusCode = http.StatusBadRequest
+		errorCode = "QUOTE_MISMATCH"
+		errorMessage = "Payment amount, chain or currency does not match the quote"
+	case errors.Is(err, domain.ErrInvalidQuoteSignature):
+		statusCode = http.StatusBadRequest
+		errorCode = "INVALID_QUOTE"
+		errorMessage = "Quote signature is invalid"
+	case errors.Is(err, domain.ErrInvalidPaymentSplits):
+		statusCode = http.StatusBadRequest
+		errorCode = "INVALID_SPLITS"
+		errorMessage = err.Error()
+	case errors.Is(err, domain.ErrSplitRecipientNotApproved):
+		statusCode = http.StatusUnprocessableEntity
+		errorCode = "SPLIT_RECIPIENT_NOT_APPROVED"
+		errorMessage = err.Error()
+	case errors.Is(err, domain.ErrQuotesNotConfigured):
+		statusCode = http.StatusServiceUnavailable
+		errorCode = "QUOTES_UNAVAILABLE"
+		errorMessage = "Rate-locked quotes are not available"
+	case errors.Is(err, domain.ErrSplitRecipientTestMode):
+		statusCode = http.StatusUnprocessableEntity
+		errorCode = "SPLIT_RECIPIENT_MODE_MISMATCH"
+		errorMessage = "Split recipients must be sandbox merchants for test payments and live merchants otherwise"
+	case errors.Is(err, domain.ErrNotTestPayment):
+		statusCode = http.StatusConflict
+		errorCode = "NOT_TEST_PAYMENT"
+		errorMessage = "Only test payments can be simulated"
+	case errors.Is(err, domain.ErrTestPaymentNotSimulated):
+		statusCode = http.StatusConflict
+		errorCode = "TEST_PAYMENT"
+		errorMessage = "Test payments are only settled by simulation"
+	case errors.Is(err, domain.ErrInvalidSimulationOutcome):
+		statusCode = http.StatusBadRequest
+		errorCode = "INVALID_SIMULATION_OUTCOME"
+		errorMessage = err.Error()
 	}
 
 	return statusCode, errorCode, errorMessage
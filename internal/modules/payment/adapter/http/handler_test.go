@@ -0,0 +1,140 @@
+package http
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	merchantDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
+)
+
+// memPaymentRepository keeps payments in memory
+type memPaymentRepository struct {
+	domain.PaymentRepository
+	payments map[string]*domain.Payment
+}
+
+func (r *memPaymentRepository) GetByID(id string) (*domain.Payment, error) {
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, domain.ErrPaymentNotFound
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (r *memPaymentRepository) Update(payment *domain.Payment) error {
+	stored := *payment
+	r.payments[payment.ID] = &stored
+	return nil
+}
+
+func (r *memPaymentRepository) Transition(payment *domain.Payment, transition *domain.PaymentStatusTransition) error {
+	return r.Update(payment)
+}
+
+func (r *memPaymentRepository) ApplyTransfer(payment *domain.Payment, transition *domain.PaymentStatusTransition, transfer *domain.PaymentTransfer) error {
+	return r.Update(payment)
+}
+
+// memTransferRepository has no transfers, every simulated transfer is new
+type memTransferRepository struct {
+	domain.PaymentTransferRepository
+}
+
+func (r *memTransferRepository) GetByTxHash(chain domain.Chain, txHash string) (*domain.PaymentTransfer, error) {
+	return nil, domain.ErrTransferNotFound
+}
+
+func (r *memTransferRepository) ListByPayment(paymentID string) ([]*domain.PaymentTransfer, error) {
+	return nil, nil
+}
+
+// stubMerchantRepository returns the payment tolerance of the merchant
+type stubMerchantRepository struct {
+	domain.MerchantRepository
+	overpayment decimal.Decimal
+}
+
+func (r *stubMerchantRepository) GetMerchantPaymentTolerance(merchantID string) (decimal.Decimal, decimal.Decimal, error) {
+	return decimal.NewFromFloat(0.01), r.overpayment, nil
+}
+
+func (r *stubMerchantRepository) UpdateMerchantVolume(merchantID string, amountUSD decimal.Decimal) error {
+	return nil
+}
+
+func (r *stubMerchantRepository) GetMerchantLatePaymentPolicy(merchantID string) (string, error) {
+	return "review", nil
+}
+
+func TestSimulatePayment_AppliesMerchantTolerance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		merchantRepo domain.MerchantRepository
+		expected     domain.PaymentStatus
+	}{
+		{"default tolerance", nil, domain.PaymentStatusOverpaid},
+		{"merchant accepts overpayments up to 60%", &stubMerchantRepository{overpayment: decimal.NewFromFloat(0.6)}, domain.PaymentStatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments := &memPaymentRepository{payments: map[string]*domain.Payment{
+				"payment-1": {
+					ID:           "payment-1",
+					MerchantID:   "merchant-1",
+					Status:       domain.PaymentStatusPending,
+					Chain:        domain.ChainSolana,
+					Currency:     "USDT",
+					AmountVND:    decimal.NewFromInt(2500000),
+					AmountCrypto: decimal.NewFromInt(100),
+					TestMode:     true,
+					ExpiresAt:    time.Now().Add(time.Hour),
+				},
+			}}
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+			paymentService := service.NewPaymentService(payments, &memTransferRepository{}, tt.merchantRepo, nil, nil, nil, service.PaymentServiceConfig{}, logger)
+			handler := NewPaymentHandler(paymentService, nil, nil, QRCodeConfig{}, "")
+
+			router := gin.New()
+			router.POST("/test/payments/:id/simulate", func(c *gin.Context) {
+				c.Set(middleware.MerchantContextKey, &merchantDomain.Merchant{
+					ID:        "merchant-1",
+					SandboxOf: sql.NullString{String: "merchant-live", Valid: true},
+				})
+				handler.SimulatePayment(c)
+			})
+
+			body := bytes.NewBufferString(`{"outcome":"overpaid"}`)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/test/payments/payment-1/simulate", body))
+
+			require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+			var response struct {
+				Data struct {
+					Status string `json:"status"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, string(tt.expected), response.Data.Status)
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// InvoiceHandler handles HTTP requests for invoices
+type InvoiceHandler struct {
+	invoiceService port.InvoiceService
+	baseURL        string // Base URL for payment pages (e.g., https://pay.example.com)
+}
+
+// NewInvoiceHandler creates a new invoice handler
+func NewInvoiceHandler(invoiceService port.InvoiceService, baseURL string) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		baseURL:        baseURL,
+	}
+}
+
+// CreateInvoice handles POST /api/v1/invoices
+// @Summary Create an invoice
+// @Description Create an invoice with line items, tax and a due date. The customer pays it on the hosted invoice page, in one or more payments.
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param request body CreateInvoiceRequest true "Invoice request"
+// @Success 201 {object} APIResponse{data=InvoiceResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/invoices [post]
+// @Security ApiKeyAuth
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	lineItems := make([]domain.InvoiceLineItem, len(req.LineItems))
+	for i, item := range req.LineItems {
+		lineItems[i] = domain.InvoiceLineItem{
+			Description: item.Description,
+			Quantity:    decimal.NewFromFloat(item.Quantity),
+			UnitPrice:   decimal.NewFromFloat(item.UnitPrice),
+		}
+	}
+
+	invoice, err := h.invoiceService.CreateInvoice(ctx, port.CreateInvoiceRequest{
+		MerchantID:      merchant.ID,
+		InvoiceNumber:   req.InvoiceNumber,
+		CustomerName:    req.CustomerName,
+		CustomerEmail:   req.CustomerEmail,
+		Description:     req.Description,
+		PricingCurrency: req.PricingCurrency,
+		LineItems:       lineItems,
+		TaxRate:         decimal.NewFromFloat(req.TaxRate),
+		DueDate:         req.DueDate,
+		Metadata:        req.Metadata,
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to create invoice")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, serviceErrorResponse(err, errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"invoice_id":  invoice.ID,
+		"merchant_id": merchant.ID,
+	}).Info("Invoice created successfully")
+
+	c.JSON(http.StatusCreated, SuccessResponse(InvoiceToResponse(invoice, h.getInvoiceURL(invoice.ID))))
+}
+
+// ListInvoices handles GET /api/v1/invoices
+// @Summary List invoices
+// @Description List the invoices of the authenticated merchant, newest first
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param per_page query int false "Items per page (default: 20, max: 100)"
+// @Param status query string false "Filter by status (open, partially_paid, paid, void)"
+// @Success 200 {object} APIResponse{data=ListInvoicesResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/invoices [get]
+// @Security ApiKeyAuth
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req ListInvoicesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	invoices, total, err := h.invoiceService.ListInvoices(ctx, merchant.ID, domain.InvoiceStatus(req.Status), perPage, (page-1)*perPage)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to list invoices")
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse("INTERNAL_ERROR", "Failed to retrieve invoices"))
+		return
+	}
+
+	items := make([]InvoiceResponse, len(invoices))
+	for i, invoice := range invoices {
+		items[i] = InvoiceToResponse(invoice, h.getInvoiceURL(invoice.ID))
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(ListInvoicesResponse{
+		Invoices:   items,
+		Pagination: newPaginationMeta(page, perPage, total),
+	}))
+}
+
+// GetInvoice handles GET /api/v1/invoices/:id
+// @Summary Get an invoice
+// @Description Retrieve an invoice of the authenticated merchant with the amount paid and due
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} APIResponse{data=InvoiceResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/invoices/{id} [get]
+// @Security ApiKeyAuth
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoice(ctx, c.Param("id"), merchant.ID)
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(InvoiceToResponse(invoice, h.getInvoiceURL(invoice.ID))))
+}
+
+// VoidInvoice handles POST /api/v1/invoices/:id/void
+// @Summary Void an invoice
+// @Description Cancel an invoice that is not fully paid, no new payments can be created for it
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} APIResponse{data=InvoiceResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/invoices/{id}/void [post]
+// @Security ApiKeyAuth
+func (h *InvoiceHandler) VoidInvoice(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	invoiceID := c.Param("id")
+	invoice, err := h.invoiceService.VoidInvoice(ctx, invoiceID, merchant.ID)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"invoice_id":  invoiceID,
+		}).Error("Failed to void invoice")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(InvoiceToResponse(invoice, h.getInvoiceURL(invoice.ID))))
+}
+
+// GetPublicInvoice handles GET /api/v1/public/invoices/:id
+// @Summary Get a hosted invoice
+// @Description Retrieve an invoice for its customer (no authentication required) - Payer Experience Layer
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} APIResponse{data=PublicInvoiceResponse}
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/public/invoices/{id} [get]
+func (h *InvoiceHandler) GetPublicInvoice(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	invoice, err := h.invoiceService.GetInvoice(ctx, c.Param("id"), "")
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(InvoiceToPublicResponse(invoice)))
+}
+
+// PayInvoice handles POST /api/v1/public/invoices/:id/payments
+// @Summary Pay a hosted invoice
+// @Description Create a payment for the amount due of an invoice, or part of it (no authentication required) - Payer Experience Layer
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Param request body PayInvoiceRequest false "Amount, token and chain of the payment"
+// @Success 201 {object} APIResponse{data=PaymentStatusResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/public/invoices/{id}/payments [post]
+func (h *InvoiceHandler) PayInvoice(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req PayInvoiceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+				"INVALID_REQUEST",
+				"Invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	invoiceID := c.Param("id")
+	payment, err := h.invoiceService.PayInvoice(ctx, port.PayInvoiceRequest{
+		InvoiceID: invoiceID,
+		Amount:    decimal.NewFromFloat(req.Amount),
+		Currency:  req.Currency,
+		Chain:     domain.Chain(req.Chain),
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":      err.Error(),
+			"invoice_id": invoiceID,
+		}).Warn("Failed to create invoice payment")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, serviceErrorResponse(err, errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"invoice_id": invoiceID,
+		"payment_id": payment.ID,
+	}).Info("Invoice payment created")
+
+	c.JSON(http.StatusCreated, SuccessResponse(PaymentToPublicStatusResponse(payment)))
+}
+
+// getInvoiceURL constructs the hosted invoice page URL
+func (h *InvoiceHandler) getInvoiceURL(invoiceID string) string {
+	return fmt.Sprintf("%s/invoices/%s", h.baseURL, invoiceID)
+}
+
+// mapServiceError maps invoice service errors to HTTP status codes and error messages
+func (h *InvoiceHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrInvoiceNotFound):
+		return http.StatusNotFound, "INVOICE_NOT_FOUND", "Invoice not found"
+	case errors.Is(err, domain.ErrInvoiceNumberExists):
+		return http.StatusConflict, "INVOICE_NUMBER_EXISTS", "An invoice with this number already exists"
+	case errors.Is(err, domain.ErrInvoiceVoid):
+		return http.StatusConflict, "INVOICE_VOID", "Invoice is void"
+	case errors.Is(err, domain.ErrInvoiceAlreadyPaid):
+		return http.StatusConflict, "INVOICE_ALREADY_PAID", "Invoice is already paid"
+	}
+
+	return mapPaymentServiceError(err)
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// CreateOwnershipChallenge handles POST /api/v1/ownership-challenges
+// @Summary Issue a wallet ownership challenge
+// @Description Issue a nonce for an EVM payer to sign as proof of wallet ownership. Pass the signed message or typed data and the signature in travel_rule when creating the payment. Each challenge is accepted once, for this wallet and chain, until expires_at.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param request body CreateOwnershipChallengeRequest true "Wallet to prove ownership of"
+// @Success 201 {object} APIResponse{data=OwnershipChallengeResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Failure 503 {object} APIResponse
+// @Router /api/v1/ownership-challenges [post]
+// @Security ApiKeyAuth
+func (h *PaymentHandler) CreateOwnershipChallenge(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreateOwnershipChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	challenge, err := h.paymentService.CreateOwnershipChallenge(ctx, port.CreateOwnershipChallengeRequest{
+		Chain:         domain.Chain(req.Chain),
+		WalletAddress: req.WalletAddress,
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":          err.Error(),
+			"merchant_id":    merchant.ID,
+			"chain":          req.Chain,
+			"wallet_address": req.WalletAddress,
+		}).Error("Failed to create ownership challenge")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse(OwnershipChallengeToResponse(challenge, h.qrConfig.ChainIDs[challenge.Chain])))
+}
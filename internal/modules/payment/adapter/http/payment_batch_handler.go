@@ -0,0 +1,275 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// PaymentBatchHandler handles HTTP requests for payment batches
+type PaymentBatchHandler struct {
+	batchService port.PaymentBatchService
+	baseURL      string // Base URL for payment pages (e.g., https://pay.example.com)
+}
+
+// NewPaymentBatchHandler creates a new payment batch handler
+func NewPaymentBatchHandler(batchService port.PaymentBatchService, baseURL string) *PaymentBatchHandler {
+	return &PaymentBatchHandler{
+		batchService: batchService,
+		baseURL:      baseURL,
+	}
+}
+
+// CreatePaymentBatch handles POST /api/v1/payments/batch
+// @Summary Create many payments at once
+// @Description Create up to 100 payments within the request, or up to 500 in the background with async. Each payment is validated and created independently and has its own result or error. Async batches are returned as pending and a batch.completed webhook delivers the results.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param request body CreatePaymentBatchRequest true "Payment batch request"
+// @Success 200 {object} APIResponse{data=PaymentBatchResponse}
+// @Success 202 {object} APIResponse{data=PaymentBatchResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payments/batch [post]
+// @Security ApiKeyAuth
+func (h *PaymentBatchHandler) CreatePaymentBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreatePaymentBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	// Items are validated one by one, an invalid item does not reject the others
+	items := make([]PaymentBatchItemResponse, len(req.Payments))
+	var valid []int
+	var invalid []string
+	for i := range req.Payments {
+		items[i] = PaymentBatchItemResponse{Index: i, Status: string(domain.PaymentBatchItemStatusPending)}
+		if orderID := req.Payments[i].OrderID; orderID != "" {
+			items[i].OrderID = &orderID
+		}
+
+		if errCode, errMessage := validatePaymentBatchItem(&req.Payments[i]); errCode != "" {
+			items[i].Status = string(domain.PaymentBatchItemStatusFailed)
+			items[i].ErrorCode = &errCode
+			items[i].Error = &errMessage
+			invalid = append(invalid, fmt.Sprintf("payments[%d]: %s", i, errMessage))
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if req.Async {
+		h.submitPaymentBatch(c, merchant.ID, req.Payments, invalid)
+		return
+	}
+
+	if len(valid) > 0 {
+		reqs := make([]port.CreatePaymentRequest, len(valid))
+		for i, index := range valid {
+			reqs[i] = paymentBatchItemToRequest(merchant.ID, req.Payments[index])
+		}
+
+		results, err := h.batchService.CreatePaymentBatch(ctx, merchant.ID, reqs)
+		if err != nil {
+			logger.WithContext(ctx).WithFields(logrus.Fields{
+				"error":       err.Error(),
+				"merchant_id": merchant.ID,
+			}).Error("Failed to create payment batch")
+
+			statusCode, errCode, errMessage := h.mapServiceError(err)
+			c.JSON(statusCode, ErrorResponseWithDetails(errCode, errMessage, err.Error()))
+			return
+		}
+
+		for _, result := range results {
+			item := &items[valid[result.Index]]
+			if result.Err != nil {
+				_, errCode, errMessage := mapPaymentServiceError(result.Err)
+				if errCode != "INTERNAL_ERROR" {
+					errMessage = result.Err.Error()
+				}
+				item.Status = string(domain.PaymentBatchItemStatusFailed)
+				item.ErrorCode = &errCode
+				item.Error = &errMessage
+				continue
+			}
+
+			payment := PaymentToResponse(result.Payment)
+			paymentURL := h.getPaymentURL(result.Payment.ID)
+			item.Status = string(domain.PaymentBatchItemStatusCreated)
+			item.PaymentID = &payment.PaymentID
+			item.Payment = &payment
+			item.PaymentURL = &paymentURL
+		}
+	}
+
+	response := PaymentBatchResponse{
+		Status:     string(domain.PaymentBatchStatusCompleted),
+		TotalCount: len(items),
+		Items:      items,
+	}
+	for _, item := range items {
+		if item.Status == string(domain.PaymentBatchItemStatusCreated) {
+			response.CreatedCount++
+		} else {
+			response.FailedCount++
+		}
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"merchant_id": merchant.ID,
+		"total":       response.TotalCount,
+		"created":     response.CreatedCount,
+		"failed":      response.FailedCount,
+	}).Info("Payment batch created successfully")
+
+	c.JSON(http.StatusOK, SuccessResponse(response))
+}
+
+// submitPaymentBatch queues a batch for the worker, invalid items reject the batch before anything is created
+func (h *PaymentBatchHandler) submitPaymentBatch(c *gin.Context, merchantID string, payments []PaymentBatchItemRequest, invalid []string) {
+	ctx := c.Request.Context()
+
+	if len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid payments in batch",
+			strings.Join(invalid, "; "),
+		))
+		return
+	}
+
+	reqs := make([]port.CreatePaymentRequest, len(payments))
+	for i, payment := range payments {
+		reqs[i] = paymentBatchItemToRequest(merchantID, payment)
+	}
+
+	batch, err := h.batchService.SubmitPaymentBatch(ctx, merchantID, reqs)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchantID,
+		}).Error("Failed to submit payment batch")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"batch_id":    batch.ID,
+		"merchant_id": merchantID,
+		"total":       batch.TotalCount,
+	}).Info("Payment batch submitted successfully")
+
+	c.JSON(http.StatusAccepted, SuccessResponse(PaymentBatchToResponse(batch)))
+}
+
+// GetPaymentBatch handles GET /api/v1/payments/batches/:id
+// @Summary Get a payment batch
+// @Description Retrieve the status of a batch created with async and the outcome of its payments
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} APIResponse{data=PaymentBatchResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payments/batches/{id} [get]
+// @Security ApiKeyAuth
+func (h *PaymentBatchHandler) GetPaymentBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	batch, err := h.batchService.GetPaymentBatch(ctx, c.Param("id"), merchant.ID)
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(PaymentBatchToResponse(batch)))
+}
+
+// validatePaymentBatchItem applies the validation of a single payment request to a batch item
+// Returns an empty error code when the item is valid
+func validatePaymentBatchItem(item *PaymentBatchItemRequest) (errCode, errMessage string) {
+	if err := binding.Validator.ValidateStruct(item); err != nil {
+		return "INVALID_REQUEST", err.Error()
+	}
+	if item.AmountVND == 0 && item.PricingAmount == 0 {
+		return "INVALID_AMOUNT", "Either amount_vnd or pricing_amount is required"
+	}
+	return "", ""
+}
+
+// paymentBatchItemToRequest converts a batch item to a payment service request
+func paymentBatchItemToRequest(merchantID string, item PaymentBatchItemRequest) port.CreatePaymentRequest {
+	return port.CreatePaymentRequest{
+		MerchantID:      merchantID,
+		AmountVND:       decimal.NewFromFloat(item.AmountVND),
+		PricingCurrency: item.PricingCurrency,
+		PricingAmount:   decimal.NewFromFloat(item.PricingAmount),
+		Currency:        item.Currency,
+		Chain:           domain.Chain(item.Chain),
+		OrderID:         item.OrderID,
+		Description:     item.Description,
+		CallbackURL:     item.CallbackURL,
+		Splits:          paymentSplitsFromRequest(item.Splits),
+	}
+}
+
+// getPaymentURL constructs the payment page URL
+func (h *PaymentBatchHandler) getPaymentURL(paymentID string) string {
+	return fmt.Sprintf("%s/pay/%s", h.baseURL, paymentID)
+}
+
+// mapServiceError maps payment batch service errors to HTTP status codes and error messages
+func (h *PaymentBatchHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrPaymentBatchNotFound):
+		return http.StatusNotFound, "BATCH_NOT_FOUND", "Payment batch not found"
+	case errors.Is(err, domain.ErrInvalidPaymentBatch):
+		return http.StatusBadRequest, "INVALID_BATCH", "Invalid payment batch"
+	case errors.Is(err, domain.ErrPaymentBatchesNotConfigured):
+		return http.StatusServiceUnavailable, "BATCHES_UNAVAILABLE", "Asynchronous payment batches are not available"
+	}
+
+	return mapPaymentServiceError(err)
+}
@@ -0,0 +1,361 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// PaymentLinkHandler handles HTTP requests for payment links
+type PaymentLinkHandler struct {
+	linkService port.PaymentLinkService
+	baseURL     string // Base URL for payment pages (e.g., https://pay.example.com)
+}
+
+// NewPaymentLinkHandler creates a new payment link handler
+func NewPaymentLinkHandler(linkService port.PaymentLinkService, baseURL string) *PaymentLinkHandler {
+	return &PaymentLinkHandler{
+		linkService: linkService,
+		baseURL:     baseURL,
+	}
+}
+
+// CreatePaymentLink handles POST /api/v1/payment-links
+// @Summary Create a payment link
+// @Description Create a reusable link that creates a new payment each time a payer opens it, for a fixed amount or an amount the payer enters
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Param request body CreatePaymentLinkRequest true "Payment link request"
+// @Success 201 {object} APIResponse{data=PaymentLinkResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payment-links [post]
+// @Security ApiKeyAuth
+func (h *PaymentLinkHandler) CreatePaymentLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreatePaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	link, err := h.linkService.CreatePaymentLink(ctx, port.CreatePaymentLinkRequest{
+		MerchantID:      merchant.ID,
+		AmountType:      domain.PaymentLinkAmountType(req.AmountType),
+		PricingCurrency: req.PricingCurrency,
+		Amount:          decimal.NewFromFloat(req.Amount),
+		MinAmount:       decimal.NewFromFloat(req.MinAmount),
+		MaxAmount:       decimal.NewFromFloat(req.MaxAmount),
+		Currency:        req.Currency,
+		Chain:           domain.Chain(req.Chain),
+		Description:     req.Description,
+		Metadata:        req.Metadata,
+		MaxUses:         req.MaxUses,
+		ExpiresAt:       req.ExpiresAt,
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to create payment link")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, serviceErrorResponse(err, errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"payment_link_id": link.ID,
+		"merchant_id":     merchant.ID,
+	}).Info("Payment link created successfully")
+
+	c.JSON(http.StatusCreated, SuccessResponse(PaymentLinkToResponse(link, h.getLinkURL(link.ID))))
+}
+
+// ListPaymentLinks handles GET /api/v1/payment-links
+// @Summary List payment links
+// @Description List the payment links of the authenticated merchant, newest first
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param per_page query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} APIResponse{data=ListPaymentLinksResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payment-links [get]
+// @Security ApiKeyAuth
+func (h *PaymentLinkHandler) ListPaymentLinks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req ListPaymentLinksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	links, total, err := h.linkService.ListPaymentLinks(ctx, merchant.ID, perPage, (page-1)*perPage)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to list payment links")
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse("INTERNAL_ERROR", "Failed to retrieve payment links"))
+		return
+	}
+
+	items := make([]PaymentLinkResponse, len(links))
+	for i, link := range links {
+		items[i] = PaymentLinkToResponse(link, h.getLinkURL(link.ID))
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(ListPaymentLinksResponse{
+		PaymentLinks: items,
+		Pagination:   newPaginationMeta(page, perPage, total),
+	}))
+}
+
+// GetPaymentLink handles GET /api/v1/payment-links/:id
+// @Summary Get a payment link
+// @Description Retrieve a payment link of the authenticated merchant
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment link ID"
+// @Success 200 {object} APIResponse{data=PaymentLinkResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payment-links/{id} [get]
+// @Security ApiKeyAuth
+func (h *PaymentLinkHandler) GetPaymentLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	link, err := h.linkService.GetPaymentLink(ctx, c.Param("id"), merchant.ID)
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(PaymentLinkToResponse(link, h.getLinkURL(link.ID))))
+}
+
+// DeactivatePaymentLink handles POST /api/v1/payment-links/:id/deactivate
+// @Summary Deactivate a payment link
+// @Description Stop a payment link from creating new payments, payments already created are not affected
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment link ID"
+// @Success 200 {object} APIResponse{data=PaymentLinkResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payment-links/{id}/deactivate [post]
+// @Security ApiKeyAuth
+func (h *PaymentLinkHandler) DeactivatePaymentLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	linkID := c.Param("id")
+	link, err := h.linkService.DeactivatePaymentLink(ctx, linkID, merchant.ID)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"merchant_id":     merchant.ID,
+			"payment_link_id": linkID,
+		}).Error("Failed to deactivate payment link")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(PaymentLinkToResponse(link, h.getLinkURL(link.ID))))
+}
+
+// GetPublicPaymentLink handles GET /api/v1/public/payment-links/:id
+// @Summary Get a public payment link
+// @Description Retrieve what a payment link charges for (no authentication required) - Payer Experience Layer
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment link ID"
+// @Success 200 {object} APIResponse{data=PublicPaymentLinkResponse}
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/public/payment-links/{id} [get]
+func (h *PaymentLinkHandler) GetPublicPaymentLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	link, err := h.linkService.GetPaymentLink(ctx, c.Param("id"), "")
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(PaymentLinkToPublicResponse(link)))
+}
+
+// OpenPaymentLink handles POST /api/v1/public/payment-links/:id/payments
+// @Summary Pay a payment link
+// @Description Create a new payment from a payment link (no authentication required) - Payer Experience Layer
+// @Tags payment-links
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment link ID"
+// @Param request body OpenPaymentLinkRequest false "Amount for open links, token and chain when the link does not set them"
+// @Success 201 {object} APIResponse{data=PaymentStatusResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 410 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/public/payment-links/{id}/payments [post]
+func (h *PaymentLinkHandler) OpenPaymentLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req OpenPaymentLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+				"INVALID_REQUEST",
+				"Invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	linkID := c.Param("id")
+	payment, err := h.linkService.OpenPaymentLink(ctx, port.OpenPaymentLinkRequest{
+		PaymentLinkID: linkID,
+		Amount:        decimal.NewFromFloat(req.Amount),
+		Currency:      req.Currency,
+		Chain:         domain.Chain(req.Chain),
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"payment_link_id": linkID,
+		}).Warn("Failed to create payment from payment link")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, serviceErrorResponse(err, errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"payment_link_id": linkID,
+		"payment_id":      payment.ID,
+	}).Info("Payment created from payment link")
+
+	c.JSON(http.StatusCreated, SuccessResponse(PaymentToPublicStatusResponse(payment)))
+}
+
+// getLinkURL constructs the hosted payment link URL
+func (h *PaymentLinkHandler) getLinkURL(linkID string) string {
+	return fmt.Sprintf("%s/links/%s", h.baseURL, linkID)
+}
+
+// mapServiceError maps payment link service errors to HTTP status codes and error messages
+func (h *PaymentLinkHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrPaymentLinkNotFound):
+		return http.StatusNotFound, "PAYMENT_LINK_NOT_FOUND", "Payment link not found"
+	case errors.Is(err, domain.ErrPaymentLinkInactive):
+		return http.StatusGone, "PAYMENT_LINK_INACTIVE", "Payment link is no longer active"
+	case errors.Is(err, domain.ErrPaymentLinkExpired):
+		return http.StatusGone, "PAYMENT_LINK_EXPIRED", "Payment link has expired"
+	case errors.Is(err, domain.ErrPaymentLinkExhausted):
+		return http.StatusConflict, "PAYMENT_LINK_EXHAUSTED", "Payment link has reached its maximum number of uses"
+	}
+
+	return mapPaymentServiceError(err)
+}
+
+// serviceErrorResponse builds the error response of a service error
+// Amount validation errors keep their details, they tell the caller which bound or field failed
+func serviceErrorResponse(err error, errCode, errMessage string) APIResponse {
+	if errors.Is(err, domain.ErrInvalidAmount) {
+		return ErrorResponseWithDetails(errCode, errMessage, err.Error())
+	}
+	return ErrorResponse(errCode, errMessage)
+}
+
+// normalizePage applies the default and maximum page size of list endpoints
+func normalizePage(page, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return page, perPage
+}
+
+// newPaginationMeta builds the pagination metadata of a list response
+func newPaginationMeta(page, perPage int, total int64) *PaginationMeta {
+	return &PaginationMeta{
+		CurrentPage: page,
+		PerPage:     perPage,
+		TotalPages:  int((total + int64(perPage) - 1) / int64(perPage)),
+		TotalCount:  total,
+	}
+}
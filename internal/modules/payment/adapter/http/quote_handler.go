@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// CreateQuote handles POST /api/v1/quotes
+// @Summary Create a rate-locked quote
+// @Description Lock the exchange rate for an amount in VND on every supported chain and token. Pass the quote_id when creating the payment to be charged the quoted amount before valid_until.
+// @Tags quotes
+// @Accept json
+// @Produce json
+// @Param request body CreateQuoteRequest true "Quote request"
+// @Success 201 {object} APIResponse{data=QuoteResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Failure 503 {object} APIResponse
+// @Router /api/v1/quotes [post]
+// @Security ApiKeyAuth
+func (h *PaymentHandler) CreateQuote(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreateQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	amountVND := decimal.NewFromFloat(req.AmountVND)
+
+	quote, err := h.paymentService.CreateQuote(ctx, port.CreateQuoteRequest{
+		MerchantID: merchant.ID,
+		AmountVND:  amountVND,
+		Chain:      domain.Chain(req.Chain),
+		Currency:   req.Currency,
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"amount_vnd":  amountVND,
+		}).Error("Failed to create quote")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"quote_id":    quote.ID,
+		"merchant_id": merchant.ID,
+		"valid_until": quote.ValidUntil,
+	}).Info("Quote created successfully")
+
+	c.JSON(http.StatusCreated, SuccessResponse(QuoteToResponse(quote)))
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// RefundHandler handles HTTP requests for payment refunds
+type RefundHandler struct {
+	refundService port.RefundService
+}
+
+// NewRefundHandler creates a new refund handler
+func NewRefundHandler(refundService port.RefundService) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+	}
+}
+
+// CreateRefund handles POST /api/v1/payments/:id/refunds
+// @Summary Refund a payment
+// @Description Refund all or part of a completed payment to the payer's address. The amount is reserved from the merchant balance and sent on-chain asynchronously.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body CreateRefundRequest true "Refund request"
+// @Success 201 {object} APIResponse{data=RefundResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payments/{id}/refunds [post]
+// @Security ApiKeyAuth
+func (h *RefundHandler) CreateRefund(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	paymentID := c.Param("id")
+	if paymentID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse("INVALID_REQUEST", "Payment ID is required"))
+		return
+	}
+
+	var req CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	amount := decimal.Zero
+	if req.Amount != "" {
+		amount, err = decimal.NewFromString(req.Amount)
+		if err != nil || amount.LessThanOrEqual(decimal.Zero) {
+			c.JSON(http.StatusBadRequest, ErrorResponse("INVALID_AMOUNT", "Refund amount must be a positive number"))
+			return
+		}
+	}
+
+	refund, err := h.refundService.CreateRefund(ctx, port.CreateRefundRequest{
+		PaymentID:  paymentID,
+		MerchantID: merchant.ID,
+		Amount:     amount,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"payment_id":  paymentID,
+		}).Error("Failed to create refund")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"refund_id":   refund.ID,
+		"payment_id":  paymentID,
+		"merchant_id": merchant.ID,
+	}).Info("Refund created successfully")
+
+	c.JSON(http.StatusCreated, SuccessResponse(RefundToResponse(refund)))
+}
+
+// ListRefunds handles GET /api/v1/payments/:id/refunds
+// @Summary List payment refunds
+// @Description List all refunds of a payment
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} APIResponse{data=[]RefundResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/payments/{id}/refunds [get]
+// @Security ApiKeyAuth
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	paymentID := c.Param("id")
+	refunds, err := h.refundService.ListRefunds(ctx, paymentID, merchant.ID)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"payment_id":  paymentID,
+		}).Error("Failed to list refunds")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	items := make([]RefundResponse, len(refunds))
+	for i, refund := range refunds {
+		items[i] = RefundToResponse(refund)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(items))
+}
+
+// mapServiceError maps refund service errors to HTTP status codes and error messages
+func (h *RefundHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrPaymentNotFound), errors.Is(err, domain.ErrInvalidPaymentID):
+		return http.StatusNotFound, "PAYMENT_NOT_FOUND", "Payment not found"
+	case errors.Is(err, domain.ErrPaymentNotRefundable):
+		return http.StatusConflict, "PAYMENT_NOT_REFUNDABLE", "Only completed payments can be refunded"
+	case errors.Is(err, domain.ErrRefundAddressUnknown):
+		return http.StatusConflict, "REFUND_ADDRESS_UNKNOWN", "Payer address is unknown for this payment"
+	case errors.Is(err, domain.ErrTestPaymentNotRefundable):
+		return http.StatusConflict, "TEST_PAYMENT_NOT_REFUNDABLE", "Test payments cannot be refunded"
+	case errors.Is(err, domain.ErrRefundAmountExceeded):
+		return http.StatusBadRequest, "REFUND_AMOUNT_EXCEEDED", "Refund amount exceeds the refundable amount"
+	case errors.Is(err, domain.ErrInsufficientBalance):
+		return http.StatusConflict, "INSUFFICIENT_BALANCE", "Insufficient balance to cover the refund"
+	}
+
+	return http.StatusInternalServerError, "INTERNAL_ERROR", "An internal error occurred"
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// SolanaPayHandler handles Solana Pay transaction requests
+// Wallets call these endpoints directly, so successful responses follow the Solana Pay specification
+// instead of the API envelope
+type SolanaPayHandler struct {
+	solanaPayService port.SolanaPayService
+}
+
+// NewSolanaPayHandler creates a new Solana Pay handler
+func NewSolanaPayHandler(solanaPayService port.SolanaPayService) *SolanaPayHandler {
+	return &SolanaPayHandler{
+		solanaPayService: solanaPayService,
+	}
+}
+
+// SolanaPayTransactionRequestURL returns the Solana Pay transaction request link of a payment
+// The HTTPS link is URL-encoded after the solana: scheme as the specification requires
+func SolanaPayTransactionRequestURL(baseURL, paymentID string) string {
+	return "solana:" + url.QueryEscape(baseURL+"/api/v1/solana-pay/"+url.PathEscape(paymentID))
+}
+
+// GetTransactionRequest handles GET /api/v1/solana-pay/:payment_id
+// @Summary Get a Solana Pay transaction request
+// @Description Return the label and icon wallets show before requesting the payment transaction (no authentication required) - Payer Experience Layer
+// @Tags solana-pay
+// @Produce json
+// @Param payment_id path string true "Payment ID"
+// @Success 200 {object} SolanaPayInfoResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/solana-pay/{payment_id} [get]
+func (h *SolanaPayHandler) GetTransactionRequest(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	info, err := h.solanaPayService.GetTransactionRequestInfo(ctx, c.Param("payment_id"))
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SolanaPayInfoResponse{
+		Label: info.Label,
+		Icon:  info.Icon,
+	})
+}
+
+// CreateTransaction handles POST /api/v1/solana-pay/:payment_id
+// @Summary Create a Solana Pay transaction
+// @Description Compose the unsigned transfer of the amount owed on a payment for the payer account to sign. It holds an SPL TransferChecked, the payment memo and the payment reference key, with the account as fee payer (no authentication required) - Payer Experience Layer
+// @Tags solana-pay
+// @Accept json
+// @Produce json
+// @Param payment_id path string true "Payment ID"
+// @Param request body SolanaPayTransactionRequest true "Payer account"
+// @Success 200 {object} SolanaPayTransactionResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 410 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Failure 503 {object} APIResponse
+// @Router /api/v1/solana-pay/{payment_id} [post]
+func (h *SolanaPayHandler) CreateTransaction(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req SolanaPayTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	paymentID := c.Param("payment_id")
+	transaction, err := h.solanaPayService.CreateTransaction(ctx, paymentID, req.Account)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":      err.Error(),
+			"payment_id": paymentID,
+			"account":    req.Account,
+		}).Warn("Failed to create Solana Pay transaction")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SolanaPayTransactionResponse{
+		Transaction: transaction.Transaction,
+		Message:     transaction.Message,
+	})
+}
+
+// mapServiceError maps Solana Pay service errors to HTTP status codes and error messages
+func (h *SolanaPayHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrSolanaPayNotConfigured):
+		return http.StatusServiceUnavailable, "SOLANA_PAY_UNAVAILABLE", "Solana Pay transaction requests are not available"
+	case errors.Is(err, domain.ErrInvalidSolanaPayAccount):
+		return http.StatusBadRequest, "INVALID_ACCOUNT", "Account must be a Solana public key"
+	case errors.Is(err, domain.ErrPaymentExpired):
+		return http.StatusGone, "PAYMENT_EXPIRED", "Payment has expired"
+	case errors.Is(err, domain.ErrInvalidPaymentState):
+		return http.StatusConflict, "PAYMENT_NOT_PAYABLE", "Payment can no longer be paid"
+	case errors.Is(err, domain.ErrTestPaymentNotSimulated):
+		return http.StatusConflict, "TEST_PAYMENT", "Test payments cannot be paid with a wallet"
+	}
+
+	return mapPaymentServiceError(err)
+}
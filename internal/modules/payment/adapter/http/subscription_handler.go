@@ -0,0 +1,487 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/api/middleware"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+)
+
+// SubscriptionHandler handles HTTP requests for subscription plans and subscriptions
+type SubscriptionHandler struct {
+	subscriptionService port.SubscriptionService
+}
+
+// NewSubscriptionHandler creates a new subscription handler
+func NewSubscriptionHandler(subscriptionService port.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// CreatePlan handles POST /api/v1/subscription-plans
+// @Summary Create a subscription plan
+// @Description Create a plan that bills a fixed amount every interval, e.g. 20 USD every month
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body CreateSubscriptionPlanRequest true "Plan request"
+// @Success 201 {object} APIResponse{data=SubscriptionPlanResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscription-plans [post]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) CreatePlan(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreateSubscriptionPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	plan, err := h.subscriptionService.CreatePlan(ctx, port.CreateSubscriptionPlanRequest{
+		MerchantID:      merchant.ID,
+		Name:            req.Name,
+		Description:     req.Description,
+		PricingCurrency: req.PricingCurrency,
+		Amount:          decimal.NewFromFloat(req.Amount),
+		BillingInterval: domain.SubscriptionInterval(req.BillingInterval),
+		IntervalCount:   req.IntervalCount,
+		Currency:        req.Currency,
+		Chain:           domain.Chain(req.Chain),
+		Metadata:        req.Metadata,
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to create subscription plan")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, serviceErrorResponse(err, errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"plan_id":     plan.ID,
+		"merchant_id": merchant.ID,
+	}).Info("Subscription plan created successfully")
+
+	c.JSON(http.StatusCreated, SuccessResponse(SubscriptionPlanToResponse(plan)))
+}
+
+// ListPlans handles GET /api/v1/subscription-plans
+// @Summary List subscription plans
+// @Description List the subscription plans of the authenticated merchant, newest first
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param per_page query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} APIResponse{data=ListSubscriptionPlansResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscription-plans [get]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) ListPlans(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req ListSubscriptionPlansRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	plans, total, err := h.subscriptionService.ListPlans(ctx, merchant.ID, perPage, (page-1)*perPage)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to list subscription plans")
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse("INTERNAL_ERROR", "Failed to retrieve subscription plans"))
+		return
+	}
+
+	items := make([]SubscriptionPlanResponse, len(plans))
+	for i, plan := range plans {
+		items[i] = SubscriptionPlanToResponse(plan)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(ListSubscriptionPlansResponse{
+		Plans:      items,
+		Pagination: newPaginationMeta(page, perPage, total),
+	}))
+}
+
+// GetPlan handles GET /api/v1/subscription-plans/:id
+// @Summary Get a subscription plan
+// @Description Retrieve a subscription plan of the authenticated merchant
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} APIResponse{data=SubscriptionPlanResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscription-plans/{id} [get]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) GetPlan(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	plan, err := h.subscriptionService.GetPlan(ctx, c.Param("id"), merchant.ID)
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(SubscriptionPlanToResponse(plan)))
+}
+
+// ArchivePlan handles POST /api/v1/subscription-plans/:id/archive
+// @Summary Archive a subscription plan
+// @Description Stop new subscriptions to a plan, existing subscriptions keep billing
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} APIResponse{data=SubscriptionPlanResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscription-plans/{id}/archive [post]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) ArchivePlan(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	planID := c.Param("id")
+	plan, err := h.subscriptionService.ArchivePlan(ctx, planID, merchant.ID)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"plan_id":     planID,
+		}).Error("Failed to archive subscription plan")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(SubscriptionPlanToResponse(plan)))
+}
+
+// CreateSubscription handles POST /api/v1/subscriptions
+// @Summary Create a subscription
+// @Description Subscribe a customer to a plan. Each billing cycle issues a payment whose link is sent in a subscription.cycle_issued webhook and emailed to the customer.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body CreateSubscriptionRequest true "Subscription request"
+// @Success 201 {object} APIResponse{data=SubscriptionResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscriptions [post]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+		}).Error("Failed to get merchant from context")
+
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	subscription, err := h.subscriptionService.CreateSubscription(ctx, port.CreateSubscriptionRequest{
+		MerchantID:        merchant.ID,
+		PlanID:            req.PlanID,
+		CustomerName:      req.CustomerName,
+		CustomerEmail:     req.CustomerEmail,
+		CustomerReference: req.CustomerReference,
+		StartAt:           req.StartAt,
+		Metadata:          req.Metadata,
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+			"plan_id":     req.PlanID,
+		}).Error("Failed to create subscription")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"merchant_id":     merchant.ID,
+	}).Info("Subscription created successfully")
+
+	c.JSON(http.StatusCreated, SuccessResponse(SubscriptionToResponse(subscription)))
+}
+
+// ListSubscriptions handles GET /api/v1/subscriptions
+// @Summary List subscriptions
+// @Description List the subscriptions of the authenticated merchant, newest first
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param per_page query int false "Items per page (default: 20, max: 100)"
+// @Param status query string false "Filter by status (active, past_due, canceled)"
+// @Success 200 {object} APIResponse{data=ListSubscriptionsResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscriptions [get]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req ListSubscriptionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+			"INVALID_REQUEST",
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	subscriptions, total, err := h.subscriptionService.ListSubscriptions(ctx, merchant.ID, domain.SubscriptionStatus(req.Status), perPage, (page-1)*perPage)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":       err.Error(),
+			"merchant_id": merchant.ID,
+		}).Error("Failed to list subscriptions")
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse("INTERNAL_ERROR", "Failed to retrieve subscriptions"))
+		return
+	}
+
+	items := make([]SubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		items[i] = SubscriptionToResponse(subscription)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(ListSubscriptionsResponse{
+		Subscriptions: items,
+		Pagination:    newPaginationMeta(page, perPage, total),
+	}))
+}
+
+// GetSubscription handles GET /api/v1/subscriptions/:id
+// @Summary Get a subscription
+// @Description Retrieve a subscription of the authenticated merchant
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} APIResponse{data=SubscriptionResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscriptions/{id} [get]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	subscription, err := h.subscriptionService.GetSubscription(ctx, c.Param("id"), merchant.ID)
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(SubscriptionToResponse(subscription)))
+}
+
+// ListCycles handles GET /api/v1/subscriptions/:id/cycles
+// @Summary List subscription billing cycles
+// @Description List the billing cycles of a subscription with their payment and dunning state, newest first
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} APIResponse{data=[]SubscriptionCycleResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscriptions/{id}/cycles [get]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) ListCycles(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	cycles, err := h.subscriptionService.ListCycles(ctx, c.Param("id"), merchant.ID)
+	if err != nil {
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	items := make([]SubscriptionCycleResponse, len(cycles))
+	for i, cycle := range cycles {
+		items[i] = SubscriptionCycleToResponse(cycle)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(items))
+}
+
+// CancelSubscription handles POST /api/v1/subscriptions/:id/cancel
+// @Summary Cancel a subscription
+// @Description Cancel a subscription now, voiding its unpaid cycle, or at the end of the current period
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body CancelSubscriptionRequest false "Cancellation options"
+// @Success 200 {object} APIResponse{data=SubscriptionResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/subscriptions/{id}/cancel [post]
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	merchant, err := middleware.GetMerchantFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse("UNAUTHORIZED", "Authentication required"))
+		return
+	}
+
+	var req CancelSubscriptionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponseWithDetails(
+				"INVALID_REQUEST",
+				"Invalid request body",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	subscriptionID := c.Param("id")
+	subscription, err := h.subscriptionService.CancelSubscription(ctx, subscriptionID, merchant.ID, req.AtPeriodEnd)
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"merchant_id":     merchant.ID,
+			"subscription_id": subscriptionID,
+		}).Error("Failed to cancel subscription")
+
+		statusCode, errCode, errMessage := h.mapServiceError(err)
+		c.JSON(statusCode, ErrorResponse(errCode, errMessage))
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse(SubscriptionToResponse(subscription)))
+}
+
+// mapServiceError maps subscription service errors to HTTP status codes and error messages
+func (h *SubscriptionHandler) mapServiceError(err error) (statusCode int, errorCode string, errorMessage string) {
+	switch {
+	case errors.Is(err, domain.ErrSubscriptionPlanNotFound):
+		return http.StatusNotFound, "SUBSCRIPTION_PLAN_NOT_FOUND", "Subscription plan not found"
+	case errors.Is(err, domain.ErrInvalidSubscriptionPlan):
+		return http.StatusBadRequest, "INVALID_SUBSCRIPTION_PLAN", err.Error()
+	case errors.Is(err, domain.ErrSubscriptionPlanArchived):
+		return http.StatusConflict, "SUBSCRIPTION_PLAN_ARCHIVED", "Subscription plan is archived"
+	case errors.Is(err, domain.ErrSubscriptionNotFound):
+		return http.StatusNotFound, "SUBSCRIPTION_NOT_FOUND", "Subscription not found"
+	case errors.Is(err, domain.ErrSubscriptionCanceled):
+		return http.StatusConflict, "SUBSCRIPTION_CANCELED", "Subscription is canceled"
+	}
+
+	return mapPaymentServiceError(err)
+}
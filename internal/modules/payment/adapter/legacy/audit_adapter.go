@@ -0,0 +1,36 @@
+package legacy
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	auditdomain "github.com/hxuan190/stable_payment_gateway/internal/modules/audit/domain"
+	auditrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/audit/repository"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type AuditAdapter struct {
+	repo *auditrepository.AuditRepository
+}
+
+func NewAuditAdapter(repo *auditrepository.AuditRepository) domain.AuditRecorder {
+	return &AuditAdapter{repo: repo}
+}
+
+func (a *AuditAdapter) RecordPaymentAction(ctx context.Context, merchantID, paymentID, action string, metadata map[string]interface{}) error {
+	return a.repo.Create(&auditdomain.AuditLog{
+		ID:             uuid.New().String(),
+		ActorType:      auditdomain.ActorTypeMerchant,
+		ActorID:        sql.NullString{String: merchantID, Valid: merchantID != ""},
+		Action:         action,
+		ActionCategory: auditdomain.ActionCategoryPayment,
+		ResourceType:   "payment",
+		ResourceID:     paymentID,
+		Status:         auditdomain.AuditStatusSuccess,
+		Metadata:       metadata,
+		CreatedAt:      time.Now(),
+	})
+}
@@ -0,0 +1,29 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	complianceservice "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/service"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type ComplianceAlertAdapter struct {
+	svc *complianceservice.ComplianceAlertService
+}
+
+func NewComplianceAlertAdapter(svc *complianceservice.ComplianceAlertService) domain.ComplianceAlerter {
+	return &ComplianceAlertAdapter{svc: svc}
+}
+
+func (a *ComplianceAlertAdapter) RaisePaymentReversalAlert(ctx context.Context, paymentID string, chain domain.Chain, txHash, reason string) error {
+	id, err := uuid.Parse(paymentID)
+	if err != nil {
+		return fmt.Errorf("invalid payment ID: %w", err)
+	}
+
+	_, err = a.svc.CreatePaymentReversalAlert(ctx, id, string(chain), txHash, reason)
+	return err
+}
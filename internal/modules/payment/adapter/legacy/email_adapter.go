@@ -0,0 +1,20 @@
+package legacy
+
+import (
+	"context"
+
+	notificationservice "github.com/hxuan190/stable_payment_gateway/internal/modules/notification/service"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type NotificationEmailAdapter struct {
+	svc *notificationservice.NotificationService
+}
+
+func NewNotificationEmailAdapter(svc *notificationservice.NotificationService) domain.EmailSender {
+	return &NotificationEmailAdapter{svc: svc}
+}
+
+func (a *NotificationEmailAdapter) SendEmail(ctx context.Context, template, to string, data map[string]interface{}) error {
+	return a.svc.SendEmail(ctx, notificationservice.EmailType(template), to, data)
+}
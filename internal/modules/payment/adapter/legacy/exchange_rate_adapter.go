@@ -26,6 +26,14 @@ func (a *ExchangeRateServiceAdapter) GetUSDCToVND(ctx context.Context) (decimal.
 	return a.svc.GetUSDCToVND(ctx)
 }
 
+func (a *ExchangeRateServiceAdapter) GetRateSource(ctx context.Context) string {
+	return a.svc.GetRateSource(ctx)
+}
+
+func (a *ExchangeRateServiceAdapter) GetRate(ctx context.Context, base, quote string) (decimal.Decimal, error) {
+	return a.svc.GetRate(ctx, base, quote)
+}
+
 func (a *ExchangeRateServiceAdapter) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
 	if fromCurrency == "VND" && toCurrency == "USDT" {
 		rate, err := a.svc.GetUSDTToVND(ctx)
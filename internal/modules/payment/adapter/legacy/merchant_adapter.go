@@ -23,6 +23,46 @@ func (a *MerchantRepositoryAdapter) GetMerchantKYCStatus(merchantID string) (str
 	return string(merchant.KYCStatus), nil
 }
 
+func (a *MerchantRepositoryAdapter) GetMerchantPaymentTolerance(merchantID string) (decimal.Decimal, decimal.Decimal, error) {
+	merchant, err := a.repo.GetByID(merchantID)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	return merchant.UnderpaymentTolerancePercentage, merchant.OverpaymentTolerancePercentage, nil
+}
+
+func (a *MerchantRepositoryAdapter) GetMerchantAddressMode(merchantID, chain string) (string, error) {
+	merchant, err := a.repo.GetByID(merchantID)
+	if err != nil {
+		return "", err
+	}
+	return merchant.GetAddressMode(chain), nil
+}
+
+func (a *MerchantRepositoryAdapter) GetMerchantLatePaymentPolicy(merchantID string) (string, error) {
+	merchant, err := a.repo.GetByID(merchantID)
+	if err != nil {
+		return "", err
+	}
+	return merchant.GetLatePaymentPolicy(), nil
+}
+
+func (a *MerchantRepositoryAdapter) GetMerchantQuoteSpread(merchantID string) (decimal.Decimal, error) {
+	merchant, err := a.repo.GetByID(merchantID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return merchant.QuoteSpreadPercentage, nil
+}
+
+func (a *MerchantRepositoryAdapter) IsSandboxMerchant(merchantID string) (bool, error) {
+	merchant, err := a.repo.GetByID(merchantID)
+	if err != nil {
+		return false, err
+	}
+	return merchant.IsSandbox(), nil
+}
+
 func (a *MerchantRepositoryAdapter) UpdateMerchantVolume(merchantID string, amountUSD decimal.Decimal) error {
 	merchant, err := a.repo.GetByID(merchantID)
 	if err != nil {
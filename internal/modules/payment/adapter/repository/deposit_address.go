@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresDepositAddressRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresDepositAddressRepository(db *gorm.DB) *PostgresDepositAddressRepository {
+	return &PostgresDepositAddressRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresDepositAddressRepository) Create(address *domain.DepositAddress) error {
+	if address == nil {
+		return errors.New("deposit address cannot be nil")
+	}
+	if address.PaymentID == "" {
+		return domain.ErrInvalidPaymentID
+	}
+
+	if address.ID == "" {
+		address.ID = uuid.New().String()
+	}
+	if address.Status == "" {
+		address.Status = domain.DepositAddressStatusActive
+	}
+	now := time.Now()
+	if address.CreatedAt.IsZero() {
+		address.CreatedAt = now
+	}
+	if address.UpdatedAt.IsZero() {
+		address.UpdatedAt = now
+	}
+
+	return r.db.Create(address).Error
+}
+
+func (r *PostgresDepositAddressRepository) Update(address *domain.DepositAddress) error {
+	if address == nil {
+		return errors.New("deposit address cannot be nil")
+	}
+	if address.ID == "" {
+		return domain.ErrDepositAddressNotFound
+	}
+
+	address.UpdatedAt = time.Now()
+
+	// Save writes zero values too, so a successful sweep clears last_sweep_error
+	result := r.db.Save(address)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrDepositAddressNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresDepositAddressRepository) GetByPaymentID(paymentID string) (*domain.DepositAddress, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	address := &domain.DepositAddress{}
+	if err := r.db.Where("payment_id = ?", paymentID).First(address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrDepositAddressNotFound
+		}
+		return nil, err
+	}
+
+	return address, nil
+}
+
+func (r *PostgresDepositAddressRepository) ListWatchedByChain(chain domain.Chain) ([]*domain.DepositAddress, error) {
+	watched := []domain.DepositAddressStatus{domain.DepositAddressStatusActive, domain.DepositAddressStatusSweeping}
+
+	var addresses []*domain.DepositAddress
+	if err := r.db.Where("chain = ?", chain).
+		Where("status IN ? OR (status = ? AND swept_at > ?)", watched,
+			domain.DepositAddressStatusSwept, time.Now().Add(-domain.DepositAddressWatchRetention)).
+		Order("created_at ASC").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (r *PostgresDepositAddressRepository) ListSweepable(chain domain.Chain, finalizedBefore time.Time, limit int) ([]*domain.DepositAddress, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	finalStatuses := []domain.PaymentStatus{
+		domain.PaymentStatusCompleted,
+		domain.PaymentStatusOverpaid,
+		domain.PaymentStatusExpired,
+		domain.PaymentStatusFailed,
+		domain.PaymentStatusCanceled,
+	}
+
+	// Swept addresses are swept again when a transfer was recorded after their sweep
+	transferredAfterSweep := r.db.Model(&domain.PaymentTransfer{}).Select("1").
+		Where("payment_transfers.payment_id = payments.id AND payment_transfers.created_at > deposit_addresses.swept_at")
+	// A transfer may still be reorged out until it is finalized or its watch window is over
+	unfinalized := r.db.Model(&domain.PaymentTransfer{}).Select("1").
+		Where("payment_transfers.payment_id = payments.id AND payment_transfers.finalized_at IS NULL").
+		Where("payment_transfers.reversed_at IS NULL AND payment_transfers.created_at > ?", finalizedBefore)
+
+	var addresses []*domain.DepositAddress
+	if err := r.db.Select("deposit_addresses.*").
+		Joins("JOIN payments ON payments.id = deposit_addresses.payment_id").
+		Where("deposit_addresses.chain = ?", chain).
+		Where("deposit_addresses.status = ? OR (deposit_addresses.status = ? AND EXISTS (?))",
+			domain.DepositAddressStatusActive, domain.DepositAddressStatusSwept, transferredAfterSweep).
+		Where("payments.status IN ?", finalStatuses).
+		Where("NOT EXISTS (?)", unfinalized).
+		Order("deposit_addresses.created_at ASC").
+		Limit(limit).
+		Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (r *PostgresDepositAddressRepository) ClaimForSweeping(address *domain.DepositAddress) (bool, error) {
+	if address == nil || address.ID == "" {
+		return false, domain.ErrDepositAddressNotFound
+	}
+
+	now := time.Now()
+	result := r.db.Model(&domain.DepositAddress{}).
+		Where("id = ? AND status = ?", address.ID, address.Status).
+		Updates(map[string]interface{}{
+			"status":     domain.DepositAddressStatusSweeping,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	address.Status = domain.DepositAddressStatusSweeping
+	address.UpdatedAt = now
+	return true, nil
+}
+
+func (r *PostgresDepositAddressRepository) NextDerivationIndex() (uint32, error) {
+	var index int64
+	if err := r.db.Raw("SELECT nextval('deposit_address_derivation_index_seq')").Scan(&index).Error; err != nil {
+		return 0, err
+	}
+
+	return uint32(index), nil
+}
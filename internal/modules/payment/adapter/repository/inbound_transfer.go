@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresInboundTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresInboundTransferRepository(db *gorm.DB) *PostgresInboundTransferRepository {
+	return &PostgresInboundTransferRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresInboundTransferRepository) Record(transfer *domain.InboundTransfer) error {
+	if transfer == nil {
+		return errors.New("inbound transfer cannot be nil")
+	}
+	if transfer.TxHash == "" || transfer.ToAddress == "" {
+		return errors.New("inbound transfer needs a transaction hash and a recipient")
+	}
+
+	if transfer.ID == "" {
+		transfer.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = now
+	}
+	transfer.UpdatedAt = now
+
+	// A transfer seen again only moves out of the unmatched queue, it never returns to it
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chain"}, {Name: "tx_hash"}, {Name: "to_address"}},
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: "inbound_transfers", Name: "status"}, Value: domain.InboundTransferStatusUnmatched},
+		}},
+		DoUpdates: clause.AssignmentColumns([]string{"payment_id", "status", "unmatched_reason", "unmatched_detail", "updated_at"}),
+	}).Create(transfer).Error
+}
+
+func (r *PostgresInboundTransferRepository) GetByID(id string) (*domain.InboundTransfer, error) {
+	if id == "" {
+		return nil, domain.ErrInboundTransferNotFound
+	}
+
+	transfer := &domain.InboundTransfer{}
+	if err := r.db.Where("id = ?", id).First(transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInboundTransferNotFound
+		}
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (r *PostgresInboundTransferRepository) List(status domain.InboundTransferStatus, limit, offset int) ([]*domain.InboundTransfer, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := r.db.Model(&domain.InboundTransfer{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var transfers []*domain.InboundTransfer
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (r *PostgresInboundTransferRepository) Count(status domain.InboundTransferStatus) (int64, error) {
+	query := r.db.Model(&domain.InboundTransfer{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresInboundTransferRepository) Resolve(transfer *domain.InboundTransfer) error {
+	if transfer == nil {
+		return errors.New("inbound transfer cannot be nil")
+	}
+	if transfer.ID == "" {
+		return domain.ErrInboundTransferNotFound
+	}
+
+	transfer.UpdatedAt = time.Now()
+
+	// Only a queued transfer can be resolved, two operators cannot resolve the same transfer
+	result := r.db.Model(&domain.InboundTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, domain.InboundTransferStatusUnmatched).
+		Updates(map[string]interface{}{
+			"status":          transfer.Status,
+			"payment_id":      transfer.PaymentID,
+			"resolved_by":     transfer.ResolvedBy,
+			"resolved_at":     transfer.ResolvedAt,
+			"resolution_note": transfer.ResolutionNote,
+			"updated_at":      transfer.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrInboundTransferNotUnmatched
+	}
+
+	return nil
+}
+
+// MarkRefunded saves the refund of a transfer marked for refund by an operator
+func (r *PostgresInboundTransferRepository) MarkRefunded(transfer *domain.InboundTransfer) error {
+	if transfer == nil {
+		return errors.New("inbound transfer cannot be nil")
+	}
+	if transfer.ID == "" {
+		return domain.ErrInboundTransferNotFound
+	}
+
+	transfer.UpdatedAt = time.Now()
+
+	// Only a transfer still waiting for its refund can be refunded, a refund is never recorded twice
+	result := r.db.Model(&domain.InboundTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, domain.InboundTransferStatusRefundPending).
+		Updates(map[string]interface{}{
+			"status":         transfer.Status,
+			"refund_tx_hash": transfer.RefundTxHash,
+			"refunded_by":    transfer.RefundedBy,
+			"refunded_at":    transfer.RefundedAt,
+			"updated_at":     transfer.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrInboundTransferNotRefundPending
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresInvoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresInvoiceRepository(db *gorm.DB) *PostgresInvoiceRepository {
+	return &PostgresInvoiceRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresInvoiceRepository) Create(invoice *domain.Invoice) error {
+	if invoice == nil {
+		return errors.New("invoice cannot be nil")
+	}
+
+	if invoice.ID == "" {
+		invoice.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if invoice.CreatedAt.IsZero() {
+		invoice.CreatedAt = now
+	}
+	if invoice.UpdatedAt.IsZero() {
+		invoice.UpdatedAt = now
+	}
+
+	if err := r.db.Create(invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrInvoiceNumberExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresInvoiceRepository) GetByID(id string) (*domain.Invoice, error) {
+	if id == "" {
+		return nil, domain.ErrInvoiceNotFound
+	}
+
+	invoice := &domain.Invoice{}
+	if err := r.db.Where("id = ?", id).First(invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (r *PostgresInvoiceRepository) ListByMerchant(merchantID string, status domain.InvoiceStatus, limit, offset int) ([]*domain.Invoice, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invoices []*domain.Invoice
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+func (r *PostgresInvoiceRepository) CountByMerchant(merchantID string, status domain.InvoiceStatus) (int64, error) {
+	if merchantID == "" {
+		return 0, errors.New("merchant ID cannot be empty")
+	}
+
+	query := r.db.Model(&domain.Invoice{}).Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresInvoiceRepository) Update(invoice *domain.Invoice) error {
+	if invoice == nil {
+		return errors.New("invoice cannot be nil")
+	}
+	if invoice.ID == "" {
+		return domain.ErrInvoiceNotFound
+	}
+
+	invoice.UpdatedAt = time.Now()
+
+	// amount_paid is only changed by ApplyPayment
+	result := r.db.Model(&domain.Invoice{}).Where("id = ?", invoice.ID).Omit("amount_paid").Updates(invoice)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrInvoiceNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresInvoiceRepository) ApplyPayment(id string, amount decimal.Decimal) (*domain.Invoice, bool, error) {
+	if id == "" {
+		return nil, false, domain.ErrInvoiceNotFound
+	}
+
+	invoice := &domain.Invoice{}
+	var statusChanged bool
+
+	// Payments of the same invoice can complete at the same time
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(invoice).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrInvoiceNotFound
+			}
+			return err
+		}
+
+		statusChanged = invoice.ApplyPayment(amount)
+		invoice.UpdatedAt = time.Now()
+
+		return tx.Model(&domain.Invoice{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"amount_paid": invoice.AmountPaid,
+			"status":      invoice.Status,
+			"paid_at":     invoice.PaidAt,
+			"updated_at":  invoice.UpdatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return invoice, statusChanged, nil
+}
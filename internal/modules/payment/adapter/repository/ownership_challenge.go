@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresOwnershipChallengeRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresOwnershipChallengeRepository(db *gorm.DB) *PostgresOwnershipChallengeRepository {
+	return &PostgresOwnershipChallengeRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresOwnershipChallengeRepository) Create(challenge *domain.OwnershipChallenge) error {
+	if challenge == nil {
+		return errors.New("ownership challenge cannot be nil")
+	}
+	if challenge.Nonce == "" {
+		return errors.New("ownership challenge nonce is required")
+	}
+
+	challenge.WalletAddress = strings.ToLower(challenge.WalletAddress)
+	if challenge.CreatedAt.IsZero() {
+		challenge.CreatedAt = time.Now()
+	}
+
+	return r.db.Create(challenge).Error
+}
+
+func (r *PostgresOwnershipChallengeRepository) ClaimChallengeNonce(chain, address, nonce string) error {
+	if nonce == "" {
+		return domain.ErrOwnershipChallengeRejected
+	}
+
+	// Two requests replaying the same signature, only the first one claims the nonce
+	now := time.Now()
+	result := r.db.Model(&domain.OwnershipChallenge{}).
+		Where("nonce = ? AND chain = ? AND wallet_address = ? AND used_at IS NULL AND expires_at > ?",
+			nonce, strings.ToLower(chain), strings.ToLower(address), now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrOwnershipChallengeRejected
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresPaymentBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPaymentBatchRepository(db *gorm.DB) *PostgresPaymentBatchRepository {
+	return &PostgresPaymentBatchRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresPaymentBatchRepository) Create(batch *domain.PaymentBatch) error {
+	if batch == nil {
+		return errors.New("payment batch cannot be nil")
+	}
+
+	if batch.ID == "" {
+		batch.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = now
+	}
+	if batch.UpdatedAt.IsZero() {
+		batch.UpdatedAt = now
+	}
+
+	return r.db.Create(batch).Error
+}
+
+func (r *PostgresPaymentBatchRepository) GetByID(id string) (*domain.PaymentBatch, error) {
+	if id == "" {
+		return nil, domain.ErrPaymentBatchNotFound
+	}
+
+	batch := &domain.PaymentBatch{}
+	if err := r.db.Where("id = ?", id).First(batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentBatchNotFound
+		}
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r *PostgresPaymentBatchRepository) Update(batch *domain.PaymentBatch) error {
+	if batch == nil {
+		return errors.New("payment batch cannot be nil")
+	}
+	if batch.ID == "" {
+		return domain.ErrPaymentBatchNotFound
+	}
+
+	batch.UpdatedAt = time.Now()
+
+	// Items are only changed by UpdateItem
+	result := r.db.Model(&domain.PaymentBatch{}).
+		Where("id = ?", batch.ID).
+		Select("status", "created_count", "failed_count", "completed_at", "updated_at").
+		Updates(batch)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrPaymentBatchNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresPaymentBatchRepository) UpdateItem(batchID string, item *domain.PaymentBatchItem) error {
+	if item == nil {
+		return errors.New("payment batch item cannot be nil")
+	}
+	if batchID == "" {
+		return domain.ErrPaymentBatchNotFound
+	}
+
+	value, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment batch item: %w", err)
+	}
+
+	// Items are stored at their index, only the element of this item is replaced
+	result := r.db.Model(&domain.PaymentBatch{}).
+		Where("id = ?", batchID).
+		Updates(map[string]interface{}{
+			"items":      gorm.Expr("jsonb_set(items, ?::text[], ?::jsonb)", fmt.Sprintf("{%d}", item.Index), string(value)),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrPaymentBatchNotFound
+	}
+
+	return nil
+}
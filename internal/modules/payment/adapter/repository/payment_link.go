@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresPaymentLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPaymentLinkRepository(db *gorm.DB) *PostgresPaymentLinkRepository {
+	return &PostgresPaymentLinkRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresPaymentLinkRepository) Create(link *domain.PaymentLink) error {
+	if link == nil {
+		return errors.New("payment link cannot be nil")
+	}
+
+	if link.ID == "" {
+		link.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = now
+	}
+	if link.UpdatedAt.IsZero() {
+		link.UpdatedAt = now
+	}
+
+	return r.db.Create(link).Error
+}
+
+func (r *PostgresPaymentLinkRepository) GetByID(id string) (*domain.PaymentLink, error) {
+	if id == "" {
+		return nil, domain.ErrPaymentLinkNotFound
+	}
+
+	link := &domain.PaymentLink{}
+	if err := r.db.Where("id = ?", id).First(link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentLinkNotFound
+		}
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (r *PostgresPaymentLinkRepository) ListByMerchant(merchantID string, limit, offset int) ([]*domain.PaymentLink, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var links []*domain.PaymentLink
+	if err := r.db.Where("merchant_id = ?", merchantID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&links).Error; err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (r *PostgresPaymentLinkRepository) CountByMerchant(merchantID string) (int64, error) {
+	if merchantID == "" {
+		return 0, errors.New("merchant ID cannot be empty")
+	}
+
+	var count int64
+	if err := r.db.Model(&domain.PaymentLink{}).Where("merchant_id = ?", merchantID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresPaymentLinkRepository) Update(link *domain.PaymentLink) error {
+	if link == nil {
+		return errors.New("payment link cannot be nil")
+	}
+	if link.ID == "" {
+		return domain.ErrPaymentLinkNotFound
+	}
+
+	link.UpdatedAt = time.Now()
+
+	// uses_count is only changed by ReserveUse and ReleaseUse
+	result := r.db.Model(&domain.PaymentLink{}).Where("id = ?", link.ID).Omit("uses_count").Updates(link)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrPaymentLinkNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresPaymentLinkRepository) ReserveUse(id string) error {
+	if id == "" {
+		return domain.ErrPaymentLinkNotFound
+	}
+
+	// Payers opening the link at the same time cannot go over max_uses
+	result := r.db.Model(&domain.PaymentLink{}).
+		Where("id = ? AND (max_uses IS NULL OR uses_count < max_uses)", id).
+		Updates(map[string]interface{}{
+			"uses_count": gorm.Expr("uses_count + 1"),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrPaymentLinkExhausted
+	}
+
+	return nil
+}
+
+func (r *PostgresPaymentLinkRepository) ReleaseUse(id string) error {
+	if id == "" {
+		return domain.ErrPaymentLinkNotFound
+	}
+
+	return r.db.Model(&domain.PaymentLink{}).
+		Where("id = ? AND uses_count > 0", id).
+		Updates(map[string]interface{}{
+			"uses_count": gorm.Expr("uses_count - 1"),
+			"updated_at": time.Now(),
+		}).Error
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 
@@ -32,6 +35,9 @@ func (r *PostgresPaymentRepository) Create(payment *domain.Payment) error {
 	if payment.UpdatedAt.IsZero() {
 		payment.UpdatedAt = now
 	}
+	if payment.Version == 0 {
+		payment.Version = 1
+	}
 
 	if err := r.db.Create(payment).Error; err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
@@ -91,65 +97,128 @@ func (r *PostgresPaymentRepository) GetByPaymentReference(reference string) (*do
 	return payment, nil
 }
 
-func (r *PostgresPaymentRepository) UpdateStatus(id string, status domain.PaymentStatus) error {
-	if id == "" {
-		return domain.ErrInvalidPaymentID
+func (r *PostgresPaymentRepository) Update(payment *domain.Payment) error {
+	if payment == nil {
+		return errors.New("payment cannot be nil")
 	}
-	if status == "" {
-		return domain.ErrInvalidPaymentStatus
+	if payment.ID == "" {
+		return domain.ErrInvalidPaymentID
 	}
 
-	validStatuses := map[domain.PaymentStatus]bool{
-		domain.PaymentStatusCreated:           true,
-		domain.PaymentStatusPending:           true,
-		domain.PaymentStatusPendingCompliance: true,
-		domain.PaymentStatusConfirming:        true,
-		domain.PaymentStatusCompleted:         true,
-		domain.PaymentStatusExpired:           true,
-		domain.PaymentStatusFailed:            true,
+	// Status changes go through Transition so that they are recorded in the history
+	return r.save(r.db.Omit("status"), payment)
+}
+
+func (r *PostgresPaymentRepository) Transition(payment *domain.Payment, transition *domain.PaymentStatusTransition) error {
+	if payment == nil || transition == nil {
+		return errors.New("payment and transition cannot be nil")
+	}
+	if payment.ID == "" {
+		return domain.ErrInvalidPaymentID
 	}
-	if !validStatuses[status] {
+	if transition.ToStatus != payment.Status {
 		return domain.ErrInvalidPaymentStatus
 	}
 
-	result := r.db.Model(&domain.Payment{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":     status,
-		"updated_at": time.Now(),
+	version := payment.Version
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.save(tx, payment); err != nil {
+			return err
+		}
+		return tx.Create(transition).Error
 	})
-
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return domain.ErrPaymentNotFound
+	if err != nil {
+		payment.Version = version
+		return err
 	}
 
 	return nil
 }
 
-func (r *PostgresPaymentRepository) Update(payment *domain.Payment) error {
-	if payment == nil {
-		return errors.New("payment cannot be nil")
+func (r *PostgresPaymentRepository) ApplyTransfer(payment *domain.Payment, transition *domain.PaymentStatusTransition, transfer *domain.PaymentTransfer) error {
+	if payment == nil || transfer == nil {
+		return errors.New("payment and transfer cannot be nil")
 	}
-	if payment.ID == "" {
+	if payment.ID == "" || transfer.PaymentID != payment.ID {
 		return domain.ErrInvalidPaymentID
 	}
+	if transfer.TxHash == "" {
+		return errors.New("transaction hash cannot be empty")
+	}
+	if transition != nil && transition.ToStatus != payment.Status {
+		return domain.ErrInvalidPaymentStatus
+	}
 
+	if transfer.ID == "" {
+		transfer.ID = uuid.New().String()
+	}
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = time.Now()
+	}
+
+	// The transfer only counts once it is added to the payment, a duplicate tx hash rolls back both
+	version := payment.Version
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.save(tx, payment); err != nil {
+			return err
+		}
+		if transition != nil {
+			if err := tx.Create(transition).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(transfer).Error
+	})
+	if err != nil {
+		payment.Version = version
+		return err
+	}
+
+	return nil
+}
+
+// save writes the payment if its version is still the one it was read at and increments the version
+func (r *PostgresPaymentRepository) save(db *gorm.DB, payment *domain.Payment) error {
+	version := payment.Version
+	payment.Version = version + 1
 	payment.UpdatedAt = time.Now()
 
-	result := r.db.Model(&domain.Payment{}).Where("id = ?", payment.ID).Updates(payment)
+	result := db.Model(&domain.Payment{}).Where("id = ? AND version = ?", payment.ID, version).Updates(payment)
 	if result.Error != nil {
+		payment.Version = version
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return domain.ErrPaymentNotFound
+		payment.Version = version
+
+		// Either the payment is gone or it was updated since it was read
+		var count int64
+		if err := db.Session(&gorm.Session{NewDB: true}).Model(&domain.Payment{}).Where("id = ?", payment.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrPaymentNotFound
+		}
+		return domain.ErrPaymentVersionConflict
 	}
 
 	return nil
 }
 
+func (r *PostgresPaymentRepository) ListStatusHistory(paymentID string) ([]*domain.PaymentStatusTransition, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	var history []*domain.PaymentStatusTransition
+	if err := r.db.Where("payment_id = ?", paymentID).Order("created_at ASC").Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
 func (r *PostgresPaymentRepository) ListByMerchant(merchantID string, limit, offset int) ([]*domain.Payment, error) {
 	if merchantID == "" {
 		return nil, errors.New("merchant ID cannot be empty")
@@ -169,6 +238,133 @@ func (r *PostgresPaymentRepository) ListByMerchant(merchantID string, limit, off
 	return payments, nil
 }
 
+func (r *PostgresPaymentRepository) ListBySubscriptionCycle(cycleID string) ([]*domain.Payment, error) {
+	if cycleID == "" {
+		return nil, errors.New("subscription cycle ID cannot be empty")
+	}
+
+	var payments []*domain.Payment
+	if err := r.db.Where("subscription_cycle_id = ?", cycleID).Order("created_at ASC").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (r *PostgresPaymentRepository) Search(search domain.PaymentSearch) (*domain.PaymentPage, error) {
+	if !search.SortBy.IsValid() {
+		return nil, domain.ErrInvalidPaymentSearch
+	}
+	if search.Limit <= 0 {
+		search.Limit = domain.DefaultPaymentSearchLimit
+	}
+
+	query, err := r.paymentFilterQuery(search.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	column := string(search.SortBy)
+	direction, comparison := "ASC", ">"
+	if search.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	// The ID breaks ties between payments with the same sort value
+	if search.Cursor != "" {
+		cursor, err := domain.DecodePaymentCursor(search.Cursor, search.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		value, err := cursor.SortValue()
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), value, cursor.ID)
+	}
+
+	// One more payment than the page size tells whether there is a next page
+	var payments []*domain.Payment
+	if err := query.Order(column + " " + direction).Order("id " + direction).Limit(search.Limit + 1).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	page := &domain.PaymentPage{Payments: payments}
+	if len(payments) > search.Limit {
+		page.Payments = payments[:search.Limit]
+		page.NextCursor = domain.NewPaymentCursor(page.Payments[search.Limit-1], search.SortBy).Encode()
+	}
+
+	return page, nil
+}
+
+// paymentFilterQuery builds the conditions of a payment search, the indexes of migration 038 cover them
+func (r *PostgresPaymentRepository) paymentFilterQuery(filter domain.PaymentFilter) (*gorm.DB, error) {
+	query := r.db.Model(&domain.Payment{}).Where("deleted_at IS NULL")
+
+	if filter.MerchantID != "" {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.Chain != "" {
+		query = query.Where("chain = ?", filter.Chain)
+	}
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+	if !filter.ConfirmedFrom.IsZero() {
+		query = query.Where("confirmed_at >= ?", filter.ConfirmedFrom)
+	}
+	if !filter.ConfirmedTo.IsZero() {
+		query = query.Where("confirmed_at < ?", filter.ConfirmedTo)
+	}
+	if filter.OrderID != "" {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.TxHash != "" {
+		// Top-ups of underpaid payments are only recorded in payment_transfers
+		transfers := r.db.Table("payment_transfers").Select("1").
+			Where("payment_transfers.payment_id = payments.id AND payment_transfers.tx_hash = ?", filter.TxHash)
+		query = query.Where("payments.tx_hash = ? OR EXISTS (?)", filter.TxHash, transfers)
+	}
+	if filter.FromAddress != "" {
+		query = query.Where("from_address = ?", filter.FromAddress)
+	}
+	if !filter.MinAmountVND.IsZero() {
+		query = query.Where("amount_vnd >= ?", filter.MinAmountVND)
+	}
+	if !filter.MaxAmountVND.IsZero() {
+		query = query.Where("amount_vnd <= ?", filter.MaxAmountVND)
+	}
+	if filter.PricingCurrency != "" {
+		query = query.Where("pricing_currency = ?", filter.PricingCurrency)
+	}
+	if !filter.MinPricingAmount.IsZero() {
+		query = query.Where("pricing_amount >= ?", filter.MinPricingAmount)
+	}
+	if !filter.MaxPricingAmount.IsZero() {
+		query = query.Where("pricing_amount <= ?", filter.MaxPricingAmount)
+	}
+	if len(filter.Metadata) > 0 {
+		// Containment is served by the GIN index on metadata
+		metadata, err := json.Marshal(filter.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("metadata @> ?::jsonb", string(metadata))
+	}
+
+	return query, nil
+}
+
 func (r *PostgresPaymentRepository) GetExpiredPayments() ([]*domain.Payment, error) {
 	var payments []*domain.Payment
 	if err := r.db.Where("status IN ?", []string{"created", "pending"}).Where("expires_at < ?", time.Now()).Order("expires_at ASC").Find(&payments).Error; err != nil {
@@ -212,6 +408,39 @@ func (r *PostgresPaymentRepository) ListByStatus(status domain.PaymentStatus, li
 	return payments, nil
 }
 
+func (r *PostgresPaymentRepository) ListLatePayments(resolution domain.LatePaymentResolution, limit, offset int) ([]*domain.Payment, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var payments []*domain.Payment
+	if err := r.latePaymentsQuery(resolution).Order("late_paid_at DESC").Limit(limit).Offset(offset).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (r *PostgresPaymentRepository) CountLatePayments(resolution domain.LatePaymentResolution) (int64, error) {
+	var count int64
+	if err := r.latePaymentsQuery(resolution).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresPaymentRepository) latePaymentsQuery(resolution domain.LatePaymentResolution) *gorm.DB {
+	query := r.db.Model(&domain.Payment{}).Where("late_paid_at IS NOT NULL")
+	if resolution != "" {
+		query = query.Where("late_resolution = ?", resolution)
+	}
+	return query
+}
+
 func (r *PostgresPaymentRepository) Count() (int64, error) {
 	var count int64
 	if err := r.db.Model(&domain.Payment{}).Count(&count).Error; err != nil {
@@ -253,7 +482,7 @@ func (r *PostgresPaymentRepository) GetTotalVolumeByMerchant(merchantID string)
 	}
 
 	var total decimal.Decimal
-	if err := r.db.Model(&domain.Payment{}).Where("merchant_id = ? AND status = ?", merchantID, "completed").Select("COALESCE(SUM(amount_vnd), 0)").Scan(&total).Error; err != nil {
+	if err := r.db.Model(&domain.Payment{}).Where("merchant_id = ? AND status IN ?", merchantID, []string{"completed", "overpaid"}).Select("COALESCE(SUM(amount_vnd), 0)").Scan(&total).Error; err != nil {
 		return decimal.Zero, err
 	}
 
@@ -274,6 +503,39 @@ func (r *PostgresPaymentRepository) CountByAddressAndWindow(fromAddress string,
 	return count, nil
 }
 
+func (r *PostgresPaymentRepository) SetSolanaPayReference(paymentID, reference string) error {
+	if paymentID == "" {
+		return domain.ErrInvalidPaymentID
+	}
+	if reference == "" {
+		return errors.New("solana pay reference cannot be empty")
+	}
+
+	// Only the first reference is stored, transactions composed earlier carry it
+	return r.db.Model(&domain.Payment{}).
+		Where("id = ? AND solana_pay_reference IS NULL", paymentID).
+		Update("solana_pay_reference", reference).Error
+}
+
+func (r *PostgresPaymentRepository) ListSolanaPayPayments(expiredAfter time.Time) ([]*domain.Payment, error) {
+	var payments []*domain.Payment
+	if err := r.db.Where("chain = ? AND solana_pay_reference IS NOT NULL AND test_mode = FALSE", domain.ChainSolana).
+		Where("status IN ?", []domain.PaymentStatus{
+			domain.PaymentStatusCreated,
+			domain.PaymentStatusPending,
+			domain.PaymentStatusUnderpaid,
+			domain.PaymentStatusExpired,
+		}).
+		Where("expires_at > ?", expiredAfter).
+		Order("expires_at ASC").
+		Limit(1000).
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
 func (r *PostgresPaymentRepository) Delete(id string) error {
 	if id == "" {
 		return domain.ErrInvalidPaymentID
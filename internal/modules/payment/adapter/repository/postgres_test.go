@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// capturedQuery is a payments statement built by a dry run database
+type capturedQuery struct {
+	sql  string
+	vars []interface{}
+}
+
+// newDryRunRepository returns a repository whose queries are built but never sent to the database
+func newDryRunRepository(t *testing.T) (*PostgresPaymentRepository, *[]capturedQuery) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+
+	var queries []capturedQuery
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		// Subqueries are built through the same callbacks
+		if tx.Statement.Table == "payments" {
+			queries = append(queries, capturedQuery{sql: tx.Statement.SQL.String(), vars: tx.Statement.Vars})
+		}
+	}))
+	return NewPostgresPaymentRepository(db), &queries
+}
+
+func TestSearch_FirstPageFetchesOneMorePayment(t *testing.T) {
+	repo, queries := newDryRunRepository(t)
+
+	_, err := repo.Search(domain.PaymentSearch{
+		Filter:     domain.PaymentFilter{MerchantID: "merchant-1"},
+		SortBy:     domain.PaymentSortCreatedAt,
+		Descending: true,
+		Limit:      20,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, *queries, 1)
+	query := (*queries)[0]
+	assert.NotContains(t, query.sql, "(created_at, id)")
+	assert.Contains(t, query.sql, "ORDER BY created_at DESC,id DESC LIMIT $2")
+	assert.Equal(t, []interface{}{"merchant-1", 21}, query.vars)
+}
+
+func TestSearch_CursorContinuesAfterLastPayment(t *testing.T) {
+	createdAt := time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC)
+	last := &domain.Payment{ID: "payment-9", CreatedAt: createdAt, AmountVND: decimal.NewFromInt(2500000)}
+
+	tests := []struct {
+		name       string
+		sortBy     domain.PaymentSortField
+		descending bool
+		condition  string
+		order      string
+		value      interface{}
+	}{
+		{name: "newest first", sortBy: domain.PaymentSortCreatedAt, descending: true,
+			condition: "(created_at, id) < ($1, $2)", order: "ORDER BY created_at DESC,id DESC", value: createdAt},
+		{name: "smallest amount first", sortBy: domain.PaymentSortAmountVND,
+			condition: "(amount_vnd, id) > ($1, $2)", order: "ORDER BY amount_vnd ASC,id ASC", value: last.AmountVND},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, queries := newDryRunRepository(t)
+
+			_, err := repo.Search(domain.PaymentSearch{
+				SortBy:     tt.sortBy,
+				Descending: tt.descending,
+				Cursor:     domain.NewPaymentCursor(last, tt.sortBy).Encode(),
+				Limit:      20,
+			})
+
+			require.NoError(t, err)
+			require.Len(t, *queries, 1)
+			query := (*queries)[0]
+			assert.Contains(t, query.sql, tt.condition)
+			assert.Contains(t, query.sql, tt.order)
+			require.Len(t, query.vars, 3)
+			assert.Equal(t, tt.value, query.vars[0])
+			assert.Equal(t, "payment-9", query.vars[1])
+		})
+	}
+}
+
+func TestSearch_RejectsCursorOfAnotherSort(t *testing.T) {
+	repo, queries := newDryRunRepository(t)
+	cursor := domain.NewPaymentCursor(&domain.Payment{ID: "payment-9", CreatedAt: time.Now()}, domain.PaymentSortCreatedAt)
+
+	_, err := repo.Search(domain.PaymentSearch{SortBy: domain.PaymentSortAmountVND, Cursor: cursor.Encode()})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentCursor)
+	assert.Empty(t, *queries)
+}
+
+func TestSearch_TxHashMatchesPaymentTransfers(t *testing.T) {
+	repo, queries := newDryRunRepository(t)
+
+	_, err := repo.Search(domain.PaymentSearch{
+		Filter: domain.PaymentFilter{TxHash: "tx-1", PricingCurrency: "USD"},
+		SortBy: domain.PaymentSortCreatedAt,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, *queries, 1)
+	query := (*queries)[0]
+	assert.Contains(t, query.sql, "(payments.tx_hash = $1 OR EXISTS (SELECT 1 FROM \"payment_transfers\" WHERE payment_transfers.payment_id = payments.id AND payment_transfers.tx_hash = $2))")
+	assert.Contains(t, query.sql, "pricing_currency = $3")
+	assert.Equal(t, []interface{}{"tx-1", "tx-1", "USD", 21}, query.vars)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresQuoteRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresQuoteRepository(db *gorm.DB) *PostgresQuoteRepository {
+	return &PostgresQuoteRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresQuoteRepository) Create(quote *domain.Quote) error {
+	if quote == nil {
+		return errors.New("quote cannot be nil")
+	}
+
+	if quote.ID == "" {
+		quote.ID = uuid.New().String()
+	}
+	if quote.CreatedAt.IsZero() {
+		quote.CreatedAt = time.Now()
+	}
+
+	return r.db.Create(quote).Error
+}
+
+func (r *PostgresQuoteRepository) GetByID(id string) (*domain.Quote, error) {
+	if id == "" {
+		return nil, domain.ErrQuoteNotFound
+	}
+
+	quote := &domain.Quote{}
+	if err := r.db.Where("id = ?", id).First(quote).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrQuoteNotFound
+		}
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+func (r *PostgresQuoteRepository) MarkUsed(id, paymentID string) error {
+	if id == "" {
+		return domain.ErrQuoteNotFound
+	}
+
+	// Two payments racing for the same quote, only the first one gets the locked rate
+	result := r.db.Model(&domain.Quote{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"payment_id": paymentID,
+			"used_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrQuoteAlreadyUsed
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresRefundRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresRefundRepository(db *gorm.DB) *PostgresRefundRepository {
+	return &PostgresRefundRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresRefundRepository) Create(refund *domain.Refund) error {
+	if refund == nil {
+		return errors.New("refund cannot be nil")
+	}
+	if refund.PaymentID == "" {
+		return domain.ErrInvalidPaymentID
+	}
+
+	if refund.ID == "" {
+		refund.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = now
+	}
+	if refund.UpdatedAt.IsZero() {
+		refund.UpdatedAt = now
+	}
+
+	return r.db.Create(refund).Error
+}
+
+// CreateWithinLimit creates the refund if the refunds of its payment, failed ones excluded, stay within limit
+// The payment row is locked so concurrent refunds of the same payment are checked one after another
+func (r *PostgresRefundRepository) CreateWithinLimit(refund *domain.Refund, limit decimal.Decimal) error {
+	if refund == nil {
+		return errors.New("refund cannot be nil")
+	}
+	if refund.PaymentID == "" {
+		return domain.ErrInvalidPaymentID
+	}
+
+	if refund.ID == "" {
+		refund.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = now
+	}
+	if refund.UpdatedAt.IsZero() {
+		refund.UpdatedAt = now
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", refund.PaymentID).First(&domain.Payment{}).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrPaymentNotFound
+			}
+			return err
+		}
+
+		var refunded decimal.Decimal
+		if err := tx.Model(&domain.Refund{}).Where("payment_id = ? AND status != ?", refund.PaymentID, domain.RefundStatusFailed).Select("COALESCE(SUM(amount_crypto), 0)").Scan(&refunded).Error; err != nil {
+			return err
+		}
+		if refunded.Add(refund.AmountCrypto).GreaterThan(limit) {
+			return domain.ErrRefundAmountExceeded
+		}
+
+		return tx.Create(refund).Error
+	})
+}
+
+func (r *PostgresRefundRepository) GetByID(id string) (*domain.Refund, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidRefundID
+	}
+
+	refund := &domain.Refund{}
+	if err := r.db.Where("id = ?", id).First(refund).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrRefundNotFound
+		}
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (r *PostgresRefundRepository) Update(refund *domain.Refund) error {
+	if refund == nil {
+		return errors.New("refund cannot be nil")
+	}
+	if refund.ID == "" {
+		return domain.ErrInvalidRefundID
+	}
+
+	refund.UpdatedAt = time.Now()
+
+	result := r.db.Model(&domain.Refund{}).Where("id = ?", refund.ID).Updates(refund)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrRefundNotFound
+	}
+
+	return nil
+}
+
+// ClaimForSending moves a pending refund to sending in a single conditional update,
+// so concurrent workers never send the same refund
+func (r *PostgresRefundRepository) ClaimForSending(refundID string) (bool, error) {
+	if refundID == "" {
+		return false, domain.ErrInvalidRefundID
+	}
+
+	result := r.db.Model(&domain.Refund{}).
+		Where("id = ? AND status = ?", refundID, domain.RefundStatusPending).
+		Updates(map[string]interface{}{
+			"status":     domain.RefundStatusSending,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *PostgresRefundRepository) ListByPayment(paymentID string) ([]*domain.Refund, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	var refunds []*domain.Refund
+	if err := r.db.Where("payment_id = ?", paymentID).Order("created_at ASC").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (r *PostgresRefundRepository) ListByStatus(status domain.RefundStatus, limit int) ([]*domain.Refund, error) {
+	if status == "" {
+		return nil, errors.New("refund status cannot be empty")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var refunds []*domain.Refund
+	if err := r.db.Where("status = ?", status).Order("created_at ASC").Limit(limit).Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (r *PostgresRefundRepository) GetTotalRefundedByPayment(paymentID string) (decimal.Decimal, error) {
+	if paymentID == "" {
+		return decimal.Zero, domain.ErrInvalidPaymentID
+	}
+
+	var total decimal.Decimal
+	if err := r.db.Model(&domain.Refund{}).Where("payment_id = ? AND status != ?", paymentID, domain.RefundStatusFailed).Select("COALESCE(SUM(amount_crypto), 0)").Scan(&total).Error; err != nil {
+		return decimal.Zero, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,483 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresSubscriptionPlanRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresSubscriptionPlanRepository(db *gorm.DB) *PostgresSubscriptionPlanRepository {
+	return &PostgresSubscriptionPlanRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresSubscriptionPlanRepository) Create(plan *domain.SubscriptionPlan) error {
+	if plan == nil {
+		return errors.New("subscription plan cannot be nil")
+	}
+
+	if plan.ID == "" {
+		plan.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if plan.CreatedAt.IsZero() {
+		plan.CreatedAt = now
+	}
+	if plan.UpdatedAt.IsZero() {
+		plan.UpdatedAt = now
+	}
+
+	return r.db.Create(plan).Error
+}
+
+func (r *PostgresSubscriptionPlanRepository) GetByID(id string) (*domain.SubscriptionPlan, error) {
+	if id == "" {
+		return nil, domain.ErrSubscriptionPlanNotFound
+	}
+
+	plan := &domain.SubscriptionPlan{}
+	if err := r.db.Where("id = ?", id).First(plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSubscriptionPlanNotFound
+		}
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (r *PostgresSubscriptionPlanRepository) ListByMerchant(merchantID string, limit, offset int) ([]*domain.SubscriptionPlan, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var plans []*domain.SubscriptionPlan
+	err := r.db.Where("merchant_id = ?", merchantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&plans).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+func (r *PostgresSubscriptionPlanRepository) CountByMerchant(merchantID string) (int64, error) {
+	if merchantID == "" {
+		return 0, errors.New("merchant ID cannot be empty")
+	}
+
+	var count int64
+	if err := r.db.Model(&domain.SubscriptionPlan{}).Where("merchant_id = ?", merchantID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresSubscriptionPlanRepository) Update(plan *domain.SubscriptionPlan) error {
+	if plan == nil {
+		return errors.New("subscription plan cannot be nil")
+	}
+	if plan.ID == "" {
+		return domain.ErrSubscriptionPlanNotFound
+	}
+
+	plan.UpdatedAt = time.Now()
+
+	result := r.db.Model(&domain.SubscriptionPlan{}).Where("id = ?", plan.ID).Updates(plan)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrSubscriptionPlanNotFound
+	}
+
+	return nil
+}
+
+type PostgresSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresSubscriptionRepository(db *gorm.DB) *PostgresSubscriptionRepository {
+	return &PostgresSubscriptionRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresSubscriptionRepository) Create(subscription *domain.Subscription) error {
+	if subscription == nil {
+		return errors.New("subscription cannot be nil")
+	}
+
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if subscription.CreatedAt.IsZero() {
+		subscription.CreatedAt = now
+	}
+	if subscription.UpdatedAt.IsZero() {
+		subscription.UpdatedAt = now
+	}
+
+	return r.db.Create(subscription).Error
+}
+
+func (r *PostgresSubscriptionRepository) GetByID(id string) (*domain.Subscription, error) {
+	if id == "" {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	subscription := &domain.Subscription{}
+	if err := r.db.Where("id = ?", id).First(subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (r *PostgresSubscriptionRepository) ListByMerchant(merchantID string, status domain.SubscriptionStatus, limit, offset int) ([]*domain.Subscription, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var subscriptions []*domain.Subscription
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PostgresSubscriptionRepository) CountByMerchant(merchantID string, status domain.SubscriptionStatus) (int64, error) {
+	if merchantID == "" {
+		return 0, errors.New("merchant ID cannot be empty")
+	}
+
+	query := r.db.Model(&domain.Subscription{}).Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresSubscriptionRepository) Update(subscription *domain.Subscription) error {
+	if subscription == nil {
+		return errors.New("subscription cannot be nil")
+	}
+	if subscription.ID == "" {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	subscription.UpdatedAt = time.Now()
+
+	result := r.db.Model(&domain.Subscription{}).Where("id = ?", subscription.ID).Updates(subscriptionUpdates(subscription))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresSubscriptionRepository) ListDueForBilling(now time.Time, limit int) ([]*domain.Subscription, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var subscriptions []*domain.Subscription
+	err := r.db.
+		Where("status IN ?", []domain.SubscriptionStatus{domain.SubscriptionStatusActive, domain.SubscriptionStatusPastDue}).
+		Where("next_billing_at <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM subscription_cycles c WHERE c.subscription_id = subscriptions.id AND c.status = ?)", domain.SubscriptionCycleStatusOpen).
+		Order("next_billing_at ASC").
+		Limit(limit).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PostgresSubscriptionRepository) IssueCycle(subscription *domain.Subscription, cycle *domain.SubscriptionCycle) error {
+	if subscription == nil || cycle == nil {
+		return errors.New("subscription and cycle cannot be nil")
+	}
+
+	if cycle.ID == "" {
+		cycle.ID = uuid.New().String()
+	}
+	now := time.Now()
+	cycle.CreatedAt = now
+	cycle.UpdatedAt = now
+	subscription.UpdatedAt = now
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cycle).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return domain.ErrSubscriptionCycleExists
+			}
+			return err
+		}
+
+		result := tx.Model(&domain.Subscription{}).Where("id = ?", subscription.ID).Updates(subscriptionUpdates(subscription))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrSubscriptionNotFound
+		}
+		return nil
+	})
+}
+
+func (r *PostgresSubscriptionRepository) GetCycle(id string) (*domain.SubscriptionCycle, error) {
+	if id == "" {
+		return nil, domain.ErrSubscriptionCycleNotFound
+	}
+
+	cycle := &domain.SubscriptionCycle{}
+	if err := r.db.Where("id = ?", id).First(cycle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSubscriptionCycleNotFound
+		}
+		return nil, err
+	}
+
+	return cycle, nil
+}
+
+func (r *PostgresSubscriptionRepository) ListCycles(subscriptionID string) ([]*domain.SubscriptionCycle, error) {
+	if subscriptionID == "" {
+		return nil, errors.New("subscription ID cannot be empty")
+	}
+
+	var cycles []*domain.SubscriptionCycle
+	if err := r.db.Where("subscription_id = ?", subscriptionID).Order("cycle_number DESC").Find(&cycles).Error; err != nil {
+		return nil, err
+	}
+
+	return cycles, nil
+}
+
+func (r *PostgresSubscriptionRepository) UpdateCycle(cycle *domain.SubscriptionCycle) error {
+	if cycle == nil {
+		return errors.New("subscription cycle cannot be nil")
+	}
+	if cycle.ID == "" {
+		return domain.ErrSubscriptionCycleNotFound
+	}
+
+	cycle.UpdatedAt = time.Now()
+
+	// A payment completing at the same time marks the cycle paid, which must not be overwritten
+	result := r.db.Model(&domain.SubscriptionCycle{}).
+		Where("id = ? AND status = ?", cycle.ID, domain.SubscriptionCycleStatusOpen).
+		Updates(cycleUpdates(cycle))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrSubscriptionCycleNotOpen
+	}
+
+	return nil
+}
+
+func (r *PostgresSubscriptionRepository) ListCyclesDueForAttempt(now time.Time, limit int) ([]*domain.SubscriptionCycle, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var cycles []*domain.SubscriptionCycle
+	err := r.db.
+		Where("status = ?", domain.SubscriptionCycleStatusOpen).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&cycles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return cycles, nil
+}
+
+func (r *PostgresSubscriptionRepository) MarkCyclePaid(cycleID, paymentID string) (*domain.SubscriptionCycle, *domain.Subscription, bool, error) {
+	cycle := &domain.SubscriptionCycle{}
+	subscription := &domain.Subscription{}
+	var paid bool
+
+	// The worker may be retrying the cycle while its payment completes
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.lockCycle(tx, cycleID, cycle, subscription); err != nil {
+			return err
+		}
+
+		if !cycle.IsOpen() {
+			return nil
+		}
+
+		now := time.Now()
+		cycle.MarkPaid(paymentID)
+		cycle.UpdatedAt = now
+		if err := tx.Model(&domain.SubscriptionCycle{}).Where("id = ?", cycle.ID).Updates(cycleUpdates(cycle)).Error; err != nil {
+			return err
+		}
+		paid = true
+
+		if subscription.Status != domain.SubscriptionStatusPastDue {
+			return nil
+		}
+		subscription.Status = domain.SubscriptionStatusActive
+		subscription.UpdatedAt = now
+		return tx.Model(&domain.Subscription{}).Where("id = ?", subscription.ID).Updates(subscriptionUpdates(subscription)).Error
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return cycle, subscription, paid, nil
+}
+
+func (r *PostgresSubscriptionRepository) ReopenCycle(cycleID, paymentID string) (*domain.SubscriptionCycle, *domain.Subscription, bool, error) {
+	cycle := &domain.SubscriptionCycle{}
+	subscription := &domain.Subscription{}
+	var reopened bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.lockCycle(tx, cycleID, cycle, subscription); err != nil {
+			return err
+		}
+
+		if cycle.Status != domain.SubscriptionCycleStatusPaid || cycle.PaymentID.String != paymentID {
+			return nil
+		}
+
+		// The worker retries the cycle on its next run, or cancels it if the grace period is over
+		now := time.Now()
+		cycle.Status = domain.SubscriptionCycleStatusOpen
+		cycle.PaymentID.Valid = false
+		cycle.PaidAt.Valid = false
+		cycle.NextAttemptAt.Time = now
+		cycle.NextAttemptAt.Valid = true
+		cycle.UpdatedAt = now
+		if err := tx.Model(&domain.SubscriptionCycle{}).Where("id = ?", cycle.ID).Updates(cycleUpdates(cycle)).Error; err != nil {
+			return err
+		}
+		reopened = true
+
+		if subscription.IsCanceled() {
+			return nil
+		}
+		subscription.Status = domain.SubscriptionStatusPastDue
+		subscription.UpdatedAt = now
+		return tx.Model(&domain.Subscription{}).Where("id = ?", subscription.ID).Updates(subscriptionUpdates(subscription)).Error
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return cycle, subscription, reopened, nil
+}
+
+// lockCycle loads a cycle and its subscription FOR UPDATE
+func (r *PostgresSubscriptionRepository) lockCycle(tx *gorm.DB, cycleID string, cycle *domain.SubscriptionCycle, subscription *domain.Subscription) error {
+	if cycleID == "" {
+		return domain.ErrSubscriptionCycleNotFound
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", cycleID).First(cycle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrSubscriptionCycleNotFound
+		}
+		return err
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", cycle.SubscriptionID).First(subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrSubscriptionNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// subscriptionUpdates lists the mutable subscription columns, including the ones reset to false or NULL
+func subscriptionUpdates(subscription *domain.Subscription) map[string]interface{} {
+	return map[string]interface{}{
+		"customer_name":        subscription.CustomerName,
+		"customer_email":       subscription.CustomerEmail,
+		"customer_reference":   subscription.CustomerReference,
+		"status":               subscription.Status,
+		"current_period_start": subscription.CurrentPeriodStart,
+		"current_period_end":   subscription.CurrentPeriodEnd,
+		"next_billing_at":      subscription.NextBillingAt,
+		"cycles_count":         subscription.CyclesCount,
+		"cancel_at_period_end": subscription.CancelAtPeriodEnd,
+		"canceled_at":          subscription.CanceledAt,
+		"cancellation_reason":  subscription.CancellationReason,
+		"metadata":             subscription.Metadata,
+		"updated_at":           subscription.UpdatedAt,
+	}
+}
+
+// cycleUpdates lists the mutable cycle columns, including the ones reset to NULL
+func cycleUpdates(cycle *domain.SubscriptionCycle) map[string]interface{} {
+	return map[string]interface{}{
+		"status":          cycle.Status,
+		"payment_id":      cycle.PaymentID,
+		"attempts":        cycle.Attempts,
+		"next_attempt_at": cycle.NextAttemptAt,
+		"grace_ends_at":   cycle.GraceEndsAt,
+		"paid_at":         cycle.PaidAt,
+		"updated_at":      cycle.UpdatedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresTokenRepository(db *gorm.DB) *PostgresTokenRepository {
+	return &PostgresTokenRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresTokenRepository) Create(token *domain.Token) error {
+	if token == nil {
+		return errors.New("token cannot be nil")
+	}
+
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = now
+	}
+	if token.UpdatedAt.IsZero() {
+		token.UpdatedAt = now
+	}
+
+	if err := r.db.Create(token).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrTokenAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresTokenRepository) Update(token *domain.Token) error {
+	if token == nil {
+		return errors.New("token cannot be nil")
+	}
+	if token.ID == "" {
+		return domain.ErrTokenNotFound
+	}
+
+	token.UpdatedAt = time.Now()
+
+	// Save writes zero values too, so disabling a token or clearing a bound is persisted
+	result := r.db.Save(token)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresTokenRepository) GetByID(id string) (*domain.Token, error) {
+	if id == "" {
+		return nil, domain.ErrTokenNotFound
+	}
+
+	token := &domain.Token{}
+	if err := r.db.Where("id = ?", id).First(token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *PostgresTokenRepository) List() ([]*domain.Token, error) {
+	var tokens []*domain.Token
+	if err := r.db.Order("chain ASC, symbol ASC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+type PostgresPaymentTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPaymentTransferRepository(db *gorm.DB) *PostgresPaymentTransferRepository {
+	return &PostgresPaymentTransferRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresPaymentTransferRepository) Create(transfer *domain.PaymentTransfer) error {
+	if transfer == nil {
+		return errors.New("payment transfer cannot be nil")
+	}
+	if transfer.PaymentID == "" {
+		return domain.ErrInvalidPaymentID
+	}
+	if transfer.TxHash == "" {
+		return errors.New("transaction hash cannot be empty")
+	}
+
+	if transfer.ID == "" {
+		transfer.ID = uuid.New().String()
+	}
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = time.Now()
+	}
+
+	return r.db.Create(transfer).Error
+}
+
+func (r *PostgresPaymentTransferRepository) GetByTxHash(chain domain.Chain, txHash string) (*domain.PaymentTransfer, error) {
+	if txHash == "" {
+		return nil, errors.New("transaction hash cannot be empty")
+	}
+
+	transfer := &domain.PaymentTransfer{}
+	if err := r.db.Where("chain = ? AND tx_hash = ?", chain, txHash).First(transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTransferNotFound
+		}
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (r *PostgresPaymentTransferRepository) ListByPayment(paymentID string) ([]*domain.PaymentTransfer, error) {
+	if paymentID == "" {
+		return nil, domain.ErrInvalidPaymentID
+	}
+
+	var transfers []*domain.PaymentTransfer
+	if err := r.db.Where("payment_id = ?", paymentID).Order("created_at ASC").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (r *PostgresPaymentTransferRepository) Update(transfer *domain.PaymentTransfer) error {
+	if transfer == nil {
+		return errors.New("payment transfer cannot be nil")
+	}
+	if transfer.ID == "" {
+		return errors.New("payment transfer ID cannot be empty")
+	}
+
+	// Select the watcher columns explicitly, Updates skips zero values such as a reset missing_checks
+	result := r.db.Model(&domain.PaymentTransfer{}).Where("id = ?", transfer.ID).
+		Select("confirmations", "finalized_at", "missing_checks", "reversed_at").
+		Updates(transfer)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrTransferNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresPaymentTransferRepository) ListUnfinalized(since time.Time, limit int) ([]*domain.PaymentTransfer, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var transfers []*domain.PaymentTransfer
+	if err := r.db.Where("finalized_at IS NULL AND reversed_at IS NULL").
+		Where("created_at > ? OR payment_id IN (?)", since,
+			r.db.Model(&domain.Payment{}).Select("id").Where("status = ?", domain.PaymentStatusConfirming)).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
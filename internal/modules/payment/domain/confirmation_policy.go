@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// FinalizedSpec is the band requirement that waits for chain finality instead of a block depth
+const FinalizedSpec = "finalized"
+
+// ConfirmationRequirement is the depth a payment transfer must reach before the payment completes
+type ConfirmationRequirement struct {
+	Confirmations int32
+	Finalized     bool // Wait for chain finality (e.g. Solana finalized commitment), Confirmations is ignored
+}
+
+// IsSatisfied returns true if a transfer with the given depth meets the requirement
+// A finalized transfer satisfies every requirement
+func (r ConfirmationRequirement) IsSatisfied(confirmations int32, finalized bool) bool {
+	if finalized {
+		return true
+	}
+	if r.Finalized {
+		return false
+	}
+	return confirmations >= r.Confirmations
+}
+
+// String returns the requirement in the format used by ParseConfirmationBands
+func (r ConfirmationRequirement) String() string {
+	if r.Finalized {
+		return FinalizedSpec
+	}
+	return strconv.Itoa(int(r.Confirmations))
+}
+
+// ConfirmationBand applies a requirement to payments of at least MinAmountUSD
+type ConfirmationBand struct {
+	MinAmountUSD decimal.Decimal
+	Requirement  ConfirmationRequirement
+}
+
+// ConfirmationPolicy holds the confirmation bands of each chain, sorted by MinAmountUSD
+// Chains without bands require a single confirmation
+type ConfirmationPolicy map[Chain][]ConfirmationBand
+
+// DefaultConfirmationPolicy returns the confirmation depths used when a chain is not configured
+func DefaultConfirmationPolicy() ConfirmationPolicy {
+	return ConfirmationPolicy{
+		ChainSolana: {
+			{MinAmountUSD: decimal.Zero, Requirement: ConfirmationRequirement{Confirmations: 1}},
+			{MinAmountUSD: decimal.NewFromInt(1000), Requirement: ConfirmationRequirement{Finalized: true}},
+		},
+		ChainBSC: {
+			{MinAmountUSD: decimal.Zero, Requirement: ConfirmationRequirement{Confirmations: 15}},
+		},
+		ChainTRON: {
+			{MinAmountUSD: decimal.Zero, Requirement: ConfirmationRequirement{Confirmations: 19}},
+		},
+	}
+}
+
+// RequirementFor returns the requirement of the highest band the amount reaches
+func (p ConfirmationPolicy) RequirementFor(chain Chain, amountUSD decimal.Decimal) ConfirmationRequirement {
+	requirement := ConfirmationRequirement{Confirmations: 1}
+	for _, band := range p[chain] {
+		if amountUSD.LessThan(band.MinAmountUSD) {
+			break
+		}
+		requirement = band.Requirement
+	}
+	return requirement
+}
+
+// ParseConfirmationBands parses a comma-separated list of MIN_AMOUNT_USD:REQUIREMENT entries,
+// where REQUIREMENT is a number of confirmations or "finalized", e.g. "0:15,1000:30"
+func ParseConfirmationBands(value string) ([]ConfirmationBand, error) {
+	var bands []ConfirmationBand
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("band %q must be MIN_AMOUNT_USD:CONFIRMATIONS or MIN_AMOUNT_USD:finalized", entry)
+		}
+
+		minAmount, err := decimal.NewFromString(strings.TrimSpace(parts[0]))
+		if err != nil || minAmount.IsNegative() {
+			return nil, fmt.Errorf("band %q has an invalid minimum amount", entry)
+		}
+
+		var requirement ConfirmationRequirement
+		spec := strings.ToLower(strings.TrimSpace(parts[1]))
+		if spec == FinalizedSpec {
+			requirement.Finalized = true
+		} else {
+			confirmations, err := strconv.Atoi(spec)
+			if err != nil || confirmations < 1 {
+				return nil, fmt.Errorf("band %q must require at least one confirmation", entry)
+			}
+			requirement.Confirmations = int32(confirmations)
+		}
+
+		bands = append(bands, ConfirmationBand{MinAmountUSD: minAmount, Requirement: requirement})
+	}
+
+	sort.Slice(bands, func(i, j int) bool {
+		return bands[i].MinAmountUSD.LessThan(bands[j].MinAmountUSD)
+	})
+
+	return bands, nil
+}
+
+// ParseConfirmationPolicy builds a policy from per-chain band lists, see ParseConfirmationBands
+// Chains missing from specs keep their default bands
+func ParseConfirmationPolicy(specs map[string]string) (ConfirmationPolicy, error) {
+	policy := DefaultConfirmationPolicy()
+
+	for chain, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+
+		bands, err := ParseConfirmationBands(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid confirmation policy for %s: %w", chain, err)
+		}
+		policy[Chain(chain)] = bands
+	}
+
+	return policy, nil
+}
+
+// TransactionInclusion is the on-chain state of a transfer as seen by a TransactionVerifier
+type TransactionInclusion struct {
+	Included      bool
+	BlockNumber   uint64 // Block or slot the transaction was included in
+	Confirmations int32
+	Finalized     bool
+}
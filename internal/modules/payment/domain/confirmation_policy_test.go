@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmationPolicy_RequirementFor(t *testing.T) {
+	policy := DefaultConfirmationPolicy()
+
+	tests := []struct {
+		name     string
+		chain    Chain
+		amount   string
+		expected ConfirmationRequirement
+	}{
+		{"solana small payment", ChainSolana, "50", ConfirmationRequirement{Confirmations: 1}},
+		{"solana large payment", ChainSolana, "1000", ConfirmationRequirement{Finalized: true}},
+		{"bsc", ChainBSC, "5000", ConfirmationRequirement{Confirmations: 15}},
+		{"tron", ChainTRON, "10", ConfirmationRequirement{Confirmations: 19}},
+		{"unconfigured chain", Chain("polygon"), "10", ConfirmationRequirement{Confirmations: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.RequirementFor(tt.chain, decimal.RequireFromString(tt.amount)))
+		})
+	}
+}
+
+func TestConfirmationRequirement_IsSatisfied(t *testing.T) {
+	depth := ConfirmationRequirement{Confirmations: 15}
+	assert.False(t, depth.IsSatisfied(14, false))
+	assert.True(t, depth.IsSatisfied(15, false))
+	assert.True(t, depth.IsSatisfied(0, true))
+
+	finality := ConfirmationRequirement{Finalized: true}
+	assert.False(t, finality.IsSatisfied(100, false))
+	assert.True(t, finality.IsSatisfied(1, true))
+}
+
+func TestParseConfirmationPolicy(t *testing.T) {
+	policy, err := ParseConfirmationPolicy(map[string]string{
+		"bsc":      "10000:finalized, 0:15, 1000:30",
+		"ethereum": "0:12",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ConfirmationRequirement{Confirmations: 15}, policy.RequirementFor(ChainBSC, decimal.NewFromInt(999)))
+	assert.Equal(t, ConfirmationRequirement{Confirmations: 30}, policy.RequirementFor(ChainBSC, decimal.NewFromInt(1000)))
+	assert.Equal(t, ConfirmationRequirement{Finalized: true}, policy.RequirementFor(ChainBSC, decimal.NewFromInt(20000)))
+	assert.Equal(t, ConfirmationRequirement{Confirmations: 12}, policy.RequirementFor(Chain("ethereum"), decimal.NewFromInt(1)))
+	assert.Equal(t, ConfirmationRequirement{Confirmations: 19}, policy.RequirementFor(ChainTRON, decimal.NewFromInt(1)))
+
+	for _, spec := range []string{"15", "0:0", "-1:5", "0:soon"} {
+		_, err := ParseConfirmationPolicy(map[string]string{"bsc": spec})
+		assert.Error(t, err, spec)
+	}
+}
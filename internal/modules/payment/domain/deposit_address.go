@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AddressMode selects how incoming transfers are matched to payments
+type AddressMode string
+
+const (
+	// AddressModeMemo sends all payments to the hot wallet and matches them by memo
+	AddressModeMemo AddressMode = "memo"
+	// AddressModeDeposit gives every payment its own deposit address and matches by recipient
+	AddressModeDeposit AddressMode = "deposit"
+)
+
+// IsValid returns true if the mode is a known address mode
+func (m AddressMode) IsValid() bool {
+	return m == AddressModeMemo || m == AddressModeDeposit
+}
+
+// DepositAddressStatus represents the lifecycle of a deposit address
+type DepositAddressStatus string
+
+const (
+	DepositAddressStatusPending  DepositAddressStatus = "pending"  // Its on-chain account is being created, not watched yet
+	DepositAddressStatusActive   DepositAddressStatus = "active"   // Watched by the listeners
+	DepositAddressStatusSweeping DepositAddressStatus = "sweeping" // Claimed by a sweep, its transfer may be broadcast
+	DepositAddressStatusSwept    DepositAddressStatus = "swept"    // Funds consolidated into the hot wallet
+)
+
+// DepositAddressWatchRetention is how long a swept deposit address stays watched by the listeners,
+// a payer reusing the address meanwhile is still matched to its payment
+const DepositAddressWatchRetention = 30 * 24 * time.Hour
+
+// DepositAddress is an address generated for a single payment
+// On BSC it is derived from the deposit xpub at DerivationIndex,
+// on Solana it is a token account created from the hot wallet with Seed by the worker,
+// the address stays pending until its creation is confirmed.
+type DepositAddress struct {
+	ID         string `json:"id" db:"id"`
+	PaymentID  string `json:"payment_id" db:"payment_id" validate:"required,uuid"`
+	MerchantID string `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+	Chain      Chain  `json:"chain" db:"chain" validate:"required,oneof=solana bsc"`
+	Currency   string `json:"currency" db:"currency" validate:"required"`
+	Address    string `json:"address" db:"address" validate:"required"`
+
+	// Derivation details (one of them is set depending on the chain)
+	DerivationIndex sql.NullInt64  `json:"derivation_index,omitempty" db:"derivation_index"`
+	Seed            sql.NullString `json:"seed,omitempty" db:"seed"`
+
+	// Sweep state
+	Status         DepositAddressStatus `json:"status" db:"status" validate:"required,oneof=pending active sweeping swept"`
+	SweptTxHash    sql.NullString       `json:"swept_tx_hash,omitempty" db:"swept_tx_hash"`
+	SweptAmount    decimal.Decimal      `json:"swept_amount" db:"swept_amount"`
+	SweptAt        sql.NullTime         `json:"swept_at,omitempty" db:"swept_at"`
+	LastSweepError sql.NullString       `json:"last_sweep_error,omitempty" db:"last_sweep_error"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (DepositAddress) TableName() string {
+	return "deposit_addresses"
+}
+
+// MarkActive records the confirmed creation of the on-chain account of a pending deposit address
+func (d *DepositAddress) MarkActive() {
+	d.Status = DepositAddressStatusActive
+	d.UpdatedAt = time.Now()
+}
+
+// MarkSwept records the consolidation of the deposit address into the hot wallet
+// An address swept again keeps the total swept and the hash of its last sweep transfer
+func (d *DepositAddress) MarkSwept(txHash string, amount decimal.Decimal) {
+	now := time.Now()
+	d.Status = DepositAddressStatusSwept
+	if txHash != "" {
+		d.SweptTxHash = sql.NullString{String: txHash, Valid: true}
+	}
+	d.SweptAmount = d.SweptAmount.Add(amount)
+	d.SweptAt = sql.NullTime{Time: now, Valid: true}
+	d.LastSweepError = sql.NullString{}
+	d.UpdatedAt = now
+}
@@ -20,14 +20,172 @@ var (
 	ErrPaymentAlreadyCompleted = errors.New("payment is already completed")
 	// ErrInvalidPaymentState is returned when payment is in invalid state for operation
 	ErrInvalidPaymentState = errors.New("payment is in invalid state for this operation")
+	// ErrInvalidPaymentTransition is returned when the payment state machine does not allow a status change
+	ErrInvalidPaymentTransition = errors.New("invalid payment status transition")
+	// ErrPaymentVersionConflict is returned when a payment was updated concurrently since it was read
+	ErrPaymentVersionConflict = errors.New("payment was modified concurrently")
+	// ErrInvalidPaymentCursor is returned when a search cursor is malformed or was issued for another sort order
+	ErrInvalidPaymentCursor = errors.New("invalid payment search cursor")
+	// ErrInvalidPaymentSearch is returned when the filters or sort order of a payment search are invalid
+	ErrInvalidPaymentSearch = errors.New("invalid payment search")
+	// ErrInvalidExpiryExtension is returned when an expiry extension is outside the allowed limits
+	ErrInvalidExpiryExtension = errors.New("invalid payment expiry extension")
 	// ErrAmountMismatch is returned when actual amount doesn't match expected amount
 	ErrAmountMismatch = errors.New("payment amount mismatch")
+	// ErrTransferNotFound is returned when a payment transfer is not found
+	ErrTransferNotFound = errors.New("payment transfer not found")
+	// ErrTransferChainMismatch is returned when a transfer was observed on another chain than the payment's
+	ErrTransferChainMismatch = errors.New("transfer chain does not match the payment")
+	// ErrTransferCurrencyMismatch is returned when a transfer carries another token than the payment's
+	ErrTransferCurrencyMismatch = errors.New("transfer currency does not match the payment")
 	// ErrInvalidChain is returned when chain is not supported
 	ErrInvalidChain = errors.New("invalid or unsupported blockchain chain")
 	// ErrInvalidSignature is returned when wallet signature verification fails
 	ErrInvalidSignature = errors.New("invalid wallet signature: proof of ownership failed")
+	// ErrInvalidWalletAddress is returned when a wallet address is not valid on its chain
+	ErrInvalidWalletAddress = errors.New("invalid wallet address")
+	// ErrOwnershipChallengeRejected is returned when a signed challenge was not issued for the wallet, expired or was already used
+	ErrOwnershipChallengeRejected = errors.New("ownership challenge was not issued for this wallet or was already used")
+	// ErrOwnershipChallengesNotConfigured is returned when this service cannot issue or claim ownership challenges
+	ErrOwnershipChallengesNotConfigured = errors.New("ownership challenges not configured")
 	// ErrMerchantNotFound is returned when merchant is not found
 	ErrMerchantNotFound = errors.New("merchant not found")
 	// ErrMerchantNotApproved is returned when merchant is not approved
 	ErrMerchantNotApproved = errors.New("merchant is not approved")
+
+	// ErrRefundNotFound is returned when a refund is not found
+	ErrRefundNotFound = errors.New("refund not found")
+	// ErrInvalidRefundID is returned when the refund ID is invalid
+	ErrInvalidRefundID = errors.New("invalid refund ID")
+	// ErrPaymentNotRefundable is returned when the payment is not completed
+	ErrPaymentNotRefundable = errors.New("only completed payments can be refunded")
+	// ErrRefundAmountExceeded is returned when the refund exceeds the amount left to refund
+	ErrRefundAmountExceeded = errors.New("refund amount exceeds refundable amount")
+	// ErrRefundAddressUnknown is returned when the payer address of the payment is not known
+	ErrRefundAddressUnknown = errors.New("payer address unknown, cannot refund")
+	// ErrInsufficientBalance is returned when the merchant balance cannot cover the refund
+	ErrInsufficientBalance = errors.New("insufficient merchant balance")
+	// ErrRefundSenderNotConfigured is returned when no wallet is configured to send refunds
+	ErrRefundSenderNotConfigured = errors.New("refund sender not configured")
+	// ErrRefundTxNotFound is returned when the chain does not know a refund transaction
+	ErrRefundTxNotFound = errors.New("refund transaction not found")
+
+	// ErrDepositAddressNotFound is returned when a payment has no deposit address
+	ErrDepositAddressNotFound = errors.New("deposit address not found")
+	// ErrDepositAddressNotSupported is returned when deposit addresses are not configured for a chain
+	ErrDepositAddressNotSupported = errors.New("deposit addresses not supported on this chain")
+
+	// ErrTransactionVerifierNotConfigured is returned when no chain client is configured to verify transfers
+	ErrTransactionVerifierNotConfigured = errors.New("transaction verifier not configured")
+
+	// ErrInvalidLatePaymentAction is returned when a late payment is resolved with anything but accept or refund
+	ErrInvalidLatePaymentAction = errors.New("late payment can only be accepted or refunded")
+	// ErrLatePaymentRefunderNotConfigured is returned when late payments cannot be refunded by this service
+	ErrLatePaymentRefunderNotConfigured = errors.New("late payment refunder not configured")
+	// ErrUnsupportedPricingCurrency is returned when a payment is priced in a currency we do not convert
+	ErrUnsupportedPricingCurrency = errors.New("unsupported pricing currency")
+	// ErrExchangeRateNotConfigured is returned when late payments cannot be re-quoted by this service
+	ErrExchangeRateNotConfigured = errors.New("exchange rate provider not configured")
+
+	// ErrInboundTransferNotFound is returned when an inbound transfer is not found
+	ErrInboundTransferNotFound = errors.New("inbound transfer not found")
+	// ErrInboundTransferNotUnmatched is returned when an operator resolves a transfer that is no longer queued
+	ErrInboundTransferNotUnmatched = errors.New("inbound transfer is not unmatched")
+	// ErrInboundTransferNotRefundPending is returned when a refund is recorded for a transfer that was not marked for refund
+	ErrInboundTransferNotRefundPending = errors.New("inbound transfer is not marked for refund")
+	// ErrInboundTransferRefundNotIncluded is returned when the recorded refund transaction is not included on chain
+	ErrInboundTransferRefundNotIncluded = errors.New("refund transaction is not included on chain")
+	// ErrInboundTransferMismatch is returned when a transfer is attached to a payment on another chain or currency
+	ErrInboundTransferMismatch = errors.New("inbound transfer chain or currency does not match the payment")
+	// ErrInvalidInboundTransferAction is returned when an unmatched transfer is resolved with an unsupported action
+	ErrInvalidInboundTransferAction = errors.New("unmatched transfer can only be attached, refunded or booked to treasury")
+	// ErrInboundTransfersNotConfigured is returned when this service has no inbound transfer repository
+	ErrInboundTransfersNotConfigured = errors.New("inbound transfer repository not configured")
+
+	// ErrQuoteNotFound is returned when a quote is not found or belongs to another merchant
+	ErrQuoteNotFound = errors.New("quote not found")
+	// ErrQuoteExpired is returned when a payment is created from a quote past its valid_until
+	ErrQuoteExpired = errors.New("quote has expired")
+	// ErrQuoteAlreadyUsed is returned when a payment was already created from the quote
+	ErrQuoteAlreadyUsed = errors.New("quote has already been used")
+	// ErrQuoteMismatch is returned when the payment amount, chain or currency is not covered by the quote
+	ErrQuoteMismatch = errors.New("payment does not match the quote")
+	// ErrInvalidQuoteSignature is returned when the stored quote terms no longer match their signature
+	ErrInvalidQuoteSignature = errors.New("invalid quote signature")
+	// ErrQuotesNotConfigured is returned when this service has no quote repository or signing key
+	ErrQuotesNotConfigured = errors.New("quotes not configured")
+
+	// ErrPaymentLinkNotFound is returned when a payment link is not found or belongs to another merchant
+	ErrPaymentLinkNotFound = errors.New("payment link not found")
+	// ErrPaymentLinkInactive is returned when a payer opens a link the merchant deactivated
+	ErrPaymentLinkInactive = errors.New("payment link is no longer active")
+	// ErrPaymentLinkExpired is returned when a payer opens a link past its expiry
+	ErrPaymentLinkExpired = errors.New("payment link has expired")
+	// ErrPaymentLinkExhausted is returned when a link already created its maximum number of payments
+	ErrPaymentLinkExhausted = errors.New("payment link has reached its maximum number of uses")
+
+	// ErrInvoiceNotFound is returned when an invoice is not found or belongs to another merchant
+	ErrInvoiceNotFound = errors.New("invoice not found")
+	// ErrInvoiceNumberExists is returned when the merchant already has an invoice with the same number
+	ErrInvoiceNumberExists = errors.New("invoice with this number already exists")
+	// ErrInvoiceVoid is returned when a payment is created for a voided invoice
+	ErrInvoiceVoid = errors.New("invoice is void")
+	// ErrInvoiceAlreadyPaid is returned when a payment is created for, or a merchant voids, a paid invoice
+	ErrInvoiceAlreadyPaid = errors.New("invoice is already paid")
+	// ErrInvoicesNotConfigured is returned when this service has no invoice repository
+	ErrInvoicesNotConfigured = errors.New("invoice repository not configured")
+
+	// ErrSubscriptionPlanNotFound is returned when a plan is not found or belongs to another merchant
+	ErrSubscriptionPlanNotFound = errors.New("subscription plan not found")
+	// ErrInvalidSubscriptionPlan is returned when a plan has an unknown billing interval or count
+	ErrInvalidSubscriptionPlan = errors.New("invalid subscription plan")
+	// ErrSubscriptionPlanArchived is returned when a customer is subscribed to an archived plan
+	ErrSubscriptionPlanArchived = errors.New("subscription plan is archived")
+	// ErrSubscriptionNotFound is returned when a subscription is not found or belongs to another merchant
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrSubscriptionCanceled is returned when a canceled subscription is billed or changed
+	ErrSubscriptionCanceled = errors.New("subscription is canceled")
+	// ErrSubscriptionCycleNotFound is returned when a billing cycle is not found
+	ErrSubscriptionCycleNotFound = errors.New("subscription cycle not found")
+	// ErrSubscriptionCycleExists is returned when a billing cycle was already issued by another worker
+	ErrSubscriptionCycleExists = errors.New("subscription cycle already issued")
+	// ErrSubscriptionCycleNotOpen is returned when a cycle was paid or closed while it was being updated
+	ErrSubscriptionCycleNotOpen = errors.New("subscription cycle is no longer open")
+
+	// ErrInvalidPaymentSplits is returned when the splits of a payment do not add up to its net amount
+	ErrInvalidPaymentSplits = errors.New("invalid payment splits")
+	// ErrSplitRecipientNotApproved is returned when a split credits a merchant that is not KYC approved
+	ErrSplitRecipientNotApproved = errors.New("split recipient is not an approved merchant")
+
+	// ErrPaymentBatchNotFound is returned when a payment batch is not found or belongs to another merchant
+	ErrPaymentBatchNotFound = errors.New("payment batch not found")
+	// ErrInvalidPaymentBatch is returned when a batch is empty or has more items than allowed
+	ErrInvalidPaymentBatch = errors.New("invalid payment batch")
+	// ErrPaymentBatchesNotConfigured is returned when this service cannot queue batches for the worker
+	ErrPaymentBatchesNotConfigured = errors.New("asynchronous payment batches not configured")
+
+	// ErrTokenNotFound is returned when a token is not in the registry
+	ErrTokenNotFound = errors.New("token not found")
+	// ErrTokenAlreadyExists is returned when the chain already lists a token with the symbol
+	ErrTokenAlreadyExists = errors.New("token already exists on this chain")
+	// ErrInvalidToken is returned when a registry token has invalid fields
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenAmountOutOfRange is returned when a payment amount is outside the token bounds
+	ErrTokenAmountOutOfRange = errors.New("payment amount outside the token limits")
+
+	// ErrSolanaPayNotConfigured is returned when this service cannot compose Solana Pay transactions
+	ErrSolanaPayNotConfigured = errors.New("solana pay transaction requests not configured")
+	// ErrInvalidSolanaPayAccount is returned when the account of a transaction request is not a Solana public key
+	ErrInvalidSolanaPayAccount = errors.New("invalid solana pay account")
+
+	// ErrTestPaymentNotSimulated is returned when a test payment is confirmed by anything but a simulation
+	ErrTestPaymentNotSimulated = errors.New("test payments are only settled by simulation")
+	// ErrNotTestPayment is returned when a live payment is simulated
+	ErrNotTestPayment = errors.New("payment is not a test payment")
+	// ErrInvalidSimulationOutcome is returned when a simulation outcome is unknown or does not apply to the payment
+	ErrInvalidSimulationOutcome = errors.New("invalid simulation outcome")
+	// ErrTestPaymentNotRefundable is returned when a refund is requested for a test payment
+	ErrTestPaymentNotRefundable = errors.New("test payments cannot be refunded on-chain")
+	// ErrSplitRecipientTestMode is returned when a split credits a merchant of the other mode, live or sandbox
+	ErrSplitRecipientTestMode = errors.New("split recipient is not in the payment's mode")
 )
@@ -7,6 +7,29 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// PaymentEventReversed is sent to merchants when a completed or confirming payment is reversed
+const PaymentEventReversed = "payment.reversed"
+
+// Payment status webhook events
+const (
+	PaymentEventCompleted = "payment.completed"
+	PaymentEventOverpaid  = "payment.overpaid"
+	PaymentEventUnderpaid = "payment.underpaid"
+	PaymentEventExpired   = "payment.expired"
+)
+
+// Late payment webhook events
+const (
+	PaymentEventLatePaid            = "payment.late_paid"
+	PaymentEventLatePaymentResolved = "payment.late_payment_resolved"
+)
+
+// Merchant action webhook events
+const (
+	PaymentEventCanceled       = "payment.canceled"
+	PaymentEventExpiryExtended = "payment.expiry_extended"
+)
+
 // PaymentCreatedEvent is published when a new payment is created
 type PaymentCreatedEvent struct {
 	events.BaseEvent
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// InboundTransferStatus represents what happened to a transfer received on a watched address
+type InboundTransferStatus string
+
+const (
+	InboundTransferStatusMatched       InboundTransferStatus = "matched"        // Confirmed a payment when the listener saw it
+	InboundTransferStatusUnmatched     InboundTransferStatus = "unmatched"      // Queued for an operator
+	InboundTransferStatusAttached      InboundTransferStatus = "attached"       // Attached to a payment by an operator
+	InboundTransferStatusRefundPending InboundTransferStatus = "refund_pending" // Marked by an operator to be sent back
+	InboundTransferStatusTreasury      InboundTransferStatus = "treasury"       // Booked by an operator as a treasury deposit
+	InboundTransferStatusRefunded      InboundTransferStatus = "refunded"       // Sent back to the sender, see RefundTxHash
+)
+
+// IsValid returns true if the status is one of the supported statuses
+func (s InboundTransferStatus) IsValid() bool {
+	switch s {
+	case InboundTransferStatusMatched, InboundTransferStatusUnmatched, InboundTransferStatusAttached,
+		InboundTransferStatusRefundPending, InboundTransferStatusTreasury, InboundTransferStatusRefunded:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnmatchedReason explains why a transfer did not confirm a payment
+type UnmatchedReason string
+
+const (
+	UnmatchedReasonNoReference      UnmatchedReason = "no_reference"      // No memo and not sent to a deposit address
+	UnmatchedReasonUnknownReference UnmatchedReason = "unknown_reference" // The memo does not identify a payment
+	UnmatchedReasonRejected         UnmatchedReason = "rejected"          // The payment refused the transfer, see UnmatchedDetail
+)
+
+// InboundTransfer is a stablecoin transfer received on a watched wallet or deposit address
+// Every transfer the listeners see is recorded, those that did not confirm a payment are
+// queued as unmatched until an operator resolves them.
+type InboundTransfer struct {
+	ID      string `json:"id" db:"id"`
+	Chain   Chain  `json:"chain" db:"chain" validate:"required"`
+	Network string `json:"network" db:"network" validate:"required"`
+
+	TxHash      string        `json:"tx_hash" db:"tx_hash" validate:"required"`
+	BlockNumber sql.NullInt64 `json:"block_number,omitempty" db:"block_number"`
+	BlockTime   sql.NullTime  `json:"block_time,omitempty" db:"block_time"`
+
+	FromAddress  sql.NullString  `json:"from_address,omitempty" db:"from_address"`
+	ToAddress    string          `json:"to_address" db:"to_address" validate:"required"`
+	Amount       decimal.Decimal `json:"amount" db:"amount" validate:"required,gt=0"`
+	Currency     string          `json:"currency" db:"currency" validate:"required"`
+	TokenAddress sql.NullString  `json:"token_address,omitempty" db:"token_address"`
+
+	// Memo carried by the transfer and the payment it confirmed or was attached to
+	Reference sql.NullString `json:"reference,omitempty" db:"reference"`
+	PaymentID sql.NullString `json:"payment_id,omitempty" db:"payment_id"`
+
+	Status          InboundTransferStatus `json:"status" db:"status" validate:"required"`
+	UnmatchedReason sql.NullString        `json:"unmatched_reason,omitempty" db:"unmatched_reason"`
+	UnmatchedDetail sql.NullString        `json:"unmatched_detail,omitempty" db:"unmatched_detail"`
+
+	// Operator resolution of an unmatched transfer
+	ResolvedBy     sql.NullString `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt     sql.NullTime   `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolutionNote sql.NullString `json:"resolution_note,omitempty" db:"resolution_note"`
+
+	// Transfer that sent a refund_pending transfer back to the sender, recorded by an operator
+	RefundTxHash sql.NullString `json:"refund_tx_hash,omitempty" db:"refund_tx_hash"`
+	RefundedBy   sql.NullString `json:"refunded_by,omitempty" db:"refunded_by"`
+	RefundedAt   sql.NullTime   `json:"refunded_at,omitempty" db:"refunded_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (InboundTransfer) TableName() string {
+	return "inbound_transfers"
+}
+
+// IsUnmatched returns true if the transfer is waiting for an operator
+func (t *InboundTransfer) IsUnmatched() bool {
+	return t.Status == InboundTransferStatusUnmatched
+}
+
+// IsRefundPending returns true if the transfer is waiting to be sent back to the sender
+func (t *InboundTransfer) IsRefundPending() bool {
+	return t.Status == InboundTransferStatusRefundPending
+}
+
+// GetPaymentID returns the payment ID if available
+func (t *InboundTransfer) GetPaymentID() string {
+	if t.PaymentID.Valid {
+		return t.PaymentID.String
+	}
+	return ""
+}
+
+// GetUnmatchedReason returns the unmatched reason, empty if the transfer matched a payment
+func (t *InboundTransfer) GetUnmatchedReason() UnmatchedReason {
+	if t.UnmatchedReason.Valid {
+		return UnmatchedReason(t.UnmatchedReason.String)
+	}
+	return ""
+}
+
+// MarkUnmatched queues the transfer for an operator
+// paymentID is the payment the transfer referenced (memo or deposit address), empty if none,
+// matchErr is the reason the payment did not accept it, nil if the transfer had no reference.
+func (t *InboundTransfer) MarkUnmatched(paymentID string, matchErr error) {
+	reason := UnmatchedReasonRejected
+	switch {
+	case paymentID == "":
+		reason = UnmatchedReasonNoReference
+	case errors.Is(matchErr, ErrPaymentNotFound):
+		reason = UnmatchedReasonUnknownReference
+	case uuid.Validate(paymentID) != nil:
+		// Payment IDs are UUIDs, anything else in the memo is not a reference we issued
+		reason = UnmatchedReasonUnknownReference
+	}
+
+	t.Status = InboundTransferStatusUnmatched
+	t.UnmatchedReason = sql.NullString{String: string(reason), Valid: true}
+	t.PaymentID = sql.NullString{}
+	if reason == UnmatchedReasonRejected {
+		// The payment exists, keep it so operators see which payment refused the transfer
+		t.PaymentID = sql.NullString{String: paymentID, Valid: true}
+	}
+	if matchErr != nil {
+		t.UnmatchedDetail = sql.NullString{String: matchErr.Error(), Valid: true}
+	}
+}
+
+// MarkMatched records the payment the transfer confirmed
+func (t *InboundTransfer) MarkMatched(paymentID string) {
+	t.Status = InboundTransferStatusMatched
+	t.PaymentID = sql.NullString{String: paymentID, Valid: true}
+	t.UnmatchedReason = sql.NullString{}
+	t.UnmatchedDetail = sql.NullString{}
+}
+
+// Resolve records an operator's resolution of an unmatched transfer
+func (t *InboundTransfer) Resolve(status InboundTransferStatus, resolvedBy, note string) {
+	t.Status = status
+	t.ResolvedBy = sql.NullString{String: resolvedBy, Valid: resolvedBy != ""}
+	t.ResolvedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	t.ResolutionNote = sql.NullString{String: note, Valid: note != ""}
+}
+
+// MarkRefunded records the transfer that sent a refund_pending transfer back to the sender
+func (t *InboundTransfer) MarkRefunded(refundTxHash, refundedBy string) {
+	t.Status = InboundTransferStatusRefunded
+	t.RefundTxHash = sql.NullString{String: refundTxHash, Valid: true}
+	t.RefundedBy = sql.NullString{String: refundedBy, Valid: refundedBy != ""}
+	t.RefundedAt = sql.NullTime{Time: time.Now(), Valid: true}
+}
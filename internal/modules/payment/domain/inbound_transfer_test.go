@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInboundTransfer_MarkUnmatched(t *testing.T) {
+	paymentID := "3f8a4c1e-9b2d-4e6f-8a1c-5d7e9f0b2c4a"
+
+	tests := []struct {
+		name            string
+		paymentID       string
+		matchErr        error
+		expectedReason  UnmatchedReason
+		expectedPayment string
+	}{
+		{"no memo", "", nil, UnmatchedReasonNoReference, ""},
+		{"payment not found", paymentID, fmt.Errorf("failed to get payment: %w", ErrPaymentNotFound), UnmatchedReasonUnknownReference, ""},
+		{"memo is not a payment ID", "invoice 42", fmt.Errorf("failed to get payment: invalid input syntax for type uuid"), UnmatchedReasonUnknownReference, ""},
+		{"refused by the payment", paymentID, fmt.Errorf("payment confirmation failed: %w", ErrInvalidPaymentState), UnmatchedReasonRejected, paymentID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfer := &InboundTransfer{}
+			transfer.MarkUnmatched(tt.paymentID, tt.matchErr)
+
+			assert.True(t, transfer.IsUnmatched())
+			assert.Equal(t, tt.expectedReason, transfer.GetUnmatchedReason())
+			assert.Equal(t, tt.expectedPayment, transfer.GetPaymentID())
+			assert.Equal(t, tt.matchErr != nil, transfer.UnmatchedDetail.Valid)
+		})
+	}
+}
+
+func TestInboundTransfer_MarkMatched(t *testing.T) {
+	transfer := &InboundTransfer{}
+	transfer.MarkUnmatched("", nil)
+	transfer.MarkMatched("3f8a4c1e-9b2d-4e6f-8a1c-5d7e9f0b2c4a")
+
+	assert.Equal(t, InboundTransferStatusMatched, transfer.Status)
+	assert.Empty(t, transfer.GetUnmatchedReason())
+	assert.Equal(t, "3f8a4c1e-9b2d-4e6f-8a1c-5d7e9f0b2c4a", transfer.GetPaymentID())
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceStatus represents the status of an invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusOpen          InvoiceStatus = "open"
+	InvoiceStatusPartiallyPaid InvoiceStatus = "partially_paid"
+	InvoiceStatusPaid          InvoiceStatus = "paid"
+	InvoiceStatusVoid          InvoiceStatus = "void" // Cancelled by the merchant, no new payments
+)
+
+// Invoice webhook events
+const (
+	InvoiceEventCreated        = "invoice.created"
+	InvoiceEventPaymentCreated = "invoice.payment_created"
+	InvoiceEventPartiallyPaid  = "invoice.partially_paid"
+	InvoiceEventPaid           = "invoice.paid"
+	InvoiceEventVoided         = "invoice.voided"
+)
+
+// MaxInvoiceTaxRate caps the tax rate of an invoice
+var MaxInvoiceTaxRate = decimal.NewFromInt(1)
+
+// InvoiceLineItem is a billed item of an invoice
+type InvoiceLineItem struct {
+	Description string          `json:"description"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Amount      decimal.Decimal `json:"amount"` // Quantity x unit price
+}
+
+// InvoiceLineItems is stored as a JSONB array
+type InvoiceLineItems []InvoiceLineItem
+
+// Value implements the driver.Valuer interface for database writes
+func (items InvoiceLineItems) Value() (driver.Value, error) {
+	if items == nil {
+		return nil, nil
+	}
+	return json.Marshal(items)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (items *InvoiceLineItems) Scan(value interface{}) error {
+	if value == nil {
+		*items = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan invoice line items: not a byte slice")
+	}
+
+	return json.Unmarshal(bytes, items)
+}
+
+// Invoice bills a customer for line items plus tax, payable by one or more payments until the due date
+type Invoice struct {
+	ID            string         `json:"id" db:"id"`
+	MerchantID    string         `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+	InvoiceNumber string         `json:"invoice_number" db:"invoice_number" validate:"required,max=50"`
+	CustomerName  sql.NullString `json:"customer_name,omitempty" db:"customer_name"`
+	CustomerEmail sql.NullString `json:"customer_email,omitempty" db:"customer_email"`
+	Description   sql.NullString `json:"description,omitempty" db:"description"`
+
+	// Amounts are in the pricing currency
+	PricingCurrency string           `json:"pricing_currency" db:"pricing_currency" validate:"required,len=3"`
+	LineItems       InvoiceLineItems `json:"line_items" db:"line_items" validate:"required,min=1"`
+	Subtotal        decimal.Decimal  `json:"subtotal" db:"subtotal"`
+	TaxRate         decimal.Decimal  `json:"tax_rate" db:"tax_rate" validate:"gte=0,lte=1"`
+	TaxAmount       decimal.Decimal  `json:"tax_amount" db:"tax_amount"`
+	TotalAmount     decimal.Decimal  `json:"total_amount" db:"total_amount"`
+	AmountPaid      decimal.Decimal  `json:"amount_paid" db:"amount_paid"` // Sum of the completed payments
+
+	DueDate time.Time     `json:"due_date" db:"due_date"`
+	Status  InvoiceStatus `json:"status" db:"status" validate:"required,oneof=open partially_paid paid void"`
+
+	Metadata database.JSONBMap `json:"metadata,omitempty" db:"metadata"`
+
+	PaidAt    sql.NullTime `json:"paid_at,omitempty" db:"paid_at"`
+	VoidedAt  sql.NullTime `json:"voided_at,omitempty" db:"voided_at"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// CalculateTotals prices the line items and derives the subtotal, tax and total
+func (i *Invoice) CalculateTotals() error {
+	if len(i.LineItems) == 0 {
+		return fmt.Errorf("%w: an invoice needs at least one line item", ErrInvalidAmount)
+	}
+	if i.TaxRate.IsNegative() || i.TaxRate.GreaterThan(MaxInvoiceTaxRate) {
+		return fmt.Errorf("%w: tax rate must be between 0 and 1", ErrInvalidAmount)
+	}
+
+	subtotal := decimal.Zero
+	for idx := range i.LineItems {
+		item := &i.LineItems[idx]
+		if item.Quantity.LessThanOrEqual(decimal.Zero) || item.UnitPrice.IsNegative() {
+			return fmt.Errorf("%w: line item %d needs a positive quantity and price", ErrInvalidAmount, idx+1)
+		}
+		item.Amount = item.Quantity.Mul(item.UnitPrice).Round(2)
+		subtotal = subtotal.Add(item.Amount)
+	}
+
+	i.Subtotal = subtotal
+	i.TaxAmount = subtotal.Mul(i.TaxRate).Round(2)
+	i.TotalAmount = subtotal.Add(i.TaxAmount)
+	if i.TotalAmount.LessThanOrEqual(decimal.Zero) {
+		return fmt.Errorf("%w: invoice total must be positive", ErrInvalidAmount)
+	}
+	return nil
+}
+
+// AmountDue returns what is left to pay on the invoice
+func (i *Invoice) AmountDue() decimal.Decimal {
+	due := i.TotalAmount.Sub(i.AmountPaid)
+	if due.IsNegative() {
+		return decimal.Zero
+	}
+	return due
+}
+
+// IsOverdue returns true if the invoice is not fully paid after its due date
+func (i *Invoice) IsOverdue() bool {
+	if i.Status == InvoiceStatusPaid || i.Status == InvoiceStatusVoid {
+		return false
+	}
+	return time.Now().After(i.DueDate)
+}
+
+// CheckPayable returns why no payment can be created for the invoice, nil if one can
+func (i *Invoice) CheckPayable() error {
+	switch i.Status {
+	case InvoiceStatusVoid:
+		return ErrInvoiceVoid
+	case InvoiceStatusPaid:
+		return ErrInvoiceAlreadyPaid
+	}
+	return nil
+}
+
+// ApplyPayment adds a completed payment to the amount paid, a negative amount removes a reversed one
+// Returns true if the status changed. Void invoices keep their status.
+func (i *Invoice) ApplyPayment(amount decimal.Decimal) bool {
+	i.AmountPaid = i.AmountPaid.Add(amount)
+	if i.AmountPaid.IsNegative() {
+		i.AmountPaid = decimal.Zero
+	}
+
+	if i.Status == InvoiceStatusVoid {
+		return false
+	}
+
+	previous := i.Status
+	switch {
+	case i.AmountPaid.GreaterThanOrEqual(i.TotalAmount):
+		i.Status = InvoiceStatusPaid
+		if !i.PaidAt.Valid {
+			i.PaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	case i.AmountPaid.IsPositive():
+		i.Status = InvoiceStatusPartiallyPaid
+		i.PaidAt = sql.NullTime{}
+	default:
+		i.Status = InvoiceStatusOpen
+		i.PaidAt = sql.NullTime{}
+	}
+
+	return i.Status != previous
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInvoice(t *testing.T) *Invoice {
+	invoice := &Invoice{
+		PricingCurrency: "USD",
+		LineItems: InvoiceLineItems{
+			{Description: "Consulting", Quantity: decimal.NewFromInt(3), UnitPrice: decimal.RequireFromString("120.50")},
+			{Description: "Hosting", Quantity: decimal.NewFromInt(1), UnitPrice: decimal.RequireFromString("19.99")},
+		},
+		TaxRate: decimal.RequireFromString("0.1"),
+		DueDate: time.Now().Add(7 * 24 * time.Hour),
+		Status:  InvoiceStatusOpen,
+	}
+	require.NoError(t, invoice.CalculateTotals())
+	return invoice
+}
+
+func TestInvoice_CalculateTotals(t *testing.T) {
+	invoice := newTestInvoice(t)
+
+	assert.True(t, invoice.LineItems[0].Amount.Equal(decimal.RequireFromString("361.50")))
+	assert.True(t, invoice.Subtotal.Equal(decimal.RequireFromString("381.49")))
+	assert.True(t, invoice.TaxAmount.Equal(decimal.RequireFromString("38.15")))
+	assert.True(t, invoice.TotalAmount.Equal(decimal.RequireFromString("419.64")))
+	assert.True(t, invoice.AmountDue().Equal(invoice.TotalAmount))
+
+	invoice.TaxRate = decimal.RequireFromString("1.5")
+	assert.ErrorIs(t, invoice.CalculateTotals(), ErrInvalidAmount)
+
+	invoice.TaxRate = decimal.Zero
+	invoice.LineItems[1].Quantity = decimal.Zero
+	assert.ErrorIs(t, invoice.CalculateTotals(), ErrInvalidAmount)
+
+	assert.ErrorIs(t, (&Invoice{}).CalculateTotals(), ErrInvalidAmount)
+}
+
+func TestInvoice_ApplyPayment(t *testing.T) {
+	invoice := newTestInvoice(t)
+
+	assert.True(t, invoice.ApplyPayment(decimal.NewFromInt(200)))
+	assert.Equal(t, InvoiceStatusPartiallyPaid, invoice.Status)
+	assert.True(t, invoice.AmountDue().Equal(decimal.RequireFromString("219.64")))
+
+	// A second installment keeps the status
+	assert.False(t, invoice.ApplyPayment(decimal.NewFromInt(100)))
+	assert.Equal(t, InvoiceStatusPartiallyPaid, invoice.Status)
+
+	assert.True(t, invoice.ApplyPayment(decimal.RequireFromString("119.64")))
+	assert.Equal(t, InvoiceStatusPaid, invoice.Status)
+	assert.True(t, invoice.PaidAt.Valid)
+	assert.True(t, invoice.AmountDue().IsZero())
+	assert.ErrorIs(t, invoice.CheckPayable(), ErrInvoiceAlreadyPaid)
+	assert.False(t, invoice.IsOverdue())
+
+	// A reversed payment reopens the invoice
+	assert.True(t, invoice.ApplyPayment(decimal.NewFromInt(-100)))
+	assert.Equal(t, InvoiceStatusPartiallyPaid, invoice.Status)
+	assert.False(t, invoice.PaidAt.Valid)
+
+	assert.True(t, invoice.ApplyPayment(decimal.NewFromInt(-500)))
+	assert.Equal(t, InvoiceStatusOpen, invoice.Status)
+	assert.True(t, invoice.AmountPaid.IsZero())
+}
+
+func TestInvoice_ApplyPaymentVoid(t *testing.T) {
+	invoice := newTestInvoice(t)
+	invoice.Status = InvoiceStatusVoid
+
+	assert.False(t, invoice.ApplyPayment(invoice.TotalAmount))
+	assert.Equal(t, InvoiceStatusVoid, invoice.Status)
+	assert.True(t, invoice.AmountPaid.Equal(invoice.TotalAmount))
+	assert.ErrorIs(t, invoice.CheckPayable(), ErrInvoiceVoid)
+}
+
+func TestInvoice_IsOverdue(t *testing.T) {
+	invoice := newTestInvoice(t)
+	assert.False(t, invoice.IsOverdue())
+
+	invoice.DueDate = time.Now().Add(-time.Hour)
+	assert.True(t, invoice.IsOverdue())
+
+	invoice.Status = InvoiceStatusVoid
+	assert.False(t, invoice.IsOverdue())
+}
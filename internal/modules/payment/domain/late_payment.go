@@ -0,0 +1,54 @@
+package domain
+
+// LatePaymentPolicy is the merchant's choice for transfers that arrive after a payment expired
+type LatePaymentPolicy string
+
+const (
+	// LatePaymentPolicyAccept completes the payment with the amount received, valued at a re-quoted exchange rate
+	LatePaymentPolicyAccept LatePaymentPolicy = "accept"
+	// LatePaymentPolicyRefund sends the amount received back to the payer
+	LatePaymentPolicyRefund LatePaymentPolicy = "refund"
+	// LatePaymentPolicyReview holds the amount received until an operator accepts or refunds it
+	LatePaymentPolicyReview LatePaymentPolicy = "review"
+)
+
+// IsValid returns true if the policy is one of the supported policies
+func (p LatePaymentPolicy) IsValid() bool {
+	return p == LatePaymentPolicyAccept || p == LatePaymentPolicyRefund || p == LatePaymentPolicyReview
+}
+
+// LatePaymentResolution tracks what happened to a late payment
+type LatePaymentResolution string
+
+const (
+	LatePaymentPendingReview LatePaymentResolution = "pending_review" // Held, waiting for an operator
+	LatePaymentAccepted      LatePaymentResolution = "accepted"       // Re-quoted and counted as a regular payment
+	LatePaymentRefunding     LatePaymentResolution = "refunding"      // Refund to the payer created, not yet finalized
+	LatePaymentRefunded      LatePaymentResolution = "refunded"       // Refund to the payer finalized
+)
+
+// IsLatePaid returns true if the payment holds transfers that arrived after it expired
+func (p *Payment) IsLatePaid() bool {
+	return p.Status == PaymentStatusLatePaid
+}
+
+// AcceptsLatePayment returns true if a transfer arriving now is a late payment:
+// the payment expired (or its window passed before the expiry job ran) or was canceled without being paid in full
+func (p *Payment) AcceptsLatePayment() bool {
+	switch p.Status {
+	case PaymentStatusExpired, PaymentStatusLatePaid, PaymentStatusCanceled:
+		return true
+	case PaymentStatusCreated, PaymentStatusPending, PaymentStatusPendingCompliance, PaymentStatusUnderpaid:
+		return p.IsExpired()
+	default:
+		return false
+	}
+}
+
+// GetLateResolution returns the late payment resolution, empty if the payment was never paid late
+func (p *Payment) GetLateResolution() LatePaymentResolution {
+	if p.LateResolution.Valid {
+		return LatePaymentResolution(p.LateResolution.String)
+	}
+	return ""
+}
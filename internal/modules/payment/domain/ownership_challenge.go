@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DefaultOwnershipChallengeValidity is how long an EVM payer has to sign an issued ownership challenge
+const DefaultOwnershipChallengeValidity = 10 * time.Minute
+
+// OwnershipChallenge is a nonce issued to an EVM payer to sign as proof of wallet ownership
+// A challenge is accepted once, for the wallet and chain it was issued to, before it expires.
+type OwnershipChallenge struct {
+	Nonce         string `json:"nonce" db:"nonce"`
+	Chain         Chain  `json:"chain" db:"chain"`
+	WalletAddress string `json:"wallet_address" db:"wallet_address"` // Lower case hex
+
+	ExpiresAt time.Time    `json:"expires_at" db:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at,omitempty" db:"used_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (OwnershipChallenge) TableName() string {
+	return "ownership_challenges"
+}
@@ -15,12 +15,29 @@ const (
 	PaymentStatusCreated           PaymentStatus = "created"
 	PaymentStatusPending           PaymentStatus = "pending"
 	PaymentStatusPendingCompliance PaymentStatus = "pending_compliance"
+	PaymentStatusUnderpaid         PaymentStatus = "underpaid"
 	PaymentStatusConfirming        PaymentStatus = "confirming"
 	PaymentStatusCompleted         PaymentStatus = "completed"
+	PaymentStatusOverpaid          PaymentStatus = "overpaid"
 	PaymentStatusExpired           PaymentStatus = "expired"
 	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusReversed          PaymentStatus = "reversed"  // A counted transfer disappeared from the chain (reorg)
+	PaymentStatusLatePaid          PaymentStatus = "late_paid" // A transfer arrived after the payment expired
+	PaymentStatusCanceled          PaymentStatus = "canceled"  // Canceled by the merchant before it was paid
 )
 
+// IsValid returns true if the status is one of the payment statuses
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentStatusCreated, PaymentStatusPending, PaymentStatusPendingCompliance, PaymentStatusUnderpaid,
+		PaymentStatusConfirming, PaymentStatusCompleted, PaymentStatusOverpaid, PaymentStatusExpired,
+		PaymentStatusFailed, PaymentStatusReversed, PaymentStatusLatePaid, PaymentStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
 // Chain represents a blockchain network
 type Chain string
 
@@ -28,6 +45,7 @@ const (
 	ChainSolana   Chain = "solana"
 	ChainBSC      Chain = "bsc"
 	ChainEthereum Chain = "ethereum"
+	ChainTRON     Chain = "tron"
 )
 
 type Payment struct {
@@ -37,31 +55,65 @@ type Payment struct {
 	// Payment details
 	AmountVND    decimal.Decimal `json:"amount_vnd" db:"amount_vnd" validate:"required,gt=0"`
 	AmountCrypto decimal.Decimal `json:"amount_crypto" db:"amount_crypto" validate:"required,gt=0"`
-	Currency     string          `json:"currency" db:"currency" validate:"required,oneof=USDT USDC BUSD"`
-	Chain        Chain           `json:"chain" db:"chain" validate:"required,oneof=solana bsc ethereum"`
+	Currency     string          `json:"currency" db:"currency" validate:"required,max=10"`
+	Chain        Chain           `json:"chain" db:"chain" validate:"required,max=20"`
 	ExchangeRate decimal.Decimal `json:"exchange_rate" db:"exchange_rate" validate:"required,gt=0"`
 
+	// Price set by the merchant, AmountVND is its VND equivalent at PricingRate
+	PricingCurrency string          `json:"pricing_currency" db:"pricing_currency" validate:"required,len=3"`
+	PricingAmount   decimal.Decimal `json:"pricing_amount" db:"pricing_amount" validate:"required,gt=0"`
+	PricingRate     decimal.Decimal `json:"pricing_rate" db:"pricing_rate" validate:"required,gt=0"` // VND per unit of the pricing currency
+
 	// Merchant reference
 	OrderID     sql.NullString `json:"order_id,omitempty" db:"order_id"`
 	Description sql.NullString `json:"description,omitempty" db:"description"`
 	CallbackURL sql.NullString `json:"callback_url,omitempty" db:"callback_url" validate:"omitempty,url"`
 
 	// Payment status
-	Status PaymentStatus `json:"status" db:"status" validate:"required,oneof=created pending pending_compliance confirming completed expired failed"`
+	Status PaymentStatus `json:"status" db:"status" validate:"required,oneof=created pending pending_compliance underpaid confirming completed overpaid expired failed reversed late_paid canceled"`
 
 	// Blockchain transaction details
 	TxHash          sql.NullString `json:"tx_hash,omitempty" db:"tx_hash"`
 	TxConfirmations sql.NullInt32  `json:"tx_confirmations,omitempty" db:"tx_confirmations"`
 	FromAddress     sql.NullString `json:"from_address,omitempty" db:"from_address"`
 
+	// Sum of all transfers recorded in payment_transfers
+	AmountReceived decimal.Decimal `json:"amount_received" db:"amount_received"`
+
 	// Payment reference (used in memo)
 	PaymentReference  string `json:"payment_reference" db:"payment_reference" validate:"required,min=8,max=100"`
 	DestinationWallet string `json:"destination_wallet" db:"destination_wallet" validate:"required"`
 
+	// Reference key of the Solana Pay transactions composed for the payment, set on the first transaction request
+	SolanaPayReference sql.NullString `json:"solana_pay_reference,omitempty" db:"solana_pay_reference"`
+
+	// Payment of a sandbox merchant, never matched by listeners and only settled by simulation
+	TestMode bool `json:"test_mode" db:"test_mode"`
+
 	// Timing
 	ExpiresAt   time.Time    `json:"expires_at" db:"expires_at"`
 	PaidAt      sql.NullTime `json:"paid_at,omitempty" db:"paid_at"`
 	ConfirmedAt sql.NullTime `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	ReversedAt  sql.NullTime `json:"reversed_at,omitempty" db:"reversed_at"`
+
+	// Merchant cancellation and expiry extensions
+	CanceledAt       sql.NullTime   `json:"canceled_at,omitempty" db:"canceled_at"`
+	CancelReason     sql.NullString `json:"cancel_reason,omitempty" db:"cancel_reason"`
+	ExpiryExtensions int            `json:"expiry_extensions" db:"expiry_extensions"`
+
+	// Quote whose locked exchange rate the payment was created at
+	QuoteID sql.NullString `json:"quote_id,omitempty" db:"quote_id"`
+
+	// Payment link or invoice the payment was created from
+	PaymentLinkID sql.NullString `json:"payment_link_id,omitempty" db:"payment_link_id"`
+	InvoiceID     sql.NullString `json:"invoice_id,omitempty" db:"invoice_id"`
+
+	// Subscription billing cycle the payment was issued for
+	SubscriptionCycleID sql.NullString `json:"subscription_cycle_id,omitempty" db:"subscription_cycle_id"`
+
+	// Late payment (transfers received after expiry)
+	LatePaidAt     sql.NullTime   `json:"late_paid_at,omitempty" db:"late_paid_at"`
+	LateResolution sql.NullString `json:"late_resolution,omitempty" db:"late_resolution"`
 
 	// Compliance timing (FATF Travel Rule)
 	ComplianceDeadline    sql.NullTime `json:"compliance_deadline,omitempty" db:"compliance_deadline"`
@@ -72,11 +124,21 @@ type Payment struct {
 	FeeVND        decimal.Decimal `json:"fee_vnd" db:"fee_vnd"`
 	NetAmountVND  decimal.Decimal `json:"net_amount_vnd" db:"net_amount_vnd"`
 
+	// Fee and net amount in the pricing currency
+	PricingFee       decimal.Decimal `json:"pricing_fee" db:"pricing_fee"`
+	PricingNetAmount decimal.Decimal `json:"pricing_net_amount" db:"pricing_net_amount"`
+
+	// Marketplace shares of the net amount, credited to each recipient when the payment completes
+	Splits PaymentSplits `json:"splits,omitempty" db:"splits" gorm:"type:jsonb"`
+
 	// Status tracking
 	FailureReason sql.NullString `json:"failure_reason,omitempty" db:"failure_reason"`
 
 	// Metadata
-	Metadata database.JSONBMap `json:"metadata,omitempty" db:"metadata"`
+	Metadata database.JSONBMap `json:"metadata,omitempty" db:"metadata" gorm:"type:jsonb"`
+
+	// Optimistic lock, incremented by every update
+	Version int `json:"version" db:"version"`
 
 	// Timestamps
 	CreatedAt time.Time    `json:"created_at" db:"created_at"`
@@ -89,9 +151,14 @@ func (p *Payment) IsExpired() bool {
 	return time.Now().After(p.ExpiresAt)
 }
 
-// IsCompleted returns true if the payment is completed
+// IsCompleted returns true if the payment is completed (overpaid payments are completed with a surplus)
 func (p *Payment) IsCompleted() bool {
-	return p.Status == PaymentStatusCompleted
+	return p.Status == PaymentStatusCompleted || p.Status == PaymentStatusOverpaid
+}
+
+// IsUnderpaid returns true if the payment has received less than the requested amount
+func (p *Payment) IsUnderpaid() bool {
+	return p.Status == PaymentStatusUnderpaid
 }
 
 // IsPending returns true if the payment is pending confirmation
@@ -99,9 +166,69 @@ func (p *Payment) IsPending() bool {
 	return p.Status == PaymentStatusPending || p.Status == PaymentStatusPendingCompliance || p.Status == PaymentStatusConfirming
 }
 
+// IsReversed returns true if a transfer of the payment was reorged out of the chain
+func (p *Payment) IsReversed() bool {
+	return p.Status == PaymentStatusReversed
+}
+
+// IsCanceled returns true if the merchant canceled the payment
+func (p *Payment) IsCanceled() bool {
+	return p.Status == PaymentStatusCanceled
+}
+
+// CanBeModifiedByMerchant returns true while the merchant can cancel the payment or extend its expiry:
+// nothing was received yet and the payer can still pay
+func (p *Payment) CanBeModifiedByMerchant() bool {
+	return (p.Status == PaymentStatusCreated || p.Status == PaymentStatusPending) && !p.IsExpired()
+}
+
 // CanBeConfirmed returns true if the payment can be confirmed
 func (p *Payment) CanBeConfirmed() bool {
-	return (p.Status == PaymentStatusCreated || p.Status == PaymentStatusPending || p.Status == PaymentStatusPendingCompliance) && !p.IsExpired()
+	return (p.Status == PaymentStatusCreated || p.Status == PaymentStatusPending || p.Status == PaymentStatusPendingCompliance || p.Status == PaymentStatusUnderpaid) && !p.IsExpired()
+}
+
+// RemainingAmount returns the crypto amount still owed by the payer
+func (p *Payment) RemainingAmount() decimal.Decimal {
+	remaining := p.AmountCrypto.Sub(p.AmountReceived)
+	if remaining.IsNegative() {
+		return decimal.Zero
+	}
+	return remaining
+}
+
+// ReceivedPricingAmount returns the part of the pricing amount covered by what the payer sent
+// An underpaid payment that received 60 of 100 USDT for 2,500,000 VND covered 1,500,000 VND.
+func (p *Payment) ReceivedPricingAmount() decimal.Decimal {
+	if !p.AmountCrypto.IsPositive() || p.AmountReceived.GreaterThanOrEqual(p.AmountCrypto) {
+		return p.PricingAmount
+	}
+	return p.PricingAmount.Mul(p.AmountReceived).Div(p.AmountCrypto).Round(2)
+}
+
+// SurplusAmount returns the crypto amount received above the requested amount
+func (p *Payment) SurplusAmount() decimal.Decimal {
+	surplus := p.AmountReceived.Sub(p.AmountCrypto)
+	if surplus.IsNegative() {
+		return decimal.Zero
+	}
+	return surplus
+}
+
+// ResolveAmountStatus compares the amount received so far against the requested amount
+// and returns PaymentStatusUnderpaid, PaymentStatusOverpaid or PaymentStatusCompleted.
+// Differences inside the merchant's tolerance policy are treated as an exact payment.
+func (p *Payment) ResolveAmountStatus(policy TolerancePolicy) PaymentStatus {
+	minAccepted := p.AmountCrypto.Sub(p.AmountCrypto.Mul(policy.UnderpaymentPercentage)).Sub(AmountRoundingTolerance)
+	maxAccepted := p.AmountCrypto.Add(p.AmountCrypto.Mul(policy.OverpaymentPercentage)).Add(AmountRoundingTolerance)
+
+	switch {
+	case p.AmountReceived.LessThan(minAccepted):
+		return PaymentStatusUnderpaid
+	case p.AmountReceived.GreaterThan(maxAccepted):
+		return PaymentStatusOverpaid
+	default:
+		return PaymentStatusCompleted
+	}
 }
 
 // IsPendingCompliance returns true if the payment is waiting for Travel Rule data submission
@@ -126,6 +253,17 @@ func (p *Payment) HasComplianceData() bool {
 func (p *Payment) CalculateFee() {
 	p.FeeVND = p.AmountVND.Mul(p.FeePercentage)
 	p.NetAmountVND = p.AmountVND.Sub(p.FeeVND)
+
+	p.PricingFee = p.PricingAmount.Mul(p.FeePercentage).Round(2)
+	p.PricingNetAmount = p.PricingAmount.Sub(p.PricingFee)
+}
+
+// GetPricingCurrency returns the currency the merchant priced the payment in
+func (p *Payment) GetPricingCurrency() string {
+	if p.PricingCurrency == "" {
+		return PricingCurrencyVND
+	}
+	return p.PricingCurrency
 }
 
 // GetTxHash returns the transaction hash if available
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaymentBatchStatus represents the status of a payment batch created asynchronously
+type PaymentBatchStatus string
+
+const (
+	PaymentBatchStatusPending    PaymentBatchStatus = "pending"    // Queued for the worker
+	PaymentBatchStatusProcessing PaymentBatchStatus = "processing" // The worker is creating its payments
+	PaymentBatchStatusCompleted  PaymentBatchStatus = "completed"  // Every item was created or failed
+)
+
+// PaymentBatchItemStatus represents the outcome of one payment request of a batch
+type PaymentBatchItemStatus string
+
+const (
+	PaymentBatchItemStatusPending PaymentBatchItemStatus = "pending"
+	PaymentBatchItemStatusCreated PaymentBatchItemStatus = "created"
+	PaymentBatchItemStatusFailed  PaymentBatchItemStatus = "failed"
+)
+
+// Payment batch webhook events
+const (
+	PaymentBatchEventCompleted = "batch.completed"
+)
+
+const (
+	// MaxPaymentBatchItems is the maximum number of payment requests in a batch created asynchronously
+	MaxPaymentBatchItems = 500
+	// MaxSyncPaymentBatchItems is the maximum number of payment requests in a batch created within the request
+	MaxSyncPaymentBatchItems = 100
+)
+
+// PaymentBatchItem is one payment request of a batch and its outcome
+type PaymentBatchItem struct {
+	Index int `json:"index"`
+
+	// Payment request, as accepted by CreatePayment
+	AmountVND       decimal.Decimal `json:"amount_vnd,omitempty"`
+	PricingCurrency string          `json:"pricing_currency,omitempty"`
+	PricingAmount   decimal.Decimal `json:"pricing_amount,omitempty"`
+	Currency        string          `json:"currency,omitempty"`
+	Chain           Chain           `json:"chain,omitempty"`
+	OrderID         string          `json:"order_id,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	CallbackURL     string          `json:"callback_url,omitempty"`
+	Splits          []PaymentSplit  `json:"splits,omitempty"`
+
+	// Outcome, PaymentID is set once created and Error once failed
+	Status    PaymentBatchItemStatus `json:"status"`
+	PaymentID string                 `json:"payment_id,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// PaymentBatchItems is stored as a JSONB array
+type PaymentBatchItems []PaymentBatchItem
+
+// Value implements the driver.Valuer interface for database writes
+func (items PaymentBatchItems) Value() (driver.Value, error) {
+	if items == nil {
+		return nil, nil
+	}
+	return json.Marshal(items)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (items *PaymentBatchItems) Scan(value interface{}) error {
+	if value == nil {
+		*items = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan payment batch items: not a byte slice")
+	}
+
+	return json.Unmarshal(bytes, items)
+}
+
+// PaymentBatch is a set of payment requests created by the worker, each item independently of the others
+type PaymentBatch struct {
+	ID         string             `json:"id" db:"id"`
+	MerchantID string             `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+	Status     PaymentBatchStatus `json:"status" db:"status" validate:"required,oneof=pending processing completed"`
+
+	Items        PaymentBatchItems `json:"items" db:"items" validate:"required,min=1"`
+	TotalCount   int               `json:"total_count" db:"total_count"`
+	CreatedCount int               `json:"created_count" db:"created_count"`
+	FailedCount  int               `json:"failed_count" db:"failed_count"`
+
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+	CompletedAt sql.NullTime `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+func (PaymentBatch) TableName() string {
+	return "payment_batches"
+}
+
+// IsCompleted returns true once every item of the batch was processed
+func (b *PaymentBatch) IsCompleted() bool {
+	return b.Status == PaymentBatchStatusCompleted
+}
+
+// Complete counts the outcome of the items and marks the batch completed
+func (b *PaymentBatch) Complete(now time.Time) {
+	b.CreatedCount, b.FailedCount = 0, 0
+	for _, item := range b.Items {
+		switch item.Status {
+		case PaymentBatchItemStatusCreated:
+			b.CreatedCount++
+		case PaymentBatchItemStatusFailed:
+			b.FailedCount++
+		}
+	}
+
+	b.Status = PaymentBatchStatusCompleted
+	b.CompletedAt = sql.NullTime{Time: now, Valid: true}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentBatch_Complete(t *testing.T) {
+	batch := &PaymentBatch{
+		Status: PaymentBatchStatusProcessing,
+		Items: PaymentBatchItems{
+			{Index: 0, Status: PaymentBatchItemStatusCreated, PaymentID: "payment-1"},
+			{Index: 1, Status: PaymentBatchItemStatusFailed, Error: "invalid chain"},
+			{Index: 2, Status: PaymentBatchItemStatusCreated, PaymentID: "payment-2"},
+		},
+		TotalCount:   3,
+		CreatedCount: 5, // Recounted from the items
+	}
+	assert.False(t, batch.IsCompleted())
+
+	now := time.Now()
+	batch.Complete(now)
+
+	assert.True(t, batch.IsCompleted())
+	assert.Equal(t, 2, batch.CreatedCount)
+	assert.Equal(t, 1, batch.FailedCount)
+	assert.True(t, batch.CompletedAt.Valid)
+	assert.Equal(t, now, batch.CompletedAt.Time)
+}
+
+func TestPaymentBatchItems_ValueScan(t *testing.T) {
+	items := PaymentBatchItems{{
+		Index:     0,
+		AmountVND: decimal.NewFromInt(250000),
+		Currency:  "USDT",
+		Chain:     ChainSolana,
+		OrderID:   "INV-001",
+		Status:    PaymentBatchItemStatusPending,
+	}}
+
+	value, err := items.Value()
+	require.NoError(t, err)
+
+	var scanned PaymentBatchItems
+	require.NoError(t, scanned.Scan(value))
+	require.Len(t, scanned, 1)
+	assert.True(t, scanned[0].AmountVND.Equal(decimal.NewFromInt(250000)))
+	assert.Equal(t, ChainSolana, scanned[0].Chain)
+	assert.Equal(t, "INV-001", scanned[0].OrderID)
+	assert.Equal(t, PaymentBatchItemStatusPending, scanned[0].Status)
+}
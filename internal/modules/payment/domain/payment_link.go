@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// PaymentLinkAmountType tells whether the merchant or the payer sets the amount of a payment link
+type PaymentLinkAmountType string
+
+const (
+	PaymentLinkAmountFixed PaymentLinkAmountType = "fixed" // Every payment is for the link amount
+	PaymentLinkAmountOpen  PaymentLinkAmountType = "open"  // The payer enters the amount, within the optional bounds
+)
+
+// PaymentLinkStatus represents the status of a payment link
+type PaymentLinkStatus string
+
+const (
+	PaymentLinkStatusActive   PaymentLinkStatus = "active"
+	PaymentLinkStatusInactive PaymentLinkStatus = "inactive" // Deactivated by the merchant
+)
+
+// Payment link webhook events
+const (
+	PaymentLinkEventPaymentCreated = "payment_link.payment_created"
+	PaymentLinkEventDeactivated    = "payment_link.deactivated"
+)
+
+// PaymentLink is a reusable link that creates a new payment each time a payer opens it
+type PaymentLink struct {
+	ID         string `json:"id" db:"id"`
+	MerchantID string `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+
+	// Amounts are in the pricing currency, zero bounds are not enforced
+	AmountType      PaymentLinkAmountType `json:"amount_type" db:"amount_type" validate:"required,oneof=fixed open"`
+	PricingCurrency string                `json:"pricing_currency" db:"pricing_currency" validate:"required,len=3"`
+	Amount          decimal.Decimal       `json:"amount" db:"amount"`
+	MinAmount       decimal.Decimal       `json:"min_amount" db:"min_amount"`
+	MaxAmount       decimal.Decimal       `json:"max_amount" db:"max_amount"`
+
+	// Optional: token and chain of the payments, the payer chooses when empty
+	Currency sql.NullString `json:"currency,omitempty" db:"currency"`
+	Chain    sql.NullString `json:"chain,omitempty" db:"chain"`
+
+	Description string            `json:"description" db:"description" validate:"required,max=1000"`
+	Metadata    database.JSONBMap `json:"metadata,omitempty" db:"metadata"`
+
+	MaxUses   sql.NullInt32 `json:"max_uses,omitempty" db:"max_uses"`
+	UsesCount int32         `json:"uses_count" db:"uses_count"`
+	ExpiresAt sql.NullTime  `json:"expires_at,omitempty" db:"expires_at"`
+
+	Status PaymentLinkStatus `json:"status" db:"status" validate:"required,oneof=active inactive"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (PaymentLink) TableName() string {
+	return "payment_links"
+}
+
+// Validate checks the amounts of a new payment link
+func (l *PaymentLink) Validate() error {
+	if l.MinAmount.IsNegative() || l.MaxAmount.IsNegative() {
+		return fmt.Errorf("%w: amount bounds cannot be negative", ErrInvalidAmount)
+	}
+
+	switch l.AmountType {
+	case PaymentLinkAmountFixed:
+		if l.Amount.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("%w: fixed payment links need an amount", ErrInvalidAmount)
+		}
+	case PaymentLinkAmountOpen:
+		if !l.Amount.IsZero() {
+			return fmt.Errorf("%w: open payment links cannot set an amount", ErrInvalidAmount)
+		}
+		if l.MaxAmount.IsPositive() && l.MinAmount.GreaterThan(l.MaxAmount) {
+			return fmt.Errorf("%w: min_amount is greater than max_amount", ErrInvalidAmount)
+		}
+	default:
+		return fmt.Errorf("%w: unknown amount type %q", ErrInvalidAmount, l.AmountType)
+	}
+
+	if l.MaxUses.Valid && l.MaxUses.Int32 <= 0 {
+		return fmt.Errorf("%w: max_uses must be positive", ErrInvalidAmount)
+	}
+	return nil
+}
+
+// IsExpired returns true if the link expiry has passed
+func (l *PaymentLink) IsExpired() bool {
+	return l.ExpiresAt.Valid && time.Now().After(l.ExpiresAt.Time)
+}
+
+// IsExhausted returns true if the link created its maximum number of payments
+func (l *PaymentLink) IsExhausted() bool {
+	return l.MaxUses.Valid && l.UsesCount >= l.MaxUses.Int32
+}
+
+// CheckUsable returns why a payer cannot open the link, nil if they can
+func (l *PaymentLink) CheckUsable() error {
+	switch {
+	case l.Status != PaymentLinkStatusActive:
+		return ErrPaymentLinkInactive
+	case l.IsExpired():
+		return ErrPaymentLinkExpired
+	case l.IsExhausted():
+		return ErrPaymentLinkExhausted
+	}
+	return nil
+}
+
+// ResolveAmount returns the amount of a payment opened from the link
+// Fixed links ignore a zero requested amount, open links require one within the bounds.
+func (l *PaymentLink) ResolveAmount(requested decimal.Decimal) (decimal.Decimal, error) {
+	if l.AmountType == PaymentLinkAmountFixed {
+		if !requested.IsZero() && !requested.Equal(l.Amount) {
+			return decimal.Zero, fmt.Errorf("%w: the link amount is fixed at %s %s", ErrInvalidAmount, l.Amount, l.PricingCurrency)
+		}
+		return l.Amount, nil
+	}
+
+	if requested.LessThanOrEqual(decimal.Zero) {
+		return decimal.Zero, fmt.Errorf("%w: enter the amount to pay", ErrInvalidAmount)
+	}
+	if l.MinAmount.IsPositive() && requested.LessThan(l.MinAmount) {
+		return decimal.Zero, fmt.Errorf("%w: the minimum amount is %s %s", ErrInvalidAmount, l.MinAmount, l.PricingCurrency)
+	}
+	if l.MaxAmount.IsPositive() && requested.GreaterThan(l.MaxAmount) {
+		return decimal.Zero, fmt.Errorf("%w: the maximum amount is %s %s", ErrInvalidAmount, l.MaxAmount, l.PricingCurrency)
+	}
+	return requested, nil
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentLink_Validate(t *testing.T) {
+	fixed := &PaymentLink{AmountType: PaymentLinkAmountFixed, Amount: decimal.NewFromInt(150000)}
+	assert.NoError(t, fixed.Validate())
+
+	fixed.Amount = decimal.Zero
+	assert.ErrorIs(t, fixed.Validate(), ErrInvalidAmount)
+
+	open := &PaymentLink{AmountType: PaymentLinkAmountOpen, MinAmount: decimal.NewFromInt(10), MaxAmount: decimal.NewFromInt(500)}
+	assert.NoError(t, open.Validate())
+
+	open.Amount = decimal.NewFromInt(20)
+	assert.ErrorIs(t, open.Validate(), ErrInvalidAmount)
+
+	open.Amount = decimal.Zero
+	open.MinAmount = decimal.NewFromInt(1000)
+	assert.ErrorIs(t, open.Validate(), ErrInvalidAmount)
+
+	open.MinAmount = decimal.Zero
+	open.MaxUses = sql.NullInt32{Int32: 0, Valid: true}
+	assert.ErrorIs(t, open.Validate(), ErrInvalidAmount)
+
+	assert.ErrorIs(t, (&PaymentLink{AmountType: "tiered"}).Validate(), ErrInvalidAmount)
+}
+
+func TestPaymentLink_CheckUsable(t *testing.T) {
+	link := &PaymentLink{Status: PaymentLinkStatusActive, UsesCount: 2}
+	assert.NoError(t, link.CheckUsable())
+
+	link.MaxUses = sql.NullInt32{Int32: 2, Valid: true}
+	assert.ErrorIs(t, link.CheckUsable(), ErrPaymentLinkExhausted)
+
+	link.MaxUses = sql.NullInt32{}
+	link.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	assert.ErrorIs(t, link.CheckUsable(), ErrPaymentLinkExpired)
+
+	link.Status = PaymentLinkStatusInactive
+	assert.ErrorIs(t, link.CheckUsable(), ErrPaymentLinkInactive)
+}
+
+func TestPaymentLink_ResolveAmount(t *testing.T) {
+	fixed := &PaymentLink{AmountType: PaymentLinkAmountFixed, PricingCurrency: "USD", Amount: decimal.RequireFromString("49.99")}
+
+	amount, err := fixed.ResolveAmount(decimal.Zero)
+	require.NoError(t, err)
+	assert.True(t, amount.Equal(decimal.RequireFromString("49.99")))
+
+	_, err = fixed.ResolveAmount(decimal.NewFromInt(10))
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	open := &PaymentLink{AmountType: PaymentLinkAmountOpen, PricingCurrency: "USD", MinAmount: decimal.NewFromInt(5)}
+
+	amount, err = open.ResolveAmount(decimal.NewFromInt(1000))
+	require.NoError(t, err)
+	assert.True(t, amount.Equal(decimal.NewFromInt(1000)))
+
+	_, err = open.ResolveAmount(decimal.Zero)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = open.ResolveAmount(decimal.NewFromInt(2))
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	open.MaxAmount = decimal.NewFromInt(100)
+	_, err = open.ResolveAmount(decimal.NewFromInt(1000))
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Page size limits of a payment search
+const (
+	DefaultPaymentSearchLimit = 20
+	MaxPaymentSearchLimit     = 100
+)
+
+// PaymentSortField is a column payments can be sorted by in a search
+type PaymentSortField string
+
+const (
+	PaymentSortCreatedAt PaymentSortField = "created_at"
+	PaymentSortAmountVND PaymentSortField = "amount_vnd"
+)
+
+// IsValid returns true if payments can be sorted by the field
+func (f PaymentSortField) IsValid() bool {
+	return f == PaymentSortCreatedAt || f == PaymentSortAmountVND
+}
+
+// ParsePaymentStatuses parses a comma-separated list of payment statuses, nil for an empty list
+func ParsePaymentStatuses(list string) ([]PaymentStatus, error) {
+	var statuses []PaymentStatus
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		status := PaymentStatus(value)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidPaymentSearch, value)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// PaymentFilter selects the payments of a search. Empty fields, zero dates and zero amounts are not applied.
+type PaymentFilter struct {
+	MerchantID string // Empty searches the payments of every merchant (admin)
+
+	Statuses []PaymentStatus
+	Chain    Chain
+	Currency string
+
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	ConfirmedFrom time.Time
+	ConfirmedTo   time.Time
+
+	OrderID     string
+	TxHash      string
+	FromAddress string
+
+	MinAmountVND decimal.Decimal
+	MaxAmountVND decimal.Decimal
+
+	// Amount range in the currency the merchant priced the payment in, applied with PricingCurrency only
+	PricingCurrency  string
+	MinPricingAmount decimal.Decimal
+	MaxPricingAmount decimal.Decimal
+
+	// Payments whose metadata holds every key with the given value
+	Metadata map[string]string
+}
+
+// PaymentSearch is a page of a filtered and sorted payment search
+type PaymentSearch struct {
+	Filter     PaymentFilter
+	SortBy     PaymentSortField
+	Descending bool
+	Cursor     string // Empty for the first page, the NextCursor of the previous page otherwise
+	Limit      int
+}
+
+// PaymentPage is a page of payments found by a search
+type PaymentPage struct {
+	Payments   []*Payment
+	NextCursor string // Empty on the last page
+}
+
+// PaymentCursor is the position after the last payment of a page: its sort value and ID
+type PaymentCursor struct {
+	SortBy PaymentSortField `json:"s"`
+	Value  string           `json:"v"`
+	ID     string           `json:"id"`
+}
+
+// NewPaymentCursor returns the cursor positioned after the payment in the given sort order
+func NewPaymentCursor(payment *Payment, sortBy PaymentSortField) PaymentCursor {
+	cursor := PaymentCursor{SortBy: sortBy, ID: payment.ID}
+	switch sortBy {
+	case PaymentSortAmountVND:
+		cursor.Value = payment.AmountVND.String()
+	default:
+		cursor.Value = payment.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return cursor
+}
+
+// Encode returns the opaque cursor string handed to API clients
+func (c PaymentCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// SortValue returns the cursor value as the type of the sort column
+func (c PaymentCursor) SortValue() (interface{}, error) {
+	switch c.SortBy {
+	case PaymentSortCreatedAt:
+		value, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentCursor, err)
+		}
+		return value, nil
+	case PaymentSortAmountVND:
+		value, err := decimal.NewFromString(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentCursor, err)
+		}
+		return value, nil
+	default:
+		return nil, ErrInvalidPaymentCursor
+	}
+}
+
+// DecodePaymentCursor parses a cursor string, which must have been issued for the same sort field
+func DecodePaymentCursor(encoded string, sortBy PaymentSortField) (PaymentCursor, error) {
+	var cursor PaymentCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidPaymentCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, ErrInvalidPaymentCursor
+	}
+	if cursor.SortBy != sortBy || cursor.ID == "" {
+		return cursor, ErrInvalidPaymentCursor
+	}
+	if _, err := cursor.SortValue(); err != nil {
+		return cursor, err
+	}
+
+	return cursor, nil
+}
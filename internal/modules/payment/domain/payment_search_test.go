@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentCursor_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 3, 14, 9, 26, 53, 589793000, time.FixedZone("ICT", 7*60*60))
+	payment := &Payment{
+		ID:        "payment-1",
+		AmountVND: decimal.RequireFromString("2500000.50"),
+		CreatedAt: createdAt,
+	}
+
+	t.Run("created at", func(t *testing.T) {
+		encoded := NewPaymentCursor(payment, PaymentSortCreatedAt).Encode()
+
+		cursor, err := DecodePaymentCursor(encoded, PaymentSortCreatedAt)
+		require.NoError(t, err)
+		assert.Equal(t, "payment-1", cursor.ID)
+
+		value, err := cursor.SortValue()
+		require.NoError(t, err)
+		require.IsType(t, time.Time{}, value)
+		assert.True(t, createdAt.Equal(value.(time.Time)))
+	})
+
+	t.Run("amount", func(t *testing.T) {
+		encoded := NewPaymentCursor(payment, PaymentSortAmountVND).Encode()
+
+		cursor, err := DecodePaymentCursor(encoded, PaymentSortAmountVND)
+		require.NoError(t, err)
+
+		value, err := cursor.SortValue()
+		require.NoError(t, err)
+		require.IsType(t, decimal.Decimal{}, value)
+		assert.True(t, payment.AmountVND.Equal(value.(decimal.Decimal)))
+	})
+}
+
+func TestDecodePaymentCursor_Invalid(t *testing.T) {
+	payment := &Payment{ID: "payment-1", CreatedAt: time.Now()}
+
+	tests := []struct {
+		name    string
+		encoded string
+		sortBy  PaymentSortField
+	}{
+		{"other sort field", NewPaymentCursor(payment, PaymentSortCreatedAt).Encode(), PaymentSortAmountVND},
+		{"not base64", "not a cursor!", PaymentSortCreatedAt},
+		{"not json", "bm90IGpzb24", PaymentSortCreatedAt},
+		{"missing id", PaymentCursor{SortBy: PaymentSortCreatedAt, Value: time.Now().Format(time.RFC3339Nano)}.Encode(), PaymentSortCreatedAt},
+		{"bad value", PaymentCursor{SortBy: PaymentSortAmountVND, Value: "abc", ID: "payment-1"}.Encode(), PaymentSortAmountVND},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePaymentCursor(tt.encoded, tt.sortBy)
+			assert.ErrorIs(t, err, ErrInvalidPaymentCursor)
+		})
+	}
+}
+
+func TestParsePaymentStatuses(t *testing.T) {
+	statuses, err := ParsePaymentStatuses("completed, late_paid,,expired")
+	require.NoError(t, err)
+	assert.Equal(t, []PaymentStatus{PaymentStatusCompleted, PaymentStatusLatePaid, PaymentStatusExpired}, statuses)
+
+	statuses, err = ParsePaymentStatuses("")
+	require.NoError(t, err)
+	assert.Nil(t, statuses)
+
+	_, err = ParsePaymentStatuses("completed,settled")
+	assert.ErrorIs(t, err, ErrInvalidPaymentSearch)
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayment_ResolveAmountStatus(t *testing.T) {
+	onePercent := TolerancePolicy{
+		UnderpaymentPercentage: decimal.NewFromFloat(0.01),
+		OverpaymentPercentage:  decimal.NewFromFloat(0.01),
+	}
+
+	tests := []struct {
+		name     string
+		received string
+		policy   TolerancePolicy
+		expected PaymentStatus
+	}{
+		{"exact amount", "100", DefaultTolerancePolicy(), PaymentStatusCompleted},
+		{"within rounding tolerance", "99.9999995", DefaultTolerancePolicy(), PaymentStatusCompleted},
+		{"underpaid without tolerance", "99.5", DefaultTolerancePolicy(), PaymentStatusUnderpaid},
+		{"overpaid without tolerance", "100.5", DefaultTolerancePolicy(), PaymentStatusOverpaid},
+		{"underpaid within merchant tolerance", "99.5", onePercent, PaymentStatusCompleted},
+		{"overpaid within merchant tolerance", "100.5", onePercent, PaymentStatusCompleted},
+		{"underpaid beyond merchant tolerance", "98", onePercent, PaymentStatusUnderpaid},
+		{"overpaid beyond merchant tolerance", "102", onePercent, PaymentStatusOverpaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{
+				AmountCrypto:   decimal.NewFromInt(100),
+				AmountReceived: decimal.RequireFromString(tt.received),
+			}
+			assert.Equal(t, tt.expected, payment.ResolveAmountStatus(tt.policy))
+		})
+	}
+}
+
+func TestPayment_RemainingAndSurplusAmount(t *testing.T) {
+	payment := &Payment{
+		AmountCrypto:   decimal.NewFromInt(100),
+		AmountReceived: decimal.NewFromInt(60),
+	}
+	assert.True(t, payment.RemainingAmount().Equal(decimal.NewFromInt(40)))
+	assert.True(t, payment.SurplusAmount().IsZero())
+
+	payment.AmountReceived = decimal.NewFromInt(105)
+	assert.True(t, payment.RemainingAmount().IsZero())
+	assert.True(t, payment.SurplusAmount().Equal(decimal.NewFromInt(5)))
+}
+
+func TestPayment_ReceivedPricingAmount(t *testing.T) {
+	payment := &Payment{
+		PricingAmount:  decimal.NewFromInt(2500000),
+		AmountCrypto:   decimal.NewFromInt(100),
+		AmountReceived: decimal.NewFromInt(60),
+	}
+	assert.True(t, payment.ReceivedPricingAmount().Equal(decimal.NewFromInt(1500000)))
+
+	payment.AmountReceived = decimal.Zero
+	assert.True(t, payment.ReceivedPricingAmount().IsZero())
+
+	payment.AmountReceived = decimal.NewFromInt(105)
+	assert.True(t, payment.ReceivedPricingAmount().Equal(decimal.NewFromInt(2500000)))
+}
+
+func TestPayment_AcceptsLatePayment(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		status    PaymentStatus
+		expiresAt time.Time
+		expected  bool
+	}{
+		{"pending before expiry", PaymentStatusPending, future, false},
+		{"pending after expiry", PaymentStatusPending, past, true},
+		{"underpaid after expiry", PaymentStatusUnderpaid, past, true},
+		{"expired", PaymentStatusExpired, past, true},
+		{"already late paid", PaymentStatusLatePaid, past, true},
+		{"canceled before expiry", PaymentStatusCanceled, future, true},
+		{"completed after expiry", PaymentStatusCompleted, past, false},
+		{"failed after expiry", PaymentStatusFailed, past, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{Status: tt.status, ExpiresAt: tt.expiresAt}
+			assert.Equal(t, tt.expected, payment.AcceptsLatePayment())
+		})
+	}
+}
+
+func TestPayment_CanBeModifiedByMerchant(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		status    PaymentStatus
+		expiresAt time.Time
+		expected  bool
+	}{
+		{"created", PaymentStatusCreated, future, true},
+		{"pending", PaymentStatusPending, future, true},
+		{"pending after expiry", PaymentStatusPending, past, false},
+		{"underpaid", PaymentStatusUnderpaid, future, false},
+		{"confirming", PaymentStatusConfirming, future, false},
+		{"completed", PaymentStatusCompleted, future, false},
+		{"already canceled", PaymentStatusCanceled, future, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{Status: tt.status, ExpiresAt: tt.expiresAt}
+			assert.Equal(t, tt.expected, payment.CanBeModifiedByMerchant())
+		})
+	}
+}
+
+func TestPayment_CalculateFee(t *testing.T) {
+	payment := &Payment{
+		AmountVND:       decimal.NewFromInt(2700000),
+		PricingCurrency: "USD",
+		PricingAmount:   decimal.RequireFromString("104.99"),
+		PricingRate:     decimal.RequireFromString("25716.74"),
+		FeePercentage:   decimal.NewFromFloat(0.01),
+	}
+	payment.CalculateFee()
+
+	assert.True(t, payment.FeeVND.Equal(decimal.NewFromInt(27000)))
+	assert.True(t, payment.NetAmountVND.Equal(decimal.NewFromInt(2673000)))
+	assert.True(t, payment.PricingFee.Equal(decimal.RequireFromString("1.05")))
+	assert.True(t, payment.PricingNetAmount.Equal(decimal.RequireFromString("103.94")))
+	assert.Equal(t, "USD", payment.GetPricingCurrency())
+
+	assert.Equal(t, PricingCurrencyVND, (&Payment{}).GetPricingCurrency())
+}
+
+func TestPricingCurrency(t *testing.T) {
+	assert.Equal(t, "VND", NormalizePricingCurrency(""))
+	assert.Equal(t, "EUR", NormalizePricingCurrency(" eur "))
+
+	assert.True(t, IsSupportedPricingCurrency("USD"))
+	assert.True(t, IsSupportedPricingCurrency("VND"))
+	assert.False(t, IsSupportedPricingCurrency("usd"))
+	assert.False(t, IsSupportedPricingCurrency("XYZ"))
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "strings"
+
+// PricingCurrencyVND is the pricing currency of payments that do not set one
+const PricingCurrencyVND = "VND"
+
+// SupportedPricingCurrencies lists the fiat currencies merchants may price payments in (ISO 4217)
+var SupportedPricingCurrencies = []string{"VND", "USD", "EUR", "GBP", "JPY", "SGD", "AUD"}
+
+// NormalizePricingCurrency returns the ISO 4217 code of a pricing currency, VND when empty
+func NormalizePricingCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return PricingCurrencyVND
+	}
+	return currency
+}
+
+// IsSupportedPricingCurrency returns true if payments can be priced in the currency
+func IsSupportedPricingCurrency(currency string) bool {
+	for _, supported := range SupportedPricingCurrencies {
+		if supported == currency {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxQuoteSpreadPercentage caps the spread a merchant may deduct from the market rate on quotes
+var MaxQuoteSpreadPercentage = decimal.NewFromFloat(0.05)
+
+// QuoteOption is the locked rate and crypto amount of a quote on one chain and token
+type QuoteOption struct {
+	Chain        Chain           `json:"chain"`
+	Currency     string          `json:"currency"`
+	MarketRate   decimal.Decimal `json:"market_rate"`   // VND per token from the rate source
+	ExchangeRate decimal.Decimal `json:"exchange_rate"` // Market rate less the merchant spread
+	AmountCrypto decimal.Decimal `json:"amount_crypto"`
+}
+
+// QuoteOptions is stored as a JSONB array
+type QuoteOptions []QuoteOption
+
+// Value implements the driver.Valuer interface for database writes
+func (o QuoteOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (o *QuoteOptions) Scan(value interface{}) error {
+	if value == nil {
+		*o = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan quote options: not a byte slice")
+	}
+
+	return json.Unmarshal(bytes, o)
+}
+
+// Quote locks the exchange rate for an amount in VND until ValidUntil
+// The terms are signed so merchants and payers can check a quote was issued by the gateway.
+type Quote struct {
+	ID         string `json:"id" db:"id"`
+	MerchantID string `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+
+	AmountVND        decimal.Decimal `json:"amount_vnd" db:"amount_vnd" validate:"required,gt=0"`
+	SpreadPercentage decimal.Decimal `json:"spread_percentage" db:"spread_percentage" validate:"gte=0,lte=0.05"`
+	RateSource       string          `json:"rate_source" db:"rate_source" validate:"required"`
+	Options          QuoteOptions    `json:"options" db:"options" validate:"required,min=1"`
+
+	Signature  string    `json:"signature" db:"signature"`
+	ValidUntil time.Time `json:"valid_until" db:"valid_until"`
+
+	// Payment created from the quote, a quote backs a single payment
+	PaymentID sql.NullString `json:"payment_id,omitempty" db:"payment_id"`
+	UsedAt    sql.NullTime   `json:"used_at,omitempty" db:"used_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (Quote) TableName() string {
+	return "payment_quotes"
+}
+
+// IsExpired returns true if the locked rate can no longer be used
+func (q *Quote) IsExpired() bool {
+	return time.Now().After(q.ValidUntil)
+}
+
+// IsUsed returns true if a payment was already created from the quote
+func (q *Quote) IsUsed() bool {
+	return q.UsedAt.Valid
+}
+
+// OptionFor returns the locked terms for the chain and token, nil if the quote does not cover them
+func (q *Quote) OptionFor(chain Chain, currency string) *QuoteOption {
+	for i := range q.Options {
+		if q.Options[i].Chain == chain && q.Options[i].Currency == currency {
+			return &q.Options[i]
+		}
+	}
+	return nil
+}
+
+// SigningPayload returns the canonical form of the quote terms covered by the signature
+func (q *Quote) SigningPayload() string {
+	parts := []string{
+		q.ID,
+		q.MerchantID,
+		q.AmountVND.String(),
+		q.SpreadPercentage.String(),
+		q.RateSource,
+		fmt.Sprintf("%d", q.ValidUntil.Unix()),
+	}
+	for _, option := range q.Options {
+		parts = append(parts, fmt.Sprintf("%s:%s:%s:%s", option.Chain, option.Currency, option.ExchangeRate.String(), option.AmountCrypto.String()))
+	}
+	return strings.Join(parts, "|")
+}
+
+// ApplySpread returns the rate a quote locks for a market rate and spread, e.g. 25000 less 0.5% = 24875
+func ApplySpread(marketRate, spread decimal.Decimal) decimal.Decimal {
+	return marketRate.Mul(decimal.NewFromInt(1).Sub(spread)).Round(2)
+}
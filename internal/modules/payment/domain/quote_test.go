@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySpread(t *testing.T) {
+	assert.True(t, ApplySpread(decimal.NewFromInt(25000), decimal.NewFromFloat(0.005)).Equal(decimal.NewFromInt(24875)))
+	assert.True(t, ApplySpread(decimal.NewFromInt(25000), decimal.Zero).Equal(decimal.NewFromInt(25000)))
+	assert.True(t, ApplySpread(decimal.RequireFromString("25432.17"), decimal.NewFromFloat(0.01)).Equal(decimal.RequireFromString("25177.85")))
+}
+
+func TestQuote_OptionFor(t *testing.T) {
+	quote := &Quote{Options: QuoteOptions{
+		{Chain: ChainSolana, Currency: "USDT"},
+		{Chain: ChainBSC, Currency: "USDT"},
+	}}
+
+	option := quote.OptionFor(ChainBSC, "USDT")
+	require.NotNil(t, option)
+	assert.Equal(t, ChainBSC, option.Chain)
+
+	assert.Nil(t, quote.OptionFor(Chain("tron"), "USDT"))
+	assert.Nil(t, quote.OptionFor(ChainSolana, "USDC"))
+}
+
+func TestQuote_IsExpired(t *testing.T) {
+	quote := &Quote{ValidUntil: time.Now().Add(-time.Second)}
+	assert.True(t, quote.IsExpired())
+
+	quote.ValidUntil = time.Now().Add(time.Minute)
+	assert.False(t, quote.IsExpired())
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RefundStatus represents the status of an on-chain refund
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"   // Balance reserved, waiting to be sent on-chain
+	RefundStatusSending   RefundStatus = "sending"   // Claimed by a worker, transaction signed and being broadcast
+	RefundStatusSubmitted RefundStatus = "submitted" // Transaction broadcast, waiting for finality
+	RefundStatusCompleted RefundStatus = "completed" // Transaction finalized
+	RefundStatusFailed    RefundStatus = "failed"    // Sending failed, reserved balance released
+	RefundStatusDropped   RefundStatus = "dropped"   // Transaction never found on-chain, left for an operator with the balance reserved
+)
+
+// RefundSource identifies what funds a refund
+type RefundSource string
+
+const (
+	RefundSourceMerchant    RefundSource = "merchant"     // Debited from the merchant balance
+	RefundSourceLatePayment RefundSource = "late_payment" // Returns a late transfer that was never credited to the merchant
+)
+
+// Refund webhook events
+const (
+	RefundEventCreated   = "refund.created"
+	RefundEventSubmitted = "refund.submitted"
+	RefundEventCompleted = "refund.completed"
+	RefundEventFailed    = "refund.failed"
+)
+
+// Refund represents crypto sent back to the payer of a completed payment
+type Refund struct {
+	ID         string `json:"id" db:"id"`
+	PaymentID  string `json:"payment_id" db:"payment_id" validate:"required,uuid"`
+	MerchantID string `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+
+	// Refund amount (crypto is sent, VND is debited from the merchant balance)
+	AmountCrypto decimal.Decimal `json:"amount_crypto" db:"amount_crypto" validate:"required,gt=0"`
+	AmountVND    decimal.Decimal `json:"amount_vnd" db:"amount_vnd" validate:"required,gt=0"`
+	Currency     string          `json:"currency" db:"currency" validate:"required,max=10"`
+	Chain        Chain           `json:"chain" db:"chain" validate:"required,oneof=solana bsc ethereum tron"`
+	ToAddress    string          `json:"to_address" db:"to_address" validate:"required"`
+	Reason       sql.NullString  `json:"reason,omitempty" db:"reason"`
+	Source       RefundSource    `json:"source" db:"source" validate:"required,oneof=merchant late_payment"`
+
+	// Status
+	Status RefundStatus `json:"status" db:"status" validate:"required,oneof=pending sending submitted completed failed dropped"`
+
+	// Blockchain transaction details
+	TxHash        sql.NullString `json:"tx_hash,omitempty" db:"tx_hash"`
+	FailureReason sql.NullString `json:"failure_reason,omitempty" db:"failure_reason"`
+
+	// Timing
+	SubmittedAt sql.NullTime `json:"submitted_at,omitempty" db:"submitted_at"`
+	CompletedAt sql.NullTime `json:"completed_at,omitempty" db:"completed_at"`
+	FailedAt    sql.NullTime `json:"failed_at,omitempty" db:"failed_at"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// IsFinal returns true if the refund will not change status anymore
+func (r *Refund) IsFinal() bool {
+	return r.Status == RefundStatusCompleted || r.Status == RefundStatusFailed
+}
+
+// CountsTowardPayment returns true if the refund amount is (or will be) deducted from the payment
+func (r *Refund) CountsTowardPayment() bool {
+	return r.Status != RefundStatusFailed
+}
+
+// SignedRefundTx is a refund transaction signed by a platform wallet that is not broadcast yet
+type SignedRefundTx struct {
+	Chain  Chain
+	TxHash string
+	Tx     interface{} // Chain-specific transaction, only read by the RefundSender that signed it
+}
+
+// IsLatePayment returns true if the refund returns a late payment instead of debiting the merchant balance
+func (r *Refund) IsLatePayment() bool {
+	return r.Source == RefundSourceLatePayment
+}
@@ -13,20 +13,197 @@ type PaymentRepository interface {
 	GetByID(id string) (*Payment, error)
 	GetByTxHash(txHash string) (*Payment, error)
 	GetByPaymentReference(reference string) (*Payment, error)
-	UpdateStatus(id string, status PaymentStatus) error
+	// Update saves the payment except its status, ErrPaymentVersionConflict if it changed since it was read
 	Update(payment *Payment) error
+	// Transition saves the payment with its new status and records the transition in its status history,
+	// ErrPaymentVersionConflict if the payment changed since it was read
+	Transition(payment *Payment, transition *PaymentStatusTransition) error
+	// ApplyTransfer saves the payment, records the transition unless it is nil and creates the transfer
+	// in one transaction, ErrPaymentVersionConflict if the payment changed since it was read
+	ApplyTransfer(payment *Payment, transition *PaymentStatusTransition, transfer *PaymentTransfer) error
+	ListStatusHistory(paymentID string) ([]*PaymentStatusTransition, error)
 	ListByMerchant(merchantID string, limit, offset int) ([]*Payment, error)
+	// ListBySubscriptionCycle lists the payments issued for a subscription billing cycle, oldest first
+	ListBySubscriptionCycle(cycleID string) ([]*Payment, error)
+	// Search returns a page of the payments matching the filter, sorted with keyset (cursor) pagination
+	Search(search PaymentSearch) (*PaymentPage, error)
 	GetExpiredPayments() ([]*Payment, error)
 	GetComplianceExpiredPayments() ([]*Payment, error)
 	ListByStatus(status PaymentStatus, limit, offset int) ([]*Payment, error)
+	// ListLatePayments lists payments paid after expiry, all of them when resolution is empty
+	ListLatePayments(resolution LatePaymentResolution, limit, offset int) ([]*Payment, error)
+	CountLatePayments(resolution LatePaymentResolution) (int64, error)
 	Count() (int64, error)
 	CountByMerchant(merchantID string) (int64, error)
 	CountByStatus(status PaymentStatus) (int64, error)
 	GetTotalVolumeByMerchant(merchantID string) (decimal.Decimal, error)
 	CountByAddressAndWindow(fromAddress string, window time.Duration) (int64, error)
+	// SetSolanaPayReference stores the Solana Pay reference key of a payment, a stored key is kept
+	SetSolanaPayReference(paymentID, reference string) error
+	// ListSolanaPayPayments lists the payments with a Solana Pay reference that can still receive a transfer:
+	// created, pending or underpaid, or expired after expiredAfter
+	ListSolanaPayPayments(expiredAfter time.Time) ([]*Payment, error)
 	Delete(id string) error
 }
 
+// PaymentTransferRepository defines the interface for the transfers counted toward a payment
+type PaymentTransferRepository interface {
+	Create(transfer *PaymentTransfer) error
+	GetByTxHash(chain Chain, txHash string) (*PaymentTransfer, error)
+	ListByPayment(paymentID string) ([]*PaymentTransfer, error)
+	Update(transfer *PaymentTransfer) error
+	// ListUnfinalized returns transfers not yet finalized nor reversed that were created after since,
+	// plus those of payments still confirming regardless of age
+	ListUnfinalized(since time.Time, limit int) ([]*PaymentTransfer, error)
+}
+
+// RefundRepository defines the interface for refund data access
+type RefundRepository interface {
+	Create(refund *Refund) error
+	// CreateWithinLimit creates the refund only if the payment's refunds, failed ones excluded, stay within limit
+	// The check and the insert run under a lock of the payment row. Returns ErrRefundAmountExceeded otherwise
+	CreateWithinLimit(refund *Refund, limit decimal.Decimal) error
+	GetByID(id string) (*Refund, error)
+	Update(refund *Refund) error
+	ListByPayment(paymentID string) ([]*Refund, error)
+	ListByStatus(status RefundStatus, limit int) ([]*Refund, error)
+	GetTotalRefundedByPayment(paymentID string) (decimal.Decimal, error)
+	// ClaimForSending moves a pending refund to sending
+	// Returns false if the refund was no longer pending, e.g. claimed by another worker
+	ClaimForSending(refundID string) (bool, error)
+}
+
+// InboundTransferRepository defines the interface for the transfers received on watched addresses
+type InboundTransferRepository interface {
+	// Record stores a transfer seen by a listener. A transfer seen again (e.g. by a rescan) only
+	// updates a record that is still unmatched, so operator resolutions are never overwritten
+	Record(transfer *InboundTransfer) error
+	GetByID(id string) (*InboundTransfer, error)
+	// List lists transfers by status, all of them when status is empty
+	List(status InboundTransferStatus, limit, offset int) ([]*InboundTransfer, error)
+	Count(status InboundTransferStatus) (int64, error)
+	// Resolve saves an operator resolution, ErrInboundTransferNotUnmatched if the transfer left the queue
+	Resolve(transfer *InboundTransfer) error
+	// MarkRefunded saves the refund of a transfer, ErrInboundTransferNotRefundPending if it was not marked for refund
+	MarkRefunded(transfer *InboundTransfer) error
+}
+
+// QuoteRepository defines the interface for rate-locked quote data access
+type QuoteRepository interface {
+	Create(quote *Quote) error
+	GetByID(id string) (*Quote, error)
+	// MarkUsed links the quote to the payment created from it, ErrQuoteAlreadyUsed if another payment was
+	MarkUsed(id, paymentID string) error
+}
+
+// OwnershipChallengeRepository defines the interface for EVM ownership challenge data access
+type OwnershipChallengeRepository interface {
+	Create(challenge *OwnershipChallenge) error
+	// ClaimChallengeNonce marks an unexpired challenge of the wallet on chain used
+	// Returns ErrOwnershipChallengeRejected if it was not issued, has expired or was already used
+	ClaimChallengeNonce(chain, address, nonce string) error
+}
+
+// PaymentLinkRepository defines the interface for payment link data access
+type PaymentLinkRepository interface {
+	Create(link *PaymentLink) error
+	GetByID(id string) (*PaymentLink, error)
+	ListByMerchant(merchantID string, limit, offset int) ([]*PaymentLink, error)
+	CountByMerchant(merchantID string) (int64, error)
+	Update(link *PaymentLink) error
+	// ReserveUse counts a payment against the link, ErrPaymentLinkExhausted if max_uses was reached
+	ReserveUse(id string) error
+	// ReleaseUse gives back a use reserved for a payment that could not be created or ended unpaid
+	ReleaseUse(id string) error
+}
+
+// PaymentBatchRepository defines the interface for payment batch data access
+type PaymentBatchRepository interface {
+	Create(batch *PaymentBatch) error
+	GetByID(id string) (*PaymentBatch, error)
+	Update(batch *PaymentBatch) error
+	// UpdateItem stores the outcome of one item without rewriting the others
+	UpdateItem(batchID string, item *PaymentBatchItem) error
+}
+
+// InvoiceRepository defines the interface for invoice data access
+type InvoiceRepository interface {
+	Create(invoice *Invoice) error
+	GetByID(id string) (*Invoice, error)
+	// ListByMerchant lists the invoices of a merchant by status, all of them when status is empty
+	ListByMerchant(merchantID string, status InvoiceStatus, limit, offset int) ([]*Invoice, error)
+	CountByMerchant(merchantID string, status InvoiceStatus) (int64, error)
+	Update(invoice *Invoice) error
+	// ApplyPayment adds amount to amount_paid under a row lock and returns the updated invoice
+	// and whether its status changed, see Invoice.ApplyPayment
+	ApplyPayment(id string, amount decimal.Decimal) (*Invoice, bool, error)
+}
+
+// SubscriptionPlanRepository defines the interface for subscription plan data access
+type SubscriptionPlanRepository interface {
+	Create(plan *SubscriptionPlan) error
+	GetByID(id string) (*SubscriptionPlan, error)
+	ListByMerchant(merchantID string, limit, offset int) ([]*SubscriptionPlan, error)
+	CountByMerchant(merchantID string) (int64, error)
+	Update(plan *SubscriptionPlan) error
+}
+
+// SubscriptionRepository defines the interface for subscription and billing cycle data access
+type SubscriptionRepository interface {
+	Create(subscription *Subscription) error
+	GetByID(id string) (*Subscription, error)
+	// ListByMerchant lists the subscriptions of a merchant by status, all of them when status is empty
+	ListByMerchant(merchantID string, status SubscriptionStatus, limit, offset int) ([]*Subscription, error)
+	CountByMerchant(merchantID string, status SubscriptionStatus) (int64, error)
+	Update(subscription *Subscription) error
+	// ListDueForBilling returns billable subscriptions whose next cycle is due and that have no open cycle
+	ListDueForBilling(now time.Time, limit int) ([]*Subscription, error)
+
+	// IssueCycle saves a new cycle and the subscription moved to its period in one transaction
+	// Returns ErrSubscriptionCycleExists if the cycle was already issued
+	IssueCycle(subscription *Subscription, cycle *SubscriptionCycle) error
+	GetCycle(id string) (*SubscriptionCycle, error)
+	ListCycles(subscriptionID string) ([]*SubscriptionCycle, error)
+	// UpdateCycle updates an open cycle, returns ErrSubscriptionCycleNotOpen if it was paid or closed meanwhile
+	UpdateCycle(cycle *SubscriptionCycle) error
+	// ListCyclesDueForAttempt returns open cycles whose next attempt is due
+	ListCyclesDueForAttempt(now time.Time, limit int) ([]*SubscriptionCycle, error)
+	// MarkCyclePaid marks an open cycle paid under a row lock and reactivates a past due subscription
+	// Returns false if the cycle was no longer open, e.g. paid by an earlier attempt
+	MarkCyclePaid(cycleID, paymentID string) (*SubscriptionCycle, *Subscription, bool, error)
+	// ReopenCycle reopens a cycle whose payment was reversed and puts a billable subscription past due
+	// Returns false if the cycle was not paid by the payment
+	ReopenCycle(cycleID, paymentID string) (*SubscriptionCycle, *Subscription, bool, error)
+}
+
+// DepositAddressRepository defines the interface for per-payment deposit address data access
+type DepositAddressRepository interface {
+	Create(address *DepositAddress) error
+	Update(address *DepositAddress) error
+	GetByPaymentID(paymentID string) (*DepositAddress, error)
+	// ListWatchedByChain returns the deposit addresses the listeners watch: active and sweeping ones,
+	// and swept ones for DepositAddressWatchRetention after their sweep
+	ListWatchedByChain(chain Chain) ([]*DepositAddress, error)
+	// ListSweepable returns the deposit addresses to sweep: active ones whose payment no longer accepts
+	// transfers, and swept ones that received a transfer after their sweep. Payments with a transfer
+	// recorded after finalizedBefore that is not finalized yet are left out
+	ListSweepable(chain Chain, finalizedBefore time.Time, limit int) ([]*DepositAddress, error)
+	// ClaimForSweeping moves the address from the status it was read in to sweeping
+	// Returns false if its status changed meanwhile, e.g. claimed by another run
+	ClaimForSweeping(address *DepositAddress) (bool, error)
+	// NextDerivationIndex reserves the next unused HD derivation index
+	NextDerivationIndex() (uint32, error)
+}
+
+// TokenRepository defines the interface for token registry data access
+type TokenRepository interface {
+	Create(token *Token) error
+	Update(token *Token) error
+	GetByID(id string) (*Token, error)
+	// List returns every token ordered by chain and symbol, disabled ones included
+	List() ([]*Token, error)
+}
+
 // MerchantRepository defines the interface for merchant data access (port for payment module)
 type MerchantRepository interface {
 	// Note: In a strict Hexagonal Architecture, this should probably return a minimal Merchant struct defined in this module
@@ -35,12 +212,25 @@ type MerchantRepository interface {
 	// However, since we are refactoring payment, let's define a local interface for what we need.
 	GetMerchantKYCStatus(merchantID string) (string, error)
 	UpdateMerchantVolume(merchantID string, amountUSD decimal.Decimal) error
+	GetMerchantPaymentTolerance(merchantID string) (underpayment, overpayment decimal.Decimal, err error)
+	// GetMerchantAddressMode returns "memo" or "deposit" for the chain
+	GetMerchantAddressMode(merchantID, chain string) (string, error)
+	// GetMerchantLatePaymentPolicy returns "accept", "refund" or "review"
+	GetMerchantLatePaymentPolicy(merchantID string) (string, error)
+	// GetMerchantQuoteSpread returns the fraction deducted from the market rate on quotes
+	GetMerchantQuoteSpread(merchantID string) (decimal.Decimal, error)
+	// IsSandboxMerchant reports whether the merchant authenticates with sk_test_ keys
+	IsSandboxMerchant(merchantID string) (bool, error)
 }
 
 // ExchangeRateProvider defines the interface for getting exchange rates
 type ExchangeRateProvider interface {
 	GetUSDTToVND(ctx context.Context) (decimal.Decimal, error)
 	GetUSDCToVND(ctx context.Context) (decimal.Decimal, error)
+	// GetRate returns how many units of quote one unit of base is worth, e.g. EUR to VND
+	GetRate(ctx context.Context, base, quote string) (decimal.Decimal, error)
+	// GetRateSource returns the provider the current rates come from, e.g. "coingecko"
+	GetRateSource(ctx context.Context) string
 }
 
 // ComplianceService defines the interface for compliance operations
@@ -53,3 +243,117 @@ type ComplianceService interface {
 type AMLService interface {
 	ScreenWallet(ctx context.Context, walletAddress string, chain string) error
 }
+
+// LedgerService defines the interface for ledger operations needed by the payment module
+type LedgerService interface {
+	// RecordPaymentSurplus holds the surplus of an overpaid payment for the payer, once per payment and transfer hash
+	RecordPaymentSurplus(paymentID, merchantID string, surplusCrypto decimal.Decimal, cryptoCurrency, txHash string) error
+	RecordRefundRequested(refundID, merchantID string, amountVND decimal.Decimal) error
+	RecordRefundCompleted(refundID, merchantID string, amountVND, amountCrypto decimal.Decimal, cryptoCurrency string) error
+	RecordRefundFailed(refundID, merchantID string, amountVND decimal.Decimal, reason string) error
+	// RecordPaymentReversed posts the mirror entries of everything recorded for the payment
+	RecordPaymentReversed(paymentID, merchantID, reason string) error
+	// RecordLatePaymentReceived holds a transfer received after the payment expired
+	RecordLatePaymentReceived(paymentID, merchantID string, amountCrypto decimal.Decimal, cryptoCurrency string) error
+	// RecordLatePaymentReleased releases a held late transfer once it is accepted or refunded
+	RecordLatePaymentReleased(paymentID, merchantID string, amountCrypto decimal.Decimal, cryptoCurrency, resolution string) error
+	// RecordPaymentSplit credits the shares of a completed split payment to the sellers' pending balances
+	// and the commission to the marketplace merchant in one transaction
+	RecordPaymentSplit(paymentID, merchantID string, sellerShares map[string]decimal.Decimal, commission decimal.Decimal) error
+	// RecordSplitRefundRequested reserves a refund of a split payment from the recipients' shares
+	RecordSplitRefundRequested(refundID, merchantID string, sellerShares map[string]decimal.Decimal, commission decimal.Decimal) error
+	// RecordSplitRefundFailed returns the shares reserved for a failed refund of a split payment
+	RecordSplitRefundFailed(refundID, merchantID string, sellerShares map[string]decimal.Decimal, commission decimal.Decimal, reason string) error
+}
+
+// LatePaymentRefunder creates refunds that return late payments to the payer
+type LatePaymentRefunder interface {
+	CreateLatePaymentRefund(ctx context.Context, payment *Payment, reason string) (*Refund, error)
+}
+
+// RefundSender defines the interface for sending refunds on-chain from the platform wallets
+// Refunds are signed and broadcast in two steps so the transaction hash can be saved before anything is sent
+type RefundSender interface {
+	// SignRefund builds and signs the transfer of amount of currency to toAddress without broadcasting it
+	// An error means nothing was broadcast
+	SignRefund(ctx context.Context, chain Chain, currency, toAddress string, amount decimal.Decimal) (*SignedRefundTx, error)
+	// BroadcastRefund sends a signed refund transaction
+	// An error does not mean the transaction was not broadcast, its hash has to be checked on-chain
+	BroadcastRefund(ctx context.Context, tx *SignedRefundTx) error
+	// GetRefundTxStatus returns RefundStatusSubmitted, RefundStatusCompleted or RefundStatusFailed
+	// Returns ErrRefundTxNotFound if the chain does not know the transaction
+	GetRefundTxStatus(ctx context.Context, chain Chain, txHash string) (RefundStatus, error)
+}
+
+// TransactionVerifier checks that a transfer is still part of the canonical chain
+type TransactionVerifier interface {
+	// VerifyTransaction returns the current inclusion of the transaction
+	// Included is false when the node does not know the transaction, it was reorged out or it reverted
+	VerifyTransaction(ctx context.Context, chain Chain, txHash string) (*TransactionInclusion, error)
+}
+
+// BlockchainTxRecorder keeps the blockchain transaction records of the listeners in sync with the watcher
+type BlockchainTxRecorder interface {
+	UpdateConfirmations(txHash string, confirmations int) error
+	MarkAsFinalized(txHash string) error
+	MarkAsFailed(txHash string, errorMessage string) error
+}
+
+// ComplianceAlerter raises compliance alerts for payment events that need manual review
+type ComplianceAlerter interface {
+	RaisePaymentReversalAlert(ctx context.Context, paymentID string, chain Chain, txHash, reason string) error
+}
+
+// DepositAddressGenerator creates per-payment deposit addresses
+type DepositAddressGenerator interface {
+	// GenerateDepositAddress returns a new deposit address for the payment, not yet persisted
+	// Nothing is sent on-chain, addresses that need an account (Solana) are returned pending
+	// Returns ErrDepositAddressNotSupported if the chain has no deposit address configuration
+	GenerateDepositAddress(ctx context.Context, paymentID, merchantID string, chain Chain, currency string) (*DepositAddress, error)
+	// ProvisionDepositAddress creates the on-chain account of a pending deposit address and waits for
+	// its confirmation. Safe to call again after a failure, an account that already exists is kept
+	ProvisionDepositAddress(ctx context.Context, address *DepositAddress) error
+}
+
+// DepositAddressQueue hands pending deposit addresses over to the worker, which provisions them
+type DepositAddressQueue interface {
+	EnqueueDepositProvision(ctx context.Context, paymentID string) error
+}
+
+// SolanaPayTransactionBuilder composes the transactions of Solana Pay transaction requests
+type SolanaPayTransactionBuilder interface {
+	// ReferenceKey returns the reference public key added to the transactions of the payment
+	ReferenceKey(paymentID string) string
+	// BuildTransferTransaction returns the unsigned transaction of the transfer, serialized in base64
+	BuildTransferTransaction(ctx context.Context, transfer SolanaPayTransfer) (string, error)
+}
+
+// DepositSweeper consolidates deposit address funds into the platform hot wallets
+type DepositSweeper interface {
+	// PrepareSweep readies a batch of claimed deposit addresses of one chain for SweepDepositAddress,
+	// e.g. funds their gas. An address it could not prepare fails its sweep and is retried by the next run
+	PrepareSweep(ctx context.Context, chain Chain, addresses []*DepositAddress) error
+	// SweepDepositAddress moves the balance of the deposit address to the hot wallet
+	// Returns an empty txHash if there was nothing to sweep
+	SweepDepositAddress(ctx context.Context, address *DepositAddress) (txHash string, amount decimal.Decimal, err error)
+}
+
+// WebhookPublisher defines the interface for delivering merchant webhooks
+type WebhookPublisher interface {
+	PublishWebhook(ctx context.Context, merchantID, event string, data map[string]interface{}) error
+}
+
+// AuditRecorder writes audit log entries for merchant actions on payments
+type AuditRecorder interface {
+	RecordPaymentAction(ctx context.Context, merchantID, paymentID, action string, metadata map[string]interface{}) error
+}
+
+// PaymentBatchQueue hands payment batches over to the worker
+type PaymentBatchQueue interface {
+	EnqueuePaymentBatch(ctx context.Context, batchID string) error
+}
+
+// EmailSender sends transactional emails to payers
+type EmailSender interface {
+	SendEmail(ctx context.Context, template, to string, data map[string]interface{}) error
+}
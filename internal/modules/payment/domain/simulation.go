@@ -0,0 +1,48 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// SimulationOutcome is what a simulation does to a test payment
+// Simulations run the same confirmation and expiry code as live payments, only the transfer is made up
+type SimulationOutcome string
+
+const (
+	// SimulationOutcomePaid sends the amount still owed, completing the payment
+	SimulationOutcomePaid SimulationOutcome = "paid"
+	// SimulationOutcomeUnderpaid sends half the amount still owed, leaving the payment open for a top-up
+	SimulationOutcomeUnderpaid SimulationOutcome = "underpaid"
+	// SimulationOutcomeOverpaid sends the amount still owed plus half the payment amount
+	SimulationOutcomeOverpaid SimulationOutcome = "overpaid"
+	// SimulationOutcomeExpired expires the payment without any transfer
+	SimulationOutcomeExpired SimulationOutcome = "expired"
+	// SimulationOutcomeLate expires the payment, then sends the amount still owed
+	SimulationOutcomeLate SimulationOutcome = "late"
+)
+
+// IsValid returns true if the outcome is one of the supported outcomes
+func (o SimulationOutcome) IsValid() bool {
+	switch o {
+	case SimulationOutcomePaid, SimulationOutcomeUnderpaid, SimulationOutcomeOverpaid,
+		SimulationOutcomeExpired, SimulationOutcomeLate:
+		return true
+	default:
+		return false
+	}
+}
+
+// TransferAmount returns the crypto amount of the simulated transfer for the payment,
+// zero when the outcome sends nothing
+func (o SimulationOutcome) TransferAmount(payment *Payment) decimal.Decimal {
+	remaining := payment.RemainingAmount()
+
+	switch o {
+	case SimulationOutcomePaid, SimulationOutcomeLate:
+		return remaining
+	case SimulationOutcomeUnderpaid:
+		return remaining.Div(decimal.NewFromInt(2)).Truncate(6)
+	case SimulationOutcomeOverpaid:
+		return remaining.Add(payment.AmountCrypto.Div(decimal.NewFromInt(2)).Truncate(6))
+	default:
+		return decimal.Zero
+	}
+}
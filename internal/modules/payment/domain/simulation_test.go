@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulationOutcome_IsValid(t *testing.T) {
+	for _, outcome := range []SimulationOutcome{
+		SimulationOutcomePaid,
+		SimulationOutcomeUnderpaid,
+		SimulationOutcomeOverpaid,
+		SimulationOutcomeExpired,
+		SimulationOutcomeLate,
+	} {
+		assert.True(t, outcome.IsValid(), outcome)
+	}
+
+	assert.False(t, SimulationOutcome("").IsValid())
+	assert.False(t, SimulationOutcome("refunded").IsValid())
+}
+
+func TestSimulationOutcome_TransferAmount(t *testing.T) {
+	payment := &Payment{
+		AmountCrypto:   decimal.RequireFromString("40.000001"),
+		AmountReceived: decimal.RequireFromString("10"),
+	}
+
+	assert.Equal(t, "30.000001", SimulationOutcomePaid.TransferAmount(payment).String())
+	assert.Equal(t, "30.000001", SimulationOutcomeLate.TransferAmount(payment).String())
+	assert.Equal(t, "15", SimulationOutcomeUnderpaid.TransferAmount(payment).String())
+	assert.Equal(t, "50.000001", SimulationOutcomeOverpaid.TransferAmount(payment).String())
+	assert.True(t, SimulationOutcomeExpired.TransferAmount(payment).IsZero())
+}
+
+func TestSimulationOutcome_TransferAmount_Underpaid(t *testing.T) {
+	payment := &Payment{AmountCrypto: decimal.RequireFromString("25")}
+
+	// The payment stays well below any underpayment tolerance
+	amount := SimulationOutcomeUnderpaid.TransferAmount(payment)
+	assert.Equal(t, "12.5", amount.String())
+
+	payment.AmountReceived = amount
+	assert.Equal(t, "12.5", payment.RemainingAmount().String())
+	assert.Equal(t, "25", SimulationOutcomeOverpaid.TransferAmount(payment).String())
+}
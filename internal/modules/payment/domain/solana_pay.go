@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SolanaPayLateTransferWindow is how long after expiry the listener keeps watching a payment's reference key
+// Transfers found in this window are handled as late payments
+const SolanaPayLateTransferWindow = time.Hour
+
+// SolanaPayInfo is what wallets show about the recipient before requesting the transaction
+type SolanaPayInfo struct {
+	Label string
+	Icon  string // URL of an SVG, PNG or WebP icon
+}
+
+// SolanaPayTransfer is the SPL token transfer composed for a Solana Pay transaction request
+type SolanaPayTransfer struct {
+	Payer         string // Payer wallet, signs and pays the fee
+	Recipient     string // Platform wallet, paid into its associated token account
+	TokenMint     string
+	TokenDecimals int
+	Amount        decimal.Decimal // In token units
+	Memo          string
+	Reference     string // Read-only key the listener finds the transaction by
+}
+
+// SolanaPayTransaction is the response to a Solana Pay transaction request
+type SolanaPayTransaction struct {
+	Transaction string // Unsigned transaction in base64, the payer signs and sends it
+	Message     string // Shown by the wallet next to the transaction
+	Reference   string
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaymentSplitType is how the share of a split recipient is expressed
+type PaymentSplitType string
+
+const (
+	PaymentSplitTypeFixed      PaymentSplitType = "fixed"      // Value is an amount in the pricing currency
+	PaymentSplitTypePercentage PaymentSplitType = "percentage" // Value is a percentage of the net amount
+)
+
+// MaxPaymentSplits is the maximum number of recipients of a split payment
+const MaxPaymentSplits = 20
+
+// PaymentSplit is the share of a payment's net amount credited to one merchant
+type PaymentSplit struct {
+	MerchantID string           `json:"merchant_id"`
+	Type       PaymentSplitType `json:"type"`
+	Value      decimal.Decimal  `json:"value"`
+
+	// Resolved share of the net amount, AmountVND is credited to the recipient's pending balance
+	Amount    decimal.Decimal `json:"amount"`
+	AmountVND decimal.Decimal `json:"amount_vnd"`
+
+	// Commission is the share of the marketplace merchant that created the payment
+	Commission bool `json:"commission,omitempty"`
+}
+
+// PaymentSplits is stored as a JSONB array
+type PaymentSplits []PaymentSplit
+
+// Value implements the driver.Valuer interface for database writes
+func (splits PaymentSplits) Value() (driver.Value, error) {
+	if splits == nil {
+		return nil, nil
+	}
+	return json.Marshal(splits)
+}
+
+// Scan implements the sql.Scanner interface for database reads
+func (splits *PaymentSplits) Scan(value interface{}) error {
+	if value == nil {
+		*splits = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan payment splits: not a byte slice")
+	}
+
+	return json.Unmarshal(bytes, splits)
+}
+
+// Shares returns the VND shares of the sellers by merchant ID and the commission, zero shares are left out
+func (splits PaymentSplits) Shares() (sellers map[string]decimal.Decimal, commission decimal.Decimal) {
+	sellers = make(map[string]decimal.Decimal)
+	for _, split := range splits {
+		switch {
+		case !split.AmountVND.IsPositive():
+		case split.Commission:
+			commission = commission.Add(split.AmountVND)
+		default:
+			sellers[split.MerchantID] = split.AmountVND
+		}
+	}
+	return sellers, commission
+}
+
+// remainderIndex returns the split that absorbs rounding differences: the commission, or the last split
+func (splits PaymentSplits) remainderIndex() int {
+	for i, split := range splits {
+		if split.Commission {
+			return i
+		}
+	}
+	return len(splits) - 1
+}
+
+// HasSplits returns true if the net amount of the payment is split among several merchants
+func (p *Payment) HasSplits() bool {
+	return len(p.Splits) > 0
+}
+
+// ApplySplits resolves the shares of the payment's net amount, CalculateFee must be called first
+// Fixed values are in the pricing currency and percentages are of the net amount; together they
+// must add up to exactly the net amount. The split of the merchant that created the payment is its
+// commission. Rounding differences go to the commission, or to the last split.
+func (p *Payment) ApplySplits(splits []PaymentSplit) error {
+	if len(splits) == 0 {
+		p.Splits = nil
+		return nil
+	}
+	if len(splits) > MaxPaymentSplits {
+		return fmt.Errorf("%w: at most %d splits are allowed", ErrInvalidPaymentSplits, MaxPaymentSplits)
+	}
+
+	hundred := decimal.NewFromInt(100)
+	resolved := make(PaymentSplits, len(splits))
+	seen := make(map[string]bool, len(splits))
+	total := decimal.Zero
+	for i, split := range splits {
+		if split.MerchantID == "" {
+			return fmt.Errorf("%w: merchant_id is required", ErrInvalidPaymentSplits)
+		}
+		if seen[split.MerchantID] {
+			return fmt.Errorf("%w: merchant %s is split more than once", ErrInvalidPaymentSplits, split.MerchantID)
+		}
+		seen[split.MerchantID] = true
+
+		if !split.Value.IsPositive() {
+			return fmt.Errorf("%w: split value must be positive", ErrInvalidPaymentSplits)
+		}
+		switch split.Type {
+		case PaymentSplitTypeFixed:
+			split.Amount = split.Value
+		case PaymentSplitTypePercentage:
+			if split.Value.GreaterThan(hundred) {
+				return fmt.Errorf("%w: percentage cannot exceed 100", ErrInvalidPaymentSplits)
+			}
+			split.Amount = p.PricingNetAmount.Mul(split.Value).Div(hundred)
+		default:
+			return fmt.Errorf("%w: unknown split type %q", ErrInvalidPaymentSplits, split.Type)
+		}
+
+		split.Commission = split.MerchantID == p.MerchantID
+		total = total.Add(split.Amount)
+		resolved[i] = split
+	}
+
+	if !total.Equal(p.PricingNetAmount) {
+		return fmt.Errorf("%w: splits add up to %s %s, the net amount is %s %s",
+			ErrInvalidPaymentSplits, total.String(), p.GetPricingCurrency(), p.PricingNetAmount.String(), p.GetPricingCurrency())
+	}
+
+	// VND shares follow the pricing shares so they add up to the VND net amount
+	remainder := resolved.remainderIndex()
+	allocated, allocatedVND := decimal.Zero, decimal.Zero
+	for i := range resolved {
+		if i == remainder {
+			continue
+		}
+		resolved[i].Amount = resolved[i].Amount.Round(2)
+		resolved[i].AmountVND = p.NetAmountVND.Mul(resolved[i].Amount).Div(p.PricingNetAmount).Round(0)
+		allocated = allocated.Add(resolved[i].Amount)
+		allocatedVND = allocatedVND.Add(resolved[i].AmountVND)
+	}
+	resolved[remainder].Amount = p.PricingNetAmount.Sub(allocated)
+	resolved[remainder].AmountVND = p.NetAmountVND.Sub(allocatedVND)
+
+	for _, split := range resolved {
+		if !split.Amount.IsPositive() || !split.AmountVND.IsPositive() {
+			return fmt.Errorf("%w: the share of merchant %s rounds to zero", ErrInvalidPaymentSplits, split.MerchantID)
+		}
+	}
+
+	p.Splits = resolved
+	return nil
+}
+
+// SplitRefundShares divides a refunded VND amount among the split recipients in proportion to their shares
+// AmountVND of each returned split is its part of the refund, rounding differences go to the commission
+// or the last split
+func (p *Payment) SplitRefundShares(amountVND decimal.Decimal) PaymentSplits {
+	if !p.HasSplits() || !p.NetAmountVND.IsPositive() {
+		return nil
+	}
+
+	shares := make(PaymentSplits, len(p.Splits))
+	remainder := p.Splits.remainderIndex()
+	allocated := decimal.Zero
+	for i, split := range p.Splits {
+		shares[i] = PaymentSplit{MerchantID: split.MerchantID, Commission: split.Commission}
+		if i == remainder {
+			continue
+		}
+		shares[i].AmountVND = amountVND.Mul(split.AmountVND).Div(p.NetAmountVND).Round(0)
+		allocated = allocated.Add(shares[i].AmountVND)
+	}
+	shares[remainder].AmountVND = amountVND.Sub(allocated)
+
+	return shares
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSplitPayment() *Payment {
+	payment := &Payment{
+		MerchantID:      "marketplace",
+		AmountVND:       decimal.NewFromInt(2500000),
+		PricingCurrency: "USD",
+		PricingAmount:   decimal.NewFromInt(100),
+		FeePercentage:   decimal.NewFromFloat(0.01),
+	}
+	payment.CalculateFee()
+	return payment
+}
+
+func TestPayment_ApplySplits(t *testing.T) {
+	payment := newSplitPayment()
+
+	err := payment.ApplySplits([]PaymentSplit{
+		{MerchantID: "seller-a", Type: PaymentSplitTypeFixed, Value: decimal.NewFromInt(50)},
+		{MerchantID: "seller-b", Type: PaymentSplitTypePercentage, Value: decimal.NewFromInt(40)},
+		{MerchantID: "marketplace", Type: PaymentSplitTypeFixed, Value: decimal.NewFromFloat(9.4)},
+	})
+	require.NoError(t, err)
+	require.True(t, payment.HasSplits())
+
+	assert.Equal(t, "50", payment.Splits[0].Amount.String())
+	assert.Equal(t, "1250000", payment.Splits[0].AmountVND.String())
+	assert.Equal(t, "39.6", payment.Splits[1].Amount.String())
+	assert.Equal(t, "990000", payment.Splits[1].AmountVND.String())
+	assert.True(t, payment.Splits[2].Commission)
+	assert.Equal(t, "9.4", payment.Splits[2].Amount.String())
+	assert.Equal(t, "235000", payment.Splits[2].AmountVND.String())
+
+	sellers, commission := payment.Splits.Shares()
+	assert.Len(t, sellers, 2)
+	assert.Equal(t, "1250000", sellers["seller-a"].String())
+	assert.Equal(t, "235000", commission.String())
+}
+
+func TestPayment_ApplySplits_RoundingGoesToLastSplit(t *testing.T) {
+	payment := newSplitPayment()
+
+	err := payment.ApplySplits([]PaymentSplit{
+		{MerchantID: "seller-a", Type: PaymentSplitTypePercentage, Value: decimal.NewFromFloat(33.33)},
+		{MerchantID: "seller-b", Type: PaymentSplitTypePercentage, Value: decimal.NewFromFloat(33.33)},
+		{MerchantID: "seller-c", Type: PaymentSplitTypePercentage, Value: decimal.NewFromFloat(33.34)},
+	})
+	require.NoError(t, err)
+
+	total, totalVND := decimal.Zero, decimal.Zero
+	for _, split := range payment.Splits {
+		assert.False(t, split.Commission)
+		total = total.Add(split.Amount)
+		totalVND = totalVND.Add(split.AmountVND)
+	}
+	assert.True(t, total.Equal(payment.PricingNetAmount))
+	assert.True(t, totalVND.Equal(payment.NetAmountVND))
+	assert.Equal(t, "33", payment.Splits[0].Amount.String())
+}
+
+func TestPayment_ApplySplits_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		splits []PaymentSplit
+	}{
+		{
+			name: "sum below net amount",
+			splits: []PaymentSplit{
+				{MerchantID: "seller-a", Type: PaymentSplitTypeFixed, Value: decimal.NewFromInt(50)},
+			},
+		},
+		{
+			name: "sum above net amount",
+			splits: []PaymentSplit{
+				{MerchantID: "seller-a", Type: PaymentSplitTypePercentage, Value: decimal.NewFromInt(100)},
+				{MerchantID: "marketplace", Type: PaymentSplitTypeFixed, Value: decimal.NewFromInt(1)},
+			},
+		},
+		{
+			name: "duplicate merchant",
+			splits: []PaymentSplit{
+				{MerchantID: "seller-a", Type: PaymentSplitTypePercentage, Value: decimal.NewFromInt(50)},
+				{MerchantID: "seller-a", Type: PaymentSplitTypePercentage, Value: decimal.NewFromInt(50)},
+			},
+		},
+		{
+			name: "percentage above 100",
+			splits: []PaymentSplit{
+				{MerchantID: "seller-a", Type: PaymentSplitTypePercentage, Value: decimal.NewFromInt(101)},
+			},
+		},
+		{
+			name: "unknown type",
+			splits: []PaymentSplit{
+				{MerchantID: "seller-a", Type: PaymentSplitType("share"), Value: decimal.NewFromInt(99)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := newSplitPayment()
+			assert.ErrorIs(t, payment.ApplySplits(tt.splits), ErrInvalidPaymentSplits)
+			assert.False(t, payment.HasSplits())
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentActor identifies who moved a payment to a new status
+type PaymentActor string
+
+const (
+	PaymentActorSystem   PaymentActor = "system"   // Payment service itself (e.g. a failed creation)
+	PaymentActorListener PaymentActor = "listener" // Blockchain listener reporting a transfer
+	PaymentActorWorker   PaymentActor = "worker"   // Background jobs (expiry, confirmation watcher, compliance)
+	PaymentActorMerchant PaymentActor = "merchant" // Merchant through the API
+	PaymentActorAdmin    PaymentActor = "admin"    // Operator through the admin API
+)
+
+// paymentTransitions lists the statuses a payment can move to from each status.
+// Failed and reversed payments are final.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusCreated: {
+		PaymentStatusPending, PaymentStatusPendingCompliance, PaymentStatusUnderpaid, PaymentStatusConfirming,
+		PaymentStatusCompleted, PaymentStatusOverpaid, PaymentStatusExpired, PaymentStatusFailed,
+		PaymentStatusCanceled, PaymentStatusLatePaid,
+	},
+	PaymentStatusPending: {
+		PaymentStatusPendingCompliance, PaymentStatusUnderpaid, PaymentStatusConfirming, PaymentStatusCompleted,
+		PaymentStatusOverpaid, PaymentStatusExpired, PaymentStatusFailed, PaymentStatusCanceled,
+		PaymentStatusLatePaid,
+	},
+	PaymentStatusPendingCompliance: {
+		PaymentStatusUnderpaid, PaymentStatusConfirming, PaymentStatusCompleted, PaymentStatusOverpaid,
+		PaymentStatusFailed, PaymentStatusLatePaid,
+	},
+	PaymentStatusUnderpaid: {
+		PaymentStatusConfirming, PaymentStatusCompleted, PaymentStatusOverpaid, PaymentStatusFailed,
+		PaymentStatusLatePaid, PaymentStatusReversed,
+	},
+	PaymentStatusConfirming: {
+		PaymentStatusUnderpaid, PaymentStatusCompleted, PaymentStatusOverpaid, PaymentStatusFailed,
+		PaymentStatusReversed,
+	},
+	PaymentStatusCompleted: {PaymentStatusReversed},
+	PaymentStatusOverpaid:  {PaymentStatusReversed},
+	PaymentStatusExpired:   {PaymentStatusLatePaid},
+	PaymentStatusCanceled:  {PaymentStatusLatePaid},
+	PaymentStatusLatePaid:  {PaymentStatusConfirming, PaymentStatusCompleted, PaymentStatusReversed},
+}
+
+// CanTransitionTo returns true if the payment can move from its current status to the given status
+func (p *Payment) CanTransitionTo(to PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[p.Status] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal returns true if the payment can no longer change status
+func (p *Payment) IsFinal() bool {
+	return len(paymentTransitions[p.Status]) == 0
+}
+
+// PaymentStatusTransition records a status change of a payment
+type PaymentStatusTransition struct {
+	ID         string         `json:"id" db:"id"`
+	PaymentID  string         `json:"payment_id" db:"payment_id"`
+	FromStatus PaymentStatus  `json:"from_status" db:"from_status"`
+	ToStatus   PaymentStatus  `json:"to_status" db:"to_status"`
+	Actor      PaymentActor   `json:"actor" db:"actor"`
+	ActorID    sql.NullString `json:"actor_id,omitempty" db:"actor_id"` // Merchant or operator ID
+	Reason     sql.NullString `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+func (PaymentStatusTransition) TableName() string {
+	return "payment_status_history"
+}
+
+// TransitionTo moves the payment to a new status and returns the transition to record,
+// ErrInvalidPaymentTransition if the state machine does not allow it
+func (p *Payment) TransitionTo(to PaymentStatus, actor PaymentActor, actorID, reason string) (*PaymentStatusTransition, error) {
+	if !p.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidPaymentTransition, p.Status, to)
+	}
+
+	transition := &PaymentStatusTransition{
+		ID:         uuid.New().String(),
+		PaymentID:  p.ID,
+		FromStatus: p.Status,
+		ToStatus:   to,
+		Actor:      actor,
+		ActorID:    sql.NullString{String: actorID, Valid: actorID != ""},
+		Reason:     sql.NullString{String: reason, Valid: reason != ""},
+		CreatedAt:  time.Now(),
+	}
+	p.Status = to
+
+	return transition, nil
+}
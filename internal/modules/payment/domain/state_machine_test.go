@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayment_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     PaymentStatus
+		to       PaymentStatus
+		expected bool
+	}{
+		{"created to expired", PaymentStatusCreated, PaymentStatusExpired, true},
+		{"pending to canceled", PaymentStatusPending, PaymentStatusCanceled, true},
+		{"underpaid to completed", PaymentStatusUnderpaid, PaymentStatusCompleted, true},
+		{"confirming to overpaid", PaymentStatusConfirming, PaymentStatusOverpaid, true},
+		{"completed to reversed", PaymentStatusCompleted, PaymentStatusReversed, true},
+		{"expired to late paid", PaymentStatusExpired, PaymentStatusLatePaid, true},
+		{"late paid to completed", PaymentStatusLatePaid, PaymentStatusCompleted, true},
+		{"completed to failed", PaymentStatusCompleted, PaymentStatusFailed, false},
+		{"underpaid to expired", PaymentStatusUnderpaid, PaymentStatusExpired, false},
+		{"confirming to canceled", PaymentStatusConfirming, PaymentStatusCanceled, false},
+		{"expired to completed", PaymentStatusExpired, PaymentStatusCompleted, false},
+		{"failed to anything", PaymentStatusFailed, PaymentStatusCreated, false},
+		{"reversed to anything", PaymentStatusReversed, PaymentStatusCompleted, false},
+		{"same status", PaymentStatusPending, PaymentStatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{Status: tt.from}
+			assert.Equal(t, tt.expected, payment.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestPayment_IsFinal(t *testing.T) {
+	assert.True(t, (&Payment{Status: PaymentStatusFailed}).IsFinal())
+	assert.True(t, (&Payment{Status: PaymentStatusReversed}).IsFinal())
+	assert.False(t, (&Payment{Status: PaymentStatusCompleted}).IsFinal())
+	assert.False(t, (&Payment{Status: PaymentStatusExpired}).IsFinal())
+}
+
+func TestPayment_TransitionTo(t *testing.T) {
+	payment := &Payment{ID: "payment-1", Status: PaymentStatusPending}
+
+	transition, err := payment.TransitionTo(PaymentStatusCanceled, PaymentActorMerchant, "merchant-1", "order canceled")
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusCanceled, payment.Status)
+	assert.Equal(t, "payment-1", transition.PaymentID)
+	assert.Equal(t, PaymentStatusPending, transition.FromStatus)
+	assert.Equal(t, PaymentStatusCanceled, transition.ToStatus)
+	assert.Equal(t, PaymentActorMerchant, transition.Actor)
+	assert.Equal(t, "merchant-1", transition.ActorID.String)
+	assert.Equal(t, "order canceled", transition.Reason.String)
+	assert.NotEmpty(t, transition.ID)
+
+	// Canceled payments only accept late transfers
+	transition, err = payment.TransitionTo(PaymentStatusCompleted, PaymentActorListener, "", "")
+	assert.ErrorIs(t, err, ErrInvalidPaymentTransition)
+	assert.Nil(t, transition)
+	assert.Equal(t, PaymentStatusCanceled, payment.Status)
+
+	transition, err = payment.TransitionTo(PaymentStatusLatePaid, PaymentActorListener, "", "")
+	require.NoError(t, err)
+	assert.False(t, transition.ActorID.Valid)
+	assert.False(t, transition.Reason.Valid)
+}
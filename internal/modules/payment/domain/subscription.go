@@ -0,0 +1,298 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// SubscriptionInterval is the unit of the billing interval of a plan
+type SubscriptionInterval string
+
+const (
+	SubscriptionIntervalDay   SubscriptionInterval = "day"
+	SubscriptionIntervalWeek  SubscriptionInterval = "week"
+	SubscriptionIntervalMonth SubscriptionInterval = "month"
+	SubscriptionIntervalYear  SubscriptionInterval = "year"
+)
+
+// SubscriptionPlanStatus represents the status of a subscription plan
+type SubscriptionPlanStatus string
+
+const (
+	SubscriptionPlanStatusActive   SubscriptionPlanStatus = "active"
+	SubscriptionPlanStatusArchived SubscriptionPlanStatus = "archived" // No new subscriptions, existing ones keep billing
+)
+
+// SubscriptionStatus represents the status of a subscription
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive   SubscriptionStatus = "active"
+	SubscriptionStatusPastDue  SubscriptionStatus = "past_due" // A billing cycle is unpaid and in dunning
+	SubscriptionStatusCanceled SubscriptionStatus = "canceled"
+)
+
+// SubscriptionCycleStatus represents the status of a billing cycle
+type SubscriptionCycleStatus string
+
+const (
+	SubscriptionCycleStatusOpen          SubscriptionCycleStatus = "open"
+	SubscriptionCycleStatusPaid          SubscriptionCycleStatus = "paid"
+	SubscriptionCycleStatusUncollectible SubscriptionCycleStatus = "uncollectible" // Unpaid at the end of the grace period
+	SubscriptionCycleStatusVoid          SubscriptionCycleStatus = "void"          // The subscription was canceled while the cycle was open
+)
+
+// Subscription cancellation reasons
+const (
+	SubscriptionCancelReasonMerchant  = "merchant"   // Canceled by the merchant
+	SubscriptionCancelReasonPeriodEnd = "period_end" // Canceled by the merchant at the end of the paid period
+	SubscriptionCancelReasonUnpaid    = "unpaid"     // A cycle was still unpaid when the grace period ended
+)
+
+// Subscription webhook events
+const (
+	SubscriptionEventCreated          = "subscription.created"
+	SubscriptionEventCycleIssued      = "subscription.cycle_issued" // A payment was issued and its link sent to the payer
+	SubscriptionEventPaymentSucceeded = "subscription.payment_succeeded"
+	SubscriptionEventPaymentFailed    = "subscription.payment_failed" // A cycle payment expired unpaid
+	SubscriptionEventPastDue          = "subscription.past_due"
+	SubscriptionEventCanceled         = "subscription.canceled"
+)
+
+// Subscription payer email templates
+const (
+	SubscriptionEmailPaymentDue    = "subscription_payment_due"
+	SubscriptionEmailPaymentFailed = "subscription_payment_failed"
+	SubscriptionEmailCanceled      = "subscription_canceled"
+)
+
+// DunningPolicy decides when unpaid billing cycles are retried and when the subscription is canceled
+type DunningPolicy struct {
+	// RetryIntervals are the offsets from the cycle due date at which a new payment is issued
+	// after the previous one expired unpaid, in increasing order
+	RetryIntervals []time.Duration
+	// GracePeriod is how long after the due date an unpaid cycle cancels the subscription
+	GracePeriod time.Duration
+}
+
+// DefaultDunningPolicy retries after 1, 3 and 5 days and cancels after 7 days
+func DefaultDunningPolicy() DunningPolicy {
+	return DunningPolicy{
+		RetryIntervals: []time.Duration{24 * time.Hour, 72 * time.Hour, 120 * time.Hour},
+		GracePeriod:    7 * 24 * time.Hour,
+	}
+}
+
+// GraceEndsAt returns when a cycle due at dueAt cancels the subscription if still unpaid
+func (p DunningPolicy) GraceEndsAt(dueAt time.Time) time.Time {
+	return dueAt.Add(p.GracePeriod)
+}
+
+// NextRetryAt returns when the next payment of a cycle is issued after attempts failed ones
+// Returns false when the schedule has no retry left before the grace period ends
+func (p DunningPolicy) NextRetryAt(dueAt time.Time, attempts int, now time.Time) (time.Time, bool) {
+	idx := attempts - 1
+	if idx < 0 || idx >= len(p.RetryIntervals) {
+		return time.Time{}, false
+	}
+
+	// A retry missed while the previous payment was still in flight is issued right away
+	retryAt := dueAt.Add(p.RetryIntervals[idx])
+	if retryAt.Before(now) {
+		retryAt = now
+	}
+	if !retryAt.Before(p.GraceEndsAt(dueAt)) {
+		return time.Time{}, false
+	}
+	return retryAt, true
+}
+
+// SubscriptionPlan bills a fixed amount every interval
+type SubscriptionPlan struct {
+	ID          string         `json:"id" db:"id"`
+	MerchantID  string         `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+	Name        string         `json:"name" db:"name" validate:"required,max=255"`
+	Description sql.NullString `json:"description,omitempty" db:"description"`
+
+	// Amount is in the pricing currency
+	PricingCurrency string               `json:"pricing_currency" db:"pricing_currency" validate:"required,len=3"`
+	Amount          decimal.Decimal      `json:"amount" db:"amount"`
+	BillingInterval SubscriptionInterval `json:"billing_interval" db:"billing_interval" validate:"required,oneof=day week month year"`
+	IntervalCount   int                  `json:"interval_count" db:"interval_count" validate:"gte=1"`
+
+	// Optional: token and chain of the payments, the payer chooses when empty
+	Currency sql.NullString `json:"currency,omitempty" db:"currency"`
+	Chain    sql.NullString `json:"chain,omitempty" db:"chain"`
+
+	Status   SubscriptionPlanStatus `json:"status" db:"status" validate:"required,oneof=active archived"`
+	Metadata database.JSONBMap      `json:"metadata,omitempty" db:"metadata"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (SubscriptionPlan) TableName() string {
+	return "subscription_plans"
+}
+
+// Validate checks the amount and interval of a new plan
+func (p *SubscriptionPlan) Validate() error {
+	if p.Amount.LessThanOrEqual(decimal.Zero) {
+		return fmt.Errorf("%w: plan amount must be positive", ErrInvalidAmount)
+	}
+
+	switch p.BillingInterval {
+	case SubscriptionIntervalDay, SubscriptionIntervalWeek, SubscriptionIntervalMonth, SubscriptionIntervalYear:
+	default:
+		return fmt.Errorf("%w: unknown billing interval %q", ErrInvalidSubscriptionPlan, p.BillingInterval)
+	}
+
+	if p.IntervalCount <= 0 {
+		return fmt.Errorf("%w: interval_count must be positive", ErrInvalidSubscriptionPlan)
+	}
+	return nil
+}
+
+// NextPeriodEnd returns the end of a billing period starting at start
+func (p *SubscriptionPlan) NextPeriodEnd(start time.Time) time.Time {
+	switch p.BillingInterval {
+	case SubscriptionIntervalDay:
+		return start.AddDate(0, 0, p.IntervalCount)
+	case SubscriptionIntervalWeek:
+		return start.AddDate(0, 0, 7*p.IntervalCount)
+	case SubscriptionIntervalYear:
+		return start.AddDate(p.IntervalCount, 0, 0)
+	default:
+		return start.AddDate(0, p.IntervalCount, 0)
+	}
+}
+
+// Subscription bills a customer for a plan every period until it is canceled
+type Subscription struct {
+	ID         string `json:"id" db:"id"`
+	MerchantID string `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+	PlanID     string `json:"plan_id" db:"plan_id" validate:"required,uuid"`
+
+	CustomerName      sql.NullString `json:"customer_name,omitempty" db:"customer_name"`
+	CustomerEmail     sql.NullString `json:"customer_email,omitempty" db:"customer_email"`         // Receives the payment links
+	CustomerReference sql.NullString `json:"customer_reference,omitempty" db:"customer_reference"` // Merchant's customer ID
+
+	Status             SubscriptionStatus `json:"status" db:"status" validate:"required,oneof=active past_due canceled"`
+	CurrentPeriodStart time.Time          `json:"current_period_start" db:"current_period_start"`
+	CurrentPeriodEnd   time.Time          `json:"current_period_end" db:"current_period_end"`
+	NextBillingAt      time.Time          `json:"next_billing_at" db:"next_billing_at"` // When the next cycle is issued
+	CyclesCount        int                `json:"cycles_count" db:"cycles_count"`
+
+	CancelAtPeriodEnd  bool           `json:"cancel_at_period_end" db:"cancel_at_period_end"`
+	CanceledAt         sql.NullTime   `json:"canceled_at,omitempty" db:"canceled_at"`
+	CancellationReason sql.NullString `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+
+	Metadata database.JSONBMap `json:"metadata,omitempty" db:"metadata"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
+
+// IsCanceled returns true if the subscription no longer bills
+func (s *Subscription) IsCanceled() bool {
+	return s.Status == SubscriptionStatusCanceled
+}
+
+// Cancel stops billing the subscription
+func (s *Subscription) Cancel(reason string) {
+	s.Status = SubscriptionStatusCanceled
+	s.CancelAtPeriodEnd = false
+	s.CanceledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.CancellationReason = sql.NullString{String: reason, Valid: true}
+}
+
+// NextCycle returns the billing cycle starting at NextBillingAt and moves the subscription to its period
+func (s *Subscription) NextCycle(plan *SubscriptionPlan, policy DunningPolicy, now time.Time) *SubscriptionCycle {
+	start := s.NextBillingAt
+	end := plan.NextPeriodEnd(start)
+
+	// A cycle issued late is still due when it is issued, the payer had no link before
+	dueAt := start
+	if now.After(dueAt) {
+		dueAt = now
+	}
+
+	s.CyclesCount++
+	s.CurrentPeriodStart = start
+	s.CurrentPeriodEnd = end
+	s.NextBillingAt = end
+
+	return &SubscriptionCycle{
+		SubscriptionID:  s.ID,
+		MerchantID:      s.MerchantID,
+		CycleNumber:     s.CyclesCount,
+		PeriodStart:     start,
+		PeriodEnd:       end,
+		PricingCurrency: plan.PricingCurrency,
+		Amount:          plan.Amount,
+		Status:          SubscriptionCycleStatusOpen,
+		DueAt:           dueAt,
+		NextAttemptAt:   sql.NullTime{Time: dueAt, Valid: true},
+		GraceEndsAt:     policy.GraceEndsAt(dueAt),
+	}
+}
+
+// SubscriptionCycle is one billing period of a subscription, paid by one of the payments issued for it
+type SubscriptionCycle struct {
+	ID             string `json:"id" db:"id"`
+	SubscriptionID string `json:"subscription_id" db:"subscription_id" validate:"required,uuid"`
+	MerchantID     string `json:"merchant_id" db:"merchant_id" validate:"required,uuid"`
+	CycleNumber    int    `json:"cycle_number" db:"cycle_number"`
+
+	PeriodStart     time.Time       `json:"period_start" db:"period_start"`
+	PeriodEnd       time.Time       `json:"period_end" db:"period_end"`
+	PricingCurrency string          `json:"pricing_currency" db:"pricing_currency"`
+	Amount          decimal.Decimal `json:"amount" db:"amount"`
+
+	Status        SubscriptionCycleStatus `json:"status" db:"status" validate:"required,oneof=open paid uncollectible void"`
+	PaymentID     sql.NullString          `json:"payment_id,omitempty" db:"payment_id"` // Payment of the current attempt
+	Attempts      int                     `json:"attempts" db:"attempts"`
+	DueAt         time.Time               `json:"due_at" db:"due_at"`
+	NextAttemptAt sql.NullTime            `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	GraceEndsAt   time.Time               `json:"grace_ends_at" db:"grace_ends_at"`
+	PaidAt        sql.NullTime            `json:"paid_at,omitempty" db:"paid_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (SubscriptionCycle) TableName() string {
+	return "subscription_cycles"
+}
+
+// IsOpen returns true if the cycle is still waiting for a payment
+func (c *SubscriptionCycle) IsOpen() bool {
+	return c.Status == SubscriptionCycleStatusOpen
+}
+
+// IsGraceOver returns true if an unpaid cycle should cancel its subscription
+func (c *SubscriptionCycle) IsGraceOver(now time.Time) bool {
+	return !now.Before(c.GraceEndsAt)
+}
+
+// MarkPaid records the payment that paid the cycle
+func (c *SubscriptionCycle) MarkPaid(paymentID string) {
+	c.Status = SubscriptionCycleStatusPaid
+	c.PaymentID = sql.NullString{String: paymentID, Valid: true}
+	c.PaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+	c.NextAttemptAt = sql.NullTime{}
+}
+
+// Close ends an unpaid cycle with the given status
+func (c *SubscriptionCycle) Close(status SubscriptionCycleStatus) {
+	c.Status = status
+	c.NextAttemptAt = sql.NullTime{}
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionPlan_Validate(t *testing.T) {
+	plan := &SubscriptionPlan{
+		PricingCurrency: "USD",
+		Amount:          decimal.NewFromInt(20),
+		BillingInterval: SubscriptionIntervalMonth,
+		IntervalCount:   1,
+	}
+	require.NoError(t, plan.Validate())
+
+	plan.IntervalCount = 0
+	assert.ErrorIs(t, plan.Validate(), ErrInvalidSubscriptionPlan)
+
+	plan.IntervalCount = 1
+	plan.BillingInterval = SubscriptionInterval("fortnight")
+	assert.ErrorIs(t, plan.Validate(), ErrInvalidSubscriptionPlan)
+
+	plan.BillingInterval = SubscriptionIntervalWeek
+	plan.Amount = decimal.Zero
+	assert.ErrorIs(t, plan.Validate(), ErrInvalidAmount)
+}
+
+func TestSubscriptionPlan_NextPeriodEnd(t *testing.T) {
+	start := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		interval SubscriptionInterval
+		count    int
+		expected time.Time
+	}{
+		{SubscriptionIntervalDay, 10, time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)},
+		{SubscriptionIntervalWeek, 2, time.Date(2025, 2, 14, 12, 0, 0, 0, time.UTC)},
+		{SubscriptionIntervalMonth, 1, time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)},
+		{SubscriptionIntervalYear, 1, time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.interval), func(t *testing.T) {
+			plan := &SubscriptionPlan{BillingInterval: tt.interval, IntervalCount: tt.count}
+			assert.Equal(t, tt.expected, plan.NextPeriodEnd(start))
+		})
+	}
+}
+
+func TestDunningPolicy_NextRetryAt(t *testing.T) {
+	policy := DefaultDunningPolicy()
+	dueAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	retryAt, ok := policy.NextRetryAt(dueAt, 1, dueAt.Add(time.Hour))
+	require.True(t, ok)
+	assert.Equal(t, dueAt.Add(24*time.Hour), retryAt)
+
+	retryAt, ok = policy.NextRetryAt(dueAt, 3, dueAt.Add(time.Hour))
+	require.True(t, ok)
+	assert.Equal(t, dueAt.Add(120*time.Hour), retryAt)
+
+	// A retry missed while the previous payment was in flight is issued right away
+	now := dueAt.Add(100 * time.Hour)
+	retryAt, ok = policy.NextRetryAt(dueAt, 2, now)
+	require.True(t, ok)
+	assert.Equal(t, now, retryAt)
+
+	// The schedule is exhausted
+	_, ok = policy.NextRetryAt(dueAt, 4, dueAt)
+	assert.False(t, ok)
+	_, ok = policy.NextRetryAt(dueAt, 0, dueAt)
+	assert.False(t, ok)
+
+	// Retries past the grace period are skipped
+	_, ok = policy.NextRetryAt(dueAt, 3, dueAt.Add(policy.GracePeriod))
+	assert.False(t, ok)
+}
+
+func TestSubscription_NextCycle(t *testing.T) {
+	policy := DefaultDunningPolicy()
+	plan := &SubscriptionPlan{
+		PricingCurrency: "USD",
+		Amount:          decimal.NewFromInt(20),
+		BillingInterval: SubscriptionIntervalMonth,
+		IntervalCount:   1,
+	}
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	subscription := &Subscription{
+		ID:            "sub-1",
+		MerchantID:    "merchant-1",
+		Status:        SubscriptionStatusActive,
+		NextBillingAt: start,
+	}
+
+	cycle := subscription.NextCycle(plan, policy, start)
+	assert.Equal(t, 1, cycle.CycleNumber)
+	assert.Equal(t, start, cycle.PeriodStart)
+	assert.Equal(t, start.AddDate(0, 1, 0), cycle.PeriodEnd)
+	assert.Equal(t, start, cycle.DueAt)
+	assert.Equal(t, start, cycle.NextAttemptAt.Time)
+	assert.Equal(t, start.Add(policy.GracePeriod), cycle.GraceEndsAt)
+	assert.True(t, cycle.Amount.Equal(plan.Amount))
+	assert.True(t, cycle.IsOpen())
+	assert.Equal(t, cycle.PeriodEnd, subscription.NextBillingAt)
+
+	// A cycle issued late is due when it is issued
+	late := cycle.PeriodEnd.Add(2 * time.Hour)
+	next := subscription.NextCycle(plan, policy, late)
+	assert.Equal(t, 2, next.CycleNumber)
+	assert.Equal(t, cycle.PeriodEnd, next.PeriodStart)
+	assert.Equal(t, late, next.DueAt)
+	assert.Equal(t, late.Add(policy.GracePeriod), next.GraceEndsAt)
+	assert.Equal(t, 2, subscription.CyclesCount)
+
+	assert.False(t, next.IsGraceOver(late))
+	assert.True(t, next.IsGraceOver(next.GraceEndsAt))
+
+	next.MarkPaid("payment-1")
+	assert.Equal(t, SubscriptionCycleStatusPaid, next.Status)
+	assert.False(t, next.NextAttemptAt.Valid)
+
+	subscription.Cancel(SubscriptionCancelReasonUnpaid)
+	assert.True(t, subscription.IsCanceled())
+	assert.Equal(t, SubscriptionCancelReasonUnpaid, subscription.CancellationReason.String)
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceSource selects the exchange rate a token is priced with
+type PriceSource string
+
+const (
+	PriceSourceUSDT PriceSource = "usdt" // USDT to VND rate of the exchange rate provider
+	PriceSourceUSDC PriceSource = "usdc" // USDC to VND rate of the exchange rate provider
+	PriceSourcePeg  PriceSource = "peg"  // VND rate of the peg currency, e.g. USD to VND
+)
+
+// IsValid returns true if the source is a known price source
+func (s PriceSource) IsValid() bool {
+	switch s {
+	case PriceSourceUSDT, PriceSourceUSDC, PriceSourcePeg:
+		return true
+	}
+	return false
+}
+
+// DefaultPriceSource returns the price source of a token added from configuration
+// USDC has its own rate, other USD stablecoins are priced with the USDT rate
+func DefaultPriceSource(symbol string) PriceSource {
+	if symbol == "USDC" {
+		return PriceSourceUSDC
+	}
+	return PriceSourceUSDT
+}
+
+// MaxTokenDecimals is the largest number of decimals a token can declare
+const MaxTokenDecimals = 36
+
+var tokenSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+// Token is a token accepted on a chain, managed in the token registry
+type Token struct {
+	ID     string `json:"id" db:"id"`
+	Chain  Chain  `json:"chain" db:"chain" validate:"required,max=20"`
+	Symbol string `json:"symbol" db:"symbol" validate:"required,max=10"`
+
+	// SPL mint on Solana, contract address on BSC, TRON and EVM networks
+	// Empty until filled from the deployment configuration, listeners skip tokens without one
+	Address  string `json:"address" db:"address"`
+	Decimals int    `json:"decimals" db:"decimals"`
+
+	PegCurrency string      `json:"peg_currency" db:"peg_currency"`
+	PriceSource PriceSource `json:"price_source" db:"price_source"`
+	Enabled     bool        `json:"enabled" db:"enabled"`
+
+	// Bounds of the crypto amount of a payment, zero means unbounded
+	MinAmount decimal.Decimal `json:"min_amount" db:"min_amount"`
+	MaxAmount decimal.Decimal `json:"max_amount" db:"max_amount"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (Token) TableName() string {
+	return "tokens"
+}
+
+// Validate checks the token can be stored in the registry
+func (t *Token) Validate() error {
+	if t.Chain == "" || len(t.Chain) > 20 {
+		return fmt.Errorf("%w: chain is required", ErrInvalidToken)
+	}
+	if !tokenSymbolPattern.MatchString(t.Symbol) {
+		return fmt.Errorf("%w: symbol must be 2 to 10 uppercase letters or digits", ErrInvalidToken)
+	}
+	if len(t.Address) > 100 {
+		return fmt.Errorf("%w: address is too long", ErrInvalidToken)
+	}
+	if t.Decimals < 0 || t.Decimals > MaxTokenDecimals {
+		return fmt.Errorf("%w: decimals must be between 0 and %d", ErrInvalidToken, MaxTokenDecimals)
+	}
+	if len(t.PegCurrency) != 3 || strings.ToUpper(t.PegCurrency) != t.PegCurrency {
+		return fmt.Errorf("%w: peg_currency must be an uppercase ISO 4217 code", ErrInvalidToken)
+	}
+	if !t.PriceSource.IsValid() {
+		return fmt.Errorf("%w: unknown price_source %q", ErrInvalidToken, t.PriceSource)
+	}
+	if t.MinAmount.IsNegative() || t.MaxAmount.IsNegative() {
+		return fmt.Errorf("%w: amount bounds cannot be negative", ErrInvalidToken)
+	}
+	if t.MaxAmount.IsPositive() && t.MinAmount.GreaterThan(t.MaxAmount) {
+		return fmt.Errorf("%w: min_amount is greater than max_amount", ErrInvalidToken)
+	}
+	return nil
+}
+
+// CheckAmount returns ErrTokenAmountOutOfRange if a payment of amount is outside the token bounds
+func (t *Token) CheckAmount(amount decimal.Decimal) error {
+	if t.MinAmount.IsPositive() && amount.LessThan(t.MinAmount) {
+		return fmt.Errorf("%w: minimum is %s %s", ErrTokenAmountOutOfRange, t.MinAmount.String(), t.Symbol)
+	}
+	if t.MaxAmount.IsPositive() && amount.GreaterThan(t.MaxAmount) {
+		return fmt.Errorf("%w: maximum is %s %s", ErrTokenAmountOutOfRange, t.MaxAmount.String(), t.Symbol)
+	}
+	return nil
+}
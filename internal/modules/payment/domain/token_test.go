@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken_Validate(t *testing.T) {
+	token := &Token{
+		Chain:       ChainBSC,
+		Symbol:      "FDUSD",
+		Address:     "0xc5f0f7b66764F6ec8C8Dff7BA683102295E16409",
+		Decimals:    18,
+		PegCurrency: "USD",
+		PriceSource: PriceSourceUSDT,
+		MinAmount:   decimal.NewFromInt(1),
+		MaxAmount:   decimal.NewFromInt(50000),
+	}
+	assert.NoError(t, token.Validate())
+
+	invalid := []func(t *Token){
+		func(t *Token) { t.Chain = "" },
+		func(t *Token) { t.Symbol = "fdusd" },
+		func(t *Token) { t.Symbol = "A" },
+		func(t *Token) { t.Decimals = -1 },
+		func(t *Token) { t.Decimals = MaxTokenDecimals + 1 },
+		func(t *Token) { t.PegCurrency = "usd" },
+		func(t *Token) { t.PriceSource = "coingecko" },
+		func(t *Token) { t.MinAmount = decimal.NewFromInt(-1) },
+		func(t *Token) { t.MinAmount = decimal.NewFromInt(60000) },
+	}
+	for _, mutate := range invalid {
+		broken := *token
+		mutate(&broken)
+		assert.ErrorIs(t, broken.Validate(), ErrInvalidToken)
+	}
+
+	// A zero maximum leaves the token unbounded above
+	unbounded := *token
+	unbounded.MaxAmount = decimal.Zero
+	unbounded.MinAmount = decimal.NewFromInt(60000)
+	assert.NoError(t, unbounded.Validate())
+}
+
+func TestToken_CheckAmount(t *testing.T) {
+	token := &Token{Symbol: "USDT", MinAmount: decimal.NewFromInt(1), MaxAmount: decimal.NewFromInt(100)}
+
+	assert.NoError(t, token.CheckAmount(decimal.NewFromInt(1)))
+	assert.NoError(t, token.CheckAmount(decimal.NewFromInt(100)))
+	assert.ErrorIs(t, token.CheckAmount(decimal.RequireFromString("0.5")), ErrTokenAmountOutOfRange)
+	assert.ErrorIs(t, token.CheckAmount(decimal.RequireFromString("100.01")), ErrTokenAmountOutOfRange)
+
+	unbounded := &Token{Symbol: "USDT"}
+	assert.NoError(t, unbounded.CheckAmount(decimal.NewFromInt(1000000)))
+}
+
+func TestDefaultPriceSource(t *testing.T) {
+	assert.Equal(t, PriceSourceUSDC, DefaultPriceSource("USDC"))
+	assert.Equal(t, PriceSourceUSDT, DefaultPriceSource("USDT"))
+	assert.Equal(t, PriceSourceUSDT, DefaultPriceSource("PYUSD"))
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// AmountRoundingTolerance absorbs rounding differences between the quoted
+// crypto amount (6 decimals) and the on-chain transfer amount
+var AmountRoundingTolerance = decimal.NewFromFloat(0.000001)
+
+// TolerancePolicy defines how far the received amount may deviate from the
+// requested amount and still be treated as an exact payment.
+// Percentages are fractions of AmountCrypto (0.01 = 1%).
+type TolerancePolicy struct {
+	UnderpaymentPercentage decimal.Decimal
+	OverpaymentPercentage  decimal.Decimal
+}
+
+// DefaultTolerancePolicy returns a policy with no tolerance beyond rounding
+func DefaultTolerancePolicy() TolerancePolicy {
+	return TolerancePolicy{
+		UnderpaymentPercentage: decimal.Zero,
+		OverpaymentPercentage:  decimal.Zero,
+	}
+}
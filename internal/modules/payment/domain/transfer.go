@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaymentTransfer records a single on-chain transfer counted toward a payment.
+// A payment can have several transfers when an underpaid payment is topped up.
+type PaymentTransfer struct {
+	ID            string          `json:"id" db:"id"`
+	PaymentID     string          `json:"payment_id" db:"payment_id" validate:"required,uuid"`
+	TxHash        string          `json:"tx_hash" db:"tx_hash" validate:"required"`
+	FromAddress   sql.NullString  `json:"from_address,omitempty" db:"from_address"`
+	Amount        decimal.Decimal `json:"amount" db:"amount" validate:"required,gt=0"`
+	Currency      string          `json:"currency" db:"currency" validate:"required"`
+	Chain         Chain           `json:"chain" db:"chain" validate:"required"`
+	Confirmations int32           `json:"confirmations" db:"confirmations"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+
+	// Inclusion tracking by the confirmation watcher
+	FinalizedAt   sql.NullTime `json:"finalized_at,omitempty" db:"finalized_at"`
+	MissingChecks int32        `json:"missing_checks" db:"missing_checks"` // Consecutive checks that did not find the transaction
+	ReversedAt    sql.NullTime `json:"reversed_at,omitempty" db:"reversed_at"`
+}
+
+// IsFinalized returns true if the transfer reached chain finality and can no longer be reorged
+func (t *PaymentTransfer) IsFinalized() bool {
+	return t.FinalizedAt.Valid
+}
+
+func (PaymentTransfer) TableName() string {
+	return "payment_transfers"
+}
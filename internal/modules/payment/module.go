@@ -34,6 +34,7 @@ type Config struct {
 	ExchangeRateProvider paymentdomain.ExchangeRateProvider
 	ComplianceChecker    paymentdomain.ComplianceService
 	AMLService           paymentdomain.AMLService
+	LedgerService        paymentdomain.LedgerService // Optional: records overpayment surplus
 
 	// Service configuration
 	DefaultChain    string
@@ -62,6 +63,7 @@ func NewModule(cfg Config) (*Module, error) {
 
 	// Initialize repository (adapter layer)
 	repository := paymentrepo.NewPostgresPaymentRepository(cfg.DB)
+	transferRepository := paymentrepo.NewPostgresPaymentTransferRepository(cfg.DB)
 
 	// Initialize service (core business logic)
 	serviceConfig := paymentservice.PaymentServiceConfig{
@@ -71,10 +73,17 @@ func NewModule(cfg Config) (*Module, error) {
 		FeePercentage:   cfg.FeePercentage,
 		ExpiryMinutes:   cfg.ExpiryMinutes,
 		RedisClient:     cfg.RedisClient,
+		LedgerService:   cfg.LedgerService,
+		TokenRegistry: paymentservice.NewTokenRegistry(
+			paymentrepo.NewPostgresTokenRepository(cfg.DB),
+			paymentservice.DefaultTokenCacheTTL,
+			cfg.Logger,
+		),
 	}
 
 	service := paymentservice.NewPaymentService(
 		repository,
+		transferRepository,
 		cfg.MerchantReader,
 		cfg.ExchangeRateProvider,
 		cfg.ComplianceChecker,
@@ -88,6 +97,7 @@ func NewModule(cfg Config) (*Module, error) {
 		service,
 		nil, // ComplianceChecker - optional for now
 		nil, // ExchangeRateHTTPAdapter - optional for now
+		paymenthttp.QRCodeConfig{Tokens: serviceConfig.TokenRegistry},
 		cfg.BaseURL,
 	)
 
@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/shopspring/decimal"
 
@@ -12,15 +13,120 @@ import (
 type CreatePaymentRequest struct {
 	MerchantID  string
 	AmountVND   decimal.Decimal
-	Currency    string       // USDT, USDC, BUSD
+	Currency    string       // Token symbol from the registry, e.g. USDT
 	Chain       domain.Chain // solana, bsc
 	OrderID     string
 	Description string
 	CallbackURL string
 	// Proof of Ownership (optional, required for unhosted wallets > $1000)
-	FromAddress   string // Solana wallet address (Base58)
-	Signature     string // Base64-encoded signature proving wallet ownership
-	SignedMessage string // The message that was signed (for verification)
+	FromAddress   string // Payer wallet address, Base58 on Solana and 0x hex on EVM chains
+	Signature     string // Base64 Ed25519 signature on Solana, 0x hex ECDSA signature on EVM chains
+	SignedMessage string // The message that was signed, an ownership challenge with nonce and expiry on EVM chains
+	// Optional: quote whose locked rate the payment is created at, AmountVND must match the quote
+	QuoteID string
+	// Optional: price in another fiat currency, AmountVND is then its equivalent at the current rate
+	PricingCurrency string // ISO 4217, defaults to VND
+	PricingAmount   decimal.Decimal
+	// Set by the payment link, invoice and subscription services for the payments they create
+	PaymentLinkID       string
+	InvoiceID           string
+	SubscriptionCycleID string
+	// Optional: marketplace splits of the net amount among sellers, the creating merchant's split is its commission
+	Splits []domain.PaymentSplit
+	// Optional: ID of the payment, a retried request with the same ID returns the payment already created
+	PaymentID string
+}
+
+// CreateQuoteRequest contains parameters for locking an exchange rate before creating a payment
+type CreateQuoteRequest struct {
+	MerchantID string
+	AmountVND  decimal.Decimal
+	Chain      domain.Chain // Optional: quote a single chain
+	Currency   string       // Optional: quote a single token
+}
+
+// CreateOwnershipChallengeRequest contains parameters for issuing an ownership challenge to an EVM payer
+type CreateOwnershipChallengeRequest struct {
+	Chain         domain.Chain
+	WalletAddress string // 0x hex address the payer will sign with
+}
+
+// CreatePaymentLinkRequest contains parameters for creating a payment link
+type CreatePaymentLinkRequest struct {
+	MerchantID      string
+	AmountType      domain.PaymentLinkAmountType
+	PricingCurrency string          // ISO 4217, defaults to VND
+	Amount          decimal.Decimal // Required for fixed links
+	MinAmount       decimal.Decimal // Optional bounds of open links, zero is unbounded
+	MaxAmount       decimal.Decimal
+	Currency        string       // Optional: token of the payments
+	Chain           domain.Chain // Optional: chain of the payments
+	Description     string
+	Metadata        map[string]interface{}
+	MaxUses         int32      // Optional: zero is unlimited
+	ExpiresAt       *time.Time // Optional
+}
+
+// OpenPaymentLinkRequest contains parameters for creating a payment from a payment link
+type OpenPaymentLinkRequest struct {
+	PaymentLinkID string
+	Amount        decimal.Decimal // Required for open links, in the link pricing currency
+	Currency      string          // Used when the link does not set one
+	Chain         domain.Chain    // Used when the link does not set one
+}
+
+// CreateInvoiceRequest contains parameters for creating an invoice
+type CreateInvoiceRequest struct {
+	MerchantID      string
+	InvoiceNumber   string // Optional: generated when empty
+	CustomerName    string
+	CustomerEmail   string
+	Description     string
+	PricingCurrency string // ISO 4217, defaults to VND
+	LineItems       []domain.InvoiceLineItem
+	TaxRate         decimal.Decimal // e.g. 0.1 = 10%
+	DueDate         time.Time
+	Metadata        map[string]interface{}
+}
+
+// PayInvoiceRequest contains parameters for creating a payment for an invoice
+type PayInvoiceRequest struct {
+	InvoiceID string
+	Amount    decimal.Decimal // Optional: zero pays the amount due
+	Currency  string
+	Chain     domain.Chain
+}
+
+// CreateSubscriptionPlanRequest contains parameters for creating a subscription plan
+type CreateSubscriptionPlanRequest struct {
+	MerchantID      string
+	Name            string
+	Description     string
+	PricingCurrency string // ISO 4217, defaults to VND
+	Amount          decimal.Decimal
+	BillingInterval domain.SubscriptionInterval
+	IntervalCount   int          // Optional: defaults to 1
+	Currency        string       // Optional: token of the payments
+	Chain           domain.Chain // Optional: chain of the payments
+	Metadata        map[string]interface{}
+}
+
+// CreateSubscriptionRequest contains parameters for subscribing a customer to a plan
+type CreateSubscriptionRequest struct {
+	MerchantID        string
+	PlanID            string
+	CustomerName      string
+	CustomerEmail     string // Optional: receives the payment link of each cycle
+	CustomerReference string
+	StartAt           *time.Time // Optional: first cycle is issued now when empty
+	Metadata          map[string]interface{}
+}
+
+// PaymentBatchResult is the outcome of one payment request of a batch created within the request
+type PaymentBatchResult struct {
+	Index   int
+	Payment *domain.Payment // Set when the payment was created
+	Err     error           // Set when the request failed
 }
 
 // ConfirmPaymentRequest contains parameters for confirming a payment
@@ -30,17 +136,119 @@ type ConfirmPaymentRequest struct {
 	FromAddress   string
 	ActualAmount  decimal.Decimal
 	Confirmations int32
+
+	// Chain and token symbol the transfer was observed with, they must be the payment's
+	Chain    domain.Chain
+	Currency string
+
+	// Who reported the transfer, recorded in the status history. Defaults to the listener.
+	Actor   domain.PaymentActor
+	ActorID string
+
+	// Simulated transfers settle test payments, they are final at once and never verified on-chain.
+	// Test payments accept nothing else and live payments never accept them.
+	Simulated bool
+}
+
+// CreateRefundRequest contains parameters for refunding a payment
+type CreateRefundRequest struct {
+	PaymentID  string
+	MerchantID string
+	Amount     decimal.Decimal // Crypto amount to refund, zero refunds the remaining amount
+	Reason     string
 }
 
 // PaymentService defines the interface for payment business logic (Primary Port)
 type PaymentService interface {
 	CreatePayment(ctx context.Context, req CreatePaymentRequest) (*domain.Payment, error)
+	CreateQuote(ctx context.Context, req CreateQuoteRequest) (*domain.Quote, error)
+	// CreateOwnershipChallenge issues a nonce for an EVM payer to sign as proof of wallet ownership
+	CreateOwnershipChallenge(ctx context.Context, req CreateOwnershipChallengeRequest) (*domain.OwnershipChallenge, error)
 	GetPaymentStatus(ctx context.Context, paymentID string) (*domain.Payment, error)
 	GetPaymentByReference(ctx context.Context, reference string) (*domain.Payment, error)
 	ValidatePayment(ctx context.Context, paymentID string) error
 	ConfirmPayment(ctx context.Context, req ConfirmPaymentRequest) (*domain.Payment, error)
+	ListPaymentTransfers(ctx context.Context, paymentID string) ([]*domain.PaymentTransfer, error)
+	// ListSubscriptionCyclePayments lists the payments issued for a subscription billing cycle, oldest first
+	ListSubscriptionCyclePayments(ctx context.Context, cycleID string) ([]*domain.Payment, error)
+	// UsesDepositAddress returns true if the payment is paid to a deposit address of its own instead of a memo matched wallet
+	UsesDepositAddress(ctx context.Context, payment *domain.Payment) bool
+	// ListPaymentStatusHistory lists the status transitions of a payment, oldest first
+	ListPaymentStatusHistory(ctx context.Context, paymentID string) ([]*domain.PaymentStatusTransition, error)
 	ExpirePayment(ctx context.Context, paymentID string) error
 	FailPayment(ctx context.Context, paymentID, reason string) error
+	// CancelPayment cancels a payment of the merchant that was not paid yet
+	CancelPayment(ctx context.Context, paymentID, merchantID, reason string) (*domain.Payment, error)
+	// ExtendPayment re-quotes the exchange rate of a payment of the merchant and moves its expiry to now plus extension
+	// Payments created from a quote keep the quoted amount
+	ExtendPayment(ctx context.Context, paymentID, merchantID string, extension time.Duration) (*domain.Payment, error)
+	// SimulatePayment settles a test payment of the merchant through the live confirmation and expiry paths
+	SimulatePayment(ctx context.Context, paymentID, merchantID string, outcome domain.SimulationOutcome) (*domain.Payment, error)
 	ListPaymentsByMerchant(ctx context.Context, merchantID string, limit, offset int) ([]*domain.Payment, error)
+	// SearchPayments returns a page of the payments matching the filter with cursor pagination
+	SearchPayments(ctx context.Context, search domain.PaymentSearch) (*domain.PaymentPage, error)
 	GetExpiredPayments(ctx context.Context) ([]*domain.Payment, error)
 }
+
+// RefundService defines the interface for refund business logic (Primary Port)
+type RefundService interface {
+	CreateRefund(ctx context.Context, req CreateRefundRequest) (*domain.Refund, error)
+	GetRefund(ctx context.Context, refundID string) (*domain.Refund, error)
+	ListRefunds(ctx context.Context, paymentID, merchantID string) ([]*domain.Refund, error)
+	ProcessPendingRefunds(ctx context.Context) (int, error)
+	// ReconcileSendingRefunds resolves refunds whose broadcast was interrupted, without sending them again
+	ReconcileSendingRefunds(ctx context.Context) (int, error)
+	CheckSubmittedRefunds(ctx context.Context) (int, error)
+}
+
+// PaymentLinkService defines the interface for payment link business logic (Primary Port)
+type PaymentLinkService interface {
+	CreatePaymentLink(ctx context.Context, req CreatePaymentLinkRequest) (*domain.PaymentLink, error)
+	// GetPaymentLink returns a link of the merchant, any link when merchantID is empty
+	GetPaymentLink(ctx context.Context, linkID, merchantID string) (*domain.PaymentLink, error)
+	ListPaymentLinks(ctx context.Context, merchantID string, limit, offset int) ([]*domain.PaymentLink, int64, error)
+	DeactivatePaymentLink(ctx context.Context, linkID, merchantID string) (*domain.PaymentLink, error)
+	OpenPaymentLink(ctx context.Context, req OpenPaymentLinkRequest) (*domain.Payment, error)
+}
+
+// InvoiceService defines the interface for invoice business logic (Primary Port)
+type InvoiceService interface {
+	CreateInvoice(ctx context.Context, req CreateInvoiceRequest) (*domain.Invoice, error)
+	// GetInvoice returns an invoice of the merchant, any invoice when merchantID is empty
+	GetInvoice(ctx context.Context, invoiceID, merchantID string) (*domain.Invoice, error)
+	ListInvoices(ctx context.Context, merchantID string, status domain.InvoiceStatus, limit, offset int) ([]*domain.Invoice, int64, error)
+	VoidInvoice(ctx context.Context, invoiceID, merchantID string) (*domain.Invoice, error)
+	PayInvoice(ctx context.Context, req PayInvoiceRequest) (*domain.Payment, error)
+}
+
+// SubscriptionService defines the interface for subscription business logic (Primary Port)
+type SubscriptionService interface {
+	CreatePlan(ctx context.Context, req CreateSubscriptionPlanRequest) (*domain.SubscriptionPlan, error)
+	GetPlan(ctx context.Context, planID, merchantID string) (*domain.SubscriptionPlan, error)
+	ListPlans(ctx context.Context, merchantID string, limit, offset int) ([]*domain.SubscriptionPlan, int64, error)
+	ArchivePlan(ctx context.Context, planID, merchantID string) (*domain.SubscriptionPlan, error)
+	CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*domain.Subscription, error)
+	GetSubscription(ctx context.Context, subscriptionID, merchantID string) (*domain.Subscription, error)
+	ListSubscriptions(ctx context.Context, merchantID string, status domain.SubscriptionStatus, limit, offset int) ([]*domain.Subscription, int64, error)
+	ListCycles(ctx context.Context, subscriptionID, merchantID string) ([]*domain.SubscriptionCycle, error)
+	// CancelSubscription cancels now, or at the end of the current period when atPeriodEnd is set
+	CancelSubscription(ctx context.Context, subscriptionID, merchantID string, atPeriodEnd bool) (*domain.Subscription, error)
+	ProcessDueSubscriptions(ctx context.Context) (int, error)
+}
+
+// PaymentBatchService defines the interface for creating many payments at once (Primary Port)
+type PaymentBatchService interface {
+	// CreatePaymentBatch creates every payment within the call, each request independently of the others
+	CreatePaymentBatch(ctx context.Context, merchantID string, reqs []CreatePaymentRequest) ([]PaymentBatchResult, error)
+	// SubmitPaymentBatch stores the requests for the worker, which delivers a batch.completed webhook
+	SubmitPaymentBatch(ctx context.Context, merchantID string, reqs []CreatePaymentRequest) (*domain.PaymentBatch, error)
+	GetPaymentBatch(ctx context.Context, batchID, merchantID string) (*domain.PaymentBatch, error)
+	ProcessPaymentBatch(ctx context.Context, batchID string) error
+}
+
+// SolanaPayService defines the interface for Solana Pay transaction requests (Primary Port)
+type SolanaPayService interface {
+	GetTransactionRequestInfo(ctx context.Context, paymentID string) (*domain.SolanaPayInfo, error)
+	// CreateTransaction composes the unsigned transfer of the payment paid and signed by the account
+	CreateTransaction(ctx context.Context, paymentID, account string) (*domain.SolanaPayTransaction, error)
+}
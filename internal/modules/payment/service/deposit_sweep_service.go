@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+const (
+	// DepositSweepBatchSize is the maximum number of deposit addresses swept per chain and run
+	DepositSweepBatchSize = 50
+)
+
+// DepositSweepService provisions per-payment deposit addresses and consolidates them into the hot wallets
+type DepositSweepService struct {
+	depositAddressRepo domain.DepositAddressRepository
+	sweeper            domain.DepositSweeper
+	provisioner        domain.DepositAddressGenerator
+	finalityWindow     time.Duration
+	logger             *logrus.Logger
+}
+
+// NewDepositSweepService creates a new deposit sweep service
+// Payments are swept once their transfers are finalized or older than finalityWindow,
+// the reorg watch window of the confirmation watcher (DefaultReorgWatchWindow when zero)
+func NewDepositSweepService(
+	depositAddressRepo domain.DepositAddressRepository,
+	sweeper domain.DepositSweeper,
+	provisioner domain.DepositAddressGenerator,
+	finalityWindow time.Duration,
+	logger *logrus.Logger,
+) *DepositSweepService {
+	if finalityWindow <= 0 {
+		finalityWindow = DefaultReorgWatchWindow
+	}
+
+	return &DepositSweepService{
+		depositAddressRepo: depositAddressRepo,
+		sweeper:            sweeper,
+		provisioner:        provisioner,
+		finalityWindow:     finalityWindow,
+		logger:             logger,
+	}
+}
+
+// ProvisionDepositAddress creates the on-chain account of the payment's pending deposit address
+// and activates the address once the creation is confirmed, so the listeners start watching it.
+// Addresses that are no longer pending are left alone, a failed attempt is retried by the queue
+func (s *DepositSweepService) ProvisionDepositAddress(ctx context.Context, paymentID string) error {
+	if s.provisioner == nil {
+		return domain.ErrDepositAddressNotSupported
+	}
+
+	address, err := s.depositAddressRepo.GetByPaymentID(paymentID)
+	if err != nil {
+		return fmt.Errorf("failed to get deposit address: %w", err)
+	}
+	if address.Status != domain.DepositAddressStatusPending {
+		return nil
+	}
+
+	if err := s.provisioner.ProvisionDepositAddress(ctx, address); err != nil {
+		return err
+	}
+
+	address.MarkActive()
+	if err := s.depositAddressRepo.Update(address); err != nil {
+		return fmt.Errorf("failed to activate deposit address: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id": address.PaymentID,
+		"address":    address.Address,
+		"chain":      address.Chain,
+	}).Info("Deposit address provisioned")
+
+	return nil
+}
+
+// SweepDepositAddresses sweeps the deposit addresses of payments that no longer accept transfers
+// Addresses of open payments are left alone so top-ups keep being matched, as are those of payments
+// whose transfers may still be reorged out. Returns the number of addresses swept
+func (s *DepositSweepService) SweepDepositAddresses(ctx context.Context) (int, error) {
+	swept := 0
+	for _, chain := range []domain.Chain{domain.ChainSolana, domain.ChainBSC} {
+		addresses, err := s.depositAddressRepo.ListSweepable(chain, time.Now().Add(-s.finalityWindow), DepositSweepBatchSize)
+		if err != nil {
+			return swept, fmt.Errorf("failed to list sweepable %s deposit addresses: %w", chain, err)
+		}
+
+		// Claimed before anything is sent, an address left sweeping is never swept (nor topped up) twice
+		claimed := make([]*domain.DepositAddress, 0, len(addresses))
+		previousStatuses := make(map[string]domain.DepositAddressStatus, len(addresses))
+		for _, address := range addresses {
+			previousStatus := address.Status
+			ok, err := s.depositAddressRepo.ClaimForSweeping(address)
+			if err != nil {
+				s.logger.WithError(err).WithField("address", address.Address).Error("Failed to claim deposit address for sweeping")
+				continue
+			}
+			if ok {
+				claimed = append(claimed, address)
+				previousStatuses[address.ID] = previousStatus
+			}
+		}
+		if len(claimed) == 0 {
+			continue
+		}
+
+		// The whole batch is prepared at once, e.g. its gas top-ups are confirmed together
+		if err := s.sweeper.PrepareSweep(ctx, chain, claimed); err != nil {
+			sweepError := err.Error()
+			if errors.Is(err, domain.ErrDepositAddressNotSupported) {
+				sweepError = ""
+				s.logger.WithField("chain", chain).Warn("Deposit sweeping not configured for chain, skipping")
+			} else {
+				s.logger.WithError(err).WithField("chain", chain).Error("Failed to prepare deposit sweep")
+			}
+			for _, address := range claimed {
+				s.releaseDepositAddress(address, previousStatuses[address.ID], sweepError)
+			}
+			continue
+		}
+
+		for i, address := range claimed {
+			txHash, amount, err := s.sweeper.SweepDepositAddress(ctx, address)
+			if errors.Is(err, domain.ErrDepositAddressNotSupported) {
+				for _, unswept := range claimed[i:] {
+					s.releaseDepositAddress(unswept, previousStatuses[unswept.ID], "")
+				}
+				s.logger.WithField("chain", chain).Warn("Deposit sweeping not configured for chain, skipping")
+				break
+			}
+			if err != nil {
+				s.logger.WithFields(logrus.Fields{
+					"payment_id": address.PaymentID,
+					"address":    address.Address,
+					"chain":      chain,
+					"error":      err.Error(),
+				}).Error("Failed to sweep deposit address")
+
+				s.releaseDepositAddress(address, previousStatuses[address.ID], err.Error())
+				continue
+			}
+
+			address.MarkSwept(txHash, amount)
+			if err := s.depositAddressRepo.Update(address); err != nil {
+				// The sweep is already broadcast, the address stays sweeping until an operator checks it
+				s.logger.WithFields(logrus.Fields{
+					"address": address.Address,
+					"tx_hash": txHash,
+					"error":   err.Error(),
+				}).Error("Deposit address swept but status update failed, left sweeping")
+				continue
+			}
+
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": address.PaymentID,
+				"address":    address.Address,
+				"chain":      chain,
+				"amount":     amount.String(),
+				"tx_hash":    txHash,
+			}).Info("Deposit address swept into hot wallet")
+			swept++
+		}
+	}
+
+	return swept, nil
+}
+
+// releaseDepositAddress returns an address whose sweep failed to the status it was claimed from,
+// with the error, so the next run tries again
+func (s *DepositSweepService) releaseDepositAddress(address *domain.DepositAddress, status domain.DepositAddressStatus, sweepError string) {
+	address.Status = status
+	if sweepError != "" {
+		address.LastSweepError = sql.NullString{String: sweepError, Valid: true}
+	}
+	if err := s.depositAddressRepo.Update(address); err != nil {
+		s.logger.WithError(err).WithField("address", address.Address).Warn("Failed to release deposit address after failed sweep")
+	}
+}
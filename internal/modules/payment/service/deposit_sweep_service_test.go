@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// memDepositAddressRepository keeps deposit addresses in memory, every active or re-swept solana address is sweepable
+type memDepositAddressRepository struct {
+	domain.DepositAddressRepository
+	addresses       map[string]*domain.DepositAddress
+	updateErr       error
+	finalizedBefore time.Time
+}
+
+func newMemDepositAddressRepository(addresses ...*domain.DepositAddress) *memDepositAddressRepository {
+	repo := &memDepositAddressRepository{addresses: make(map[string]*domain.DepositAddress)}
+	for _, address := range addresses {
+		stored := *address
+		repo.addresses[address.ID] = &stored
+	}
+	return repo
+}
+
+func (r *memDepositAddressRepository) ListSweepable(chain domain.Chain, finalizedBefore time.Time, limit int) ([]*domain.DepositAddress, error) {
+	r.finalizedBefore = finalizedBefore
+	var addresses []*domain.DepositAddress
+	for _, address := range r.addresses {
+		if address.Chain == chain && address.Status == domain.DepositAddressStatusActive {
+			copied := *address
+			addresses = append(addresses, &copied)
+		}
+	}
+	return addresses, nil
+}
+
+func (r *memDepositAddressRepository) ClaimForSweeping(address *domain.DepositAddress) (bool, error) {
+	stored := r.addresses[address.ID]
+	if stored.Status != address.Status {
+		return false, nil
+	}
+	stored.Status = domain.DepositAddressStatusSweeping
+	address.Status = domain.DepositAddressStatusSweeping
+	return true, nil
+}
+
+func (r *memDepositAddressRepository) GetByPaymentID(paymentID string) (*domain.DepositAddress, error) {
+	for _, address := range r.addresses {
+		if address.PaymentID == paymentID {
+			copied := *address
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrDepositAddressNotFound
+}
+
+func (r *memDepositAddressRepository) Update(address *domain.DepositAddress) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	stored := *address
+	r.addresses[address.ID] = &stored
+	return nil
+}
+
+// stubDepositSweeper sweeps a fixed amount and records the status each address had when it was swept
+type stubDepositSweeper struct {
+	err        error
+	prepareErr error
+	prepared   []int
+	statuses   []domain.DepositAddressStatus
+}
+
+func (s *stubDepositSweeper) PrepareSweep(ctx context.Context, chain domain.Chain, addresses []*domain.DepositAddress) error {
+	s.prepared = append(s.prepared, len(addresses))
+	return s.prepareErr
+}
+
+func (s *stubDepositSweeper) SweepDepositAddress(ctx context.Context, address *domain.DepositAddress) (string, decimal.Decimal, error) {
+	s.statuses = append(s.statuses, address.Status)
+	if s.err != nil {
+		return "", decimal.Zero, s.err
+	}
+	return "sweep-tx", decimal.NewFromInt(100), nil
+}
+
+// stubDepositProvisioner records the addresses it provisions
+type stubDepositProvisioner struct {
+	domain.DepositAddressGenerator
+	err         error
+	provisioned []string
+}
+
+func (p *stubDepositProvisioner) ProvisionDepositAddress(ctx context.Context, address *domain.DepositAddress) error {
+	p.provisioned = append(p.provisioned, address.Address)
+	return p.err
+}
+
+func newSweepableAddress() *domain.DepositAddress {
+	return &domain.DepositAddress{
+		ID:        "address-1",
+		PaymentID: "payment-1",
+		Chain:     domain.ChainSolana,
+		Currency:  "USDT",
+		Address:   "deposit-1",
+		Status:    domain.DepositAddressStatusActive,
+	}
+}
+
+func TestSweepDepositAddresses_ClaimsBeforeSweeping(t *testing.T) {
+	repo := newMemDepositAddressRepository(newSweepableAddress())
+	sweeper := &stubDepositSweeper{}
+	service := NewDepositSweepService(repo, sweeper, nil, 0, newTestLogger())
+
+	swept, err := service.SweepDepositAddresses(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, swept)
+	assert.Equal(t, []domain.DepositAddressStatus{domain.DepositAddressStatusSweeping}, sweeper.statuses)
+	assert.Equal(t, domain.DepositAddressStatusSwept, repo.addresses["address-1"].Status)
+	assert.WithinDuration(t, time.Now().Add(-DefaultReorgWatchWindow), repo.finalizedBefore, time.Minute)
+}
+
+func TestSweepDepositAddresses_FailedStatusUpdateIsNotSweptAgain(t *testing.T) {
+	repo := newMemDepositAddressRepository(newSweepableAddress())
+	repo.updateErr = errors.New("database unavailable")
+	sweeper := &stubDepositSweeper{}
+	service := NewDepositSweepService(repo, sweeper, nil, time.Hour, newTestLogger())
+
+	_, err := service.SweepDepositAddresses(context.Background())
+	require.NoError(t, err)
+	_, err = service.SweepDepositAddresses(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, sweeper.statuses, 1)
+	assert.Equal(t, domain.DepositAddressStatusSweeping, repo.addresses["address-1"].Status)
+}
+
+func TestSweepDepositAddresses_FailedSweepIsRetried(t *testing.T) {
+	repo := newMemDepositAddressRepository(newSweepableAddress())
+	sweeper := &stubDepositSweeper{err: errors.New("rpc unavailable")}
+	service := NewDepositSweepService(repo, sweeper, nil, time.Hour, newTestLogger())
+
+	swept, err := service.SweepDepositAddresses(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, swept)
+	stored := repo.addresses["address-1"]
+	assert.Equal(t, domain.DepositAddressStatusActive, stored.Status)
+	assert.Equal(t, "rpc unavailable", stored.LastSweepError.String)
+}
+
+func TestSweepDepositAddresses_PreparesClaimedBatchOnce(t *testing.T) {
+	second := newSweepableAddress()
+	second.ID = "address-2"
+	second.PaymentID = "payment-2"
+	second.Address = "deposit-2"
+	repo := newMemDepositAddressRepository(newSweepableAddress(), second)
+	sweeper := &stubDepositSweeper{}
+	service := NewDepositSweepService(repo, sweeper, nil, time.Hour, newTestLogger())
+
+	swept, err := service.SweepDepositAddresses(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, swept)
+	assert.Equal(t, []int{2}, sweeper.prepared)
+}
+
+func TestSweepDepositAddresses_FailedPreparationReleasesBatch(t *testing.T) {
+	repo := newMemDepositAddressRepository(newSweepableAddress())
+	sweeper := &stubDepositSweeper{prepareErr: errors.New("gas top-up not confirmed")}
+	service := NewDepositSweepService(repo, sweeper, nil, time.Hour, newTestLogger())
+
+	swept, err := service.SweepDepositAddresses(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, swept)
+	assert.Empty(t, sweeper.statuses)
+	stored := repo.addresses["address-1"]
+	assert.Equal(t, domain.DepositAddressStatusActive, stored.Status)
+	assert.Equal(t, "gas top-up not confirmed", stored.LastSweepError.String)
+}
+
+func TestProvisionDepositAddress_ActivatesOnceCreated(t *testing.T) {
+	address := newSweepableAddress()
+	address.Status = domain.DepositAddressStatusPending
+	repo := newMemDepositAddressRepository(address)
+	provisioner := &stubDepositProvisioner{}
+	service := NewDepositSweepService(repo, &stubDepositSweeper{}, provisioner, time.Hour, newTestLogger())
+
+	require.NoError(t, service.ProvisionDepositAddress(context.Background(), "payment-1"))
+	// A retried job finds the address active and sends nothing
+	require.NoError(t, service.ProvisionDepositAddress(context.Background(), "payment-1"))
+
+	assert.Equal(t, []string{"deposit-1"}, provisioner.provisioned)
+	assert.Equal(t, domain.DepositAddressStatusActive, repo.addresses["address-1"].Status)
+}
+
+func TestProvisionDepositAddress_StaysPendingWhenCreationFails(t *testing.T) {
+	address := newSweepableAddress()
+	address.Status = domain.DepositAddressStatusPending
+	repo := newMemDepositAddressRepository(address)
+	provisioner := &stubDepositProvisioner{err: errors.New("transaction not finalized")}
+	service := NewDepositSweepService(repo, &stubDepositSweeper{}, provisioner, time.Hour, newTestLogger())
+
+	err := service.ProvisionDepositAddress(context.Background(), "payment-1")
+
+	require.Error(t, err)
+	assert.Equal(t, domain.DepositAddressStatusPending, repo.addresses["address-1"].Status)
+}
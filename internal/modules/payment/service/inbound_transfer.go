@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// ListInboundTransfers lists transfers received on watched addresses, all of them when status is empty
+func (s *PaymentService) ListInboundTransfers(ctx context.Context, status domain.InboundTransferStatus, limit, offset int) ([]*domain.InboundTransfer, int64, error) {
+	if s.inboundTransferRepo == nil {
+		return nil, 0, domain.ErrInboundTransfersNotConfigured
+	}
+
+	transfers, err := s.inboundTransferRepo.List(status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list inbound transfers: %w", err)
+	}
+
+	total, err := s.inboundTransferRepo.Count(status)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count inbound transfers: %w", err)
+	}
+
+	return transfers, total, nil
+}
+
+// GetInboundTransfer retrieves a transfer received on a watched address
+func (s *PaymentService) GetInboundTransfer(ctx context.Context, transferID string) (*domain.InboundTransfer, error) {
+	if s.inboundTransferRepo == nil {
+		return nil, domain.ErrInboundTransfersNotConfigured
+	}
+
+	return s.inboundTransferRepo.GetByID(transferID)
+}
+
+// AttachInboundTransfer counts an unmatched transfer toward a payment chosen by an operator
+// The transfer goes through ConfirmPayment like any transfer seen by a listener, so tolerance,
+// confirmation depth, late payment and ledger handling all apply.
+func (s *PaymentService) AttachInboundTransfer(ctx context.Context, transferID, paymentID, resolvedBy, note string) (*domain.InboundTransfer, *domain.Payment, error) {
+	transfer, err := s.getUnmatchedTransfer(transferID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentNotFound) {
+			return nil, nil, domain.ErrPaymentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if payment.Chain != transfer.Chain || payment.Currency != transfer.Currency {
+		return nil, nil, domain.ErrInboundTransferMismatch
+	}
+
+	// ConfirmPayment counts a tx hash once per chain, attaching twice cannot pay twice
+	confirmed, err := s.ConfirmPayment(ctx, port.ConfirmPaymentRequest{
+		PaymentID:     payment.ID,
+		TxHash:        transfer.TxHash,
+		FromAddress:   transfer.FromAddress.String,
+		ActualAmount:  transfer.Amount,
+		Confirmations: 1,
+		Chain:         transfer.Chain,
+		Currency:      transfer.Currency,
+		Actor:         domain.PaymentActorAdmin,
+		ActorID:       resolvedBy,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to confirm payment: %w", err)
+	}
+
+	transfer.PaymentID = sql.NullString{String: payment.ID, Valid: true}
+	transfer.Resolve(domain.InboundTransferStatusAttached, resolvedBy, note)
+	if err := s.inboundTransferRepo.Resolve(transfer); err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve inbound transfer: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"transfer_id": transfer.ID,
+		"payment_id":  payment.ID,
+		"tx_hash":     transfer.TxHash,
+		"amount":      transfer.Amount.String(),
+		"status":      confirmed.Status,
+		"resolved_by": resolvedBy,
+	}).Info("Unmatched transfer attached to payment")
+
+	return transfer, confirmed, nil
+}
+
+// MarkInboundTransferForRefund takes an unmatched transfer out of the queue to be sent back to the sender
+// The transfer stays refund_pending until CompleteInboundTransferRefund records the refund transaction.
+func (s *PaymentService) MarkInboundTransferForRefund(ctx context.Context, transferID, resolvedBy, note string) (*domain.InboundTransfer, error) {
+	return s.resolveInboundTransfer(transferID, domain.InboundTransferStatusRefundPending, resolvedBy, note)
+}
+
+// CompleteInboundTransferRefund records the transaction that sent a transfer marked for refund back to the sender
+// When a transaction verifier is configured, the refund must be included on chain before it is recorded.
+func (s *PaymentService) CompleteInboundTransferRefund(ctx context.Context, transferID, refundTxHash, refundedBy string) (*domain.InboundTransfer, error) {
+	if s.inboundTransferRepo == nil {
+		return nil, domain.ErrInboundTransfersNotConfigured
+	}
+
+	transfer, err := s.inboundTransferRepo.GetByID(transferID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInboundTransferNotFound) {
+			return nil, domain.ErrInboundTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get inbound transfer: %w", err)
+	}
+
+	if !transfer.IsRefundPending() {
+		return nil, domain.ErrInboundTransferNotRefundPending
+	}
+
+	if s.txVerifier != nil {
+		inclusion, err := s.txVerifier.VerifyTransaction(ctx, transfer.Chain, refundTxHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify refund transaction: %w", err)
+		}
+		if !inclusion.Included {
+			return nil, domain.ErrInboundTransferRefundNotIncluded
+		}
+	}
+
+	transfer.MarkRefunded(refundTxHash, refundedBy)
+	if err := s.inboundTransferRepo.MarkRefunded(transfer); err != nil {
+		return nil, fmt.Errorf("failed to mark inbound transfer as refunded: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"transfer_id":    transfer.ID,
+		"tx_hash":        transfer.TxHash,
+		"refund_tx_hash": refundTxHash,
+		"from_address":   transfer.FromAddress.String,
+		"amount":         transfer.Amount.String(),
+		"currency":       transfer.Currency,
+		"refunded_by":    refundedBy,
+	}).Info("Unmatched transfer refunded")
+
+	return transfer, nil
+}
+
+// MarkInboundTransferAsTreasury takes an unmatched transfer out of the queue as a treasury deposit
+func (s *PaymentService) MarkInboundTransferAsTreasury(ctx context.Context, transferID, resolvedBy, note string) (*domain.InboundTransfer, error) {
+	return s.resolveInboundTransfer(transferID, domain.InboundTransferStatusTreasury, resolvedBy, note)
+}
+
+// resolveInboundTransfer records an operator resolution that does not involve a payment
+func (s *PaymentService) resolveInboundTransfer(transferID string, status domain.InboundTransferStatus, resolvedBy, note string) (*domain.InboundTransfer, error) {
+	transfer, err := s.getUnmatchedTransfer(transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.Resolve(status, resolvedBy, note)
+	if err := s.inboundTransferRepo.Resolve(transfer); err != nil {
+		return nil, fmt.Errorf("failed to resolve inbound transfer: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"transfer_id": transfer.ID,
+		"tx_hash":     transfer.TxHash,
+		"amount":      transfer.Amount.String(),
+		"currency":    transfer.Currency,
+		"status":      status,
+		"resolved_by": resolvedBy,
+	}).Info("Unmatched transfer resolved")
+
+	return transfer, nil
+}
+
+// getUnmatchedTransfer loads a transfer that is still waiting for an operator
+func (s *PaymentService) getUnmatchedTransfer(transferID string) (*domain.InboundTransfer, error) {
+	if s.inboundTransferRepo == nil {
+		return nil, domain.ErrInboundTransfersNotConfigured
+	}
+
+	transfer, err := s.inboundTransferRepo.GetByID(transferID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInboundTransferNotFound) {
+			return nil, domain.ErrInboundTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get inbound transfer: %w", err)
+	}
+
+	if !transfer.IsUnmatched() {
+		return nil, domain.ErrInboundTransferNotUnmatched
+	}
+
+	return transfer, nil
+}
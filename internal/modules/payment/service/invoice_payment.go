@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// recordInvoicePayment adds a completed payment to the amount paid of its invoice (non-fatal)
+func (s *PaymentService) recordInvoicePayment(ctx context.Context, payment *domain.Payment) {
+	s.applyInvoicePayment(ctx, payment, payment.PricingAmount)
+}
+
+// reverseInvoicePayment removes a reversed payment from the amount paid of its invoice (non-fatal)
+func (s *PaymentService) reverseInvoicePayment(ctx context.Context, payment *domain.Payment) {
+	s.applyInvoicePayment(ctx, payment, payment.PricingAmount.Neg())
+}
+
+// applyInvoicePayment updates the invoice of a payment and notifies the merchant of the new balance
+// Invoice payments are priced in the invoice currency, so the pricing amount is what the payment paid
+func (s *PaymentService) applyInvoicePayment(ctx context.Context, payment *domain.Payment, amount decimal.Decimal) {
+	if !payment.InvoiceID.Valid || s.invoiceRepo == nil {
+		return
+	}
+
+	invoice, statusChanged, err := s.invoiceRepo.ApplyPayment(payment.InvoiceID.String, amount)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"invoice_id": payment.InvoiceID.String,
+			"amount":     amount.String(),
+			"error":      err.Error(),
+		}).Error("Failed to apply payment to invoice")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":  payment.ID,
+		"invoice_id":  invoice.ID,
+		"amount":      amount.String(),
+		"amount_paid": invoice.AmountPaid.String(),
+		"status":      invoice.Status,
+	}).Info("Invoice amount paid updated")
+
+	var event string
+	switch invoice.Status {
+	case domain.InvoiceStatusPaid:
+		// Only the payment that settled the invoice announces it
+		if !statusChanged {
+			return
+		}
+		event = domain.InvoiceEventPaid
+	case domain.InvoiceStatusPartiallyPaid:
+		event = domain.InvoiceEventPartiallyPaid
+	default:
+		return
+	}
+
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	data := invoiceWebhookData(invoice)
+	data["payment_id"] = payment.ID
+	if err := s.webhookPublisher.PublishWebhook(ctx, invoice.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"invoice_id": invoice.ID,
+			"event":      event,
+			"error":      err.Error(),
+		}).Warn("Failed to publish invoice webhook")
+	}
+}
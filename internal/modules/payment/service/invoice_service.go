@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// InvoiceService manages merchant invoices and creates the payments that pay them
+// The amount paid is tracked by PaymentService as the payments complete
+type InvoiceService struct {
+	paymentService   port.PaymentService
+	invoiceRepo      domain.InvoiceRepository
+	webhookPublisher domain.WebhookPublisher // For invoice.* merchant webhooks
+	logger           *logrus.Logger
+}
+
+// InvoiceServiceConfig contains optional dependencies for InvoiceService
+type InvoiceServiceConfig struct {
+	WebhookPublisher domain.WebhookPublisher // Optional: for invoice.* webhooks
+}
+
+// NewInvoiceService creates a new invoice service
+func NewInvoiceService(
+	paymentService port.PaymentService,
+	invoiceRepo domain.InvoiceRepository,
+	config InvoiceServiceConfig,
+	logger *logrus.Logger,
+) *InvoiceService {
+	return &InvoiceService{
+		paymentService:   paymentService,
+		invoiceRepo:      invoiceRepo,
+		webhookPublisher: config.WebhookPublisher,
+		logger:           logger,
+	}
+}
+
+// CreateInvoice creates an open invoice, the totals are computed from the line items and tax rate
+func (s *InvoiceService) CreateInvoice(ctx context.Context, req port.CreateInvoiceRequest) (*domain.Invoice, error) {
+	currency := domain.NormalizePricingCurrency(req.PricingCurrency)
+	if !domain.IsSupportedPricingCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedPricingCurrency, currency)
+	}
+	if req.DueDate.IsZero() {
+		return nil, fmt.Errorf("%w: due_date is required", domain.ErrInvalidAmount)
+	}
+
+	invoiceNumber := strings.TrimSpace(req.InvoiceNumber)
+	if invoiceNumber == "" {
+		invoiceNumber = generateInvoiceNumber()
+	}
+
+	now := time.Now()
+	invoice := &domain.Invoice{
+		ID:              uuid.New().String(),
+		MerchantID:      req.MerchantID,
+		InvoiceNumber:   invoiceNumber,
+		PricingCurrency: currency,
+		LineItems:       domain.InvoiceLineItems(req.LineItems),
+		TaxRate:         req.TaxRate,
+		AmountPaid:      decimal.Zero,
+		DueDate:         req.DueDate,
+		Status:          domain.InvoiceStatusOpen,
+		Metadata:        req.Metadata,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if req.CustomerName != "" {
+		invoice.CustomerName = sql.NullString{String: req.CustomerName, Valid: true}
+	}
+	if req.CustomerEmail != "" {
+		invoice.CustomerEmail = sql.NullString{String: req.CustomerEmail, Valid: true}
+	}
+	if req.Description != "" {
+		invoice.Description = sql.NullString{String: req.Description, Valid: true}
+	}
+
+	if err := invoice.CalculateTotals(); err != nil {
+		return nil, err
+	}
+
+	if err := s.invoiceRepo.Create(invoice); err != nil {
+		if errors.Is(err, domain.ErrInvoiceNumberExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"invoice_id":     invoice.ID,
+		"merchant_id":    invoice.MerchantID,
+		"invoice_number": invoice.InvoiceNumber,
+		"total_amount":   invoice.TotalAmount.String(),
+		"currency":       invoice.PricingCurrency,
+	}).Info("Invoice created")
+
+	s.publishInvoiceWebhook(ctx, domain.InvoiceEventCreated, invoice, nil)
+
+	return invoice, nil
+}
+
+// GetInvoice returns an invoice of the merchant, any invoice when merchantID is empty
+func (s *InvoiceService) GetInvoice(ctx context.Context, invoiceID, merchantID string) (*domain.Invoice, error) {
+	invoice, err := s.invoiceRepo.GetByID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not reveal invoices of other merchants
+	if merchantID != "" && invoice.MerchantID != merchantID {
+		return nil, domain.ErrInvoiceNotFound
+	}
+
+	return invoice, nil
+}
+
+// ListInvoices lists the invoices of a merchant by status, newest first, with their total count
+func (s *InvoiceService) ListInvoices(ctx context.Context, merchantID string, status domain.InvoiceStatus, limit, offset int) ([]*domain.Invoice, int64, error) {
+	invoices, err := s.invoiceRepo.ListByMerchant(merchantID, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	total, err := s.invoiceRepo.CountByMerchant(merchantID, status)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count invoices: %w", err)
+	}
+
+	return invoices, total, nil
+}
+
+// VoidInvoice cancels an invoice that is not fully paid, no new payments can be created for it
+// Amounts already paid stay with the merchant and can be refunded per payment
+func (s *InvoiceService) VoidInvoice(ctx context.Context, invoiceID, merchantID string) (*domain.Invoice, error) {
+	invoice, err := s.GetInvoice(ctx, invoiceID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch invoice.Status {
+	case domain.InvoiceStatusVoid:
+		return invoice, nil
+	case domain.InvoiceStatusPaid:
+		return nil, domain.ErrInvoiceAlreadyPaid
+	}
+
+	invoice.Status = domain.InvoiceStatusVoid
+	invoice.VoidedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.invoiceRepo.Update(invoice); err != nil {
+		return nil, fmt.Errorf("failed to void invoice: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"invoice_id":  invoice.ID,
+		"merchant_id": invoice.MerchantID,
+		"amount_paid": invoice.AmountPaid.String(),
+	}).Info("Invoice voided")
+
+	s.publishInvoiceWebhook(ctx, domain.InvoiceEventVoided, invoice, nil)
+
+	return invoice, nil
+}
+
+// PayInvoice creates a payment for an invoice in its pricing currency
+// A zero amount pays what is due, smaller amounts pay the invoice in installments
+func (s *InvoiceService) PayInvoice(ctx context.Context, req port.PayInvoiceRequest) (*domain.Payment, error) {
+	invoice, err := s.invoiceRepo.GetByID(req.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := invoice.CheckPayable(); err != nil {
+		return nil, err
+	}
+
+	due := invoice.AmountDue()
+	amount := req.Amount.Round(2)
+	if amount.IsZero() {
+		amount = due
+	}
+	if amount.LessThanOrEqual(decimal.Zero) || amount.GreaterThan(due) {
+		return nil, fmt.Errorf("%w: the amount due is %s %s", domain.ErrInvalidAmount, due, invoice.PricingCurrency)
+	}
+
+	description := fmt.Sprintf("Invoice %s", invoice.InvoiceNumber)
+	if invoice.Description.Valid {
+		description = fmt.Sprintf("%s: %s", description, invoice.Description.String)
+	}
+
+	payment, err := s.paymentService.CreatePayment(ctx, port.CreatePaymentRequest{
+		MerchantID:      invoice.MerchantID,
+		Currency:        req.Currency,
+		Chain:           req.Chain,
+		OrderID:         invoice.InvoiceNumber,
+		Description:     description,
+		PricingCurrency: invoice.PricingCurrency,
+		PricingAmount:   amount,
+		InvoiceID:       invoice.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"invoice_id":     invoice.ID,
+		"payment_id":     payment.ID,
+		"pricing_amount": payment.PricingAmount.String(),
+		"amount_due":     due.String(),
+	}).Info("Payment created for invoice")
+
+	s.publishInvoiceWebhook(ctx, domain.InvoiceEventPaymentCreated, invoice, payment)
+
+	return payment, nil
+}
+
+// publishInvoiceWebhook notifies the merchant of an invoice event (non-fatal)
+func (s *InvoiceService) publishInvoiceWebhook(ctx context.Context, event string, invoice *domain.Invoice, payment *domain.Payment) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	data := invoiceWebhookData(invoice)
+	if payment != nil {
+		data["payment_id"] = payment.ID
+		data["pricing_amount"] = payment.PricingAmount.String()
+		data["amount_crypto"] = payment.AmountCrypto.String()
+		data["currency"] = payment.Currency
+		data["chain"] = string(payment.Chain)
+	}
+
+	if err := s.webhookPublisher.PublishWebhook(ctx, invoice.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"invoice_id": invoice.ID,
+			"event":      event,
+			"error":      err.Error(),
+		}).Warn("Failed to publish invoice webhook")
+	}
+}
+
+// invoiceWebhookData returns the invoice fields sent with every invoice.* webhook
+func invoiceWebhookData(invoice *domain.Invoice) map[string]interface{} {
+	return map[string]interface{}{
+		"invoice_id":       invoice.ID,
+		"invoice_number":   invoice.InvoiceNumber,
+		"status":           string(invoice.Status),
+		"pricing_currency": invoice.PricingCurrency,
+		"total_amount":     invoice.TotalAmount.String(),
+		"amount_paid":      invoice.AmountPaid.String(),
+		"amount_due":       invoice.AmountDue().String(),
+		"due_date":         invoice.DueDate.Format(time.RFC3339),
+	}
+}
+
+// generateInvoiceNumber returns an invoice number for merchants that do not number their invoices
+func generateInvoiceNumber() string {
+	suffix := strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", "")[:8])
+	return fmt.Sprintf("INV-%s-%s", time.Now().Format("20060102"), suffix)
+}
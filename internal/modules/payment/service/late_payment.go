@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// recordLatePayment adds a transfer that arrived after the payment expired and saves it with the payment.
+// The amount is held in the ledger and the payment becomes late_paid until the merchant's
+// late payment policy (or an operator) accepts or refunds it.
+// Returns ErrPaymentVersionConflict if the payment changed since it was read.
+func (s *PaymentService) recordLatePayment(ctx context.Context, payment *domain.Payment, req port.ConfirmPaymentRequest) error {
+	// The first late transfer also holds anything received before expiry
+	held := req.ActualAmount
+	if !payment.IsLatePaid() {
+		held = payment.AmountReceived.Add(req.ActualAmount)
+	}
+
+	transfer := newPaymentTransfer(payment, req)
+	addTransfer(payment, req)
+
+	if !payment.LatePaidAt.Valid {
+		payment.LatePaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	payment.LateResolution = sql.NullString{String: string(domain.LatePaymentPendingReview), Valid: true}
+
+	reason := fmt.Sprintf("transfer %s of %s %s received after expiry", req.TxHash, req.ActualAmount, payment.Currency)
+	if err := s.transitionPaymentWithTransfer(payment, domain.PaymentStatusLatePaid, req.Actor, req.ActorID, reason, transfer); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	if ledger := s.ledgerFor(payment); ledger != nil {
+		if err := ledger.RecordLatePaymentReceived(payment.ID, payment.MerchantID, held, payment.Currency); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": payment.ID,
+				"amount":     held.String(),
+				"error":      err.Error(),
+			}).Error("Failed to record late payment in ledger")
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":      payment.ID,
+		"tx_hash":         req.TxHash,
+		"amount_received": payment.AmountReceived,
+		"expires_at":      payment.ExpiresAt,
+	}).Warn("Transfer received after payment expired, recorded as late payment")
+
+	s.publishStatusEvent(ctx, payment, req.TxHash)
+	s.publishLatePaymentWebhook(ctx, domain.PaymentEventLatePaid, payment, nil)
+
+	s.applyLatePaymentPolicy(ctx, payment)
+
+	return nil
+}
+
+// applyLatePaymentPolicy resolves a late payment according to the merchant's policy
+// Payments that cannot be resolved automatically stay pending review (errors are logged)
+func (s *PaymentService) applyLatePaymentPolicy(ctx context.Context, payment *domain.Payment) {
+	policy := domain.LatePaymentPolicyReview
+	if s.merchantRepo != nil {
+		configured, err := s.merchantRepo.GetMerchantLatePaymentPolicy(payment.MerchantID)
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id":  payment.ID,
+				"merchant_id": payment.MerchantID,
+				"error":       err.Error(),
+			}).Warn("Failed to get merchant late payment policy, holding for review")
+		} else if domain.LatePaymentPolicy(configured).IsValid() {
+			policy = domain.LatePaymentPolicy(configured)
+		}
+	}
+
+	var err error
+	switch policy {
+	case domain.LatePaymentPolicyAccept:
+		err = s.acceptLatePayment(ctx, payment, domain.PaymentActorSystem, "merchant late payment policy")
+	case domain.LatePaymentPolicyRefund:
+		err = s.refundLatePayment(ctx, payment)
+	default:
+		s.logger.WithField("payment_id", payment.ID).Info("Late payment held for review")
+		return
+	}
+
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"policy":     policy,
+			"error":      err.Error(),
+		}).Warn("Failed to apply late payment policy, holding for review")
+	}
+}
+
+// ResolveLatePayment accepts or refunds a late payment held for review
+func (s *PaymentService) ResolveLatePayment(ctx context.Context, paymentID string, action domain.LatePaymentPolicy) (*domain.Payment, error) {
+	if action != domain.LatePaymentPolicyAccept && action != domain.LatePaymentPolicyRefund {
+		return nil, domain.ErrInvalidLatePaymentAction
+	}
+
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if !payment.IsLatePaid() || payment.GetLateResolution() != domain.LatePaymentPendingReview {
+		return nil, domain.ErrInvalidPaymentState
+	}
+
+	if action == domain.LatePaymentPolicyAccept {
+		err = s.acceptLatePayment(ctx, payment, domain.PaymentActorAdmin, "late payment accepted by an operator")
+	} else {
+		err = s.refundLatePayment(ctx, payment)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// ListLatePayments lists payments paid after expiry, optionally filtered by resolution
+func (s *PaymentService) ListLatePayments(ctx context.Context, resolution domain.LatePaymentResolution, limit, offset int) ([]*domain.Payment, int64, error) {
+	payments, err := s.paymentRepo.ListLatePayments(resolution, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list late payments: %w", err)
+	}
+
+	total, err := s.paymentRepo.CountLatePayments(resolution)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count late payments: %w", err)
+	}
+
+	return payments, total, nil
+}
+
+// acceptLatePayment completes a late payment with the amount received, valued at the current
+// exchange rate. The original quote is kept in the payment metadata.
+func (s *PaymentService) acceptLatePayment(ctx context.Context, payment *domain.Payment, actor domain.PaymentActor, reason string) error {
+	if s.exchangeRateService == nil {
+		return domain.ErrExchangeRateNotConfigured
+	}
+
+	rate, err := s.getExchangeRate(ctx, payment.Chain, payment.Currency)
+	if err != nil {
+		return fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	if payment.Metadata == nil {
+		payment.Metadata = make(map[string]interface{})
+	}
+	payment.Metadata["late_payment"] = map[string]interface{}{
+		"original_amount_vnd":    payment.AmountVND.String(),
+		"original_amount_crypto": payment.AmountCrypto.String(),
+		"original_exchange_rate": payment.ExchangeRate.String(),
+		"original_pricing":       payment.PricingAmount.String() + " " + payment.GetPricingCurrency(),
+		"requoted_at":            time.Now().Format(time.RFC3339),
+	}
+
+	payment.AmountCrypto = payment.AmountReceived
+	payment.ExchangeRate = rate
+	payment.AmountVND = payment.AmountReceived.Mul(rate).Round(0)
+	if err := s.repricePayment(ctx, payment); err != nil {
+		return err
+	}
+	payment.CalculateFee()
+	payment.LateResolution = sql.NullString{String: string(domain.LatePaymentAccepted), Valid: true}
+
+	status := domain.PaymentStatusCompleted
+	if s.transfersConfirmed(payment) {
+		payment.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	} else {
+		// WatchConfirmations completes the payment once every transfer reaches the required depth
+		status = domain.PaymentStatusConfirming
+	}
+
+	if err := s.transitionPayment(payment, status, actor, "", reason); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	s.releaseLatePayment(payment, domain.LatePaymentAccepted)
+	if payment.IsCompleted() {
+		s.updateMerchantVolume(payment)
+		s.recordPaymentSplit(ctx, payment)
+		s.recordInvoicePayment(ctx, payment)
+		s.recordSubscriptionPayment(ctx, payment)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":    payment.ID,
+		"status":        payment.Status,
+		"amount_crypto": payment.AmountCrypto.String(),
+		"amount_vnd":    payment.AmountVND.String(),
+		"exchange_rate": rate.String(),
+	}).Info("Late payment accepted at re-quoted exchange rate")
+
+	s.publishStatusEvent(ctx, payment, payment.TxHash.String)
+	s.publishLatePaymentWebhook(ctx, domain.PaymentEventLatePaymentResolved, payment, nil)
+
+	return nil
+}
+
+// refundLatePayment sends everything the late payment received back to the payer
+// The held amount is released once the refund transfer is finalized
+func (s *PaymentService) refundLatePayment(ctx context.Context, payment *domain.Payment) error {
+	if s.latePaymentRefunder == nil {
+		return domain.ErrLatePaymentRefunderNotConfigured
+	}
+
+	refund, err := s.latePaymentRefunder.CreateLatePaymentRefund(ctx, payment, "payment received after expiry")
+	if err != nil {
+		return fmt.Errorf("failed to create late payment refund: %w", err)
+	}
+
+	payment.LateResolution = sql.NullString{String: string(domain.LatePaymentRefunding), Valid: true}
+	if err := s.paymentRepo.Update(payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"refund_id":  refund.ID,
+		"amount":     refund.AmountCrypto.String(),
+	}).Info("Late payment refund created")
+
+	s.publishLatePaymentWebhook(ctx, domain.PaymentEventLatePaymentResolved, payment, map[string]interface{}{
+		"refund_id": refund.ID,
+	})
+
+	return nil
+}
+
+// releaseLatePayment releases the ledger hold of a resolved late payment (non-fatal)
+func (s *PaymentService) releaseLatePayment(payment *domain.Payment, resolution domain.LatePaymentResolution) {
+	ledger := s.ledgerFor(payment)
+	if ledger == nil {
+		return
+	}
+
+	if err := ledger.RecordLatePaymentReleased(payment.ID, payment.MerchantID, payment.AmountReceived, payment.Currency, string(resolution)); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"resolution": resolution,
+			"error":      err.Error(),
+		}).Error("Failed to release late payment in ledger")
+	}
+}
+
+// publishLatePaymentWebhook sends a late payment webhook to the merchant (non-blocking, errors are logged)
+func (s *PaymentService) publishLatePaymentWebhook(ctx context.Context, event string, payment *domain.Payment, extra map[string]interface{}) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"payment_id":      payment.ID,
+		"status":          string(payment.Status),
+		"late_resolution": string(payment.GetLateResolution()),
+		"tx_hash":         payment.GetTxHash(),
+		"chain":           string(payment.Chain),
+		"amount_received": payment.AmountReceived.String(),
+		"currency":        payment.Currency,
+		"expires_at":      payment.ExpiresAt,
+		"test_mode":       payment.TestMode,
+	}
+	if payment.LatePaidAt.Valid {
+		data["late_paid_at"] = payment.LatePaidAt.Time
+	}
+	if payment.GetLateResolution() == domain.LatePaymentAccepted {
+		data["amount_vnd"] = payment.AmountVND.String()
+		data["exchange_rate"] = payment.ExchangeRate.String()
+		data["pricing_currency"] = payment.GetPricingCurrency()
+		data["pricing_amount"] = payment.PricingAmount.String()
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+
+	if err := s.webhookPublisher.PublishWebhook(ctx, payment.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"event":      event,
+			"error":      err.Error(),
+		}).Warn("Failed to publish late payment webhook")
+	}
+}
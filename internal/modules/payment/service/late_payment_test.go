@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+func (l *memLedgerService) RecordLatePaymentReleased(paymentID, merchantID string, amountCrypto decimal.Decimal, cryptoCurrency, resolution string) error {
+	l.calls = append(l.calls, "late_payment_released:"+amountCrypto.String()+":"+resolution)
+	return nil
+}
+
+// stubMerchantRepository returns the configured merchant settings, methods the tests do not use panic
+type stubMerchantRepository struct {
+	domain.MerchantRepository
+	latePaymentPolicy string
+	quoteSpread       decimal.Decimal
+}
+
+func (r *stubMerchantRepository) GetMerchantLatePaymentPolicy(merchantID string) (string, error) {
+	return r.latePaymentPolicy, nil
+}
+
+// stubExchangeRateProvider quotes every stablecoin at the same VND rate
+type stubExchangeRateProvider struct {
+	domain.ExchangeRateProvider
+	rate decimal.Decimal
+}
+
+func (p *stubExchangeRateProvider) GetUSDTToVND(ctx context.Context) (decimal.Decimal, error) {
+	return p.rate, nil
+}
+
+func (p *stubExchangeRateProvider) GetUSDCToVND(ctx context.Context) (decimal.Decimal, error) {
+	return p.rate, nil
+}
+
+// memTokenRepository keeps registry tokens in memory
+type memTokenRepository struct {
+	tokens []*domain.Token
+	// lists counts the calls to List, listErr is returned by them when set
+	lists   int
+	listErr error
+}
+
+func (r *memTokenRepository) Create(token *domain.Token) error {
+	stored := *token
+	r.tokens = append(r.tokens, &stored)
+	return nil
+}
+
+func (r *memTokenRepository) Update(token *domain.Token) error {
+	for i, stored := range r.tokens {
+		if stored.ID == token.ID {
+			copied := *token
+			r.tokens[i] = &copied
+			return nil
+		}
+	}
+	return domain.ErrTokenNotFound
+}
+
+func (r *memTokenRepository) GetByID(id string) (*domain.Token, error) {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrTokenNotFound
+}
+
+func (r *memTokenRepository) List() ([]*domain.Token, error) {
+	r.lists++
+	if r.listErr != nil {
+		return nil, r.listErr
+	}
+	tokens := make([]*domain.Token, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		copied := *token
+		tokens = append(tokens, &copied)
+	}
+	return tokens, nil
+}
+
+func newExpiredPayment() *domain.Payment {
+	payment := newPendingPayment()
+	payment.ExpiresAt = time.Now().Add(-time.Minute)
+	return payment
+}
+
+func newLatePaidPayment() *domain.Payment {
+	payment := newExpiredPayment()
+	payment.Status = domain.PaymentStatusLatePaid
+	payment.AmountReceived = decimal.NewFromInt(100)
+	payment.FromAddress = sql.NullString{String: "payer-wallet", Valid: true}
+	payment.LatePaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+	payment.LateResolution = sql.NullString{String: string(domain.LatePaymentPendingReview), Valid: true}
+	return payment
+}
+
+func confirmLateTransfer(t *testing.T, service *PaymentService, txHash string, amount int64) *domain.Payment {
+	t.Helper()
+	payment, err := service.ConfirmPayment(context.Background(), port.ConfirmPaymentRequest{
+		PaymentID:    "payment-1",
+		TxHash:       txHash,
+		ActualAmount: decimal.NewFromInt(amount),
+		Chain:        domain.ChainSolana,
+		Currency:     "USDT",
+		FromAddress:  "payer-wallet",
+	})
+	require.NoError(t, err)
+	return payment
+}
+
+func TestConfirmPayment_HoldsLateTransferForReview(t *testing.T) {
+	payments := newMemPaymentRepository(newExpiredPayment())
+	payments.transfers = &memTransferRepository{}
+	ledger := &memLedgerService{}
+	webhooks := &memWebhookPublisher{}
+	service := NewPaymentService(payments, payments.transfers, nil, nil, nil, nil, PaymentServiceConfig{
+		LedgerService:    ledger,
+		WebhookPublisher: webhooks,
+	}, newTestLogger())
+
+	payment := confirmLateTransfer(t, service, "tx-1", 100)
+
+	assert.Equal(t, domain.PaymentStatusLatePaid, payment.Status)
+	assert.Equal(t, domain.LatePaymentPendingReview, payment.GetLateResolution())
+	assert.Equal(t, []string{"late_payment_received:100"}, ledger.calls)
+	assert.Equal(t, []string{domain.PaymentEventLatePaid}, webhooks.events)
+}
+
+func TestConfirmPayment_FirstLateTransferHoldsAmountReceivedBeforeExpiry(t *testing.T) {
+	underpaid := newExpiredPayment()
+	underpaid.Status = domain.PaymentStatusUnderpaid
+	underpaid.AmountReceived = decimal.NewFromInt(40)
+	payments := newMemPaymentRepository(underpaid)
+	payments.transfers = &memTransferRepository{}
+	ledger := &memLedgerService{}
+	service := NewPaymentService(payments, payments.transfers, nil, nil, nil, nil, PaymentServiceConfig{
+		LedgerService: ledger,
+	}, newTestLogger())
+
+	confirmLateTransfer(t, service, "tx-2", 60)
+	payment := confirmLateTransfer(t, service, "tx-3", 10)
+
+	// The first late transfer also holds the 40 received before expiry, later ones only themselves
+	assert.True(t, payment.AmountReceived.Equal(decimal.NewFromInt(110)))
+	assert.Equal(t, []string{"late_payment_received:100", "late_payment_received:10"}, ledger.calls)
+}
+
+func TestResolveLatePayment_AcceptReleasesHold(t *testing.T) {
+	payments := newMemPaymentRepository(newLatePaidPayment())
+	ledger := &memLedgerService{}
+	tokens := &memTokenRepository{tokens: []*domain.Token{
+		{ID: "token-1", Chain: domain.ChainSolana, Symbol: "USDT", PegCurrency: "USD", PriceSource: domain.PriceSourceUSDT, Enabled: true},
+	}}
+	rates := &stubExchangeRateProvider{rate: decimal.NewFromInt(26000)}
+	service := NewPaymentService(payments, &memTransferRepository{}, nil, rates, nil, nil, PaymentServiceConfig{
+		LedgerService: ledger,
+		TokenRegistry: NewTokenRegistry(tokens, 0, newTestLogger()),
+	}, newTestLogger())
+
+	payment, err := service.ResolveLatePayment(context.Background(), "payment-1", domain.LatePaymentPolicyAccept)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.LatePaymentAccepted, payment.GetLateResolution())
+	assert.Equal(t, "2600000", payment.AmountVND.String())
+	assert.Equal(t, []string{"late_payment_released:100:accepted"}, ledger.calls)
+
+	// A resolved late payment cannot be resolved again
+	_, err = service.ResolveLatePayment(context.Background(), "payment-1", domain.LatePaymentPolicyRefund)
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentState)
+	assert.Len(t, ledger.calls, 1)
+}
+
+func TestLatePaymentRefundPolicy_ReleasesHoldOnceRefundIsFinalized(t *testing.T) {
+	payments := newMemPaymentRepository(newExpiredPayment())
+	payments.transfers = &memTransferRepository{}
+	ledger := &memLedgerService{}
+	sender := &stubRefundSender{status: domain.RefundStatusSubmitted}
+	refunds := NewRefundService(payments, payments.transfers, newMemRefundRepository(), ledger, RefundServiceConfig{
+		RefundSender: sender,
+	}, newTestLogger())
+	service := NewPaymentService(payments, payments.transfers, &stubMerchantRepository{latePaymentPolicy: "refund"}, nil, nil, nil, PaymentServiceConfig{
+		LedgerService:       ledger,
+		LatePaymentRefunder: refunds,
+	}, newTestLogger())
+
+	confirmLateTransfer(t, service, "tx-1", 100)
+
+	// The amount stays held while the refund is on its way
+	stored, err := payments.GetByID("payment-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.LatePaymentRefunding, stored.GetLateResolution())
+	assert.Equal(t, []string{"late_payment_received:100"}, ledger.calls)
+
+	_, err = refunds.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	sender.status = domain.RefundStatusCompleted
+	finalized, err := refunds.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, finalized)
+
+	stored, err = payments.GetByID("payment-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.LatePaymentRefunded, stored.GetLateResolution())
+	assert.Equal(t, []string{"late_payment_received:100", "late_payment_released:100:refunded"}, ledger.calls)
+
+	// The refund is finalized once, its hold is not released twice
+	finalized, err = refunds.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, finalized)
+	assert.Len(t, ledger.calls, 2)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/web3"
+)
+
+// ownershipNonceBytes is the entropy of an issued challenge nonce
+const ownershipNonceBytes = 16
+
+// CreateOwnershipChallenge issues a nonce for an EVM payer to sign as proof of wallet ownership
+// Only issued nonces are accepted, each once and before the challenge expires
+func (s *PaymentService) CreateOwnershipChallenge(ctx context.Context, req port.CreateOwnershipChallengeRequest) (*domain.OwnershipChallenge, error) {
+	if s.challengeRepo == nil {
+		return nil, domain.ErrOwnershipChallengesNotConfigured
+	}
+
+	chain := domain.Chain(strings.ToLower(string(req.Chain)))
+	if !web3.IsEVMChain(string(chain)) {
+		return nil, fmt.Errorf("%w: ownership challenges are issued on EVM chains", domain.ErrInvalidChain)
+	}
+	if !web3.IsEVMAddress(req.WalletAddress) {
+		return nil, fmt.Errorf("%w: %q is not a hex address", domain.ErrInvalidWalletAddress, req.WalletAddress)
+	}
+
+	bytes := make([]byte, ownershipNonceBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge nonce: %w", err)
+	}
+
+	now := time.Now()
+	challenge := &domain.OwnershipChallenge{
+		Nonce:         hex.EncodeToString(bytes),
+		Chain:         chain,
+		WalletAddress: strings.ToLower(req.WalletAddress),
+		ExpiresAt:     now.Add(domain.DefaultOwnershipChallengeValidity).Truncate(time.Second),
+		CreatedAt:     now,
+	}
+	if err := s.challengeRepo.Create(challenge); err != nil {
+		return nil, fmt.Errorf("failed to create ownership challenge: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"chain":          challenge.Chain,
+		"wallet_address": challenge.WalletAddress,
+		"expires_at":     challenge.ExpiresAt,
+	}).Info("Ownership challenge issued")
+
+	return challenge, nil
+}
+
+// claimOwnershipNonce marks the nonce of a verified EVM ownership challenge used, so the signature
+// cannot be replayed for another payment. Nonces that were not issued by CreateOwnershipChallenge are rejected.
+func (s *PaymentService) claimOwnershipNonce(chain domain.Chain, address, message string) error {
+	err := web3.ClaimChallengeNonce(s.challengeRepo, string(chain), address, message)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, web3.ErrChallengeNoncesNotConfigured):
+		return domain.ErrOwnershipChallengesNotConfigured
+	case errors.Is(err, domain.ErrOwnershipChallengeRejected):
+		return fmt.Errorf("%w: %v", domain.ErrInvalidSignature, err)
+	}
+	return fmt.Errorf("failed to claim ownership challenge nonce: %w", err)
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// Audit log actions of merchants on their payments
+const (
+	AuditActionPaymentCanceled       = "payment_canceled"
+	AuditActionPaymentExpiryExtended = "payment_expiry_extended"
+)
+
+// CancelPayment cancels a payment of the merchant that was not paid yet
+// Transfers that still arrive are handled as late payments
+func (s *PaymentService) CancelPayment(ctx context.Context, paymentID, merchantID, reason string) (*domain.Payment, error) {
+	payment, err := s.getMerchantPayment(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkModifiableByMerchant(payment); err != nil {
+		return nil, err
+	}
+
+	previousStatus := payment.Status
+	payment.CanceledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if reason != "" {
+		payment.CancelReason = sql.NullString{String: reason, Valid: true}
+	}
+
+	// A transfer recorded meanwhile wins, the version changed and the cancellation is rejected
+	if err := s.transitionPayment(payment, domain.PaymentStatusCanceled, domain.PaymentActorMerchant, merchantID, reason); err != nil {
+		return nil, fmt.Errorf("failed to cancel payment: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":  payment.ID,
+		"merchant_id": merchantID,
+		"reason":      reason,
+	}).Info("Payment canceled by merchant")
+	s.releasePaymentLinkUse(payment)
+
+	message := "Payment was canceled by the merchant"
+	if reason != "" {
+		message = reason
+	}
+	s.publishPaymentEvent(ctx, PaymentEvent{
+		Type:      domain.PaymentEventCanceled,
+		PaymentID: payment.ID,
+		Status:    string(payment.Status),
+		Timestamp: payment.CanceledAt.Time,
+		Message:   message,
+	})
+
+	data := map[string]interface{}{
+		"payment_id":  payment.ID,
+		"status":      string(payment.Status),
+		"order_id":    payment.GetOrderID(),
+		"canceled_at": payment.CanceledAt.Time.Format(time.RFC3339),
+		"reason":      reason,
+	}
+	s.publishMerchantWebhook(ctx, payment, domain.PaymentEventCanceled, data)
+
+	s.recordPaymentAction(ctx, payment, AuditActionPaymentCanceled, map[string]interface{}{
+		"previous_status": string(previousStatus),
+		"reason":          reason,
+	})
+
+	return payment, nil
+}
+
+// ExtendPayment re-quotes the exchange rate of a payment of the merchant and moves its expiry to now plus extension
+// Payments created from a quote keep the quoted amount, the merchant's spread is part of it and is not re-applied
+// A zero extension uses the default payment expiry. Extensions are limited in length and number per payment.
+func (s *PaymentService) ExtendPayment(ctx context.Context, paymentID, merchantID string, extension time.Duration) (*domain.Payment, error) {
+	payment, err := s.getMerchantPayment(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkModifiableByMerchant(payment); err != nil {
+		return nil, err
+	}
+
+	if extension == 0 {
+		extension = time.Duration(s.expiryMinutes) * time.Minute
+	}
+	if extension < 0 || extension > s.maxExpiryExtension {
+		return nil, fmt.Errorf("%w: the extension must be between 1 and %d minutes", domain.ErrInvalidExpiryExtension, int(s.maxExpiryExtension.Minutes()))
+	}
+	if payment.ExpiryExtensions >= s.maxExpiryExtensions {
+		return nil, fmt.Errorf("%w: a payment can be extended at most %d times", domain.ErrInvalidExpiryExtension, s.maxExpiryExtensions)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(extension)
+	if !expiresAt.After(payment.ExpiresAt) {
+		return nil, fmt.Errorf("%w: the payment already expires at %s", domain.ErrInvalidExpiryExtension, payment.ExpiresAt.Format(time.RFC3339))
+	}
+
+	previousExpiresAt := payment.ExpiresAt
+	previousRate := payment.ExchangeRate
+	previousAmountCrypto := payment.AmountCrypto
+
+	// The payer gets a new window, so the amount is quoted again at the current rate
+	// unless the payer accepted a quote, only its expiry moves then
+	if !payment.QuoteID.Valid {
+		if err := s.requotePayment(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+	payment.ExpiresAt = expiresAt
+	payment.ExpiryExtensions++
+
+	if err := s.paymentRepo.Update(payment); err != nil {
+		return nil, fmt.Errorf("failed to extend payment: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":    payment.ID,
+		"merchant_id":   merchantID,
+		"expires_at":    payment.ExpiresAt,
+		"exchange_rate": payment.ExchangeRate,
+		"amount_crypto": payment.AmountCrypto,
+		"extensions":    payment.ExpiryExtensions,
+	}).Info("Payment expiry extended by merchant")
+
+	s.publishPaymentEvent(ctx, PaymentEvent{
+		Type:      domain.PaymentEventExpiryExtended,
+		PaymentID: payment.ID,
+		Status:    string(payment.Status),
+		Timestamp: now,
+		Message:   fmt.Sprintf("Payment now expires at %s", payment.ExpiresAt.Format(time.RFC3339)),
+	})
+
+	data := map[string]interface{}{
+		"payment_id":    payment.ID,
+		"status":        string(payment.Status),
+		"order_id":      payment.GetOrderID(),
+		"expires_at":    payment.ExpiresAt.Format(time.RFC3339),
+		"amount_vnd":    payment.AmountVND.String(),
+		"amount_crypto": payment.AmountCrypto.String(),
+		"currency":      payment.Currency,
+		"exchange_rate": payment.ExchangeRate.String(),
+	}
+	s.publishMerchantWebhook(ctx, payment, domain.PaymentEventExpiryExtended, data)
+
+	s.recordPaymentAction(ctx, payment, AuditActionPaymentExpiryExtended, map[string]interface{}{
+		"previous_expires_at":    previousExpiresAt.Format(time.RFC3339),
+		"expires_at":             payment.ExpiresAt.Format(time.RFC3339),
+		"previous_exchange_rate": previousRate.String(),
+		"exchange_rate":          payment.ExchangeRate.String(),
+		"previous_amount_crypto": previousAmountCrypto.String(),
+		"amount_crypto":          payment.AmountCrypto.String(),
+		"extensions":             payment.ExpiryExtensions,
+	})
+
+	return payment, nil
+}
+
+// requotePayment converts the merchant price of a payment again at the current rates
+// Payments priced in another fiat currency get a new VND amount, fee and split shares
+func (s *PaymentService) requotePayment(ctx context.Context, payment *domain.Payment) error {
+	if currency := payment.GetPricingCurrency(); currency != domain.PricingCurrencyVND {
+		pricingRate, err := s.getPricingRate(ctx, currency)
+		if err != nil {
+			return err
+		}
+		payment.PricingRate = pricingRate
+		payment.AmountVND = payment.PricingAmount.Mul(pricingRate).Round(0)
+		payment.CalculateFee()
+		if err := payment.ApplySplits(payment.Splits); err != nil {
+			return err
+		}
+	}
+
+	exchangeRate, err := s.getExchangeRate(ctx, payment.Chain, payment.Currency)
+	if err != nil {
+		return fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	if exchangeRate.LessThanOrEqual(decimal.Zero) {
+		return fmt.Errorf("invalid %s/VND exchange rate: %s", payment.Currency, exchangeRate)
+	}
+
+	payment.ExchangeRate = exchangeRate
+	payment.AmountCrypto = payment.AmountVND.Div(exchangeRate).Round(6)
+	return nil
+}
+
+// getMerchantPayment returns a payment of the merchant, ErrPaymentNotFound for payments of other merchants
+func (s *PaymentService) getMerchantPayment(paymentID, merchantID string) (*domain.Payment, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPaymentNotFound) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if payment.MerchantID != merchantID {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+// checkModifiableByMerchant returns why the merchant can no longer cancel or extend the payment
+func checkModifiableByMerchant(payment *domain.Payment) error {
+	if payment.CanBeModifiedByMerchant() {
+		return nil
+	}
+	if payment.IsCompleted() {
+		return domain.ErrPaymentAlreadyCompleted
+	}
+	if payment.Status == domain.PaymentStatusExpired || payment.IsExpired() {
+		return domain.ErrPaymentExpired
+	}
+	return domain.ErrInvalidPaymentState
+}
+
+// publishMerchantWebhook notifies the merchant of a change of one of its payments (non-fatal)
+func (s *PaymentService) publishMerchantWebhook(ctx context.Context, payment *domain.Payment, event string, data map[string]interface{}) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	if err := s.webhookPublisher.PublishWebhook(ctx, payment.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"event":      event,
+			"error":      err.Error(),
+		}).Warn("Failed to publish payment webhook")
+	}
+}
+
+// recordPaymentAction writes a merchant action to the audit log (non-fatal, the action is already saved)
+func (s *PaymentService) recordPaymentAction(ctx context.Context, payment *domain.Payment, action string, metadata map[string]interface{}) {
+	if s.auditRecorder == nil {
+		return
+	}
+
+	if err := s.auditRecorder.RecordPaymentAction(ctx, payment.MerchantID, payment.ID, action, metadata); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"action":     action,
+			"error":      err.Error(),
+		}).Error("Failed to record payment action in audit log")
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// PaymentBatchService creates many payments of a merchant at once
+// Each request is created independently, a failed request does not stop the others
+type PaymentBatchService struct {
+	paymentService   port.PaymentService
+	batchRepo        domain.PaymentBatchRepository
+	batchQueue       domain.PaymentBatchQueue
+	webhookPublisher domain.WebhookPublisher // For batch.completed merchant webhooks
+	logger           *logrus.Logger
+}
+
+// PaymentBatchServiceConfig contains optional dependencies for PaymentBatchService
+type PaymentBatchServiceConfig struct {
+	BatchQueue       domain.PaymentBatchQueue // Optional: required to submit batches to the worker
+	WebhookPublisher domain.WebhookPublisher  // Optional: for batch.completed webhooks
+}
+
+// NewPaymentBatchService creates a new payment batch service
+func NewPaymentBatchService(
+	paymentService port.PaymentService,
+	batchRepo domain.PaymentBatchRepository,
+	config PaymentBatchServiceConfig,
+	logger *logrus.Logger,
+) *PaymentBatchService {
+	return &PaymentBatchService{
+		paymentService:   paymentService,
+		batchRepo:        batchRepo,
+		batchQueue:       config.BatchQueue,
+		webhookPublisher: config.WebhookPublisher,
+		logger:           logger,
+	}
+}
+
+// CreatePaymentBatch creates the payments of a batch within the call
+// Exchange rates are fetched once per token and pricing currency for the whole batch
+func (s *PaymentBatchService) CreatePaymentBatch(ctx context.Context, merchantID string, reqs []port.CreatePaymentRequest) ([]port.PaymentBatchResult, error) {
+	if err := validatePaymentBatchSize(len(reqs), domain.MaxSyncPaymentBatchItems); err != nil {
+		return nil, err
+	}
+
+	ctx = withRateCache(ctx)
+	results := make([]port.PaymentBatchResult, len(reqs))
+	created := 0
+	for i, req := range reqs {
+		req.MerchantID = merchantID
+		payment, err := s.paymentService.CreatePayment(ctx, req)
+		results[i] = port.PaymentBatchResult{Index: i, Payment: payment, Err: err}
+		if err == nil {
+			created++
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"merchant_id": merchantID,
+		"total":       len(reqs),
+		"created":     created,
+		"failed":      len(reqs) - created,
+	}).Info("Payment batch created")
+
+	return results, nil
+}
+
+// SubmitPaymentBatch stores a batch for the worker and returns it while still pending
+func (s *PaymentBatchService) SubmitPaymentBatch(ctx context.Context, merchantID string, reqs []port.CreatePaymentRequest) (*domain.PaymentBatch, error) {
+	if s.batchQueue == nil {
+		return nil, domain.ErrPaymentBatchesNotConfigured
+	}
+	if err := validatePaymentBatchSize(len(reqs), domain.MaxPaymentBatchItems); err != nil {
+		return nil, err
+	}
+
+	items := make(domain.PaymentBatchItems, len(reqs))
+	for i, req := range reqs {
+		items[i] = domain.PaymentBatchItem{
+			Index:           i,
+			AmountVND:       req.AmountVND,
+			PricingCurrency: req.PricingCurrency,
+			PricingAmount:   req.PricingAmount,
+			Currency:        req.Currency,
+			Chain:           req.Chain,
+			OrderID:         req.OrderID,
+			Description:     req.Description,
+			CallbackURL:     req.CallbackURL,
+			Splits:          req.Splits,
+			Status:          domain.PaymentBatchItemStatusPending,
+		}
+	}
+
+	now := time.Now()
+	batch := &domain.PaymentBatch{
+		ID:         uuid.New().String(),
+		MerchantID: merchantID,
+		Status:     domain.PaymentBatchStatusPending,
+		Items:      items,
+		TotalCount: len(items),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.batchRepo.Create(batch); err != nil {
+		return nil, fmt.Errorf("failed to create payment batch: %w", err)
+	}
+
+	if err := s.batchQueue.EnqueuePaymentBatch(ctx, batch.ID); err != nil {
+		return nil, fmt.Errorf("failed to enqueue payment batch: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"batch_id":    batch.ID,
+		"merchant_id": merchantID,
+		"total":       batch.TotalCount,
+	}).Info("Payment batch submitted")
+
+	return batch, nil
+}
+
+// GetPaymentBatch returns a batch of the merchant
+func (s *PaymentBatchService) GetPaymentBatch(ctx context.Context, batchID, merchantID string) (*domain.PaymentBatch, error) {
+	batch, err := s.batchRepo.GetByID(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not reveal batches of other merchants
+	if batch.MerchantID != merchantID {
+		return nil, domain.ErrPaymentBatchNotFound
+	}
+
+	return batch, nil
+}
+
+// ProcessPaymentBatch creates the pending items of a batch and delivers the batch.completed webhook
+// Each outcome is stored as soon as it is known, and each item has a payment ID derived from the batch,
+// so a task retried after a crash before the outcome was stored gets the payment it created before
+func (s *PaymentBatchService) ProcessPaymentBatch(ctx context.Context, batchID string) error {
+	batch, err := s.batchRepo.GetByID(batchID)
+	if err != nil {
+		return fmt.Errorf("failed to get payment batch: %w", err)
+	}
+	if batch.IsCompleted() {
+		return nil
+	}
+
+	if batch.Status == domain.PaymentBatchStatusPending {
+		batch.Status = domain.PaymentBatchStatusProcessing
+		if err := s.batchRepo.Update(batch); err != nil {
+			return fmt.Errorf("failed to update payment batch: %w", err)
+		}
+	}
+
+	ctx = withRateCache(ctx)
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if item.Status != domain.PaymentBatchItemStatusPending {
+			continue
+		}
+
+		payment, err := s.paymentService.CreatePayment(ctx, port.CreatePaymentRequest{
+			PaymentID:       paymentBatchItemPaymentID(batch.ID, item.Index),
+			MerchantID:      batch.MerchantID,
+			AmountVND:       item.AmountVND,
+			PricingCurrency: item.PricingCurrency,
+			PricingAmount:   item.PricingAmount,
+			Currency:        item.Currency,
+			Chain:           item.Chain,
+			OrderID:         item.OrderID,
+			Description:     item.Description,
+			CallbackURL:     item.CallbackURL,
+			Splits:          item.Splits,
+		})
+		if err != nil {
+			item.Status = domain.PaymentBatchItemStatusFailed
+			item.Error = err.Error()
+		} else {
+			item.Status = domain.PaymentBatchItemStatusCreated
+			item.PaymentID = payment.ID
+		}
+
+		if err := s.batchRepo.UpdateItem(batch.ID, item); err != nil {
+			// Keep going: failing the task would retry the item and create its payment twice
+			// The outcome is still counted and delivered in the webhook
+			s.logger.WithFields(logrus.Fields{
+				"batch_id":   batch.ID,
+				"index":      item.Index,
+				"payment_id": item.PaymentID,
+				"error":      err.Error(),
+			}).Error("Failed to store payment batch item outcome")
+		}
+	}
+
+	batch.Complete(time.Now())
+	if err := s.batchRepo.Update(batch); err != nil {
+		return fmt.Errorf("failed to update payment batch: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"batch_id":    batch.ID,
+		"merchant_id": batch.MerchantID,
+		"created":     batch.CreatedCount,
+		"failed":      batch.FailedCount,
+	}).Info("Payment batch completed")
+
+	s.publishBatchCompletedWebhook(ctx, batch)
+	return nil
+}
+
+// paymentBatchItemPaymentID returns the payment ID of a batch item, the same on every attempt
+func paymentBatchItemPaymentID(batchID string, index int) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("payment_batch:%s:%d", batchID, index))).String()
+}
+
+// validatePaymentBatchSize checks that a batch has between one and limit items
+func validatePaymentBatchSize(count, limit int) error {
+	if count == 0 {
+		return fmt.Errorf("%w: at least one payment is required", domain.ErrInvalidPaymentBatch)
+	}
+	if count > limit {
+		return fmt.Errorf("%w: at most %d payments are allowed", domain.ErrInvalidPaymentBatch, limit)
+	}
+	return nil
+}
+
+// publishBatchCompletedWebhook notifies the merchant of the outcome of every item (non-fatal)
+func (s *PaymentBatchService) publishBatchCompletedWebhook(ctx context.Context, batch *domain.PaymentBatch) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	items := make([]map[string]interface{}, len(batch.Items))
+	for i, item := range batch.Items {
+		result := map[string]interface{}{
+			"index":  item.Index,
+			"status": string(item.Status),
+		}
+		if item.OrderID != "" {
+			result["order_id"] = item.OrderID
+		}
+		if item.PaymentID != "" {
+			result["payment_id"] = item.PaymentID
+		}
+		if item.Error != "" {
+			result["error"] = item.Error
+		}
+		items[i] = result
+	}
+
+	data := map[string]interface{}{
+		"batch_id":      batch.ID,
+		"status":        string(batch.Status),
+		"total_count":   batch.TotalCount,
+		"created_count": batch.CreatedCount,
+		"failed_count":  batch.FailedCount,
+		"items":         items,
+	}
+
+	if err := s.webhookPublisher.PublishWebhook(ctx, batch.MerchantID, domain.PaymentBatchEventCompleted, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"batch_id": batch.ID,
+			"error":    err.Error(),
+		}).Warn("Failed to publish payment batch webhook")
+	}
+}
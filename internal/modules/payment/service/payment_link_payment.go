@@ -0,0 +1,31 @@
+package service
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// releasePaymentLinkUse gives back the link use reserved by a payment that ended without receiving anything (non-fatal)
+// Payers who let the payment expire or whose payment was canceled do not count against max_uses
+func (s *PaymentService) releasePaymentLinkUse(payment *domain.Payment) {
+	if !payment.PaymentLinkID.Valid || s.paymentLinkRepo == nil || payment.AmountReceived.IsPositive() {
+		return
+	}
+
+	if err := s.paymentLinkRepo.ReleaseUse(payment.PaymentLinkID.String); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id":      payment.ID,
+			"payment_link_id": payment.PaymentLinkID.String,
+			"status":          payment.Status,
+			"error":           err.Error(),
+		}).Error("Failed to release payment link use")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":      payment.ID,
+		"payment_link_id": payment.PaymentLinkID.String,
+		"status":          payment.Status,
+	}).Info("Payment link use released")
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// PaymentLinkService manages reusable payment links and creates payments from them
+type PaymentLinkService struct {
+	paymentService   port.PaymentService
+	linkRepo         domain.PaymentLinkRepository
+	webhookPublisher domain.WebhookPublisher // For payment_link.* merchant webhooks
+	logger           *logrus.Logger
+}
+
+// PaymentLinkServiceConfig contains optional dependencies for PaymentLinkService
+type PaymentLinkServiceConfig struct {
+	WebhookPublisher domain.WebhookPublisher // Optional: for payment_link.* webhooks
+}
+
+// NewPaymentLinkService creates a new payment link service
+func NewPaymentLinkService(
+	paymentService port.PaymentService,
+	linkRepo domain.PaymentLinkRepository,
+	config PaymentLinkServiceConfig,
+	logger *logrus.Logger,
+) *PaymentLinkService {
+	return &PaymentLinkService{
+		paymentService:   paymentService,
+		linkRepo:         linkRepo,
+		webhookPublisher: config.WebhookPublisher,
+		logger:           logger,
+	}
+}
+
+// CreatePaymentLink creates a payment link for a fixed or an open amount
+func (s *PaymentLinkService) CreatePaymentLink(ctx context.Context, req port.CreatePaymentLinkRequest) (*domain.PaymentLink, error) {
+	currency := domain.NormalizePricingCurrency(req.PricingCurrency)
+	if !domain.IsSupportedPricingCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedPricingCurrency, currency)
+	}
+
+	now := time.Now()
+	link := &domain.PaymentLink{
+		ID:              uuid.New().String(),
+		MerchantID:      req.MerchantID,
+		AmountType:      req.AmountType,
+		PricingCurrency: currency,
+		Amount:          req.Amount.Round(2),
+		MinAmount:       req.MinAmount.Round(2),
+		MaxAmount:       req.MaxAmount.Round(2),
+		Description:     req.Description,
+		Metadata:        req.Metadata,
+		Status:          domain.PaymentLinkStatusActive,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if req.Currency != "" {
+		link.Currency = sql.NullString{String: strings.ToUpper(req.Currency), Valid: true}
+	}
+	if req.Chain != "" {
+		link.Chain = sql.NullString{String: string(req.Chain), Valid: true}
+	}
+	if req.MaxUses > 0 {
+		link.MaxUses = sql.NullInt32{Int32: req.MaxUses, Valid: true}
+	}
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("%w: expires_at must be in the future", domain.ErrInvalidAmount)
+		}
+		link.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	if err := link.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.Create(link); err != nil {
+		return nil, fmt.Errorf("failed to create payment link: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_link_id": link.ID,
+		"merchant_id":     link.MerchantID,
+		"amount_type":     link.AmountType,
+		"amount":          link.Amount.String(),
+		"currency":        link.PricingCurrency,
+	}).Info("Payment link created")
+
+	return link, nil
+}
+
+// GetPaymentLink returns a payment link of the merchant, any link when merchantID is empty
+func (s *PaymentLinkService) GetPaymentLink(ctx context.Context, linkID, merchantID string) (*domain.PaymentLink, error) {
+	link, err := s.linkRepo.GetByID(linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not reveal links of other merchants
+	if merchantID != "" && link.MerchantID != merchantID {
+		return nil, domain.ErrPaymentLinkNotFound
+	}
+
+	return link, nil
+}
+
+// ListPaymentLinks lists the payment links of a merchant, newest first, with their total count
+func (s *PaymentLinkService) ListPaymentLinks(ctx context.Context, merchantID string, limit, offset int) ([]*domain.PaymentLink, int64, error) {
+	links, err := s.linkRepo.ListByMerchant(merchantID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list payment links: %w", err)
+	}
+
+	total, err := s.linkRepo.CountByMerchant(merchantID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count payment links: %w", err)
+	}
+
+	return links, total, nil
+}
+
+// DeactivatePaymentLink stops a payment link from creating new payments
+// Payments already created from the link are not affected
+func (s *PaymentLinkService) DeactivatePaymentLink(ctx context.Context, linkID, merchantID string) (*domain.PaymentLink, error) {
+	link, err := s.GetPaymentLink(ctx, linkID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.Status == domain.PaymentLinkStatusInactive {
+		return link, nil
+	}
+
+	link.Status = domain.PaymentLinkStatusInactive
+	if err := s.linkRepo.Update(link); err != nil {
+		return nil, fmt.Errorf("failed to deactivate payment link: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_link_id": link.ID,
+		"merchant_id":     link.MerchantID,
+	}).Info("Payment link deactivated")
+
+	s.publishPaymentLinkWebhook(ctx, domain.PaymentLinkEventDeactivated, link, nil)
+
+	return link, nil
+}
+
+// OpenPaymentLink creates a new payment from a payment link for a payer
+// A use of the link is reserved first so concurrent payers cannot exceed max_uses
+// The payment service gives the use back when the payment expires, fails or is canceled unpaid
+func (s *PaymentLinkService) OpenPaymentLink(ctx context.Context, req port.OpenPaymentLinkRequest) (*domain.Payment, error) {
+	link, err := s.linkRepo.GetByID(req.PaymentLinkID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := link.CheckUsable(); err != nil {
+		return nil, err
+	}
+
+	amount, err := link.ResolveAmount(req.Amount.Round(2))
+	if err != nil {
+		return nil, err
+	}
+
+	// The merchant's choice of token and chain wins over the payer's
+	currency := req.Currency
+	if link.Currency.Valid {
+		currency = link.Currency.String
+	}
+	chain := req.Chain
+	if link.Chain.Valid {
+		chain = domain.Chain(link.Chain.String)
+	}
+
+	if err := s.linkRepo.ReserveUse(link.ID); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.paymentService.CreatePayment(ctx, port.CreatePaymentRequest{
+		MerchantID:      link.MerchantID,
+		Currency:        currency,
+		Chain:           chain,
+		Description:     link.Description,
+		PricingCurrency: link.PricingCurrency,
+		PricingAmount:   amount,
+		PaymentLinkID:   link.ID,
+	})
+	if err != nil {
+		if releaseErr := s.linkRepo.ReleaseUse(link.ID); releaseErr != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_link_id": link.ID,
+				"error":           releaseErr.Error(),
+			}).Error("Failed to release payment link use")
+		}
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_link_id": link.ID,
+		"payment_id":      payment.ID,
+		"pricing_amount":  payment.PricingAmount.String(),
+		"currency":        payment.PricingCurrency,
+	}).Info("Payment created from payment link")
+
+	link.UsesCount++
+	s.publishPaymentLinkWebhook(ctx, domain.PaymentLinkEventPaymentCreated, link, payment)
+
+	return payment, nil
+}
+
+// publishPaymentLinkWebhook notifies the merchant of a payment link event (non-fatal)
+func (s *PaymentLinkService) publishPaymentLinkWebhook(ctx context.Context, event string, link *domain.PaymentLink, payment *domain.Payment) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"payment_link_id": link.ID,
+		"status":          string(link.Status),
+		"uses_count":      link.UsesCount,
+	}
+	if link.MaxUses.Valid {
+		data["max_uses"] = link.MaxUses.Int32
+	}
+	if payment != nil {
+		data["payment_id"] = payment.ID
+		data["pricing_currency"] = payment.PricingCurrency
+		data["pricing_amount"] = payment.PricingAmount.String()
+		data["amount_vnd"] = payment.AmountVND.String()
+		data["amount_crypto"] = payment.AmountCrypto.String()
+		data["currency"] = payment.Currency
+		data["chain"] = string(payment.Chain)
+	}
+
+	if err := s.webhookPublisher.PublishWebhook(ctx, link.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_link_id": link.ID,
+			"event":           event,
+			"error":           err.Error(),
+		}).Warn("Failed to publish payment link webhook")
+	}
+}
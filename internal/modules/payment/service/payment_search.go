@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// SearchPayments returns a page of the payments matching the filter, newest first unless sorted otherwise.
+// Merchant searches set Filter.MerchantID, admin searches may leave it empty to search every merchant.
+func (s *PaymentService) SearchPayments(ctx context.Context, search domain.PaymentSearch) (*domain.PaymentPage, error) {
+	if search.SortBy == "" {
+		search.SortBy = domain.PaymentSortCreatedAt
+	}
+	if !search.SortBy.IsValid() {
+		return nil, fmt.Errorf("%w: payments can be sorted by %s or %s", domain.ErrInvalidPaymentSearch, domain.PaymentSortCreatedAt, domain.PaymentSortAmountVND)
+	}
+	if search.Limit <= 0 {
+		search.Limit = domain.DefaultPaymentSearchLimit
+	}
+	if search.Limit > domain.MaxPaymentSearchLimit {
+		search.Limit = domain.MaxPaymentSearchLimit
+	}
+
+	filter := search.Filter
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", domain.ErrInvalidPaymentSearch)
+	}
+	if !filter.ConfirmedFrom.IsZero() && !filter.ConfirmedTo.IsZero() && !filter.ConfirmedFrom.Before(filter.ConfirmedTo) {
+		return nil, fmt.Errorf("%w: confirmed_from must be before confirmed_to", domain.ErrInvalidPaymentSearch)
+	}
+	if filter.MinAmountVND.IsNegative() || filter.MaxAmountVND.IsNegative() ||
+		(!filter.MaxAmountVND.IsZero() && filter.MinAmountVND.GreaterThan(filter.MaxAmountVND)) {
+		return nil, fmt.Errorf("%w: invalid amount range", domain.ErrInvalidPaymentSearch)
+	}
+	if filter.MinPricingAmount.IsNegative() || filter.MaxPricingAmount.IsNegative() ||
+		(!filter.MaxPricingAmount.IsZero() && filter.MinPricingAmount.GreaterThan(filter.MaxPricingAmount)) {
+		return nil, fmt.Errorf("%w: invalid pricing amount range", domain.ErrInvalidPaymentSearch)
+	}
+	// Amounts in different pricing currencies cannot be compared
+	if filter.PricingCurrency != "" {
+		search.Filter.PricingCurrency = domain.NormalizePricingCurrency(filter.PricingCurrency)
+	} else if !filter.MinPricingAmount.IsZero() || !filter.MaxPricingAmount.IsZero() {
+		return nil, fmt.Errorf("%w: min_amount and max_amount require pricing_currency", domain.ErrInvalidPaymentSearch)
+	}
+
+	page, err := s.paymentRepo.Search(search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search payments: %w", err)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// searchRecordingRepository records the search the service runs and finds nothing
+type searchRecordingRepository struct {
+	*memPaymentRepository
+	searches []domain.PaymentSearch
+}
+
+func (r *searchRecordingRepository) Search(search domain.PaymentSearch) (*domain.PaymentPage, error) {
+	r.searches = append(r.searches, search)
+	return &domain.PaymentPage{}, nil
+}
+
+func newSearchPaymentService() (*PaymentService, *searchRecordingRepository) {
+	payments := &searchRecordingRepository{memPaymentRepository: newMemPaymentRepository()}
+	return NewPaymentService(payments, &memTransferRepository{}, nil, nil, nil, nil, PaymentServiceConfig{}, newTestLogger()), payments
+}
+
+func TestSearchPayments_DefaultsToNewestFirstPage(t *testing.T) {
+	service, payments := newSearchPaymentService()
+
+	_, err := service.SearchPayments(context.Background(), domain.PaymentSearch{Descending: true})
+	require.NoError(t, err)
+	_, err = service.SearchPayments(context.Background(), domain.PaymentSearch{Limit: 1000})
+	require.NoError(t, err)
+
+	require.Len(t, payments.searches, 2)
+	assert.Equal(t, domain.PaymentSortCreatedAt, payments.searches[0].SortBy)
+	assert.Equal(t, domain.DefaultPaymentSearchLimit, payments.searches[0].Limit)
+	assert.Equal(t, domain.MaxPaymentSearchLimit, payments.searches[1].Limit)
+}
+
+func TestSearchPayments_FiltersPricingAmountInItsCurrency(t *testing.T) {
+	service, payments := newSearchPaymentService()
+
+	_, err := service.SearchPayments(context.Background(), domain.PaymentSearch{Filter: domain.PaymentFilter{
+		PricingCurrency:  " usd",
+		MinPricingAmount: decimal.NewFromInt(10),
+		MaxPricingAmount: decimal.NewFromInt(100),
+	}})
+
+	require.NoError(t, err)
+	require.Len(t, payments.searches, 1)
+	assert.Equal(t, "USD", payments.searches[0].Filter.PricingCurrency)
+}
+
+func TestSearchPayments_RejectsInvalidFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		search domain.PaymentSearch
+	}{
+		{name: "unknown sort", search: domain.PaymentSearch{SortBy: "status"}},
+		{name: "amount range", search: domain.PaymentSearch{Filter: domain.PaymentFilter{
+			MinAmountVND: decimal.NewFromInt(200000),
+			MaxAmountVND: decimal.NewFromInt(100000),
+		}}},
+		{name: "pricing amount without currency", search: domain.PaymentSearch{Filter: domain.PaymentFilter{
+			MinPricingAmount: decimal.NewFromInt(10),
+		}}},
+		{name: "negative pricing amount", search: domain.PaymentSearch{Filter: domain.PaymentFilter{
+			PricingCurrency:  "USD",
+			MaxPricingAmount: decimal.NewFromInt(-1),
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, payments := newSearchPaymentService()
+
+			_, err := service.SearchPayments(context.Background(), tt.search)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidPaymentSearch)
+			assert.Empty(t, payments.searches)
+		})
+	}
+}
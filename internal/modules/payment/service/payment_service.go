@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,20 +30,61 @@ const (
 	PaymentReferenceLength = 16
 	// KYCStatusApproved is the status for approved merchants
 	KYCStatusApproved = "approved"
+	// DefaultReorgWatchWindow is how long transfers are re-verified after they are recorded
+	DefaultReorgWatchWindow = 24 * time.Hour
+	// ReversalMissingChecks is the number of consecutive checks a transfer must be missing before
+	// its payment is reversed, so a lagging RPC node does not reverse a valid payment
+	ReversalMissingChecks = 3
+	// ConfirmationWatchBatchSize is the maximum number of transfers verified per watcher run
+	ConfirmationWatchBatchSize = 200
+	// SurplusReconcileBatchSize is the number of overpaid payments read per page by ReconcileSurplus
+	SurplusReconcileBatchSize = 100
+	// DefaultQuoteValidity is how long a quote locks the exchange rate
+	DefaultQuoteValidity = 5 * time.Minute
+	// DefaultMaxExpiryExtension is the longest a merchant can extend the expiry of a payment at once
+	DefaultMaxExpiryExtension = 60 * time.Minute
+	// DefaultMaxExpiryExtensions is how many times a merchant can extend the expiry of a payment
+	DefaultMaxExpiryExtensions = 3
 )
 
 // PaymentService handles payment business logic
 type PaymentService struct {
 	paymentRepo         domain.PaymentRepository
+	transferRepo        domain.PaymentTransferRepository
 	merchantRepo        domain.MerchantRepository
 	exchangeRateService domain.ExchangeRateProvider
 	complianceService   domain.ComplianceService // For pre-payment validation
 	amlService          domain.AMLService        // For wallet sanctions screening (shift-left security)
-	redisClient         *redis.Client            // For publishing real-time events
+	ledgerService       domain.LedgerService     // For recording overpayment surplus and reversals
+	testLedgerService   domain.LedgerService     // For the entries of test payments, kept off the live system accounts
+	depositAddressRepo  domain.DepositAddressRepository
+	depositAddressGen   domain.DepositAddressGenerator // For merchants matching payments by deposit address
+	depositAddressQueue domain.DepositAddressQueue     // For provisioning pending deposit addresses in the worker
+	redisClient         *redis.Client                  // For publishing real-time events
+	confirmationPolicy  domain.ConfirmationPolicy
+	txVerifier          domain.TransactionVerifier  // For re-verifying transfers until finality
+	txRecorder          domain.BlockchainTxRecorder // For keeping listener transaction records in sync
+	complianceAlerter   domain.ComplianceAlerter    // For alerting on reversed payments
+	webhookPublisher    domain.WebhookPublisher     // For payment.reversed merchant webhooks
+	reorgWatchWindow    time.Duration
+	latePaymentRefunder domain.LatePaymentRefunder       // For refunding late payments
+	inboundTransferRepo domain.InboundTransferRepository // For resolving the unmatched deposit queue
+	quoteRepo           domain.QuoteRepository           // For rate-locked quotes
+	quoteSigningKey     []byte
+	quoteValidity       time.Duration
+	invoiceRepo         domain.InvoiceRepository            // For tracking the amount paid on invoices
+	subscriptionRepo    domain.SubscriptionRepository       // For marking subscription cycles paid
+	paymentLinkRepo     domain.PaymentLinkRepository        // For releasing the link use of unpaid payments
+	challengeRepo       domain.OwnershipChallengeRepository // For issuing and claiming EVM ownership challenges
+	auditRecorder       domain.AuditRecorder                // For auditing merchant cancellations and extensions
+	maxExpiryExtension  time.Duration
+	maxExpiryExtensions int
 	logger              *logrus.Logger
 	defaultChain        domain.Chain
 	defaultCurrency     string
 	walletAddress       string
+	chainWallets        map[domain.Chain]string
+	tokenRegistry       *TokenRegistry
 	feePercentage       decimal.Decimal
 	expiryMinutes       int
 }
@@ -52,14 +94,63 @@ type PaymentServiceConfig struct {
 	DefaultChain    domain.Chain
 	DefaultCurrency string
 	WalletAddress   string
+	ChainWallets    map[domain.Chain]string // Optional: hot wallet per chain, chains not listed use WalletAddress
+	TokenRegistry   *TokenRegistry          // Required: tokens accepted on each chain and how they are priced
 	FeePercentage   float64
 	ExpiryMinutes   int
-	RedisClient     *redis.Client // Optional: for real-time events
+	RedisClient     *redis.Client        // Optional: for real-time events
+	LedgerService   domain.LedgerService // Optional: for recording overpayment surplus as refundable credit
+	// Optional: books test payments to the test mode system accounts, they are not booked without it
+	TestModeLedgerService domain.LedgerService
+
+	// Optional: both are required for per-payment deposit addresses, without them every payment uses memo matching
+	DepositAddressRepository domain.DepositAddressRepository
+	DepositAddressGenerator  domain.DepositAddressGenerator
+	// Optional: required for Solana deposit addresses, whose token account the worker creates
+	DepositAddressQueue domain.DepositAddressQueue
+
+	// Optional: confirmation depth per chain and amount band, DefaultConfirmationPolicy when nil
+	ConfirmationPolicy domain.ConfirmationPolicy
+	// Optional: required by WatchConfirmations, the others are used when set
+	TransactionVerifier  domain.TransactionVerifier
+	BlockchainTxRecorder domain.BlockchainTxRecorder
+	ComplianceAlerter    domain.ComplianceAlerter
+	WebhookPublisher     domain.WebhookPublisher
+	ReorgWatchWindow     time.Duration // Defaults to DefaultReorgWatchWindow
+
+	// Optional: required to refund late payments, merchants with the refund policy fall back to review without it
+	LatePaymentRefunder domain.LatePaymentRefunder
+
+	// Optional: required to resolve unmatched inbound transfers
+	InboundTransferRepository domain.InboundTransferRepository
+
+	// Optional: both are required for rate-locked quotes
+	QuoteRepository domain.QuoteRepository
+	QuoteSigningKey string
+	QuoteValidity   time.Duration // Defaults to DefaultQuoteValidity
+
+	// Optional: required to add completed payments to the amount paid of their invoice
+	InvoiceRepository domain.InvoiceRepository
+
+	// Optional: required to mark subscription cycles paid when their payment completes
+	SubscriptionRepository domain.SubscriptionRepository
+
+	// Optional: required to give back the payment link use of payments that end unpaid
+	PaymentLinkRepository domain.PaymentLinkRepository
+
+	// Optional: required to accept EVM proofs of wallet ownership, they are rejected without it
+	OwnershipChallengeRepository domain.OwnershipChallengeRepository
+
+	// Optional: records merchant cancellations and expiry extensions in the audit log
+	AuditRecorder       domain.AuditRecorder
+	MaxExpiryExtension  time.Duration // Defaults to DefaultMaxExpiryExtension
+	MaxExpiryExtensions int           // Defaults to DefaultMaxExpiryExtensions
 }
 
 // NewPaymentService creates a new payment service
 func NewPaymentService(
 	paymentRepo domain.PaymentRepository,
+	transferRepo domain.PaymentTransferRepository,
 	merchantRepo domain.MerchantRepository,
 	exchangeRateService domain.ExchangeRateProvider,
 	complianceService domain.ComplianceService, // Optional: for compliance checks
@@ -87,17 +178,68 @@ func NewPaymentService(
 		defaultCurrency = config.DefaultCurrency
 	}
 
+	confirmationPolicy := config.ConfirmationPolicy
+	if confirmationPolicy == nil {
+		confirmationPolicy = domain.DefaultConfirmationPolicy()
+	}
+
+	reorgWatchWindow := DefaultReorgWatchWindow
+	if config.ReorgWatchWindow > 0 {
+		reorgWatchWindow = config.ReorgWatchWindow
+	}
+
+	quoteValidity := DefaultQuoteValidity
+	if config.QuoteValidity > 0 {
+		quoteValidity = config.QuoteValidity
+	}
+
+	maxExpiryExtension := DefaultMaxExpiryExtension
+	if config.MaxExpiryExtension > 0 {
+		maxExpiryExtension = config.MaxExpiryExtension
+	}
+
+	maxExpiryExtensions := DefaultMaxExpiryExtensions
+	if config.MaxExpiryExtensions > 0 {
+		maxExpiryExtensions = config.MaxExpiryExtensions
+	}
+
 	return &PaymentService{
 		paymentRepo:         paymentRepo,
+		transferRepo:        transferRepo,
 		merchantRepo:        merchantRepo,
 		exchangeRateService: exchangeRateService,
 		complianceService:   complianceService,
 		amlService:          amlService,
+		ledgerService:       config.LedgerService,
+		testLedgerService:   config.TestModeLedgerService,
+		depositAddressRepo:  config.DepositAddressRepository,
+		depositAddressGen:   config.DepositAddressGenerator,
+		depositAddressQueue: config.DepositAddressQueue,
 		redisClient:         config.RedisClient,
+		confirmationPolicy:  confirmationPolicy,
+		txVerifier:          config.TransactionVerifier,
+		txRecorder:          config.BlockchainTxRecorder,
+		complianceAlerter:   config.ComplianceAlerter,
+		webhookPublisher:    config.WebhookPublisher,
+		reorgWatchWindow:    reorgWatchWindow,
+		latePaymentRefunder: config.LatePaymentRefunder,
+		inboundTransferRepo: config.InboundTransferRepository,
+		quoteRepo:           config.QuoteRepository,
+		quoteSigningKey:     []byte(config.QuoteSigningKey),
+		quoteValidity:       quoteValidity,
+		invoiceRepo:         config.InvoiceRepository,
+		subscriptionRepo:    config.SubscriptionRepository,
+		paymentLinkRepo:     config.PaymentLinkRepository,
+		challengeRepo:       config.OwnershipChallengeRepository,
+		auditRecorder:       config.AuditRecorder,
+		maxExpiryExtension:  maxExpiryExtension,
+		maxExpiryExtensions: maxExpiryExtensions,
 		logger:              logger,
 		defaultChain:        defaultChain,
 		defaultCurrency:     defaultCurrency,
 		walletAddress:       config.WalletAddress,
+		chainWallets:        config.ChainWallets,
+		tokenRegistry:       config.TokenRegistry,
 		feePercentage:       feePercentage,
 		expiryMinutes:       expiryMinutes,
 	}
@@ -111,17 +253,33 @@ func NewPaymentService(
 // 4. DB Persistence
 func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePaymentRequest) (*domain.Payment, error) {
 	s.logger.WithFields(logrus.Fields{
-		"merchant_id": req.MerchantID,
-		"amount_vnd":  req.AmountVND,
-		"currency":    req.Currency,
-		"chain":       req.Chain,
+		"merchant_id":      req.MerchantID,
+		"amount_vnd":       req.AmountVND,
+		"pricing_currency": req.PricingCurrency,
+		"pricing_amount":   req.PricingAmount,
+		"currency":         req.Currency,
+		"chain":            req.Chain,
 	}).Info("Creating payment")
 
+	// A retried request with a payment ID gets the payment it created before
+	if req.PaymentID != "" {
+		existing, err := s.paymentRepo.GetByID(req.PaymentID)
+		switch {
+		case err == nil && existing.MerchantID == req.MerchantID:
+			s.logger.WithField("payment_id", existing.ID).Info("Payment already created")
+			return existing, nil
+		case err == nil:
+			return nil, fmt.Errorf("%w: payment ID is already used", domain.ErrInvalidPaymentState)
+		case !errors.Is(err, domain.ErrPaymentNotFound):
+			return nil, fmt.Errorf("failed to get payment: %w", err)
+		}
+	}
+
 	// ============================================
 	// STEP 1: INPUT VALIDATION
 	// ============================================
 	// Validate amount > 0
-	if req.AmountVND.LessThanOrEqual(decimal.Zero) {
+	if req.AmountVND.LessThanOrEqual(decimal.Zero) && req.PricingAmount.LessThanOrEqual(decimal.Zero) {
 		return nil, domain.ErrInvalidAmount
 	}
 
@@ -137,6 +295,24 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 		return nil, domain.ErrMerchantNotApproved
 	}
 
+	// Sandbox merchants create test payments, settled by simulation instead of on-chain transfers
+	testMode, err := s.merchantRepo.IsSandboxMerchant(req.MerchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant mode: %w", err)
+	}
+
+	// Split recipients are credited on completion, each of them must be an approved merchant
+	if err := s.validateSplitRecipients(req.MerchantID, testMode, req.Splits); err != nil {
+		return nil, err
+	}
+
+	// Convert the merchant price to VND, every amount below is derived from its VND equivalent
+	pricing, err := s.resolvePricing(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	amountVND := pricing.amountVND
+
 	// Set defaults if not provided
 	currency := req.Currency
 	if currency == "" {
@@ -149,21 +325,47 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 	}
 
 	// Validate chain and currency combination
-	if err := s.validateChainCurrency(chain, currency); err != nil {
+	token, err := s.lookupToken(chain, currency)
+	if err != nil {
 		return nil, err
 	}
 
-	// Get current exchange rate
-	exchangeRate, err := s.getExchangeRate(ctx, currency)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	var exchangeRate, amountCrypto decimal.Decimal
+	var quote *domain.Quote
+	if req.QuoteID != "" {
+		// Quotes lock VND amounts only
+		if pricing.currency != domain.PricingCurrencyVND {
+			return nil, fmt.Errorf("%w: quotes are priced in VND", domain.ErrQuoteMismatch)
+		}
+
+		// Honour the rate locked by the quote instead of the live rate
+		var option *domain.QuoteOption
+		quote, option, err = s.getLockedQuote(req.QuoteID, req.MerchantID, amountVND, chain, currency)
+		if err != nil {
+			return nil, err
+		}
+		exchangeRate = option.ExchangeRate
+		amountCrypto = option.AmountCrypto
+	} else {
+		// Get current exchange rate
+		exchangeRate, err = s.getExchangeRate(ctx, chain, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+		}
+
+		// Calculate crypto amount (VND / exchange rate)
+		amountCrypto = amountVND.Div(exchangeRate).Round(6) // Round to 6 decimals for USDT/USDC
 	}
 
-	// Calculate crypto amount (VND / exchange rate)
-	amountCrypto := req.AmountVND.Div(exchangeRate).Round(6) // Round to 6 decimals for USDT/USDC
+	if err := token.CheckAmount(amountCrypto); err != nil {
+		return nil, err
+	}
 
 	// Generate payment ID early (needed for signature verification and compliance checks)
-	paymentID := uuid.New().String()
+	paymentID := req.PaymentID
+	if paymentID == "" {
+		paymentID = uuid.New().String()
+	}
 
 	// ============================================
 	// STEP 2: SIGNATURE VERIFICATION (if unhosted wallet)
@@ -181,17 +383,13 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 			return nil, fmt.Errorf("signature required for proof of wallet ownership when from_address is provided")
 		}
 
-		// Verify signature using Solana Ed25519 verification
-		var isValid bool
-		var verifyErr error
-
-		if req.SignedMessage != "" {
-			// Verify custom signed message
-			isValid, verifyErr = web3.VerifySolanaSignature(req.FromAddress, req.SignedMessage, req.Signature)
-		} else {
+		// Solana wallets sign with Ed25519, EVM wallets sign an EIP-191 or EIP-712 challenge with nonce and expiry
+		message := req.SignedMessage
+		if message == "" {
 			// Verify using standard payment challenge message
-			isValid, verifyErr = web3.VerifyPaymentSignature(req.FromAddress, paymentID, req.Signature)
+			message = web3.GenerateChallengeMessage(req.FromAddress, paymentID)
 		}
+		isValid, verifyErr := web3.VerifyOwnership(string(chain), req.FromAddress, message, req.Signature)
 
 		if verifyErr != nil {
 			s.logger.WithFields(logrus.Fields{
@@ -210,6 +408,10 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 			return nil, domain.ErrInvalidSignature
 		}
 
+		if err := s.claimOwnershipNonce(chain, req.FromAddress, message); err != nil {
+			return nil, err
+		}
+
 		s.logger.WithFields(logrus.Fields{
 			"merchant_id":  req.MerchantID,
 			"from_address": req.FromAddress,
@@ -231,7 +433,7 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 		tempPayment := &domain.Payment{
 			ID:           paymentID,
 			MerchantID:   req.MerchantID,
-			AmountVND:    req.AmountVND,
+			AmountVND:    amountVND,
 			AmountCrypto: amountCrypto,
 			Currency:     currency,
 			Chain:        chain,
@@ -268,6 +470,18 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 		return nil, fmt.Errorf("failed to generate payment reference: %w", err)
 	}
 
+	// Merchants in deposit mode get an address for this payment only, matched by recipient instead of memo
+	// Test payments never get one, listeners must not watch them
+	destinationWallet := s.walletAddressFor(chain)
+	var depositAddress *domain.DepositAddress
+	if !testMode && s.usesDepositAddress(req.MerchantID, chain) {
+		depositAddress, err = s.depositAddressGen.GenerateDepositAddress(ctx, paymentID, req.MerchantID, chain, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate deposit address: %w", err)
+		}
+		destinationWallet = depositAddress.Address
+	}
+
 	// Calculate expiration time
 	expiresAt := time.Now().Add(time.Duration(s.expiryMinutes) * time.Minute)
 
@@ -275,16 +489,20 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 	payment := &domain.Payment{
 		ID:                paymentID,
 		MerchantID:        req.MerchantID,
-		AmountVND:         req.AmountVND,
+		AmountVND:         amountVND,
 		AmountCrypto:      amountCrypto,
 		Currency:          currency,
 		Chain:             chain,
 		ExchangeRate:      exchangeRate,
+		PricingCurrency:   pricing.currency,
+		PricingAmount:     pricing.amount,
+		PricingRate:       pricing.rate,
 		Status:            domain.PaymentStatusCreated,
 		PaymentReference:  paymentReference,
-		DestinationWallet: s.walletAddress,
+		DestinationWallet: destinationWallet,
 		ExpiresAt:         expiresAt,
 		FeePercentage:     s.feePercentage,
+		TestMode:          testMode,
 	}
 
 	// Set optional fields
@@ -297,21 +515,74 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req port.CreatePayme
 	if req.CallbackURL != "" {
 		payment.CallbackURL = sql.NullString{String: req.CallbackURL, Valid: true}
 	}
+	if quote != nil {
+		payment.QuoteID = sql.NullString{String: quote.ID, Valid: true}
+	}
+	if req.PaymentLinkID != "" {
+		payment.PaymentLinkID = sql.NullString{String: req.PaymentLinkID, Valid: true}
+	}
+	if req.InvoiceID != "" {
+		payment.InvoiceID = sql.NullString{String: req.InvoiceID, Valid: true}
+	}
+	if req.SubscriptionCycleID != "" {
+		payment.SubscriptionCycleID = sql.NullString{String: req.SubscriptionCycleID, Valid: true}
+	}
 
 	// Calculate fee and net amount
 	payment.CalculateFee()
 
+	// Divide the net amount among the split recipients
+	if err := payment.ApplySplits(req.Splits); err != nil {
+		return nil, err
+	}
+
 	// Save to database (only after all security checks pass)
 	if err := s.paymentRepo.Create(payment); err != nil {
 		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
 
+	if depositAddress != nil {
+		if err := s.saveDepositAddress(ctx, depositAddress); err != nil {
+			// The listeners only watch recorded and provisioned deposit addresses, the payment could never be matched
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": payment.ID,
+				"address":    depositAddress.Address,
+				"error":      err.Error(),
+			}).Error("Failed to save deposit address, failing payment")
+			if updateErr := s.transitionPayment(payment, domain.PaymentStatusFailed, domain.PaymentActorSystem, "", "deposit address could not be saved"); updateErr != nil {
+				s.logger.WithError(updateErr).WithField("payment_id", payment.ID).Error("Failed to fail payment")
+			}
+			return nil, fmt.Errorf("failed to save deposit address: %w", err)
+		}
+	}
+
+	if quote != nil {
+		if err := s.quoteRepo.MarkUsed(quote.ID, payment.ID); err != nil {
+			// Another payment took the quote first, this one must not keep the locked rate
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": payment.ID,
+				"quote_id":   quote.ID,
+				"error":      err.Error(),
+			}).Warn("Failed to mark quote used, failing payment")
+			if updateErr := s.transitionPayment(payment, domain.PaymentStatusFailed, domain.PaymentActorSystem, "", "quote could not be marked used"); updateErr != nil {
+				s.logger.WithError(updateErr).WithField("payment_id", payment.ID).Error("Failed to fail payment")
+			}
+			if errors.Is(err, domain.ErrQuoteAlreadyUsed) {
+				return nil, domain.ErrQuoteAlreadyUsed
+			}
+			return nil, fmt.Errorf("failed to mark quote used: %w", err)
+		}
+	}
+
 	s.logger.WithFields(logrus.Fields{
 		"payment_id":        payment.ID,
 		"amount_vnd":        payment.AmountVND,
+		"pricing_currency":  payment.PricingCurrency,
+		"pricing_amount":    payment.PricingAmount,
 		"amount_crypto":     payment.AmountCrypto,
 		"exchange_rate":     payment.ExchangeRate,
 		"payment_reference": payment.PaymentReference,
+		"test_mode":         payment.TestMode,
 	}).Info("Payment created successfully")
 
 	return payment, nil
@@ -359,19 +630,22 @@ func (s *PaymentService) ValidatePayment(ctx context.Context, paymentID string)
 	}
 
 	// Check if payment is already completed
-	if payment.Status == domain.PaymentStatusCompleted {
+	if payment.IsCompleted() {
 		return domain.ErrPaymentAlreadyCompleted
 	}
 
 	// Check if payment is in a valid state
-	if payment.Status != domain.PaymentStatusCreated && payment.Status != domain.PaymentStatusPending {
+	if payment.Status != domain.PaymentStatusCreated && payment.Status != domain.PaymentStatusPending && payment.Status != domain.PaymentStatusUnderpaid {
 		return domain.ErrInvalidPaymentState
 	}
 
 	return nil
 }
 
-// ConfirmPayment confirms a payment after blockchain transaction is detected
+// ConfirmPayment records a blockchain transfer against a payment and updates its status.
+// Transfers accumulate: a payment that received less than requested becomes underpaid and
+// accepts top-up transfers; a payment that received more completes as overpaid and the
+// surplus is recorded in the ledger as a refundable credit.
 func (s *PaymentService) ConfirmPayment(ctx context.Context, req port.ConfirmPaymentRequest) (*domain.Payment, error) {
 	s.logger.WithFields(logrus.Fields{
 		"payment_id":    req.PaymentID,
@@ -379,6 +653,10 @@ func (s *PaymentService) ConfirmPayment(ctx context.Context, req port.ConfirmPay
 		"actual_amount": req.ActualAmount,
 	}).Info("Confirming payment")
 
+	if req.ActualAmount.LessThanOrEqual(decimal.Zero) {
+		return nil, domain.ErrInvalidAmount
+	}
+
 	// Get payment
 	payment, err := s.paymentRepo.GetByID(req.PaymentID)
 	if err != nil {
@@ -388,105 +666,296 @@ func (s *PaymentService) ConfirmPayment(ctx context.Context, req port.ConfirmPay
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 
-	// Validate payment can be confirmed
-	if !payment.CanBeConfirmed() {
+	// Test payments are never settled by real transfers, e.g. one carrying their memo
+	if payment.TestMode != req.Simulated {
 		s.logger.WithFields(logrus.Fields{
 			"payment_id": req.PaymentID,
-			"status":     payment.Status,
-			"is_expired": payment.IsExpired(),
-		}).Warn("Payment cannot be confirmed")
-
-		if payment.IsExpired() {
-			return nil, domain.ErrPaymentExpired
-		}
-		if payment.Status == domain.PaymentStatusCompleted {
-			return nil, domain.ErrPaymentAlreadyCompleted
+			"tx_hash":    req.TxHash,
+			"test_mode":  payment.TestMode,
+		}).Warn("Transfer does not match the payment mode")
+		if payment.TestMode {
+			return nil, domain.ErrTestPaymentNotSimulated
 		}
-		return nil, domain.ErrInvalidPaymentState
+		return nil, domain.ErrNotTestPayment
+	}
+
+	if err := s.checkTransferAsset(payment, req); err != nil {
+		return nil, err
 	}
 
-	// Verify amount matches expected amount exactly
-	// Allow a small tolerance for rounding errors (0.000001 crypto)
-	tolerance := decimal.NewFromFloat(0.000001)
-	diff := req.ActualAmount.Sub(payment.AmountCrypto).Abs()
-	if diff.GreaterThan(tolerance) {
+	// Listeners may deliver the same transfer more than once; it only counts once
+	existing, err := s.transferRepo.GetByTxHash(req.Chain, req.TxHash)
+	if err == nil {
+		if existing.PaymentID != payment.ID {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id":          req.PaymentID,
+				"tx_hash":             req.TxHash,
+				"transfer_payment_id": existing.PaymentID,
+			}).Warn("Transfer already recorded against a different payment")
+			return nil, domain.ErrInvalidPaymentState
+		}
+
 		s.logger.WithFields(logrus.Fields{
-			"payment_id":      req.PaymentID,
-			"expected_amount": payment.AmountCrypto,
-			"actual_amount":   req.ActualAmount,
-			"difference":      diff,
-		}).Error("Payment amount mismatch")
-		return nil, domain.ErrAmountMismatch
+			"payment_id": req.PaymentID,
+			"tx_hash":    req.TxHash,
+		}).Info("Transfer already recorded, skipping")
+		return payment, nil
+	}
+	if !errors.Is(err, domain.ErrTransferNotFound) {
+		return nil, fmt.Errorf("failed to check payment transfer: %w", err)
 	}
 
-	// Update payment with transaction details
-	now := time.Now()
-	payment.Status = domain.PaymentStatusConfirming
-	payment.TxHash = sql.NullString{String: req.TxHash, Valid: true}
-	payment.TxConfirmations = sql.NullInt32{Int32: req.Confirmations, Valid: true}
-	payment.FromAddress = sql.NullString{String: req.FromAddress, Valid: true}
-	payment.PaidAt = sql.NullTime{Time: now, Valid: true}
+	if err := s.checkAcceptsTransfer(payment); err != nil {
+		return nil, err
+	}
 
-	// If confirmations meet threshold, mark as completed
-	// For Solana with finalized commitment, confirmations should be sufficient
-	if req.Confirmations >= 1 {
-		payment.Status = domain.PaymentStatusCompleted
-		payment.ConfirmedAt = sql.NullTime{Time: now, Valid: true}
+	if req.Actor == "" {
+		req.Actor = domain.PaymentActorListener
+	}
 
-		// COMPLIANCE: Update merchant monthly volume when payment is completed
-		if s.merchantRepo != nil {
-			// Calculate USD amount (for USDT/USDC, crypto amount IS USD amount)
-			amountUSD := payment.AmountCrypto
-
-			// Update merchant in database via port
-			err = s.merchantRepo.UpdateMerchantVolume(payment.MerchantID, amountUSD)
-			if err != nil {
-				s.logger.WithFields(logrus.Fields{
-					"payment_id":  payment.ID,
-					"merchant_id": payment.MerchantID,
-					"amount_usd":  amountUSD.String(),
-					"error":       err.Error(),
-				}).Error("Failed to update merchant monthly volume (non-fatal)")
-				// Don't fail payment confirmation if monthly volume update fails
-			} else {
-				s.logger.WithFields(logrus.Fields{
-					"payment_id":  payment.ID,
-					"merchant_id": payment.MerchantID,
-					"amount_usd":  amountUSD.String(),
-				}).Info("Merchant monthly volume updated successfully")
-			}
+	for attempt := 1; ; attempt++ {
+		// Transfers after expiry are held and handled by the merchant's late payment policy
+		if payment.AcceptsLatePayment() {
+			err = s.recordLatePayment(ctx, payment, req)
+		} else {
+			err = s.applyTransfer(ctx, payment, req)
+		}
+		if !errors.Is(err, domain.ErrPaymentVersionConflict) || attempt == PaymentUpdateAttempts {
+			break
 		}
+
+		// Nothing was saved, the transfer is applied again to the payment as it is now
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": req.PaymentID,
+			"tx_hash":    req.TxHash,
+			"attempt":    attempt,
+		}).Warn("Payment updated concurrently, applying transfer again")
+
+		payment, err = s.paymentRepo.GetByID(req.PaymentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get payment: %w", err)
+		}
+		if err := s.checkAcceptsTransfer(payment); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": req.PaymentID,
+				"tx_hash":    req.TxHash,
+				"status":     payment.Status,
+			}).Warn("Payment updated concurrently no longer accepts the transfer")
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// checkTransferAsset rejects a transfer observed on another chain or carrying another token than
+// the payment asked for, e.g. USDC sent to a USDT deposit address. Listeners queue rejected
+// transfers as unmatched so an operator can resolve them.
+func (s *PaymentService) checkTransferAsset(payment *domain.Payment, req port.ConfirmPaymentRequest) error {
+	var err error
+	switch {
+	case req.Chain != payment.Chain:
+		err = fmt.Errorf("%w: transfer on %q, payment on %q", domain.ErrTransferChainMismatch, req.Chain, payment.Chain)
+	case !strings.EqualFold(req.Currency, payment.Currency):
+		err = fmt.Errorf("%w: transfer of %q, payment in %q", domain.ErrTransferCurrencyMismatch, req.Currency, payment.Currency)
+	default:
+		return nil
 	}
 
-	// Update payment in database
-	if err := s.paymentRepo.Update(payment); err != nil {
-		return nil, fmt.Errorf("failed to update payment: %w", err)
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":        payment.ID,
+		"tx_hash":           req.TxHash,
+		"transfer_chain":    req.Chain,
+		"transfer_currency": req.Currency,
+		"payment_chain":     payment.Chain,
+		"payment_currency":  payment.Currency,
+	}).Warn("Transfer asset does not match the payment")
+	return err
+}
+
+// checkAcceptsTransfer returns why a transfer cannot be counted toward the payment in its current status
+func (s *PaymentService) checkAcceptsTransfer(payment *domain.Payment) error {
+	if payment.AcceptsLatePayment() || payment.CanBeConfirmed() {
+		return nil
 	}
 
 	s.logger.WithFields(logrus.Fields{
 		"payment_id": payment.ID,
 		"status":     payment.Status,
-		"tx_hash":    req.TxHash,
-	}).Info("Payment confirmed successfully")
+		"is_expired": payment.IsExpired(),
+	}).Warn("Payment cannot be confirmed")
+
+	if payment.IsExpired() {
+		return domain.ErrPaymentExpired
+	}
+	if payment.IsCompleted() {
+		return domain.ErrPaymentAlreadyCompleted
+	}
+	return domain.ErrInvalidPaymentState
+}
+
+// applyTransfer adds a transfer to the payment, resolves its status against the amount received and
+// saves it with the transfer. Returns ErrPaymentVersionConflict if the payment changed since it was read.
+func (s *PaymentService) applyTransfer(ctx context.Context, payment *domain.Payment, req port.ConfirmPaymentRequest) error {
+	transfer := newPaymentTransfer(payment, req)
+	addTransfer(payment, req)
+
+	policy := s.getTolerancePolicy(payment.MerchantID)
+	now := time.Now()
+
+	status := payment.ResolveAmountStatus(policy)
+	switch {
+	case status == domain.PaymentStatusUnderpaid:
+		// Stays open for top-up transfers
+	case !s.transfersConfirmed(payment, transfer):
+		// WatchConfirmations completes the payment once every transfer reaches the required depth
+		status = domain.PaymentStatusConfirming
+	default:
+		payment.ConfirmedAt = sql.NullTime{Time: now, Valid: true}
+	}
+
+	reason := fmt.Sprintf("transfer %s of %s %s received", req.TxHash, req.ActualAmount, payment.Currency)
+	if err := s.transitionPaymentWithTransfer(payment, status, req.Actor, req.ActorID, reason, transfer); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	if payment.Status == domain.PaymentStatusOverpaid {
+		s.recordSurplus(payment)
+	}
+	if payment.IsCompleted() {
+		s.updateMerchantVolume(payment)
+		s.recordPaymentSplit(ctx, payment)
+		s.recordInvoicePayment(ctx, payment)
+		s.recordSubscriptionPayment(ctx, payment)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":      payment.ID,
+		"status":          payment.Status,
+		"tx_hash":         req.TxHash,
+		"amount_received": payment.AmountReceived,
+		"amount_crypto":   payment.AmountCrypto,
+	}).Info("Payment transfer recorded successfully")
 
 	// Publish real-time event to Redis for WebSocket clients
-	eventType := "payment.confirming"
-	eventMessage := "Payment is being confirmed"
-	if payment.Status == domain.PaymentStatusCompleted {
-		eventType = "payment.completed"
-		eventMessage = "Payment completed successfully"
+	s.publishStatusEvent(ctx, payment, req.TxHash)
+	s.publishStatusWebhook(ctx, payment)
+
+	return nil
+}
+
+// newPaymentTransfer builds the transfer that counts toward the payment, it is saved with the payment
+// The transfer is recorded on the chain it was observed on, which WatchConfirmations verifies it against
+func newPaymentTransfer(payment *domain.Payment, req port.ConfirmPaymentRequest) *domain.PaymentTransfer {
+	transfer := &domain.PaymentTransfer{
+		PaymentID:     payment.ID,
+		TxHash:        req.TxHash,
+		Amount:        req.ActualAmount,
+		Currency:      payment.Currency,
+		Chain:         req.Chain,
+		Confirmations: req.Confirmations,
+	}
+	if req.FromAddress != "" {
+		transfer.FromAddress = sql.NullString{String: req.FromAddress, Valid: true}
+	}
+	if req.Simulated {
+		// Nothing is on-chain, the confirmation watcher must not look for it
+		transfer.FinalizedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	}
 
-	s.publishPaymentEvent(ctx, PaymentEvent{
-		Type:      eventType,
-		PaymentID: payment.ID,
-		Status:    string(payment.Status),
-		TxHash:    req.TxHash,
-		Timestamp: time.Now(),
-		Message:   eventMessage,
-	})
+	return transfer
+}
 
-	return payment, nil
+// addTransfer adds a recorded transfer to the payment's amount received and transaction details
+// (the payment itself is not saved)
+func addTransfer(payment *domain.Payment, req port.ConfirmPaymentRequest) {
+	payment.AmountReceived = payment.AmountReceived.Add(req.ActualAmount)
+	payment.TxHash = sql.NullString{String: req.TxHash, Valid: true}
+	payment.TxConfirmations = sql.NullInt32{Int32: req.Confirmations, Valid: true}
+	if req.FromAddress != "" {
+		payment.FromAddress = sql.NullString{String: req.FromAddress, Valid: true}
+	}
+	if !payment.PaidAt.Valid {
+		payment.PaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+}
+
+// ListPaymentTransfers retrieves every transfer counted toward a payment
+func (s *PaymentService) ListPaymentTransfers(ctx context.Context, paymentID string) ([]*domain.PaymentTransfer, error) {
+	transfers, err := s.transferRepo.ListByPayment(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// WatchConfirmations re-verifies the inclusion of every transfer that is not yet finalized.
+// Confirming payments complete once all their transfers reach the depth required by the
+// confirmation policy; a transfer missing for ReversalMissingChecks consecutive runs reverses
+// its payment. Returns the number of payments completed and reversed.
+func (s *PaymentService) WatchConfirmations(ctx context.Context) (completed, reversed int, err error) {
+	if s.txVerifier == nil {
+		return 0, 0, domain.ErrTransactionVerifierNotConfigured
+	}
+
+	transfers, err := s.transferRepo.ListUnfinalized(time.Now().Add(-s.reorgWatchWindow), ConfirmationWatchBatchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list unfinalized transfers: %w", err)
+	}
+
+	var verifiedPayments []string
+	seen := make(map[string]bool)
+	for _, transfer := range transfers {
+		inclusion, err := s.txVerifier.VerifyTransaction(ctx, transfer.Chain, transfer.TxHash)
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": transfer.PaymentID,
+				"tx_hash":    transfer.TxHash,
+				"chain":      transfer.Chain,
+				"error":      err.Error(),
+			}).Warn("Failed to verify payment transfer")
+			continue
+		}
+
+		if !inclusion.Included {
+			if s.handleMissingTransfer(ctx, transfer) {
+				reversed++
+			}
+			continue
+		}
+
+		transfer.MissingChecks = 0
+		transfer.Confirmations = inclusion.Confirmations
+		if inclusion.Finalized {
+			transfer.FinalizedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+		if err := s.transferRepo.Update(transfer); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": transfer.PaymentID,
+				"tx_hash":    transfer.TxHash,
+				"error":      err.Error(),
+			}).Error("Failed to update payment transfer")
+			continue
+		}
+		s.recordInclusion(transfer.TxHash, inclusion)
+
+		if !seen[transfer.PaymentID] {
+			seen[transfer.PaymentID] = true
+			verifiedPayments = append(verifiedPayments, transfer.PaymentID)
+		}
+	}
+
+	for _, paymentID := range verifiedPayments {
+		if s.completeConfirmedPayment(ctx, paymentID) {
+			completed++
+		}
+	}
+
+	return completed, reversed, nil
 }
 
 // ExpirePayment marks a payment as expired
@@ -502,8 +971,8 @@ func (s *PaymentService) ExpirePayment(ctx context.Context, paymentID string) er
 		return fmt.Errorf("failed to get payment: %w", err)
 	}
 
-	// Only expire payments that are in created or pending status
-	if payment.Status != domain.PaymentStatusCreated && payment.Status != domain.PaymentStatusPending {
+	// Only payments that received nothing (created or pending) expire
+	if !payment.CanTransitionTo(domain.PaymentStatusExpired) {
 		s.logger.WithFields(logrus.Fields{
 			"payment_id": paymentID,
 			"status":     payment.Status,
@@ -511,12 +980,13 @@ func (s *PaymentService) ExpirePayment(ctx context.Context, paymentID string) er
 		return domain.ErrInvalidPaymentState
 	}
 
-	// Update status to expired
-	if err := s.paymentRepo.UpdateStatus(paymentID, domain.PaymentStatusExpired); err != nil {
+	// A transfer recorded meanwhile changes the version, the payment is then not expired
+	if err := s.transitionPayment(payment, domain.PaymentStatusExpired, domain.PaymentActorWorker, "", "payment window elapsed"); err != nil {
 		return fmt.Errorf("failed to expire payment: %w", err)
 	}
 
 	s.logger.WithField("payment_id", paymentID).Info("Payment expired successfully")
+	s.releasePaymentLinkUse(payment)
 
 	// Publish real-time event to Redis for WebSocket clients
 	s.publishPaymentEvent(ctx, PaymentEvent{
@@ -526,6 +996,7 @@ func (s *PaymentService) ExpirePayment(ctx context.Context, paymentID string) er
 		Timestamp: time.Now(),
 		Message:   "Payment has expired",
 	})
+	s.publishStatusWebhook(ctx, payment)
 
 	return nil
 }
@@ -547,15 +1018,15 @@ func (s *PaymentService) FailPayment(ctx context.Context, paymentID, reason stri
 	}
 
 	// Cannot fail a completed payment
-	if payment.Status == domain.PaymentStatusCompleted {
+	if payment.IsCompleted() {
 		return domain.ErrPaymentAlreadyCompleted
 	}
+	if !payment.CanTransitionTo(domain.PaymentStatusFailed) {
+		return domain.ErrInvalidPaymentState
+	}
 
-	// Update payment with failure reason and status
-	payment.Status = domain.PaymentStatusFailed
 	payment.FailureReason = sql.NullString{String: reason, Valid: true}
-
-	if err := s.paymentRepo.Update(payment); err != nil {
+	if err := s.transitionPayment(payment, domain.PaymentStatusFailed, domain.PaymentActorSystem, "", reason); err != nil {
 		return fmt.Errorf("failed to fail payment: %w", err)
 	}
 
@@ -563,6 +1034,7 @@ func (s *PaymentService) FailPayment(ctx context.Context, paymentID, reason stri
 		"payment_id": paymentID,
 		"reason":     reason,
 	}).Info("Payment marked as failed")
+	s.releasePaymentLinkUse(payment)
 
 	// Publish real-time event to Redis for WebSocket clients
 	s.publishPaymentEvent(ctx, PaymentEvent{
@@ -598,40 +1070,511 @@ func (s *PaymentService) GetExpiredPayments(ctx context.Context) ([]*domain.Paym
 
 // Helper functions
 
-// validateChainCurrency validates if the chain and currency combination is supported
-func (s *PaymentService) validateChainCurrency(chain domain.Chain, currency string) error {
-	validCombinations := map[domain.Chain]map[string]bool{
-		domain.ChainSolana: {
-			"USDT": true,
-			"USDC": true,
-		},
-		domain.ChainBSC: {
-			"USDT": true,
-			"BUSD": true,
-		},
+// getTolerancePolicy loads the merchant's tolerance policy, falling back to the default on error
+func (s *PaymentService) getTolerancePolicy(merchantID string) domain.TolerancePolicy {
+	policy := domain.DefaultTolerancePolicy()
+	if s.merchantRepo == nil {
+		return policy
+	}
+
+	underpayment, overpayment, err := s.merchantRepo.GetMerchantPaymentTolerance(merchantID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"merchant_id": merchantID,
+			"error":       err.Error(),
+		}).Warn("Failed to load merchant tolerance policy, using default")
+		return policy
+	}
+
+	policy.UnderpaymentPercentage = underpayment
+	policy.OverpaymentPercentage = overpayment
+	return policy
+}
+
+// walletAddressFor returns the hot wallet receiving memo-matched payments on the chain
+func (s *PaymentService) walletAddressFor(chain domain.Chain) string {
+	if address := s.chainWallets[chain]; address != "" {
+		return address
+	}
+	return s.walletAddress
+}
+
+// usesDepositAddress returns true if the merchant wants a deposit address per payment on the chain
+// Falls back to memo matching when deposit addresses are not configured or the mode cannot be loaded
+func (s *PaymentService) usesDepositAddress(merchantID string, chain domain.Chain) bool {
+	if s.depositAddressGen == nil || s.depositAddressRepo == nil || s.merchantRepo == nil {
+		return false
+	}
+
+	mode, err := s.merchantRepo.GetMerchantAddressMode(merchantID, string(chain))
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"merchant_id": merchantID,
+			"chain":       chain,
+			"error":       err.Error(),
+		}).Warn("Failed to load merchant address mode, using memo matching")
+		return false
+	}
+
+	return domain.AddressMode(mode) == domain.AddressModeDeposit
+}
+
+// saveDepositAddress records a new deposit address and hands a pending one over to the worker,
+// which creates its on-chain account off the request path
+func (s *PaymentService) saveDepositAddress(ctx context.Context, address *domain.DepositAddress) error {
+	if address.Status == domain.DepositAddressStatusPending && s.depositAddressQueue == nil {
+		return fmt.Errorf("deposit address queue not configured for %s", address.Chain)
+	}
+
+	if err := s.depositAddressRepo.Create(address); err != nil {
+		return err
+	}
+
+	if address.Status == domain.DepositAddressStatusPending {
+		if err := s.depositAddressQueue.EnqueueDepositProvision(ctx, address.PaymentID); err != nil {
+			return fmt.Errorf("failed to enqueue deposit address provisioning: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UsesDepositAddress returns true if the payment is paid to a deposit address of its own instead of a memo matched wallet
+func (s *PaymentService) UsesDepositAddress(ctx context.Context, payment *domain.Payment) bool {
+	if s.depositAddressRepo == nil || payment.TestMode {
+		return false
+	}
+
+	address, err := s.depositAddressRepo.GetByPaymentID(payment.ID)
+	if err != nil || address == nil {
+		return false
+	}
+	return address.Address == payment.DestinationWallet
+}
+
+// updateMerchantVolume adds a completed payment to the merchant's monthly volume (non-fatal)
+func (s *PaymentService) updateMerchantVolume(payment *domain.Payment) {
+	// COMPLIANCE: Update merchant monthly volume when payment is completed
+	if s.merchantRepo == nil {
+		return
+	}
+
+	// Calculate USD amount (for USDT/USDC, crypto amount IS USD amount)
+	amountUSD := payment.AmountCrypto
+
+	// Update merchant in database via port
+	if err := s.merchantRepo.UpdateMerchantVolume(payment.MerchantID, amountUSD); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id":  payment.ID,
+			"merchant_id": payment.MerchantID,
+			"amount_usd":  amountUSD.String(),
+			"error":       err.Error(),
+		}).Error("Failed to update merchant monthly volume (non-fatal)")
+		// Don't fail payment confirmation if monthly volume update fails
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":  payment.ID,
+		"merchant_id": payment.MerchantID,
+		"amount_usd":  amountUSD.String(),
+	}).Info("Merchant monthly volume updated successfully")
+}
+
+// ledgerFor returns the ledger service the entries of the payment are booked with, nil if there is none
+// Test payments never use the live ledger service, whose system accounts hold real funds
+func (s *PaymentService) ledgerFor(payment *domain.Payment) domain.LedgerService {
+	if payment.TestMode {
+		return s.testLedgerService
+	}
+	return s.ledgerService
+}
+
+// recordSurplus records the overpaid amount in the ledger as a refundable credit (non-fatal)
+// The entry is keyed by the payment and its last transfer hash, ReconcileSurplus retries failures.
+// Returns true if the surplus is recorded.
+func (s *PaymentService) recordSurplus(payment *domain.Payment) bool {
+	surplus := payment.SurplusAmount()
+	ledger := s.ledgerFor(payment)
+	if ledger == nil || surplus.IsZero() {
+		return false
+	}
+
+	if err := ledger.RecordPaymentSurplus(payment.ID, payment.MerchantID, surplus, payment.Currency, payment.TxHash.String); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"surplus":    surplus.String(),
+			"error":      err.Error(),
+		}).Error("Failed to record overpayment surplus in ledger")
+		return false
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"surplus":    surplus.String(),
+		"currency":   payment.Currency,
+	}).Info("Overpayment surplus recorded as refundable credit")
+	return true
+}
+
+// ReconcileSurplus records the surplus of payments overpaid within the reorg watch window again,
+// so a surplus whose ledger entry failed after the payment was saved is not lost.
+// The ledger skips surpluses already recorded, the count includes them.
+func (s *PaymentService) ReconcileSurplus(ctx context.Context) (int, error) {
+	since := time.Now().Add(-s.reorgWatchWindow)
+
+	reconciled := 0
+	for offset := 0; ; offset += SurplusReconcileBatchSize {
+		payments, err := s.paymentRepo.ListByStatus(domain.PaymentStatusOverpaid, SurplusReconcileBatchSize, offset)
+		if err != nil {
+			return reconciled, fmt.Errorf("failed to list overpaid payments: %w", err)
+		}
+
+		for _, payment := range payments {
+			// Newest first, older payments were retried for the whole window already
+			if payment.CreatedAt.Before(since) {
+				return reconciled, nil
+			}
+			if s.recordSurplus(payment) {
+				reconciled++
+			}
+		}
+
+		if len(payments) < SurplusReconcileBatchSize {
+			return reconciled, nil
+		}
+	}
+}
+
+// transfersConfirmed returns true if every transfer of the payment, including the unsaved ones being
+// applied, reached the depth the confirmation policy requires for the transfer's chain and payment amount
+func (s *PaymentService) transfersConfirmed(payment *domain.Payment, unsaved ...*domain.PaymentTransfer) bool {
+	transfers, err := s.transferRepo.ListByPayment(payment.ID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"error":      err.Error(),
+		}).Warn("Failed to list payment transfers, keeping payment confirming")
+		return false
+	}
+	transfers = append(transfers, unsaved...)
+
+	for _, transfer := range transfers {
+		// For USDT/USDC, crypto amount IS USD amount
+		requirement := s.confirmationPolicy.RequirementFor(transfer.Chain, payment.AmountCrypto)
+		if !requirement.IsSatisfied(transfer.Confirmations, transfer.IsFinalized()) {
+			return false
+		}
+	}
+
+	return len(transfers) > 0
+}
+
+// completeConfirmedPayment completes a confirming payment whose transfers reached the required depth
+// Returns true if the payment was completed
+func (s *PaymentService) completeConfirmedPayment(ctx context.Context, paymentID string) bool {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": paymentID,
+			"error":      err.Error(),
+		}).Error("Failed to get payment")
+		return false
+	}
+
+	if payment.Status != domain.PaymentStatusConfirming || !s.transfersConfirmed(payment) {
+		return false
+	}
+
+	status := payment.ResolveAmountStatus(s.getTolerancePolicy(payment.MerchantID))
+	payment.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	// A conflicting update (e.g. a top-up transfer) is picked up by the next run
+	if err := s.transitionPayment(payment, status, domain.PaymentActorWorker, "", "transfers reached required confirmations"); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": paymentID,
+			"error":      err.Error(),
+		}).Error("Failed to complete confirmed payment")
+		return false
+	}
+
+	if payment.Status == domain.PaymentStatusOverpaid {
+		s.recordSurplus(payment)
+	}
+	if payment.IsCompleted() {
+		s.updateMerchantVolume(payment)
+		s.recordPaymentSplit(ctx, payment)
+		s.recordInvoicePayment(ctx, payment)
+		s.recordSubscriptionPayment(ctx, payment)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"status":     payment.Status,
+		"chain":      payment.Chain,
+	}).Info("Payment reached required confirmations")
+
+	s.publishStatusEvent(ctx, payment, payment.TxHash.String)
+	s.publishStatusWebhook(ctx, payment)
+
+	return payment.IsCompleted()
+}
+
+// handleMissingTransfer counts a check that did not find the transfer on-chain and reverses
+// the payment once the transfer has been missing for ReversalMissingChecks consecutive checks
+// Returns true if the payment was reversed
+func (s *PaymentService) handleMissingTransfer(ctx context.Context, transfer *domain.PaymentTransfer) bool {
+	transfer.MissingChecks++
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":     transfer.PaymentID,
+		"tx_hash":        transfer.TxHash,
+		"chain":          transfer.Chain,
+		"missing_checks": transfer.MissingChecks,
+	}).Warn("Payment transfer not found on-chain")
+
+	if transfer.MissingChecks < ReversalMissingChecks {
+		if err := s.transferRepo.Update(transfer); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": transfer.PaymentID,
+				"tx_hash":    transfer.TxHash,
+				"error":      err.Error(),
+			}).Error("Failed to update payment transfer")
+		}
+		return false
+	}
+
+	payment, err := s.paymentRepo.GetByID(transfer.PaymentID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": transfer.PaymentID,
+			"error":      err.Error(),
+		}).Error("Failed to get payment")
+		return false
+	}
+
+	now := time.Now()
+	reason := fmt.Sprintf("transaction %s not found on %s after %d checks (chain reorganization)", transfer.TxHash, transfer.Chain, transfer.MissingChecks)
+	wasCompleted := payment.IsCompleted()
+	reversePayment := !payment.IsReversed()
+	if reversePayment && !payment.CanTransitionTo(domain.PaymentStatusReversed) {
+		// e.g. a payment failed for compliance, only the transfer is marked reversed
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"status":     payment.Status,
+			"tx_hash":    transfer.TxHash,
+		}).Warn("Payment cannot be reversed from its status")
+		reversePayment = false
+	}
+
+	if reversePayment {
+		payment.ReversedAt = sql.NullTime{Time: now, Valid: true}
+		payment.FailureReason = sql.NullString{String: reason, Valid: true}
+		if err := s.transitionPayment(payment, domain.PaymentStatusReversed, domain.PaymentActorWorker, "", reason); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"payment_id": payment.ID,
+				"error":      err.Error(),
+			}).Error("Failed to reverse payment")
+			return false
+		}
+	}
+
+	transfer.ReversedAt = sql.NullTime{Time: now, Valid: true}
+	if err := s.transferRepo.Update(transfer); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": transfer.PaymentID,
+			"tx_hash":    transfer.TxHash,
+			"error":      err.Error(),
+		}).Error("Failed to mark payment transfer as reversed")
+	}
+	if s.txRecorder != nil {
+		if err := s.txRecorder.MarkAsFailed(transfer.TxHash, "reorged: "+reason); err != nil {
+			s.logger.WithField("tx_hash", transfer.TxHash).WithError(err).Debug("Failed to mark blockchain transaction as failed")
+		}
+	}
+
+	if !reversePayment {
+		return false
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"tx_hash":    transfer.TxHash,
+		"chain":      transfer.Chain,
+	}).Error("Payment reversed: transfer disappeared from the chain")
+
+	if wasCompleted && s.merchantRepo != nil {
+		if err := s.merchantRepo.UpdateMerchantVolume(payment.MerchantID, payment.AmountCrypto.Neg()); err != nil {
+			s.logger.WithField("payment_id", payment.ID).WithError(err).Error("Failed to deduct reversed payment from merchant volume")
+		}
+	}
+	if wasCompleted {
+		s.reverseInvoicePayment(ctx, payment)
+		s.reverseSubscriptionPayment(ctx, payment)
+	}
+
+	if ledger := s.ledgerFor(payment); ledger != nil {
+		if err := ledger.RecordPaymentReversed(payment.ID, payment.MerchantID, reason); err != nil {
+			s.logger.WithField("payment_id", payment.ID).WithError(err).Error("Failed to reverse payment ledger entries")
+		}
+	}
+
+	if s.complianceAlerter != nil {
+		if err := s.complianceAlerter.RaisePaymentReversalAlert(ctx, payment.ID, payment.Chain, transfer.TxHash, reason); err != nil {
+			s.logger.WithField("payment_id", payment.ID).WithError(err).Error("Failed to raise payment reversal alert")
+		}
+	}
+
+	s.publishStatusEvent(ctx, payment, transfer.TxHash)
+
+	if s.webhookPublisher != nil {
+		data := map[string]interface{}{
+			"payment_id":      payment.ID,
+			"status":          string(payment.Status),
+			"tx_hash":         transfer.TxHash,
+			"chain":           string(payment.Chain),
+			"amount_received": payment.AmountReceived.String(),
+			"currency":        payment.Currency,
+			"reason":          reason,
+		}
+		if err := s.webhookPublisher.PublishWebhook(ctx, payment.MerchantID, domain.PaymentEventReversed, data); err != nil {
+			s.logger.WithField("payment_id", payment.ID).WithError(err).Warn("Failed to publish payment reversal webhook")
+		}
+	}
+
+	return true
+}
+
+// recordInclusion mirrors the verified depth of a transfer onto the listener's transaction record
+// Transfers without a record (e.g. recorded before listeners persisted them) are ignored
+func (s *PaymentService) recordInclusion(txHash string, inclusion *domain.TransactionInclusion) {
+	if s.txRecorder == nil {
+		return
 	}
 
-	if currencies, ok := validCombinations[chain]; ok {
-		if currencies[currency] {
-			return nil
+	if err := s.txRecorder.UpdateConfirmations(txHash, int(inclusion.Confirmations)); err != nil {
+		s.logger.WithField("tx_hash", txHash).WithError(err).Debug("Failed to update blockchain transaction confirmations")
+		return
+	}
+	if inclusion.Finalized {
+		if err := s.txRecorder.MarkAsFinalized(txHash); err != nil {
+			s.logger.WithField("tx_hash", txHash).WithError(err).Debug("Failed to mark blockchain transaction as finalized")
 		}
 	}
+}
+
+// publishStatusEvent publishes the real-time event matching the current status of the payment
+func (s *PaymentService) publishStatusEvent(ctx context.Context, payment *domain.Payment, txHash string) {
+	eventType := "payment.confirming"
+	eventMessage := "Payment is being confirmed"
+	switch payment.Status {
+	case domain.PaymentStatusUnderpaid:
+		eventType = "payment.underpaid"
+		eventMessage = fmt.Sprintf("Payment underpaid: %s %s remaining", payment.RemainingAmount().String(), payment.Currency)
+	case domain.PaymentStatusOverpaid:
+		eventType = "payment.overpaid"
+		eventMessage = fmt.Sprintf("Payment completed with surplus of %s %s", payment.SurplusAmount().String(), payment.Currency)
+	case domain.PaymentStatusCompleted:
+		eventType = "payment.completed"
+		eventMessage = "Payment completed successfully"
+	case domain.PaymentStatusReversed:
+		eventType = domain.PaymentEventReversed
+		eventMessage = "Payment reversed: the transaction is no longer on-chain"
+	case domain.PaymentStatusLatePaid:
+		eventType = domain.PaymentEventLatePaid
+		eventMessage = "Payment received after expiry, held for review"
+	}
 
-	return fmt.Errorf("%w: %s on %s", domain.ErrInvalidChain, currency, chain)
+	s.publishPaymentEvent(ctx, PaymentEvent{
+		Type:      eventType,
+		PaymentID: payment.ID,
+		Status:    string(payment.Status),
+		TxHash:    txHash,
+		Timestamp: time.Now(),
+		Message:   eventMessage,
+	})
 }
 
-// getExchangeRate retrieves the exchange rate for a specific currency
-func (s *PaymentService) getExchangeRate(ctx context.Context, currency string) (decimal.Decimal, error) {
-	switch currency {
-	case "USDT":
+// publishStatusWebhook sends the merchant the webhook of the payment's new status
+// Confirming payments are not announced, their completion is
+func (s *PaymentService) publishStatusWebhook(ctx context.Context, payment *domain.Payment) {
+	var event string
+	switch payment.Status {
+	case domain.PaymentStatusCompleted:
+		event = domain.PaymentEventCompleted
+	case domain.PaymentStatusOverpaid:
+		event = domain.PaymentEventOverpaid
+	case domain.PaymentStatusUnderpaid:
+		event = domain.PaymentEventUnderpaid
+	case domain.PaymentStatusExpired:
+		event = domain.PaymentEventExpired
+	default:
+		return
+	}
+
+	data := map[string]interface{}{
+		"payment_id":       payment.ID,
+		"status":           string(payment.Status),
+		"order_id":         payment.GetOrderID(),
+		"tx_hash":          payment.GetTxHash(),
+		"chain":            string(payment.Chain),
+		"amount_vnd":       payment.AmountVND.String(),
+		"amount_crypto":    payment.AmountCrypto.String(),
+		"amount_received":  payment.AmountReceived.String(),
+		"remaining_amount": payment.RemainingAmount().String(),
+		"currency":         payment.Currency,
+		"test_mode":        payment.TestMode,
+	}
+	if payment.ConfirmedAt.Valid {
+		data["confirmed_at"] = payment.ConfirmedAt.Time
+	}
+
+	s.publishMerchantWebhook(ctx, payment, event, data)
+}
+
+// lookupToken returns the enabled registry token of currency on chain
+// Returns ErrInvalidChain if the chain does not accept the currency
+func (s *PaymentService) lookupToken(chain domain.Chain, currency string) (*domain.Token, error) {
+	if s.tokenRegistry == nil {
+		return nil, fmt.Errorf("%w: token registry not configured", domain.ErrInvalidChain)
+	}
+
+	return s.tokenRegistry.Lookup(chain, currency)
+}
+
+// getExchangeRate retrieves the VND exchange rate of the token of currency on chain
+// Disabled tokens are still priced, payments created before they were disabled are requoted with them.
+// Payments of a batch share the rate of each price source.
+func (s *PaymentService) getExchangeRate(ctx context.Context, chain domain.Chain, currency string) (decimal.Decimal, error) {
+	if s.tokenRegistry == nil {
+		return decimal.Zero, fmt.Errorf("%w: token registry not configured", domain.ErrInvalidChain)
+	}
+
+	token, err := s.tokenRegistry.Find(chain, currency)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	key := string(token.PriceSource)
+	if token.PriceSource == domain.PriceSourcePeg {
+		key = token.PegCurrency
+	}
+	return cachedRate(ctx, key+"/VND", func() (decimal.Decimal, error) {
+		return s.fetchExchangeRate(ctx, token)
+	})
+}
+
+// fetchExchangeRate fetches the current VND rate of a token from the exchange rate provider
+func (s *PaymentService) fetchExchangeRate(ctx context.Context, token *domain.Token) (decimal.Decimal, error) {
+	switch token.PriceSource {
+	case domain.PriceSourceUSDT:
 		return s.exchangeRateService.GetUSDTToVND(ctx)
-	case "USDC":
+	case domain.PriceSourceUSDC:
 		return s.exchangeRateService.GetUSDCToVND(ctx)
-	case "BUSD":
-		// BUSD is also USD-pegged, use USDT rate
-		return s.exchangeRateService.GetUSDTToVND(ctx)
+	case domain.PriceSourcePeg:
+		return s.exchangeRateService.GetRate(ctx, token.PegCurrency, domain.PricingCurrencyVND)
 	default:
-		return decimal.Zero, fmt.Errorf("unsupported currency: %s", currency)
+		return decimal.Zero, fmt.Errorf("unsupported price source %q of %s", token.PriceSource, token.Symbol)
 	}
 }
 
@@ -647,7 +1590,7 @@ func (s *PaymentService) generatePaymentReference() (string, error) {
 
 // PaymentEvent represents a payment status update event for real-time broadcasting
 type PaymentEvent struct {
-	Type      string    `json:"type"` // payment.pending, payment.confirming, payment.completed, payment.expired, payment.failed
+	Type      string    `json:"type"` // payment.pending, payment.underpaid, payment.confirming, payment.completed, payment.overpaid, payment.expired, payment.failed, payment.reversed, payment.late_paid, payment.canceled, payment.expiry_extended
 	PaymentID string    `json:"payment_id"`
 	Status    string    `json:"status"`
 	TxHash    string    `json:"tx_hash,omitempty"`
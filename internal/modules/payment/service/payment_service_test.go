@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/web3"
+)
+
+func (r *memPaymentRepository) Transition(payment *domain.Payment, transition *domain.PaymentStatusTransition) error {
+	return r.Update(payment)
+}
+
+func (r *memPaymentRepository) ApplyTransfer(payment *domain.Payment, transition *domain.PaymentStatusTransition, transfer *domain.PaymentTransfer) error {
+	if len(r.applyErrs) > 0 {
+		err := r.applyErrs[0]
+		r.applyErrs = r.applyErrs[1:]
+		return err
+	}
+	if err := r.Update(payment); err != nil {
+		return err
+	}
+	return r.transfers.Create(transfer)
+}
+
+func (r *memPaymentRepository) ListByStatus(status domain.PaymentStatus, limit, offset int) ([]*domain.Payment, error) {
+	var payments []*domain.Payment
+	for _, payment := range r.payments {
+		if payment.Status == status {
+			copied := *payment
+			payments = append(payments, &copied)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].CreatedAt.After(payments[j].CreatedAt) })
+
+	if offset >= len(payments) {
+		return nil, nil
+	}
+	payments = payments[offset:]
+	if len(payments) > limit {
+		payments = payments[:limit]
+	}
+	return payments, nil
+}
+
+// memTransferRepository keeps payment transfers in memory
+type memTransferRepository struct {
+	transfers []*domain.PaymentTransfer
+}
+
+func (r *memTransferRepository) Create(transfer *domain.PaymentTransfer) error {
+	stored := *transfer
+	r.transfers = append(r.transfers, &stored)
+	return nil
+}
+
+func (r *memTransferRepository) GetByTxHash(chain domain.Chain, txHash string) (*domain.PaymentTransfer, error) {
+	for _, transfer := range r.transfers {
+		if transfer.Chain == chain && transfer.TxHash == txHash {
+			copied := *transfer
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrTransferNotFound
+}
+
+func (r *memTransferRepository) ListByPayment(paymentID string) ([]*domain.PaymentTransfer, error) {
+	var transfers []*domain.PaymentTransfer
+	for _, transfer := range r.transfers {
+		if transfer.PaymentID == paymentID {
+			copied := *transfer
+			transfers = append(transfers, &copied)
+		}
+	}
+	return transfers, nil
+}
+
+func (r *memTransferRepository) Update(transfer *domain.PaymentTransfer) error {
+	for i, stored := range r.transfers {
+		if stored.ID == transfer.ID {
+			copied := *transfer
+			r.transfers[i] = &copied
+			return nil
+		}
+	}
+	return domain.ErrTransferNotFound
+}
+
+func (r *memTransferRepository) ListUnfinalized(since time.Time, limit int) ([]*domain.PaymentTransfer, error) {
+	return nil, nil
+}
+
+func newPendingPayment() *domain.Payment {
+	payment := newCompletedPayment()
+	payment.Status = domain.PaymentStatusPending
+	payment.FromAddress.Valid = false
+	payment.ExpiresAt = time.Now().Add(time.Hour)
+	return payment
+}
+
+func newConfirmPaymentService(payments *memPaymentRepository, transfers *memTransferRepository) *PaymentService {
+	payments.transfers = transfers
+	return NewPaymentService(payments, transfers, nil, nil, nil, nil, PaymentServiceConfig{}, newTestLogger())
+}
+
+func TestConfirmPayment_RejectsTransferOfAnotherAsset(t *testing.T) {
+	tests := []struct {
+		name     string
+		chain    domain.Chain
+		currency string
+		wantErr  error
+	}{
+		{name: "another chain", chain: domain.ChainBSC, currency: "USDT", wantErr: domain.ErrTransferChainMismatch},
+		{name: "another token", chain: domain.ChainSolana, currency: "USDC", wantErr: domain.ErrTransferCurrencyMismatch},
+		{name: "unknown chain", chain: "", currency: "USDT", wantErr: domain.ErrTransferChainMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments := newMemPaymentRepository(newPendingPayment())
+			transfers := &memTransferRepository{}
+			service := newConfirmPaymentService(payments, transfers)
+
+			_, err := service.ConfirmPayment(context.Background(), port.ConfirmPaymentRequest{
+				PaymentID:    "payment-1",
+				TxHash:       "tx-1",
+				ActualAmount: decimal.NewFromInt(100),
+				Chain:        tt.chain,
+				Currency:     tt.currency,
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, transfers.transfers)
+			stored, _ := payments.GetByID("payment-1")
+			assert.Equal(t, domain.PaymentStatusPending, stored.Status)
+			assert.True(t, stored.AmountReceived.IsZero())
+		})
+	}
+}
+
+func TestConfirmPayment_AcceptsTransferOfThePaymentAsset(t *testing.T) {
+	payments := newMemPaymentRepository(newPendingPayment())
+	transfers := &memTransferRepository{}
+	service := newConfirmPaymentService(payments, transfers)
+
+	payment, err := service.ConfirmPayment(context.Background(), port.ConfirmPaymentRequest{
+		PaymentID:    "payment-1",
+		TxHash:       "tx-1",
+		ActualAmount: decimal.NewFromInt(40),
+		Chain:        domain.ChainSolana,
+		Currency:     "usdt",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusUnderpaid, payment.Status)
+	require.Len(t, transfers.transfers, 1)
+	assert.Equal(t, domain.ChainSolana, transfers.transfers[0].Chain)
+}
+
+func TestConfirmPayment_CountsTransferOnceOnItsChain(t *testing.T) {
+	payment := newPendingPayment()
+	payment.Chain = domain.ChainTRON
+	payments := newMemPaymentRepository(payment)
+	transfers := &memTransferRepository{}
+	service := newConfirmPaymentService(payments, transfers)
+
+	req := port.ConfirmPaymentRequest{
+		PaymentID:    "payment-1",
+		TxHash:       "tx-1",
+		ActualAmount: decimal.NewFromInt(40),
+		Chain:        domain.ChainTRON,
+		Currency:     "USDT",
+	}
+	_, err := service.ConfirmPayment(context.Background(), req)
+	require.NoError(t, err)
+	confirmed, err := service.ConfirmPayment(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Len(t, transfers.transfers, 1)
+	assert.Equal(t, domain.ChainTRON, transfers.transfers[0].Chain)
+	assert.True(t, confirmed.AmountReceived.Equal(decimal.NewFromInt(40)))
+}
+
+func TestConfirmPayment_FailedPaymentUpdateRecordsNoTransfer(t *testing.T) {
+	payments := newMemPaymentRepository(newPendingPayment())
+	transfers := &memTransferRepository{}
+	service := newConfirmPaymentService(payments, transfers)
+	payments.applyErrs = []error{domain.ErrPaymentVersionConflict, domain.ErrPaymentVersionConflict, domain.ErrPaymentVersionConflict}
+
+	req := port.ConfirmPaymentRequest{
+		PaymentID:    "payment-1",
+		TxHash:       "tx-1",
+		ActualAmount: decimal.NewFromInt(40),
+		Chain:        domain.ChainSolana,
+		Currency:     "USDT",
+	}
+	_, err := service.ConfirmPayment(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrPaymentVersionConflict)
+	assert.Empty(t, transfers.transfers)
+
+	// The listener retry applies the transfer instead of skipping it as already recorded
+	payment, err := service.ConfirmPayment(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusUnderpaid, payment.Status)
+	assert.True(t, payment.AmountReceived.Equal(decimal.NewFromInt(40)))
+	assert.Len(t, transfers.transfers, 1)
+}
+
+func TestConfirmPayment_RetriesConcurrentPaymentUpdate(t *testing.T) {
+	payments := newMemPaymentRepository(newPendingPayment())
+	transfers := &memTransferRepository{}
+	service := newConfirmPaymentService(payments, transfers)
+	payments.applyErrs = []error{domain.ErrPaymentVersionConflict}
+
+	payment, err := service.ConfirmPayment(context.Background(), port.ConfirmPaymentRequest{
+		PaymentID:    "payment-1",
+		TxHash:       "tx-1",
+		ActualAmount: decimal.NewFromInt(40),
+		Chain:        domain.ChainSolana,
+		Currency:     "USDT",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, payment.AmountReceived.Equal(decimal.NewFromInt(40)))
+	assert.Len(t, transfers.transfers, 1)
+}
+
+func TestReconcileSurplus_RecordsSurplusOfRecentOverpaidPayments(t *testing.T) {
+	recent := newCompletedPayment()
+	recent.Status = domain.PaymentStatusOverpaid
+	recent.AmountReceived = decimal.NewFromInt(150)
+	recent.TxHash = sql.NullString{String: "tx-1", Valid: true}
+	recent.CreatedAt = time.Now().Add(-time.Hour)
+
+	old := newCompletedPayment()
+	old.ID = "payment-2"
+	old.Status = domain.PaymentStatusOverpaid
+	old.AmountReceived = decimal.NewFromInt(120)
+	old.TxHash = sql.NullString{String: "tx-2", Valid: true}
+	old.CreatedAt = time.Now().Add(-2 * DefaultReorgWatchWindow)
+
+	ledger := &memLedgerService{surplusErrs: []error{errors.New("ledger unavailable")}}
+	payments := newMemPaymentRepository(recent, old)
+	service := NewPaymentService(payments, &memTransferRepository{}, nil, nil, nil, nil, PaymentServiceConfig{
+		LedgerService: ledger,
+	}, newTestLogger())
+
+	reconciled, err := service.ReconcileSurplus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, reconciled)
+
+	reconciled, err = service.ReconcileSurplus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, reconciled)
+	assert.Equal(t, []string{"payment_surplus:50"}, ledger.calls)
+}
+
+func TestExtendPayment_KeepsQuotedAmount(t *testing.T) {
+	quoted := newPendingPayment()
+	quoted.QuoteID = sql.NullString{String: "quote-1", Valid: true}
+	quoted.ExchangeRate = decimal.NewFromInt(25000)
+	quoted.ExpiresAt = time.Now().Add(10 * time.Minute)
+	payments := newMemPaymentRepository(quoted)
+	service := newConfirmPaymentService(payments, &memTransferRepository{})
+
+	// Without a token registry a re-quote fails, the quoted payment must not need one
+	payment, err := service.ExtendPayment(context.Background(), "payment-1", "merchant-1", 45*time.Minute)
+
+	require.NoError(t, err)
+	assert.True(t, payment.AmountCrypto.Equal(decimal.NewFromInt(100)))
+	assert.True(t, payment.ExchangeRate.Equal(decimal.NewFromInt(25000)))
+	assert.True(t, payment.ExpiresAt.After(time.Now().Add(30*time.Minute)))
+	assert.Equal(t, 1, payment.ExpiryExtensions)
+}
+
+func TestExtendPayment_RequotesUnquotedPayment(t *testing.T) {
+	unquoted := newPendingPayment()
+	unquoted.ExpiresAt = time.Now().Add(10 * time.Minute)
+	payments := newMemPaymentRepository(unquoted)
+	service := newConfirmPaymentService(payments, &memTransferRepository{})
+
+	_, err := service.ExtendPayment(context.Background(), "payment-1", "merchant-1", 45*time.Minute)
+
+	assert.ErrorIs(t, err, domain.ErrInvalidChain)
+}
+
+// memPaymentLinkRepository counts the link uses given back
+type memPaymentLinkRepository struct {
+	domain.PaymentLinkRepository
+	released []string
+}
+
+func (r *memPaymentLinkRepository) ReleaseUse(id string) error {
+	r.released = append(r.released, id)
+	return nil
+}
+
+func TestExpirePayment_ReleasesPaymentLinkUse(t *testing.T) {
+	fromLink := newPendingPayment()
+	fromLink.PaymentLinkID = sql.NullString{String: "link-1", Valid: true}
+	links := &memPaymentLinkRepository{}
+	service := NewPaymentService(newMemPaymentRepository(fromLink), &memTransferRepository{}, nil, nil, nil, nil, PaymentServiceConfig{
+		PaymentLinkRepository: links,
+	}, newTestLogger())
+
+	require.NoError(t, service.ExpirePayment(context.Background(), "payment-1"))
+
+	assert.Equal(t, []string{"link-1"}, links.released)
+}
+
+func TestCancelPayment_ReleasesPaymentLinkUse(t *testing.T) {
+	fromLink := newPendingPayment()
+	fromLink.PaymentLinkID = sql.NullString{String: "link-1", Valid: true}
+	links := &memPaymentLinkRepository{}
+	service := NewPaymentService(newMemPaymentRepository(fromLink), &memTransferRepository{}, nil, nil, nil, nil, PaymentServiceConfig{
+		PaymentLinkRepository: links,
+	}, newTestLogger())
+
+	payment, err := service.CancelPayment(context.Background(), "payment-1", "merchant-1", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusCanceled, payment.Status)
+	assert.Equal(t, []string{"link-1"}, links.released)
+}
+
+func TestCreatePayment_ReturnsPaymentAlreadyCreated(t *testing.T) {
+	existing := newPendingPayment()
+	existing.ID = paymentBatchItemPaymentID("batch-1", 0)
+	service := newConfirmPaymentService(newMemPaymentRepository(existing), &memTransferRepository{})
+
+	// A batch task retried after a crash sends the same payment ID again
+	payment, err := service.CreatePayment(context.Background(), port.CreatePaymentRequest{
+		PaymentID:  existing.ID,
+		MerchantID: "merchant-1",
+		AmountVND:  decimal.NewFromInt(2500000),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, payment.ID)
+
+	_, err = service.CreatePayment(context.Background(), port.CreatePaymentRequest{
+		PaymentID:  existing.ID,
+		MerchantID: "merchant-2",
+		AmountVND:  decimal.NewFromInt(2500000),
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentState)
+}
+
+func TestPaymentBatchItemPaymentID(t *testing.T) {
+	id := paymentBatchItemPaymentID("batch-1", 0)
+
+	assert.Equal(t, id, paymentBatchItemPaymentID("batch-1", 0))
+	assert.NotEqual(t, id, paymentBatchItemPaymentID("batch-1", 1))
+	assert.NotEqual(t, id, paymentBatchItemPaymentID("batch-2", 0))
+}
+
+// memOwnershipChallengeRepository accepts each issued nonce once
+type memOwnershipChallengeRepository struct {
+	challenges map[string]*domain.OwnershipChallenge
+}
+
+func (r *memOwnershipChallengeRepository) Create(challenge *domain.OwnershipChallenge) error {
+	if r.challenges == nil {
+		r.challenges = make(map[string]*domain.OwnershipChallenge)
+	}
+	stored := *challenge
+	r.challenges[challenge.Nonce] = &stored
+	return nil
+}
+
+func (r *memOwnershipChallengeRepository) ClaimChallengeNonce(chain, address, nonce string) error {
+	challenge, ok := r.challenges[nonce]
+	if !ok || challenge.UsedAt.Valid || string(challenge.Chain) != chain ||
+		challenge.WalletAddress != strings.ToLower(address) || !time.Now().Before(challenge.ExpiresAt) {
+		return domain.ErrOwnershipChallengeRejected
+	}
+	challenge.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func TestOwnershipChallenge_NonceIsAcceptedOnce(t *testing.T) {
+	const wallet = "0x52908400098527886E0F7030069857D2E4169EE7"
+	service := NewPaymentService(newMemPaymentRepository(), &memTransferRepository{}, nil, nil, nil, nil, PaymentServiceConfig{
+		OwnershipChallengeRepository: &memOwnershipChallengeRepository{},
+	}, newTestLogger())
+
+	challenge, err := service.CreateOwnershipChallenge(context.Background(), port.CreateOwnershipChallengeRequest{
+		Chain:         domain.ChainBSC,
+		WalletAddress: wallet,
+	})
+	require.NoError(t, err)
+	message := web3.GenerateEVMChallengeMessage(wallet, challenge.Nonce, challenge.ExpiresAt)
+
+	require.NoError(t, service.claimOwnershipNonce(domain.ChainBSC, wallet, message))
+	assert.ErrorIs(t, service.claimOwnershipNonce(domain.ChainBSC, wallet, message), domain.ErrInvalidSignature)
+
+	// Nonces chosen by the client were never issued
+	chosen := web3.GenerateEVMChallengeMessage(wallet, "client-nonce", challenge.ExpiresAt)
+	assert.ErrorIs(t, service.claimOwnershipNonce(domain.ChainBSC, wallet, chosen), domain.ErrInvalidSignature)
+}
+
+func TestOwnershipChallenge_RejectsEVMProofsWithoutRepository(t *testing.T) {
+	const wallet = "0x52908400098527886E0F7030069857D2E4169EE7"
+	service := newConfirmPaymentService(newMemPaymentRepository(), &memTransferRepository{})
+	message := web3.GenerateEVMChallengeMessage(wallet, "n-1", time.Now().Add(time.Minute))
+
+	assert.ErrorIs(t, service.claimOwnershipNonce(domain.ChainBSC, wallet, message), domain.ErrOwnershipChallengesNotConfigured)
+
+	_, err := service.CreateOwnershipChallenge(context.Background(), port.CreateOwnershipChallengeRequest{
+		Chain:         domain.ChainBSC,
+		WalletAddress: wallet,
+	})
+	assert.ErrorIs(t, err, domain.ErrOwnershipChallengesNotConfigured)
+}
+
+// memInboundTransferRepository holds inbound transfers by ID
+type memInboundTransferRepository struct {
+	domain.InboundTransferRepository
+	transfers map[string]*domain.InboundTransfer
+}
+
+func (r *memInboundTransferRepository) GetByID(id string) (*domain.InboundTransfer, error) {
+	transfer, ok := r.transfers[id]
+	if !ok {
+		return nil, domain.ErrInboundTransferNotFound
+	}
+	stored := *transfer
+	return &stored, nil
+}
+
+func (r *memInboundTransferRepository) Resolve(transfer *domain.InboundTransfer) error {
+	if !r.transfers[transfer.ID].IsUnmatched() {
+		return domain.ErrInboundTransferNotUnmatched
+	}
+	stored := *transfer
+	r.transfers[transfer.ID] = &stored
+	return nil
+}
+
+func (r *memInboundTransferRepository) MarkRefunded(transfer *domain.InboundTransfer) error {
+	if !r.transfers[transfer.ID].IsRefundPending() {
+		return domain.ErrInboundTransferNotRefundPending
+	}
+	stored := *transfer
+	r.transfers[transfer.ID] = &stored
+	return nil
+}
+
+// stubTransactionVerifier reports the transactions it knows as included
+type stubTransactionVerifier struct {
+	included map[string]bool
+}
+
+func (v *stubTransactionVerifier) VerifyTransaction(ctx context.Context, chain domain.Chain, txHash string) (*domain.TransactionInclusion, error) {
+	return &domain.TransactionInclusion{Included: v.included[txHash]}, nil
+}
+
+func TestCompleteInboundTransferRefund(t *testing.T) {
+	transfers := &memInboundTransferRepository{transfers: map[string]*domain.InboundTransfer{
+		"transfer-1": {
+			ID:          "transfer-1",
+			Chain:       domain.ChainSolana,
+			TxHash:      "deposit-tx",
+			FromAddress: sql.NullString{String: "sender", Valid: true},
+			Amount:      decimal.NewFromInt(100),
+			Currency:    "USDT",
+			Status:      domain.InboundTransferStatusUnmatched,
+		},
+	}}
+	service := NewPaymentService(newMemPaymentRepository(), &memTransferRepository{}, nil, nil, nil, nil, PaymentServiceConfig{
+		InboundTransferRepository: transfers,
+		TransactionVerifier:       &stubTransactionVerifier{included: map[string]bool{"refund-tx": true}},
+	}, newTestLogger())
+	ctx := context.Background()
+
+	// Only a transfer marked for refund can be refunded
+	_, err := service.CompleteInboundTransferRefund(ctx, "transfer-1", "refund-tx", "ops@example.com")
+	assert.ErrorIs(t, err, domain.ErrInboundTransferNotRefundPending)
+
+	_, err = service.MarkInboundTransferForRefund(ctx, "transfer-1", "ops@example.com", "")
+	require.NoError(t, err)
+
+	_, err = service.CompleteInboundTransferRefund(ctx, "transfer-1", "unknown-tx", "ops@example.com")
+	assert.ErrorIs(t, err, domain.ErrInboundTransferRefundNotIncluded)
+
+	transfer, err := service.CompleteInboundTransferRefund(ctx, "transfer-1", "refund-tx", "ops@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, domain.InboundTransferStatusRefunded, transfer.Status)
+	assert.Equal(t, "refund-tx", transfer.RefundTxHash.String)
+
+	_, err = service.CompleteInboundTransferRefund(ctx, "transfer-1", "refund-tx", "ops@example.com")
+	assert.ErrorIs(t, err, domain.ErrInboundTransferNotRefundPending)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// AuditActionPaymentSimulated is the audit log action of a simulation run on a test payment
+const AuditActionPaymentSimulated = "payment_simulated"
+
+// SimulatedTxHashPrefix marks the transaction hashes of simulated transfers
+const SimulatedTxHashPrefix = "sim_"
+
+// SimulatePayment settles a test payment of the merchant as if the payer did what the outcome describes
+// The made-up transfer goes through ConfirmPayment and expiry through ExpirePayment, so the ledger entries,
+// status history and webhooks are the ones a live payment would produce
+func (s *PaymentService) SimulatePayment(ctx context.Context, paymentID, merchantID string, outcome domain.SimulationOutcome) (*domain.Payment, error) {
+	if !outcome.IsValid() {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidSimulationOutcome, outcome)
+	}
+
+	payment, err := s.getMerchantPayment(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !payment.TestMode {
+		return nil, domain.ErrNotTestPayment
+	}
+
+	switch outcome {
+	case domain.SimulationOutcomeExpired:
+		if err := s.ExpirePayment(ctx, payment.ID); err != nil {
+			return nil, err
+		}
+	case domain.SimulationOutcomeLate:
+		// Payments whose window already passed take the transfer as late as they are
+		if !payment.AcceptsLatePayment() {
+			if err := s.ExpirePayment(ctx, payment.ID); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.simulateTransfer(ctx, payment, merchantID, outcome); err != nil {
+			return nil, err
+		}
+	default:
+		if err := s.simulateTransfer(ctx, payment, merchantID, outcome); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":  payment.ID,
+		"merchant_id": merchantID,
+		"outcome":     outcome,
+	}).Info("Test payment simulated")
+
+	s.recordPaymentAction(ctx, payment, AuditActionPaymentSimulated, map[string]interface{}{
+		"outcome":         string(outcome),
+		"previous_status": string(payment.Status),
+	})
+
+	// ConfirmPayment and ExpirePayment may have resolved the payment further, e.g. a late payment accepted by policy
+	return s.paymentRepo.GetByID(payment.ID)
+}
+
+// simulateTransfer confirms a made-up transfer of the outcome's amount against the test payment
+func (s *PaymentService) simulateTransfer(ctx context.Context, payment *domain.Payment, merchantID string, outcome domain.SimulationOutcome) error {
+	amount := outcome.TransferAmount(payment)
+	if !amount.IsPositive() {
+		return fmt.Errorf("%w: nothing is owed on the payment", domain.ErrInvalidSimulationOutcome)
+	}
+
+	_, err := s.ConfirmPayment(ctx, port.ConfirmPaymentRequest{
+		PaymentID:     payment.ID,
+		TxHash:        SimulatedTxHashPrefix + uuid.New().String(),
+		ActualAmount:  amount,
+		Confirmations: 1,
+		Chain:         payment.Chain,
+		Currency:      payment.Currency,
+		Actor:         domain.PaymentActorMerchant,
+		ActorID:       merchantID,
+		Simulated:     true,
+	})
+	return err
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+func (l *memLedgerService) RecordPaymentSurplus(paymentID, merchantID string, surplusCrypto decimal.Decimal, cryptoCurrency, txHash string) error {
+	if len(l.surplusErrs) > 0 {
+		err := l.surplusErrs[0]
+		l.surplusErrs = l.surplusErrs[1:]
+		return err
+	}
+	l.calls = append(l.calls, "payment_surplus:"+surplusCrypto.String())
+	return nil
+}
+
+func (l *memLedgerService) RecordLatePaymentReceived(paymentID, merchantID string, amountCrypto decimal.Decimal, cryptoCurrency string) error {
+	l.calls = append(l.calls, "late_payment_received:"+amountCrypto.String())
+	return nil
+}
+
+func TestSimulatePayment_BooksToTestModeLedger(t *testing.T) {
+	tests := []struct {
+		outcome domain.SimulationOutcome
+		want    []string
+	}{
+		{outcome: domain.SimulationOutcomeOverpaid, want: []string{"payment_surplus:50"}},
+		{outcome: domain.SimulationOutcomeLate, want: []string{"late_payment_received:100"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outcome), func(t *testing.T) {
+			payment := newPendingPayment()
+			payment.TestMode = true
+			payments := newMemPaymentRepository(payment)
+			payments.transfers = &memTransferRepository{}
+
+			live := &memLedgerService{}
+			testMode := &memLedgerService{}
+			service := NewPaymentService(payments, payments.transfers, nil, nil, nil, nil, PaymentServiceConfig{
+				LedgerService:         live,
+				TestModeLedgerService: testMode,
+			}, newTestLogger())
+
+			_, err := service.SimulatePayment(context.Background(), "payment-1", "merchant-1", tt.outcome)
+
+			require.NoError(t, err)
+			assert.Empty(t, live.calls)
+			assert.Equal(t, tt.want, testMode.calls)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// PaymentUpdateAttempts is how many times a transfer is applied to a payment that keeps being
+// updated concurrently (e.g. by the expiry job or another listener) before giving up
+const PaymentUpdateAttempts = 3
+
+// transitionPayment saves the payment moved to the given status and records the transition in its
+// status history. A payment already in that status is saved without a transition.
+func (s *PaymentService) transitionPayment(payment *domain.Payment, to domain.PaymentStatus, actor domain.PaymentActor, actorID, reason string) error {
+	if payment.Status == to {
+		return s.paymentRepo.Update(payment)
+	}
+
+	transition, err := payment.TransitionTo(to, actor, actorID, reason)
+	if err != nil {
+		return err
+	}
+
+	if err := s.paymentRepo.Transition(payment, transition); err != nil {
+		payment.Status = transition.FromStatus
+		return err
+	}
+
+	return nil
+}
+
+// transitionPaymentWithTransfer is transitionPayment that also records the transfer the update applies,
+// the transfer is only recorded if the payment is saved
+func (s *PaymentService) transitionPaymentWithTransfer(payment *domain.Payment, to domain.PaymentStatus, actor domain.PaymentActor, actorID, reason string, transfer *domain.PaymentTransfer) error {
+	var transition *domain.PaymentStatusTransition
+	if payment.Status != to {
+		var err error
+		transition, err = payment.TransitionTo(to, actor, actorID, reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.paymentRepo.ApplyTransfer(payment, transition, transfer); err != nil {
+		if transition != nil {
+			payment.Status = transition.FromStatus
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListPaymentStatusHistory lists the status transitions of a payment, oldest first
+func (s *PaymentService) ListPaymentStatusHistory(ctx context.Context, paymentID string) ([]*domain.PaymentStatusTransition, error) {
+	history, err := s.paymentRepo.ListStatusHistory(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment status history: %w", err)
+	}
+
+	return history, nil
+}
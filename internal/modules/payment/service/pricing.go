@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// paymentPricing is the merchant price of a payment and its VND equivalent
+type paymentPricing struct {
+	currency  string
+	amount    decimal.Decimal
+	rate      decimal.Decimal // VND per unit of the pricing currency
+	amountVND decimal.Decimal
+}
+
+// resolvePricing converts the price of a payment request to VND
+// Payments priced in VND keep using AmountVND, other currencies are converted at the current rate.
+func (s *PaymentService) resolvePricing(ctx context.Context, req port.CreatePaymentRequest) (*paymentPricing, error) {
+	currency := domain.NormalizePricingCurrency(req.PricingCurrency)
+	if !domain.IsSupportedPricingCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedPricingCurrency, currency)
+	}
+
+	if currency == domain.PricingCurrencyVND {
+		amount := req.AmountVND
+		if amount.IsZero() {
+			amount = req.PricingAmount
+		} else if !req.PricingAmount.IsZero() && !req.PricingAmount.Equal(amount) {
+			return nil, fmt.Errorf("%w: pricing amount does not match amount_vnd", domain.ErrInvalidAmount)
+		}
+		if amount.LessThanOrEqual(decimal.Zero) {
+			return nil, domain.ErrInvalidAmount
+		}
+
+		return &paymentPricing{
+			currency:  currency,
+			amount:    amount,
+			rate:      decimal.NewFromInt(1),
+			amountVND: amount,
+		}, nil
+	}
+
+	if !req.AmountVND.IsZero() {
+		return nil, fmt.Errorf("%w: amount_vnd cannot be set for payments priced in %s", domain.ErrInvalidAmount, currency)
+	}
+	amount := req.PricingAmount.Round(2)
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	rate, err := s.getPricingRate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &paymentPricing{
+		currency:  currency,
+		amount:    amount,
+		rate:      rate,
+		amountVND: amount.Mul(rate).Round(0),
+	}, nil
+}
+
+// repricePayment updates the amount in the pricing currency after AmountVND was re-quoted
+func (s *PaymentService) repricePayment(ctx context.Context, payment *domain.Payment) error {
+	currency := payment.GetPricingCurrency()
+
+	rate, err := s.getPricingRate(ctx, currency)
+	if err != nil {
+		return err
+	}
+
+	payment.PricingCurrency = currency
+	payment.PricingRate = rate
+	payment.PricingAmount = payment.AmountVND.DivRound(rate, 2)
+	return nil
+}
+
+// getPricingRate returns VND per unit of the pricing currency
+func (s *PaymentService) getPricingRate(ctx context.Context, currency string) (decimal.Decimal, error) {
+	if currency == domain.PricingCurrencyVND {
+		return decimal.NewFromInt(1), nil
+	}
+
+	rate, err := cachedRate(ctx, currency+"/"+domain.PricingCurrencyVND, func() (decimal.Decimal, error) {
+		return s.exchangeRateService.GetRate(ctx, currency, domain.PricingCurrencyVND)
+	})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get %s/VND exchange rate: %w", currency, err)
+	}
+	if rate.LessThanOrEqual(decimal.Zero) {
+		return decimal.Zero, fmt.Errorf("invalid %s/VND exchange rate: %s", currency, rate)
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// CreateQuote locks the exchange rate for an amount on every supported chain and token
+// The merchant spread is deducted from the market rate and the terms are signed and persisted,
+// so a payment created with the quote ID before valid_until is charged at the locked rate.
+func (s *PaymentService) CreateQuote(ctx context.Context, req port.CreateQuoteRequest) (*domain.Quote, error) {
+	if s.quoteRepo == nil || len(s.quoteSigningKey) == 0 {
+		return nil, domain.ErrQuotesNotConfigured
+	}
+
+	amountVND := req.AmountVND.Round(2)
+	if amountVND.LessThanOrEqual(decimal.Zero) {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	kycStatus, err := s.merchantRepo.GetMerchantKYCStatus(req.MerchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant status: %w", err)
+	}
+	if kycStatus != KYCStatusApproved {
+		return nil, domain.ErrMerchantNotApproved
+	}
+
+	if req.Chain != "" && req.Currency != "" {
+		if _, err := s.lookupToken(req.Chain, req.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens, err := s.quotableTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	spread := s.getQuoteSpread(req.MerchantID)
+
+	// Tokens sharing a price source are quoted at the same rate, it is fetched once
+	ctx = withRateCache(ctx)
+	var options domain.QuoteOptions
+	for _, token := range tokens {
+		if (req.Chain != "" && token.Chain != req.Chain) || (req.Currency != "" && token.Symbol != req.Currency) {
+			continue
+		}
+
+		marketRate, err := s.getExchangeRate(ctx, token.Chain, token.Symbol)
+		if err != nil {
+			// Tokens without a VND rate are left out of the quote
+			s.logger.WithFields(logrus.Fields{
+				"chain":    token.Chain,
+				"currency": token.Symbol,
+				"error":    err.Error(),
+			}).Debug("No exchange rate for token, not quoted")
+			continue
+		}
+
+		exchangeRate := domain.ApplySpread(marketRate, spread)
+		amountCrypto := amountVND.Div(exchangeRate).Round(6)
+		if token.CheckAmount(amountCrypto) != nil {
+			continue
+		}
+
+		options = append(options, domain.QuoteOption{
+			Chain:        token.Chain,
+			Currency:     token.Symbol,
+			MarketRate:   marketRate,
+			ExchangeRate: exchangeRate,
+			AmountCrypto: amountCrypto,
+		})
+	}
+
+	if len(options) == 0 {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", domain.ErrInvalidChain)
+	}
+
+	quote := &domain.Quote{
+		ID:               uuid.New().String(),
+		MerchantID:       req.MerchantID,
+		AmountVND:        amountVND,
+		SpreadPercentage: spread,
+		RateSource:       s.exchangeRateService.GetRateSource(ctx),
+		Options:          options,
+		ValidUntil:       time.Now().Add(s.quoteValidity).Truncate(time.Second),
+		CreatedAt:        time.Now(),
+	}
+	quote.Signature = s.signQuote(quote)
+
+	if err := s.quoteRepo.Create(quote); err != nil {
+		return nil, fmt.Errorf("failed to create quote: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"quote_id":    quote.ID,
+		"merchant_id": quote.MerchantID,
+		"amount_vnd":  quote.AmountVND,
+		"spread":      quote.SpreadPercentage,
+		"rate_source": quote.RateSource,
+		"options":     len(quote.Options),
+		"valid_until": quote.ValidUntil,
+	}).Info("Quote created")
+
+	return quote, nil
+}
+
+// getLockedQuote loads a quote the merchant can still create a payment from
+// The payment must be for the quoted amount on a quoted chain and token.
+func (s *PaymentService) getLockedQuote(quoteID, merchantID string, amountVND decimal.Decimal, chain domain.Chain, currency string) (*domain.Quote, *domain.QuoteOption, error) {
+	if s.quoteRepo == nil || len(s.quoteSigningKey) == 0 {
+		return nil, nil, domain.ErrQuotesNotConfigured
+	}
+
+	quote, err := s.quoteRepo.GetByID(quoteID)
+	if err != nil {
+		if errors.Is(err, domain.ErrQuoteNotFound) {
+			return nil, nil, domain.ErrQuoteNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get quote: %w", err)
+	}
+
+	// Quotes of other merchants are reported as not found
+	if quote.MerchantID != merchantID {
+		return nil, nil, domain.ErrQuoteNotFound
+	}
+	if !hmac.Equal([]byte(quote.Signature), []byte(s.signQuote(quote))) {
+		s.logger.WithField("quote_id", quote.ID).Error("Quote terms do not match their signature")
+		return nil, nil, domain.ErrInvalidQuoteSignature
+	}
+	if quote.IsUsed() {
+		return nil, nil, domain.ErrQuoteAlreadyUsed
+	}
+	if quote.IsExpired() {
+		return nil, nil, domain.ErrQuoteExpired
+	}
+	if !quote.AmountVND.Equal(amountVND.Round(2)) {
+		return nil, nil, fmt.Errorf("%w: quoted amount is %s VND", domain.ErrQuoteMismatch, quote.AmountVND)
+	}
+
+	option := quote.OptionFor(chain, currency)
+	if option == nil {
+		return nil, nil, fmt.Errorf("%w: %s on %s is not quoted", domain.ErrQuoteMismatch, currency, chain)
+	}
+
+	return quote, option, nil
+}
+
+// signQuote returns the hex HMAC-SHA256 of the quote terms
+func (s *PaymentService) signQuote(quote *domain.Quote) string {
+	mac := hmac.New(sha256.New, s.quoteSigningKey)
+	mac.Write([]byte(quote.SigningPayload()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// getQuoteSpread loads the merchant's quote spread, falling back to no spread on error
+func (s *PaymentService) getQuoteSpread(merchantID string) decimal.Decimal {
+	spread, err := s.merchantRepo.GetMerchantQuoteSpread(merchantID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"merchant_id": merchantID,
+			"error":       err.Error(),
+		}).Warn("Failed to load merchant quote spread, quoting the market rate")
+		return decimal.Zero
+	}
+
+	if spread.IsNegative() || spread.GreaterThan(domain.MaxQuoteSpreadPercentage) {
+		return decimal.Zero
+	}
+	return spread
+}
+
+// quotableTokens lists every enabled token of the registry, ordered by chain and symbol
+func (s *PaymentService) quotableTokens() ([]domain.Token, error) {
+	if s.tokenRegistry == nil {
+		return nil, fmt.Errorf("%w: token registry not configured", domain.ErrInvalidChain)
+	}
+
+	return s.tokenRegistry.EnabledTokens("")
+}
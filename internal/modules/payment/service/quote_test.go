@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+func (r *memPaymentRepository) Create(payment *domain.Payment) error {
+	stored := *payment
+	r.payments[payment.ID] = &stored
+	return nil
+}
+
+func (r *stubMerchantRepository) GetMerchantKYCStatus(merchantID string) (string, error) {
+	return KYCStatusApproved, nil
+}
+
+func (r *stubMerchantRepository) IsSandboxMerchant(merchantID string) (bool, error) {
+	return false, nil
+}
+
+func (r *stubMerchantRepository) GetMerchantQuoteSpread(merchantID string) (decimal.Decimal, error) {
+	return r.quoteSpread, nil
+}
+
+func (p *stubExchangeRateProvider) GetRateSource(ctx context.Context) string {
+	return "coingecko"
+}
+
+// memQuoteRepository keeps quotes in memory, stored the way the database returns them
+type memQuoteRepository struct {
+	quotes map[string]*domain.Quote
+	// beforeMarkUsed runs before MarkUsed, e.g. to let a concurrent payment take the quote
+	beforeMarkUsed func(id string)
+}
+
+func (r *memQuoteRepository) Create(quote *domain.Quote) error {
+	stored := *quote
+	// Amounts come back with the scale of their column and the options as JSON
+	stored.AmountVND = decimal.RequireFromString(quote.AmountVND.StringFixed(2))
+	value, err := quote.Options.Value()
+	if err != nil {
+		return err
+	}
+	stored.Options = nil
+	if err := stored.Options.Scan(value); err != nil {
+		return err
+	}
+	r.quotes[quote.ID] = &stored
+	return nil
+}
+
+func (r *memQuoteRepository) GetByID(id string) (*domain.Quote, error) {
+	quote, ok := r.quotes[id]
+	if !ok {
+		return nil, domain.ErrQuoteNotFound
+	}
+	copied := *quote
+	copied.Options = append(domain.QuoteOptions(nil), quote.Options...)
+	return &copied, nil
+}
+
+func (r *memQuoteRepository) MarkUsed(id, paymentID string) error {
+	if r.beforeMarkUsed != nil {
+		r.beforeMarkUsed(id)
+	}
+	quote, ok := r.quotes[id]
+	if !ok {
+		return domain.ErrQuoteNotFound
+	}
+	if quote.IsUsed() {
+		return domain.ErrQuoteAlreadyUsed
+	}
+	quote.PaymentID = sql.NullString{String: paymentID, Valid: true}
+	quote.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+type quoteFixture struct {
+	service  *PaymentService
+	payments *memPaymentRepository
+	quotes   *memQuoteRepository
+}
+
+func newQuoteFixture(validity time.Duration) *quoteFixture {
+	f := &quoteFixture{
+		payments: newMemPaymentRepository(),
+		quotes:   &memQuoteRepository{quotes: make(map[string]*domain.Quote)},
+	}
+	tokens := &memTokenRepository{tokens: []*domain.Token{
+		{ID: "token-1", Chain: domain.ChainSolana, Symbol: "USDT", PegCurrency: "USD", PriceSource: domain.PriceSourceUSDT, Enabled: true},
+	}}
+	f.service = NewPaymentService(f.payments, &memTransferRepository{},
+		&stubMerchantRepository{quoteSpread: decimal.NewFromFloat(0.005)},
+		&stubExchangeRateProvider{rate: decimal.NewFromInt(25000)}, nil, nil, PaymentServiceConfig{
+			TokenRegistry:   NewTokenRegistry(tokens, 0, newTestLogger()),
+			QuoteRepository: f.quotes,
+			QuoteSigningKey: "quote-signing-key",
+			QuoteValidity:   validity,
+		}, newTestLogger())
+	return f
+}
+
+func (f *quoteFixture) createQuote(t *testing.T) *domain.Quote {
+	t.Helper()
+	quote, err := f.service.CreateQuote(context.Background(), port.CreateQuoteRequest{
+		MerchantID: "merchant-1",
+		AmountVND:  decimal.NewFromInt(2500000),
+	})
+	require.NoError(t, err)
+	return quote
+}
+
+func (f *quoteFixture) payWithQuote(quoteID string) (*domain.Payment, error) {
+	return f.service.CreatePayment(context.Background(), port.CreatePaymentRequest{
+		MerchantID: "merchant-1",
+		AmountVND:  decimal.NewFromInt(2500000),
+		Chain:      domain.ChainSolana,
+		Currency:   "USDT",
+		QuoteID:    quoteID,
+	})
+}
+
+func TestCreatePayment_ChargesTheLockedQuoteRate(t *testing.T) {
+	f := newQuoteFixture(0)
+	quote := f.createQuote(t)
+
+	option := quote.OptionFor(domain.ChainSolana, "USDT")
+	require.NotNil(t, option)
+	assert.Equal(t, "24875", option.ExchangeRate.String())
+	assert.Equal(t, "100.502513", option.AmountCrypto.String())
+
+	payment, err := f.payWithQuote(quote.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, quote.ID, payment.QuoteID.String)
+	assert.Equal(t, "24875", payment.ExchangeRate.String())
+	assert.Equal(t, "100.502513", payment.AmountCrypto.String())
+	assert.Equal(t, payment.ID, f.quotes.quotes[quote.ID].PaymentID.String)
+
+	// A quote backs a single payment
+	_, err = f.payWithQuote(quote.ID)
+	assert.ErrorIs(t, err, domain.ErrQuoteAlreadyUsed)
+}
+
+func TestCreatePayment_RejectsQuoteWhoseTermsChanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(quote *domain.Quote)
+	}{
+		{name: "exchange rate", tamper: func(quote *domain.Quote) { quote.Options[0].ExchangeRate = decimal.NewFromInt(25000) }},
+		{name: "crypto amount", tamper: func(quote *domain.Quote) { quote.Options[0].AmountCrypto = decimal.NewFromInt(100) }},
+		{name: "valid until", tamper: func(quote *domain.Quote) { quote.ValidUntil = quote.ValidUntil.Add(time.Hour) }},
+		{name: "signature", tamper: func(quote *domain.Quote) { quote.Signature = strings.Repeat("0", 64) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newQuoteFixture(0)
+			quote := f.createQuote(t)
+			tt.tamper(f.quotes.quotes[quote.ID])
+
+			_, err := f.payWithQuote(quote.ID)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidQuoteSignature)
+			assert.Empty(t, f.payments.payments)
+		})
+	}
+}
+
+func TestCreatePayment_RejectsExpiredQuote(t *testing.T) {
+	// Valid until is truncated to the second, a quote valid for a nanosecond is already expired
+	f := newQuoteFixture(time.Nanosecond)
+	quote := f.createQuote(t)
+
+	_, err := f.payWithQuote(quote.ID)
+
+	assert.ErrorIs(t, err, domain.ErrQuoteExpired)
+	assert.False(t, f.quotes.quotes[quote.ID].IsUsed())
+}
+
+func TestCreatePayment_QuoteTakenConcurrentlyFailsPayment(t *testing.T) {
+	f := newQuoteFixture(0)
+	quote := f.createQuote(t)
+
+	// Another payment marks the quote used after this one checked it
+	f.quotes.beforeMarkUsed = func(id string) {
+		f.quotes.beforeMarkUsed = nil
+		require.NoError(t, f.quotes.MarkUsed(id, "payment-other"))
+	}
+
+	_, err := f.payWithQuote(quote.ID)
+
+	assert.ErrorIs(t, err, domain.ErrQuoteAlreadyUsed)
+	assert.Equal(t, "payment-other", f.quotes.quotes[quote.ID].PaymentID.String)
+	require.Len(t, f.payments.payments, 1)
+	for _, payment := range f.payments.payments {
+		assert.Equal(t, domain.PaymentStatusFailed, payment.Status)
+	}
+}
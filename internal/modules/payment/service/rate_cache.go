@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+type rateCacheKey struct{}
+
+// rateCache keeps the exchange rates fetched while creating a batch of payments, so every rate is
+// fetched once per batch instead of once per payment
+type rateCache struct {
+	mu    sync.Mutex
+	rates map[string]decimal.Decimal
+}
+
+// withRateCache returns a context whose payments share the exchange rates they fetch
+func withRateCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateCacheKey{}, &rateCache{rates: make(map[string]decimal.Decimal)})
+}
+
+// cachedRate returns the rate stored under key in the context's cache, fetching it on first use
+// Errors are not cached, the next payment fetches the rate again
+func cachedRate(ctx context.Context, key string, fetch func() (decimal.Decimal, error)) (decimal.Decimal, error) {
+	cache, ok := ctx.Value(rateCacheKey{}).(*rateCache)
+	if !ok {
+		return fetch()
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if rate, ok := cache.rates[key]; ok {
+		return rate, nil
+	}
+
+	rate, err := fetch()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	cache.rates[key] = rate
+	return rate, nil
+}
@@ -0,0 +1,683 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+const (
+	// RefundBatchSize is the maximum number of refunds handled per processing run
+	RefundBatchSize = 50
+	// RefundSendingTimeout is how long a refund may stay in sending before it is reconciled
+	RefundSendingTimeout = 10 * time.Minute
+	// SolanaRefundExpiry is how long a Solana refund transaction can be missing before it is signed again
+	// Its blockhash expires after about 150 slots, the transaction can no longer land past that
+	SolanaRefundExpiry = 5 * time.Minute
+	// RefundDroppedTimeout is how long a refund transaction of another chain can be missing before
+	// the refund is marked dropped for an operator
+	RefundDroppedTimeout = time.Hour
+)
+
+// RefundService handles on-chain refunds of completed payments
+type RefundService struct {
+	paymentRepo      domain.PaymentRepository
+	transferRepo     domain.PaymentTransferRepository
+	refundRepo       domain.RefundRepository
+	ledgerService    domain.LedgerService
+	refundSender     domain.RefundSender     // Sends refunds on-chain (only configured on the worker)
+	webhookPublisher domain.WebhookPublisher // For refund.* merchant webhooks
+	logger           *logrus.Logger
+}
+
+// RefundServiceConfig contains optional dependencies for RefundService
+type RefundServiceConfig struct {
+	RefundSender     domain.RefundSender     // Optional: required to send and track refunds
+	WebhookPublisher domain.WebhookPublisher // Optional: for refund.* webhooks
+}
+
+// NewRefundService creates a new refund service
+func NewRefundService(
+	paymentRepo domain.PaymentRepository,
+	transferRepo domain.PaymentTransferRepository,
+	refundRepo domain.RefundRepository,
+	ledgerService domain.LedgerService,
+	config RefundServiceConfig,
+	logger *logrus.Logger,
+) *RefundService {
+	return &RefundService{
+		paymentRepo:      paymentRepo,
+		transferRepo:     transferRepo,
+		refundRepo:       refundRepo,
+		ledgerService:    ledgerService,
+		refundSender:     config.RefundSender,
+		webhookPublisher: config.WebhookPublisher,
+		logger:           logger,
+	}
+}
+
+// CreateRefund creates a full or partial refund of a completed payment
+// The refund amount is reserved from the merchant's available balance immediately;
+// the on-chain transfer is sent asynchronously by ProcessPendingRefunds
+func (s *RefundService) CreateRefund(ctx context.Context, req port.CreateRefundRequest) (*domain.Refund, error) {
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":  req.PaymentID,
+		"merchant_id": req.MerchantID,
+		"amount":      req.Amount,
+	}).Info("Creating refund")
+
+	payment, err := s.paymentRepo.GetByID(req.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// Do not reveal payments of other merchants
+	if payment.MerchantID != req.MerchantID {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	if !payment.IsCompleted() {
+		return nil, domain.ErrPaymentNotRefundable
+	}
+	// Refunds are sent on-chain by the worker, test payments never received anything
+	if payment.TestMode {
+		return nil, domain.ErrTestPaymentNotRefundable
+	}
+	if !payment.FromAddress.Valid || payment.FromAddress.String == "" {
+		return nil, domain.ErrRefundAddressUnknown
+	}
+
+	// Only what the payer actually sent can be returned, which differs from the requested
+	// amount when a shortfall or excess was accepted within the merchant's tolerance
+	received, err := s.receivedAmount(payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	refunded, err := s.refundRepo.GetTotalRefundedByPayment(payment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunded amount: %w", err)
+	}
+	refundable := received.Sub(refunded)
+
+	// Zero amount means refund everything that is left
+	amount := req.Amount
+	if amount.IsZero() {
+		amount = refundable
+	}
+	if amount.LessThanOrEqual(decimal.Zero) || amount.GreaterThan(refundable) {
+		return nil, domain.ErrRefundAmountExceeded
+	}
+
+	// Merchant balance is kept in VND, convert at the payment's original rate.
+	// The merchant was never credited more than the payment amount
+	amountVND := payment.AmountVND.Mul(amount).Div(payment.AmountCrypto).Round(0)
+	if amount.GreaterThanOrEqual(payment.AmountCrypto) {
+		amountVND = payment.AmountVND
+	}
+
+	now := time.Now()
+	refund := &domain.Refund{
+		ID:           uuid.New().String(),
+		PaymentID:    payment.ID,
+		MerchantID:   payment.MerchantID,
+		AmountCrypto: amount,
+		AmountVND:    amountVND,
+		Currency:     payment.Currency,
+		Chain:        payment.Chain,
+		ToAddress:    payment.FromAddress.String,
+		Source:       domain.RefundSourceMerchant,
+		Status:       domain.RefundStatusPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if req.Reason != "" {
+		refund.Reason = sql.NullString{String: req.Reason, Valid: true}
+	}
+
+	// Reserve the refund amount from the merchant's available balance
+	if err := s.reserveRefund(payment, refund); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"refund_id":  refund.ID,
+			"error":      err.Error(),
+		}).Warn("Failed to reserve merchant balance for refund")
+		return nil, fmt.Errorf("%w: %v", domain.ErrInsufficientBalance, err)
+	}
+
+	// The refundable amount read above is checked again under a lock of the payment,
+	// concurrent refunds may have been created since
+	if err := s.refundRepo.CreateWithinLimit(refund, received); err != nil {
+		// Release the reservation so the merchant balance is not stuck
+		if releaseErr := s.releaseRefund(payment, refund, "refund record could not be created"); releaseErr != nil {
+			s.logger.WithFields(logrus.Fields{
+				"refund_id": refund.ID,
+				"error":     releaseErr.Error(),
+			}).Error("Failed to release reserved balance for refund")
+		}
+		if errors.Is(err, domain.ErrRefundAmountExceeded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"refund_id":     refund.ID,
+		"payment_id":    payment.ID,
+		"amount_crypto": refund.AmountCrypto.String(),
+		"amount_vnd":    refund.AmountVND.String(),
+	}).Info("Refund created")
+
+	s.publishRefundWebhook(ctx, domain.RefundEventCreated, refund)
+
+	return refund, nil
+}
+
+// CreateLatePaymentRefund creates a refund of everything a late-paid payment received
+// Late payments were never credited to the merchant, so no merchant balance is reserved;
+// the held amount is released from the ledger once the refund transfer is finalized
+func (s *RefundService) CreateLatePaymentRefund(ctx context.Context, payment *domain.Payment, reason string) (*domain.Refund, error) {
+	if !payment.IsLatePaid() {
+		return nil, domain.ErrPaymentNotRefundable
+	}
+	if payment.TestMode {
+		return nil, domain.ErrTestPaymentNotRefundable
+	}
+	if !payment.FromAddress.Valid || payment.FromAddress.String == "" {
+		return nil, domain.ErrRefundAddressUnknown
+	}
+
+	refunded, err := s.refundRepo.GetTotalRefundedByPayment(payment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refunded amount: %w", err)
+	}
+	amount := payment.AmountReceived.Sub(refunded)
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, domain.ErrRefundAmountExceeded
+	}
+
+	now := time.Now()
+	refund := &domain.Refund{
+		ID:           uuid.New().String(),
+		PaymentID:    payment.ID,
+		MerchantID:   payment.MerchantID,
+		AmountCrypto: amount,
+		AmountVND:    amount.Mul(payment.ExchangeRate).Round(0),
+		Currency:     payment.Currency,
+		Chain:        payment.Chain,
+		ToAddress:    payment.FromAddress.String,
+		Source:       domain.RefundSourceLatePayment,
+		Status:       domain.RefundStatusPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if reason != "" {
+		refund.Reason = sql.NullString{String: reason, Valid: true}
+	}
+
+	if err := s.refundRepo.CreateWithinLimit(refund, payment.AmountReceived); err != nil {
+		if errors.Is(err, domain.ErrRefundAmountExceeded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"refund_id":     refund.ID,
+		"payment_id":    payment.ID,
+		"amount_crypto": refund.AmountCrypto.String(),
+	}).Info("Late payment refund created")
+
+	s.publishRefundWebhook(ctx, domain.RefundEventCreated, refund)
+
+	return refund, nil
+}
+
+// receivedAmount returns the sum of the transfers recorded for a payment
+func (s *RefundService) receivedAmount(paymentID string) (decimal.Decimal, error) {
+	transfers, err := s.transferRepo.ListByPayment(paymentID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to list payment transfers: %w", err)
+	}
+
+	received := decimal.Zero
+	for _, transfer := range transfers {
+		received = received.Add(transfer.Amount)
+	}
+	return received, nil
+}
+
+// GetRefund retrieves a refund by ID
+func (s *RefundService) GetRefund(ctx context.Context, refundID string) (*domain.Refund, error) {
+	refund, err := s.refundRepo.GetByID(refundID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	return refund, nil
+}
+
+// ListRefunds lists all refunds of a payment owned by the merchant
+func (s *RefundService) ListRefunds(ctx context.Context, paymentID, merchantID string) ([]*domain.Refund, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if payment.MerchantID != merchantID {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	refunds, err := s.refundRepo.ListByPayment(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+	return refunds, nil
+}
+
+// ProcessPendingRefunds sends pending refunds on-chain
+// Each refund is claimed before it is signed and its transaction hash is saved before the broadcast,
+// so a refund is never sent twice. Returns the number of refunds submitted
+func (s *RefundService) ProcessPendingRefunds(ctx context.Context) (int, error) {
+	if s.refundSender == nil {
+		return 0, domain.ErrRefundSenderNotConfigured
+	}
+
+	refunds, err := s.refundRepo.ListByStatus(domain.RefundStatusPending, RefundBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending refunds: %w", err)
+	}
+
+	submitted := 0
+	for _, refund := range refunds {
+		claimed, err := s.refundRepo.ClaimForSending(refund.ID)
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"refund_id": refund.ID,
+				"error":     err.Error(),
+			}).Error("Failed to claim refund for sending")
+			continue
+		}
+		if !claimed {
+			// Another worker run is sending it
+			continue
+		}
+		refund.Status = domain.RefundStatusSending
+
+		if s.sendRefund(ctx, refund) {
+			submitted++
+		}
+	}
+
+	return submitted, nil
+}
+
+// sendRefund signs and broadcasts a claimed refund, returns true if it was submitted
+func (s *RefundService) sendRefund(ctx context.Context, refund *domain.Refund) bool {
+	signed, err := s.refundSender.SignRefund(ctx, refund.Chain, refund.Currency, refund.ToAddress, refund.AmountCrypto)
+	if err != nil {
+		// Nothing was broadcast, the reserved balance can be released
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"chain":     refund.Chain,
+			"error":     err.Error(),
+		}).Error("Failed to sign refund")
+		s.failRefund(ctx, refund, err.Error())
+		return false
+	}
+
+	refund.TxHash = sql.NullString{String: signed.TxHash, Valid: true}
+	if err := s.refundRepo.Update(refund); err != nil {
+		// Not broadcast without a saved hash, ReconcileSendingRefunds returns the refund to pending
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"tx_hash":   signed.TxHash,
+			"error":     err.Error(),
+		}).Error("Failed to save refund transaction hash, refund not broadcast")
+		return false
+	}
+
+	if err := s.refundSender.BroadcastRefund(ctx, signed); err != nil {
+		// The node may have accepted the transaction anyway, ReconcileSendingRefunds checks the hash
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"tx_hash":   signed.TxHash,
+			"error":     err.Error(),
+		}).Warn("Refund broadcast failed, transaction will be checked on-chain")
+		return false
+	}
+
+	s.markRefundSubmitted(ctx, refund)
+	return true
+}
+
+// markRefundSubmitted records that the refund transaction reached the chain
+func (s *RefundService) markRefundSubmitted(ctx context.Context, refund *domain.Refund) {
+	refund.Status = domain.RefundStatusSubmitted
+	refund.SubmittedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if err := s.refundRepo.Update(refund); err != nil {
+		// The refund stays in sending with its hash, ReconcileSendingRefunds picks it up
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"tx_hash":   refund.TxHash.String,
+			"error":     err.Error(),
+		}).Error("Refund sent on-chain but status update failed")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"refund_id": refund.ID,
+		"tx_hash":   refund.TxHash.String,
+	}).Info("Refund submitted on-chain")
+
+	s.publishRefundWebhook(ctx, domain.RefundEventSubmitted, refund)
+}
+
+// ReconcileSendingRefunds resolves refunds left in sending by an interrupted or failed broadcast
+// Refunds without a saved hash were never broadcast and go back to pending; the others follow
+// their transaction on-chain. Refunds are only sent again once their transaction can no longer land.
+// Returns the number of refunds that left the sending status
+func (s *RefundService) ReconcileSendingRefunds(ctx context.Context) (int, error) {
+	if s.refundSender == nil {
+		return 0, domain.ErrRefundSenderNotConfigured
+	}
+
+	refunds, err := s.refundRepo.ListByStatus(domain.RefundStatusSending, RefundBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sending refunds: %w", err)
+	}
+
+	reconciled := 0
+	for _, refund := range refunds {
+		// Leave refunds alone while the worker that claimed them may still be sending
+		if time.Since(refund.UpdatedAt) < RefundSendingTimeout {
+			continue
+		}
+
+		if !refund.TxHash.Valid || refund.TxHash.String == "" {
+			refund.Status = domain.RefundStatusPending
+			if err := s.refundRepo.Update(refund); err != nil {
+				s.logger.WithFields(logrus.Fields{
+					"refund_id": refund.ID,
+					"error":     err.Error(),
+				}).Error("Failed to return unsent refund to pending")
+				continue
+			}
+			reconciled++
+			continue
+		}
+
+		status, err := s.refundSender.GetRefundTxStatus(ctx, refund.Chain, refund.TxHash.String)
+		if errors.Is(err, domain.ErrRefundTxNotFound) {
+			if s.handleMissingRefundTx(refund, refund.UpdatedAt) {
+				reconciled++
+			}
+			continue
+		}
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"refund_id": refund.ID,
+				"tx_hash":   refund.TxHash.String,
+				"error":     err.Error(),
+			}).Warn("Failed to get refund transaction status")
+			continue
+		}
+
+		switch status {
+		case domain.RefundStatusCompleted:
+			s.completeRefund(ctx, refund)
+		case domain.RefundStatusFailed:
+			s.failRefund(ctx, refund, "refund transaction failed on-chain")
+		default:
+			s.markRefundSubmitted(ctx, refund)
+		}
+		reconciled++
+	}
+
+	return reconciled, nil
+}
+
+// CheckSubmittedRefunds tracks submitted refunds until they are finalized or fail
+// Returns the number of refunds that reached a final status
+func (s *RefundService) CheckSubmittedRefunds(ctx context.Context) (int, error) {
+	if s.refundSender == nil {
+		return 0, domain.ErrRefundSenderNotConfigured
+	}
+
+	refunds, err := s.refundRepo.ListByStatus(domain.RefundStatusSubmitted, RefundBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list submitted refunds: %w", err)
+	}
+
+	finalized := 0
+	for _, refund := range refunds {
+		status, err := s.refundSender.GetRefundTxStatus(ctx, refund.Chain, refund.TxHash.String)
+		if errors.Is(err, domain.ErrRefundTxNotFound) {
+			// Nodes can take a moment to index a transaction they just accepted
+			sentAt := refund.UpdatedAt
+			if refund.SubmittedAt.Valid {
+				sentAt = refund.SubmittedAt.Time
+			}
+			s.handleMissingRefundTx(refund, sentAt)
+			continue
+		}
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"refund_id": refund.ID,
+				"tx_hash":   refund.TxHash.String,
+				"error":     err.Error(),
+			}).Warn("Failed to get refund transaction status")
+			continue
+		}
+
+		switch status {
+		case domain.RefundStatusCompleted:
+			s.completeRefund(ctx, refund)
+			finalized++
+		case domain.RefundStatusFailed:
+			s.failRefund(ctx, refund, "refund transaction failed on-chain")
+			finalized++
+		}
+	}
+
+	return finalized, nil
+}
+
+// handleMissingRefundTx resolves a refund whose transaction the chain has not known since sentAt
+// A Solana transaction whose blockhash expired can no longer land, the refund goes back to pending
+// to be signed again. Other chains may still include the transaction, so the refund is marked dropped
+// and its balance stays reserved until an operator checks it. Returns true if the refund status changed
+func (s *RefundService) handleMissingRefundTx(refund *domain.Refund, sentAt time.Time) bool {
+	fields := logrus.Fields{
+		"refund_id": refund.ID,
+		"chain":     refund.Chain,
+		"tx_hash":   refund.TxHash.String,
+	}
+
+	if refund.Chain == domain.ChainSolana {
+		if time.Since(sentAt) < SolanaRefundExpiry {
+			return false
+		}
+		refund.Status = domain.RefundStatusPending
+		refund.TxHash = sql.NullString{}
+		refund.SubmittedAt = sql.NullTime{}
+		if err := s.refundRepo.Update(refund); err != nil {
+			fields["error"] = err.Error()
+			s.logger.WithFields(fields).Error("Failed to return expired refund to pending")
+			return false
+		}
+		s.logger.WithFields(fields).Warn("Refund transaction expired before landing, refund will be signed again")
+		return true
+	}
+
+	if time.Since(sentAt) < RefundDroppedTimeout {
+		return false
+	}
+	refund.Status = domain.RefundStatusDropped
+	refund.FailureReason = sql.NullString{String: "refund transaction not found on-chain", Valid: true}
+	if err := s.refundRepo.Update(refund); err != nil {
+		fields["error"] = err.Error()
+		s.logger.WithFields(fields).Error("Failed to mark refund as dropped")
+		return false
+	}
+	s.logger.WithFields(fields).Error("CRITICAL: Refund transaction not found on-chain, needs manual reconciliation")
+	return true
+}
+
+// completeRefund finalizes the ledger entries of a refund whose transfer is finalized
+// The ledger posts the completion of a refund once, so a refund whose status update failed
+// is completed again by the next run without being booked twice
+func (s *RefundService) completeRefund(ctx context.Context, refund *domain.Refund) {
+	var err error
+	if refund.IsLatePayment() {
+		err = s.ledgerService.RecordLatePaymentReleased(refund.PaymentID, refund.MerchantID, refund.AmountCrypto, refund.Currency, string(domain.LatePaymentRefunded))
+	} else {
+		err = s.ledgerService.RecordRefundCompleted(refund.ID, refund.MerchantID, refund.AmountVND, refund.AmountCrypto, refund.Currency)
+	}
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"error":     err.Error(),
+		}).Error("Failed to record refund completion in ledger")
+		return
+	}
+
+	refund.Status = domain.RefundStatusCompleted
+	refund.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if err := s.refundRepo.Update(refund); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"error":     err.Error(),
+		}).Error("Failed to mark refund as completed")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"refund_id": refund.ID,
+		"tx_hash":   refund.TxHash.String,
+	}).Info("Refund completed")
+
+	if refund.IsLatePayment() {
+		s.setLateResolution(refund.PaymentID, domain.LatePaymentRefunded)
+	}
+
+	s.publishRefundWebhook(ctx, domain.RefundEventCompleted, refund)
+}
+
+// reserveRefund reserves the refund amount from the merchant's available balance
+// Refunds of split payments are taken back from every recipient in proportion to its share
+func (s *RefundService) reserveRefund(payment *domain.Payment, refund *domain.Refund) error {
+	if payment.HasSplits() {
+		sellers, commission := payment.SplitRefundShares(refund.AmountVND).Shares()
+		return s.ledgerService.RecordSplitRefundRequested(refund.ID, refund.MerchantID, sellers, commission)
+	}
+	return s.ledgerService.RecordRefundRequested(refund.ID, refund.MerchantID, refund.AmountVND)
+}
+
+// releaseRefund gives the reserved refund amount back to the merchant, or to the recipients of a split payment
+func (s *RefundService) releaseRefund(payment *domain.Payment, refund *domain.Refund, reason string) error {
+	if payment.HasSplits() {
+		sellers, commission := payment.SplitRefundShares(refund.AmountVND).Shares()
+		return s.ledgerService.RecordSplitRefundFailed(refund.ID, refund.MerchantID, sellers, commission, reason)
+	}
+	return s.ledgerService.RecordRefundFailed(refund.ID, refund.MerchantID, refund.AmountVND, reason)
+}
+
+// failRefund marks a refund as failed and releases the reserved merchant balance
+// Failed late payment refunds reserved nothing; the payment goes back to manual review.
+// Like completions, the release is posted once if the status update has to be retried.
+func (s *RefundService) failRefund(ctx context.Context, refund *domain.Refund, reason string) {
+	if !refund.IsLatePayment() {
+		payment, err := s.paymentRepo.GetByID(refund.PaymentID)
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"refund_id":  refund.ID,
+				"payment_id": refund.PaymentID,
+				"error":      err.Error(),
+			}).Error("Failed to get payment of failed refund")
+			return
+		}
+		if err := s.releaseRefund(payment, refund, reason); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"refund_id": refund.ID,
+				"error":     err.Error(),
+			}).Error("Failed to release reserved balance for failed refund")
+			return
+		}
+	}
+
+	refund.Status = domain.RefundStatusFailed
+	refund.FailureReason = sql.NullString{String: reason, Valid: true}
+	refund.FailedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if err := s.refundRepo.Update(refund); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"error":     err.Error(),
+		}).Error("Failed to mark refund as failed")
+		return
+	}
+
+	if refund.IsLatePayment() {
+		s.setLateResolution(refund.PaymentID, domain.LatePaymentPendingReview)
+	}
+
+	s.publishRefundWebhook(ctx, domain.RefundEventFailed, refund)
+}
+
+// setLateResolution updates the late payment resolution of the refunded payment (errors are logged)
+func (s *RefundService) setLateResolution(paymentID string, resolution domain.LatePaymentResolution) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err == nil {
+		payment.LateResolution = sql.NullString{String: string(resolution), Valid: true}
+		err = s.paymentRepo.Update(payment)
+	}
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": paymentID,
+			"resolution": resolution,
+			"error":      err.Error(),
+		}).Error("Failed to update late payment resolution")
+	}
+}
+
+// publishRefundWebhook sends a refund.* webhook to the merchant (non-blocking, errors are logged)
+func (s *RefundService) publishRefundWebhook(ctx context.Context, event string, refund *domain.Refund) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"refund_id":     refund.ID,
+		"payment_id":    refund.PaymentID,
+		"status":        string(refund.Status),
+		"amount_crypto": refund.AmountCrypto.String(),
+		"amount_vnd":    refund.AmountVND.String(),
+		"currency":      refund.Currency,
+		"chain":         string(refund.Chain),
+		"to_address":    refund.ToAddress,
+		"source":        string(refund.Source),
+	}
+	if refund.TxHash.Valid {
+		data["tx_hash"] = refund.TxHash.String
+	}
+	if refund.FailureReason.Valid {
+		data["failure_reason"] = refund.FailureReason.String
+	}
+
+	if err := s.webhookPublisher.PublishWebhook(ctx, refund.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"refund_id": refund.ID,
+			"event":     event,
+			"error":     err.Error(),
+		}).Warn("Failed to publish refund webhook")
+	}
+}
@@ -0,0 +1,533 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// memPaymentRepository keeps payments in memory, methods the tests do not use panic
+type memPaymentRepository struct {
+	domain.PaymentRepository
+	payments map[string]*domain.Payment
+	// transfers receives the transfers saved with ApplyTransfer
+	transfers *memTransferRepository
+	// applyErrs are returned by the next calls to ApplyTransfer, e.g. to fail the payment update
+	applyErrs []error
+}
+
+func newMemPaymentRepository(payments ...*domain.Payment) *memPaymentRepository {
+	repo := &memPaymentRepository{payments: make(map[string]*domain.Payment)}
+	for _, payment := range payments {
+		stored := *payment
+		repo.payments[payment.ID] = &stored
+	}
+	return repo
+}
+
+func (r *memPaymentRepository) GetByID(id string) (*domain.Payment, error) {
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, domain.ErrPaymentNotFound
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (r *memPaymentRepository) Update(payment *domain.Payment) error {
+	if _, ok := r.payments[payment.ID]; !ok {
+		return domain.ErrPaymentNotFound
+	}
+	stored := *payment
+	r.payments[payment.ID] = &stored
+	return nil
+}
+
+// memRefundRepository keeps refunds in memory
+type memRefundRepository struct {
+	refunds map[string]*domain.Refund
+	// beforeCreate runs before the limit check of CreateWithinLimit, e.g. to create a concurrent refund
+	beforeCreate func()
+	// beforeClaim runs before ClaimForSending, e.g. to let another worker claim the refund
+	beforeClaim func(refundID string)
+}
+
+func newMemRefundRepository() *memRefundRepository {
+	return &memRefundRepository{refunds: make(map[string]*domain.Refund)}
+}
+
+func (r *memRefundRepository) Create(refund *domain.Refund) error {
+	stored := *refund
+	r.refunds[refund.ID] = &stored
+	return nil
+}
+
+func (r *memRefundRepository) CreateWithinLimit(refund *domain.Refund, limit decimal.Decimal) error {
+	if r.beforeCreate != nil {
+		r.beforeCreate()
+	}
+	refunded, _ := r.GetTotalRefundedByPayment(refund.PaymentID)
+	if refunded.Add(refund.AmountCrypto).GreaterThan(limit) {
+		return domain.ErrRefundAmountExceeded
+	}
+	return r.Create(refund)
+}
+
+func (r *memRefundRepository) GetByID(id string) (*domain.Refund, error) {
+	refund, ok := r.refunds[id]
+	if !ok {
+		return nil, domain.ErrRefundNotFound
+	}
+	copied := *refund
+	return &copied, nil
+}
+
+func (r *memRefundRepository) Update(refund *domain.Refund) error {
+	if _, ok := r.refunds[refund.ID]; !ok {
+		return domain.ErrRefundNotFound
+	}
+	stored := *refund
+	r.refunds[refund.ID] = &stored
+	return nil
+}
+
+func (r *memRefundRepository) ListByPayment(paymentID string) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	for _, refund := range r.refunds {
+		if refund.PaymentID == paymentID {
+			copied := *refund
+			refunds = append(refunds, &copied)
+		}
+	}
+	sort.Slice(refunds, func(i, j int) bool { return refunds[i].CreatedAt.Before(refunds[j].CreatedAt) })
+	return refunds, nil
+}
+
+func (r *memRefundRepository) ListByStatus(status domain.RefundStatus, limit int) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	for _, refund := range r.refunds {
+		if refund.Status == status {
+			copied := *refund
+			refunds = append(refunds, &copied)
+		}
+	}
+	return refunds, nil
+}
+
+func (r *memRefundRepository) GetTotalRefundedByPayment(paymentID string) (decimal.Decimal, error) {
+	total := decimal.Zero
+	for _, refund := range r.refunds {
+		if refund.PaymentID == paymentID && refund.CountsTowardPayment() {
+			total = total.Add(refund.AmountCrypto)
+		}
+	}
+	return total, nil
+}
+
+func (r *memRefundRepository) ClaimForSending(refundID string) (bool, error) {
+	if r.beforeClaim != nil {
+		r.beforeClaim(refundID)
+	}
+	refund, ok := r.refunds[refundID]
+	if !ok || refund.Status != domain.RefundStatusPending {
+		return false, nil
+	}
+	refund.Status = domain.RefundStatusSending
+	refund.UpdatedAt = time.Now()
+	return true, nil
+}
+
+// memLedgerService records the ledger operations it is asked for, methods the tests do not use panic
+type memLedgerService struct {
+	domain.LedgerService
+	calls []string
+	// surplusErrs are returned by the next calls to RecordPaymentSurplus
+	surplusErrs []error
+}
+
+func (l *memLedgerService) RecordRefundRequested(refundID, merchantID string, amountVND decimal.Decimal) error {
+	l.calls = append(l.calls, "refund_requested:"+amountVND.String())
+	return nil
+}
+
+func (l *memLedgerService) RecordRefundCompleted(refundID, merchantID string, amountVND, amountCrypto decimal.Decimal, cryptoCurrency string) error {
+	l.calls = append(l.calls, "refund_completed:"+amountVND.String())
+	return nil
+}
+
+func (l *memLedgerService) RecordRefundFailed(refundID, merchantID string, amountVND decimal.Decimal, reason string) error {
+	l.calls = append(l.calls, "refund_failed:"+amountVND.String())
+	return nil
+}
+
+// stubRefundSender signs transfers with predictable hashes and reports the configured transaction status
+type stubRefundSender struct {
+	signErr      error
+	broadcastErr error
+	status       domain.RefundStatus
+	statusErr    error
+	signed       int
+	broadcasts   []string
+}
+
+func (s *stubRefundSender) SignRefund(ctx context.Context, chain domain.Chain, currency, toAddress string, amount decimal.Decimal) (*domain.SignedRefundTx, error) {
+	if s.signErr != nil {
+		return nil, s.signErr
+	}
+	s.signed++
+	return &domain.SignedRefundTx{Chain: chain, TxHash: "refund-tx-" + amount.String()}, nil
+}
+
+func (s *stubRefundSender) BroadcastRefund(ctx context.Context, tx *domain.SignedRefundTx) error {
+	s.broadcasts = append(s.broadcasts, tx.TxHash)
+	return s.broadcastErr
+}
+
+func (s *stubRefundSender) GetRefundTxStatus(ctx context.Context, chain domain.Chain, txHash string) (domain.RefundStatus, error) {
+	return s.status, s.statusErr
+}
+
+// memWebhookPublisher records the events of the published webhooks
+type memWebhookPublisher struct {
+	events []string
+}
+
+func (p *memWebhookPublisher) PublishWebhook(ctx context.Context, merchantID, event string, data map[string]interface{}) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newCompletedPayment() *domain.Payment {
+	return &domain.Payment{
+		ID:           "payment-1",
+		MerchantID:   "merchant-1",
+		AmountVND:    decimal.NewFromInt(2500000),
+		AmountCrypto: decimal.NewFromInt(100),
+		Currency:     "USDT",
+		Chain:        domain.ChainSolana,
+		Status:       domain.PaymentStatusCompleted,
+		FromAddress:  sql.NullString{String: "payer-wallet", Valid: true},
+	}
+}
+
+type refundServiceFixture struct {
+	service   *RefundService
+	transfers *memTransferRepository
+	refunds   *memRefundRepository
+	ledger    *memLedgerService
+	sender    *stubRefundSender
+	webhooks  *memWebhookPublisher
+}
+
+func newRefundServiceFixture(payments ...*domain.Payment) *refundServiceFixture {
+	f := &refundServiceFixture{
+		transfers: &memTransferRepository{},
+		refunds:   newMemRefundRepository(),
+		ledger:    &memLedgerService{},
+		sender:    &stubRefundSender{status: domain.RefundStatusSubmitted},
+		webhooks:  &memWebhookPublisher{},
+	}
+	// Each payment received its amount in one transfer unless it says otherwise
+	for _, payment := range payments {
+		received := payment.AmountReceived
+		if received.IsZero() {
+			received = payment.AmountCrypto
+		}
+		f.transfers.transfers = append(f.transfers.transfers, &domain.PaymentTransfer{
+			PaymentID: payment.ID,
+			TxHash:    "tx-" + payment.ID,
+			Amount:    received,
+			Currency:  payment.Currency,
+			Chain:     payment.Chain,
+		})
+	}
+	f.service = NewRefundService(newMemPaymentRepository(payments...), f.transfers, f.refunds, f.ledger, RefundServiceConfig{
+		RefundSender:     f.sender,
+		WebhookPublisher: f.webhooks,
+	}, newTestLogger())
+	return f
+}
+
+func (f *refundServiceFixture) createRefund(t *testing.T, amount string) *domain.Refund {
+	t.Helper()
+	refund, err := f.service.CreateRefund(context.Background(), port.CreateRefundRequest{
+		PaymentID:  "payment-1",
+		MerchantID: "merchant-1",
+		Amount:     decimal.RequireFromString(amount),
+	})
+	require.NoError(t, err)
+	return refund
+}
+
+func (f *refundServiceFixture) stored(t *testing.T, refundID string) *domain.Refund {
+	t.Helper()
+	refund, err := f.refunds.GetByID(refundID)
+	require.NoError(t, err)
+	return refund
+}
+
+func TestRefundService_CreateRefund_Partial(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+
+	refund := f.createRefund(t, "40")
+	assert.Equal(t, "40", refund.AmountCrypto.String())
+	assert.Equal(t, "1000000", refund.AmountVND.String())
+	assert.Equal(t, domain.RefundStatusPending, refund.Status)
+	assert.Equal(t, "payer-wallet", refund.ToAddress)
+
+	// Zero refunds whatever is left
+	rest := f.createRefund(t, "0")
+	assert.Equal(t, "60", rest.AmountCrypto.String())
+	assert.Equal(t, "1500000", rest.AmountVND.String())
+
+	assert.Equal(t, []string{"refund_requested:1000000", "refund_requested:1500000"}, f.ledger.calls)
+	assert.Equal(t, []string{domain.RefundEventCreated, domain.RefundEventCreated}, f.webhooks.events)
+}
+
+func TestRefundService_CreateRefund_Full(t *testing.T) {
+	payment := newCompletedPayment()
+	payment.AmountVND = decimal.NewFromInt(2500001)
+	f := newRefundServiceFixture(payment)
+
+	refund := f.createRefund(t, "100")
+
+	// A full refund debits exactly the payment amount, without rounding
+	assert.Equal(t, "2500001", refund.AmountVND.String())
+}
+
+func TestRefundService_CreateRefund_RejectsOverRefund(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+	f.createRefund(t, "80")
+
+	_, err := f.service.CreateRefund(context.Background(), port.CreateRefundRequest{
+		PaymentID:  "payment-1",
+		MerchantID: "merchant-1",
+		Amount:     decimal.NewFromInt(30),
+	})
+	assert.ErrorIs(t, err, domain.ErrRefundAmountExceeded)
+	assert.Len(t, f.refunds.refunds, 1)
+}
+
+func TestRefundService_CreateRefund_LimitedToAmountReceived(t *testing.T) {
+	payment := newCompletedPayment()
+	// The shortfall was accepted within the merchant's underpayment tolerance
+	payment.AmountReceived = decimal.NewFromInt(98)
+	f := newRefundServiceFixture(payment)
+
+	_, err := f.service.CreateRefund(context.Background(), port.CreateRefundRequest{
+		PaymentID:  "payment-1",
+		MerchantID: "merchant-1",
+		Amount:     decimal.NewFromInt(100),
+	})
+	assert.ErrorIs(t, err, domain.ErrRefundAmountExceeded)
+
+	// Zero amount refunds everything the payer sent
+	refund := f.createRefund(t, "0")
+	assert.Equal(t, "98", refund.AmountCrypto.String())
+}
+
+func TestRefundService_CreateRefund_ConcurrentOverRefundReleasesReservation(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+
+	// Another request refunds most of the payment after this one read the refunded total
+	f.refunds.beforeCreate = func() {
+		f.refunds.beforeCreate = nil
+		require.NoError(t, f.refunds.Create(&domain.Refund{
+			ID:           "concurrent",
+			PaymentID:    "payment-1",
+			AmountCrypto: decimal.NewFromInt(90),
+			Status:       domain.RefundStatusPending,
+		}))
+	}
+
+	_, err := f.service.CreateRefund(context.Background(), port.CreateRefundRequest{
+		PaymentID:  "payment-1",
+		MerchantID: "merchant-1",
+		Amount:     decimal.NewFromInt(50),
+	})
+	assert.ErrorIs(t, err, domain.ErrRefundAmountExceeded)
+	assert.Equal(t, []string{"refund_requested:1250000", "refund_failed:1250000"}, f.ledger.calls)
+}
+
+func TestRefundService_ProcessPendingRefunds_SignFailureReleasesReservation(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+	refund := f.createRefund(t, "40")
+	f.sender.signErr = errors.New("no solana mint configured for USDT")
+
+	submitted, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, submitted)
+
+	stored := f.stored(t, refund.ID)
+	assert.Equal(t, domain.RefundStatusFailed, stored.Status)
+	assert.Equal(t, "no solana mint configured for USDT", stored.FailureReason.String)
+	assert.Equal(t, []string{"refund_requested:1000000", "refund_failed:1000000"}, f.ledger.calls)
+	assert.Equal(t, []string{domain.RefundEventCreated, domain.RefundEventFailed}, f.webhooks.events)
+}
+
+func TestRefundService_ProcessPendingRefunds_BroadcastFailureKeepsReservation(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+	refund := f.createRefund(t, "40")
+	f.sender.broadcastErr = errors.New("context deadline exceeded")
+
+	submitted, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, submitted)
+
+	// The node may have accepted the transaction, it is followed by its hash
+	stored := f.stored(t, refund.ID)
+	assert.Equal(t, domain.RefundStatusSending, stored.Status)
+	assert.Equal(t, "refund-tx-40", stored.TxHash.String)
+	assert.Equal(t, []string{"refund_requested:1000000"}, f.ledger.calls)
+
+	// Later runs neither send it again nor touch it before the timeout
+	f.sender.broadcastErr = nil
+	_, err = f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	reconciled, err := f.service.ReconcileSendingRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, reconciled)
+	assert.Equal(t, 1, f.sender.signed)
+
+	// Once the timeout passed the transaction found on-chain completes the refund
+	f.refunds.refunds[refund.ID].UpdatedAt = time.Now().Add(-RefundSendingTimeout)
+	f.sender.status = domain.RefundStatusCompleted
+	reconciled, err = f.service.ReconcileSendingRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, reconciled)
+	assert.Equal(t, domain.RefundStatusCompleted, f.stored(t, refund.ID).Status)
+	assert.Equal(t, []string{"refund_requested:1000000", "refund_completed:1000000"}, f.ledger.calls)
+}
+
+func TestRefundService_ReconcileSendingRefunds(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+	unsent := f.createRefund(t, "10")
+	lost := f.createRefund(t, "20")
+	for _, refund := range []*domain.Refund{unsent, lost} {
+		stored := f.refunds.refunds[refund.ID]
+		stored.Status = domain.RefundStatusSending
+		stored.UpdatedAt = time.Now().Add(-RefundSendingTimeout)
+	}
+	f.refunds.refunds[lost.ID].TxHash = sql.NullString{String: "refund-tx-20", Valid: true}
+	f.sender.statusErr = domain.ErrRefundTxNotFound
+
+	reconciled, err := f.service.ReconcileSendingRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, reconciled)
+
+	// A refund without a saved hash was never broadcast and can be sent again
+	assert.Equal(t, domain.RefundStatusPending, f.stored(t, unsent.ID).Status)
+	// The blockhash of a missing Solana transaction expired, the refund is signed again
+	stored := f.stored(t, lost.ID)
+	assert.Equal(t, domain.RefundStatusPending, stored.Status)
+	assert.False(t, stored.TxHash.Valid)
+	assert.Equal(t, []string{"refund_requested:250000", "refund_requested:500000"}, f.ledger.calls)
+}
+
+func TestRefundService_MissingBSCRefundIsDropped(t *testing.T) {
+	payment := newCompletedPayment()
+	payment.Chain = domain.ChainBSC
+	f := newRefundServiceFixture(payment)
+	refund := f.createRefund(t, "20")
+
+	_, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	f.sender.statusErr = domain.ErrRefundTxNotFound
+
+	// The transaction may still be picked up by the chain
+	finalized, err := f.service.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, finalized)
+	assert.Equal(t, domain.RefundStatusSubmitted, f.stored(t, refund.ID).Status)
+
+	// Past the timeout it is left for an operator, the balance stays reserved
+	f.refunds.refunds[refund.ID].SubmittedAt = sql.NullTime{Time: time.Now().Add(-RefundDroppedTimeout), Valid: true}
+	_, err = f.service.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+
+	stored := f.stored(t, refund.ID)
+	assert.Equal(t, domain.RefundStatusDropped, stored.Status)
+	assert.Equal(t, "refund-tx-20", stored.TxHash.String)
+	assert.Equal(t, 1, f.sender.signed)
+	assert.Equal(t, []string{"refund_requested:500000"}, f.ledger.calls)
+}
+
+func TestRefundService_ProcessPendingRefunds_SkipsClaimedRefunds(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+	f.createRefund(t, "40")
+
+	// Another worker run claims the refund after this one listed it
+	f.refunds.beforeClaim = func(refundID string) {
+		f.refunds.refunds[refundID].Status = domain.RefundStatusSending
+	}
+
+	submitted, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, submitted)
+	assert.Equal(t, 0, f.sender.signed)
+	assert.Empty(t, f.sender.broadcasts)
+}
+
+func TestRefundService_Finalization(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+	refund := f.createRefund(t, "40")
+
+	submitted, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, submitted)
+
+	stored := f.stored(t, refund.ID)
+	assert.Equal(t, domain.RefundStatusSubmitted, stored.Status)
+	assert.Equal(t, "refund-tx-40", stored.TxHash.String)
+	assert.Equal(t, []string{"refund-tx-40"}, f.sender.broadcasts)
+
+	// Not final yet
+	finalized, err := f.service.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, finalized)
+
+	f.sender.status = domain.RefundStatusCompleted
+	finalized, err = f.service.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, finalized)
+
+	stored = f.stored(t, refund.ID)
+	assert.Equal(t, domain.RefundStatusCompleted, stored.Status)
+	assert.True(t, stored.CompletedAt.Valid)
+	assert.Equal(t, []string{"refund_requested:1000000", "refund_completed:1000000"}, f.ledger.calls)
+	assert.Equal(t, []string{domain.RefundEventCreated, domain.RefundEventSubmitted, domain.RefundEventCompleted}, f.webhooks.events)
+}
+
+func TestRefundService_Finalization_FailedOnChain(t *testing.T) {
+	f := newRefundServiceFixture(newCompletedPayment())
+	refund := f.createRefund(t, "40")
+
+	_, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+
+	f.sender.status = domain.RefundStatusFailed
+	finalized, err := f.service.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, finalized)
+
+	assert.Equal(t, domain.RefundStatusFailed, f.stored(t, refund.ID).Status)
+	assert.Equal(t, []string{"refund_requested:1000000", "refund_failed:1000000"}, f.ledger.calls)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// DefaultSolanaPayLabel is shown by wallets when no label is configured
+const DefaultSolanaPayLabel = "Stable Payment Gateway"
+
+// SolanaPayService answers Solana Pay transaction requests of payments
+// Wallets first fetch the label and icon, then post the payer account and sign the returned transaction
+type SolanaPayService struct {
+	paymentRepo   domain.PaymentRepository
+	tokenRegistry *TokenRegistry
+	builder       domain.SolanaPayTransactionBuilder // Composes the transactions (nil when no Solana RPC is configured)
+	label         string
+	iconURL       string
+	logger        *logrus.Logger
+}
+
+// SolanaPayServiceConfig contains the optional settings of SolanaPayService
+type SolanaPayServiceConfig struct {
+	TransactionBuilder domain.SolanaPayTransactionBuilder // Optional: transaction requests fail without it
+	Label              string                             // Optional: defaults to DefaultSolanaPayLabel
+	IconURL            string                             // Optional: SVG, PNG or WebP icon shown by wallets
+}
+
+// NewSolanaPayService creates a new Solana Pay service
+func NewSolanaPayService(
+	paymentRepo domain.PaymentRepository,
+	tokenRegistry *TokenRegistry,
+	config SolanaPayServiceConfig,
+	logger *logrus.Logger,
+) *SolanaPayService {
+	label := config.Label
+	if label == "" {
+		label = DefaultSolanaPayLabel
+	}
+
+	return &SolanaPayService{
+		paymentRepo:   paymentRepo,
+		tokenRegistry: tokenRegistry,
+		builder:       config.TransactionBuilder,
+		label:         label,
+		iconURL:       config.IconURL,
+		logger:        logger,
+	}
+}
+
+// GetTransactionRequestInfo returns the label and icon of a Solana payment's transaction request
+func (s *SolanaPayService) GetTransactionRequestInfo(ctx context.Context, paymentID string) (*domain.SolanaPayInfo, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Chain != domain.ChainSolana {
+		return nil, fmt.Errorf("%w: solana pay is not available on %s", domain.ErrInvalidChain, payment.Chain)
+	}
+
+	return &domain.SolanaPayInfo{
+		Label: s.label,
+		Icon:  s.iconURL,
+	}, nil
+}
+
+// CreateTransaction composes the transfer of the amount still owed on a payment, paid and signed by account
+// The transaction carries the payment's reference key, stored on the payment so the listener watches it,
+// and the payment ID as memo
+func (s *SolanaPayService) CreateTransaction(ctx context.Context, paymentID, account string) (*domain.SolanaPayTransaction, error) {
+	if s.builder == nil || s.tokenRegistry == nil {
+		return nil, domain.ErrSolanaPayNotConfigured
+	}
+
+	if account == "" {
+		return nil, domain.ErrInvalidSolanaPayAccount
+	}
+
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Chain != domain.ChainSolana {
+		return nil, fmt.Errorf("%w: solana pay is not available on %s", domain.ErrInvalidChain, payment.Chain)
+	}
+
+	// Test payments are settled by simulation, a wallet must not pay them
+	if payment.TestMode {
+		return nil, domain.ErrTestPaymentNotSimulated
+	}
+
+	if !payment.CanBeConfirmed() {
+		if payment.IsExpired() {
+			return nil, domain.ErrPaymentExpired
+		}
+		return nil, domain.ErrInvalidPaymentState
+	}
+
+	token, err := s.tokenRegistry.Find(payment.Chain, payment.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if token.Address == "" {
+		return nil, fmt.Errorf("%w: no mint configured for %s", domain.ErrSolanaPayNotConfigured, token.Symbol)
+	}
+
+	reference := payment.SolanaPayReference.String
+	if !payment.SolanaPayReference.Valid {
+		reference = s.builder.ReferenceKey(payment.ID)
+		if err := s.paymentRepo.SetSolanaPayReference(payment.ID, reference); err != nil {
+			return nil, fmt.Errorf("failed to store solana pay reference: %w", err)
+		}
+	}
+
+	// Payments that already received part of the amount are asked for the rest
+	amount := payment.RemainingAmount().Truncate(int32(token.Decimals))
+
+	transaction, err := s.builder.BuildTransferTransaction(ctx, domain.SolanaPayTransfer{
+		Payer:         account,
+		Recipient:     payment.DestinationWallet,
+		TokenMint:     token.Address,
+		TokenDecimals: token.Decimals,
+		Amount:        amount,
+		Memo:          payment.ID,
+		Reference:     reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"account":    account,
+		"amount":     amount.String(),
+		"reference":  reference,
+	}).Info("Composed Solana Pay transaction")
+
+	return &domain.SolanaPayTransaction{
+		Transaction: transaction,
+		Message:     fmt.Sprintf("Pay %s %s to %s", amount.String(), payment.Currency, s.label),
+		Reference:   reference,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// recordPaymentSplit credits the shares of a completed split payment to its recipients (non-fatal)
+func (s *PaymentService) recordPaymentSplit(ctx context.Context, payment *domain.Payment) {
+	ledger := s.ledgerFor(payment)
+	if !payment.HasSplits() || ledger == nil {
+		return
+	}
+
+	sellers, commission := payment.Splits.Shares()
+	if err := ledger.RecordPaymentSplit(payment.ID, payment.MerchantID, sellers, commission); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id":  payment.ID,
+			"merchant_id": payment.MerchantID,
+			"splits":      len(payment.Splits),
+			"error":       err.Error(),
+		}).Error("Failed to record payment split in ledger")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":  payment.ID,
+		"merchant_id": payment.MerchantID,
+		"splits":      len(payment.Splits),
+	}).Info("Payment split recorded in ledger")
+}
+
+// validateSplitRecipients checks that every seller of a split payment is an approved merchant of the payment's mode
+func (s *PaymentService) validateSplitRecipients(merchantID string, testMode bool, splits []domain.PaymentSplit) error {
+	for _, split := range splits {
+		if split.MerchantID == "" || split.MerchantID == merchantID {
+			continue
+		}
+
+		kycStatus, err := s.merchantRepo.GetMerchantKYCStatus(split.MerchantID)
+		if err != nil {
+			return fmt.Errorf("%w: merchant %s", domain.ErrSplitRecipientNotApproved, split.MerchantID)
+		}
+		if kycStatus != KYCStatusApproved {
+			s.logger.WithFields(logrus.Fields{
+				"merchant_id":  merchantID,
+				"recipient_id": split.MerchantID,
+			}).Warn("Split recipient not approved")
+			return fmt.Errorf("%w: merchant %s", domain.ErrSplitRecipientNotApproved, split.MerchantID)
+		}
+
+		// Test payments only credit sandbox ledgers and live payments only live ones
+		isSandbox, err := s.merchantRepo.IsSandboxMerchant(split.MerchantID)
+		if err != nil {
+			return fmt.Errorf("failed to get split recipient mode: %w", err)
+		}
+		if isSandbox != testMode {
+			return fmt.Errorf("%w: merchant %s", domain.ErrSplitRecipientTestMode, split.MerchantID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+func (l *memLedgerService) RecordSplitRefundRequested(refundID, merchantID string, sellerShares map[string]decimal.Decimal, commission decimal.Decimal) error {
+	l.calls = append(l.calls, "split_refund_requested:"+formatSplitShares(sellerShares, commission))
+	return nil
+}
+
+func (l *memLedgerService) RecordSplitRefundFailed(refundID, merchantID string, sellerShares map[string]decimal.Decimal, commission decimal.Decimal, reason string) error {
+	l.calls = append(l.calls, "split_refund_failed:"+formatSplitShares(sellerShares, commission))
+	return nil
+}
+
+// formatSplitShares lists the shares of a split ledger call ordered by seller, commission last
+func formatSplitShares(sellerShares map[string]decimal.Decimal, commission decimal.Decimal) string {
+	shares := make([]string, 0, len(sellerShares)+1)
+	for sellerID, amount := range sellerShares {
+		shares = append(shares, sellerID+"="+amount.String())
+	}
+	sort.Strings(shares)
+	return strings.Join(append(shares, "commission="+commission.String()), ",")
+}
+
+// newSplitPayment returns a completed 100 USD payment whose net amount went to two sellers and the marketplace
+func newSplitPayment(t *testing.T) *domain.Payment {
+	t.Helper()
+	payment := newCompletedPayment()
+	payment.PricingCurrency = "USD"
+	payment.PricingAmount = decimal.NewFromInt(100)
+	payment.FeePercentage = decimal.NewFromFloat(0.01)
+	payment.CalculateFee()
+	require.NoError(t, payment.ApplySplits([]domain.PaymentSplit{
+		{MerchantID: "seller-a", Type: domain.PaymentSplitTypeFixed, Value: decimal.NewFromInt(50)},
+		{MerchantID: "seller-b", Type: domain.PaymentSplitTypePercentage, Value: decimal.NewFromInt(40)},
+		{MerchantID: "merchant-1", Type: domain.PaymentSplitTypeFixed, Value: decimal.NewFromFloat(9.4)},
+	}))
+	return payment
+}
+
+func TestRefundService_SplitPaymentRefundIsTakenFromEveryShare(t *testing.T) {
+	f := newRefundServiceFixture(newSplitPayment(t))
+
+	refund := f.createRefund(t, "40")
+
+	// 1,000,000 VND of a 2,475,000 VND net amount shared 1,250,000 / 990,000 / 235,000
+	assert.Equal(t, "1000000", refund.AmountVND.String())
+	assert.Equal(t, []string{"split_refund_requested:seller-a=505051,seller-b=400000,commission=94949"}, f.ledger.calls)
+
+	f.sender.status = domain.RefundStatusCompleted
+	_, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+	_, err = f.service.CheckSubmittedRefunds(context.Background())
+	require.NoError(t, err)
+
+	// Once sent the reserved amount is deducted from the marketplace merchant like any refund
+	assert.Equal(t, domain.RefundStatusCompleted, f.stored(t, refund.ID).Status)
+	assert.Equal(t, "refund_completed:1000000", f.ledger.calls[len(f.ledger.calls)-1])
+}
+
+func TestRefundService_FailedSplitRefundReturnsEveryShare(t *testing.T) {
+	f := newRefundServiceFixture(newSplitPayment(t))
+	refund := f.createRefund(t, "10")
+	f.sender.signErr = errors.New("insufficient hot wallet balance")
+
+	_, err := f.service.ProcessPendingRefunds(context.Background())
+	require.NoError(t, err)
+
+	// The reserve is given back with the shares it was taken with, remainder included
+	assert.Equal(t, domain.RefundStatusFailed, f.stored(t, refund.ID).Status)
+	assert.Equal(t, []string{
+		"split_refund_requested:seller-a=126263,seller-b=100000,commission=23737",
+		"split_refund_failed:seller-a=126263,seller-b=100000,commission=23737",
+	}, f.ledger.calls)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// ListSubscriptionCyclePayments lists the payments issued for a subscription billing cycle, oldest first
+func (s *PaymentService) ListSubscriptionCyclePayments(ctx context.Context, cycleID string) ([]*domain.Payment, error) {
+	payments, err := s.paymentRepo.ListBySubscriptionCycle(cycleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription cycle payments: %w", err)
+	}
+	return payments, nil
+}
+
+// recordSubscriptionPayment marks the billing cycle of a completed payment paid (non-fatal)
+// A past due subscription becomes active again
+func (s *PaymentService) recordSubscriptionPayment(ctx context.Context, payment *domain.Payment) {
+	if !payment.SubscriptionCycleID.Valid || s.subscriptionRepo == nil {
+		return
+	}
+
+	cycle, subscription, paid, err := s.subscriptionRepo.MarkCyclePaid(payment.SubscriptionCycleID.String, payment.ID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"cycle_id":   payment.SubscriptionCycleID.String,
+			"error":      err.Error(),
+		}).Error("Failed to mark subscription cycle paid")
+		return
+	}
+
+	if !paid {
+		// An earlier attempt already paid the cycle or it was closed, the merchant can refund this payment
+		s.logger.WithFields(logrus.Fields{
+			"payment_id":      payment.ID,
+			"cycle_id":        cycle.ID,
+			"subscription_id": subscription.ID,
+			"cycle_status":    cycle.Status,
+		}).Warn("Payment completed for a subscription cycle that is no longer open")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":      payment.ID,
+		"cycle_id":        cycle.ID,
+		"subscription_id": subscription.ID,
+		"cycle_number":    cycle.CycleNumber,
+	}).Info("Subscription cycle paid")
+
+	s.publishSubscriptionPaymentWebhook(ctx, domain.SubscriptionEventPaymentSucceeded, subscription, cycle, payment)
+}
+
+// reverseSubscriptionPayment reopens the billing cycle of a reversed payment (non-fatal)
+// The worker then retries the cycle on the dunning schedule
+func (s *PaymentService) reverseSubscriptionPayment(ctx context.Context, payment *domain.Payment) {
+	if !payment.SubscriptionCycleID.Valid || s.subscriptionRepo == nil {
+		return
+	}
+
+	cycle, subscription, reopened, err := s.subscriptionRepo.ReopenCycle(payment.SubscriptionCycleID.String, payment.ID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"payment_id": payment.ID,
+			"cycle_id":   payment.SubscriptionCycleID.String,
+			"error":      err.Error(),
+		}).Error("Failed to reopen subscription cycle")
+		return
+	}
+	if !reopened {
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"payment_id":      payment.ID,
+		"cycle_id":        cycle.ID,
+		"subscription_id": subscription.ID,
+	}).Warn("Subscription cycle reopened after payment reversal")
+
+	if subscription.Status == domain.SubscriptionStatusPastDue {
+		s.publishSubscriptionPaymentWebhook(ctx, domain.SubscriptionEventPastDue, subscription, cycle, payment)
+	}
+}
+
+// publishSubscriptionPaymentWebhook notifies the merchant of a subscription payment event (non-fatal)
+func (s *PaymentService) publishSubscriptionPaymentWebhook(ctx context.Context, event string, subscription *domain.Subscription, cycle *domain.SubscriptionCycle, payment *domain.Payment) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	data := subscriptionWebhookData(subscription, cycle)
+	data["payment_id"] = payment.ID
+	if err := s.webhookPublisher.PublishWebhook(ctx, subscription.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"subscription_id": subscription.ID,
+			"event":           event,
+			"error":           err.Error(),
+		}).Warn("Failed to publish subscription webhook")
+	}
+}
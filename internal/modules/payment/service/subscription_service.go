@@ -0,0 +1,732 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+const (
+	// SubscriptionBatchSize is the maximum number of subscriptions and cycles handled per billing run
+	SubscriptionBatchSize = 50
+
+	// SubscriptionRecheckInterval is how long the worker waits before checking a cycle payment still in flight,
+	// or retrying a payment that could not be created
+	SubscriptionRecheckInterval = 5 * time.Minute
+)
+
+// SubscriptionService manages plans and subscriptions and issues the payments of their billing cycles
+// Cycles are marked paid by PaymentService as their payments complete
+type SubscriptionService struct {
+	paymentService     port.PaymentService
+	planRepo           domain.SubscriptionPlanRepository
+	subscriptionRepo   domain.SubscriptionRepository
+	webhookPublisher   domain.WebhookPublisher // For subscription.* merchant webhooks
+	emailSender        domain.EmailSender      // For sending payment links to payers
+	dunningPolicy      domain.DunningPolicy
+	paymentPageBaseURL string
+	logger             *logrus.Logger
+}
+
+// SubscriptionServiceConfig contains optional dependencies for SubscriptionService
+type SubscriptionServiceConfig struct {
+	WebhookPublisher   domain.WebhookPublisher // Optional: for subscription.* webhooks
+	EmailSender        domain.EmailSender      // Optional: payers without it only get links through the merchant
+	DunningPolicy      domain.DunningPolicy    // Defaults to domain.DefaultDunningPolicy()
+	PaymentPageBaseURL string                  // Base URL of the hosted payment page sent to payers
+}
+
+// NewSubscriptionService creates a new subscription service
+func NewSubscriptionService(
+	paymentService port.PaymentService,
+	planRepo domain.SubscriptionPlanRepository,
+	subscriptionRepo domain.SubscriptionRepository,
+	config SubscriptionServiceConfig,
+	logger *logrus.Logger,
+) *SubscriptionService {
+	dunningPolicy := config.DunningPolicy
+	if dunningPolicy.GracePeriod <= 0 {
+		dunningPolicy = domain.DefaultDunningPolicy()
+	}
+
+	return &SubscriptionService{
+		paymentService:     paymentService,
+		planRepo:           planRepo,
+		subscriptionRepo:   subscriptionRepo,
+		webhookPublisher:   config.WebhookPublisher,
+		emailSender:        config.EmailSender,
+		dunningPolicy:      dunningPolicy,
+		paymentPageBaseURL: strings.TrimRight(config.PaymentPageBaseURL, "/"),
+		logger:             logger,
+	}
+}
+
+// CreatePlan creates an active plan billed every interval count of the billing interval
+func (s *SubscriptionService) CreatePlan(ctx context.Context, req port.CreateSubscriptionPlanRequest) (*domain.SubscriptionPlan, error) {
+	currency := domain.NormalizePricingCurrency(req.PricingCurrency)
+	if !domain.IsSupportedPricingCurrency(currency) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedPricingCurrency, currency)
+	}
+
+	intervalCount := req.IntervalCount
+	if intervalCount == 0 {
+		intervalCount = 1
+	}
+
+	now := time.Now()
+	plan := &domain.SubscriptionPlan{
+		ID:              uuid.New().String(),
+		MerchantID:      req.MerchantID,
+		Name:            req.Name,
+		PricingCurrency: currency,
+		Amount:          req.Amount.Round(2),
+		BillingInterval: req.BillingInterval,
+		IntervalCount:   intervalCount,
+		Status:          domain.SubscriptionPlanStatusActive,
+		Metadata:        req.Metadata,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if req.Description != "" {
+		plan.Description = sql.NullString{String: req.Description, Valid: true}
+	}
+	if req.Currency != "" {
+		plan.Currency = sql.NullString{String: req.Currency, Valid: true}
+	}
+	if req.Chain != "" {
+		plan.Chain = sql.NullString{String: string(req.Chain), Valid: true}
+	}
+
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.planRepo.Create(plan); err != nil {
+		return nil, fmt.Errorf("failed to create subscription plan: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"plan_id":          plan.ID,
+		"merchant_id":      plan.MerchantID,
+		"amount":           plan.Amount.String(),
+		"currency":         plan.PricingCurrency,
+		"billing_interval": plan.BillingInterval,
+		"interval_count":   plan.IntervalCount,
+	}).Info("Subscription plan created")
+
+	return plan, nil
+}
+
+// GetPlan returns a plan of the merchant
+func (s *SubscriptionService) GetPlan(ctx context.Context, planID, merchantID string) (*domain.SubscriptionPlan, error) {
+	plan, err := s.planRepo.GetByID(planID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not reveal plans of other merchants
+	if plan.MerchantID != merchantID {
+		return nil, domain.ErrSubscriptionPlanNotFound
+	}
+
+	return plan, nil
+}
+
+// ListPlans lists the plans of a merchant, newest first, with their total count
+func (s *SubscriptionService) ListPlans(ctx context.Context, merchantID string, limit, offset int) ([]*domain.SubscriptionPlan, int64, error) {
+	plans, err := s.planRepo.ListByMerchant(merchantID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscription plans: %w", err)
+	}
+
+	total, err := s.planRepo.CountByMerchant(merchantID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count subscription plans: %w", err)
+	}
+
+	return plans, total, nil
+}
+
+// ArchivePlan stops new subscriptions to a plan, existing subscriptions keep billing
+func (s *SubscriptionService) ArchivePlan(ctx context.Context, planID, merchantID string) (*domain.SubscriptionPlan, error) {
+	plan, err := s.GetPlan(ctx, planID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Status == domain.SubscriptionPlanStatusArchived {
+		return plan, nil
+	}
+
+	plan.Status = domain.SubscriptionPlanStatusArchived
+	if err := s.planRepo.Update(plan); err != nil {
+		return nil, fmt.Errorf("failed to archive subscription plan: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"plan_id":     plan.ID,
+		"merchant_id": plan.MerchantID,
+	}).Info("Subscription plan archived")
+
+	return plan, nil
+}
+
+// CreateSubscription subscribes a customer to an active plan
+// The first cycle is issued right away unless the subscription starts later
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, req port.CreateSubscriptionRequest) (*domain.Subscription, error) {
+	plan, err := s.GetPlan(ctx, req.PlanID, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status != domain.SubscriptionPlanStatusActive {
+		return nil, domain.ErrSubscriptionPlanArchived
+	}
+
+	now := time.Now()
+	start := now
+	if req.StartAt != nil && req.StartAt.After(now) {
+		start = *req.StartAt
+	}
+
+	subscription := &domain.Subscription{
+		ID:                 uuid.New().String(),
+		MerchantID:         plan.MerchantID,
+		PlanID:             plan.ID,
+		Status:             domain.SubscriptionStatusActive,
+		CurrentPeriodStart: start,
+		CurrentPeriodEnd:   plan.NextPeriodEnd(start),
+		NextBillingAt:      start,
+		Metadata:           req.Metadata,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if req.CustomerName != "" {
+		subscription.CustomerName = sql.NullString{String: req.CustomerName, Valid: true}
+	}
+	if req.CustomerEmail != "" {
+		subscription.CustomerEmail = sql.NullString{String: req.CustomerEmail, Valid: true}
+	}
+	if req.CustomerReference != "" {
+		subscription.CustomerReference = sql.NullString{String: req.CustomerReference, Valid: true}
+	}
+
+	if err := s.subscriptionRepo.Create(subscription); err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"merchant_id":     subscription.MerchantID,
+		"plan_id":         plan.ID,
+		"start":           start,
+	}).Info("Subscription created")
+
+	s.publishSubscriptionWebhook(ctx, domain.SubscriptionEventCreated, subscription, nil, nil)
+
+	if !start.After(now) {
+		// The worker issues the cycle on its next run if this fails
+		if err := s.issueCycle(ctx, subscription, plan, now); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"subscription_id": subscription.ID,
+				"error":           err.Error(),
+			}).Warn("Failed to issue first subscription cycle")
+		}
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription returns a subscription of the merchant
+func (s *SubscriptionService) GetSubscription(ctx context.Context, subscriptionID, merchantID string) (*domain.Subscription, error) {
+	subscription, err := s.subscriptionRepo.GetByID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not reveal subscriptions of other merchants
+	if subscription.MerchantID != merchantID {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	return subscription, nil
+}
+
+// ListSubscriptions lists the subscriptions of a merchant by status, newest first, with their total count
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, merchantID string, status domain.SubscriptionStatus, limit, offset int) ([]*domain.Subscription, int64, error) {
+	subscriptions, err := s.subscriptionRepo.ListByMerchant(merchantID, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	total, err := s.subscriptionRepo.CountByMerchant(merchantID, status)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+
+	return subscriptions, total, nil
+}
+
+// ListCycles lists the billing cycles of a subscription of the merchant, newest first
+func (s *SubscriptionService) ListCycles(ctx context.Context, subscriptionID, merchantID string) ([]*domain.SubscriptionCycle, error) {
+	subscription, err := s.GetSubscription(ctx, subscriptionID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	cycles, err := s.subscriptionRepo.ListCycles(subscription.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription cycles: %w", err)
+	}
+
+	return cycles, nil
+}
+
+// CancelSubscription cancels a subscription now, voiding its open cycle, or at the end of the current period
+// Payments of a voided cycle that are still in flight complete normally and can be refunded
+func (s *SubscriptionService) CancelSubscription(ctx context.Context, subscriptionID, merchantID string, atPeriodEnd bool) (*domain.Subscription, error) {
+	subscription, err := s.GetSubscription(ctx, subscriptionID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.IsCanceled() {
+		return subscription, nil
+	}
+
+	if atPeriodEnd {
+		subscription.CancelAtPeriodEnd = true
+		if err := s.subscriptionRepo.Update(subscription); err != nil {
+			return nil, fmt.Errorf("failed to update subscription: %w", err)
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"subscription_id":    subscription.ID,
+			"merchant_id":        subscription.MerchantID,
+			"current_period_end": subscription.CurrentPeriodEnd,
+		}).Info("Subscription set to cancel at period end")
+
+		return subscription, nil
+	}
+
+	cycles, err := s.subscriptionRepo.ListCycles(subscription.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription cycles: %w", err)
+	}
+	for _, cycle := range cycles {
+		if !cycle.IsOpen() {
+			continue
+		}
+		cycle.Close(domain.SubscriptionCycleStatusVoid)
+		if err := s.subscriptionRepo.UpdateCycle(cycle); err != nil && !errors.Is(err, domain.ErrSubscriptionCycleNotOpen) {
+			return nil, fmt.Errorf("failed to void subscription cycle: %w", err)
+		}
+	}
+
+	if err := s.cancel(ctx, subscription, domain.SubscriptionCancelReasonMerchant); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// ProcessDueSubscriptions issues the cycles of subscriptions due for billing and runs the dunning
+// schedule of unpaid cycles: expired payments are retried and subscriptions still unpaid at the
+// end of the grace period are canceled. Returns the number of subscriptions and cycles handled.
+func (s *SubscriptionService) ProcessDueSubscriptions(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	subscriptions, err := s.subscriptionRepo.ListDueForBilling(now, SubscriptionBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list subscriptions due for billing: %w", err)
+	}
+
+	processed := 0
+	for _, subscription := range subscriptions {
+		if err := s.billSubscription(ctx, subscription, now); err != nil {
+			if errors.Is(err, domain.ErrSubscriptionCycleExists) {
+				continue
+			}
+			s.logger.WithFields(logrus.Fields{
+				"subscription_id": subscription.ID,
+				"error":           err.Error(),
+			}).Error("Failed to bill subscription")
+			continue
+		}
+		processed++
+	}
+
+	cycles, err := s.subscriptionRepo.ListCyclesDueForAttempt(now, SubscriptionBatchSize)
+	if err != nil {
+		return processed, fmt.Errorf("failed to list subscription cycles due for an attempt: %w", err)
+	}
+
+	for _, cycle := range cycles {
+		if err := s.processCycle(ctx, cycle, now); err != nil {
+			if errors.Is(err, domain.ErrSubscriptionCycleNotOpen) {
+				continue
+			}
+			s.logger.WithFields(logrus.Fields{
+				"cycle_id":        cycle.ID,
+				"subscription_id": cycle.SubscriptionID,
+				"error":           err.Error(),
+			}).Error("Failed to process subscription cycle")
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// billSubscription issues the next cycle of a subscription, or cancels it if the merchant asked to at period end
+func (s *SubscriptionService) billSubscription(ctx context.Context, subscription *domain.Subscription, now time.Time) error {
+	if subscription.CancelAtPeriodEnd {
+		return s.cancel(ctx, subscription, domain.SubscriptionCancelReasonPeriodEnd)
+	}
+
+	plan, err := s.planRepo.GetByID(subscription.PlanID)
+	if err != nil {
+		return fmt.Errorf("failed to get subscription plan: %w", err)
+	}
+
+	return s.issueCycle(ctx, subscription, plan, now)
+}
+
+// issueCycle saves the next billing cycle of a subscription and issues its first payment
+func (s *SubscriptionService) issueCycle(ctx context.Context, subscription *domain.Subscription, plan *domain.SubscriptionPlan, now time.Time) error {
+	cycle := subscription.NextCycle(plan, s.dunningPolicy, now)
+	if err := s.subscriptionRepo.IssueCycle(subscription, cycle); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"cycle_id":        cycle.ID,
+		"cycle_number":    cycle.CycleNumber,
+		"period_start":    cycle.PeriodStart,
+		"period_end":      cycle.PeriodEnd,
+	}).Info("Subscription cycle issued")
+
+	return s.attemptCycle(ctx, subscription, plan, cycle, now)
+}
+
+// processCycle checks the payment of an open cycle that is due for an attempt
+// Completed payments mark the cycle paid, payments in flight are checked again later and
+// failed ones are retried on the dunning schedule until the grace period ends
+func (s *SubscriptionService) processCycle(ctx context.Context, cycle *domain.SubscriptionCycle, now time.Time) error {
+	subscription, err := s.subscriptionRepo.GetByID(cycle.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	if subscription.IsCanceled() {
+		cycle.Close(domain.SubscriptionCycleStatusVoid)
+		return s.subscriptionRepo.UpdateCycle(cycle)
+	}
+
+	if cycle.PaymentID.Valid {
+		payment, err := s.paymentService.GetPaymentStatus(ctx, cycle.PaymentID.String)
+		if err != nil {
+			return fmt.Errorf("failed to get cycle payment: %w", err)
+		}
+
+		switch {
+		case payment.IsCompleted():
+			// Completion normally marks the cycle paid, this covers a payment service without the subscription repository
+			return s.markCyclePaid(ctx, cycle, payment)
+		case isPaymentInFlight(payment):
+			cycle.NextAttemptAt = sql.NullTime{Time: now.Add(SubscriptionRecheckInterval), Valid: true}
+			return s.subscriptionRepo.UpdateCycle(cycle)
+		default:
+			return s.failAttempt(ctx, subscription, cycle, payment, now)
+		}
+	}
+
+	if cycle.IsGraceOver(now) {
+		return s.closeUnpaidCycle(ctx, subscription, cycle)
+	}
+
+	plan, err := s.planRepo.GetByID(subscription.PlanID)
+	if err != nil {
+		return fmt.Errorf("failed to get subscription plan: %w", err)
+	}
+
+	return s.attemptCycle(ctx, subscription, plan, cycle, now)
+}
+
+// attemptCycle creates a payment for the cycle and sends its link to the merchant and the payer
+// A payment issued by an attempt whose cycle update failed is reused instead of creating another one,
+// and what expired underpaid payments of the cycle received is deducted from the amount asked.
+func (s *SubscriptionService) attemptCycle(ctx context.Context, subscription *domain.Subscription, plan *domain.SubscriptionPlan, cycle *domain.SubscriptionCycle, now time.Time) error {
+	payments, err := s.paymentService.ListSubscriptionCyclePayments(ctx, cycle.ID)
+	if err != nil {
+		return s.rescheduleAttempt(cycle, now, err)
+	}
+
+	amount := cycle.Amount
+	var payment, underpaid *domain.Payment
+	for _, cyclePayment := range payments {
+		switch {
+		case isPaymentInFlight(cyclePayment):
+			payment = cyclePayment
+		case cyclePayment.IsUnderpaid():
+			amount = amount.Sub(cyclePayment.ReceivedPricingAmount())
+			underpaid = cyclePayment
+		}
+	}
+
+	if payment == nil && underpaid != nil && !amount.IsPositive() {
+		// The underpaid payments of the cycle add up to its amount
+		return s.markCyclePaid(ctx, cycle, underpaid)
+	}
+
+	if payment == nil {
+		description := fmt.Sprintf("%s (%s - %s)", plan.Name, cycle.PeriodStart.Format("2006-01-02"), cycle.PeriodEnd.Format("2006-01-02"))
+
+		payment, err = s.paymentService.CreatePayment(ctx, port.CreatePaymentRequest{
+			MerchantID:          subscription.MerchantID,
+			Currency:            plan.Currency.String,
+			Chain:               domain.Chain(plan.Chain.String),
+			OrderID:             fmt.Sprintf("%s-%d", subscription.ID, cycle.CycleNumber),
+			Description:         description,
+			PricingCurrency:     cycle.PricingCurrency,
+			PricingAmount:       amount,
+			SubscriptionCycleID: cycle.ID,
+		})
+		if err != nil {
+			return s.rescheduleAttempt(cycle, now, err)
+		}
+	}
+
+	cycle.Attempts++
+	cycle.PaymentID = sql.NullString{String: payment.ID, Valid: true}
+	cycle.NextAttemptAt = sql.NullTime{Time: payment.ExpiresAt, Valid: true}
+	if err := s.subscriptionRepo.UpdateCycle(cycle); err != nil {
+		return fmt.Errorf("failed to update subscription cycle: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"cycle_id":        cycle.ID,
+		"payment_id":      payment.ID,
+		"amount":          payment.PricingAmount.String(),
+		"attempt":         cycle.Attempts,
+	}).Info("Subscription cycle payment issued")
+
+	s.publishSubscriptionWebhook(ctx, domain.SubscriptionEventCycleIssued, subscription, cycle, payment)
+	s.sendPayerEmail(ctx, domain.SubscriptionEmailPaymentDue, subscription, cycle, payment)
+
+	return nil
+}
+
+// rescheduleAttempt retries a cycle whose payment could not be issued on a later run, the attempt is not counted
+func (s *SubscriptionService) rescheduleAttempt(cycle *domain.SubscriptionCycle, now time.Time, err error) error {
+	cycle.NextAttemptAt = sql.NullTime{Time: now.Add(SubscriptionRecheckInterval), Valid: true}
+	if updateErr := s.subscriptionRepo.UpdateCycle(cycle); updateErr != nil {
+		return fmt.Errorf("failed to create cycle payment: %v (reschedule failed: %w)", err, updateErr)
+	}
+	return fmt.Errorf("failed to create cycle payment: %w", err)
+}
+
+// failAttempt records an expired or failed cycle payment and schedules the next retry
+// A subscription whose grace period is over is canceled instead. What an underpaid payment
+// received is kept, the retry only asks for the rest.
+func (s *SubscriptionService) failAttempt(ctx context.Context, subscription *domain.Subscription, cycle *domain.SubscriptionCycle, payment *domain.Payment, now time.Time) error {
+	if cycle.IsGraceOver(now) {
+		return s.closeUnpaidCycle(ctx, subscription, cycle)
+	}
+
+	cycle.PaymentID = sql.NullString{}
+	nextAttemptAt, ok := s.dunningPolicy.NextRetryAt(cycle.DueAt, cycle.Attempts, now)
+	if !ok {
+		// No retry left, the subscription is canceled at the end of the grace period unless the payer pays
+		nextAttemptAt = cycle.GraceEndsAt
+	}
+	cycle.NextAttemptAt = sql.NullTime{Time: nextAttemptAt, Valid: true}
+	if err := s.subscriptionRepo.UpdateCycle(cycle); err != nil {
+		return fmt.Errorf("failed to update subscription cycle: %w", err)
+	}
+
+	becamePastDue := subscription.Status == domain.SubscriptionStatusActive
+	if becamePastDue {
+		subscription.Status = domain.SubscriptionStatusPastDue
+		if err := s.subscriptionRepo.Update(subscription); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"cycle_id":        cycle.ID,
+		"payment_id":      payment.ID,
+		"payment_status":  payment.Status,
+		"attempt":         cycle.Attempts,
+		"next_attempt_at": nextAttemptAt,
+		"retry_scheduled": ok,
+	}).Warn("Subscription cycle payment failed")
+
+	s.publishSubscriptionWebhook(ctx, domain.SubscriptionEventPaymentFailed, subscription, cycle, payment)
+	if becamePastDue {
+		s.publishSubscriptionWebhook(ctx, domain.SubscriptionEventPastDue, subscription, cycle, nil)
+	}
+	s.sendPayerEmail(ctx, domain.SubscriptionEmailPaymentFailed, subscription, cycle, nil)
+
+	return nil
+}
+
+// closeUnpaidCycle marks a cycle uncollectible and cancels its subscription
+func (s *SubscriptionService) closeUnpaidCycle(ctx context.Context, subscription *domain.Subscription, cycle *domain.SubscriptionCycle) error {
+	cycle.PaymentID = sql.NullString{}
+	cycle.Close(domain.SubscriptionCycleStatusUncollectible)
+	if err := s.subscriptionRepo.UpdateCycle(cycle); err != nil {
+		return fmt.Errorf("failed to close subscription cycle: %w", err)
+	}
+
+	return s.cancel(ctx, subscription, domain.SubscriptionCancelReasonUnpaid)
+}
+
+// markCyclePaid marks a cycle paid by a completed payment the payment service did not record
+func (s *SubscriptionService) markCyclePaid(ctx context.Context, cycle *domain.SubscriptionCycle, payment *domain.Payment) error {
+	cycle, subscription, paid, err := s.subscriptionRepo.MarkCyclePaid(cycle.ID, payment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark subscription cycle paid: %w", err)
+	}
+	if !paid {
+		return nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"cycle_id":        cycle.ID,
+		"payment_id":      payment.ID,
+	}).Info("Subscription cycle paid")
+
+	s.publishSubscriptionWebhook(ctx, domain.SubscriptionEventPaymentSucceeded, subscription, cycle, payment)
+	return nil
+}
+
+// cancel stops billing a subscription and notifies the merchant and the payer
+func (s *SubscriptionService) cancel(ctx context.Context, subscription *domain.Subscription, reason string) error {
+	subscription.Cancel(reason)
+	if err := s.subscriptionRepo.Update(subscription); err != nil {
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"merchant_id":     subscription.MerchantID,
+		"reason":          reason,
+	}).Info("Subscription canceled")
+
+	s.publishSubscriptionWebhook(ctx, domain.SubscriptionEventCanceled, subscription, nil, nil)
+	s.sendPayerEmail(ctx, domain.SubscriptionEmailCanceled, subscription, nil, nil)
+
+	return nil
+}
+
+// publishSubscriptionWebhook notifies the merchant of a subscription event (non-fatal)
+func (s *SubscriptionService) publishSubscriptionWebhook(ctx context.Context, event string, subscription *domain.Subscription, cycle *domain.SubscriptionCycle, payment *domain.Payment) {
+	if s.webhookPublisher == nil {
+		return
+	}
+
+	data := subscriptionWebhookData(subscription, cycle)
+	if payment != nil {
+		s.addPaymentData(data, payment)
+	}
+
+	if err := s.webhookPublisher.PublishWebhook(ctx, subscription.MerchantID, event, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"subscription_id": subscription.ID,
+			"event":           event,
+			"error":           err.Error(),
+		}).Warn("Failed to publish subscription webhook")
+	}
+}
+
+// sendPayerEmail emails the payer of a subscription (non-fatal), skipped without an email address
+func (s *SubscriptionService) sendPayerEmail(ctx context.Context, template string, subscription *domain.Subscription, cycle *domain.SubscriptionCycle, payment *domain.Payment) {
+	if s.emailSender == nil || !subscription.CustomerEmail.Valid {
+		return
+	}
+
+	data := subscriptionWebhookData(subscription, cycle)
+	if subscription.CustomerName.Valid {
+		data["customer_name"] = subscription.CustomerName.String
+	}
+	if payment != nil {
+		s.addPaymentData(data, payment)
+	}
+
+	if err := s.emailSender.SendEmail(ctx, template, subscription.CustomerEmail.String, data); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"subscription_id": subscription.ID,
+			"template":        template,
+			"error":           err.Error(),
+		}).Warn("Failed to send subscription email")
+	}
+}
+
+// addPaymentData adds the payment and its hosted page link to webhook or email data
+func (s *SubscriptionService) addPaymentData(data map[string]interface{}, payment *domain.Payment) {
+	data["payment_id"] = payment.ID
+	data["payment_url"] = fmt.Sprintf("%s/pay/%s", s.paymentPageBaseURL, payment.ID)
+	data["amount_crypto"] = payment.AmountCrypto.String()
+	data["currency"] = payment.Currency
+	data["chain"] = string(payment.Chain)
+	data["expires_at"] = payment.ExpiresAt.Format(time.RFC3339)
+}
+
+// subscriptionWebhookData returns the subscription and cycle fields sent with every subscription.* webhook
+func subscriptionWebhookData(subscription *domain.Subscription, cycle *domain.SubscriptionCycle) map[string]interface{} {
+	data := map[string]interface{}{
+		"subscription_id":      subscription.ID,
+		"plan_id":              subscription.PlanID,
+		"status":               string(subscription.Status),
+		"current_period_start": subscription.CurrentPeriodStart.Format(time.RFC3339),
+		"current_period_end":   subscription.CurrentPeriodEnd.Format(time.RFC3339),
+		"cancel_at_period_end": subscription.CancelAtPeriodEnd,
+	}
+	if subscription.CustomerReference.Valid {
+		data["customer_reference"] = subscription.CustomerReference.String
+	}
+	if subscription.CancellationReason.Valid {
+		data["cancellation_reason"] = subscription.CancellationReason.String
+	}
+
+	if cycle != nil {
+		data["cycle_id"] = cycle.ID
+		data["cycle_number"] = cycle.CycleNumber
+		data["cycle_status"] = string(cycle.Status)
+		data["pricing_currency"] = cycle.PricingCurrency
+		data["amount"] = cycle.Amount.String()
+		data["attempts"] = cycle.Attempts
+		data["grace_ends_at"] = cycle.GraceEndsAt.Format(time.RFC3339)
+		if cycle.NextAttemptAt.Valid {
+			data["next_attempt_at"] = cycle.NextAttemptAt.Time.Format(time.RFC3339)
+		}
+	}
+
+	return data
+}
+
+// isPaymentInFlight returns true if a cycle payment can still complete without a retry
+// Late payments wait for the merchant's late payment policy to resolve them
+func isPaymentInFlight(payment *domain.Payment) bool {
+	switch payment.Status {
+	case domain.PaymentStatusConfirming, domain.PaymentStatusPendingCompliance, domain.PaymentStatusLatePaid:
+		return true
+	case domain.PaymentStatusCreated, domain.PaymentStatusPending, domain.PaymentStatusUnderpaid:
+		return !payment.IsExpired()
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/port"
+)
+
+// stubCyclePaymentService issues cycle payments and lists the payments of the cycle
+type stubCyclePaymentService struct {
+	port.PaymentService
+	cyclePayments []*domain.Payment
+	created       []port.CreatePaymentRequest
+}
+
+func (s *stubCyclePaymentService) ListSubscriptionCyclePayments(ctx context.Context, cycleID string) ([]*domain.Payment, error) {
+	return s.cyclePayments, nil
+}
+
+func (s *stubCyclePaymentService) CreatePayment(ctx context.Context, req port.CreatePaymentRequest) (*domain.Payment, error) {
+	s.created = append(s.created, req)
+	return &domain.Payment{
+		ID:            "payment-2",
+		Status:        domain.PaymentStatusCreated,
+		PricingAmount: req.PricingAmount,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}, nil
+}
+
+// memSubscriptionRepository keeps the last saved cycle
+type memSubscriptionRepository struct {
+	domain.SubscriptionRepository
+	cycle *domain.SubscriptionCycle
+}
+
+func (r *memSubscriptionRepository) UpdateCycle(cycle *domain.SubscriptionCycle) error {
+	stored := *cycle
+	r.cycle = &stored
+	return nil
+}
+
+func newTestCycle() (*domain.Subscription, *domain.SubscriptionPlan, *domain.SubscriptionCycle) {
+	now := time.Now()
+	subscription := &domain.Subscription{ID: "subscription-1", MerchantID: "merchant-1"}
+	plan := &domain.SubscriptionPlan{
+		Name:     "Pro",
+		Currency: sql.NullString{String: "USDT", Valid: true},
+		Chain:    sql.NullString{String: string(domain.ChainSolana), Valid: true},
+	}
+	cycle := &domain.SubscriptionCycle{
+		ID:              "cycle-1",
+		SubscriptionID:  subscription.ID,
+		CycleNumber:     1,
+		PeriodStart:     now,
+		PeriodEnd:       now.AddDate(0, 1, 0),
+		PricingCurrency: domain.PricingCurrencyVND,
+		Amount:          decimal.NewFromInt(2500000),
+		Status:          domain.SubscriptionCycleStatusOpen,
+		DueAt:           now,
+		GraceEndsAt:     now.Add(7 * 24 * time.Hour),
+	}
+	return subscription, plan, cycle
+}
+
+func TestAttemptCycle_ReusesPaymentOfUnrecordedAttempt(t *testing.T) {
+	subscription, plan, cycle := newTestCycle()
+	// Created by an attempt whose cycle update failed
+	issued := &domain.Payment{
+		ID:                  "payment-1",
+		Status:              domain.PaymentStatusPending,
+		ExpiresAt:           time.Now().Add(time.Hour),
+		SubscriptionCycleID: sql.NullString{String: cycle.ID, Valid: true},
+	}
+	payments := &stubCyclePaymentService{cyclePayments: []*domain.Payment{issued}}
+	cycles := &memSubscriptionRepository{}
+	service := NewSubscriptionService(payments, nil, cycles, SubscriptionServiceConfig{}, newTestLogger())
+
+	require.NoError(t, service.attemptCycle(context.Background(), subscription, plan, cycle, time.Now()))
+
+	assert.Empty(t, payments.created)
+	assert.Equal(t, "payment-1", cycles.cycle.PaymentID.String)
+	assert.Equal(t, 1, cycles.cycle.Attempts)
+}
+
+func TestAttemptCycle_AsksForRestOfUnderpaidPayment(t *testing.T) {
+	subscription, plan, cycle := newTestCycle()
+	cycle.Attempts = 1
+	underpaid := &domain.Payment{
+		ID:                  "payment-1",
+		Status:              domain.PaymentStatusUnderpaid,
+		PricingAmount:       decimal.NewFromInt(2500000),
+		AmountCrypto:        decimal.NewFromInt(100),
+		AmountReceived:      decimal.NewFromInt(60),
+		ExpiresAt:           time.Now().Add(-time.Hour),
+		SubscriptionCycleID: sql.NullString{String: cycle.ID, Valid: true},
+	}
+	payments := &stubCyclePaymentService{cyclePayments: []*domain.Payment{underpaid}}
+	cycles := &memSubscriptionRepository{}
+	service := NewSubscriptionService(payments, nil, cycles, SubscriptionServiceConfig{}, newTestLogger())
+
+	require.NoError(t, service.attemptCycle(context.Background(), subscription, plan, cycle, time.Now()))
+
+	require.Len(t, payments.created, 1)
+	assert.True(t, payments.created[0].PricingAmount.Equal(decimal.NewFromInt(1000000)))
+	assert.Equal(t, "payment-2", cycles.cycle.PaymentID.String)
+	assert.Equal(t, 2, cycles.cycle.Attempts)
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+// DefaultTokenCacheTTL is how long the registry serves cached tokens before reloading them
+const DefaultTokenCacheTTL = time.Minute
+
+// TokenRegistry serves the tokens accepted on each chain from the token repository
+// Tokens are cached, so admin changes made through another process apply within the cache TTL
+type TokenRegistry struct {
+	repo   domain.TokenRepository
+	ttl    time.Duration
+	logger *logrus.Logger
+
+	mu       sync.RWMutex
+	tokens   []domain.Token // Every token ordered by chain and symbol, disabled ones included
+	loadedAt time.Time
+}
+
+// TokenUpdate lists the registry fields admins can change, nil fields are left as they are
+type TokenUpdate struct {
+	Address     *string
+	Decimals    *int
+	PegCurrency *string
+	PriceSource *domain.PriceSource
+	Enabled     *bool
+	MinAmount   *decimal.Decimal
+	MaxAmount   *decimal.Decimal
+}
+
+// NewTokenRegistry creates a token registry, ttl defaults to DefaultTokenCacheTTL
+func NewTokenRegistry(repo domain.TokenRepository, ttl time.Duration, logger *logrus.Logger) *TokenRegistry {
+	if ttl <= 0 {
+		ttl = DefaultTokenCacheTTL
+	}
+
+	return &TokenRegistry{
+		repo:   repo,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Lookup returns the enabled token of symbol on chain
+// Returns ErrInvalidChain if the chain does not accept the token
+func (r *TokenRegistry) Lookup(chain domain.Chain, symbol string) (*domain.Token, error) {
+	token, err := r.Find(chain, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Enabled {
+		return nil, fmt.Errorf("%w: %s on %s", domain.ErrInvalidChain, symbol, chain)
+	}
+
+	return token, nil
+}
+
+// Find returns the token of symbol on chain, disabled or not
+// Payments created before a token was disabled are still priced and settled with it
+func (r *TokenRegistry) Find(chain domain.Chain, symbol string) (*domain.Token, error) {
+	tokens, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		if token.Chain == chain && token.Symbol == symbol {
+			found := token
+			return &found, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s on %s", domain.ErrInvalidChain, symbol, chain)
+}
+
+// EnabledTokens returns the enabled tokens of chain ordered by symbol, or of every chain if chain is empty
+func (r *TokenRegistry) EnabledTokens(chain domain.Chain) ([]domain.Token, error) {
+	tokens, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var enabled []domain.Token
+	for _, token := range tokens {
+		if token.Enabled && (chain == "" || token.Chain == chain) {
+			enabled = append(enabled, token)
+		}
+	}
+
+	return enabled, nil
+}
+
+// ListTokens returns every token of the registry, disabled ones included
+func (r *TokenRegistry) ListTokens() ([]*domain.Token, error) {
+	tokens, err := r.repo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// CreateToken adds a token to the registry
+// The peg currency defaults to USD and the price source to DefaultPriceSource
+func (r *TokenRegistry) CreateToken(token *domain.Token) error {
+	token.Symbol = strings.ToUpper(strings.TrimSpace(token.Symbol))
+	token.Address = strings.TrimSpace(token.Address)
+	if token.PegCurrency == "" {
+		token.PegCurrency = "USD"
+	}
+	if token.PriceSource == "" {
+		token.PriceSource = domain.DefaultPriceSource(token.Symbol)
+	}
+
+	if err := token.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.repo.Create(token); err != nil {
+		return err
+	}
+	r.invalidate()
+
+	r.logger.WithFields(logrus.Fields{
+		"token_id": token.ID,
+		"chain":    token.Chain,
+		"symbol":   token.Symbol,
+		"enabled":  token.Enabled,
+	}).Info("Token added to registry")
+
+	return nil
+}
+
+// UpdateToken applies an admin update to a token of the registry
+func (r *TokenRegistry) UpdateToken(id string, update TokenUpdate) (*domain.Token, error) {
+	token, err := r.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Address != nil {
+		token.Address = strings.TrimSpace(*update.Address)
+	}
+	if update.Decimals != nil {
+		token.Decimals = *update.Decimals
+	}
+	if update.PegCurrency != nil {
+		token.PegCurrency = *update.PegCurrency
+	}
+	if update.PriceSource != nil {
+		token.PriceSource = *update.PriceSource
+	}
+	if update.Enabled != nil {
+		token.Enabled = *update.Enabled
+	}
+	if update.MinAmount != nil {
+		token.MinAmount = *update.MinAmount
+	}
+	if update.MaxAmount != nil {
+		token.MaxAmount = *update.MaxAmount
+	}
+
+	if err := token.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := r.repo.Update(token); err != nil {
+		return nil, fmt.Errorf("failed to update token: %w", err)
+	}
+	r.invalidate()
+
+	r.logger.WithFields(logrus.Fields{
+		"token_id": token.ID,
+		"chain":    token.Chain,
+		"symbol":   token.Symbol,
+		"enabled":  token.Enabled,
+	}).Info("Token registry entry updated")
+
+	return token, nil
+}
+
+// Bootstrap adds the tokens of the deployment configuration missing from the registry and fills
+// in the addresses left empty. Tokens already in the registry otherwise keep what admins set,
+// so a disabled token stays disabled.
+func (r *TokenRegistry) Bootstrap(configured []domain.Token) error {
+	existing, err := r.repo.List()
+	if err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	byKey := make(map[string]*domain.Token, len(existing))
+	for _, token := range existing {
+		byKey[string(token.Chain)+"/"+token.Symbol] = token
+	}
+
+	for _, token := range configured {
+		if token.Address == "" {
+			continue
+		}
+
+		current, ok := byKey[string(token.Chain)+"/"+token.Symbol]
+		if !ok {
+			token.Enabled = true
+			if err := r.CreateToken(&token); err != nil {
+				return fmt.Errorf("failed to add %s on %s: %w", token.Symbol, token.Chain, err)
+			}
+			continue
+		}
+
+		if current.Address == "" {
+			current.Address = token.Address
+			if err := r.repo.Update(current); err != nil {
+				return fmt.Errorf("failed to set address of %s on %s: %w", token.Symbol, token.Chain, err)
+			}
+			r.logger.WithFields(logrus.Fields{
+				"chain":   current.Chain,
+				"symbol":  current.Symbol,
+				"address": current.Address,
+			}).Info("Token address filled from configuration")
+		}
+	}
+
+	r.invalidate()
+	return nil
+}
+
+// load returns the cached tokens, reloading them once the TTL has passed
+// A failed reload keeps serving the previous tokens
+func (r *TokenRegistry) load() ([]domain.Token, error) {
+	r.mu.RLock()
+	if r.tokens != nil && time.Since(r.loadedAt) < r.ttl {
+		tokens := r.tokens
+		r.mu.RUnlock()
+		return tokens, nil
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tokens != nil && time.Since(r.loadedAt) < r.ttl {
+		return r.tokens, nil
+	}
+
+	stored, err := r.repo.List()
+	if err != nil {
+		if r.tokens != nil {
+			r.logger.WithError(err).Warn("Failed to reload token registry, serving cached tokens")
+			return r.tokens, nil
+		}
+		return nil, fmt.Errorf("failed to load token registry: %w", err)
+	}
+
+	tokens := make([]domain.Token, 0, len(stored))
+	for _, token := range stored {
+		tokens = append(tokens, *token)
+	}
+	r.tokens = tokens
+	r.loadedAt = time.Now()
+
+	return tokens, nil
+}
+
+// invalidate makes the next lookup reload the tokens
+func (r *TokenRegistry) invalidate() {
+	r.mu.Lock()
+	r.tokens = nil
+	r.mu.Unlock()
+}
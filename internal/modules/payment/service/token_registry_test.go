@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
+)
+
+func newTokenRegistryRepository() *memTokenRepository {
+	return &memTokenRepository{tokens: []*domain.Token{
+		{ID: "token-1", Chain: domain.ChainSolana, Symbol: "USDT", Address: "usdt-mint", Decimals: 6, PegCurrency: "USD", PriceSource: domain.PriceSourceUSDT, Enabled: true},
+	}}
+}
+
+func TestTokenRegistry_ServesCachedTokensWithinTTL(t *testing.T) {
+	repo := newTokenRegistryRepository()
+	registry := NewTokenRegistry(repo, time.Hour, newTestLogger())
+
+	_, err := registry.Lookup(domain.ChainSolana, "USDT")
+	require.NoError(t, err)
+	tokens, err := registry.EnabledTokens(domain.ChainSolana)
+	require.NoError(t, err)
+
+	assert.Len(t, tokens, 1)
+	assert.Equal(t, 1, repo.lists)
+}
+
+func TestTokenRegistry_ReloadsOnceTTLHasPassed(t *testing.T) {
+	repo := newTokenRegistryRepository()
+	registry := NewTokenRegistry(repo, time.Nanosecond, newTestLogger())
+
+	_, err := registry.Lookup(domain.ChainSolana, "USDT")
+	require.NoError(t, err)
+
+	// Another process disables the token
+	repo.tokens[0].Enabled = false
+
+	_, err = registry.Lookup(domain.ChainSolana, "USDT")
+	assert.ErrorIs(t, err, domain.ErrInvalidChain)
+	assert.Equal(t, 2, repo.lists)
+}
+
+func TestTokenRegistry_ChangesInvalidateCache(t *testing.T) {
+	repo := newTokenRegistryRepository()
+	registry := NewTokenRegistry(repo, time.Hour, newTestLogger())
+	_, err := registry.Lookup(domain.ChainSolana, "USDT")
+	require.NoError(t, err)
+
+	disabled := false
+	_, err = registry.UpdateToken("token-1", TokenUpdate{Enabled: &disabled})
+	require.NoError(t, err)
+
+	_, err = registry.Lookup(domain.ChainSolana, "USDT")
+	assert.ErrorIs(t, err, domain.ErrInvalidChain)
+
+	require.NoError(t, registry.CreateToken(&domain.Token{ID: "token-2", Chain: domain.ChainSolana, Symbol: "usdc", Decimals: 6, Enabled: true}))
+
+	token, err := registry.Lookup(domain.ChainSolana, "USDC")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PriceSourceUSDC, token.PriceSource)
+
+	require.NoError(t, registry.Bootstrap([]domain.Token{
+		{Chain: domain.ChainSolana, Symbol: "USDC", Address: "usdc-mint", Decimals: 6, PegCurrency: "USD", PriceSource: domain.PriceSourceUSDC},
+	}))
+
+	token, err = registry.Lookup(domain.ChainSolana, "USDC")
+	require.NoError(t, err)
+	assert.Equal(t, "usdc-mint", token.Address)
+}
+
+func TestTokenRegistry_ServesCachedTokensWhenReloadFails(t *testing.T) {
+	repo := newTokenRegistryRepository()
+	registry := NewTokenRegistry(repo, time.Nanosecond, newTestLogger())
+	_, err := registry.Lookup(domain.ChainSolana, "USDT")
+	require.NoError(t, err)
+
+	repo.listErr = errors.New("connection refused")
+
+	token, err := registry.Lookup(domain.ChainSolana, "USDT")
+	require.NoError(t, err)
+	assert.Equal(t, "usdt-mint", token.Address)
+	assert.Equal(t, 2, repo.lists)
+
+	// Without cached tokens there is nothing to fall back on
+	_, err = NewTokenRegistry(repo, 0, newTestLogger()).Lookup(domain.ChainSolana, "USDT")
+	assert.Error(t, err)
+}
@@ -20,6 +20,16 @@ const (
 	PayoutStatusFailed     PayoutStatus = "failed"
 )
 
+// IsValid returns true if the status is a known payout status
+func (s PayoutStatus) IsValid() bool {
+	switch s {
+	case PayoutStatusRequested, PayoutStatusApproved, PayoutStatusProcessing,
+		PayoutStatusCompleted, PayoutStatusRejected, PayoutStatusFailed:
+		return true
+	}
+	return false
+}
+
 // Payout represents a merchant withdrawal request
 type Payout struct {
 	ID         string `json:"id" db:"id"`
@@ -39,6 +49,9 @@ type Payout struct {
 	// Status
 	Status PayoutStatus `json:"status" db:"status" validate:"required,oneof=requested approved processing completed rejected failed"`
 
+	// Payout of a sandbox merchant, completed at once without a bank transfer
+	TestMode bool `json:"test_mode" db:"test_mode"`
+
 	// Approval workflow
 	RequestedBy     string         `json:"requested_by" db:"requested_by" validate:"required,uuid"`
 	ApprovedBy      sql.NullString `json:"approved_by,omitempty" db:"approved_by"`
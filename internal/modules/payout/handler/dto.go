@@ -29,6 +29,7 @@ type RequestPayoutResponse struct {
 	BankName          string          `json:"bank_name"`
 	BankBranch        *string         `json:"bank_branch,omitempty"`
 	Status            string          `json:"status"`
+	TestMode          bool            `json:"test_mode"`
 	Notes             *string         `json:"notes,omitempty"`
 	CreatedAt         time.Time       `json:"created_at"`
 }
@@ -45,6 +46,7 @@ type GetPayoutResponse struct {
 	BankName          string          `json:"bank_name"`
 	BankBranch        *string         `json:"bank_branch,omitempty"`
 	Status            string          `json:"status"`
+	TestMode          bool            `json:"test_mode"` // Sandbox payout, never sent to a bank
 
 	// Approval information
 	RequestedBy     string     `json:"requested_by"`
@@ -111,6 +113,7 @@ func PayoutToResponse(payout *payoutDomain.Payout) GetPayoutResponse {
 		BankAccountNumber: payout.BankAccountNumber,
 		BankName:          payout.BankName,
 		Status:            string(payout.Status),
+		TestMode:          payout.TestMode,
 		RequestedBy:       payout.RequestedBy,
 		RetryCount:        payout.RetryCount,
 		CreatedAt:         payout.CreatedAt,
@@ -88,6 +88,7 @@ func (h *PayoutHandler) RequestPayout(c *gin.Context) {
 		BankName:          req.BankName,
 		BankBranch:        req.BankBranch,
 		Notes:             req.Notes,
+		TestMode:          merchant.IsSandbox(),
 	}
 
 	// Request payout
@@ -309,6 +310,7 @@ func buildPayoutResponse(payout *payoutDomain.Payout) RequestPayoutResponse {
 		BankAccountNumber: payout.BankAccountNumber,
 		BankName:          payout.BankName,
 		Status:            string(payout.Status),
+		TestMode:          payout.TestMode,
 		CreatedAt:         payout.CreatedAt,
 	}
 
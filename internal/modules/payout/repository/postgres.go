@@ -81,7 +81,34 @@ func (r *PayoutRepository) ListByMerchant(merchantID string, limit, offset int)
 	return payouts, nil
 }
 
+// ListByMerchantCreatedBetween retrieves payouts of a merchant created in [from, to), oldest first
+// Statuses restricts the payouts to the given statuses when not empty
+func (r *PayoutRepository) ListByMerchantCreatedBetween(merchantID string, from, to time.Time, statuses []payoutDomain.PayoutStatus, limit, offset int) ([]*payoutDomain.Payout, error) {
+	if merchantID == "" {
+		return nil, errors.New("merchant ID cannot be empty")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := r.gormDB.Where("merchant_id = ? AND created_at >= ? AND created_at < ?", merchantID, from, to)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	payouts := make([]*payoutDomain.Payout, 0)
+	if err := query.Order("created_at ASC").Order("id ASC").Offset(offset).Limit(limit).Find(&payouts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list payouts by merchant and date range: %w", err)
+	}
+
+	return payouts, nil
+}
+
 // ListByStatus retrieves payouts by status with pagination
+// Test payouts are left out, they never wait on operators
 func (r *PayoutRepository) ListByStatus(status payoutDomain.PayoutStatus, limit, offset int) ([]*payoutDomain.Payout, error) {
 	if status == "" {
 		return nil, ErrInvalidPayoutStatus
@@ -94,7 +121,7 @@ func (r *PayoutRepository) ListByStatus(status payoutDomain.PayoutStatus, limit,
 	}
 
 	payouts := make([]*payoutDomain.Payout, 0)
-	if err := r.gormDB.Where("status = ?", status).Offset(offset).Limit(limit).Find(&payouts).Error; err != nil {
+	if err := r.gormDB.Where("status = ? AND test_mode = FALSE", status).Offset(offset).Limit(limit).Find(&payouts).Error; err != nil {
 		return nil, fmt.Errorf("failed to list payouts by status: %w", err)
 	}
 
@@ -241,14 +268,14 @@ func (r *PayoutRepository) CountByMerchant(merchantID string) (int64, error) {
 	return count, nil
 }
 
-// CountByStatus returns the count of payouts by status
+// CountByStatus returns the count of live payouts by status
 func (r *PayoutRepository) CountByStatus(status payoutDomain.PayoutStatus) (int64, error) {
 	if status == "" {
 		return 0, ErrInvalidPayoutStatus
 	}
 
 	var count int64
-	if err := r.gormDB.Model(&payoutDomain.Payout{}).Where("status = ? AND deleted_at IS NULL", status).Count(&count).Error; err != nil {
+	if err := r.gormDB.Model(&payoutDomain.Payout{}).Where("status = ? AND test_mode = FALSE AND deleted_at IS NULL", status).Count(&count).Error; err != nil {
 		return 0, fmt.Errorf("failed to count payouts by status: %w", err)
 	}
 
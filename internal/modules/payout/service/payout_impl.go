@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -71,6 +72,7 @@ type RequestPayoutInput struct {
 	BankName          string
 	BankBranch        string
 	Notes             string
+	TestMode          bool // Sandbox merchant: the payout is completed at once and never sent to a bank
 }
 
 // Validate validates the payout request input
@@ -155,6 +157,16 @@ func (s *PayoutService) RequestPayout(input RequestPayoutInput) (*payoutDomain.P
 		UpdatedAt:         time.Now(),
 	}
 
+	// Test payouts skip the approval queue, nothing is transferred
+	if input.TestMode {
+		now := time.Now()
+		payout.TestMode = true
+		payout.Status = payoutDomain.PayoutStatusCompleted
+		payout.ProcessedAt = sql.NullTime{Time: now, Valid: true}
+		payout.CompletionDate = sql.NullTime{Time: now, Valid: true}
+		payout.BankReferenceNumber = sql.NullString{String: "SANDBOX-" + strings.ToUpper(payout.ID[:8]), Valid: true}
+	}
+
 	// Save payout to database
 	if err := s.payoutRepo.Create(payout); err != nil {
 		return nil, fmt.Errorf("failed to create payout: %w", err)
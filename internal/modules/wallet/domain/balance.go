@@ -24,7 +24,7 @@ type WalletBalanceSnapshot struct {
 	ID string `json:"id" db:"id"`
 
 	// Blockchain details
-	Chain   paymentDomain.Chain `json:"chain" db:"chain" validate:"required,oneof=solana bsc ethereum"`
+	Chain   paymentDomain.Chain `json:"chain" db:"chain" validate:"required,oneof=solana bsc ethereum tron"`
 	Network Network             `json:"network" db:"network" validate:"required,oneof=mainnet testnet devnet"`
 
 	// Wallet information
@@ -0,0 +1,214 @@
+package hdwallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mr-tron/base58"
+)
+
+// HardenedOffset is the first hardened child index (BIP-32)
+const HardenedOffset uint32 = 0x80000000
+
+// serializedKeyLength is the length of a decoded extended key including its checksum
+const serializedKeyLength = 82
+
+var (
+	// ErrInvalidExtendedKey is returned when an xpub/xprv string cannot be decoded
+	ErrInvalidExtendedKey = errors.New("invalid extended key")
+	// ErrHardenedFromPublic is returned when a hardened child is requested from a public key
+	ErrHardenedFromPublic = errors.New("cannot derive hardened child from public key")
+	// ErrInvalidChild is returned for the (astronomically unlikely) invalid child index
+	ErrInvalidChild = errors.New("invalid child key, use the next index")
+)
+
+// Extended key version bytes
+var (
+	versionMainnetPublic  = []byte{0x04, 0x88, 0xb2, 0x1e} // xpub
+	versionMainnetPrivate = []byte{0x04, 0x88, 0xad, 0xe4} // xprv
+	versionTestnetPublic  = []byte{0x04, 0x35, 0x87, 0xcf} // tpub
+	versionTestnetPrivate = []byte{0x04, 0x35, 0x83, 0x94} // tprv
+)
+
+// ExtendedKey is a BIP-32 hierarchical deterministic key on secp256k1
+type ExtendedKey struct {
+	key       []byte // 33-byte compressed public key, or 32-byte private key
+	chainCode []byte
+	depth     uint8
+	index     uint32
+	isPrivate bool
+}
+
+// ParseExtendedKey decodes a base58 xpub, xprv, tpub or tprv string
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	data, err := base58.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+	}
+	if len(data) != serializedKeyLength {
+		return nil, fmt.Errorf("%w: unexpected length %d", ErrInvalidExtendedKey, len(data))
+	}
+
+	payload, checksum := data[:78], data[78:]
+	if !bytes.Equal(doubleSHA256(payload)[:4], checksum) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidExtendedKey)
+	}
+
+	version := payload[:4]
+	var isPrivate bool
+	switch {
+	case bytes.Equal(version, versionMainnetPublic), bytes.Equal(version, versionTestnetPublic):
+		isPrivate = false
+	case bytes.Equal(version, versionMainnetPrivate), bytes.Equal(version, versionTestnetPrivate):
+		isPrivate = true
+	default:
+		return nil, fmt.Errorf("%w: unknown version %x", ErrInvalidExtendedKey, version)
+	}
+
+	keyData := payload[45:78]
+	var key []byte
+	if isPrivate {
+		if keyData[0] != 0x00 {
+			return nil, fmt.Errorf("%w: malformed private key", ErrInvalidExtendedKey)
+		}
+		key = append([]byte{}, keyData[1:]...)
+	} else {
+		if _, err := crypto.DecompressPubkey(keyData); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+		}
+		key = append([]byte{}, keyData...)
+	}
+
+	return &ExtendedKey{
+		key:       key,
+		chainCode: append([]byte{}, payload[13:45]...),
+		depth:     payload[4],
+		index:     binary.BigEndian.Uint32(payload[9:13]),
+		isPrivate: isPrivate,
+	}, nil
+}
+
+// IsPrivate returns true if the key can derive private keys
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.isPrivate
+}
+
+// Depth returns the depth of the key in the derivation tree
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// Child derives the child key at the given index
+// Hardened indexes (>= HardenedOffset) require a private key.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	curve := crypto.S256()
+	curveOrder := curve.Params().N
+
+	var data []byte
+	if index >= HardenedOffset {
+		if !k.isPrivate {
+			return nil, ErrHardenedFromPublic
+		}
+		data = append([]byte{0x00}, k.key...)
+	} else {
+		data = append([]byte{}, k.compressedPublicKey()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(curveOrder) >= 0 {
+		return nil, ErrInvalidChild
+	}
+
+	child := &ExtendedKey{
+		chainCode: sum[32:],
+		depth:     k.depth + 1,
+		index:     index,
+		isPrivate: k.isPrivate,
+	}
+
+	if k.isPrivate {
+		childKey := new(big.Int).Add(tweak, new(big.Int).SetBytes(k.key))
+		childKey.Mod(childKey, curveOrder)
+		if childKey.Sign() == 0 {
+			return nil, ErrInvalidChild
+		}
+		child.key = childKey.FillBytes(make([]byte, 32))
+		return child, nil
+	}
+
+	parent, err := crypto.DecompressPubkey(k.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress parent key: %w", err)
+	}
+	tx, ty := curve.ScalarBaseMult(sum[:32])
+	x, y := curve.Add(tx, ty, parent.X, parent.Y)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, ErrInvalidChild
+	}
+	child.key = crypto.CompressPubkey(&ecdsa.PublicKey{Curve: curve, X: x, Y: y})
+	return child, nil
+}
+
+// Derive walks a sequence of child indexes starting from this key
+func (k *ExtendedKey) Derive(path ...uint32) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		child, err := key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// PublicKey returns the ECDSA public key
+func (k *ExtendedKey) PublicKey() (*ecdsa.PublicKey, error) {
+	return crypto.DecompressPubkey(k.compressedPublicKey())
+}
+
+// PrivateKey returns the ECDSA private key, only available on private extended keys
+func (k *ExtendedKey) PrivateKey() (*ecdsa.PrivateKey, error) {
+	if !k.isPrivate {
+		return nil, errors.New("extended key is public")
+	}
+	return crypto.ToECDSA(k.key)
+}
+
+// Address returns the EVM address of the key
+func (k *ExtendedKey) Address() (common.Address, error) {
+	pub, err := k.PublicKey()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// compressedPublicKey returns the 33-byte compressed public key
+func (k *ExtendedKey) compressedPublicKey() []byte {
+	if !k.isPrivate {
+		return k.key
+	}
+	x, y := crypto.S256().ScalarBaseMult(k.key)
+	return crypto.CompressPubkey(&ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y})
+}
+
+func doubleSHA256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
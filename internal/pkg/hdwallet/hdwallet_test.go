@@ -0,0 +1,80 @@
+package hdwallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// BIP-32 test vector 1 (seed 000102030405060708090a0b0c0d0e0f)
+const (
+	vectorMasterPrivate = "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+	vector0H            = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+	vector0H1           = "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ"
+	vector0H12H2        = "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV"
+)
+
+func mustParse(t *testing.T, s string) *ExtendedKey {
+	t.Helper()
+	key, err := ParseExtendedKey(s)
+	require.NoError(t, err)
+	return key
+}
+
+func TestParseExtendedKey(t *testing.T) {
+	master := mustParse(t, vectorMasterPrivate)
+	assert.True(t, master.IsPrivate())
+	assert.Equal(t, uint8(0), master.Depth())
+
+	pub := mustParse(t, vector0H)
+	assert.False(t, pub.IsPrivate())
+	assert.Equal(t, uint8(1), pub.Depth())
+
+	_, err := ParseExtendedKey("xpub-not-a-key")
+	assert.ErrorIs(t, err, ErrInvalidExtendedKey)
+
+	// Flip the last character to break the checksum
+	broken := vector0H[:len(vector0H)-1] + "x"
+	_, err = ParseExtendedKey(broken)
+	assert.ErrorIs(t, err, ErrInvalidExtendedKey)
+}
+
+func TestChild_PrivateDerivationMatchesVectors(t *testing.T) {
+	master := mustParse(t, vectorMasterPrivate)
+
+	key0H, err := master.Child(HardenedOffset)
+	require.NoError(t, err)
+	assertSameAddress(t, mustParse(t, vector0H), key0H)
+
+	key0H12H2, err := master.Derive(HardenedOffset, 1, HardenedOffset+2, 2)
+	require.NoError(t, err)
+	assertSameAddress(t, mustParse(t, vector0H12H2), key0H12H2)
+	assert.Equal(t, uint8(4), key0H12H2.Depth())
+
+	_, err = key0H12H2.PrivateKey()
+	assert.NoError(t, err)
+}
+
+func TestChild_PublicDerivationMatchesPrivate(t *testing.T) {
+	xpub := mustParse(t, vector0H)
+
+	child, err := xpub.Child(1)
+	require.NoError(t, err)
+	assertSameAddress(t, mustParse(t, vector0H1), child)
+
+	_, err = xpub.Child(HardenedOffset)
+	assert.ErrorIs(t, err, ErrHardenedFromPublic)
+
+	_, err = xpub.PrivateKey()
+	assert.Error(t, err)
+}
+
+func assertSameAddress(t *testing.T, expected, actual *ExtendedKey) {
+	t.Helper()
+	expectedAddress, err := expected.Address()
+	require.NoError(t, err)
+	actualAddress, err := actual.Address()
+	require.NoError(t, err)
+	assert.Equal(t, expectedAddress, actualAddress)
+}
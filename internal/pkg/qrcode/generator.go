@@ -3,8 +3,10 @@ package qrcode
 import (
 	"encoding/base64"
 	"fmt"
+	"image"
 	"net/url"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 	"github.com/skip2/go-qrcode"
 )
@@ -31,18 +33,30 @@ const (
 
 // PaymentQRConfig holds configuration for payment QR code generation
 type PaymentQRConfig struct {
-	// WalletAddress is the recipient's Solana wallet address
+	// Chain selects the payment URI: "solana" (default) for Solana Pay, "tron" for a TRON URI,
+	// any chain with a ChainID for an EIP-681 transfer and a plain address otherwise or when a Memo is set
+	Chain string
+	// ChainID is the EVM chain ID of the network, e.g. 56 for BSC
+	ChainID int64
+	// WalletAddress is the recipient's wallet address
 	WalletAddress string
 	// Amount is the payment amount in token units
 	Amount decimal.Decimal
-	// TokenMint is the SPL token mint address (USDT or USDC)
+	// TokenMint is the SPL token mint address on Solana and the token contract on TRON and EVM chains
 	TokenMint string
-	// Memo is the payment reference/ID
+	// TokenDecimals converts Amount to base units for EIP-681 transfers
+	TokenDecimals int
+	// Memo is the payment reference/ID, required by Solana Pay
+	// Leave it empty on other chains when the recipient address only receives this payment
 	Memo string
 	// Label is an optional label for the payment
 	Label string
 	// Size is the QR code size in pixels
 	Size QRCodeSize
+	// Format is the image format, PNG by default
+	Format QRCodeFormat
+	// Logo is an optional merchant logo drawn in the center of the QR code
+	Logo image.Image
 }
 
 // Generator handles QR code generation
@@ -57,12 +71,36 @@ func NewGenerator() *Generator {
 	}
 }
 
-// GeneratePaymentQR generates a Solana Pay QR code for a payment
-// Returns a base64-encoded PNG image
+// GeneratePaymentQR generates a payment QR code in the URI format of the chain
+// Returns the base64-encoded image, a PNG unless another format is configured
 func (g *Generator) GeneratePaymentQR(config PaymentQRConfig) (string, error) {
+	imageBytes, err := g.generateImage(config)
+	if err != nil {
+		return "", err
+	}
+
+	// Encode to base64
+	base64String := base64.StdEncoding.EncodeToString(imageBytes)
+
+	return base64String, nil
+}
+
+// GeneratePaymentQRDataURL generates a payment QR code as a data URL
+// The result can be used directly as the src of an <img> element
+func (g *Generator) GeneratePaymentQRDataURL(config PaymentQRConfig) (string, error) {
+	base64String, err := g.GeneratePaymentQR(config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", config.Format.MIMEType(), base64String), nil
+}
+
+// generateImage encodes the payment URI of config as a QR code image
+func (g *Generator) generateImage(config PaymentQRConfig) ([]byte, error) {
 	// Validate inputs
 	if err := g.validateConfig(config); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Use default size if not specified
@@ -71,28 +109,35 @@ func (g *Generator) GeneratePaymentQR(config PaymentQRConfig) (string, error) {
 		size = g.defaultSize
 	}
 
-	// Build Solana Pay URL
-	solanaPayURL, err := g.buildSolanaPayURL(config)
+	// Build the payment URI of the chain
+	paymentURI, err := g.buildPaymentURI(config)
 	if err != nil {
-		return "", fmt.Errorf("failed to build Solana Pay URL: %w", err)
+		return nil, fmt.Errorf("failed to build payment URI: %w", err)
+	}
+
+	// A logo hides part of the code, the highest error correction keeps it readable
+	recoveryLevel := qrcode.Medium
+	if config.Logo != nil {
+		recoveryLevel = qrcode.Highest
 	}
 
 	// Generate QR code
-	qrCode, err := qrcode.New(solanaPayURL, qrcode.Medium)
+	qrCode, err := qrcode.New(paymentURI, recoveryLevel)
 	if err != nil {
-		return "", fmt.Errorf("failed to create QR code: %w", err)
+		return nil, fmt.Errorf("failed to create QR code: %w", err)
+	}
+
+	if config.Format == QRCodeFormatSVG {
+		return renderSVG(qrCode, int(size), config.Logo)
 	}
 
 	// Generate PNG bytes
-	pngBytes, err := qrCode.PNG(int(size))
+	pngBytes, err := renderPNG(qrCode, int(size), config.Logo)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate PNG: %w", err)
+		return nil, fmt.Errorf("failed to generate PNG: %w", err)
 	}
 
-	// Encode to base64
-	base64String := base64.StdEncoding.EncodeToString(pngBytes)
-
-	return base64String, nil
+	return pngBytes, nil
 }
 
 // buildSolanaPayURL constructs a Solana Pay URL
@@ -134,23 +179,47 @@ func (g *Generator) validateConfig(config PaymentQRConfig) error {
 		return fmt.Errorf("amount must be greater than zero")
 	}
 
-	if config.TokenMint == "" {
-		return fmt.Errorf("token mint is required")
-	}
+	switch config.Scheme() {
+	case SchemeSolanaPay:
+		if config.TokenMint == "" {
+			return fmt.Errorf("token mint is required")
+		}
 
-	if config.Memo == "" {
-		return fmt.Errorf("memo is required")
+		if config.Memo == "" {
+			return fmt.Errorf("memo is required")
+		}
+	case SchemeTRON:
+		if config.TokenMint == "" {
+			return fmt.Errorf("token contract is required")
+		}
+	case SchemeEIP681:
+		if !common.IsHexAddress(config.TokenMint) {
+			return fmt.Errorf("token contract must be a hex address")
+		}
+
+		if !common.IsHexAddress(config.WalletAddress) {
+			return fmt.Errorf("wallet address must be a hex address")
+		}
+
+		if config.TokenDecimals < 0 || config.TokenDecimals > maxTokenDecimals {
+			return fmt.Errorf("invalid token decimals: %d", config.TokenDecimals)
+		}
 	}
 
 	// Validate size if specified
 	if config.Size != 0 {
 		if config.Size != QRCodeSizeSmall &&
-		   config.Size != QRCodeSizeMedium &&
-		   config.Size != QRCodeSizeLarge {
+			config.Size != QRCodeSizeMedium &&
+			config.Size != QRCodeSizeLarge {
 			return fmt.Errorf("invalid QR code size: %d", config.Size)
 		}
 	}
 
+	// Validate format if specified
+	if config.Format != "" && config.Format != QRCodeFormatPNG && config.Format != QRCodeFormatSVG {
+		return fmt.Errorf("invalid QR code format: %s", config.Format)
+	}
+
 	return nil
 }
 
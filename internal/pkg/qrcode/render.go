@@ -0,0 +1,143 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// QRCodeFormat represents the image format of a QR code
+type QRCodeFormat string
+
+const (
+	// QRCodeFormatPNG is a PNG image (default)
+	QRCodeFormatPNG QRCodeFormat = "png"
+	// QRCodeFormatSVG is an SVG image, it scales without blurring on payment pages and in print
+	QRCodeFormatSVG QRCodeFormat = "svg"
+)
+
+// MIMEType returns the media type of images in the format
+func (f QRCodeFormat) MIMEType() string {
+	if f == QRCodeFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+const (
+	// logoFraction is the share of the QR code width covered by the logo area
+	// With the highest error correction up to 30% of the code can be lost, 1/5 of the width stays well below
+	logoFraction = 5
+	// logoPaddingFraction is the share of the logo area kept white around the logo
+	logoPaddingFraction = 10
+)
+
+// renderPNG draws the QR code as a PNG image, with the logo in the center if provided
+func renderPNG(qrCode *qrcode.QRCode, size int, logo image.Image) ([]byte, error) {
+	if logo == nil {
+		return qrCode.PNG(size)
+	}
+
+	code := qrCode.Image(size)
+	canvas := image.NewRGBA(code.Bounds())
+	draw.Draw(canvas, canvas.Bounds(), code, code.Bounds().Min, draw.Src)
+	drawLogo(canvas, logo)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// drawLogo draws the logo on a white square in the center of the canvas
+func drawLogo(canvas *image.RGBA, logo image.Image) {
+	bounds := canvas.Bounds()
+	box := bounds.Dx() / logoFraction
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-box)/2, bounds.Min.Y+(bounds.Dy()-box)/2)
+	draw.Draw(canvas, image.Rectangle{Min: origin, Max: origin.Add(image.Pt(box, box))}, image.White, image.Point{}, draw.Src)
+
+	inner := box - 2*(box/logoPaddingFraction)
+	width, height := fitLogo(logo.Bounds(), inner)
+	if width == 0 || height == 0 {
+		return
+	}
+
+	// Nearest-neighbour scaling is enough for a logo of a few dozen pixels
+	source := logo.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			scaled.Set(x, y, logo.At(source.Min.X+x*source.Dx()/width, source.Min.Y+y*source.Dy()/height))
+		}
+	}
+
+	offset := origin.Add(image.Pt((box-width)/2, (box-height)/2))
+	draw.Draw(canvas, image.Rectangle{Min: offset, Max: offset.Add(image.Pt(width, height))}, scaled, image.Point{}, draw.Over)
+}
+
+// fitLogo returns the size of the logo scaled to fit a square of side pixels, keeping its aspect ratio
+func fitLogo(bounds image.Rectangle, side int) (int, int) {
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 || side <= 0 {
+		return 0, 0
+	}
+
+	if bounds.Dx() >= bounds.Dy() {
+		return side, max(1, side*bounds.Dy()/bounds.Dx())
+	}
+	return max(1, side*bounds.Dx()/bounds.Dy()), side
+}
+
+// renderSVG draws the QR code as an SVG image, with the logo in the center if provided
+// Each row of dark modules is one path segment, the view box is in modules and scaled to size pixels
+func renderSVG(qrCode *qrcode.QRCode, size int, logo image.Image) ([]byte, error) {
+	bitmap := qrCode.Bitmap()
+	modules := len(bitmap)
+
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/>`, modules, modules)
+	fmt.Fprintf(&buf, `<path d="%s" fill="#000000"/>`, path.String())
+
+	if logo != nil {
+		var logoPNG bytes.Buffer
+		if err := png.Encode(&logoPNG, logo); err != nil {
+			return nil, fmt.Errorf("failed to encode logo: %w", err)
+		}
+
+		box := float64(modules) / logoFraction
+		origin := (float64(modules) - box) / 2
+		padding := box / logoPaddingFraction
+		fmt.Fprintf(&buf, `<rect x="%g" y="%g" width="%g" height="%g" fill="#ffffff"/>`, origin, origin, box, box)
+		fmt.Fprintf(&buf, `<image x="%g" y="%g" width="%g" height="%g" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`,
+			origin+padding, origin+padding, box-2*padding, box-2*padding, base64.StdEncoding.EncodeToString(logoPNG.Bytes()))
+	}
+
+	buf.WriteString("</svg>")
+
+	return buf.Bytes(), nil
+}
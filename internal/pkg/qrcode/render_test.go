@@ -0,0 +1,111 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBSCConfig() PaymentQRConfig {
+	return PaymentQRConfig{
+		Chain:         "bsc",
+		ChainID:       56,
+		WalletAddress: testBSCWallet,
+		Amount:        decimal.NewFromInt(100),
+		TokenMint:     testBSCUSDT,
+		TokenDecimals: 18,
+		Size:          QRCodeSizeSmall,
+	}
+}
+
+func solidLogo(width, height int, c color.Color) image.Image {
+	logo := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			logo.Set(x, y, c)
+		}
+	}
+	return logo
+}
+
+func TestGeneratePaymentQR_SVG(t *testing.T) {
+	config := testBSCConfig()
+	config.Format = QRCodeFormatSVG
+
+	dataURL, err := NewGenerator().GeneratePaymentQRDataURL(config)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(dataURL, "data:image/svg+xml;base64,"))
+
+	svg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(dataURL, "data:image/svg+xml;base64,"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(svg), `<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256"`))
+	assert.Contains(t, string(svg), `<path d="M`)
+	assert.NotContains(t, string(svg), "<image")
+	assert.True(t, strings.HasSuffix(string(svg), "</svg>"))
+}
+
+func TestGeneratePaymentQR_SVGWithLogo(t *testing.T) {
+	config := testBSCConfig()
+	config.Format = QRCodeFormatSVG
+	config.Logo = solidLogo(40, 20, color.RGBA{R: 255, A: 255})
+
+	encoded, err := NewGenerator().GeneratePaymentQR(config)
+	require.NoError(t, err)
+
+	svg, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	assert.Contains(t, string(svg), `<image `)
+	assert.Contains(t, string(svg), `href="data:image/png;base64,`)
+}
+
+func TestGeneratePaymentQR_PNGWithLogo(t *testing.T) {
+	config := testBSCConfig()
+	red := color.RGBA{R: 255, A: 255}
+	config.Logo = solidLogo(64, 32, red)
+
+	encoded, err := NewGenerator().GeneratePaymentQR(config)
+	require.NoError(t, err)
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(decoded))
+	require.NoError(t, err)
+
+	bounds := img.Bounds()
+	center := color.RGBAModel.Convert(img.At(bounds.Dx()/2, bounds.Dy()/2))
+	assert.Equal(t, red, center)
+
+	// Outside the logo area the code is untouched
+	edge := color.RGBAModel.Convert(img.At(bounds.Dx()/2, bounds.Min.Y+2))
+	assert.NotEqual(t, red, edge)
+}
+
+func TestGeneratePaymentQR_InvalidFormat(t *testing.T) {
+	config := testBSCConfig()
+	config.Format = "gif"
+
+	_, err := NewGenerator().GeneratePaymentQR(config)
+	assert.ErrorContains(t, err, "invalid QR code format")
+}
+
+func TestFitLogo(t *testing.T) {
+	width, height := fitLogo(image.Rect(0, 0, 200, 100), 40)
+	assert.Equal(t, 40, width)
+	assert.Equal(t, 20, height)
+
+	width, height = fitLogo(image.Rect(0, 0, 10, 100), 40)
+	assert.Equal(t, 4, width)
+	assert.Equal(t, 40, height)
+
+	width, height = fitLogo(image.Rectangle{}, 40)
+	assert.Zero(t, width)
+	assert.Zero(t, height)
+}
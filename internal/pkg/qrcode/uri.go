@@ -0,0 +1,97 @@
+package qrcode
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PaymentScheme is the payment URI format encoded in a QR code
+type PaymentScheme string
+
+const (
+	// SchemeSolanaPay is a Solana Pay transfer request
+	SchemeSolanaPay PaymentScheme = "solana"
+	// SchemeEIP681 is an EIP-681 ERC-20/BEP-20 transfer on an EVM chain
+	SchemeEIP681 PaymentScheme = "eip681"
+	// SchemeTRON is a TRON payment URI for a TRC-20 transfer
+	SchemeTRON PaymentScheme = "tron"
+	// SchemePlainAddress is the bare recipient address, the payer enters the amount in the wallet
+	SchemePlainAddress PaymentScheme = "address"
+)
+
+// maxTokenDecimals bounds the decimals of EIP-681 amounts
+const maxTokenDecimals = 36
+
+// Scheme returns the payment URI format used for the chain of the configuration
+// EVM chains need a chain ID for EIP-681, without one the plain address is encoded
+// EIP-681 and TRON URIs carry no memo, a payment matched by memo gets the plain address and the memo is shown apart
+func (c PaymentQRConfig) Scheme() PaymentScheme {
+	switch strings.ToLower(c.Chain) {
+	case "", "solana":
+		return SchemeSolanaPay
+	case "tron":
+		if c.Memo == "" {
+			return SchemeTRON
+		}
+		return SchemePlainAddress
+	}
+
+	if c.ChainID > 0 && c.Memo == "" {
+		return SchemeEIP681
+	}
+	return SchemePlainAddress
+}
+
+// BuildPaymentURI returns the payment URI of the chain without generating a QR code
+// Useful for deep links and wallets that accept pasted payment requests
+func BuildPaymentURI(config PaymentQRConfig) (string, error) {
+	generator := NewGenerator()
+
+	if err := generator.validateConfig(config); err != nil {
+		return "", err
+	}
+
+	return generator.buildPaymentURI(config)
+}
+
+// buildPaymentURI builds the payment URI of the scheme selected by the chain
+func (g *Generator) buildPaymentURI(config PaymentQRConfig) (string, error) {
+	switch config.Scheme() {
+	case SchemeSolanaPay:
+		return g.buildSolanaPayURL(config)
+	case SchemeEIP681:
+		return g.buildEIP681URI(config)
+	case SchemeTRON:
+		return g.buildTRONURI(config)
+	default:
+		return config.WalletAddress, nil
+	}
+}
+
+// buildEIP681URI constructs an EIP-681 token transfer request
+// Format: ethereum:<token contract>@<chain id>/transfer?address=<recipient>&uint256=<amount in base units>
+func (g *Generator) buildEIP681URI(config PaymentQRConfig) (string, error) {
+	baseUnits := config.Amount.Shift(int32(config.TokenDecimals))
+	if !baseUnits.Equal(baseUnits.Truncate(0)) {
+		return "", fmt.Errorf("amount %s has more than %d decimals", config.Amount, config.TokenDecimals)
+	}
+
+	// Parameters are kept in EIP-681 order, url.Values would sort them
+	return fmt.Sprintf("ethereum:%s@%d/transfer?address=%s&uint256=%s",
+		config.TokenMint,
+		config.ChainID,
+		config.WalletAddress,
+		baseUnits.BigInt().String(),
+	), nil
+}
+
+// buildTRONURI constructs a TRON payment URI for a TRC-20 transfer
+// Format: tron:<recipient>?amount=<amount>&token=<contract>
+func (g *Generator) buildTRONURI(config PaymentQRConfig) (string, error) {
+	params := url.Values{}
+	params.Add("amount", config.Amount.String())
+	params.Add("token", config.TokenMint)
+
+	return fmt.Sprintf("tron:%s?%s", config.WalletAddress, params.Encode()), nil
+}
@@ -0,0 +1,135 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBSCWallet   = "0x52908400098527886E0F7030069857D2E4169EE7"
+	testBSCUSDT     = "0x55d398326f99059fF775485246999027B3197955"
+	testTRONWallet  = "TJRabPrwbZy45sbavfcjinPJC18kjpRTv8"
+	testTRONUSDT    = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	testSolanaAddr  = "7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU"
+	testPaymentMemo = "payment-123"
+)
+
+func TestPaymentQRConfig_Scheme(t *testing.T) {
+	assert.Equal(t, SchemeSolanaPay, PaymentQRConfig{}.Scheme())
+	assert.Equal(t, SchemeSolanaPay, PaymentQRConfig{Chain: "solana"}.Scheme())
+	assert.Equal(t, SchemeTRON, PaymentQRConfig{Chain: "tron"}.Scheme())
+	assert.Equal(t, SchemeEIP681, PaymentQRConfig{Chain: "bsc", ChainID: 56}.Scheme())
+	assert.Equal(t, SchemeEIP681, PaymentQRConfig{Chain: "polygon", ChainID: 137}.Scheme())
+	assert.Equal(t, SchemePlainAddress, PaymentQRConfig{Chain: "bsc"}.Scheme())
+	assert.Equal(t, SchemePlainAddress, PaymentQRConfig{Chain: "bsc", ChainID: 56, Memo: testPaymentMemo}.Scheme())
+	assert.Equal(t, SchemePlainAddress, PaymentQRConfig{Chain: "tron", Memo: testPaymentMemo}.Scheme())
+	assert.Equal(t, SchemeSolanaPay, PaymentQRConfig{Chain: "solana", Memo: testPaymentMemo}.Scheme())
+}
+
+func TestBuildPaymentURI_EIP681(t *testing.T) {
+	uri, err := BuildPaymentURI(PaymentQRConfig{
+		Chain:         "bsc",
+		ChainID:       56,
+		WalletAddress: testBSCWallet,
+		Amount:        decimal.RequireFromString("100.5"),
+		TokenMint:     testBSCUSDT,
+		TokenDecimals: 18,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t,
+		"ethereum:"+testBSCUSDT+"@56/transfer?address="+testBSCWallet+"&uint256=100500000000000000000",
+		uri)
+}
+
+func TestBuildPaymentURI_EIP681Validation(t *testing.T) {
+	config := PaymentQRConfig{
+		Chain:         "bsc",
+		ChainID:       56,
+		WalletAddress: testBSCWallet,
+		Amount:        decimal.RequireFromString("1.2345678"),
+		TokenMint:     testBSCUSDT,
+		TokenDecimals: 6,
+	}
+
+	_, err := BuildPaymentURI(config)
+	assert.ErrorContains(t, err, "more than 6 decimals")
+
+	invalid := config
+	invalid.TokenMint = testTRONUSDT
+	_, err = BuildPaymentURI(invalid)
+	assert.ErrorContains(t, err, "token contract must be a hex address")
+
+	invalid = config
+	invalid.WalletAddress = testSolanaAddr
+	_, err = BuildPaymentURI(invalid)
+	assert.ErrorContains(t, err, "wallet address must be a hex address")
+}
+
+func TestBuildPaymentURI_TRON(t *testing.T) {
+	uri, err := BuildPaymentURI(PaymentQRConfig{
+		Chain:         "tron",
+		WalletAddress: testTRONWallet,
+		Amount:        decimal.RequireFromString("25.75"),
+		TokenMint:     testTRONUSDT,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "tron:"+testTRONWallet+"?amount=25.75&token="+testTRONUSDT, uri)
+
+	_, err = BuildPaymentURI(PaymentQRConfig{Chain: "tron", WalletAddress: testTRONWallet, Amount: decimal.NewFromInt(1)})
+	assert.ErrorContains(t, err, "token contract is required")
+}
+
+func TestBuildPaymentURI_PlainAddress(t *testing.T) {
+	// Without a chain ID the amount cannot be encoded, the payer enters it in the wallet
+	uri, err := BuildPaymentURI(PaymentQRConfig{
+		Chain:         "bsc",
+		WalletAddress: testBSCWallet,
+		Amount:        decimal.NewFromInt(10),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, testBSCWallet, uri)
+}
+
+func TestBuildPaymentURI_MemoPaymentGetsPlainAddress(t *testing.T) {
+	// A shared wallet needs the memo to match the payment, a URI without it would pay an unmatched transfer
+	uri, err := BuildPaymentURI(PaymentQRConfig{
+		Chain:         "bsc",
+		ChainID:       56,
+		WalletAddress: testBSCWallet,
+		Amount:        decimal.NewFromInt(10),
+		TokenMint:     testBSCUSDT,
+		TokenDecimals: 18,
+		Memo:          testPaymentMemo,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testBSCWallet, uri)
+
+	uri, err = BuildPaymentURI(PaymentQRConfig{
+		Chain:         "tron",
+		WalletAddress: testTRONWallet,
+		Amount:        decimal.NewFromInt(10),
+		TokenMint:     testTRONUSDT,
+		Memo:          testPaymentMemo,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testTRONWallet, uri)
+}
+
+func TestBuildPaymentURI_SolanaPay(t *testing.T) {
+	uri, err := BuildPaymentURI(PaymentQRConfig{
+		Chain:         "solana",
+		WalletAddress: testSolanaAddr,
+		Amount:        decimal.NewFromInt(50),
+		TokenMint:     USDCMintSolana,
+		Memo:          testPaymentMemo,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "solana:"+testSolanaAddr+"?amount=50&memo="+testPaymentMemo+"&spl-token="+USDCMintSolana, uri)
+}
@@ -23,6 +23,8 @@ type StorageService interface {
 	DeleteFile(ctx context.Context, bucket string, key string) error
 	FileExists(ctx context.Context, bucket string, key string) (bool, error)
 	GetFileMetadata(ctx context.Context, bucket string, key string) (*FileMetadata, error)
+	// GetDownloadURL returns a URL that downloads the file without credentials until it expires
+	GetDownloadURL(ctx context.Context, bucket string, key string, expiry time.Duration) (string, error)
 }
 
 // FileMetadata represents metadata about a stored file
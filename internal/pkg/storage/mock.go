@@ -211,6 +211,13 @@ func (m *MockStorage) GetFileMetadata(ctx context.Context, bucket string, key st
 	return metadata, nil
 }
 
+// GetDownloadURL returns a mock URL of a file in mock storage
+// The file is not looked up, it may have been uploaded by another process
+func (m *MockStorage) GetDownloadURL(ctx context.Context, bucket string, key string, expiry time.Duration) (string, error) {
+	fullKey := fmt.Sprintf("%s/%s", bucket, key)
+	return fmt.Sprintf("mock://storage/%s?expires=%d", fullKey, time.Now().Add(expiry).Unix()), nil
+}
+
 // Clear removes all files from mock storage (useful for tests)
 func (m *MockStorage) Clear() {
 	m.mu.Lock()
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -223,6 +224,21 @@ func (s *S3Storage) GetFileMetadata(ctx context.Context, bucket string, key stri
 	return metadata, nil
 }
 
+// GetDownloadURL returns a presigned GET URL of a file in S3
+func (s *S3Storage) GetDownloadURL(ctx context.Context, bucket string, key string, expiry time.Duration) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	request, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, input, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign S3 download URL: %w", err)
+	}
+
+	return request.URL, nil
+}
+
 // extractKeyFromURL extracts the S3 key from a full S3 URL
 func (s *S3Storage) extractKeyFromURL(url string) (string, error) {
 	// Simple implementation - in production, use proper URL parsing
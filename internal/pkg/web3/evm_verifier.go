@@ -0,0 +1,273 @@
+package web3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	// ErrChallengeExpired is returned when the expiry of a signed challenge has passed
+	ErrChallengeExpired = errors.New("ownership challenge has expired")
+	// ErrChallengeTooLong is returned when a challenge expires further out than MaxChallengeValidity
+	ErrChallengeTooLong = errors.New("ownership challenge validity is too long")
+)
+
+// MaxChallengeValidity bounds how far in the future an ownership challenge may expire,
+// limiting how long a leaked signature can be replayed
+const MaxChallengeValidity = 24 * time.Hour
+
+// EIP-712 domain and type of ownership challenges
+const (
+	OwnershipDomainName    = "Stable Payment Gateway"
+	OwnershipDomainVersion = "1"
+	OwnershipPrimaryType   = "OwnershipProof"
+)
+
+// Lines of a personal_sign (EIP-191) ownership challenge
+const (
+	challengeStatementPrefix = "Sign this message to prove ownership of wallet "
+	challengeNoncePrefix     = "Nonce: "
+	challengeExpiryPrefix    = "Expires At: "
+)
+
+// OwnershipChallenge is the content an EVM payer signs to prove they own a wallet
+// The nonce makes every challenge unique, callers reject nonces that were already used
+type OwnershipChallenge struct {
+	Address   string
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+// GenerateEVMChallengeMessage generates a personal_sign (EIP-191) ownership challenge
+//
+// Format:
+//
+//	Sign this message to prove ownership of wallet {address}
+//	Nonce: {nonce}
+//	Expires At: {RFC 3339 expiry}
+func GenerateEVMChallengeMessage(address, nonce string, expiresAt time.Time) string {
+	return challengeStatementPrefix + address + "\n" +
+		challengeNoncePrefix + nonce + "\n" +
+		challengeExpiryPrefix + expiresAt.UTC().Format(time.RFC3339)
+}
+
+// EVMOwnershipTypedData builds the EIP-712 ownership challenge signed with eth_signTypedData_v4
+// chainID is shown by wallets in the signing prompt, it does not restrict where the proof is accepted
+func EVMOwnershipTypedData(address, nonce string, expiresAt time.Time, chainID int64) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+			OwnershipPrimaryType: {
+				{Name: "wallet", Type: "address"},
+				{Name: "nonce", Type: "string"},
+				{Name: "expiresAt", Type: "string"},
+			},
+		},
+		PrimaryType: OwnershipPrimaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:    OwnershipDomainName,
+			Version: OwnershipDomainVersion,
+			ChainId: (*math.HexOrDecimal256)(big.NewInt(chainID)),
+		},
+		Message: apitypes.TypedDataMessage{
+			"wallet":    address,
+			"nonce":     nonce,
+			"expiresAt": expiresAt.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+// ParseEVMChallenge extracts the wallet, nonce and expiry of an ownership challenge
+// The message is either the JSON of the EIP-712 typed data or a personal_sign challenge
+func ParseEVMChallenge(message string) (*OwnershipChallenge, error) {
+	if isTypedDataMessage(message) {
+		typedData, err := parseOwnershipTypedData(message)
+		if err != nil {
+			return nil, err
+		}
+		return challengeFromTypedData(typedData)
+	}
+
+	return parsePersonalSignChallenge(message)
+}
+
+// VerifyEVMSignature verifies a personal_sign (EIP-191) ownership challenge signed by address
+// The message must be a challenge from GenerateEVMChallengeMessage for address that has not expired
+//
+// Parameters:
+//   - address: 0x-prefixed hex EVM address
+//   - message: Plain text challenge that was signed
+//   - signatureHex: 0x-prefixed 65-byte r || s || v signature, v is 0/1 or 27/28
+func VerifyEVMSignature(address, message, signatureHex string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, ErrInvalidWalletAddress
+	}
+
+	challenge, err := parsePersonalSignChallenge(message)
+	if err != nil {
+		return false, err
+	}
+	if err := checkChallenge(challenge, address); err != nil {
+		return false, err
+	}
+
+	return verifyEVMHash(address, accounts.TextHash([]byte(message)), signatureHex)
+}
+
+// VerifyEVMTypedDataSignature verifies an EIP-712 ownership challenge signed by address
+// typedDataJSON is the typed data passed to eth_signTypedData_v4, see EVMOwnershipTypedData
+func VerifyEVMTypedDataSignature(address, typedDataJSON, signatureHex string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, ErrInvalidWalletAddress
+	}
+
+	typedData, err := parseOwnershipTypedData(typedDataJSON)
+	if err != nil {
+		return false, err
+	}
+	challenge, err := challengeFromTypedData(typedData)
+	if err != nil {
+		return false, err
+	}
+	if err := checkChallenge(challenge, address); err != nil {
+		return false, err
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to hash typed data: %v", ErrInvalidMessageFormat, err)
+	}
+
+	return verifyEVMHash(address, hash, signatureHex)
+}
+
+// verifyEVMHash recovers the signer of hash and compares it with address
+func verifyEVMHash(address string, hash []byte, signatureHex string) (bool, error) {
+	if signatureHex == "" {
+		return false, errors.New("signature cannot be empty")
+	}
+
+	signature, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode signature from hex: %w", err)
+	}
+	if len(signature) != crypto.SignatureLength {
+		return false, fmt.Errorf("invalid signature length: expected %d bytes, got %d bytes", crypto.SignatureLength, len(signature))
+	}
+
+	// Wallets return v as 27/28, the recovery expects 0/1
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return false, ErrInvalidSignature
+	}
+
+	if crypto.PubkeyToAddress(*publicKey) != common.HexToAddress(address) {
+		return false, ErrInvalidSignature
+	}
+
+	return true, nil
+}
+
+// checkChallenge checks the challenge was issued for address and is still valid
+func checkChallenge(challenge *OwnershipChallenge, address string) error {
+	if !common.IsHexAddress(challenge.Address) || common.HexToAddress(challenge.Address) != common.HexToAddress(address) {
+		return fmt.Errorf("%w: challenge is for another wallet", ErrInvalidMessageFormat)
+	}
+
+	now := time.Now()
+	if !challenge.ExpiresAt.After(now) {
+		return ErrChallengeExpired
+	}
+	if challenge.ExpiresAt.After(now.Add(MaxChallengeValidity)) {
+		return ErrChallengeTooLong
+	}
+
+	return nil
+}
+
+// parsePersonalSignChallenge parses a challenge from GenerateEVMChallengeMessage
+func parsePersonalSignChallenge(message string) (*OwnershipChallenge, error) {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(message), "\r\n", "\n"), "\n")
+	if len(lines) != 3 ||
+		!strings.HasPrefix(lines[0], challengeStatementPrefix) ||
+		!strings.HasPrefix(lines[1], challengeNoncePrefix) ||
+		!strings.HasPrefix(lines[2], challengeExpiryPrefix) {
+		return nil, fmt.Errorf("%w: expected an ownership challenge with nonce and expiry", ErrInvalidMessageFormat)
+	}
+
+	challenge := &OwnershipChallenge{
+		Address: strings.TrimPrefix(lines[0], challengeStatementPrefix),
+		Nonce:   strings.TrimPrefix(lines[1], challengeNoncePrefix),
+	}
+	if challenge.Nonce == "" {
+		return nil, fmt.Errorf("%w: nonce is required", ErrInvalidMessageFormat)
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, strings.TrimPrefix(lines[2], challengeExpiryPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid expiry: %v", ErrInvalidMessageFormat, err)
+	}
+	challenge.ExpiresAt = expiresAt
+
+	return challenge, nil
+}
+
+// isTypedDataMessage returns true if the signed message is EIP-712 typed data JSON
+func isTypedDataMessage(message string) bool {
+	return strings.HasPrefix(strings.TrimSpace(message), "{")
+}
+
+// parseOwnershipTypedData parses EIP-712 typed data and checks it is an ownership challenge of this gateway
+// Checking the domain name keeps proofs signed for other applications from being accepted
+func parseOwnershipTypedData(typedDataJSON string) (*apitypes.TypedData, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal([]byte(typedDataJSON), &typedData); err != nil {
+		return nil, fmt.Errorf("%w: invalid typed data: %v", ErrInvalidMessageFormat, err)
+	}
+
+	if typedData.PrimaryType != OwnershipPrimaryType || typedData.Domain.Name != OwnershipDomainName {
+		return nil, fmt.Errorf("%w: typed data is not an ownership challenge", ErrInvalidMessageFormat)
+	}
+
+	return &typedData, nil
+}
+
+// challengeFromTypedData reads the wallet, nonce and expiry of an EIP-712 ownership challenge
+func challengeFromTypedData(typedData *apitypes.TypedData) (*OwnershipChallenge, error) {
+	wallet, _ := typedData.Message["wallet"].(string)
+	nonce, _ := typedData.Message["nonce"].(string)
+	expiry, _ := typedData.Message["expiresAt"].(string)
+	if wallet == "" || nonce == "" || expiry == "" {
+		return nil, fmt.Errorf("%w: wallet, nonce and expiresAt are required", ErrInvalidMessageFormat)
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid expiry: %v", ErrInvalidMessageFormat, err)
+	}
+
+	return &OwnershipChallenge{
+		Address:   wallet,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package web3
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return key, crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// signHash signs like a wallet, with v as 27/28
+func signHash(t *testing.T, key *ecdsa.PrivateKey, hash []byte) string {
+	signature, err := crypto.Sign(hash, key)
+	require.NoError(t, err)
+	signature[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(signature)
+}
+
+func TestVerifyEVMSignature(t *testing.T) {
+	key, address := newTestKey(t)
+	message := GenerateEVMChallengeMessage(address, "n-1", time.Now().Add(10*time.Minute))
+	signature := signHash(t, key, accounts.TextHash([]byte(message)))
+
+	valid, err := VerifyEVMSignature(address, message, signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Addresses are compared case-insensitively
+	_, err = VerifyEVMSignature(strings.ToLower(address), message, signature)
+	assert.NoError(t, err)
+
+	_, otherAddress := newTestKey(t)
+	_, err = VerifyEVMSignature(otherAddress, GenerateEVMChallengeMessage(otherAddress, "n-1", time.Now().Add(10*time.Minute)), signature)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = VerifyEVMSignature(otherAddress, message, signature)
+	assert.ErrorIs(t, err, ErrInvalidMessageFormat)
+
+	_, err = VerifyEVMSignature(address, "I own this wallet", signature)
+	assert.ErrorIs(t, err, ErrInvalidMessageFormat)
+}
+
+func TestVerifyEVMSignature_Expiry(t *testing.T) {
+	key, address := newTestKey(t)
+
+	expired := GenerateEVMChallengeMessage(address, "n-1", time.Now().Add(-time.Minute))
+	_, err := VerifyEVMSignature(address, expired, signHash(t, key, accounts.TextHash([]byte(expired))))
+	assert.ErrorIs(t, err, ErrChallengeExpired)
+
+	tooLong := GenerateEVMChallengeMessage(address, "n-1", time.Now().Add(MaxChallengeValidity+time.Hour))
+	_, err = VerifyEVMSignature(address, tooLong, signHash(t, key, accounts.TextHash([]byte(tooLong))))
+	assert.ErrorIs(t, err, ErrChallengeTooLong)
+}
+
+func TestVerifyEVMTypedDataSignature(t *testing.T) {
+	key, address := newTestKey(t)
+	typedData := EVMOwnershipTypedData(address, "n-2", time.Now().Add(10*time.Minute), 56)
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+	signature := signHash(t, key, hash)
+
+	typedDataJSON, err := json.Marshal(typedData)
+	require.NoError(t, err)
+
+	valid, err := VerifyEVMTypedDataSignature(address, string(typedDataJSON), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// A different nonce changes the hash
+	tampered := EVMOwnershipTypedData(address, "n-3", time.Now().Add(10*time.Minute), 56)
+	tamperedJSON, err := json.Marshal(tampered)
+	require.NoError(t, err)
+	_, err = VerifyEVMTypedDataSignature(address, string(tamperedJSON), signature)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Typed data of another application is not an ownership challenge
+	foreign := EVMOwnershipTypedData(address, "n-2", time.Now().Add(10*time.Minute), 56)
+	foreign.Domain.Name = "Other dApp"
+	foreignJSON, err := json.Marshal(foreign)
+	require.NoError(t, err)
+	_, err = VerifyEVMTypedDataSignature(address, string(foreignJSON), signature)
+	assert.ErrorIs(t, err, ErrInvalidMessageFormat)
+}
+
+func TestVerifyOwnership_Dispatch(t *testing.T) {
+	key, address := newTestKey(t)
+	message := GenerateEVMChallengeMessage(address, "n-4", time.Now().Add(10*time.Minute))
+	signature := signHash(t, key, accounts.TextHash([]byte(message)))
+
+	for _, chain := range []string{"bsc", "ethereum", "polygon"} {
+		valid, err := VerifyOwnership(chain, address, message, signature)
+		require.NoError(t, err, chain)
+		assert.True(t, valid, chain)
+	}
+
+	_, err := VerifyOwnership("tron", address, message, signature)
+	assert.ErrorIs(t, err, ErrUnsupportedChain)
+
+	// Solana expects a Base58 address
+	_, err = VerifyOwnership("solana", address, message, signature)
+	assert.ErrorIs(t, err, ErrInvalidWalletAddress)
+}
+
+func TestParseEVMChallenge(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	address := "0x52908400098527886E0F7030069857D2E4169EE7"
+
+	challenge, err := ParseEVMChallenge(GenerateEVMChallengeMessage(address, "abc", expiresAt))
+	require.NoError(t, err)
+	assert.Equal(t, &OwnershipChallenge{Address: address, Nonce: "abc", ExpiresAt: expiresAt}, challenge)
+
+	typedDataJSON, err := json.Marshal(EVMOwnershipTypedData(address, "abc", expiresAt, 1))
+	require.NoError(t, err)
+	challenge, err = ParseEVMChallenge(string(typedDataJSON))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", challenge.Nonce)
+	assert.True(t, expiresAt.Equal(challenge.ExpiresAt))
+}
+
+// memNonceClaimer accepts the nonces it issued once
+type memNonceClaimer struct {
+	issued map[string]bool
+}
+
+func (c *memNonceClaimer) ClaimChallengeNonce(chain, address, nonce string) error {
+	key := chain + ":" + strings.ToLower(address) + ":" + nonce
+	if !c.issued[key] {
+		return errors.New("nonce rejected")
+	}
+	delete(c.issued, key)
+	return nil
+}
+
+func TestClaimChallengeNonce(t *testing.T) {
+	_, address := newTestKey(t)
+	message := GenerateEVMChallengeMessage(address, "n-5", time.Now().Add(10*time.Minute))
+	claimer := &memNonceClaimer{issued: map[string]bool{"bsc:" + strings.ToLower(address) + ":n-5": true}}
+
+	require.NoError(t, ClaimChallengeNonce(claimer, "BSC", address, message))
+
+	// A replayed signature carries a nonce that was already claimed
+	assert.Error(t, ClaimChallengeNonce(claimer, "bsc", address, message))
+
+	// Fails closed for EVM chains without a claimer, Solana proofs carry no nonce
+	assert.ErrorIs(t, ClaimChallengeNonce(nil, "bsc", address, message), ErrChallengeNoncesNotConfigured)
+	assert.NoError(t, ClaimChallengeNonce(nil, "solana", address, "any message"))
+}
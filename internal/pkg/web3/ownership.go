@@ -0,0 +1,74 @@
+package web3
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrUnsupportedChain is returned when ownership proofs cannot be verified for the chain
+	ErrUnsupportedChain = errors.New("wallet ownership proofs not supported on this chain")
+	// ErrChallengeNoncesNotConfigured is returned when EVM proofs are checked without a nonce claimer
+	ErrChallengeNoncesNotConfigured = errors.New("ownership challenge nonces not configured")
+)
+
+// ChallengeNonceClaimer accepts each challenge nonce it issued once, for the wallet and chain it was issued to
+type ChallengeNonceClaimer interface {
+	ClaimChallengeNonce(chain, address, nonce string) error
+}
+
+// VerifyOwnership verifies that message was signed by the wallet address on chain
+//
+// Solana wallets sign the message with Ed25519 (signature in Base64). TRON is not supported.
+// Every other chain is treated as an EVM network (BSC, Ethereum and the configured EVM networks)
+// and the message must be an ownership challenge with a nonce and an expiry: either the
+// personal_sign (EIP-191) text from GenerateEVMChallengeMessage or the EIP-712 typed data JSON
+// from EVMOwnershipTypedData. EVM signatures are 0x-prefixed hex.
+func VerifyOwnership(chain, address, message, signature string) (bool, error) {
+	switch {
+	case strings.EqualFold(chain, "solana"):
+		return VerifySolanaSignature(address, message, signature)
+	case !IsEVMChain(chain):
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedChain, chain)
+	}
+
+	if isTypedDataMessage(message) {
+		return VerifyEVMTypedDataSignature(address, message, signature)
+	}
+	return VerifyEVMSignature(address, message, signature)
+}
+
+// ClaimChallengeNonce claims the nonce of a verified EVM ownership challenge, so the signature cannot be replayed
+// EVM proofs are rejected without a claimer. Solana proofs carry no nonce and are not claimed.
+func ClaimChallengeNonce(claimer ChallengeNonceClaimer, chain, address, message string) error {
+	if !IsEVMChain(chain) {
+		return nil
+	}
+	if claimer == nil {
+		return ErrChallengeNoncesNotConfigured
+	}
+
+	challenge, err := ParseEVMChallenge(message)
+	if err != nil {
+		return err
+	}
+
+	return claimer.ClaimChallengeNonce(strings.ToLower(chain), address, challenge.Nonce)
+}
+
+// IsEVMAddress returns true if address is a 0x hex EVM address
+func IsEVMAddress(address string) bool {
+	return common.IsHexAddress(address)
+}
+
+// IsEVMChain returns true if ownership proofs on chain use EVM signatures
+func IsEVMChain(chain string) bool {
+	switch strings.ToLower(chain) {
+	case "", "solana", "tron":
+		return false
+	}
+	return true
+}
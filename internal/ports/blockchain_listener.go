@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/shopspring/decimal"
+
+	blockchainDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/domain"
 )
 
 // BlockchainType represents the type of blockchain
@@ -50,7 +52,7 @@ type PaymentConfirmationHandler func(ctx context.Context, confirmation PaymentCo
 
 // BlockchainListener defines the interface for monitoring blockchain transactions
 // This is the PORT in the Hexagonal Architecture (Ports & Adapters pattern)
-// Implementations (Adapters) include: SolanaListener, BSCListener, TRONListener
+// Implementations (Adapters) include: SolanaListener, BSCListener, TRONListener, EVMListener
 type BlockchainListener interface {
 	// Start begins listening for transactions on the blockchain
 	Start(ctx context.Context) error
@@ -99,6 +101,17 @@ type ListenerHealth struct {
 	ConnectionStatus string
 }
 
+// ListenerToken is a token accepted by a listener
+type ListenerToken struct {
+	Symbol   string
+	Address  string // Contract or mint address
+	Decimals uint8
+}
+
+// TokenProvider returns the tokens a listener accepts
+// Listeners reload them every poll, so tokens enabled or disabled meanwhile are picked up without a restart
+type TokenProvider func(ctx context.Context) ([]ListenerToken, error)
+
 // BlockchainListenerConfig holds common configuration for blockchain listeners
 type BlockchainListenerConfig struct {
 	// BlockchainType identifies the blockchain
@@ -116,6 +129,16 @@ type BlockchainListenerConfig struct {
 	// SupportedTokens maps token symbols to their contract addresses or mint addresses
 	SupportedTokens map[string]string
 
+	// TokenDecimals maps token symbols to their decimals, listeners fall back to chain defaults for missing symbols
+	TokenDecimals map[string]uint8
+
+	// TokenProvider reloads the supported tokens every poll (optional)
+	// SupportedTokens and TokenDecimals are used until it first answers
+	TokenProvider TokenProvider
+
+	// ChainID identifies EVM networks, a non-zero value selects the generic EVM listener
+	ChainID int64
+
 	// ConfirmationHandler is the callback for confirmed payments
 	ConfirmationHandler PaymentConfirmationHandler
 
@@ -127,4 +150,8 @@ type BlockchainListenerConfig struct {
 
 	// MaxRetries is the maximum number of retries for failed RPC calls
 	MaxRetries int
+
+	// ListenerStore persists the listener cursor and processed transactions (optional)
+	// Without it the listener starts at the chain head and missed blocks are not backfilled
+	ListenerStore blockchainDomain.ListenerStore
 }
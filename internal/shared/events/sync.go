@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SyncEventBus implements EventBus by running handlers in the publisher's goroutine
+// Publish returns the handlers' errors, so publishers can act on a rejected event
+type SyncEventBus struct {
+	handlers map[string][]Handler
+	mu       sync.RWMutex
+	logger   *logrus.Logger
+	inFlight sync.WaitGroup
+	stopped  bool
+}
+
+// NewSyncEventBus creates a new synchronous event bus
+func NewSyncEventBus(logger *logrus.Logger) *SyncEventBus {
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	return &SyncEventBus{
+		handlers: make(map[string][]Handler),
+		logger:   logger,
+	}
+}
+
+// Publish runs all handlers registered for the event one after the other
+// Every handler runs, the returned error joins the errors of those that failed
+func (b *SyncEventBus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	if b.stopped {
+		b.mu.RUnlock()
+		return fmt.Errorf("event bus is stopped")
+	}
+
+	handlers := b.handlers[event.Name()]
+	b.inFlight.Add(1)
+	b.mu.RUnlock()
+	defer b.inFlight.Done()
+
+	if len(handlers) == 0 {
+		b.logger.WithFields(logrus.Fields{
+			"event": event.Name(),
+		}).Debug("No handlers registered for event")
+		return nil
+	}
+
+	var errs []error
+	for i, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			b.logger.WithFields(logrus.Fields{
+				"event":   event.Name(),
+				"handler": i,
+				"error":   err.Error(),
+			}).Error("Event handler failed")
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Subscribe registers a handler for a specific event
+func (b *SyncEventBus) Subscribe(eventName string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		b.logger.Warn("Cannot subscribe to stopped event bus")
+		return
+	}
+
+	b.handlers[eventName] = append(b.handlers[eventName], handler)
+}
+
+// Shutdown stops accepting events and waits for in-flight publishes to complete
+func (b *SyncEventBus) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncEventBus_PublishRunsHandlersBeforeReturning(t *testing.T) {
+	bus := NewSyncEventBus(logrus.New())
+
+	var received []string
+	for i := 0; i < 2; i++ {
+		bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+			received = append(received, event.(*TestEvent).Data)
+			return nil
+		})
+	}
+
+	require.NoError(t, bus.Publish(context.Background(), NewTestEvent("hello")))
+	assert.Equal(t, []string{"hello", "hello"}, received)
+}
+
+func TestSyncEventBus_PublishReturnsHandlerErrors(t *testing.T) {
+	bus := NewSyncEventBus(logrus.New())
+	rejected := errors.New("rejected")
+
+	var calls int
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		calls++
+		return rejected
+	})
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		calls++
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), NewTestEvent("hello"))
+	assert.ErrorIs(t, err, rejected)
+	assert.Equal(t, 2, calls)
+}
+
+func TestSyncEventBus_PublishAfterShutdown(t *testing.T) {
+	bus := NewSyncEventBus(logrus.New())
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		t.Fatal("handler called after shutdown")
+		return nil
+	})
+
+	require.NoError(t, bus.Shutdown(context.Background()))
+	assert.Error(t, bus.Publish(context.Background(), NewTestEvent("hello")))
+}
@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -118,6 +119,60 @@ func (s *Server) handlePaymentExpiry(ctx context.Context, task *asynq.Task) erro
 	return nil
 }
 
+// handleSubscriptionBilling issues due subscription billing cycles and retries unpaid ones
+func (s *Server) handleSubscriptionBilling(ctx context.Context, task *asynq.Task) error {
+	processed, err := s.subscriptionService.ProcessDueSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to process due subscriptions: %w", err)
+	}
+
+	if processed > 0 {
+		logger.Info("Subscription billing completed", logger.Fields{
+			"processed": processed,
+		})
+	}
+
+	return nil
+}
+
+// handlePaymentBatch creates the payments of a batch submitted through the API
+func (s *Server) handlePaymentBatch(ctx context.Context, task *asynq.Task) error {
+	var payload PaymentBatchPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payment batch payload: %w", err)
+	}
+
+	if err := s.paymentBatchService.ProcessPaymentBatch(ctx, payload.BatchID); err != nil {
+		return fmt.Errorf("failed to process payment batch %s: %w", payload.BatchID, err)
+	}
+
+	return nil
+}
+
+// handleExport writes the file of an export requested through the API
+// The export is marked failed once the last retry failed, so the merchant is not left waiting
+func (s *Server) handleExport(ctx context.Context, task *asynq.Task) error {
+	var payload ExportPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal export payload: %w", err)
+	}
+
+	if err := s.exportService.ProcessExport(ctx, payload.ExportID); err != nil {
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			if failErr := s.exportService.FailExport(ctx, payload.ExportID); failErr != nil {
+				logger.Error("Failed to mark export failed", failErr, logger.Fields{
+					"export_id": payload.ExportID,
+				})
+			}
+		}
+		return fmt.Errorf("failed to process export %s: %w", payload.ExportID, err)
+	}
+
+	return nil
+}
+
 // handleBalanceCheck processes wallet balance check jobs
 func (s *Server) handleBalanceCheck(ctx context.Context, task *asynq.Task) error {
 	var payload BalanceCheckPayload
@@ -337,12 +392,8 @@ func (s *Server) handleDailyReconciliation(ctx context.Context, task *asynq.Task
 			"duration_seconds": duration.Seconds(),
 		})
 
-		// Check if this is a critical deficit error
 		// This is critical - the task should fail so alerts are triggered
 		return fmt.Errorf("CRITICAL: %w", err)
-
-		// Non-critical error, just log
-		return err
 	}
 
 	// Get the latest reconciliation result to log it
@@ -373,3 +424,103 @@ func (s *Server) handleDailyReconciliation(ctx context.Context, task *asynq.Task
 
 	return nil
 }
+
+// handleRefundProcess reconciles interrupted refunds, sends pending refunds on-chain
+// and tracks submitted ones until finality
+func (s *Server) handleRefundProcess(ctx context.Context, task *asynq.Task) error {
+	reconciled, err := s.refundService.ReconcileSendingRefunds(ctx)
+	if err != nil {
+		if errors.Is(err, paymentDomain.ErrRefundSenderNotConfigured) {
+			logger.Warn("Refund sender not configured, skipping refund processing")
+			return nil
+		}
+		return fmt.Errorf("failed to reconcile sending refunds: %w", err)
+	}
+
+	sent, err := s.refundService.ProcessPendingRefunds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to process pending refunds: %w", err)
+	}
+
+	finalized, err := s.refundService.CheckSubmittedRefunds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check submitted refunds: %w", err)
+	}
+
+	if reconciled > 0 || sent > 0 || finalized > 0 {
+		logger.Info("Refund processing completed", logger.Fields{
+			"reconciled": reconciled,
+			"sent":       sent,
+			"finalized":  finalized,
+		})
+	}
+
+	return nil
+}
+
+// handleDepositSweep consolidates deposit addresses of finished payments into the hot wallets
+func (s *Server) handleDepositSweep(ctx context.Context, task *asynq.Task) error {
+	if s.depositSweepService == nil {
+		logger.Warn("Deposit sweeper not configured, skipping deposit sweep")
+		return nil
+	}
+
+	swept, err := s.depositSweepService.SweepDepositAddresses(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sweep deposit addresses: %w", err)
+	}
+
+	if swept > 0 {
+		logger.Info("Deposit sweep completed", logger.Fields{
+			"swept": swept,
+		})
+	}
+
+	return nil
+}
+
+// handleDepositProvision creates the on-chain account of a deposit address generated through the API
+func (s *Server) handleDepositProvision(ctx context.Context, task *asynq.Task) error {
+	if s.depositSweepService == nil {
+		logger.Warn("Deposit sweeper not configured, skipping deposit provisioning")
+		return nil
+	}
+
+	var payload DepositProvisionPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal deposit provision payload: %w", err)
+	}
+
+	if err := s.depositSweepService.ProvisionDepositAddress(ctx, payload.PaymentID); err != nil {
+		return fmt.Errorf("failed to provision deposit address of payment %s: %w", payload.PaymentID, err)
+	}
+
+	return nil
+}
+
+// handleConfirmationWatch re-verifies payment transfers until they are final
+// Payments complete once their transfers reach the confirmation policy and are reversed if a transfer disappears.
+// Surpluses of overpaid payments that failed to reach the ledger are retried first.
+func (s *Server) handleConfirmationWatch(ctx context.Context, task *asynq.Task) error {
+	if _, err := s.paymentService.ReconcileSurplus(ctx); err != nil {
+		return fmt.Errorf("failed to reconcile payment surpluses: %w", err)
+	}
+
+	completed, reversed, err := s.paymentService.WatchConfirmations(ctx)
+	if err != nil {
+		if errors.Is(err, paymentDomain.ErrTransactionVerifierNotConfigured) {
+			logger.Warn("Transaction verifier not configured, skipping confirmation watch")
+			return nil
+		}
+		return fmt.Errorf("failed to watch payment confirmations: %w", err)
+	}
+
+	if completed > 0 || reversed > 0 {
+		logger.Info("Payment confirmation watch completed", logger.Fields{
+			"completed": completed,
+			"reversed":  reversed,
+		})
+	}
+
+	return nil
+}
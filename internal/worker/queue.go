@@ -14,9 +14,16 @@ import (
 const (
 	TypeWebhookDelivery       = "webhook:delivery"
 	TypePaymentExpiry         = "payment:expiry"
+	TypeSubscriptionBilling   = "subscription:billing"
+	TypePaymentBatch          = "payment:batch"
+	TypeExport                = "export:generate"
 	TypeBalanceCheck          = "wallet:balance_check"
 	TypeDailySettlementReport = "report:daily_settlement"
 	TypeDailyReconciliation   = "audit:daily_reconciliation"
+	TypeRefundProcess         = "refund:process"
+	TypeDepositSweep          = "deposit:sweep"
+	TypeConfirmationWatch     = "payment:confirmation_watch"
+	TypeDepositProvision      = "deposit:provision"
 )
 
 // Job priority levels
@@ -75,6 +82,21 @@ type PaymentExpiryPayload struct {
 	RunAt time.Time `json:"run_at"`
 }
 
+// PaymentBatchPayload represents the payload for payment batch jobs
+type PaymentBatchPayload struct {
+	BatchID string `json:"batch_id"`
+}
+
+// ExportPayload represents the payload for merchant export jobs
+type ExportPayload struct {
+	ExportID string `json:"export_id"`
+}
+
+// DepositProvisionPayload represents the payload for deposit address provisioning jobs
+type DepositProvisionPayload struct {
+	PaymentID string `json:"payment_id"`
+}
+
 // BalanceCheckPayload represents the payload for balance check jobs
 type BalanceCheckPayload struct {
 	RunAt time.Time `json:"run_at"`
@@ -121,6 +143,16 @@ func (q *Queue) EnqueueWebhookDelivery(ctx context.Context, payload *WebhookDeli
 	return nil
 }
 
+// PublishWebhook enqueues a webhook delivery for a merchant
+// Implements the payment module's domain.WebhookPublisher
+func (q *Queue) PublishWebhook(ctx context.Context, merchantID, event string, data map[string]interface{}) error {
+	return q.EnqueueWebhookDelivery(ctx, &WebhookDeliveryPayload{
+		MerchantID: merchantID,
+		Event:      event,
+		Payload:    data,
+	})
+}
+
 // EnqueuePaymentExpiry enqueues a payment expiry check job
 func (q *Queue) EnqueuePaymentExpiry(ctx context.Context, payload *PaymentExpiryPayload) error {
 	taskPayload, err := json.Marshal(payload)
@@ -149,6 +181,99 @@ func (q *Queue) EnqueuePaymentExpiry(ctx context.Context, payload *PaymentExpiry
 	return nil
 }
 
+// EnqueuePaymentBatch enqueues a job creating the payments of a batch
+// Implements the payment module's domain.PaymentBatchQueue
+func (q *Queue) EnqueuePaymentBatch(ctx context.Context, batchID string) error {
+	taskPayload, err := json.Marshal(&PaymentBatchPayload{BatchID: batchID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment batch payload: %w", err)
+	}
+
+	task := asynq.NewTask(TypePaymentBatch, taskPayload)
+
+	// Items already processed are skipped when the job is retried
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue("batches"),
+		asynq.Timeout(30 * time.Minute),
+		asynq.TaskID(batchID),
+	}
+
+	info, err := q.client.EnqueueContext(ctx, task, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue payment batch job: %w", err)
+	}
+
+	logger.Info("Payment batch job enqueued", logger.Fields{
+		"task_id":  info.ID,
+		"batch_id": batchID,
+	})
+
+	return nil
+}
+
+// EnqueueExport enqueues a job writing the file of a merchant export
+// Implements the export module's domain.ExportQueue
+func (q *Queue) EnqueueExport(ctx context.Context, exportID string) error {
+	taskPayload, err := json.Marshal(&ExportPayload{ExportID: exportID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal export payload: %w", err)
+	}
+
+	task := asynq.NewTask(TypeExport, taskPayload)
+
+	// A retried job writes the whole file again
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Queue("reports"),
+		asynq.Timeout(30 * time.Minute),
+		asynq.TaskID(exportID),
+	}
+
+	info, err := q.client.EnqueueContext(ctx, task, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue export job: %w", err)
+	}
+
+	logger.Info("Export job enqueued", logger.Fields{
+		"task_id":   info.ID,
+		"export_id": exportID,
+	})
+
+	return nil
+}
+
+// EnqueueDepositProvision enqueues a job creating the on-chain account of a payment's deposit address
+// Implements the payment module's domain.DepositAddressQueue
+func (q *Queue) EnqueueDepositProvision(ctx context.Context, paymentID string) error {
+	taskPayload, err := json.Marshal(&DepositProvisionPayload{PaymentID: paymentID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal deposit provision payload: %w", err)
+	}
+
+	task := asynq.NewTask(TypeDepositProvision, taskPayload)
+
+	// The payer cannot pay before the account exists, retries back off quickly
+	opts := []asynq.Option{
+		asynq.MaxRetry(8),
+		asynq.Queue("periodic"),
+		asynq.Timeout(2 * time.Minute),
+		asynq.TaskID(TypeDepositProvision + ":" + paymentID),
+	}
+
+	info, err := q.client.EnqueueContext(ctx, task, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue deposit provision job: %w", err)
+	}
+
+	logger.Info("Deposit provision job enqueued", logger.Fields{
+		"task_id":    info.ID,
+		"payment_id": paymentID,
+	})
+
+	return nil
+}
+
 // EnqueueBalanceCheck enqueues a balance check job
 func (q *Queue) EnqueueBalanceCheck(ctx context.Context, payload *BalanceCheckPayload) error {
 	taskPayload, err := json.Marshal(payload)
@@ -208,7 +333,7 @@ func (q *Queue) EnqueueDailySettlementReport(ctx context.Context, payload *Daily
 
 // GetQueueStats returns statistics for all queues
 func (q *Queue) GetQueueStats(ctx context.Context) (map[string]*asynq.QueueInfo, error) {
-	queues := []string{"webhooks", "webhooks_retry", "periodic", "monitoring", "reports"}
+	queues := []string{"webhooks", "webhooks_retry", "periodic", "batches", "monitoring", "reports"}
 	stats := make(map[string]*asynq.QueueInfo)
 
 	for _, queueName := range queues {
@@ -5,8 +5,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/hibiken/asynq"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/bsc"
 	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/solana"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/blockchain/tron"
+	compliancerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/repository"
+	complianceservice "github.com/hxuan190/stable_payment_gateway/internal/modules/compliance/service"
+	exportrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/export/repository"
+	exportservice "github.com/hxuan190/stable_payment_gateway/internal/modules/export/service"
 	infrastructurerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/repository"
 	infrastructureservice "github.com/hxuan190/stable_payment_gateway/internal/modules/infrastructure/service"
 	balancerepository "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/repository"
@@ -14,12 +21,16 @@ import (
 	ledgerservice "github.com/hxuan190/stable_payment_gateway/internal/modules/ledger/service"
 	merchantrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/merchant/repository"
 	notificationservice "github.com/hxuan190/stable_payment_gateway/internal/modules/notification/service"
+	paymentblockchain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/blockchain"
+	"github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/legacy"
 	paymentrepo "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/adapter/repository"
 	paymentDomain "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/domain"
 	paymentservice "github.com/hxuan190/stable_payment_gateway/internal/modules/payment/service"
 	payoutrepository "github.com/hxuan190/stable_payment_gateway/internal/modules/payout/repository"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/cache"
 	"github.com/hxuan190/stable_payment_gateway/internal/pkg/logger"
+	"github.com/hxuan190/stable_payment_gateway/internal/pkg/storage"
+	"github.com/jmoiron/sqlx"
 	"gorm.io/gorm"
 )
 
@@ -32,7 +43,13 @@ type Server struct {
 	cache                 cache.Cache
 	solanaClient          *solana.Client
 	solanaWallet          *solana.Wallet
+	queue                 *Queue
 	paymentService        *paymentservice.PaymentService
+	refundService         *paymentservice.RefundService
+	subscriptionService   *paymentservice.SubscriptionService
+	paymentBatchService   *paymentservice.PaymentBatchService
+	exportService         *exportservice.ExportService
+	depositSweepService   *paymentservice.DepositSweepService
 	notificationSvc       *notificationservice.NotificationService
 	reconciliationService *infrastructureservice.ReconciliationService
 	merchantRepo          *merchantrepository.MerchantRepository
@@ -50,12 +67,36 @@ type ServerConfig struct {
 	Cache                    cache.Cache
 	SolanaClient             *solana.Client
 	SolanaWallet             *solana.Wallet
+	BSCWallet                *bsc.Wallet
+	SolanaTokenMints         map[string]string // Currency -> SPL mint address, used for refunds
+	BSCTokenContracts        map[string]string // Currency -> BEP20 contract address, used for refunds
+	BSCDepositXPrv           string            // Account xprv of the BSC deposit addresses, used for sweeps
 	Concurrency              int
 	Queues                   map[string]int // Queue name to priority mapping
 	ExchangeRatePrimaryAPI   string
 	ExchangeRateSecondaryAPI string
 	ExchangeRateCacheTTL     time.Duration
 	ExchangeRateTimeout      time.Duration
+
+	// Optional: read-only clients used to verify payment transfers until finality
+	BSCClient          *bsc.Client
+	TRONClient         *tron.Client
+	EVMClients         map[string]*ethclient.Client // EVM network name -> client
+	ConfirmationPolicy paymentDomain.ConfirmationPolicy
+	ReorgWatchWindow   time.Duration
+	OpsTeamEmails      []string // Notified of payment reversals
+
+	// Optional: dunning of subscription billing cycles, defaults to paymentDomain.DefaultDunningPolicy()
+	SubscriptionDunningPolicy paymentDomain.DunningPolicy
+	PaymentPageBaseURL        string // Hosted payment page linked in payer emails
+
+	// Payments issued by the worker (subscription cycles, payment batches) pay into the same wallets as
+	// the API's, chains not listed use the Solana wallet. Accepted tokens are read from the token registry.
+	ChainWallets map[paymentDomain.Chain]string
+
+	// Merchant export files are stored in this bucket, defaults to in-memory storage
+	Storage       storage.StorageService
+	StorageBucket string
 }
 
 // NewServer creates a new worker server instance
@@ -135,26 +176,185 @@ func NewServer(cfg *ServerConfig) *Server {
 		logger.GetLogger().Logger,
 	)
 	ledgerService := ledgerservice.NewLedgerService(ledgerRepo, balanceRepo, cfg.DB)
+	testModeLedgerService := ledgerservice.NewTestModeLedgerService(ledgerRepo, balanceRepo, cfg.DB)
 	notificationService := notificationservice.NewNotificationService(notificationservice.NotificationServiceConfig{
 		Logger:      logger.GetLogger().Logger,
 		HTTPTimeout: 30 * time.Second,
 	})
+	// Queue used by services to publish merchant webhooks
+	queue, _ := NewQueue(&QueueConfig{
+		RedisAddr:     cfg.RedisAddr,
+		RedisPassword: cfg.RedisPassword,
+		RedisDB:       cfg.RedisDB,
+	})
+
+	// Confirming payments are re-verified on-chain until their transfers are final
+	evmClients := make(map[paymentDomain.Chain]paymentblockchain.EVMReader)
+	if cfg.BSCClient != nil {
+		evmClients[paymentDomain.ChainBSC] = cfg.BSCClient.GetEthClient()
+	}
+	for name, client := range cfg.EVMClients {
+		evmClients[paymentDomain.Chain(name)] = client
+	}
+	txVerifier := paymentblockchain.NewTransactionVerifier(paymentblockchain.TransactionVerifierConfig{
+		SolanaClient: cfg.SolanaClient,
+		TRONClient:   cfg.TRONClient,
+		EVMClients:   evmClients,
+	})
+
+	// Reversed payments raise a compliance alert, skipped when the alert repository cannot be built
+	var complianceAlerter paymentDomain.ComplianceAlerter
+	if sqlDB, err := cfg.DB.DB(); err != nil {
+		logger.Error("Failed to initialize compliance alerts for payment reversals", err)
+	} else {
+		complianceAlerter = legacy.NewComplianceAlertAdapter(complianceservice.NewComplianceAlertService(complianceservice.ComplianceAlertServiceConfig{
+			ComplianceAlertRepo: compliancerepository.NewComplianceAlertRepository(sqlx.NewDb(sqlDB, "postgres")),
+			PaymentRepo:         newPaymentRepo,
+			NotificationService: notificationService,
+			Logger:              logger.GetLogger().Logger,
+			OpsTeamEmails:       cfg.OpsTeamEmails,
+		}))
+	}
+
+	walletAddress := ""
+	if cfg.SolanaWallet != nil {
+		walletAddress = cfg.SolanaWallet.GetAddress()
+	}
+
+	subscriptionRepo := paymentrepo.NewPostgresSubscriptionRepository(cfg.DB)
+	tokenRegistry := paymentservice.NewTokenRegistry(
+		paymentrepo.NewPostgresTokenRepository(cfg.DB),
+		paymentservice.DefaultTokenCacheTTL,
+		logger.GetLogger().Logger,
+	)
 	paymentService := paymentservice.NewPaymentService(
 		newPaymentRepo,
-		nil, // merchantAdapter - not needed for worker operations
-		nil,
+		paymentrepo.NewPostgresPaymentTransferRepository(cfg.DB),
+		merchantRepo,
+		legacy.NewExchangeRateServiceAdapter(exchangeRateService),
 		nil,
 		nil,
 		paymentservice.PaymentServiceConfig{
 			DefaultChain:    "solana",
 			DefaultCurrency: "USDT",
-			WalletAddress:   "",
+			WalletAddress:   walletAddress,
+			ChainWallets:    cfg.ChainWallets,
+			TokenRegistry:   tokenRegistry,
 			FeePercentage:   0.01,
 			ExpiryMinutes:   30,
 			RedisClient:     nil,
+			LedgerService:   ledgerService,
+
+			TestModeLedgerService: testModeLedgerService,
+
+			ConfirmationPolicy:   cfg.ConfirmationPolicy,
+			TransactionVerifier:  txVerifier,
+			BlockchainTxRecorder: infrastructurerepository.NewBlockchainTxRepository(cfg.DB),
+			ComplianceAlerter:    complianceAlerter,
+			WebhookPublisher:     queue,
+			ReorgWatchWindow:     cfg.ReorgWatchWindow,
+
+			InvoiceRepository:      paymentrepo.NewPostgresInvoiceRepository(cfg.DB),
+			SubscriptionRepository: subscriptionRepo,
+			PaymentLinkRepository:  paymentrepo.NewPostgresPaymentLinkRepository(cfg.DB),
 		},
 		logger.GetLogger().Logger,
 	)
+
+	// Subscription billing cycles issue payments and email their links to payers
+	subscriptionService := paymentservice.NewSubscriptionService(
+		paymentService,
+		paymentrepo.NewPostgresSubscriptionPlanRepository(cfg.DB),
+		subscriptionRepo,
+		paymentservice.SubscriptionServiceConfig{
+			WebhookPublisher:   queue,
+			EmailSender:        legacy.NewNotificationEmailAdapter(notificationService),
+			DunningPolicy:      cfg.SubscriptionDunningPolicy,
+			PaymentPageBaseURL: cfg.PaymentPageBaseURL,
+		},
+		logger.GetLogger().Logger,
+	)
+
+	// Batches submitted through the API are created here, one payment at a time
+	paymentBatchService := paymentservice.NewPaymentBatchService(
+		paymentService,
+		paymentrepo.NewPostgresPaymentBatchRepository(cfg.DB),
+		paymentservice.PaymentBatchServiceConfig{
+			WebhookPublisher: queue,
+		},
+		logger.GetLogger().Logger,
+	)
+
+	// Exports requested through the API are written here and stored for the merchant to download
+	exportStorage := cfg.Storage
+	if exportStorage == nil {
+		exportStorage = storage.NewMockStorage()
+	}
+	exportService := exportservice.NewExportService(
+		exportrepository.NewPostgresExportRepository(cfg.DB),
+		newPaymentRepo,
+		payoutRepo,
+		ledgerRepo,
+		exportStorage,
+		cfg.StorageBucket,
+		exportservice.ExportServiceConfig{
+			WebhookPublisher: queue,
+			MerchantReader:   merchantRepo,
+			EmailSender:      notificationService,
+		},
+		logger.GetLogger().Logger,
+	)
+
+	refundService := paymentservice.NewRefundService(
+		newPaymentRepo,
+		paymentrepo.NewPostgresPaymentTransferRepository(cfg.DB),
+		paymentrepo.NewPostgresRefundRepository(cfg.DB),
+		ledgerService,
+		paymentservice.RefundServiceConfig{
+			RefundSender: paymentblockchain.NewRefundSender(paymentblockchain.RefundSenderConfig{
+				SolanaWallet:      cfg.SolanaWallet,
+				BSCWallet:         cfg.BSCWallet,
+				SolanaTokenMints:  cfg.SolanaTokenMints,
+				BSCTokenContracts: cfg.BSCTokenContracts,
+			}),
+			WebhookPublisher: queue,
+		},
+		logger.GetLogger().Logger,
+	)
+
+	// Deposit sweeps and provisioning are disabled when the sweeper cannot be built
+	// Provisioning only creates Solana token accounts, the generator needs no BSC xpub
+	var depositSweepService *paymentservice.DepositSweepService
+	depositAddressRepo := paymentrepo.NewPostgresDepositAddressRepository(cfg.DB)
+	var depositProvisioner paymentDomain.DepositAddressGenerator
+	generator, err := paymentblockchain.NewDepositAddressGenerator(depositAddressRepo, paymentblockchain.DepositAddressGeneratorConfig{
+		SolanaWallet:     cfg.SolanaWallet,
+		SolanaTokenMints: cfg.SolanaTokenMints,
+	})
+	if err != nil {
+		logger.Error("Failed to initialize deposit address provisioner", err)
+	} else {
+		depositProvisioner = generator
+	}
+	depositSweeper, err := paymentblockchain.NewDepositSweeper(paymentblockchain.DepositSweeperConfig{
+		SolanaWallet:      cfg.SolanaWallet,
+		BSCWallet:         cfg.BSCWallet,
+		SolanaTokenMints:  cfg.SolanaTokenMints,
+		BSCTokenContracts: cfg.BSCTokenContracts,
+		BSCAccountXPrv:    cfg.BSCDepositXPrv,
+	})
+	if err != nil {
+		logger.Error("Failed to initialize deposit sweeper", err)
+	} else {
+		depositSweepService = paymentservice.NewDepositSweepService(
+			depositAddressRepo,
+			depositSweeper,
+			depositProvisioner,
+			cfg.ReorgWatchWindow,
+			logger.GetLogger().Logger,
+		)
+	}
+
 	reconciliationService := infrastructureservice.NewReconciliationService(
 		cfg.DB,
 		ledgerService,
@@ -172,7 +372,13 @@ func NewServer(cfg *ServerConfig) *Server {
 		cache:                 cfg.Cache,
 		solanaClient:          cfg.SolanaClient,
 		solanaWallet:          cfg.SolanaWallet,
+		queue:                 queue,
 		paymentService:        paymentService,
+		refundService:         refundService,
+		subscriptionService:   subscriptionService,
+		paymentBatchService:   paymentBatchService,
+		exportService:         exportService,
+		depositSweepService:   depositSweepService,
 		notificationSvc:       notificationService,
 		reconciliationService: reconciliationService,
 		merchantRepo:          merchantRepo,
@@ -198,6 +404,15 @@ func (s *Server) registerHandlers() {
 	// Register payment expiry handler
 	s.mux.HandleFunc(TypePaymentExpiry, s.handlePaymentExpiry)
 
+	// Register subscription billing handler
+	s.mux.HandleFunc(TypeSubscriptionBilling, s.handleSubscriptionBilling)
+
+	// Register payment batch handler
+	s.mux.HandleFunc(TypePaymentBatch, s.handlePaymentBatch)
+
+	// Register export handler
+	s.mux.HandleFunc(TypeExport, s.handleExport)
+
 	// Register balance check handler
 	s.mux.HandleFunc(TypeBalanceCheck, s.handleBalanceCheck)
 
@@ -207,13 +422,32 @@ func (s *Server) registerHandlers() {
 	// Register daily reconciliation handler
 	s.mux.HandleFunc(TypeDailyReconciliation, s.handleDailyReconciliation)
 
+	// Register refund processing handler
+	s.mux.HandleFunc(TypeRefundProcess, s.handleRefundProcess)
+
+	// Register deposit sweep handler
+	s.mux.HandleFunc(TypeDepositSweep, s.handleDepositSweep)
+
+	// Register payment confirmation watch handler
+	s.mux.HandleFunc(TypeConfirmationWatch, s.handleConfirmationWatch)
+
+	// Register deposit address provisioning handler
+	s.mux.HandleFunc(TypeDepositProvision, s.handleDepositProvision)
+
 	logger.Info("Worker handlers registered", logger.Fields{
 		"handlers": []string{
 			TypeWebhookDelivery,
 			TypePaymentExpiry,
+			TypeSubscriptionBilling,
+			TypePaymentBatch,
+			TypeExport,
 			TypeBalanceCheck,
 			TypeDailySettlementReport,
 			TypeDailyReconciliation,
+			TypeRefundProcess,
+			TypeDepositSweep,
+			TypeConfirmationWatch,
+			TypeDepositProvision,
 		},
 	})
 }
@@ -234,6 +468,62 @@ func (s *Server) scheduleTasks() {
 		})
 	}
 
+	// Schedule subscription billing every 5 minutes
+	_, err = s.scheduler.Register(
+		"*/5 * * * *", // Every 5 minutes
+		asynq.NewTask(TypeSubscriptionBilling, []byte(`{}`)),
+		asynq.Queue("periodic"),
+	)
+	if err != nil {
+		logger.Error("Failed to schedule subscription billing task", err)
+	} else {
+		logger.Info("Scheduled subscription billing task", logger.Fields{
+			"schedule": "every 5 minutes",
+		})
+	}
+
+	// Schedule refund processing every minute
+	_, err = s.scheduler.Register(
+		"* * * * *", // Every minute
+		asynq.NewTask(TypeRefundProcess, []byte(`{}`)),
+		asynq.Queue("periodic"),
+	)
+	if err != nil {
+		logger.Error("Failed to schedule refund processing task", err)
+	} else {
+		logger.Info("Scheduled refund processing task", logger.Fields{
+			"schedule": "every minute",
+		})
+	}
+
+	// Schedule payment confirmation watch every minute
+	_, err = s.scheduler.Register(
+		"* * * * *", // Every minute
+		asynq.NewTask(TypeConfirmationWatch, []byte(`{}`)),
+		asynq.Queue("periodic"),
+	)
+	if err != nil {
+		logger.Error("Failed to schedule payment confirmation watch task", err)
+	} else {
+		logger.Info("Scheduled payment confirmation watch task", logger.Fields{
+			"schedule": "every minute",
+		})
+	}
+
+	// Schedule deposit sweep every 10 minutes
+	_, err = s.scheduler.Register(
+		"*/10 * * * *", // Every 10 minutes
+		asynq.NewTask(TypeDepositSweep, []byte(`{}`)),
+		asynq.Queue("periodic"),
+	)
+	if err != nil {
+		logger.Error("Failed to schedule deposit sweep task", err)
+	} else {
+		logger.Info("Scheduled deposit sweep task", logger.Fields{
+			"schedule": "every 10 minutes",
+		})
+	}
+
 	// Schedule balance check every 5 minutes
 	_, err = s.scheduler.Register(
 		"*/5 * * * *", // Every 5 minutes
@@ -310,5 +600,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	s.server.Shutdown()
 	logger.Info("Worker server stopped")
 
+	if err := s.queue.Close(); err != nil {
+		logger.Warn("Failed to close job queue", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
 	return nil
 }
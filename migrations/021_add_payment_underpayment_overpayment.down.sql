@@ -0,0 +1,25 @@
+-- Rollback Migration 021: Remove underpayment and overpayment handling
+
+DROP INDEX IF EXISTS idx_payment_transfers_payment_id;
+DROP INDEX IF EXISTS idx_payment_transfers_chain_tx_hash;
+DROP TABLE IF EXISTS payment_transfers;
+
+ALTER TABLE merchants
+DROP COLUMN IF EXISTS overpayment_tolerance_percentage,
+DROP COLUMN IF EXISTS underpayment_tolerance_percentage;
+
+DROP INDEX IF EXISTS idx_payments_underpaid;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS amount_received;
+
+-- Restore previous status constraint (underpaid/overpaid rows fall back to completed/pending)
+UPDATE payments SET status = 'completed' WHERE status = 'overpaid';
+UPDATE payments SET status = 'pending' WHERE status = 'underpaid';
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'confirming', 'completed', 'expired', 'failed'));
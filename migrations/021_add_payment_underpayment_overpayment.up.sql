@@ -0,0 +1,74 @@
+-- Migration 021: Underpayment and overpayment handling
+-- Payments now accumulate every matching on-chain transfer instead of rejecting
+-- any amount that differs from amount_crypto.
+
+-- Add underpaid/overpaid statuses (drop both legacy constraint names)
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS check_payment_status;
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'underpaid', 'confirming', 'completed', 'overpaid', 'expired', 'failed'));
+
+-- Running total of all transfers counted toward the payment
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS amount_received DECIMAL(20, 8) NOT NULL DEFAULT 0;
+
+-- Backfill amount_received for payments that were already paid
+UPDATE payments
+SET amount_received = amount_crypto
+WHERE status IN ('confirming', 'completed') AND tx_hash IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_underpaid
+ON payments(merchant_id, created_at DESC)
+WHERE status = 'underpaid' AND deleted_at IS NULL;
+
+-- Per-merchant tolerance policy (fraction of amount_crypto, 0-10%)
+ALTER TABLE merchants
+ADD COLUMN IF NOT EXISTS underpayment_tolerance_percentage DECIMAL(5, 4) NOT NULL DEFAULT 0
+    CHECK (underpayment_tolerance_percentage >= 0 AND underpayment_tolerance_percentage <= 0.1000),
+ADD COLUMN IF NOT EXISTS overpayment_tolerance_percentage DECIMAL(5, 4) NOT NULL DEFAULT 0
+    CHECK (overpayment_tolerance_percentage >= 0 AND overpayment_tolerance_percentage <= 0.1000);
+
+-- Every on-chain transfer that counts toward a payment
+CREATE TABLE IF NOT EXISTS payment_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL,
+
+    -- Transfer details
+    tx_hash VARCHAR(255) NOT NULL,
+    from_address VARCHAR(255),
+    amount DECIMAL(20, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    chain VARCHAR(20) NOT NULL,
+    confirmations INT NOT NULL DEFAULT 0,
+
+    -- Timestamps
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_payment_transfers_payment
+        FOREIGN KEY (payment_id)
+        REFERENCES payments(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT check_payment_transfer_amount_positive
+        CHECK (amount > 0)
+);
+
+CREATE UNIQUE INDEX idx_payment_transfers_chain_tx_hash ON payment_transfers(chain, tx_hash);
+CREATE INDEX idx_payment_transfers_payment_id ON payment_transfers(payment_id, created_at);
+
+-- Backfill transfers for payments that were already paid
+INSERT INTO payment_transfers (payment_id, tx_hash, from_address, amount, currency, chain, confirmations, created_at)
+SELECT id, tx_hash, from_address, amount_crypto, currency, chain, COALESCE(tx_confirmations, 0), COALESCE(paid_at, updated_at)
+FROM payments
+WHERE status IN ('confirming', 'completed') AND tx_hash IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+COMMENT ON TABLE payment_transfers IS 'Every on-chain transfer counted toward a payment (supports top-ups of underpaid payments)';
+COMMENT ON COLUMN payments.amount_received IS 'Sum of all transfers in payment_transfers for this payment';
+COMMENT ON COLUMN merchants.underpayment_tolerance_percentage IS 'Shortfall (fraction of amount_crypto) still accepted as full payment';
+COMMENT ON COLUMN merchants.overpayment_tolerance_percentage IS 'Excess (fraction of amount_crypto) absorbed without recording a refundable surplus';
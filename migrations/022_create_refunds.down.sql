@@ -0,0 +1,6 @@
+-- Rollback Migration 022: Remove on-chain refunds
+
+DROP INDEX IF EXISTS idx_refunds_tx_hash;
+DROP INDEX IF EXISTS idx_refunds_status;
+DROP INDEX IF EXISTS idx_refunds_payment_id;
+DROP TABLE IF EXISTS refunds;
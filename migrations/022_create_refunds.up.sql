@@ -0,0 +1,56 @@
+-- Migration 022: On-chain refunds
+-- Merchants can refund all or part of a completed payment back to the payer's address.
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL,
+    merchant_id UUID NOT NULL,
+
+    -- Refund amount (crypto sent to the payer, VND debited from the merchant)
+    amount_crypto DECIMAL(20, 8) NOT NULL,
+    amount_vnd DECIMAL(20, 2) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    chain VARCHAR(20) NOT NULL,
+    to_address VARCHAR(255) NOT NULL,
+    reason TEXT,
+
+    -- Status
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+
+    -- Blockchain transaction details
+    tx_hash VARCHAR(255),
+    failure_reason TEXT,
+
+    -- Timing
+    submitted_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    failed_at TIMESTAMP,
+
+    -- Timestamps
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_refunds_payment
+        FOREIGN KEY (payment_id)
+        REFERENCES payments(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_refunds_merchant
+        FOREIGN KEY (merchant_id)
+        REFERENCES merchants(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT check_refund_status
+        CHECK (status IN ('pending', 'submitted', 'completed', 'failed')),
+
+    CONSTRAINT check_refund_amount_positive
+        CHECK (amount_crypto > 0 AND amount_vnd > 0)
+);
+
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id, created_at);
+CREATE INDEX idx_refunds_status ON refunds(status, created_at) WHERE status IN ('pending', 'submitted');
+CREATE UNIQUE INDEX idx_refunds_tx_hash ON refunds(chain, tx_hash) WHERE tx_hash IS NOT NULL;
+
+COMMENT ON TABLE refunds IS 'Crypto refunds sent back to payers of completed payments';
+COMMENT ON COLUMN refunds.amount_vnd IS 'VND debited from the merchant balance (pro-rata share of the payment amount_vnd)';
+COMMENT ON COLUMN refunds.to_address IS 'Payer address the payment was received from';
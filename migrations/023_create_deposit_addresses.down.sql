@@ -0,0 +1,10 @@
+-- Rollback Migration 023: Remove per-payment deposit addresses
+
+DROP INDEX IF EXISTS idx_deposit_addresses_active;
+DROP INDEX IF EXISTS idx_deposit_addresses_address;
+DROP INDEX IF EXISTS idx_deposit_addresses_payment_id;
+DROP TABLE IF EXISTS deposit_addresses;
+DROP SEQUENCE IF EXISTS deposit_address_derivation_index_seq;
+
+ALTER TABLE merchants
+DROP COLUMN IF EXISTS deposit_address_modes;
@@ -0,0 +1,64 @@
+-- Migration 023: Per-payment deposit addresses
+-- Instead of matching transfers to the shared hot wallet by memo, a merchant can have every
+-- payment on a chain receive its own deposit address. BSC addresses are derived from an HD
+-- xpub (m/44'/60'/0'/0/index), Solana deposits are token accounts created with a seed from
+-- the hot wallet. Funds are swept back into the hot wallet once the payment is final.
+
+-- Address mode per chain, e.g. {"bsc": "deposit"}. Chains not listed use memo matching.
+ALTER TABLE merchants
+ADD COLUMN IF NOT EXISTS deposit_address_modes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- BIP-32 child indexes handed out to BSC deposit addresses (non-hardened range)
+CREATE SEQUENCE IF NOT EXISTS deposit_address_derivation_index_seq
+    MINVALUE 0
+    START WITH 0
+    MAXVALUE 2147483647
+    NO CYCLE;
+
+CREATE TABLE IF NOT EXISTS deposit_addresses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL,
+    merchant_id UUID NOT NULL,
+    chain VARCHAR(20) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+
+    -- Derivation details
+    derivation_index BIGINT,
+    seed VARCHAR(32),
+
+    -- Sweep state
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    swept_tx_hash VARCHAR(255),
+    swept_amount DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    swept_at TIMESTAMP,
+    last_sweep_error TEXT,
+
+    -- Timestamps
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_deposit_addresses_payment
+        FOREIGN KEY (payment_id)
+        REFERENCES payments(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT fk_deposit_addresses_merchant
+        FOREIGN KEY (merchant_id)
+        REFERENCES merchants(id)
+        ON DELETE RESTRICT,
+
+    CONSTRAINT check_deposit_address_status
+        CHECK (status IN ('active', 'swept')),
+
+    CONSTRAINT check_deposit_address_derivation
+        CHECK (derivation_index IS NOT NULL OR seed IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_deposit_addresses_payment_id ON deposit_addresses(payment_id);
+CREATE UNIQUE INDEX idx_deposit_addresses_address ON deposit_addresses(chain, address);
+CREATE INDEX idx_deposit_addresses_active ON deposit_addresses(chain, created_at) WHERE status = 'active';
+
+COMMENT ON TABLE deposit_addresses IS 'Addresses generated for a single payment, matched by recipient instead of memo';
+COMMENT ON COLUMN deposit_addresses.derivation_index IS 'BSC: child index under the deposit account xpub (external chain)';
+COMMENT ON COLUMN deposit_addresses.seed IS 'Solana: seed of the token account created from the hot wallet';
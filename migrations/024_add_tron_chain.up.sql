@@ -0,0 +1,23 @@
+-- Migration 024: TRON (TRC-20) payments
+-- Payments, on-chain transactions and wallet snapshots accept the 'tron' chain.
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS check_payment_chain;
+
+ALTER TABLE payments
+ADD CONSTRAINT check_payment_chain
+CHECK (chain IN ('solana', 'bsc', 'ethereum', 'tron'));
+
+ALTER TABLE blockchain_transactions
+DROP CONSTRAINT IF EXISTS check_blockchain_chain;
+
+ALTER TABLE blockchain_transactions
+ADD CONSTRAINT check_blockchain_chain
+CHECK (chain IN ('solana', 'bsc', 'ethereum', 'tron'));
+
+ALTER TABLE wallet_balance_snapshots
+DROP CONSTRAINT IF EXISTS wallet_balance_snapshots_chain_check;
+
+ALTER TABLE wallet_balance_snapshots
+ADD CONSTRAINT wallet_balance_snapshots_chain_check
+CHECK (chain IN ('solana', 'bsc', 'ethereum', 'tron'));
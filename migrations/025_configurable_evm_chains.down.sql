@@ -0,0 +1,23 @@
+-- Rollback Migration 025: Restore the fixed chain list
+-- Fails if rows of configured EVM networks exist, they have to be archived first.
+
+ALTER TABLE wallet_balance_snapshots
+DROP CONSTRAINT IF EXISTS wallet_balance_snapshots_chain_check;
+
+ALTER TABLE wallet_balance_snapshots
+ADD CONSTRAINT wallet_balance_snapshots_chain_check
+CHECK (chain IN ('solana', 'bsc', 'ethereum', 'tron'));
+
+ALTER TABLE blockchain_transactions
+DROP CONSTRAINT IF EXISTS check_blockchain_chain;
+
+ALTER TABLE blockchain_transactions
+ADD CONSTRAINT check_blockchain_chain
+CHECK (chain IN ('solana', 'bsc', 'ethereum', 'tron'));
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS check_payment_chain;
+
+ALTER TABLE payments
+ADD CONSTRAINT check_payment_chain
+CHECK (chain IN ('solana', 'bsc', 'ethereum', 'tron'));
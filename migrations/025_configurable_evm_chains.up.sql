@@ -0,0 +1,24 @@
+-- Migration 025: Configurable EVM networks
+-- EVM networks (Ethereum, Polygon, Arbitrum, ...) are added through configuration,
+-- so chain columns accept any lowercase chain name instead of a fixed list.
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS check_payment_chain;
+
+ALTER TABLE payments
+ADD CONSTRAINT check_payment_chain
+CHECK (chain ~ '^[a-z][a-z0-9_]{0,19}$');
+
+ALTER TABLE blockchain_transactions
+DROP CONSTRAINT IF EXISTS check_blockchain_chain;
+
+ALTER TABLE blockchain_transactions
+ADD CONSTRAINT check_blockchain_chain
+CHECK (chain ~ '^[a-z][a-z0-9_]{0,19}$');
+
+ALTER TABLE wallet_balance_snapshots
+DROP CONSTRAINT IF EXISTS wallet_balance_snapshots_chain_check;
+
+ALTER TABLE wallet_balance_snapshots
+ADD CONSTRAINT wallet_balance_snapshots_chain_check
+CHECK (chain ~ '^[a-z][a-z0-9_]{0,19}$');
@@ -0,0 +1,5 @@
+-- Rollback Migration 026: Remove listener cursors and rescan requests
+
+DROP INDEX IF EXISTS idx_listener_rescan_requests_pending;
+DROP TABLE IF EXISTS listener_rescan_requests;
+DROP TABLE IF EXISTS listener_cursors;
@@ -0,0 +1,53 @@
+-- Migration 026: Persistent listener cursors and rescan requests
+-- Listeners save their last fully processed block (or Solana signature) per wallet so a restart
+-- backfills the missed range instead of starting from the chain head. Operators can force a
+-- rescan of a block range or a single transaction, the listener process picks the request up.
+
+CREATE TABLE IF NOT EXISTS listener_cursors (
+    chain VARCHAR(20) NOT NULL,
+    wallet_address VARCHAR(255) NOT NULL,
+
+    -- Last fully processed block, the slot of the signature on Solana
+    block_number BIGINT NOT NULL DEFAULT 0,
+    -- Newest fully processed signature (Solana only)
+    signature VARCHAR(128),
+
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (chain, wallet_address),
+
+    CONSTRAINT check_listener_cursor_block
+        CHECK (block_number >= 0)
+);
+
+COMMENT ON TABLE listener_cursors IS 'Last fully processed position of each blockchain listener per wallet';
+
+CREATE TABLE IF NOT EXISTS listener_rescan_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chain VARCHAR(20) NOT NULL,
+
+    -- Either a block range (slot range on Solana) or a single transaction
+    from_block BIGINT,
+    to_block BIGINT,
+    tx_hash VARCHAR(255),
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error_message TEXT,
+    requested_by VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP,
+
+    CONSTRAINT check_listener_rescan_status
+        CHECK (status IN ('pending', 'completed', 'failed')),
+
+    CONSTRAINT check_listener_rescan_target
+        CHECK (
+            (tx_hash IS NOT NULL AND from_block IS NULL AND to_block IS NULL)
+            OR (tx_hash IS NULL AND from_block IS NOT NULL AND to_block IS NOT NULL AND from_block >= 0 AND to_block >= from_block)
+        )
+);
+
+CREATE INDEX idx_listener_rescan_requests_pending ON listener_rescan_requests(chain, created_at) WHERE status = 'pending';
+
+COMMENT ON TABLE listener_rescan_requests IS 'Operator requests to process a block range or a transaction again';
@@ -0,0 +1,22 @@
+-- Rollback Migration 027: Remove reorg-aware confirmation policy and payment reversal
+
+DROP INDEX IF EXISTS idx_payments_reversed;
+DROP INDEX IF EXISTS idx_payment_transfers_unfinalized;
+
+ALTER TABLE payment_transfers
+DROP COLUMN IF EXISTS reversed_at,
+DROP COLUMN IF EXISTS missing_checks,
+DROP COLUMN IF EXISTS finalized_at;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS reversed_at;
+
+-- Restore previous status constraint (reversed payments fall back to failed)
+UPDATE payments SET status = 'failed' WHERE status = 'reversed';
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'underpaid', 'confirming', 'completed', 'overpaid', 'expired', 'failed'));
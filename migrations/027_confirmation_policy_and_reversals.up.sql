@@ -0,0 +1,39 @@
+-- Migration 027: Reorg-aware confirmation policy and payment reversal
+-- Payments stay confirming until their transfers reach the depth required for the chain and
+-- amount. A watcher re-verifies transfers until they are finalized; a transfer that disappears
+-- from the chain moves its payment to the terminal reversed status.
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'underpaid', 'confirming', 'completed', 'overpaid', 'expired', 'failed', 'reversed'));
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMP;
+
+-- Inclusion tracking of every transfer by the confirmation watcher
+ALTER TABLE payment_transfers
+ADD COLUMN IF NOT EXISTS finalized_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS missing_checks INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMP;
+
+-- Transfers of payments settled before this migration are not watched again
+UPDATE payment_transfers
+SET finalized_at = created_at
+WHERE finalized_at IS NULL
+  AND payment_id NOT IN (SELECT id FROM payments WHERE status = 'confirming');
+
+CREATE INDEX IF NOT EXISTS idx_payment_transfers_unfinalized
+ON payment_transfers(created_at)
+WHERE finalized_at IS NULL AND reversed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_reversed
+ON payments(merchant_id, reversed_at DESC)
+WHERE status = 'reversed' AND deleted_at IS NULL;
+
+COMMENT ON COLUMN payments.reversed_at IS 'When a transfer of the payment was found reorged out of the chain';
+COMMENT ON COLUMN payment_transfers.finalized_at IS 'When the transfer reached chain finality, the watcher stops checking it';
+COMMENT ON COLUMN payment_transfers.missing_checks IS 'Consecutive watcher checks that did not find the transaction on-chain';
+COMMENT ON COLUMN payment_transfers.reversed_at IS 'When the transfer was confirmed missing and its payment reversed';
@@ -0,0 +1,26 @@
+-- Rollback Migration 028: Remove late payments
+
+ALTER TABLE refunds
+DROP COLUMN IF EXISTS source;
+
+ALTER TABLE merchants
+DROP COLUMN IF EXISTS late_payment_policy;
+
+DROP INDEX IF EXISTS idx_payments_late_paid;
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS check_payment_late_resolution;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS late_resolution,
+DROP COLUMN IF EXISTS late_paid_at;
+
+-- Restore previous status constraint (late payments fall back to expired)
+UPDATE payments SET status = 'expired' WHERE status = 'late_paid';
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'underpaid', 'confirming', 'completed', 'overpaid', 'expired', 'failed', 'reversed'));
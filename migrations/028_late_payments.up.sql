@@ -0,0 +1,37 @@
+-- Migration 028: Late payments
+-- Transfers that arrive after a payment expired are recorded against the payment as late_paid
+-- instead of being dropped. Each merchant chooses whether late payments are accepted at a
+-- re-quoted exchange rate, refunded to the payer, or held for manual review.
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'underpaid', 'confirming', 'completed', 'overpaid', 'expired', 'failed', 'reversed', 'late_paid'));
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS late_paid_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS late_resolution VARCHAR(20);
+
+ALTER TABLE payments
+ADD CONSTRAINT check_payment_late_resolution
+CHECK (late_resolution IS NULL OR late_resolution IN ('pending_review', 'accepted', 'refunding', 'refunded'));
+
+CREATE INDEX IF NOT EXISTS idx_payments_late_paid
+ON payments(late_resolution, late_paid_at DESC)
+WHERE late_paid_at IS NOT NULL AND deleted_at IS NULL;
+
+ALTER TABLE merchants
+ADD COLUMN IF NOT EXISTS late_payment_policy VARCHAR(10) NOT NULL DEFAULT 'review'
+    CHECK (late_payment_policy IN ('accept', 'refund', 'review'));
+
+-- Late payment refunds are paid from the held transfer, not from the merchant balance
+ALTER TABLE refunds
+ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'merchant'
+    CHECK (source IN ('merchant', 'late_payment'));
+
+COMMENT ON COLUMN payments.late_paid_at IS 'When the first transfer arrived after the payment expired';
+COMMENT ON COLUMN payments.late_resolution IS 'pending_review, accepted (re-quoted and completed), refunding or refunded';
+COMMENT ON COLUMN merchants.late_payment_policy IS 'accept at the re-quoted rate, refund to the payer, or review manually';
+COMMENT ON COLUMN refunds.source IS 'merchant (debited from the merchant balance) or late_payment (returns a held late transfer)';
@@ -0,0 +1,6 @@
+-- Rollback Migration 029: Remove inbound transfers
+
+DROP INDEX IF EXISTS idx_inbound_transfers_tx_hash;
+DROP INDEX IF EXISTS idx_inbound_transfers_payment;
+DROP INDEX IF EXISTS idx_inbound_transfers_status;
+DROP TABLE IF EXISTS inbound_transfers;
@@ -0,0 +1,57 @@
+-- Migration 029: Inbound transfers and the unmatched deposit queue
+-- Every stablecoin transfer the listeners see on a watched address is recorded, whether it
+-- confirmed a payment or not. Transfers without a usable reference, or that the payment refused,
+-- stay unmatched until an operator attaches them to a payment, marks them for refund, or books
+-- them as a treasury deposit.
+
+CREATE TABLE IF NOT EXISTS inbound_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chain VARCHAR(20) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+
+    tx_hash VARCHAR(255) NOT NULL,
+    block_number BIGINT,
+    block_time TIMESTAMP,
+
+    from_address VARCHAR(255),
+    to_address VARCHAR(255) NOT NULL,
+    amount DECIMAL(20, 8) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    token_address VARCHAR(255),
+
+    -- Memo carried by the transfer, if any
+    reference VARCHAR(255),
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+
+    status VARCHAR(20) NOT NULL,
+    unmatched_reason VARCHAR(30),
+    unmatched_detail TEXT,
+
+    -- Operator resolution of an unmatched transfer
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMP,
+    resolution_note TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_inbound_transfers_transfer
+        UNIQUE (chain, tx_hash, to_address),
+
+    CONSTRAINT check_inbound_transfer_status
+        CHECK (status IN ('matched', 'unmatched', 'attached', 'refund_pending', 'treasury')),
+
+    CONSTRAINT check_inbound_transfer_unmatched_reason
+        CHECK (unmatched_reason IS NULL OR unmatched_reason IN ('no_reference', 'unknown_reference', 'rejected')),
+
+    CONSTRAINT check_inbound_transfer_amount
+        CHECK (amount > 0)
+);
+
+CREATE INDEX idx_inbound_transfers_status ON inbound_transfers(status, created_at DESC);
+CREATE INDEX idx_inbound_transfers_payment ON inbound_transfers(payment_id) WHERE payment_id IS NOT NULL;
+CREATE INDEX idx_inbound_transfers_tx_hash ON inbound_transfers(tx_hash);
+
+COMMENT ON TABLE inbound_transfers IS 'Every stablecoin transfer received on a watched address, matched to a payment or queued for an operator';
+COMMENT ON COLUMN inbound_transfers.status IS 'matched, unmatched (queued), attached (to a payment by an operator), refund_pending or treasury';
+COMMENT ON COLUMN inbound_transfers.unmatched_reason IS 'no_reference, unknown_reference, or rejected by the payment (see unmatched_detail)';
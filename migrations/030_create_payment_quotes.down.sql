@@ -0,0 +1,10 @@
+-- Rollback Migration 030: Remove rate-locked quotes
+
+ALTER TABLE merchants
+DROP COLUMN IF EXISTS quote_spread_percentage;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS quote_id;
+
+DROP INDEX IF EXISTS idx_payment_quotes_merchant;
+DROP TABLE IF EXISTS payment_quotes;
@@ -0,0 +1,46 @@
+-- Migration 030: Rate-locked quotes
+-- A quote locks the exchange rate for an amount in VND on every supported chain and token
+-- until valid_until. Merchants pass the quote_id when creating the payment to honour the
+-- locked rate. Quotes are kept for audit; each quote can back a single payment.
+
+CREATE TABLE IF NOT EXISTS payment_quotes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+
+    amount_vnd DECIMAL(20, 2) NOT NULL,
+    spread_percentage DECIMAL(6, 4) NOT NULL DEFAULT 0,
+    rate_source VARCHAR(50) NOT NULL,
+
+    -- Locked rate and crypto amount per chain and token
+    options JSONB NOT NULL,
+
+    -- HMAC-SHA256 of the quote terms with the platform quote signing key
+    signature VARCHAR(64) NOT NULL,
+    valid_until TIMESTAMP NOT NULL,
+
+    -- Payment created from the quote
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    used_at TIMESTAMP,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_payment_quote_amount
+        CHECK (amount_vnd > 0),
+
+    CONSTRAINT check_payment_quote_spread
+        CHECK (spread_percentage >= 0 AND spread_percentage <= 0.05)
+);
+
+CREATE INDEX idx_payment_quotes_merchant ON payment_quotes(merchant_id, created_at DESC);
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS quote_id UUID REFERENCES payment_quotes(id) ON DELETE SET NULL;
+
+ALTER TABLE merchants
+ADD COLUMN IF NOT EXISTS quote_spread_percentage DECIMAL(6, 4) NOT NULL DEFAULT 0
+    CHECK (quote_spread_percentage >= 0 AND quote_spread_percentage <= 0.05);
+
+COMMENT ON TABLE payment_quotes IS 'Rate-locked quotes returned by POST /api/v1/quotes, kept for audit';
+COMMENT ON COLUMN payment_quotes.spread_percentage IS 'Merchant spread deducted from the market rate when the quote was issued';
+COMMENT ON COLUMN payments.quote_id IS 'Quote whose locked rate the payment was created at';
+COMMENT ON COLUMN merchants.quote_spread_percentage IS 'Fraction deducted from the market rate on quotes, e.g. 0.005 = 0.5%';
@@ -0,0 +1,11 @@
+-- Rollback Migration 031: Remove fiat pricing currencies
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS check_payment_pricing_amount;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS pricing_net_amount,
+DROP COLUMN IF EXISTS pricing_fee,
+DROP COLUMN IF EXISTS pricing_rate,
+DROP COLUMN IF EXISTS pricing_amount,
+DROP COLUMN IF EXISTS pricing_currency;
@@ -0,0 +1,30 @@
+-- Migration 031: Fiat pricing currencies
+-- Merchants may price payments in a fiat currency other than VND (e.g. USD, EUR). The amount
+-- is kept in the pricing currency next to its VND equivalent, which balances and payouts use.
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS pricing_currency VARCHAR(3) NOT NULL DEFAULT 'VND',
+ADD COLUMN IF NOT EXISTS pricing_amount DECIMAL(20, 2),
+ADD COLUMN IF NOT EXISTS pricing_rate DECIMAL(20, 8) NOT NULL DEFAULT 1,
+ADD COLUMN IF NOT EXISTS pricing_fee DECIMAL(20, 2),
+ADD COLUMN IF NOT EXISTS pricing_net_amount DECIMAL(20, 2);
+
+-- Existing payments were priced in VND
+UPDATE payments
+SET pricing_amount = amount_vnd,
+    pricing_fee = fee_vnd,
+    pricing_net_amount = net_amount_vnd
+WHERE pricing_amount IS NULL;
+
+ALTER TABLE payments
+ALTER COLUMN pricing_amount SET NOT NULL;
+
+ALTER TABLE payments
+ADD CONSTRAINT check_payment_pricing_amount
+CHECK (pricing_amount > 0 AND pricing_rate > 0);
+
+COMMENT ON COLUMN payments.pricing_currency IS 'ISO 4217 currency the merchant priced the payment in';
+COMMENT ON COLUMN payments.pricing_amount IS 'Amount in the pricing currency, amount_vnd is its VND equivalent';
+COMMENT ON COLUMN payments.pricing_rate IS 'VND per unit of the pricing currency when the payment was priced (1 for VND)';
+COMMENT ON COLUMN payments.pricing_fee IS 'Platform fee in the pricing currency, fee_vnd is its VND equivalent';
+COMMENT ON COLUMN payments.pricing_net_amount IS 'Net amount in the pricing currency, net_amount_vnd is its VND equivalent';
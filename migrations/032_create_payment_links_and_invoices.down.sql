@@ -0,0 +1,17 @@
+-- Rollback Migration 032: Remove payment links and invoices
+
+DROP INDEX IF EXISTS idx_payments_invoice;
+DROP INDEX IF EXISTS idx_payments_payment_link;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS invoice_id,
+DROP COLUMN IF EXISTS payment_link_id;
+
+DROP TRIGGER IF EXISTS update_invoices_updated_at ON invoices;
+DROP INDEX IF EXISTS idx_invoices_merchant_status;
+DROP INDEX IF EXISTS idx_invoices_merchant;
+DROP TABLE IF EXISTS invoices;
+
+DROP TRIGGER IF EXISTS update_payment_links_updated_at ON payment_links;
+DROP INDEX IF EXISTS idx_payment_links_merchant;
+DROP TABLE IF EXISTS payment_links;
@@ -0,0 +1,108 @@
+-- Migration 032: Payment links and invoices
+-- A payment link creates a new payment each time a payer opens it, for a fixed amount or one
+-- the payer enters. An invoice bills line items plus tax and can be paid by several payments
+-- until amount_paid reaches total_amount. Amounts are in the pricing currency (see 031).
+
+CREATE TABLE IF NOT EXISTS payment_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+
+    amount_type VARCHAR(10) NOT NULL,
+    pricing_currency VARCHAR(3) NOT NULL DEFAULT 'VND',
+    amount DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    min_amount DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    max_amount DECIMAL(20, 2) NOT NULL DEFAULT 0,
+
+    -- Token and chain of the payments, the payer chooses when NULL
+    currency VARCHAR(10),
+    chain VARCHAR(20),
+
+    description TEXT NOT NULL,
+    metadata JSONB,
+
+    max_uses INTEGER,
+    uses_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_payment_link_amount_type
+        CHECK (amount_type IN ('fixed', 'open')),
+
+    CONSTRAINT check_payment_link_amount
+        CHECK ((amount_type = 'fixed' AND amount > 0) OR (amount_type = 'open' AND amount = 0)),
+
+    CONSTRAINT check_payment_link_uses
+        CHECK (max_uses IS NULL OR (max_uses > 0 AND uses_count <= max_uses)),
+
+    CONSTRAINT check_payment_link_status
+        CHECK (status IN ('active', 'inactive'))
+);
+
+CREATE INDEX idx_payment_links_merchant ON payment_links(merchant_id, created_at DESC);
+
+CREATE TRIGGER update_payment_links_updated_at
+    BEFORE UPDATE ON payment_links
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    invoice_number VARCHAR(50) NOT NULL,
+
+    customer_name VARCHAR(255),
+    customer_email VARCHAR(255),
+    description TEXT,
+
+    pricing_currency VARCHAR(3) NOT NULL DEFAULT 'VND',
+    line_items JSONB NOT NULL,
+    subtotal DECIMAL(20, 2) NOT NULL,
+    tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(20, 2) NOT NULL,
+    amount_paid DECIMAL(20, 2) NOT NULL DEFAULT 0,
+
+    due_date TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    metadata JSONB,
+
+    paid_at TIMESTAMP,
+    voided_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_invoices_merchant_number UNIQUE (merchant_id, invoice_number),
+
+    CONSTRAINT check_invoice_amounts
+        CHECK (total_amount > 0 AND amount_paid >= 0 AND tax_rate >= 0 AND tax_rate <= 1),
+
+    CONSTRAINT check_invoice_status
+        CHECK (status IN ('open', 'partially_paid', 'paid', 'void'))
+);
+
+CREATE INDEX idx_invoices_merchant ON invoices(merchant_id, created_at DESC);
+CREATE INDEX idx_invoices_merchant_status ON invoices(merchant_id, status);
+
+CREATE TRIGGER update_invoices_updated_at
+    BEFORE UPDATE ON invoices
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS payment_link_id UUID REFERENCES payment_links(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS invoice_id UUID REFERENCES invoices(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_payment_link ON payments(payment_link_id) WHERE payment_link_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payments_invoice ON payments(invoice_id) WHERE invoice_id IS NOT NULL;
+
+COMMENT ON TABLE payment_links IS 'Reusable links that create a payment each time a payer opens them';
+COMMENT ON COLUMN payment_links.amount_type IS 'fixed: every payment is for amount, open: the payer enters the amount within min_amount/max_amount (0 = no bound)';
+COMMENT ON COLUMN payment_links.uses_count IS 'Payments created from the link, capped by max_uses when set';
+COMMENT ON TABLE invoices IS 'Merchant invoices with line items and tax, payable by one or more payments';
+COMMENT ON COLUMN invoices.amount_paid IS 'Sum of the completed payments of the invoice in the pricing currency';
+COMMENT ON COLUMN payments.payment_link_id IS 'Payment link the payment was created from';
+COMMENT ON COLUMN payments.invoice_id IS 'Invoice the payment pays';
@@ -0,0 +1,21 @@
+-- Rollback Migration 033: Remove subscriptions
+
+DROP INDEX IF EXISTS idx_payments_subscription_cycle;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS subscription_cycle_id;
+
+DROP TRIGGER IF EXISTS update_subscription_cycles_updated_at ON subscription_cycles;
+DROP INDEX IF EXISTS idx_subscription_cycles_next_attempt;
+DROP INDEX IF EXISTS idx_subscription_cycles_subscription;
+DROP TABLE IF EXISTS subscription_cycles;
+
+DROP TRIGGER IF EXISTS update_subscriptions_updated_at ON subscriptions;
+DROP INDEX IF EXISTS idx_subscriptions_next_billing;
+DROP INDEX IF EXISTS idx_subscriptions_merchant_status;
+DROP INDEX IF EXISTS idx_subscriptions_merchant;
+DROP TABLE IF EXISTS subscriptions;
+
+DROP TRIGGER IF EXISTS update_subscription_plans_updated_at ON subscription_plans;
+DROP INDEX IF EXISTS idx_subscription_plans_merchant;
+DROP TABLE IF EXISTS subscription_plans;
@@ -0,0 +1,133 @@
+-- Migration 033: Subscriptions
+-- A subscription plan bills a fixed amount every interval. Each billing cycle of a subscription
+-- issues a payment and sends the payer its link. Unpaid cycles are retried on the dunning schedule
+-- and the subscription is canceled once the grace period ends without a completed payment.
+
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+
+    pricing_currency VARCHAR(3) NOT NULL DEFAULT 'VND',
+    amount DECIMAL(20, 2) NOT NULL,
+    billing_interval VARCHAR(10) NOT NULL,
+    interval_count INTEGER NOT NULL DEFAULT 1,
+
+    -- Token and chain of the payments, the payer chooses when NULL
+    currency VARCHAR(10),
+    chain VARCHAR(20),
+
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    metadata JSONB,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_subscription_plan_amount
+        CHECK (amount > 0),
+
+    CONSTRAINT check_subscription_plan_interval
+        CHECK (billing_interval IN ('day', 'week', 'month', 'year') AND interval_count > 0),
+
+    CONSTRAINT check_subscription_plan_status
+        CHECK (status IN ('active', 'archived'))
+);
+
+CREATE INDEX idx_subscription_plans_merchant ON subscription_plans(merchant_id, created_at DESC);
+
+CREATE TRIGGER update_subscription_plans_updated_at
+    BEFORE UPDATE ON subscription_plans
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES subscription_plans(id),
+
+    customer_name VARCHAR(255),
+    customer_email VARCHAR(255),
+    customer_reference VARCHAR(255),
+
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    current_period_start TIMESTAMP NOT NULL,
+    current_period_end TIMESTAMP NOT NULL,
+    next_billing_at TIMESTAMP NOT NULL,
+    cycles_count INTEGER NOT NULL DEFAULT 0,
+
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    canceled_at TIMESTAMP,
+    cancellation_reason VARCHAR(50),
+
+    metadata JSONB,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_subscription_status
+        CHECK (status IN ('active', 'past_due', 'canceled')),
+
+    CONSTRAINT check_subscription_period
+        CHECK (current_period_end > current_period_start)
+);
+
+CREATE INDEX idx_subscriptions_merchant ON subscriptions(merchant_id, created_at DESC);
+CREATE INDEX idx_subscriptions_merchant_status ON subscriptions(merchant_id, status);
+CREATE INDEX idx_subscriptions_next_billing ON subscriptions(next_billing_at) WHERE status IN ('active', 'past_due');
+
+CREATE TRIGGER update_subscriptions_updated_at
+    BEFORE UPDATE ON subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS subscription_cycles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    cycle_number INTEGER NOT NULL,
+
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    pricing_currency VARCHAR(3) NOT NULL,
+    amount DECIMAL(20, 2) NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    due_at TIMESTAMP NOT NULL,
+    next_attempt_at TIMESTAMP,
+    grace_ends_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- A cycle is issued once even if several workers bill the subscription
+    CONSTRAINT uq_subscription_cycles_number UNIQUE (subscription_id, cycle_number),
+
+    CONSTRAINT check_subscription_cycle_status
+        CHECK (status IN ('open', 'paid', 'uncollectible', 'void'))
+);
+
+CREATE INDEX idx_subscription_cycles_subscription ON subscription_cycles(subscription_id, cycle_number DESC);
+CREATE INDEX idx_subscription_cycles_next_attempt ON subscription_cycles(next_attempt_at) WHERE status = 'open';
+
+CREATE TRIGGER update_subscription_cycles_updated_at
+    BEFORE UPDATE ON subscription_cycles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS subscription_cycle_id UUID REFERENCES subscription_cycles(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_subscription_cycle ON payments(subscription_cycle_id) WHERE subscription_cycle_id IS NOT NULL;
+
+COMMENT ON TABLE subscription_plans IS 'Recurring billing plans, amount is billed every interval_count billing_interval';
+COMMENT ON TABLE subscriptions IS 'Customers subscribed to a plan, next_billing_at is when the next cycle is issued';
+COMMENT ON COLUMN subscriptions.status IS 'active: paid up, past_due: a cycle is unpaid and in dunning, canceled: no further cycles';
+COMMENT ON TABLE subscription_cycles IS 'Billing cycles of a subscription, each paid by one of the payments issued for it';
+COMMENT ON COLUMN subscription_cycles.payment_id IS 'Payment of the current attempt, NULL between a failed attempt and its retry';
+COMMENT ON COLUMN subscription_cycles.next_attempt_at IS 'When the worker next checks the cycle: payment expiry, retry or grace period end';
+COMMENT ON COLUMN payments.subscription_cycle_id IS 'Subscription billing cycle the payment was issued for';
@@ -0,0 +1,4 @@
+-- Rollback Migration 034: Remove split payments
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS splits;
@@ -0,0 +1,9 @@
+-- Migration 034: Split payments
+-- A marketplace merchant divides the net amount of a payment among its sellers and its own
+-- commission. The resolved shares are kept on the payment and posted to the ledger when the
+-- payment completes; refunds reverse them proportionally.
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS splits JSONB;
+
+COMMENT ON COLUMN payments.splits IS 'Shares of net_amount_vnd credited to sellers and the merchant commission, NULL when not split';
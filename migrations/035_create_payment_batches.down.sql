@@ -0,0 +1,5 @@
+-- Rollback Migration 035: Remove payment batches
+
+DROP TRIGGER IF EXISTS update_payment_batches_updated_at ON payment_batches;
+DROP INDEX IF EXISTS idx_payment_batches_merchant;
+DROP TABLE IF EXISTS payment_batches;
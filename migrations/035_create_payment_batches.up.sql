@@ -0,0 +1,36 @@
+-- Migration 035: Payment batches
+-- Merchants submit many payment requests at once. Batches created asynchronously are kept here
+-- while the worker creates their payments; each item stores its request and its outcome.
+
+CREATE TABLE IF NOT EXISTS payment_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+
+    -- Array of payment requests, each with its status, payment_id or error
+    items JSONB NOT NULL,
+    total_count INTEGER NOT NULL,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP,
+
+    CONSTRAINT check_payment_batch_status
+        CHECK (status IN ('pending', 'processing', 'completed')),
+
+    CONSTRAINT check_payment_batch_counts
+        CHECK (total_count > 0 AND created_count >= 0 AND failed_count >= 0 AND created_count + failed_count <= total_count)
+);
+
+CREATE INDEX idx_payment_batches_merchant ON payment_batches(merchant_id, created_at DESC);
+
+CREATE TRIGGER update_payment_batches_updated_at
+    BEFORE UPDATE ON payment_batches
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE payment_batches IS 'Payment requests submitted together and created by the worker';
+COMMENT ON COLUMN payment_batches.items IS 'Payment requests in submission order with their outcome';
@@ -0,0 +1,16 @@
+-- Rollback Migration 036: Remove merchant cancellation and expiry extension
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS expiry_extensions,
+DROP COLUMN IF EXISTS cancel_reason,
+DROP COLUMN IF EXISTS canceled_at;
+
+-- Restore previous status constraint (canceled payments fall back to expired)
+UPDATE payments SET status = 'expired' WHERE status = 'canceled';
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'underpaid', 'confirming', 'completed', 'overpaid', 'expired', 'failed', 'reversed', 'late_paid'));
@@ -0,0 +1,19 @@
+-- Migration 036: Merchant cancellation and expiry extension
+-- Merchants can cancel a payment that was not paid yet, or extend its expiry at a re-quoted
+-- exchange rate a limited number of times.
+
+ALTER TABLE payments
+DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('created', 'pending', 'pending_compliance', 'underpaid', 'confirming', 'completed', 'overpaid', 'expired', 'failed', 'reversed', 'late_paid', 'canceled'));
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS cancel_reason VARCHAR(255),
+ADD COLUMN IF NOT EXISTS expiry_extensions INTEGER NOT NULL DEFAULT 0 CHECK (expiry_extensions >= 0);
+
+COMMENT ON COLUMN payments.canceled_at IS 'When the merchant canceled the payment';
+COMMENT ON COLUMN payments.cancel_reason IS 'Reason given by the merchant when canceling';
+COMMENT ON COLUMN payments.expiry_extensions IS 'Number of times the merchant extended the expiry, each at a re-quoted rate';
@@ -0,0 +1,6 @@
+-- Rollback Migration 037: Remove payment status history and optimistic locking
+
+DROP TABLE IF EXISTS payment_status_history;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS version;
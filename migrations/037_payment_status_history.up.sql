@@ -0,0 +1,32 @@
+-- Migration 037: Payment status history and optimistic locking
+-- Every status change of a payment goes through the payment state machine and is recorded with
+-- who made it and why. Updates are conditional on the version read, so that the listener and the
+-- workers cannot overwrite each other's changes.
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1 CHECK (version > 0);
+
+COMMENT ON COLUMN payments.version IS 'Optimistic lock, incremented by every update';
+
+CREATE TABLE IF NOT EXISTS payment_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+
+    -- system, listener, worker, merchant or admin
+    actor VARCHAR(20) NOT NULL,
+    actor_id VARCHAR(255),
+    reason TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_payment_status_history_actor
+        CHECK (actor IN ('system', 'listener', 'worker', 'merchant', 'admin'))
+);
+
+CREATE INDEX idx_payment_status_history_payment ON payment_status_history(payment_id, created_at);
+
+COMMENT ON TABLE payment_status_history IS 'Status transitions of payments, written with the status change';
+COMMENT ON COLUMN payment_status_history.actor_id IS 'Merchant or operator that made the transition';
@@ -0,0 +1,9 @@
+-- Rollback Migration 038: Remove payment search indexes
+
+DROP INDEX IF EXISTS idx_payments_search_amount;
+DROP INDEX IF EXISTS idx_payments_search_created;
+DROP INDEX IF EXISTS idx_payments_search_merchant_from_address;
+DROP INDEX IF EXISTS idx_payments_search_merchant_confirmed;
+DROP INDEX IF EXISTS idx_payments_search_merchant_status_created;
+DROP INDEX IF EXISTS idx_payments_search_merchant_amount;
+DROP INDEX IF EXISTS idx_payments_search_merchant_created;
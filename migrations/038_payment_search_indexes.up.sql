@@ -0,0 +1,28 @@
+-- Migration 038: Payment search indexes
+-- Payment searches page with a keyset cursor on (sort column, id), so every sort order needs an
+-- index ending with id. Filters on metadata use the existing GIN index idx_payments_metadata_gin.
+
+-- Merchant searches, newest first and by amount
+CREATE INDEX IF NOT EXISTS idx_payments_search_merchant_created
+ON payments(merchant_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_search_merchant_amount
+ON payments(merchant_id, amount_vnd, id) WHERE deleted_at IS NULL;
+
+-- Merchant searches filtered by status
+CREATE INDEX IF NOT EXISTS idx_payments_search_merchant_status_created
+ON payments(merchant_id, status, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+-- Confirmed date range and payer address filters
+CREATE INDEX IF NOT EXISTS idx_payments_search_merchant_confirmed
+ON payments(merchant_id, confirmed_at) WHERE confirmed_at IS NOT NULL AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_search_merchant_from_address
+ON payments(merchant_id, from_address) WHERE from_address IS NOT NULL AND deleted_at IS NULL;
+
+-- Admin searches across every merchant
+CREATE INDEX IF NOT EXISTS idx_payments_search_created
+ON payments(created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_search_amount
+ON payments(amount_vnd, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- Rollback Migration 039: Remove merchant exports
+
+DROP INDEX IF EXISTS idx_ledger_entries_merchant_created;
+DROP INDEX IF EXISTS idx_payouts_merchant_created;
+DROP TRIGGER IF EXISTS update_exports_updated_at ON exports;
+DROP INDEX IF EXISTS idx_exports_merchant;
+DROP TABLE IF EXISTS exports;
@@ -0,0 +1,49 @@
+-- Migration 039: Merchant exports
+-- Merchants export their payments, payouts or ledger entries of a date range to CSV or XLSX.
+-- The worker writes the file to storage; the API hands out time-limited download URLs for it.
+
+CREATE TABLE IF NOT EXISTS exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+
+    type VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+
+    -- Date range and filters the records were selected with
+    filter JSONB NOT NULL,
+
+    -- Storage key of the file, set once completed
+    file_key VARCHAR(500),
+    file_size BIGINT NOT NULL DEFAULT 0,
+    row_count INTEGER NOT NULL DEFAULT 0,
+
+    error TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP,
+
+    CONSTRAINT check_export_type
+        CHECK (type IN ('payments', 'payouts', 'ledger_entries')),
+
+    CONSTRAINT check_export_format
+        CHECK (format IN ('csv', 'xlsx')),
+
+    CONSTRAINT check_export_status
+        CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
+
+CREATE INDEX idx_exports_merchant ON exports(merchant_id, created_at DESC);
+
+-- Payouts and ledger entries of a merchant are exported by date range
+CREATE INDEX IF NOT EXISTS idx_payouts_merchant_created ON payouts(merchant_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_merchant_created ON ledger_entries(merchant_id, created_at, id) WHERE merchant_id IS NOT NULL;
+
+CREATE TRIGGER update_exports_updated_at
+    BEFORE UPDATE ON exports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE exports IS 'Merchant exports of payments, payouts and ledger entries written by the worker';
+COMMENT ON COLUMN exports.filter IS 'Date range [from, to) and filters of the exported records';
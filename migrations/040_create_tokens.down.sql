@@ -0,0 +1,7 @@
+-- Rollback Migration 040: Remove the token registry
+
+ALTER TABLE travel_rule_data ADD CONSTRAINT check_travel_rule_currency
+    CHECK (transaction_currency IN ('USD', 'USDT', 'USDC', 'BUSD')) NOT VALID;
+
+DROP TRIGGER IF EXISTS update_tokens_updated_at ON tokens;
+DROP TABLE IF EXISTS tokens;
@@ -0,0 +1,60 @@
+-- Migration 040: Token registry
+-- The tokens accepted on each chain, managed by admins instead of being hard-coded.
+-- Payment validation, the exchange rate lookup and the listeners read the enabled tokens;
+-- an empty address is filled from the deployment configuration when the services start.
+
+CREATE TABLE IF NOT EXISTS tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    chain VARCHAR(20) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+
+    -- SPL mint on Solana, contract address on BSC, TRON and EVM networks
+    address VARCHAR(100) NOT NULL DEFAULT '',
+    decimals SMALLINT NOT NULL,
+
+    -- Currency the token is pegged to and the rate feed it is priced with
+    peg_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    price_source VARCHAR(20) NOT NULL DEFAULT 'usdt',
+
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Bounds of the crypto amount of a payment, 0 means unbounded
+    min_amount DECIMAL(30, 8) NOT NULL DEFAULT 0,
+    max_amount DECIMAL(30, 8) NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_tokens_chain_symbol UNIQUE (chain, symbol),
+
+    CONSTRAINT check_token_decimals
+        CHECK (decimals BETWEEN 0 AND 36),
+
+    CONSTRAINT check_token_price_source
+        CHECK (price_source IN ('usdt', 'usdc', 'peg')),
+
+    CONSTRAINT check_token_amounts
+        CHECK (min_amount >= 0 AND max_amount >= 0 AND (max_amount = 0 OR max_amount >= min_amount))
+);
+
+CREATE TRIGGER update_tokens_updated_at
+    BEFORE UPDATE ON tokens
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Tokens accepted before the registry existed, BUSD keeps being priced with the USDT rate
+INSERT INTO tokens (chain, symbol, decimals, price_source) VALUES
+    ('solana', 'USDT', 6, 'usdt'),
+    ('solana', 'USDC', 6, 'usdc'),
+    ('bsc', 'USDT', 18, 'usdt'),
+    ('bsc', 'BUSD', 18, 'usdt'),
+    ('tron', 'USDT', 6, 'usdt'),
+    ('tron', 'USDC', 6, 'usdc')
+ON CONFLICT (chain, symbol) DO NOTHING;
+
+-- Travel rule data is recorded in the currency of the payment, which may now be any registry token
+ALTER TABLE travel_rule_data DROP CONSTRAINT IF EXISTS check_travel_rule_currency;
+
+COMMENT ON TABLE tokens IS 'Tokens accepted per chain, read by payment validation, rate lookup and the listeners';
+COMMENT ON COLUMN tokens.price_source IS 'usdt or usdc use that token''s VND rate, peg uses the VND rate of peg_currency';
@@ -0,0 +1,6 @@
+-- Rollback Migration 041: Remove Solana Pay references
+
+DROP INDEX IF EXISTS idx_payments_solana_pay_reference;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS solana_pay_reference;
@@ -0,0 +1,12 @@
+-- Migration 041: Solana Pay transaction requests
+-- Wallets fetch a server-composed transaction for a payment; it carries a read-only reference key
+-- derived from the payment, which the listener looks up to match transfers without relying on the memo.
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS solana_pay_reference VARCHAR(44);
+
+CREATE INDEX IF NOT EXISTS idx_payments_solana_pay_reference
+    ON payments(solana_pay_reference)
+    WHERE solana_pay_reference IS NOT NULL;
+
+COMMENT ON COLUMN payments.solana_pay_reference IS 'Reference public key of the Solana Pay transactions composed for the payment';
@@ -0,0 +1,18 @@
+-- Rollback Migration 042: Remove sandbox merchants
+-- Sandbox merchants share their live merchant's email: remove them and their test payments and payouts
+-- before rolling back, or restoring the unique email constraint fails
+
+ALTER TABLE payouts
+DROP COLUMN IF EXISTS test_mode;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS test_mode;
+
+DROP INDEX IF EXISTS idx_merchants_live_email;
+
+ALTER TABLE merchants ADD CONSTRAINT merchants_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_merchants_sandbox_of;
+
+ALTER TABLE merchants
+DROP COLUMN IF EXISTS sandbox_of;
@@ -0,0 +1,28 @@
+-- Migration 042: Sandbox merchants
+-- A sandbox merchant is a shadow row of a live merchant, authenticated with an sk_test_ API key.
+-- Its payments and payouts are flagged test_mode: listeners never match them, they are settled by
+-- simulation and booked to the sandbox merchant's own ledger accounts.
+
+ALTER TABLE merchants
+ADD COLUMN IF NOT EXISTS sandbox_of UUID REFERENCES merchants(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_sandbox_of
+    ON merchants(sandbox_of)
+    WHERE sandbox_of IS NOT NULL;
+
+-- The sandbox shares its live merchant's email, so emails are only unique among live merchants
+ALTER TABLE merchants DROP CONSTRAINT IF EXISTS merchants_email_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_live_email
+    ON merchants(email)
+    WHERE sandbox_of IS NULL;
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE payouts
+ADD COLUMN IF NOT EXISTS test_mode BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN merchants.sandbox_of IS 'Live merchant this sandbox merchant belongs to, NULL for live merchants';
+COMMENT ON COLUMN payments.test_mode IS 'Payment of a sandbox merchant, settled by simulation instead of on-chain transfers';
+COMMENT ON COLUMN payouts.test_mode IS 'Payout of a sandbox merchant, never sent to a bank';
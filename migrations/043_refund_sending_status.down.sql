@@ -0,0 +1,17 @@
+-- Rollback Migration 043: Remove the refund sending status
+-- Refunds still in sending go back to submitted when they have a transaction hash and to pending otherwise
+
+UPDATE refunds SET status = 'submitted' WHERE status = 'sending' AND tx_hash IS NOT NULL;
+UPDATE refunds SET status = 'pending' WHERE status = 'sending';
+
+DROP INDEX IF EXISTS idx_refunds_status;
+CREATE INDEX idx_refunds_status ON refunds(status, created_at) WHERE status IN ('pending', 'submitted');
+
+ALTER TABLE refunds
+DROP CONSTRAINT IF EXISTS check_refund_status;
+
+ALTER TABLE refunds
+ADD CONSTRAINT check_refund_status
+    CHECK (status IN ('pending', 'submitted', 'completed', 'failed'));
+
+COMMENT ON COLUMN refunds.status IS NULL;
@@ -0,0 +1,16 @@
+-- Migration 043: Refund sending status
+-- A worker claims a pending refund by moving it to sending before the transfer is signed, so no two
+-- runs send the same refund. The transaction hash is saved before the broadcast; refunds left in
+-- sending are reconciled against the chain by that hash instead of being sent again.
+
+ALTER TABLE refunds
+DROP CONSTRAINT IF EXISTS check_refund_status;
+
+ALTER TABLE refunds
+ADD CONSTRAINT check_refund_status
+    CHECK (status IN ('pending', 'sending', 'submitted', 'completed', 'failed'));
+
+DROP INDEX IF EXISTS idx_refunds_status;
+CREATE INDEX idx_refunds_status ON refunds(status, created_at) WHERE status IN ('pending', 'sending', 'submitted');
+
+COMMENT ON COLUMN refunds.status IS 'pending, sending (claimed by a worker), submitted, completed or failed';
@@ -0,0 +1,17 @@
+-- Rollback Migration 044: Remove the deposit address sweeping status
+
+-- Addresses left sweeping are retried by the next sweep
+UPDATE deposit_addresses SET status = 'active' WHERE status = 'sweeping';
+
+DROP INDEX IF EXISTS idx_deposit_addresses_swept_at;
+DROP INDEX IF EXISTS idx_deposit_addresses_active;
+CREATE INDEX idx_deposit_addresses_active ON deposit_addresses(chain, created_at) WHERE status = 'active';
+
+ALTER TABLE deposit_addresses
+DROP CONSTRAINT IF EXISTS check_deposit_address_status;
+
+ALTER TABLE deposit_addresses
+ADD CONSTRAINT check_deposit_address_status
+    CHECK (status IN ('active', 'swept'));
+
+COMMENT ON COLUMN deposit_addresses.status IS NULL;
@@ -0,0 +1,17 @@
+-- Migration 044: Deposit address sweeping status
+-- A sweep claims the deposit address by moving it to sweeping before the gas top-up and the transfer
+-- are sent, so an address whose status could not be saved after its sweep is not swept twice.
+-- Swept addresses stay watched by the listeners for a retention window after their sweep.
+
+ALTER TABLE deposit_addresses
+DROP CONSTRAINT IF EXISTS check_deposit_address_status;
+
+ALTER TABLE deposit_addresses
+ADD CONSTRAINT check_deposit_address_status
+    CHECK (status IN ('active', 'sweeping', 'swept'));
+
+DROP INDEX IF EXISTS idx_deposit_addresses_active;
+CREATE INDEX idx_deposit_addresses_active ON deposit_addresses(chain, created_at) WHERE status IN ('active', 'sweeping');
+CREATE INDEX IF NOT EXISTS idx_deposit_addresses_swept_at ON deposit_addresses(chain, swept_at) WHERE status = 'swept';
+
+COMMENT ON COLUMN deposit_addresses.status IS 'active, sweeping (claimed by a sweep) or swept';
@@ -0,0 +1,4 @@
+-- Rollback Migration 045: Remove ownership challenges
+
+DROP INDEX IF EXISTS idx_ownership_challenges_wallet;
+DROP TABLE IF EXISTS ownership_challenges;
@@ -0,0 +1,22 @@
+-- Migration 045: Ownership challenges
+-- EVM payers prove they own a wallet by signing a challenge with a nonce. The gateway issues the
+-- nonces and stores them here; a signature is only accepted with an unexpired nonce issued for the
+-- signing wallet and chain, and each nonce is claimed once, so a signature cannot be replayed.
+-- Challenges are kept for audit.
+
+CREATE TABLE IF NOT EXISTS ownership_challenges (
+    nonce VARCHAR(64) PRIMARY KEY,
+    chain VARCHAR(20) NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ownership_challenges_wallet ON ownership_challenges(wallet_address, created_at DESC);
+
+COMMENT ON TABLE ownership_challenges IS 'Nonces issued by POST /api/v1/ownership-challenges, each accepted once';
+COMMENT ON COLUMN ownership_challenges.wallet_address IS 'Lower case hex address the challenge was issued to';
+COMMENT ON COLUMN ownership_challenges.used_at IS 'When a signature of the challenge was accepted';
@@ -0,0 +1,18 @@
+-- Rollback Migration 046: Remove inbound transfer refunds
+
+-- Refunded transfers go back to waiting for their refund
+UPDATE inbound_transfers SET status = 'refund_pending' WHERE status = 'refunded';
+
+ALTER TABLE inbound_transfers
+DROP CONSTRAINT IF EXISTS check_inbound_transfer_status;
+
+ALTER TABLE inbound_transfers
+ADD CONSTRAINT check_inbound_transfer_status
+    CHECK (status IN ('matched', 'unmatched', 'attached', 'refund_pending', 'treasury'));
+
+COMMENT ON COLUMN inbound_transfers.status IS 'matched, unmatched (queued), attached (to a payment by an operator), refund_pending or treasury';
+
+ALTER TABLE inbound_transfers
+DROP COLUMN IF EXISTS refunded_at,
+DROP COLUMN IF EXISTS refunded_by,
+DROP COLUMN IF EXISTS refund_tx_hash;
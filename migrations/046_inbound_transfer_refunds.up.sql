@@ -0,0 +1,18 @@
+-- Migration 046: Inbound transfer refunds
+-- A transfer an operator marked for refund stayed refund_pending forever. Once the funds are sent
+-- back to the sender, the operator records the refund transaction and the transfer becomes refunded.
+
+ALTER TABLE inbound_transfers
+ADD COLUMN IF NOT EXISTS refund_tx_hash VARCHAR(255),
+ADD COLUMN IF NOT EXISTS refunded_by VARCHAR(255),
+ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP;
+
+ALTER TABLE inbound_transfers
+DROP CONSTRAINT IF EXISTS check_inbound_transfer_status;
+
+ALTER TABLE inbound_transfers
+ADD CONSTRAINT check_inbound_transfer_status
+    CHECK (status IN ('matched', 'unmatched', 'attached', 'refund_pending', 'refunded', 'treasury'));
+
+COMMENT ON COLUMN inbound_transfers.status IS 'matched, unmatched (queued), attached (to a payment by an operator), refund_pending, refunded or treasury';
+COMMENT ON COLUMN inbound_transfers.refund_tx_hash IS 'Transaction that sent a refund_pending transfer back to the sender';
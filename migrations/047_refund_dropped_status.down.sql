@@ -0,0 +1,16 @@
+-- Rollback Migration 047: Remove the refund dropped status
+-- Dropped refunds go back to submitted, where their transaction is checked on-chain again
+
+UPDATE refunds SET status = 'submitted' WHERE status = 'dropped';
+
+DROP INDEX IF EXISTS idx_refunds_status;
+CREATE INDEX idx_refunds_status ON refunds(status, created_at) WHERE status IN ('pending', 'sending', 'submitted');
+
+ALTER TABLE refunds
+DROP CONSTRAINT IF EXISTS check_refund_status;
+
+ALTER TABLE refunds
+ADD CONSTRAINT check_refund_status
+    CHECK (status IN ('pending', 'sending', 'submitted', 'completed', 'failed'));
+
+COMMENT ON COLUMN refunds.status IS 'pending, sending (claimed by a worker), submitted, completed or failed';
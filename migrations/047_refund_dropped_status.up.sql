@@ -0,0 +1,16 @@
+-- Migration 047: Refund dropped status
+-- A refund transaction the chain still does not know long after it was broadcast is marked dropped.
+-- Solana refunds are signed again once their blockhash expired instead; on other chains the
+-- transaction may still land, so the reserved balance is kept until an operator resolves the refund.
+
+ALTER TABLE refunds
+DROP CONSTRAINT IF EXISTS check_refund_status;
+
+ALTER TABLE refunds
+ADD CONSTRAINT check_refund_status
+    CHECK (status IN ('pending', 'sending', 'submitted', 'completed', 'failed', 'dropped'));
+
+DROP INDEX IF EXISTS idx_refunds_status;
+CREATE INDEX idx_refunds_status ON refunds(status, created_at) WHERE status IN ('pending', 'sending', 'submitted', 'dropped');
+
+COMMENT ON COLUMN refunds.status IS 'pending, sending (claimed by a worker), submitted, completed, failed or dropped (transaction not found on-chain, needs an operator)';
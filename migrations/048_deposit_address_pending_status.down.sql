@@ -0,0 +1,13 @@
+-- Rollback Migration 048: Remove the deposit address pending status
+
+-- Watching an address whose account was never created is harmless, its transfers cannot land
+UPDATE deposit_addresses SET status = 'active' WHERE status = 'pending';
+
+ALTER TABLE deposit_addresses
+DROP CONSTRAINT IF EXISTS check_deposit_address_status;
+
+ALTER TABLE deposit_addresses
+ADD CONSTRAINT check_deposit_address_status
+    CHECK (status IN ('active', 'sweeping', 'swept'));
+
+COMMENT ON COLUMN deposit_addresses.status IS 'active, sweeping (claimed by a sweep) or swept';
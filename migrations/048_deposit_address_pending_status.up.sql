@@ -0,0 +1,12 @@
+-- Migration 048: Deposit address pending status
+-- Solana deposit addresses are derived when the payment is created and stay pending until the worker
+-- has created their token account and the creation is finalized. Pending addresses are not watched.
+
+ALTER TABLE deposit_addresses
+DROP CONSTRAINT IF EXISTS check_deposit_address_status;
+
+ALTER TABLE deposit_addresses
+ADD CONSTRAINT check_deposit_address_status
+    CHECK (status IN ('pending', 'active', 'sweeping', 'swept'));
+
+COMMENT ON COLUMN deposit_addresses.status IS 'pending (account being created), active, sweeping (claimed by a sweep) or swept';
@@ -0,0 +1,4 @@
+-- Rollback Migration 049: Remove the transfer hash and pricing amount search indexes
+
+DROP INDEX IF EXISTS idx_payments_search_merchant_pricing_amount;
+DROP INDEX IF EXISTS idx_payment_transfers_tx_hash;
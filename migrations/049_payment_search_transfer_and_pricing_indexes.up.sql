@@ -0,0 +1,9 @@
+-- Migration 049: Payment search indexes for transfer hashes and pricing amounts
+-- Searches by tx_hash also match the top-up transfers of a payment, whose hashes are only in
+-- payment_transfers. Amount ranges in the pricing currency filter on pricing_currency first.
+
+CREATE INDEX IF NOT EXISTS idx_payment_transfers_tx_hash
+ON payment_transfers(tx_hash, payment_id);
+
+CREATE INDEX IF NOT EXISTS idx_payments_search_merchant_pricing_amount
+ON payments(merchant_id, pricing_currency, pricing_amount) WHERE deleted_at IS NULL;